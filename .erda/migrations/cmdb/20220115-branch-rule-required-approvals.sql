@@ -0,0 +1 @@
+ALTER TABLE `dice_branch_rules` ADD COLUMN `required_approvals` int(11) NOT NULL DEFAULT 0 COMMENT '保护分支合并mr需要的审批人数' AFTER `need_approval`;
@@ -0,0 +1,20 @@
+CREATE TABLE `erda_code_coverage_report`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+    `created_at`     datetime       NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at`     datetime       NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `project_id`     bigint(20) NOT NULL DEFAULT 0 COMMENT '项目ID',
+    `app_id`         bigint(20) NOT NULL DEFAULT 0 COMMENT '应用ID',
+    `pipeline_id`    bigint(20) NOT NULL DEFAULT 0 COMMENT '流水线ID',
+    `branch`         varchar(255)   NOT NULL DEFAULT '' COMMENT '分支',
+    `commit_id`      varchar(64)    NOT NULL DEFAULT '' COMMENT '提交ID',
+    `format`         varchar(32)    NOT NULL DEFAULT '' COMMENT '报告格式: go, lcov, cobertura',
+    `coverage`       decimal(65, 2) NOT NULL DEFAULT 0.00 COMMENT '行覆盖率',
+    `report_content` longtext       NOT NULL COMMENT '报告分析内容',
+    `files`          longtext       NOT NULL COMMENT '文件行覆盖数据',
+    `creator`        varchar(255)   NOT NULL DEFAULT '' COMMENT '上传者',
+    PRIMARY KEY (`id`),
+    KEY `idx_app_commit` (`app_id`, `commit_id`),
+    KEY `idx_app_branch` (`app_id`, `branch`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='流水线上传的代码覆盖率报告';
@@ -0,0 +1,15 @@
+CREATE TABLE `erda_issue_commit_relation`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+    `created_at` datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at` datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `issue_id`   bigint(20) NOT NULL DEFAULT 0 COMMENT '事项ID',
+    `app_id`     bigint(20) NOT NULL DEFAULT 0 COMMENT '应用ID',
+    `commit_id`  varchar(64)  NOT NULL DEFAULT '' COMMENT '提交ID',
+    `branch`     varchar(255) NOT NULL DEFAULT '' COMMENT '推送的分支',
+    `stream_id`  bigint(20) NOT NULL DEFAULT 0 COMMENT '事项活动记录ID',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_issue_app_commit` (`issue_id`, `app_id`, `commit_id`),
+    KEY `idx_app_commit` (`app_id`, `commit_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='事项与提交的关联关系';
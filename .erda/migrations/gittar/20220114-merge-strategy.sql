@@ -0,0 +1,3 @@
+ALTER TABLE `dice_repo_merge_requests` ADD COLUMN `merge_strategy` varchar(32) NOT NULL DEFAULT '' COMMENT 'mr的合并方式: merge, squash, rebase, fast_forward';
+ALTER TABLE `dice_repos` ADD COLUMN `default_merge_strategy` varchar(32) NOT NULL DEFAULT '' COMMENT 'mr默认合并方式, 为空时使用merge';
+ALTER TABLE `dice_repos` ADD COLUMN `allowed_merge_strategies` varchar(128) NOT NULL DEFAULT '' COMMENT 'mr允许的合并方式, 逗号分隔, 为空时允许所有方式';
@@ -0,0 +1,29 @@
+CREATE TABLE `dice_repo_merge_approvals`
+(
+    `id`         bigint(20)   NOT NULL AUTO_INCREMENT COMMENT 'primary',
+    `repo_id`    bigint(20)   NOT NULL DEFAULT 0 COMMENT '仓库 ID',
+    `merge_id`   bigint(20)   NOT NULL DEFAULT 0 COMMENT 'mr ID',
+    `user_id`    varchar(150) NOT NULL DEFAULT '' COMMENT '审批人 ID',
+    `user_name`  varchar(150) NOT NULL DEFAULT '' COMMENT '审批人用户名',
+    `user_email` varchar(150) NOT NULL DEFAULT '' COMMENT '审批人邮箱',
+    `commit_sha` varchar(64)  NOT NULL DEFAULT '' COMMENT '审批时源分支的提交, 源分支有新的提交后审批失效',
+    `created_at` timestamp    NULL     DEFAULT NULL COMMENT '审批时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_unique_merge_user` (`merge_id`, `user_id`),
+    KEY `idx_repo_id` (`repo_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='Gittar mr审批记录';
+
+CREATE TABLE `dice_repo_merge_reviewers`
+(
+    `id`         bigint(20)   NOT NULL AUTO_INCREMENT COMMENT 'primary',
+    `repo_id`    bigint(20)   NOT NULL DEFAULT 0 COMMENT '仓库 ID',
+    `merge_id`   bigint(20)   NOT NULL DEFAULT 0 COMMENT 'mr ID',
+    `pattern`    varchar(255) NOT NULL DEFAULT '' COMMENT '审批人负责的 CODEOWNERS 规则',
+    `user_id`    varchar(150) NOT NULL DEFAULT '' COMMENT '审批人 ID',
+    `created_at` timestamp    NULL     DEFAULT NULL COMMENT '指定时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_unique_merge_pattern` (`merge_id`, `pattern`),
+    KEY `idx_repo_id` (`repo_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='Gittar mr根据 CODEOWNERS 自动指定的审批人';
@@ -0,0 +1,15 @@
+CREATE TABLE `dice_repo_ssh_keys`
+(
+    `id`           bigint(20)   NOT NULL AUTO_INCREMENT COMMENT 'primary',
+    `user_id`      varchar(150) NOT NULL DEFAULT '' COMMENT '用户 ID',
+    `title`        varchar(255) NOT NULL DEFAULT '' COMMENT '公钥标题',
+    `fingerprint`  varchar(128) NOT NULL DEFAULT '' COMMENT '公钥 SHA256 指纹',
+    `content`      text         NOT NULL COMMENT 'authorized_keys 格式的公钥',
+    `last_used_at` timestamp    NULL     DEFAULT NULL COMMENT '最后使用时间',
+    `created_at`   timestamp    NULL     DEFAULT NULL COMMENT '创建时间',
+    `updated_at`   timestamp    NULL     DEFAULT NULL COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_fingerprint` (`fingerprint`),
+    KEY `idx_user_id` (`user_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='Gittar 用户 ssh 公钥';
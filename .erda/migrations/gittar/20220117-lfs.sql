@@ -0,0 +1,13 @@
+ALTER TABLE `dice_repos` ADD COLUMN `lfs_quota` bigint(20) NOT NULL DEFAULT 0 COMMENT 'lfs存储配额, 单位Byte, 0表示使用系统默认配额, 小于0表示不限制';
+
+CREATE TABLE `dice_repo_lfs_objects`
+(
+    `id`         bigint(20)  NOT NULL AUTO_INCREMENT COMMENT 'primary',
+    `repo_id`    bigint(20)  NOT NULL DEFAULT 0 COMMENT '仓库 ID',
+    `oid`        varchar(64) NOT NULL DEFAULT '' COMMENT '对象 sha256',
+    `size`       bigint(20)  NOT NULL DEFAULT 0 COMMENT '对象大小, 单位 Byte',
+    `created_at` timestamp   NULL     DEFAULT NULL COMMENT '上传时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_repo_oid` (`repo_id`, `oid`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='Gittar lfs 对象';
@@ -0,0 +1,15 @@
+CREATE TABLE `pipeline_action_caches`
+(
+    `id`           bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '自增id',
+    `project_id`   bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT '项目 ID',
+    `cache_key`    varchar(512)        NOT NULL DEFAULT '' COMMENT '缓存 key',
+    `key_hash`     char(64)            NOT NULL DEFAULT '' COMMENT '缓存 key 的 sha256',
+    `size`         bigint(20)          NOT NULL DEFAULT 0 COMMENT '缓存大小, 单位 Byte',
+    `last_used_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '最近使用时间, 用于 LRU 淘汰',
+    `created_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'CREATED AT',
+    `updated_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'UPDATED AT',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_project_key_hash` (`project_id`, `key_hash`),
+    KEY `idx_project_cache_key` (`project_id`, `cache_key`(191))
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='pipeline action 远程缓存';
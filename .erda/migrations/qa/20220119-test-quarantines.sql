@@ -0,0 +1,16 @@
+CREATE TABLE `qa_test_quarantines`
+(
+    `id`          bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+    `created_at`  datetime      NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at`  datetime      NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `app_id`      bigint(20) NOT NULL DEFAULT 0 COMMENT '应用ID',
+    `test_key`    varchar(64)   NOT NULL DEFAULT '' COMMENT '用例唯一标识',
+    `suite`       varchar(255)  NOT NULL DEFAULT '' COMMENT '用例所属 suite',
+    `classname`   varchar(255)  NOT NULL DEFAULT '' COMMENT '用例 classname',
+    `name`        varchar(512)  NOT NULL DEFAULT '' COMMENT '用例名称',
+    `reason`      varchar(1024) NOT NULL DEFAULT '' COMMENT '隔离原因',
+    `operator_id` varchar(255)  NOT NULL DEFAULT '' COMMENT '操作者',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_app_test_key` (`app_id`, `test_key`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='被隔离的不稳定单元测试用例';
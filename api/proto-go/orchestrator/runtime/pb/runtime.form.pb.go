@@ -81,6 +81,18 @@ func (m *Deployments) UnmarshalURLValues(prefix string, values url.Values) error
 					return err
 				}
 				m.Replicas = val
+			case "currentReplicas":
+				val, err := strconv.ParseUint(vals[0], 10, 64)
+				if err != nil {
+					return err
+				}
+				m.CurrentReplicas = val
+			case "desiredReplicas":
+				val, err := strconv.ParseUint(vals[0], 10, 64)
+				if err != nil {
+					return err
+				}
+				m.DesiredReplicas = val
 			}
 		}
 	}
@@ -107,6 +119,24 @@ func (m *Service) UnmarshalURLValues(prefix string, values url.Values) error {
 					return err
 				}
 				m.Deployments.Replicas = val
+			case "deployments.currentReplicas":
+				if m.Deployments == nil {
+					m.Deployments = &Deployments{}
+				}
+				val, err := strconv.ParseUint(vals[0], 10, 64)
+				if err != nil {
+					return err
+				}
+				m.Deployments.CurrentReplicas = val
+			case "deployments.desiredReplicas":
+				if m.Deployments == nil {
+					m.Deployments = &Deployments{}
+				}
+				val, err := strconv.ParseUint(vals[0], 10, 64)
+				if err != nil {
+					return err
+				}
+				m.Deployments.DesiredReplicas = val
 			case "resources":
 				if m.Resources == nil {
 					m.Resources = &Resources{}
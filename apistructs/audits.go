@@ -195,6 +195,9 @@ const (
 	ExecuteDeploymentOrderTemplate TemplateName = "executeDeploymentOrder"
 	CancelDeploymentOrderTemplate  TemplateName = "cancelDeploymentOrder"
 
+	// =====================KMS============================
+	RotateKMSKeyTemplate TemplateName = "rotateKmsKey"
+
 	// =====================Notify============================
 	CreateProjectNotifyTemplate  TemplateName = "createProjectNotify"
 	CreateAppNotifyTemplate      TemplateName = "createAppNotify"
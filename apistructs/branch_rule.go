@@ -29,6 +29,8 @@ type BranchRule struct {
 	Workspace string `json:"workspace"`
 	// 制品可部署的环境
 	ArtifactWorkspace string `json:"artifactWorkspace"`
+	// 保护分支合并mr需要的审批人数
+	RequiredApprovals int `json:"requiredApprovals"`
 }
 type QueryBranchRuleRequest struct {
 	ProjectID int64 `query:"projectId"`
@@ -50,6 +52,7 @@ type CreateBranchRuleRequest struct {
 	Workspace         string    `json:"workspace"`
 	ArtifactWorkspace string    `json:"artifactWorkspace"`
 	Desc              string    `json:"desc"`
+	RequiredApprovals int       `json:"requiredApprovals"`
 }
 
 type CreateBranchRuleResponse struct {
@@ -66,6 +69,7 @@ type UpdateBranchRuleRequest struct {
 	Desc              string `json:"desc"`
 	Workspace         string `json:"workspace"`
 	ArtifactWorkspace string `json:"artifactWorkspace"`
+	RequiredApprovals int    `json:"requiredApprovals"`
 }
 
 type UpdateBranchRuleResponse struct {
@@ -0,0 +1,186 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CodeCoverageReportFormat 流水线上传的覆盖率报告格式
+type CodeCoverageReportFormat string
+
+const (
+	// GoCoverProfileFormat go test -coverprofile 生成的 coverprofile
+	GoCoverProfileFormat CodeCoverageReportFormat = "go"
+	// LcovFormat lcov tracefile, 如 istanbul/nyc, c8, gcov 等生成的 lcov.info
+	LcovFormat CodeCoverageReportFormat = "lcov"
+	// CoberturaFormat cobertura xml, 如 coverage.py, gocover-cobertura 等生成的 coverage.xml
+	CoberturaFormat CodeCoverageReportFormat = "cobertura"
+)
+
+func (f CodeCoverageReportFormat) Valid() bool {
+	switch f {
+	case GoCoverProfileFormat, LcovFormat, CoberturaFormat:
+		return true
+	}
+	return false
+}
+
+// CodeCoverageFile 单个源文件的行覆盖数据
+type CodeCoverageFile struct {
+	// Path 报告中的源文件路径
+	Path string `json:"path"`
+	// Lines 可执行行的行号 -> 命中次数
+	Lines map[int]int64 `json:"lines"`
+}
+
+// CodeCoverageReportUploadRequest POST /api/code-coverage/reports/actions/upload 上传覆盖率报告
+type CodeCoverageReportUploadRequest struct {
+	IdentityInfo
+
+	AppID      uint64                   `json:"appID"`
+	PipelineID uint64                   `json:"pipelineID"`
+	Branch     string                   `json:"branch"`
+	CommitID   string                   `json:"commitID"`
+	Format     CodeCoverageReportFormat `json:"format"`
+	// Content 报告原始内容
+	Content string `json:"content"`
+}
+
+func (req *CodeCoverageReportUploadRequest) Validate() error {
+	if req.AppID == 0 {
+		return errors.New("the appID is 0")
+	}
+	if !req.Format.Valid() {
+		return fmt.Errorf("invalid format: %s", req.Format)
+	}
+	if req.Content == "" {
+		return errors.New("the content is empty")
+	}
+	return nil
+}
+
+// CodeCoverageReportListRequest GET /api/code-coverage/reports/actions/list 覆盖率报告列表
+type CodeCoverageReportListRequest struct {
+	AppID    uint64 `schema:"appID"`
+	Branch   string `schema:"branch"`
+	CommitID string `schema:"commitID"`
+	PageNo   uint64 `schema:"pageNo"`
+	PageSize uint64 `schema:"pageSize"`
+}
+
+func (req *CodeCoverageReportListRequest) Validate() error {
+	if req.AppID == 0 {
+		return errors.New("the appID is 0")
+	}
+	if req.PageNo == 0 {
+		req.PageNo = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 10
+	}
+	return nil
+}
+
+type CodeCoverageReportDto struct {
+	ID            uint64              `json:"id"`
+	ProjectID     uint64              `json:"projectID"`
+	AppID         uint64              `json:"appID"`
+	PipelineID    uint64              `json:"pipelineID"`
+	Branch        string              `json:"branch"`
+	CommitID      string              `json:"commitID"`
+	Format        string              `json:"format"`
+	Coverage      float64             `json:"coverage"`
+	ReportContent []*CodeCoverageNode `json:"reportContent,omitempty"`
+	Creator       string              `json:"creator"`
+	TimeCreated   time.Time           `json:"timeCreated"`
+}
+
+type CodeCoverageReportData struct {
+	Total uint64                  `json:"total"`
+	List  []CodeCoverageReportDto `json:"list"`
+}
+
+type CodeCoverageReportUploadResponse struct {
+	Header
+	Data *CodeCoverageReportDto `json:"data"`
+}
+
+type CodeCoverageReportListResponse struct {
+	Header
+	UserInfoHeader
+	Data *CodeCoverageReportData `json:"data"`
+}
+
+type CodeCoverageReportGetResponse struct {
+	Header
+	UserInfoHeader
+	Data *CodeCoverageReportDto `json:"data"`
+}
+
+// CodeCoverageMRDeltaRequest GET /api/code-coverage/reports/actions/mr-delta 计算 MR 变更行覆盖率
+type CodeCoverageMRDeltaRequest struct {
+	IdentityInfo
+
+	AppID   uint64 `schema:"appID"`
+	MergeID uint64 `schema:"mergeID"`
+	// ReportID 指定使用的报告, 为空时使用 MR 源分支最新提交的报告
+	ReportID uint64 `schema:"reportID"`
+}
+
+func (req *CodeCoverageMRDeltaRequest) Validate() error {
+	if req.AppID == 0 {
+		return errors.New("the appID is 0")
+	}
+	if req.MergeID == 0 {
+		return errors.New("the mergeID is 0")
+	}
+	return nil
+}
+
+// CodeCoverageDelta MR 变更行覆盖率, 只统计 MR 新增或修改的可执行行
+type CodeCoverageDelta struct {
+	AppID        uint64 `json:"appID"`
+	MergeID      uint64 `json:"mergeID"`
+	ReportID     uint64 `json:"reportID"`
+	SourceBranch string `json:"sourceBranch"`
+	TargetBranch string `json:"targetBranch"`
+	CommitID     string `json:"commitID"`
+	// Coverage 报告整体的行覆盖率
+	Coverage float64 `json:"coverage"`
+	// ChangedLines MR 变更的可执行行数
+	ChangedLines int `json:"changedLines"`
+	// CoveredLines 其中被覆盖的行数
+	CoveredLines int `json:"coveredLines"`
+	// DeltaCoverage 变更行覆盖率, 没有可执行的变更行时为 100
+	DeltaCoverage float64                 `json:"deltaCoverage"`
+	Files         []CodeCoverageFileDelta `json:"files"`
+}
+
+type CodeCoverageFileDelta struct {
+	Name          string  `json:"name"`
+	ChangedLines  int     `json:"changedLines"`
+	CoveredLines  int     `json:"coveredLines"`
+	DeltaCoverage float64 `json:"deltaCoverage"`
+	// UncoveredLines 未覆盖的变更行行号
+	UncoveredLines []int `json:"uncoveredLines"`
+}
+
+type CodeCoverageMRDeltaResponse struct {
+	Header
+	Data *CodeCoverageDelta `json:"data"`
+}
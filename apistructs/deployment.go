@@ -103,6 +103,51 @@ type DeploymentCancelResponse struct {
 	// no data
 }
 
+// labels of service passed to scheduler, indicate how the executor updates the service
+// during a canary or blue-green deployment
+const (
+	LabelRolloutStrategy = "ROLLOUT_STRATEGY"
+	LabelRolloutPhase    = "ROLLOUT_PHASE"
+	LabelRolloutWeight   = "ROLLOUT_WEIGHT"
+)
+
+const (
+	// RolloutPhaseProgressing the new version runs beside the old one
+	RolloutPhaseProgressing = "progressing"
+	// RolloutPhasePromote the new version replaces the old one
+	RolloutPhasePromote = "promote"
+	// RolloutPhaseComplete the stable deployment runs the new version, the preview resources are removed
+	RolloutPhaseComplete = "complete"
+	// RolloutPhaseAbort the new version is removed, the old one is kept
+	RolloutPhaseAbort = "abort"
+)
+
+// DeploymentRollout progress of a canary or blue-green deployment
+type DeploymentRollout struct {
+	// Services strategy of each progressive service
+	Services map[string]string `json:"services"`
+	// Step index of the current canary step, blue-green has only one step
+	Step  int `json:"step"`
+	Steps int `json:"steps"`
+	// StepStartAt when the current step was applied
+	StepStartAt *time.Time `json:"stepStartAt,omitempty"`
+	// StepReadyAt when the new version of the current step became healthy
+	StepReadyAt *time.Time `json:"stepReadyAt,omitempty"`
+	// WaitPromote the current step is paused until promoted manually
+	WaitPromote bool `json:"waitPromote"`
+	// PromoteRequested promote has been requested by user
+	PromoteRequested bool `json:"promoteRequested,omitempty"`
+	// Promoted all traffic has been switched to the new version
+	Promoted bool `json:"promoted"`
+	// RolledBack the new version failed health checks and the runtime was rolled back
+	RolledBack bool `json:"rolledBack,omitempty"`
+}
+
+type RuntimeRolloutResponse struct {
+	Header
+	Data DeploymentRollout `json:"data"`
+}
+
 type DeploymentApproveRequest struct {
 	ID     uint64 `json:"id"`
 	Reject bool   `json:"reject"`
@@ -201,6 +201,8 @@ type GittarCompareData struct {
 	To      string   `json:"to"`
 	Commits []Commit `json:"commits"`
 	Diff    Diff     `json:"diff"`
+	// 两个提交之间的提交总数, commits 为分页后的结果
+	CommitsCount int `json:"commitsCount"`
 }
 
 // GittarBlobResponse GET /<projectName>/<appName>/blob/<*> 获取blob信息
@@ -287,6 +289,9 @@ type MergeRequestInfo struct {
 	RebaseBranch         string       `json:"rebaseBranch" default:"-"`
 	EventName            string       `json:"eventName"`
 	CheckRuns            CheckRuns    `json:"checkRuns,omitempty"`
+	MergeStrategy        string       `json:"mergeStrategy"`
+	// Approval 审批状态, 仅详情接口返回
+	Approval *MergeRequestApprovalState `json:"approval,omitempty"`
 }
 
 type MergeStatusInfo struct {
@@ -294,6 +299,111 @@ type MergeStatusInfo struct {
 	IsMerged    bool   `json:"isMerged"`
 	HasError    bool   `json:"hasError"`
 	ErrorMsg    string `json:"errorMsg"`
+	Strategy    string `json:"strategy"`
+}
+
+// MergeStrategy mr合并方式
+type MergeStrategy string
+
+const (
+	// MergeStrategyMerge 创建合并提交
+	MergeStrategyMerge MergeStrategy = "merge"
+	// MergeStrategySquash 将源分支的提交压缩成一个提交
+	MergeStrategySquash MergeStrategy = "squash"
+	// MergeStrategyRebase 将源分支的提交变基到目标分支
+	MergeStrategyRebase MergeStrategy = "rebase"
+	// MergeStrategyFastForward 仅允许快进合并
+	MergeStrategyFastForward MergeStrategy = "fast_forward"
+)
+
+// MergeStrategies 所有支持的合并方式
+var MergeStrategies = []MergeStrategy{MergeStrategyMerge, MergeStrategySquash, MergeStrategyRebase, MergeStrategyFastForward}
+
+func (s MergeStrategy) String() string {
+	return string(s)
+}
+
+// Valid 是否是支持的合并方式
+func (s MergeStrategy) Valid() bool {
+	for _, strategy := range MergeStrategies {
+		if s == strategy {
+			return true
+		}
+	}
+	return false
+}
+
+// MergeRequestApproval mr审批记录
+type MergeRequestApproval struct {
+	UserID    string       `json:"userId"`
+	User      *UserInfoDto `json:"user"`
+	CreatedAt time.Time    `json:"createdAt"`
+}
+
+// CodeOwnersGroup CODEOWNERS中匹配到改动文件的规则, 需要其中一个owner审批
+type CodeOwnersGroup struct {
+	Pattern  string   `json:"pattern"`
+	Owners   []string `json:"owners"`
+	Paths    []string `json:"paths"`
+	Approved bool     `json:"approved"`
+	// Reviewer 自动指定的审批人用户id, owner中没有用户id时为空
+	Reviewer string `json:"reviewer"`
+}
+
+// MergeRequestApprovalState mr审批状态
+type MergeRequestApprovalState struct {
+	// RequiredApprovals 目标分支规则要求的审批人数
+	RequiredApprovals int                    `json:"requiredApprovals"`
+	Approvals         []MergeRequestApproval `json:"approvals"`
+	CodeOwners        []CodeOwnersGroup      `json:"codeOwners"`
+	// Approved 审批人数满足要求且每个CODEOWNERS规则都已审批
+	Approved bool `json:"approved"`
+}
+
+// RepoMergeSettings 仓库的mr合并设置
+type RepoMergeSettings struct {
+	DefaultStrategy   MergeStrategy   `json:"defaultStrategy"`
+	AllowedStrategies []MergeStrategy `json:"allowedStrategies"`
+}
+
+// RepoLFSSettings 仓库的lfs设置和用量
+type RepoLFSSettings struct {
+	// Quota 仓库的lfs存储配额, 单位Byte, 0表示使用系统默认配额, 小于0表示不限制
+	Quota int64 `json:"quota"`
+	// EffectiveQuota 实际生效的配额, 小于等于0表示不限制
+	EffectiveQuota int64 `json:"effectiveQuota"`
+	Size           int64 `json:"size"`
+	Objects        int64 `json:"objects"`
+}
+
+// GittarSSHKey 用户ssh公钥
+type GittarSSHKey struct {
+	ID          int64      `json:"id"`
+	UserID      string     `json:"userId"`
+	Title       string     `json:"title"`
+	Fingerprint string     `json:"fingerprint"`
+	Key         string     `json:"key"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	LastUsedAt  *time.Time `json:"lastUsedAt"`
+}
+
+// GittarCreateSSHKeyRequest 添加ssh公钥请求
+type GittarCreateSSHKeyRequest struct {
+	Title string `json:"title"`
+	// Key authorized_keys格式的公钥, 如 ssh-ed25519 AAAA... user@host
+	Key string `json:"key"`
+}
+
+// GittarListSSHKeysResponse ssh公钥列表响应
+type GittarListSSHKeysResponse struct {
+	Header
+	Data []*GittarSSHKey `json:"data"`
+}
+
+// GittarCreateSSHKeyResponse 添加ssh公钥响应
+type GittarCreateSSHKeyResponse struct {
+	Header
+	Data *GittarSSHKey `json:"data"`
 }
 
 // GittarCreateMergeResponse 创建mr响应
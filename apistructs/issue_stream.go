@@ -35,7 +35,8 @@ type IssueStreamType string
 const (
 	ISTCreate                        IssueStreamType = "Create" // 创建事件
 	ISTComment                       IssueStreamType = "Comment"
-	ISTRelateMR                      IssueStreamType = "RelateMR" // 关联 MR
+	ISTRelateMR                      IssueStreamType = "RelateMR"     // 关联 MR
+	ISTRelateCommit                  IssueStreamType = "RelateCommit" // 提交信息或分支名引用了事项
 	ISTAssign                        IssueStreamType = "Assign"
 	ISTTransferState                 IssueStreamType = "TransferState" // 状态迁移
 	ISTChangeTitle                   IssueStreamType = "ChangeTitle"
@@ -27,6 +27,7 @@ var IssueTemplate = map[string]map[IssueStreamType]string{
 		ISTCreate:                        `该事项由 {{.UserName}} 创建`,
 		ISTComment:                       `{{.Comment}}`,
 		ISTRelateMR:                      `mrInfo: {{.MRInfo}}`,
+		ISTRelateCommit:                  `应用 "{{.AppName}}" 的提交 {{.CommitID}} 引用了该事项: {{.CommitMessage}}`,
 		ISTAssign:                        `分派给 "{{.UserName}}" 处理`,
 		ISTTransferState:                 `状态自 "{{.CurrentState}}" 迁移至 "{{.NewState}}"`,
 		ISTChangeTitle:                   `标题自 "{{.CurrentTitle}}" 更新为 "{{.NewTitle}}"`,
@@ -50,6 +51,7 @@ var IssueTemplate = map[string]map[IssueStreamType]string{
 		ISTCreate:                        `{{.UserName}} created`,
 		ISTComment:                       `{{.Comment}}`,
 		ISTRelateMR:                      `mrInfo: {{.MRInfo}}`,
+		ISTRelateCommit:                  `commit {{.CommitID}} of application "{{.AppName}}" referenced this issue: {{.CommitMessage}}`,
 		ISTAssign:                        `assigned to "{{.UserName}}"`,
 		ISTTransferState:                 `transfer state from "{{.CurrentState}}" to "{{.NewState}}"`,
 		ISTChangeTitle:                   `change title "{{.CurrentTitle}}" to "{{.NewTitle}}"`,
@@ -89,6 +91,10 @@ type ISTParam struct {
 
 	MRInfo MRCommentInfo `json:",omitempty"` // MR 类型评论内容
 
+	AppName       string `json:",omitempty"` // 提交所属应用
+	CommitID      string `json:",omitempty"` // 引用事项的提交
+	CommitMessage string `json:",omitempty"` // 提交信息标题
+
 	CurrentState string `json:",omitempty"` // 当前状态
 	NewState     string `json:",omitempty"` // 新状态
 
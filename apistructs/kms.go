@@ -63,6 +63,24 @@ type KMSRotateKeyVersionResponse struct {
 	Data *kmstypes.RotateKeyVersionResponse `json:"data,omitempty"`
 }
 
+// update rotation policy
+type KMSUpdateRotationPolicyRequest struct {
+	kmstypes.UpdateRotationPolicyRequest
+}
+type KMSUpdateRotationPolicyResponse struct {
+	Header
+	Data *kmstypes.UpdateRotationPolicyResponse `json:"data,omitempty"`
+}
+
+// re-encrypt
+type KMSReEncryptRequest struct {
+	kmstypes.ReEncryptRequest
+}
+type KMSReEncryptResponse struct {
+	Header
+	Data *kmstypes.ReEncryptResponse `json:"data,omitempty"`
+}
+
 // describe key
 type KMSDescribeKeyRequest struct {
 	kmstypes.DescribeKeyRequest
@@ -71,3 +89,48 @@ type KMSDescribeKeyResponse struct {
 	Header
 	Data *kmstypes.DescribeKeyResponse `json:"data,omitempty"`
 }
+
+// get public key
+type KMSGetPublicKeyRequest struct {
+	kmstypes.GetPublicKeyRequest
+}
+type KMSGetPublicKeyResponse struct {
+	Header
+	Data *kmstypes.PublicKey `json:"data,omitempty"`
+}
+
+// asymmetric encrypt
+type KMSAsymmetricEncryptRequest struct {
+	kmstypes.AsymmetricEncryptRequest
+}
+type KMSAsymmetricEncryptResponse struct {
+	Header
+	Data *kmstypes.AsymmetricEncryptResponse `json:"data,omitempty"`
+}
+
+// asymmetric decrypt
+type KMSAsymmetricDecryptRequest struct {
+	kmstypes.AsymmetricDecryptRequest
+}
+type KMSAsymmetricDecryptResponse struct {
+	Header
+	Data *kmstypes.AsymmetricDecryptResponse `json:"data,omitempty"`
+}
+
+// sign
+type KMSSignRequest struct {
+	kmstypes.SignRequest
+}
+type KMSSignResponse struct {
+	Header
+	Data *kmstypes.SignResponse `json:"data,omitempty"`
+}
+
+// verify
+type KMSVerifyRequest struct {
+	kmstypes.VerifyRequest
+}
+type KMSVerifyResponse struct {
+	Header
+	Data *kmstypes.VerifyResponse `json:"data,omitempty"`
+}
@@ -33,6 +33,10 @@ const (
 	DingdingNotifyTarget           NotifyTargetType = "dingding"
 	DingdingWorkNoticeNotifyTarget NotifyTargetType = "dingding_worknotice"
 	WebhookNotifyTarget            NotifyTargetType = "webhook"
+	SlackNotifyTarget              NotifyTargetType = "slack"
+	TeamsNotifyTarget              NotifyTargetType = "teams"
+	FeishuNotifyTarget             NotifyTargetType = "feishu"
+	WeComNotifyTarget              NotifyTargetType = "wecom"
 )
 
 var ValidateNotifyChannel = map[string]bool{
@@ -42,6 +46,10 @@ var ValidateNotifyChannel = map[string]bool{
 	"mbox":     true,
 	"webhook":  true,
 	"vms":      true,
+	"slack":    true,
+	"teams":    true,
+	"feishu":   true,
+	"wecom":    true,
 }
 
 // NotifyTarget 通知目标
@@ -59,7 +67,7 @@ type OldNotifyTarget struct {
 // Target 目标详情
 type Target struct {
 	Receiver string `json:"receiver"`
-	// 目前只有钉钉用
+	// 钉钉、飞书机器人的签名密钥
 	Secret string `json:"secret"`
 }
 
@@ -132,6 +140,10 @@ type NotifyGroupDetail struct {
 	DingdingList           []Target       `json:"dingdingList"`
 	DingdingWorkNoticeList []Target       `json:"dingdingWorknoticeList"`
 	WebHookList            []string       `json:"webhookList"`
+	SlackList              []Target       `json:"slackList"`
+	TeamsList              []Target       `json:"teamsList"`
+	FeishuList             []Target       `json:"feishuList"`
+	WeComList              []Target       `json:"wecomList"`
 }
 
 // CreateNotifyGroupRequest 创建通知组请求
@@ -655,6 +655,8 @@ type ValidBranch struct {
 	Workspace string `json:"workspace"`
 	// 制品可部署的环境
 	ArtifactWorkspace string `json:"artifactWorkspace"`
+	// 保护分支合并mr需要的审批人数
+	RequiredApprovals int `json:"requiredApprovals"`
 }
 
 func (branch *ValidBranch) GetPermissionResource() string {
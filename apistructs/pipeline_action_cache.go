@@ -0,0 +1,87 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+// PipelineTaskActionCacheStatus 远程缓存的恢复结果
+type PipelineTaskActionCacheStatus string
+
+var (
+	// ActionCacheStatusHit key 完全匹配
+	ActionCacheStatusHit PipelineTaskActionCacheStatus = "hit"
+	// ActionCacheStatusPartialHit key 未匹配, 通过 restoreKeys 前缀匹配到了缓存
+	ActionCacheStatusPartialHit PipelineTaskActionCacheStatus = "partial-hit"
+	// ActionCacheStatusMiss 未匹配到任何缓存
+	ActionCacheStatusMiss PipelineTaskActionCacheStatus = "miss"
+)
+
+// PipelineTaskActionCache task 中一个远程缓存的使用情况, 记录在 task inspect 中
+type PipelineTaskActionCache struct {
+	Path       string                        `json:"path"`
+	Key        string                        `json:"key"`                  // 渲染后的 key
+	MatchedKey string                        `json:"matchedKey,omitempty"` // 实际恢复的缓存 key
+	Status     PipelineTaskActionCacheStatus `json:"status"`
+	Size       int64                         `json:"size,omitempty"`      // 恢复的缓存大小, 单位 Byte
+	SavedSize  int64                         `json:"savedSize,omitempty"` // 执行结束后保存的缓存大小, 单位 Byte, 0 表示未保存
+}
+
+// SetActionCache 按 path 更新或添加 task 的缓存使用记录
+func (t *PipelineTaskInspect) SetActionCache(cache *PipelineTaskActionCache) {
+	for i := range t.ActionCaches {
+		if t.ActionCaches[i].Path == cache.Path {
+			t.ActionCaches[i] = cache
+			return
+		}
+	}
+	t.ActionCaches = append(t.ActionCaches, cache)
+}
+
+// GetActionCache 根据 path 获取 task 的缓存使用记录
+func (t *PipelineTaskInspect) GetActionCache(path string) *PipelineTaskActionCache {
+	for _, cache := range t.ActionCaches {
+		if cache.Path == path {
+			return cache
+		}
+	}
+	return nil
+}
+
+// PipelineActionCacheQueryRequest action agent 恢复缓存前查询可用的缓存
+type PipelineActionCacheQueryRequest struct {
+	Path        string   `schema:"path"`
+	Key         string   `schema:"key"`
+	RestoreKeys []string `schema:"restoreKeys"`
+}
+
+type PipelineActionCacheQueryResponse struct {
+	Header
+	Data *PipelineTaskActionCache `json:"data"`
+}
+
+// PipelineActionCacheDownloadRequest 下载缓存, key 为查询结果中的 matchedKey
+type PipelineActionCacheDownloadRequest struct {
+	Path string `schema:"path"`
+	Key  string `schema:"key"`
+}
+
+// PipelineActionCacheUploadRequest 上传缓存, 缓存内容 (.tar) 作为请求 body
+type PipelineActionCacheUploadRequest struct {
+	Path string `schema:"path"`
+	Key  string `schema:"key"`
+}
+
+type PipelineActionCacheUploadResponse struct {
+	Header
+	Data *PipelineTaskActionCache `json:"data"`
+}
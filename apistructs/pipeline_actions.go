@@ -110,6 +110,9 @@ type ActionCallback struct {
 	// machine stat
 	MachineStat *PipelineTaskMachineStat `json:"machineStat,omitempty"`
 
+	// exit code of action, only reported when action agent exit
+	ExitCode *int `json:"exitCode,omitempty"`
+
 	// behind
 	PipelineID     uint64 `json:"pipelineID"`
 	PipelineTaskID uint64 `json:"pipelineTaskID"`
@@ -0,0 +1,79 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+// PipelineDryRunRequest is same as create request, the pipeline won't be created or run.
+type PipelineDryRunRequest = PipelineCreateRequestV2
+
+type PipelineDryRunResponse struct {
+	Header
+	Data *PipelineDryRunResult `json:"data"`
+}
+
+// PipelineDryRunResult is the fully materialized plan of pipeline.
+type PipelineDryRunResult struct {
+	// PipelineYml is rendered by envs, params and secrets, secret values are masked as ((key))
+	PipelineYml string                      `json:"pipelineYml"`
+	RunParams   []PipelineRunParamWithValue `json:"runParams,omitempty"`
+	Secrets     []PipelineDryRunSecret      `json:"secrets,omitempty"`
+	// Stages is the final DAG, tasks in the same stage run in parallel
+	Stages [][]*PipelineDryRunTask `json:"stages"`
+	// Warns from parser, such as outputs which can only be known at runtime
+	Warns []string `json:"warns,omitempty"`
+	// PreCheck is the result of precheck checkers, same as the pipeline show message
+	PreCheck *ShowMessage `json:"preCheck,omitempty"`
+}
+
+// PipelineDryRunSecret only contains name of secret referenced by pipeline yml, value is never returned.
+type PipelineDryRunSecret struct {
+	Name  string `json:"name"`
+	Found bool   `json:"found"`
+}
+
+type PipelineDryRunTask struct {
+	Name       string                 `json:"name"`
+	Type       string                 `json:"type"`
+	Version    string                 `json:"version,omitempty"`
+	Image      string                 `json:"image,omitempty"`
+	Status     PipelineStatus         `json:"status"` // Analyzed or Disabled
+	Needs      []string               `json:"needs,omitempty"`
+	Params     map[string]interface{} `json:"params,omitempty"`
+	Commands   []string               `json:"commands,omitempty"`
+	TimeoutSec int64                  `json:"timeoutSec"` // -1 means forever
+	Caches     []ActionCache          `json:"caches,omitempty"`
+	Services   []string               `json:"services,omitempty"`
+	Loop       *PipelineTaskLoop      `json:"loop,omitempty"`
+	Retry      *PipelineTaskRetry     `json:"retry,omitempty"`
+
+	If        string                  `json:"if,omitempty"`
+	Condition PipelineDryRunCondition `json:"condition"`
+	// ConditionMsg is the reason of skip, unknown or invalid
+	ConditionMsg string `json:"conditionMsg,omitempty"`
+
+	// Snippet is the expanded plan of snippet task
+	Snippet *PipelineDryRunResult `json:"snippet,omitempty"`
+}
+
+// PipelineDryRunCondition is the result of `if` condition of task.
+type PipelineDryRunCondition string
+
+var (
+	PipelineDryRunConditionRun  PipelineDryRunCondition = "run"
+	PipelineDryRunConditionSkip PipelineDryRunCondition = "skip"
+	// PipelineDryRunConditionUnknown condition depends on outputs of other tasks, which can only be evaluated at runtime
+	PipelineDryRunConditionUnknown PipelineDryRunCondition = "unknown"
+	// PipelineDryRunConditionInvalid condition can't be evaluated, task will be failed
+	PipelineDryRunConditionInvalid PipelineDryRunCondition = "invalid"
+)
@@ -24,6 +24,7 @@ import (
 const (
 	PipelineReportEventMetaKey = "event"
 	PipelineReportLoopMetaKey  = "task-loop"
+	PipelineReportRetryMetaKey = "task-retry"
 )
 
 // PipelineReportSet 流水线报告集，一条流水线可能会有多个报告，称为报告集
@@ -57,6 +57,14 @@ type PipelineTaskExtra struct {
 	UUID           string          `json:"uuid"`
 	AllowFailure   bool            `json:"allowFailure"`
 	TaskContainers []TaskContainer `json:"taskContainers"`
+
+	// matrix
+	MatrixSource      string            `json:"matrixSource,omitempty"`      // original action alias before matrix expanded
+	MatrixCombination map[string]string `json:"matrixCombination,omitempty"` // matrix values of this task
+
+	// retry
+	Attempt  uint64                 `json:"attempt,omitempty"`  // current attempt, begin from 1
+	Attempts []*PipelineTaskAttempt `json:"attempts,omitempty"` // finished attempts which were retried
 }
 
 type TaskContainer struct {
@@ -71,6 +79,8 @@ type PipelineTaskResult struct {
 	MachineStat *PipelineTaskMachineStat   `json:"machineStat,omitempty"`
 	Inspect     string                     `json:"inspect,omitempty"`
 	Events      string                     `json:"events,omitempty"`
+	// ActionCaches 远程缓存的命中情况
+	ActionCaches []*PipelineTaskActionCache `json:"actionCaches,omitempty"`
 }
 
 type PipelineTaskInspect struct {
@@ -78,6 +88,9 @@ type PipelineTaskInspect struct {
 	MachineStat *PipelineTaskMachineStat   `json:"machineStat,omitempty"`
 	Inspect     string                     `json:"inspect,omitempty"`
 	Events      string                     `json:"events,omitempty"`
+	ExitCode    *int                       `json:"exitCode,omitempty"` // exit code of action, reported by action agent
+	// ActionCaches 远程缓存的命中情况, 由 action agent 查询和上传缓存时记录
+	ActionCaches []*PipelineTaskActionCache `json:"actionCaches,omitempty"`
 }
 
 type PipelineTaskSnippetDetail struct {
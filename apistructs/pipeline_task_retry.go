@@ -0,0 +1,213 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// TaskAttemptBegin the first attempt of task is 1
+	TaskAttemptBegin = 1
+	// PipelineTaskRetryMaxAttemptsLimit limit the max attempts of task retry
+	PipelineTaskRetryMaxAttemptsLimit = 10
+)
+
+// PipelineTaskRetryOn represents the error class which task can retry on
+type PipelineTaskRetryOn string
+
+const (
+	PipelineTaskRetryOnFailed    PipelineTaskRetryOn = "failed"     // task logic failed, status: Failed
+	PipelineTaskRetryOnTimeout   PipelineTaskRetryOn = "timeout"    // task timeout, status: Timeout
+	PipelineTaskRetryOnError     PipelineTaskRetryOn = "error"      // platform error, status: Error, CreateError, StartError ...
+	PipelineTaskRetryOnImagePull PipelineTaskRetryOn = "image-pull" // failed to pull image
+	PipelineTaskRetryOnScheduler PipelineTaskRetryOn = "scheduler"  // failed to schedule, such as insufficient resources
+	PipelineTaskRetryOnExitCode  PipelineTaskRetryOn = "exit-code"  // exit code matched `exit_codes`
+)
+
+// PipelineTaskRetryOns all valid retry on values declared in pipeline.yml
+var PipelineTaskRetryOns = []PipelineTaskRetryOn{
+	PipelineTaskRetryOnFailed,
+	PipelineTaskRetryOnTimeout,
+	PipelineTaskRetryOnError,
+	PipelineTaskRetryOnImagePull,
+	PipelineTaskRetryOnScheduler,
+}
+
+// errKeywords used to classify task errors, match both k8s event reason and executor analyzed messages
+var (
+	pipelineTaskImagePullErrKeywords = []string{"ErrImagePull", "ImagePullBackOff", "InvalidImageName", "ErrImageNeverPull",
+		"Failed to pull image", "拉取镜像失败", "无效的镜像名", "永不拉取镜像"}
+	pipelineTaskSchedulerErrKeywords = []string{"FailedScheduling", "Insufficient cpu", "Insufficient memory",
+		"didn't match node selector", "节点标签不匹配", "CPU 资源不足", "内存资源不足"}
+)
+
+// PipelineDefaultTaskRetryBackoff default backoff for task retry
+var PipelineDefaultTaskRetryBackoff = PipelineTaskRetryBackoff{
+	IntervalSec:     10,
+	DeclineRatio:    2,
+	DeclineLimitSec: 300,
+}
+
+// PipelineTaskRetry declared in pipeline.yml action.
+//
+// example:
+//
+//	retry:
+//	  max_attempts: 3
+//	  backoff:
+//	    interval_sec: 10
+//	    decline_ratio: 2
+//	    decline_limit_sec: 60
+//	  exit_codes: [137]
+//	  on: [image-pull, scheduler]
+type PipelineTaskRetry struct {
+	MaxAttempts uint64                    `json:"max_attempts" yaml:"max_attempts"`                 // include the first attempt
+	Backoff     *PipelineTaskRetryBackoff `json:"backoff,omitempty" yaml:"backoff,omitempty"`       // backoff before next attempt
+	ExitCodes   []int                     `json:"exit_codes,omitempty" yaml:"exit_codes,omitempty"` // retry when exit code matched
+	On          []PipelineTaskRetryOn     `json:"on,omitempty" yaml:"on,omitempty"`                 // retry when error class matched
+}
+
+type PipelineTaskRetryBackoff struct {
+	IntervalSec     uint64  `json:"interval_sec,omitempty" yaml:"interval_sec,omitempty"`
+	DeclineRatio    float64 `json:"decline_ratio,omitempty" yaml:"decline_ratio,omitempty"`
+	DeclineLimitSec int64   `json:"decline_limit_sec,omitempty" yaml:"decline_limit_sec,omitempty"`
+}
+
+// PipelineTaskRetryOptions stored in task extra.
+type PipelineTaskRetryOptions struct {
+	Retry    *PipelineTaskRetry     `json:"retry,omitempty"`    // retry config declared in pipeline.yml
+	Attempt  uint64                 `json:"attempt,omitempty"`  // current attempt, begin from 1
+	Attempts []*PipelineTaskAttempt `json:"attempts,omitempty"` // finished attempts which were retried
+}
+
+// PipelineTaskAttempt represents a finished attempt of task.
+type PipelineTaskAttempt struct {
+	Attempt     uint64                     `json:"attempt"`
+	Status      PipelineStatus             `json:"status"`
+	RetryOn     PipelineTaskRetryOn        `json:"retryOn"`            // why this attempt is retried
+	LogID       string                     `json:"logID"`              // use to query logs of this attempt
+	ExitCode    *int                       `json:"exitCode,omitempty"` // reported by action agent
+	Errors      []*PipelineTaskErrResponse `json:"errors,omitempty"`
+	CostTimeSec int64                      `json:"costTimeSec"`
+	TimeBegin   time.Time                  `json:"timeBegin"`
+	TimeEnd     time.Time                  `json:"timeEnd"`
+}
+
+// Validate check retry config.
+func (r *PipelineTaskRetry) Validate() error {
+	if r == nil {
+		return nil
+	}
+	if r.MaxAttempts < TaskAttemptBegin || r.MaxAttempts > PipelineTaskRetryMaxAttemptsLimit {
+		return fmt.Errorf("invalid retry max_attempts: %d (must between %d and %d)",
+			r.MaxAttempts, TaskAttemptBegin, PipelineTaskRetryMaxAttemptsLimit)
+	}
+	for _, on := range r.On {
+		var valid bool
+		for _, validOn := range PipelineTaskRetryOns {
+			if on == validOn {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid retry on: %s (valid: %v)", on, PipelineTaskRetryOns)
+		}
+	}
+	if r.Backoff != nil && (r.Backoff.DeclineRatio < 0 || r.Backoff.DeclineLimitSec < 0) {
+		return fmt.Errorf("invalid retry backoff: %+v", *r.Backoff)
+	}
+	return nil
+}
+
+// GetBackoff return declared backoff with default values filled.
+func (r *PipelineTaskRetry) GetBackoff() PipelineTaskRetryBackoff {
+	backoff := PipelineDefaultTaskRetryBackoff
+	if r.Backoff == nil {
+		return backoff
+	}
+	if r.Backoff.IntervalSec > 0 {
+		backoff.IntervalSec = r.Backoff.IntervalSec
+	}
+	if r.Backoff.DeclineRatio > 0 {
+		backoff.DeclineRatio = r.Backoff.DeclineRatio
+	}
+	if r.Backoff.DeclineLimitSec > 0 {
+		backoff.DeclineLimitSec = r.Backoff.DeclineLimitSec
+	}
+	return backoff
+}
+
+// Match return whether the finished task can retry and the matched reason.
+// If neither `exit_codes` nor `on` declared, task retry on any failure except stopped by user.
+func (r *PipelineTaskRetry) Match(status PipelineStatus, exitCode *int, errMsgs []string) (PipelineTaskRetryOn, bool) {
+	if r == nil || !status.IsFailedStatus() {
+		return "", false
+	}
+	// user or system stopped, don't retry
+	if status == PipelineStatusStopByUser || status == PipelineStatusNoNeedBySystem || status == PipelineStatusAnalyzeFailed {
+		return "", false
+	}
+
+	// classify by errors first, image-pull and scheduler errors usually end with status Failed or Timeout
+	errClass := classifyPipelineTaskErrors(errMsgs)
+	statusClass := PipelineTaskRetryOnError
+	switch status {
+	case PipelineStatusFailed:
+		statusClass = PipelineTaskRetryOnFailed
+	case PipelineStatusTimeout:
+		statusClass = PipelineTaskRetryOnTimeout
+	}
+
+	if len(r.On) == 0 && len(r.ExitCodes) == 0 {
+		if errClass != "" {
+			return errClass, true
+		}
+		return statusClass, true
+	}
+
+	if exitCode != nil {
+		for _, code := range r.ExitCodes {
+			if code == *exitCode {
+				return PipelineTaskRetryOnExitCode, true
+			}
+		}
+	}
+	for _, on := range r.On {
+		if on == errClass || on == statusClass {
+			return on, true
+		}
+	}
+	return "", false
+}
+
+func classifyPipelineTaskErrors(errMsgs []string) PipelineTaskRetryOn {
+	for _, msg := range errMsgs {
+		for _, keyword := range pipelineTaskImagePullErrKeywords {
+			if strings.Contains(msg, keyword) {
+				return PipelineTaskRetryOnImagePull
+			}
+		}
+		for _, keyword := range pipelineTaskSchedulerErrKeywords {
+			if strings.Contains(msg, keyword) {
+				return PipelineTaskRetryOnScheduler
+			}
+		}
+	}
+	return ""
+}
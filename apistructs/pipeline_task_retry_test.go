@@ -0,0 +1,69 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineTaskRetry_Validate(t *testing.T) {
+	assert.NoError(t, (*PipelineTaskRetry)(nil).Validate())
+	assert.NoError(t, (&PipelineTaskRetry{MaxAttempts: 3, On: []PipelineTaskRetryOn{PipelineTaskRetryOnImagePull}}).Validate())
+	assert.Error(t, (&PipelineTaskRetry{MaxAttempts: 0}).Validate())
+	assert.Error(t, (&PipelineTaskRetry{MaxAttempts: PipelineTaskRetryMaxAttemptsLimit + 1}).Validate())
+	assert.Error(t, (&PipelineTaskRetry{MaxAttempts: 2, On: []PipelineTaskRetryOn{"unknown"}}).Validate())
+	assert.Error(t, (&PipelineTaskRetry{MaxAttempts: 2, Backoff: &PipelineTaskRetryBackoff{DeclineRatio: -1}}).Validate())
+}
+
+func TestPipelineTaskRetry_GetBackoff(t *testing.T) {
+	r := PipelineTaskRetry{MaxAttempts: 2}
+	assert.Equal(t, PipelineDefaultTaskRetryBackoff, r.GetBackoff())
+
+	r.Backoff = &PipelineTaskRetryBackoff{IntervalSec: 5}
+	backoff := r.GetBackoff()
+	assert.Equal(t, uint64(5), backoff.IntervalSec)
+	assert.Equal(t, PipelineDefaultTaskRetryBackoff.DeclineRatio, backoff.DeclineRatio)
+	assert.Equal(t, PipelineDefaultTaskRetryBackoff.DeclineLimitSec, backoff.DeclineLimitSec)
+}
+
+func TestPipelineTaskRetry_Match(t *testing.T) {
+	exitCode := 137
+
+	// retry on any failure by default
+	r := PipelineTaskRetry{MaxAttempts: 3}
+	on, match := r.Match(PipelineStatusFailed, nil, nil)
+	assert.True(t, match)
+	assert.Equal(t, PipelineTaskRetryOnFailed, on)
+	on, match = r.Match(PipelineStatusStartError, nil, []string{"Back-off pulling image: ErrImagePull"})
+	assert.True(t, match)
+	assert.Equal(t, PipelineTaskRetryOnImagePull, on)
+	_, match = r.Match(PipelineStatusSuccess, nil, nil)
+	assert.False(t, match)
+	_, match = r.Match(PipelineStatusStopByUser, nil, nil)
+	assert.False(t, match)
+
+	// retry only on declared classes
+	r = PipelineTaskRetry{MaxAttempts: 3, ExitCodes: []int{137}, On: []PipelineTaskRetryOn{PipelineTaskRetryOnScheduler}}
+	_, match = r.Match(PipelineStatusFailed, nil, nil)
+	assert.False(t, match)
+	on, match = r.Match(PipelineStatusFailed, &exitCode, nil)
+	assert.True(t, match)
+	assert.Equal(t, PipelineTaskRetryOnExitCode, on)
+	on, match = r.Match(PipelineStatusTimeout, nil, []string{"0/3 nodes are available: 3 Insufficient memory."})
+	assert.True(t, match)
+	assert.Equal(t, PipelineTaskRetryOnScheduler, on)
+}
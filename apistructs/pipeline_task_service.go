@@ -0,0 +1,160 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+const (
+	// PipelineTaskServiceMaxCount limit the count of services of one task
+	PipelineTaskServiceMaxCount = 5
+	// PipelineTaskServiceNameMaxLength service name is used as hostname and part of k8s resource name
+	PipelineTaskServiceNameMaxLength = 24
+
+	PipelineTaskServiceDefaultCPU            = 0.5
+	PipelineTaskServiceDefaultMem            = 512 // unit: MB
+	PipelineTaskServiceDefaultPeriodSec      = 3
+	PipelineTaskServiceDefaultWaitTimeoutSec = 300
+)
+
+var pipelineTaskServiceNameRegexp = regexp.MustCompile(`^[a-z]([-a-z0-9]*[a-z0-9])?$`)
+
+// PipelineTaskService is a service container (sidecar) started alongside the task, such as mysql or redis for integration test.
+// The task can access the service by hostname, which is the name of service.
+//
+// example:
+//
+//	services:
+//	  mysql:
+//	    image: mysql:5.7
+//	    envs:
+//	      MYSQL_ROOT_PASSWORD: root
+//	    ports: [3306]
+//	    health_check:
+//	      exec: mysqladmin ping -uroot -proot
+type PipelineTaskService struct {
+	Image       string                          `json:"image" yaml:"image"`
+	Cmd         string                          `json:"cmd,omitempty" yaml:"cmd,omitempty"`
+	Envs        map[string]string               `json:"envs,omitempty" yaml:"envs,omitempty"`
+	Ports       []int                           `json:"ports,omitempty" yaml:"ports,omitempty"`
+	Resources   PipelineTaskServiceResources    `json:"resources,omitempty" yaml:"resources,omitempty"`
+	HealthCheck *PipelineTaskServiceHealthCheck `json:"health_check,omitempty" yaml:"health_check,omitempty"`
+}
+
+type PipelineTaskServiceResources struct {
+	CPU float64 `json:"cpu,omitempty" yaml:"cpu,omitempty"`
+	Mem int     `json:"mem,omitempty" yaml:"mem,omitempty"` // unit: MB
+}
+
+// PipelineTaskServiceHealthCheck only one of exec, tcp_port and http_port can be declared.
+// If not declared, check tcp of the first port.
+type PipelineTaskServiceHealthCheck struct {
+	Exec     string `json:"exec,omitempty" yaml:"exec,omitempty"` // executed by `sh -c`
+	TCPPort  int    `json:"tcp_port,omitempty" yaml:"tcp_port,omitempty"`
+	HTTPPort int    `json:"http_port,omitempty" yaml:"http_port,omitempty"`
+	HTTPPath string `json:"http_path,omitempty" yaml:"http_path,omitempty"`
+
+	InitialDelaySec int32 `json:"initial_delay_sec,omitempty" yaml:"initial_delay_sec,omitempty"`
+	PeriodSec       int32 `json:"period_sec,omitempty" yaml:"period_sec,omitempty"`
+	// WaitTimeoutSec is the max time to wait for service healthy, task will be failed if service is still unhealthy
+	WaitTimeoutSec int64 `json:"wait_timeout_sec,omitempty" yaml:"wait_timeout_sec,omitempty"`
+}
+
+// ValidatePipelineTaskServices check services declared in action.
+func ValidatePipelineTaskServices(services map[string]*PipelineTaskService) error {
+	if len(services) > PipelineTaskServiceMaxCount {
+		return fmt.Errorf("too many services: %d (max: %d)", len(services), PipelineTaskServiceMaxCount)
+	}
+	for _, name := range GetPipelineTaskServiceNames(services) {
+		if err := services[name].Validate(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPipelineTaskServiceNames return sorted service names.
+func GetPipelineTaskServiceNames(services map[string]*PipelineTaskService) []string {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate check service config.
+func (s *PipelineTaskService) Validate(name string) error {
+	if len(name) > PipelineTaskServiceNameMaxLength || !pipelineTaskServiceNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid service name: %s (must be lowercase letters, digits and '-', max length: %d)",
+			name, PipelineTaskServiceNameMaxLength)
+	}
+	if s == nil || s.Image == "" {
+		return fmt.Errorf("service %s missing image", name)
+	}
+	for _, port := range s.Ports {
+		if port <= 0 || port > 65535 {
+			return fmt.Errorf("service %s has invalid port: %d", name, port)
+		}
+	}
+	if s.Resources.CPU < 0 || s.Resources.Mem < 0 {
+		return fmt.Errorf("service %s has invalid resources: %+v", name, s.Resources)
+	}
+	if hc := s.HealthCheck; hc != nil {
+		var declared int
+		if hc.Exec != "" {
+			declared++
+		}
+		if hc.TCPPort != 0 {
+			declared++
+		}
+		if hc.HTTPPort != 0 {
+			declared++
+		}
+		if declared > 1 {
+			return fmt.Errorf("service %s health_check can only declare one of exec, tcp_port and http_port", name)
+		}
+		if hc.TCPPort < 0 || hc.TCPPort > 65535 || hc.HTTPPort < 0 || hc.HTTPPort > 65535 {
+			return fmt.Errorf("service %s health_check has invalid port", name)
+		}
+		if hc.InitialDelaySec < 0 || hc.PeriodSec < 0 || hc.WaitTimeoutSec < 0 {
+			return fmt.Errorf("service %s health_check has negative seconds", name)
+		}
+	}
+	return nil
+}
+
+// GetResources return resources with default values filled.
+func (s *PipelineTaskService) GetResources() PipelineTaskServiceResources {
+	resources := s.Resources
+	if resources.CPU <= 0 {
+		resources.CPU = PipelineTaskServiceDefaultCPU
+	}
+	if resources.Mem <= 0 {
+		resources.Mem = PipelineTaskServiceDefaultMem
+	}
+	return resources
+}
+
+// GetWaitTimeoutSec return the max time to wait for service healthy.
+func (s *PipelineTaskService) GetWaitTimeoutSec() int64 {
+	if s.HealthCheck == nil || s.HealthCheck.WaitTimeoutSec <= 0 {
+		return PipelineTaskServiceDefaultWaitTimeoutSec
+	}
+	return s.HealthCheck.WaitTimeoutSec
+}
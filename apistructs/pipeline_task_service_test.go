@@ -0,0 +1,49 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePipelineTaskServices(t *testing.T) {
+	assert.NoError(t, ValidatePipelineTaskServices(map[string]*PipelineTaskService{
+		"mysql": {Image: "mysql:5.7", Ports: []int{3306}},
+		"redis": {Image: "redis", HealthCheck: &PipelineTaskServiceHealthCheck{TCPPort: 6379}},
+	}))
+	// invalid name
+	assert.Error(t, ValidatePipelineTaskServices(map[string]*PipelineTaskService{"My_SQL": {Image: "mysql"}}))
+	// missing image
+	assert.Error(t, ValidatePipelineTaskServices(map[string]*PipelineTaskService{"mysql": {}}))
+	assert.Error(t, ValidatePipelineTaskServices(map[string]*PipelineTaskService{"mysql": nil}))
+	// invalid port
+	assert.Error(t, ValidatePipelineTaskServices(map[string]*PipelineTaskService{"mysql": {Image: "mysql", Ports: []int{70000}}}))
+	// multiple health check
+	assert.Error(t, ValidatePipelineTaskServices(map[string]*PipelineTaskService{"mysql": {Image: "mysql",
+		HealthCheck: &PipelineTaskServiceHealthCheck{Exec: "mysqladmin ping", TCPPort: 3306}}}))
+}
+
+func TestPipelineTaskService_Defaults(t *testing.T) {
+	s := PipelineTaskService{Image: "redis"}
+	assert.Equal(t, PipelineTaskServiceResources{CPU: PipelineTaskServiceDefaultCPU, Mem: PipelineTaskServiceDefaultMem}, s.GetResources())
+	assert.Equal(t, int64(PipelineTaskServiceDefaultWaitTimeoutSec), s.GetWaitTimeoutSec())
+
+	s.Resources.Mem = 1024
+	s.HealthCheck = &PipelineTaskServiceHealthCheck{WaitTimeoutSec: 60}
+	assert.Equal(t, 1024, s.GetResources().Mem)
+	assert.Equal(t, int64(60), s.GetWaitTimeoutSec())
+}
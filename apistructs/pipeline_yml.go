@@ -107,6 +107,21 @@ type PipelineYmlAction struct {
 	Loop          *PipelineTaskLoop      `json:"loop,omitempty"`                                           // 循环执行
 	SnippetStages *SnippetStages         `json:"snippetStages,omitempty"`                                  // snippetStages snippet 展开
 	Policy        *Policy                `json:"policy,omitempty"`                                         // action execution strategy
+	Matrix        *ActionMatrix          `json:"matrix,omitempty"`                                         // matrix 定义
+	MatrixActions []*PipelineYmlAction   `json:"matrixActions,omitempty"`                                  // matrix 展开后的 actions
+	Retry         *PipelineTaskRetry     `json:"retry,omitempty"`                                          // 失败重试策略
+
+	MatrixCombination map[string]string `json:"matrixCombination,omitempty"` // matrix 展开后 action 对应的组合值
+
+	Services map[string]*PipelineTaskService `json:"services,omitempty"` // service containers started alongside the task
+}
+
+// ActionMatrix expand an action into multiple tasks by combinations
+type ActionMatrix struct {
+	Dimensions  map[string][]string `json:"dimensions,omitempty" yaml:"dimensions,omitempty"`
+	Include     []map[string]string `json:"include,omitempty" yaml:"include,omitempty"`
+	Exclude     []map[string]string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	MaxParallel int                 `json:"max_parallel,omitempty" yaml:"max_parallel,omitempty"`
 }
 
 type PolicyType string
@@ -241,8 +256,11 @@ type ActionCache struct {
 	// 缓存生成的 key 或者是用户指定的 key
 	// 用户指定的话 需要 {{basePath}}/路径/{{endPath}} 来自定义 key
 	// 用户没有指定 key 有一定的生成规则, 具体生成规则看 prepare.go 的 setActionCacheStorageAndBinds 方法
+	// 不使用上述占位符的 key 为远程缓存的 key, 如 go-{{ hashFiles "go.sum" }}, 缓存保存在对象存储中
 	Key  string `json:"key,omitempty"`
 	Path string `json:"path,omitempty"` // 指定那个目录被缓存, 只能是由 / 开始的绝对路径
+	// 远程缓存未命中 key 时, 依次使用 restoreKeys 作为前缀匹配最近使用的缓存
+	RestoreKeys []string `json:"restoreKeys,omitempty"`
 }
 
 type CronCompensator struct {
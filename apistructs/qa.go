@@ -189,6 +189,8 @@ type Test struct {
 	Error     interface{}   `json:"error" yaml:"error"`
 	SystemOut string        `json:"stdout,omitempty"`
 	SystemErr string        `json:"stderr,omitempty"`
+	// Quarantined 用例已被隔离, 失败时不阻塞
+	Quarantined bool `json:"quarantined,omitempty"`
 }
 
 type TestError struct {
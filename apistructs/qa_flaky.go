@@ -0,0 +1,124 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+import (
+	"time"
+)
+
+// FlakyTest 单元测试用例在多次执行中的不稳定性统计
+type FlakyTest struct {
+	// Key 用例唯一标识, 由 suite, classname, name 计算得到
+	Key       string `json:"key"`
+	Suite     string `json:"suite"`
+	Classname string `json:"classname"`
+	Name      string `json:"name"`
+
+	// Runs 执行次数, 不包含跳过
+	Runs   int `json:"runs"`
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+	// Flips 同一分支上相邻两次执行结果在成功与失败之间翻转的次数
+	Flips int `json:"flips"`
+	// FlakyCommits 同一 commit 上既有成功又有失败的 commit 数
+	FlakyCommits int `json:"flakyCommits"`
+	// Score 不稳定分数, 取值 [0, 1], 为翻转次数与相邻执行对数的比值
+	Score float64 `json:"score"`
+
+	LastFailedAt *time.Time `json:"lastFailedAt,omitempty"`
+	Quarantined  bool       `json:"quarantined"`
+
+	History []FlakyTestRun `json:"history,omitempty"`
+}
+
+// FlakyTestRun 用例的一次执行结果
+type FlakyTestRun struct {
+	RecordID  uint64        `json:"recordId"`
+	CommitID  string        `json:"commitId"`
+	Branch    string        `json:"branch"`
+	Status    TestStatus    `json:"status"`
+	Duration  time.Duration `json:"duration"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
+
+type FlakyTestListRequest struct {
+	AppID  uint64 `schema:"applicationId,required"`
+	Branch string `schema:"branch"`
+	// RecordLimit 参与统计的最近测试记录数, 默认 50, 最大 200
+	RecordLimit int `schema:"recordLimit"`
+	// MinScore 最小不稳定分数, 默认返回所有出现过翻转的用例
+	MinScore float64 `schema:"minScore"`
+	// Limit 返回的用例数, 默认 20
+	Limit int `schema:"limit"`
+}
+
+type FlakyTestListResponse struct {
+	Header
+	Data []FlakyTest `json:"data"`
+}
+
+type FlakyTestHistoryRequest struct {
+	AppID       uint64 `schema:"applicationId,required"`
+	Key         string `schema:"key,required"`
+	Branch      string `schema:"branch"`
+	RecordLimit int    `schema:"recordLimit"`
+}
+
+type FlakyTestHistoryResponse struct {
+	Header
+	Data *FlakyTest `json:"data"`
+}
+
+// TestQuarantine 被隔离的单元测试用例, 隔离后用例失败不再阻塞测试报告
+type TestQuarantine struct {
+	ID            uint64    `json:"id"`
+	ApplicationID uint64    `json:"applicationId"`
+	Key           string    `json:"key"`
+	Suite         string    `json:"suite"`
+	Classname     string    `json:"classname"`
+	Name          string    `json:"name"`
+	Reason        string    `json:"reason"`
+	OperatorID    string    `json:"operatorId"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type TestQuarantineCreateRequest struct {
+	ApplicationID uint64 `json:"applicationId"`
+	Suite         string `json:"suite"`
+	Classname     string `json:"classname"`
+	Name          string `json:"name"`
+	Reason        string `json:"reason"`
+
+	IdentityInfo
+}
+
+type TestQuarantineCreateResponse struct {
+	Header
+	Data *TestQuarantine `json:"data"`
+}
+
+type TestQuarantineListRequest struct {
+	AppID uint64 `schema:"applicationId,required"`
+}
+
+type TestQuarantineListResponse struct {
+	Header
+	Data []TestQuarantine `json:"data"`
+}
+
+type TestQuarantineDeleteResponse struct {
+	Header
+	Data *TestQuarantine `json:"data"`
+}
@@ -65,6 +65,7 @@ const (
 	DeploymentPhaseAddon     DeploymentPhase = "ADDON_REQUESTING"
 	DeploymentPhaseScript    DeploymentPhase = "SCRIPT_APPLYING"
 	DeploymentPhaseService   DeploymentPhase = "SERVICE_DEPLOYING"
+	DeploymentPhaseRollout   DeploymentPhase = "ROLLOUT"
 	DeploymentPhaseRegister  DeploymentPhase = "DISCOVERY_REGISTER"
 	DeploymentPhaseCompleted DeploymentPhase = "COMPLETED"
 )
@@ -102,6 +102,9 @@ type RuntimeServiceDTO struct {
 
 type RuntimeServiceDeploymentsDTO struct {
 	Replicas int `json:"replicas"`
+	// CurrentReplicas, DesiredReplicas only available when the service is autoscaled
+	CurrentReplicas int `json:"currentReplicas,omitempty"`
+	DesiredReplicas int `json:"desiredReplicas,omitempty"`
 }
 
 type RuntimeServiceResourceDTO struct {
@@ -122,6 +125,8 @@ type DeploymentStatusDTO struct {
 	// 模块错误信息
 	ModuleErrMsg map[string]string           `json:"lastMessage"`
 	Runtime      *DeploymentStatusRuntimeDTO `json:"runtime"`
+	// 金丝雀或蓝绿发布进度
+	Rollout *DeploymentRollout `json:"rollout,omitempty"`
 }
 
 // Deprecated: use RuntimeInspect api to get ServiceGroup Info
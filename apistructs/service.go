@@ -350,6 +350,11 @@ type Service struct {
 	// WorkLoad indicates the type of service，
 	//support Kubernetes workload DaemonSet(Per-Node), Statefulset and Deployment
 	WorkLoad string `json:"workLoad,omitempty"`
+	// Autoscaling horizontal pod autoscaling of the service
+	Autoscaling *diceyml.Autoscaling `json:"autoscaling,omitempty"`
+	// CurrentReplicas, DesiredReplicas replicas reported by autoscaler, only for display
+	CurrentReplicas int `json:"currentReplicas,omitempty"`
+	DesiredReplicas int `json:"desiredReplicas,omitempty"`
 
 	// ProjectServiceName means use service name with servicegroup id when create k8s service
 	ProjectServiceName string `json:"projectServiceName,omitempty"`
@@ -136,6 +136,9 @@ type WebhookUpdateRequestBody struct {
 
 	// 是否激活，如果没有该参数，默认为false
 	Active bool `json:"active"`
+
+	// 更换签名用的 secret，为空则不修改
+	Secret string `json:"secret"`
 }
 
 // WebhookUpdateResponseData WebhookUpdateResponse 的 Data
@@ -163,6 +166,94 @@ type WebhookDeleteRequest struct {
 // WebhookDeleteResponseData WebhookDeleteResponse 的 Data
 type WebhookDeleteResponseData string
 
+// WebhookListDeliveriesRequest webhook 投递记录列表
+// Path:         "/api/webhooks/<id>/deliveries",
+// BackendPath:  "/api/dice/eventbox/webhooks/<id>/deliveries",
+type WebhookListDeliveriesRequest struct {
+	// webhook ID
+	ID string `path:"id"`
+}
+
+// WebhookListDeliveriesResponse webhook 投递记录列表
+// Path:         "/api/webhooks/<id>/deliveries",
+// BackendPath:  "/api/dice/eventbox/webhooks/<id>/deliveries",
+type WebhookListDeliveriesResponse struct {
+	Header
+	Data WebhookListDeliveriesResponseData `json:"data"`
+}
+
+// WebhookListDeliveriesResponseData WebhookListDeliveriesResponse 的 Data, 按时间倒序
+type WebhookListDeliveriesResponseData []WebhookDelivery
+
+// WebhookRedeliverRequest 重新投递 webhook 事件
+// Path:         "/api/webhooks/<id>/deliveries/<deliveryID>/actions/redeliver",
+// BackendPath:  "/api/dice/eventbox/webhooks/<id>/deliveries/<deliveryID>/actions/redeliver",
+type WebhookRedeliverRequest struct {
+	// webhook ID
+	ID string `path:"id"`
+
+	// 投递记录 ID
+	DeliveryID string `path:"deliveryID"`
+}
+
+// WebhookRedeliverResponse 重新投递 webhook 事件
+// Path:         "/api/webhooks/<id>/deliveries/<deliveryID>/actions/redeliver",
+// BackendPath:  "/api/dice/eventbox/webhooks/<id>/deliveries/<deliveryID>/actions/redeliver",
+type WebhookRedeliverResponse struct {
+	Header
+	Data WebhookDelivery `json:"data"`
+}
+
+// WebhookDeliveryStatus webhook 投递状态
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryStatusPending 投递中
+	WebhookDeliveryStatusPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliveryStatusSucceeded 投递成功
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryStatusRetrying 投递失败，等待重试
+	WebhookDeliveryStatusRetrying WebhookDeliveryStatus = "retrying"
+	// WebhookDeliveryStatusDead 重试次数耗尽，不再重试，可手动重新投递
+	WebhookDeliveryStatusDead WebhookDeliveryStatus = "dead"
+)
+
+// WebhookDelivery 一次事件投递的记录
+type WebhookDelivery struct {
+	ID     string                `json:"id"`
+	HookID string                `json:"hookID"`
+	Event  string                `json:"event"`
+	URL    string                `json:"url"`
+	Status WebhookDeliveryStatus `json:"status"`
+
+	// 投递的事件内容大小，单位 byte；事件内容不记录在投递记录中，
+	// 仅在投递失败时临时保存（不超过上限），用于重试和重新投递
+	PayloadSize int `json:"payloadSize"`
+
+	// 每次尝试投递的记录
+	Attempts []WebhookDeliveryAttempt `json:"attempts"`
+
+	// 本轮已重试次数，重新投递时清零
+	Retries int `json:"retries"`
+	// 下次重试时间，仅 retrying 状态有效
+	NextRetryAt string `json:"nextRetryAt,omitempty"`
+	CreatedAt   string `json:"createdAt"`
+	UpdatedAt   string `json:"updatedAt"`
+}
+
+// WebhookDeliveryAttempt 一次投递尝试的请求与响应
+type WebhookDeliveryAttempt struct {
+	RequestHeaders map[string]string `json:"requestHeaders"`
+	ResponseStatus int               `json:"responseStatus"`
+	// 响应内容，超长时截断
+	ResponseBody string `json:"responseBody,omitempty"`
+	// 请求失败的错误信息
+	Error string `json:"error,omitempty"`
+	// 耗时，单位 ms
+	Latency     int64  `json:"latency"`
+	DeliveredAt string `json:"deliveredAt"`
+}
+
 // WebhookListEventsRequest webhook 事件列表
 // Path:         "/api/webhook-events",
 // BackendPath:  "/api/dice/eventbox/webhook_events",
@@ -187,9 +278,6 @@ type Hook struct {
 	UpdatedAt string `json:"updatedAt"`
 	CreatedAt string `json:"createdAt"`
 
-	// 用于计算后续发送的事件内容的sha值，目前没有用
-	Secret string `json:"secret"`
-
 	CreateHookRequest
 }
 
@@ -203,6 +291,9 @@ type CreateHookRequest struct {
 	URL string `json:"url"`
 	// 是否激活
 	Active bool `json:"active"`
+	// 由创建者设置，用于对发送的事件内容进行 HMAC-SHA256 签名，接收方据此校验事件来源；
+	// 为空则不签名，查询接口只返回掩码
+	Secret string `json:"secret"`
 	HookLocation
 }
 
@@ -655,3 +655,64 @@ func (b *Bundle) GetGittarCompare(after, before string, appID int64, userID stri
 
 	return &compareResponse.Data, nil
 }
+
+// ListGittarCompareCommits 分页获取两个提交之间的提交, 不计算 diff, 最多返回 limit 个, 同时返回提交总数
+func (b *Bundle) ListGittarCompareCommits(after, before string, appID int64, userID string, limit int) ([]apistructs.Commit, int, error) {
+	host, err := b.urls.Gittar()
+	if err != nil {
+		return nil, 0, err
+	}
+	hc := b.hc
+
+	const pageSize = 100
+	var commits []apistructs.Commit
+	for {
+		size := pageSize
+		if limit-len(commits) < size {
+			size = limit - len(commits)
+		}
+		var compareResponse apistructs.GittarCompareResponse
+		resp, err := hc.Get(host).
+			Header(httputil.UserHeader, userID).
+			Path(fmt.Sprintf("/app-repo/%d/compare/%s...%s/commits", appID, after, before)).
+			Param("skip", strconv.Itoa(len(commits))).
+			Param("limit", strconv.Itoa(size)).
+			Do().JSON(&compareResponse)
+		if err != nil {
+			return nil, 0, apierrors.ErrInvoke.InternalError(err)
+		}
+		if !resp.IsOK() || !compareResponse.Success {
+			return nil, 0, toAPIError(resp.StatusCode(), compareResponse.Error)
+		}
+		commits = append(commits, compareResponse.Data.Commits...)
+		if len(compareResponse.Data.Commits) == 0 || len(commits) >= compareResponse.Data.CommitsCount || len(commits) >= limit {
+			return commits, compareResponse.Data.CommitsCount, nil
+		}
+	}
+}
+
+// GetMergeRequestDetail 获取 mr 详情
+func (b *Bundle) GetMergeRequestDetail(appID int64, mrID int, userID string) (*apistructs.MergeRequestInfo, error) {
+	var (
+		host string
+		err  error
+		rsp  apistructs.GittarQueryMrDetailResponse
+	)
+	hc := b.hc
+	host, err = b.urls.Gittar()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := hc.Get(host).
+		Header(httputil.UserHeader, userID).
+		Path(fmt.Sprintf("/app-repo/%d/merge-requests/%d", appID, mrID)).
+		Do().JSON(&rsp)
+	if err != nil {
+		return nil, apierrors.ErrInvoke.InternalError(err)
+	}
+	if !resp.IsOK() || !rsp.Success {
+		return nil, toAPIError(resp.StatusCode(), rsp.Error)
+	}
+	return &rsp.Data, nil
+}
@@ -134,6 +134,49 @@ func (b *Bundle) KMSRotateKeyVersion(req apistructs.KMSRotateKeyVersionRequest)
 	return rotateResp.Data, nil
 }
 
+func (b *Bundle) KMSUpdateRotationPolicy(req apistructs.KMSUpdateRotationPolicyRequest) (*kmstypes.UpdateRotationPolicyResponse, error) {
+	host, err := b.urls.KMS()
+	if err != nil {
+		return nil, err
+	}
+	hc := b.hc
+
+	var policyResp apistructs.KMSUpdateRotationPolicyResponse
+	httpResp, err := hc.Post(host).Path("/api/kms/update-rotation-policy").
+		Header(httputil.InternalHeader, "bundle").
+		JSONBody(&req).
+		Do().JSON(&policyResp)
+	if err != nil {
+		return nil, apierrors.ErrInvoke.InternalError(err)
+	}
+	if !httpResp.IsOK() || !policyResp.Success {
+		return nil, toAPIError(httpResp.StatusCode(), policyResp.Error)
+	}
+	return policyResp.Data, nil
+}
+
+// KMSReEncrypt 将旧版本密钥加密的密文使用主版本密钥重新加密，密钥轮转后可用于更新存储的密文
+func (b *Bundle) KMSReEncrypt(req apistructs.KMSReEncryptRequest) (*kmstypes.ReEncryptResponse, error) {
+	host, err := b.urls.KMS()
+	if err != nil {
+		return nil, err
+	}
+	hc := b.hc
+
+	var reEncryptResp apistructs.KMSReEncryptResponse
+	httpResp, err := hc.Post(host).Path("/api/kms/re-encrypt").
+		Header(httputil.InternalHeader, "bundle").
+		JSONBody(&req).
+		Do().JSON(&reEncryptResp)
+	if err != nil {
+		return nil, apierrors.ErrInvoke.InternalError(err)
+	}
+	if !httpResp.IsOK() || !reEncryptResp.Success {
+		return nil, toAPIError(httpResp.StatusCode(), reEncryptResp.Error)
+	}
+	return reEncryptResp.Data, nil
+}
+
 func (b *Bundle) KMSDescribeKey(req apistructs.KMSDescribeKeyRequest) (*kmstypes.DescribeKeyResponse, error) {
 	host, err := b.urls.KMS()
 	if err != nil {
@@ -154,3 +197,112 @@ func (b *Bundle) KMSDescribeKey(req apistructs.KMSDescribeKeyRequest) (*kmstypes
 	}
 	return descResp.Data, nil
 }
+
+func (b *Bundle) KMSGetPublicKey(req apistructs.KMSGetPublicKeyRequest) (*kmstypes.PublicKey, error) {
+	host, err := b.urls.KMS()
+	if err != nil {
+		return nil, err
+	}
+	hc := b.hc
+
+	var getResp apistructs.KMSGetPublicKeyResponse
+	httpResp, err := hc.Get(host).Path("/api/kms/get-public-key").
+		Header(httputil.InternalHeader, "bundle").
+		JSONBody(&req).
+		Do().JSON(&getResp)
+	if err != nil {
+		return nil, apierrors.ErrInvoke.InternalError(err)
+	}
+	if !httpResp.IsOK() || !getResp.Success {
+		return nil, toAPIError(httpResp.StatusCode(), getResp.Error)
+	}
+	return getResp.Data, nil
+}
+
+func (b *Bundle) KMSAsymmetricEncrypt(req apistructs.KMSAsymmetricEncryptRequest) (*kmstypes.AsymmetricEncryptResponse, error) {
+	host, err := b.urls.KMS()
+	if err != nil {
+		return nil, err
+	}
+	hc := b.hc
+
+	var encryptResp apistructs.KMSAsymmetricEncryptResponse
+	httpResp, err := hc.Post(host).Path("/api/kms/asymmetric-encrypt").
+		Header(httputil.InternalHeader, "bundle").
+		JSONBody(&req).
+		Do().JSON(&encryptResp)
+	if err != nil {
+		return nil, apierrors.ErrInvoke.InternalError(err)
+	}
+	if !httpResp.IsOK() || !encryptResp.Success {
+		return nil, toAPIError(httpResp.StatusCode(), encryptResp.Error)
+	}
+	return encryptResp.Data, nil
+}
+
+func (b *Bundle) KMSAsymmetricDecrypt(req apistructs.KMSAsymmetricDecryptRequest) (*kmstypes.AsymmetricDecryptResponse, error) {
+	host, err := b.urls.KMS()
+	if err != nil {
+		return nil, err
+	}
+	hc := b.hc
+
+	var decryptResp apistructs.KMSAsymmetricDecryptResponse
+	httpResp, err := hc.Post(host).Path("/api/kms/asymmetric-decrypt").
+		Header(httputil.InternalHeader, "bundle").
+		JSONBody(&req).
+		Do().JSON(&decryptResp)
+	if err != nil {
+		return nil, apierrors.ErrInvoke.InternalError(err)
+	}
+	if !httpResp.IsOK() || !decryptResp.Success {
+		return nil, toAPIError(httpResp.StatusCode(), decryptResp.Error)
+	}
+	return decryptResp.Data, nil
+}
+
+// 典型使用场景（制品签名）：
+// 1. 使用 SIGN_VERIFY 类型的非对称密钥，在本地计算制品的摘要，调用 KMSSign 对摘要签名（messageType 为 DIGEST）
+// 2. 将签名和密钥版本随制品一并发布
+// 3. 调用 KMSVerify 验签，或使用 KMSGetPublicKey 获取的公钥在本地验签
+func (b *Bundle) KMSSign(req apistructs.KMSSignRequest) (*kmstypes.SignResponse, error) {
+	host, err := b.urls.KMS()
+	if err != nil {
+		return nil, err
+	}
+	hc := b.hc
+
+	var signResp apistructs.KMSSignResponse
+	httpResp, err := hc.Post(host).Path("/api/kms/sign").
+		Header(httputil.InternalHeader, "bundle").
+		JSONBody(&req).
+		Do().JSON(&signResp)
+	if err != nil {
+		return nil, apierrors.ErrInvoke.InternalError(err)
+	}
+	if !httpResp.IsOK() || !signResp.Success {
+		return nil, toAPIError(httpResp.StatusCode(), signResp.Error)
+	}
+	return signResp.Data, nil
+}
+
+func (b *Bundle) KMSVerify(req apistructs.KMSVerifyRequest) (*kmstypes.VerifyResponse, error) {
+	host, err := b.urls.KMS()
+	if err != nil {
+		return nil, err
+	}
+	hc := b.hc
+
+	var verifyResp apistructs.KMSVerifyResponse
+	httpResp, err := hc.Post(host).Path("/api/kms/verify").
+		Header(httputil.InternalHeader, "bundle").
+		JSONBody(&req).
+		Do().JSON(&verifyResp)
+	if err != nil {
+		return nil, apierrors.ErrInvoke.InternalError(err)
+	}
+	if !httpResp.IsOK() || !verifyResp.Success {
+		return nil, toAPIError(httpResp.StatusCode(), verifyResp.Error)
+	}
+	return verifyResp.Data, nil
+}
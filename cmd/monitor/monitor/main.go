@@ -39,6 +39,7 @@ import (
 	_ "github.com/erda-project/erda/modules/core/monitor/metric/query"
 	_ "github.com/erda-project/erda/modules/core/monitor/metric/query-example"
 	_ "github.com/erda-project/erda/modules/core/monitor/metric/query/metricq"
+	_ "github.com/erda-project/erda/modules/core/monitor/metric/storage/tsdb"
 	_ "github.com/erda-project/erda/modules/core/monitor/settings"
 	_ "github.com/erda-project/erda/modules/core/monitor/settings/retention-strategy"
 	_ "github.com/erda-project/erda/modules/core/monitor/storekit/elasticsearch/index/cleaner"
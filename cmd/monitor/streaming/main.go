@@ -31,6 +31,7 @@ import (
 	_ "github.com/erda-project/erda/modules/core/monitor/log/storage/elasticsearch"
 	_ "github.com/erda-project/erda/modules/core/monitor/metric/persist"
 	_ "github.com/erda-project/erda/modules/core/monitor/metric/storage/elasticsearch"
+	_ "github.com/erda-project/erda/modules/core/monitor/metric/storage/tsdb"
 	_ "github.com/erda-project/erda/modules/core/monitor/settings/retention-strategy"
 	_ "github.com/erda-project/erda/modules/core/monitor/storekit/elasticsearch/index/creator"
 	_ "github.com/erda-project/erda/modules/core/monitor/storekit/elasticsearch/index/initializer"
@@ -39,6 +39,9 @@ func (agent *Agent) Callback() {
 	cb := &Callback{}
 	defer func() {
 		cb.Errors = append(cb.Errors, agent.MergeErrors()...)
+		// report exit code for task retry policy
+		exitCode := agent.ExitCode
+		cb.ExitCode = &exitCode
 		if err := agent.callbackToPipelinePlatform(cb); err != nil {
 			for _, err := range cb.Errors {
 				logrus.Println(err.Msg)
@@ -94,7 +97,7 @@ func (agent *Agent) callbackToPipelinePlatform(cb *Callback) (err error) {
 	}
 
 	// 如果全部为空，则不需要回调
-	if len(cb.Metadata) == 0 && len(cb.Errors) == 0 && cb.MachineStat == nil {
+	if len(cb.Metadata) == 0 && len(cb.Errors) == 0 && cb.MachineStat == nil && cb.ExitCode == nil {
 		return nil
 	}
 
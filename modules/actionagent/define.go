@@ -57,6 +57,9 @@ type Agent struct {
 	MaxWaitingPathUnlockSec int
 
 	TextBlackList []string // enciphered data will Replaced by '******' when log output
+
+	// remoteCaches key: cache storage name
+	remoteCaches map[string]*remoteCache
 }
 
 type AgentArg struct {
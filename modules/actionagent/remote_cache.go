@@ -0,0 +1,177 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actionagent
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/actionagent/agenttool"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/pvolumes"
+	"github.com/erda-project/erda/pkg/filehelper"
+	"github.com/erda-project/erda/pkg/http/httpclient"
+)
+
+// remoteCache 记录 restore 时渲染的 key, store 时使用相同的 key 上传
+type remoteCache struct {
+	key string
+	hit bool // key 完全命中的缓存不需要重新上传
+}
+
+func (agent *Agent) remoteCacheTaskPath(action string) string {
+	return fmt.Sprintf("/api/pipelines/%d/tasks/%d/actions/%s", agent.Arg.PipelineID, agent.Arg.PipelineTaskID, action)
+}
+
+// restoreRemoteCache 渲染 key 后查询可恢复的缓存, 下载并解压到缓存目录
+func (agent *Agent) restoreRemoteCache(in apistructs.MetadataField) error {
+	cachePath := in.Labels[pvolumes.TaskCachePath]
+	key, err := pvolumes.RenderCacheKey(in.Labels[pvolumes.TaskCacheKey], agent.EasyUse.ContainerWd)
+	if err != nil {
+		return errors.Errorf("failed to render cache key, err: %v", err)
+	}
+	params := url.Values{}
+	params.Set("path", cachePath)
+	params.Set("key", key)
+	for _, restoreKey := range pvolumes.GetRemoteCacheRestoreKeys(in.Labels) {
+		rendered, err := pvolumes.RenderCacheKey(restoreKey, agent.EasyUse.ContainerWd)
+		if err != nil {
+			return errors.Errorf("failed to render cache restore key, err: %v", err)
+		}
+		params.Add("restoreKeys", rendered)
+	}
+	state := &remoteCache{key: key}
+	agent.remoteCaches[in.Name] = state
+
+	var resp apistructs.PipelineActionCacheQueryResponse
+	r, err := httpclient.New().
+		Get(agent.EasyUse.OpenAPIAddr).
+		Path(agent.remoteCacheTaskPath("query-cache")).
+		Params(params).
+		Header("Authorization", agent.EasyUse.TokenForBootstrap).
+		Do().JSON(&resp)
+	if err != nil {
+		return errors.Errorf("failed to query cache, key: %s, err: %v", key, err)
+	}
+	if !r.IsOK() || !resp.Success {
+		return errors.Errorf("failed to query cache, key: %s, statusCode: %d, err: %+v", key, r.StatusCode(), resp.Error)
+	}
+	if resp.Data == nil || resp.Data.Status == apistructs.ActionCacheStatusMiss {
+		logrus.Printf("action cache miss, path: %s, key: %s", cachePath, key)
+		return nil
+	}
+
+	tarFile, err := agent.downloadRemoteCache(cachePath, resp.Data.MatchedKey)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tarFile)
+	if err := agent.restoreCache(tarFile, cachePath); err != nil {
+		return errors.Errorf("failed to untar cache file: %s to exec dir: %s, err: %v", tarFile, cachePath, err)
+	}
+	state.hit = resp.Data.Status == apistructs.ActionCacheStatusHit
+	logrus.Printf("action cache %s, path: %s, key: %s, matched key: %s", resp.Data.Status, cachePath, key, resp.Data.MatchedKey)
+	return nil
+}
+
+func (agent *Agent) downloadRemoteCache(cachePath, key string) (string, error) {
+	body, r, err := httpclient.New().
+		Get(agent.EasyUse.OpenAPIAddr).
+		Path(agent.remoteCacheTaskPath("download-cache")).
+		Param("path", cachePath).
+		Param("key", key).
+		Header("Authorization", agent.EasyUse.TokenForBootstrap).
+		Do().StreamBody()
+	if err != nil {
+		return "", errors.Errorf("failed to download cache, key: %s, err: %v", key, err)
+	}
+	defer body.Close()
+	if !r.IsOK() {
+		bodyBytes, _ := ioutil.ReadAll(body)
+		return "", errors.Errorf("failed to download cache, key: %s, err: %s", key, string(bodyBytes))
+	}
+	tmpFile, err := ioutil.TempFile(cacheTempDir, cacheTempPrefix)
+	if err != nil {
+		return "", err
+	}
+	defer tmpFile.Close()
+	if _, err := io.Copy(tmpFile, body); err != nil {
+		os.Remove(tmpFile.Name())
+		return "", errors.Errorf("failed to download cache, key: %s, err: %v", key, err)
+	}
+	return tmpFile.Name(), nil
+}
+
+// storeRemoteCache action 执行成功且缓存未完全命中时, 压缩缓存目录并上传
+func (agent *Agent) storeRemoteCache(out apistructs.MetadataField) error {
+	cachePath := out.Labels[pvolumes.TaskCachePath]
+	state, ok := agent.remoteCaches[out.Name]
+	if !ok {
+		logrus.Printf("skip upload action cache %s, cache key not rendered", cachePath)
+		return nil
+	}
+	if state.hit {
+		logrus.Printf("skip upload action cache %s, cache key %s hit", cachePath, state.key)
+		return nil
+	}
+	if len(agent.Errs) > 0 || agent.ExitCode != 0 {
+		logrus.Printf("skip upload action cache %s, action failed", cachePath)
+		return nil
+	}
+	if filehelper.CheckExist(cachePath, true) != nil {
+		logrus.Printf("upload action cache error: %s is not dir", cachePath)
+		return nil
+	}
+
+	tmpFile, err := ioutil.TempFile(cacheTempDir, cacheTempPrefix)
+	if err != nil {
+		return err
+	}
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+	if err := agenttool.Tar(tmpFile.Name(), cachePath); err != nil {
+		return errors.Errorf("failed to tar cache path: %s, err: %v", cachePath, err)
+	}
+	f, err := os.Open(tmpFile.Name())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var resp apistructs.PipelineActionCacheUploadResponse
+	r, err := httpclient.New().
+		Put(agent.EasyUse.OpenAPIAddr).
+		Path(agent.remoteCacheTaskPath("upload-cache")).
+		Param("path", cachePath).
+		Param("key", state.key).
+		Header("Authorization", agent.EasyUse.TokenForBootstrap).
+		Header("Content-Type", "application/x-tar").
+		RawBody(f).
+		Do().JSON(&resp)
+	if err != nil {
+		return errors.Errorf("failed to upload cache, key: %s, err: %v", state.key, err)
+	}
+	if !r.IsOK() || !resp.Success {
+		return errors.Errorf("failed to upload cache, key: %s, statusCode: %d, err: %+v", state.key, r.StatusCode(), resp.Error)
+	}
+	logrus.Printf("upload action cache %s success, key: %s", cachePath, state.key)
+	return nil
+}
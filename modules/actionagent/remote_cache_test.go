@@ -0,0 +1,140 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actionagent
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/pvolumes"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+)
+
+// fakeCacheServer 模拟 pipeline 的远程缓存接口
+type fakeCacheServer struct {
+	caches  map[string][]byte
+	uploads int
+}
+
+func (s *fakeCacheServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("key")
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/actions/query-cache"):
+		result := apistructs.PipelineTaskActionCache{Key: key, Status: apistructs.ActionCacheStatusMiss}
+		if _, ok := s.caches[key]; ok {
+			result.MatchedKey, result.Status = key, apistructs.ActionCacheStatusHit
+		} else {
+			for _, restoreKey := range r.URL.Query()["restoreKeys"] {
+				for cacheKey := range s.caches {
+					if strings.HasPrefix(cacheKey, restoreKey) {
+						result.MatchedKey, result.Status = cacheKey, apistructs.ActionCacheStatusPartialHit
+					}
+				}
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(apistructs.PipelineActionCacheQueryResponse{Header: apistructs.Header{Success: true}, Data: &result})
+	case strings.HasSuffix(r.URL.Path, "/actions/download-cache"):
+		w.Write(s.caches[key])
+	case strings.HasSuffix(r.URL.Path, "/actions/upload-cache"):
+		b, _ := ioutil.ReadAll(r.Body)
+		s.caches[key] = b
+		s.uploads++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(apistructs.PipelineActionCacheUploadResponse{Header: apistructs.Header{Success: true},
+			Data: &apistructs.PipelineTaskActionCache{Key: key, SavedSize: int64(len(b))}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestRemoteCache(t *testing.T) {
+	server := &fakeCacheServer{caches: make(map[string][]byte)}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "remote-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	wd := filepath.Join(tmpDir, "wd")
+	cachePath := filepath.Join(tmpDir, "deps")
+	assert.NoError(t, os.MkdirAll(wd, 0755))
+	assert.NoError(t, os.MkdirAll(cachePath, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(wd, "go.sum"), []byte("v1"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(cachePath, "dep"), []byte("dep"), 0644))
+
+	field := apistructs.MetadataField{
+		Name: "action_cache_deps",
+		Type: string(spec.StoreTypeDiceCacheRemote),
+		Labels: map[string]string{
+			pvolumes.TaskCachePath:        cachePath,
+			pvolumes.TaskCacheKey:         `go-{{ hashFiles "go.sum" }}`,
+			pvolumes.TaskCacheRestoreKeys: "go-",
+		},
+	}
+	newAgent := func() *Agent {
+		agent := &Agent{Arg: &AgentArg{PipelineID: 1, PipelineTaskID: 2}, remoteCaches: make(map[string]*remoteCache)}
+		agent.EasyUse.OpenAPIAddr = strings.TrimPrefix(ts.URL, "http://")
+		agent.EasyUse.ContainerWd = wd
+		return agent
+	}
+
+	// 第一次执行未命中, 执行结束后上传
+	agent := newAgent()
+	assert.NoError(t, agent.restoreRemoteCache(field))
+	assert.False(t, agent.remoteCaches[field.Name].hit)
+	assert.NoError(t, agent.storeRemoteCache(field))
+	assert.Equal(t, 1, server.uploads)
+	key := agent.remoteCaches[field.Name].key
+	assert.True(t, strings.HasPrefix(key, "go-"))
+	assert.NotEqual(t, "go-", key)
+
+	// 相同 go.sum 完全命中, 恢复缓存目录且不再上传
+	assert.NoError(t, os.RemoveAll(cachePath))
+	agent = newAgent()
+	assert.NoError(t, agent.restoreRemoteCache(field))
+	assert.True(t, agent.remoteCaches[field.Name].hit)
+	b, err := ioutil.ReadFile(filepath.Join(cachePath, "dep"))
+	assert.NoError(t, err)
+	assert.Equal(t, "dep", string(b))
+	assert.NoError(t, agent.storeRemoteCache(field))
+	assert.Equal(t, 1, server.uploads)
+
+	// go.sum 变化后通过 restoreKeys 部分命中, 执行结束后以新的 key 上传
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(wd, "go.sum"), []byte("v2"), 0644))
+	agent = newAgent()
+	assert.NoError(t, agent.restoreRemoteCache(field))
+	assert.False(t, agent.remoteCaches[field.Name].hit)
+	assert.NotEqual(t, key, agent.remoteCaches[field.Name].key)
+	assert.NoError(t, agent.storeRemoteCache(field))
+	assert.Equal(t, 2, server.uploads)
+
+	// action 执行失败时不上传
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(wd, "go.sum"), []byte("v3"), 0644))
+	agent = newAgent()
+	assert.NoError(t, agent.restoreRemoteCache(field))
+	agent.ExitCode = 1
+	assert.NoError(t, agent.storeRemoteCache(field))
+	assert.Equal(t, 2, server.uploads)
+}
@@ -37,6 +37,7 @@ const (
 )
 
 func (agent *Agent) restore() {
+	agent.remoteCaches = make(map[string]*remoteCache)
 	for _, in := range agent.Arg.Context.InStorages {
 		switch in.Type {
 
@@ -82,6 +83,11 @@ func (agent *Agent) restore() {
 				continue
 			}
 			logrus.Printf("get action cache: %s success", in.Labels[pvolumes.TaskCachePath])
+		// 远程缓存恢复失败不影响 action 执行
+		case string(spec.StoreTypeDiceCacheRemote):
+			if err := agent.restoreRemoteCache(in); err != nil {
+				logrus.Printf("failed to restore action cache: %s, err: %v", in.Labels[pvolumes.TaskCachePath], err)
+			}
 		default:
 			agent.AppendError(errors.Errorf("[restore] unsupported store type: %s", in.Type))
 		}
@@ -63,6 +63,10 @@ func (agent *Agent) store() {
 				continue
 			}
 			logrus.Printf("upload action cache %s success", out.Labels[pvolumes.TaskCachePath])
+		case string(spec.StoreTypeDiceCacheRemote):
+			if err := agent.storeRemoteCache(out); err != nil {
+				logrus.Printf("failed to upload action cache: %s, err: %v", out.Labels[pvolumes.TaskCachePath], err)
+			}
 		default:
 			agent.AppendError(errors.Errorf("[store] unsupported store type: %s", out.Type))
 		}
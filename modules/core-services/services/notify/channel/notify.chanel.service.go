@@ -443,7 +443,7 @@ func (s *notifyChannelService) GetNotifyChannelsEnabled(ctx context.Context, req
 	if err != nil {
 		return nil, err
 	}
-	targetList := []string{"dingding", "webhook", "email", "mbox", "ticket"}
+	targetList := []string{"dingding", "webhook", "email", "mbox", "ticket", "slack", "teams", "feishu", "wecom"}
 	for _, v := range targetList {
 		result.Data[v] = true
 	}
@@ -130,7 +130,7 @@ var (
 		"neq":      OperatorTypeOne,
 	}
 	aggregators, aggregatorSet     = getListAndSet("sum", "avg", "max", "min", "distinct", "count", "value", "values", "distinct_count", "p99", "p95", "p90", "p75", "p50")
-	notifyTargets, notifyTargetSet = getListAndSet("dingding", "webhook", "email", "mbox", "ticket", "sms", "dingtalk_work_notice")
+	notifyTargets, notifyTargetSet = getListAndSet("dingding", "webhook", "email", "mbox", "ticket", "sms", "dingtalk_work_notice", "slack", "teams", "feishu", "wecom")
 	//notifyTargets, notifyTargetSet = getListAndSet("dingding", "webhook", "email", "mbox", "ticket", "vms", "sms")
 	notifySilences = []*struct {
 		Value int64  `json:"value"`
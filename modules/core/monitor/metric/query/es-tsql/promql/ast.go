@@ -0,0 +1,557 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package espromql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expr is node of PromQL
+type Expr interface {
+	String() string
+}
+
+// MetricNameLabel is the label of metric name
+const MetricNameLabel = "__name__"
+
+// MatchType .
+type MatchType string
+
+// MatchType values
+const (
+	MatchEqual     MatchType = "="
+	MatchNotEqual  MatchType = "!="
+	MatchRegexp    MatchType = "=~"
+	MatchNotRegexp MatchType = "!~"
+)
+
+// LabelMatcher .
+type LabelMatcher struct {
+	Name  string
+	Type  MatchType
+	Value string
+}
+
+func (m *LabelMatcher) String() string {
+	return fmt.Sprintf("%s%s%q", m.Name, m.Type, m.Value)
+}
+
+// VectorSelector select series by metric name and labels, Range is set for range vector.
+// Metric name is `<metric>:<field>`, such as `docker_container_summary:cpu_usage_percent`.
+type VectorSelector struct {
+	Metric   string
+	Field    string
+	Matchers []*LabelMatcher
+	Range    time.Duration
+	Offset   time.Duration
+}
+
+func (v *VectorSelector) String() string {
+	var sb strings.Builder
+	sb.WriteString(v.Metric)
+	if len(v.Field) > 0 {
+		sb.WriteString(":" + v.Field)
+	}
+	if len(v.Matchers) > 0 {
+		var ms []string
+		for _, m := range v.Matchers {
+			ms = append(ms, m.String())
+		}
+		sb.WriteString("{" + strings.Join(ms, ",") + "}")
+	}
+	if v.Range > 0 {
+		sb.WriteString("[" + formatDuration(v.Range) + "]")
+	}
+	if v.Offset > 0 {
+		sb.WriteString(" offset " + formatDuration(v.Offset))
+	}
+	return sb.String()
+}
+
+// Call is function call, such as rate(x[5m])
+type Call struct {
+	Func string
+	Args []Expr
+}
+
+func (c *Call) String() string {
+	var args []string
+	for _, arg := range c.Args {
+		args = append(args, arg.String())
+	}
+	return fmt.Sprintf("%s(%s)", c.Func, strings.Join(args, ", "))
+}
+
+// AggregateExpr such as sum by (host) (x)
+type AggregateExpr struct {
+	Op       string
+	Expr     Expr
+	Param    Expr
+	Grouping []string
+	Without  bool
+}
+
+func (a *AggregateExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(a.Op)
+	if len(a.Grouping) > 0 || a.Without {
+		if a.Without {
+			sb.WriteString(" without ")
+		} else {
+			sb.WriteString(" by ")
+		}
+		sb.WriteString("(" + strings.Join(a.Grouping, ", ") + ") ")
+	}
+	sb.WriteString("(")
+	if a.Param != nil {
+		sb.WriteString(a.Param.String() + ", ")
+	}
+	sb.WriteString(a.Expr.String() + ")")
+	return sb.String()
+}
+
+// BinaryExpr .
+type BinaryExpr struct {
+	Op  string
+	LHS Expr
+	RHS Expr
+}
+
+func (b *BinaryExpr) String() string {
+	return fmt.Sprintf("%s %s %s", b.LHS.String(), b.Op, b.RHS.String())
+}
+
+// ParenExpr .
+type ParenExpr struct {
+	Expr Expr
+}
+
+func (p *ParenExpr) String() string {
+	return "(" + p.Expr.String() + ")"
+}
+
+// NumberLiteral .
+type NumberLiteral struct {
+	Val float64
+}
+
+func (n *NumberLiteral) String() string {
+	return strconv.FormatFloat(n.Val, 'f', -1, 64)
+}
+
+// StringLiteral .
+type StringLiteral struct {
+	Val string
+}
+
+func (s *StringLiteral) String() string {
+	return strconv.Quote(s.Val)
+}
+
+// ParseExpr parse PromQL expression
+func ParseExpr(input string) (Expr, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	expr, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.typ != tokenEOF {
+		return nil, p.unexpected(t, "end of input")
+	}
+	return expr, nil
+}
+
+// ParseSelector parse series selector, such as match[] of /api/v1/series
+func ParseSelector(input string) (*VectorSelector, error) {
+	expr, err := ParseExpr(input)
+	if err != nil {
+		return nil, err
+	}
+	vs, ok := expr.(*VectorSelector)
+	if !ok || vs.Range > 0 {
+		return nil, fmt.Errorf("invalid series selector %q", input)
+	}
+	return vs, nil
+}
+
+// aggregation operators
+var aggregateOps = map[string]bool{
+	"sum": true, "avg": true, "min": true, "max": true, "count": true,
+	"stddev": true, "stdvar": true, "topk": true, "bottomk": true,
+	"quantile": true, "count_values": true, "group": true,
+}
+
+// binary operators and precedence
+var binaryOps = map[tokenType]int{
+	tokenADD: 1,
+	tokenSUB: 1,
+	tokenMUL: 2,
+	tokenDIV: 2,
+	tokenMOD: 2,
+	tokenPOW: 3,
+}
+
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	t := p.tokens[p.pos]
+	if t.typ != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *exprParser) expect(typ tokenType, context string) (token, error) {
+	t := p.next()
+	if t.typ != typ {
+		return t, p.unexpected(t, typ.String()+" in "+context)
+	}
+	return t, nil
+}
+
+func (p *exprParser) unexpected(t token, expected string) error {
+	val := t.val
+	if t.typ == tokenEOF {
+		val = "EOF"
+	}
+	return fmt.Errorf("unexpected %q at position %d, expected %s", val, t.pos, expected)
+}
+
+// parseExpr precedence climbing, ^ is right associative
+func (p *exprParser) parseExpr(minPrec int) (Expr, error) {
+	lhs, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		prec, ok := binaryOps[t.typ]
+		if !ok || prec < minPrec {
+			return lhs, nil
+		}
+		p.next()
+		nextPrec := prec + 1
+		if t.typ == tokenPOW {
+			nextPrec = prec
+		}
+		rhs, err := p.parseExpr(nextPrec)
+		if err != nil {
+			return nil, err
+		}
+		lhs = &BinaryExpr{Op: t.val, LHS: lhs, RHS: rhs}
+	}
+}
+
+func (p *exprParser) parseUnary() (Expr, error) {
+	t := p.peek()
+	if t.typ == tokenSUB || t.typ == tokenADD {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if t.typ == tokenADD {
+			return expr, nil
+		}
+		if n, ok := expr.(*NumberLiteral); ok {
+			n.Val = -n.Val
+			return n, nil
+		}
+		return &BinaryExpr{Op: "*", LHS: &NumberLiteral{Val: -1}, RHS: expr}, nil
+	}
+	expr, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	return p.parseModifiers(expr)
+}
+
+func (p *exprParser) parsePrimary() (Expr, error) {
+	t := p.next()
+	switch t.typ {
+	case tokenNumber:
+		v, err := strconv.ParseFloat(t.val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.val, t.pos)
+		}
+		return &NumberLiteral{Val: v}, nil
+	case tokenString:
+		return &StringLiteral{Val: t.val}, nil
+	case tokenLeftParen:
+		expr, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRightParen, "paren expression"); err != nil {
+			return nil, err
+		}
+		return &ParenExpr{Expr: expr}, nil
+	case tokenLeftBrace:
+		p.pos--
+		return p.parseVectorSelector("")
+	case tokenIdentifier:
+		if aggregateOps[t.val] && p.isAggregateFollowing() {
+			return p.parseAggregateExpr(t.val)
+		}
+		if p.peek().typ == tokenLeftParen {
+			return p.parseCall(t.val)
+		}
+		return p.parseVectorSelector(t.val)
+	}
+	return nil, p.unexpected(t, "expression")
+}
+
+func (p *exprParser) isAggregateFollowing() bool {
+	t := p.peek()
+	if t.typ == tokenLeftParen {
+		return true
+	}
+	return t.typ == tokenIdentifier && (t.val == "by" || t.val == "without")
+}
+
+func (p *exprParser) parseAggregateExpr(op string) (Expr, error) {
+	agg := &AggregateExpr{Op: op}
+	grouped := false
+	if t := p.peek(); t.typ == tokenIdentifier {
+		if err := p.parseGrouping(agg); err != nil {
+			return nil, err
+		}
+		grouped = true
+	}
+	if _, err := p.expect(tokenLeftParen, "aggregation"); err != nil {
+		return nil, err
+	}
+	expr, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().typ == tokenComma {
+		p.next()
+		agg.Param = expr
+		expr, err = p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+	}
+	agg.Expr = expr
+	if _, err := p.expect(tokenRightParen, "aggregation"); err != nil {
+		return nil, err
+	}
+	if t := p.peek(); !grouped && t.typ == tokenIdentifier && (t.val == "by" || t.val == "without") {
+		if err := p.parseGrouping(agg); err != nil {
+			return nil, err
+		}
+	}
+	return agg, nil
+}
+
+func (p *exprParser) parseGrouping(agg *AggregateExpr) error {
+	t := p.next()
+	switch t.val {
+	case "by":
+	case "without":
+		agg.Without = true
+	default:
+		return p.unexpected(t, "by or without")
+	}
+	if _, err := p.expect(tokenLeftParen, "grouping"); err != nil {
+		return err
+	}
+	for p.peek().typ != tokenRightParen {
+		label, err := p.expect(tokenIdentifier, "grouping")
+		if err != nil {
+			return err
+		}
+		agg.Grouping = append(agg.Grouping, label.val)
+		if p.peek().typ == tokenComma {
+			p.next()
+		}
+	}
+	p.next()
+	return nil
+}
+
+func (p *exprParser) parseCall(name string) (Expr, error) {
+	p.next() // (
+	call := &Call{Func: name}
+	for p.peek().typ != tokenRightParen {
+		arg, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
+		if p.peek().typ == tokenComma {
+			p.next()
+			continue
+		}
+		if p.peek().typ != tokenRightParen {
+			return nil, p.unexpected(p.peek(), ", or ) in function call")
+		}
+	}
+	p.next()
+	return call, nil
+}
+
+func (p *exprParser) parseVectorSelector(name string) (Expr, error) {
+	vs := &VectorSelector{}
+	if p.peek().typ == tokenLeftBrace {
+		p.next()
+		for p.peek().typ != tokenRightBrace {
+			label, err := p.expect(tokenIdentifier, "label matching")
+			if err != nil {
+				return nil, err
+			}
+			op := p.next()
+			var typ MatchType
+			switch op.typ {
+			case tokenEQL:
+				typ = MatchEqual
+			case tokenNEQ:
+				typ = MatchNotEqual
+			case tokenEQLRegex:
+				typ = MatchRegexp
+			case tokenNEQRegex:
+				typ = MatchNotRegexp
+			default:
+				return nil, p.unexpected(op, "label matching operator")
+			}
+			value, err := p.expect(tokenString, "label matching")
+			if err != nil {
+				return nil, err
+			}
+			if typ == MatchRegexp || typ == MatchNotRegexp {
+				if _, err := regexp.Compile(value.val); err != nil {
+					return nil, fmt.Errorf("invalid regular expression %q: %s", value.val, err)
+				}
+			}
+			if label.val == MetricNameLabel {
+				if typ != MatchEqual {
+					return nil, fmt.Errorf("only support '=' for label %s", MetricNameLabel)
+				}
+				name = value.val
+			} else {
+				vs.Matchers = append(vs.Matchers, &LabelMatcher{Name: label.val, Type: typ, Value: value.val})
+			}
+			if p.peek().typ == tokenComma {
+				p.next()
+				continue
+			}
+			if p.peek().typ != tokenRightBrace {
+				return nil, p.unexpected(p.peek(), ", or } in label matching")
+			}
+		}
+		p.next()
+	}
+	if len(name) == 0 {
+		return nil, fmt.Errorf("vector selector must contain metric name")
+	}
+	if idx := strings.Index(name, ":"); idx >= 0 {
+		vs.Metric, vs.Field = name[:idx], name[idx+1:]
+	} else {
+		vs.Metric = name
+	}
+	return vs, nil
+}
+
+// parseModifiers parse range and offset
+func (p *exprParser) parseModifiers(expr Expr) (Expr, error) {
+	if p.peek().typ == tokenLeftBracket {
+		vs, ok := expr.(*VectorSelector)
+		if !ok {
+			return nil, fmt.Errorf("range specification must be preceded by a vector selector, subquery is not supported")
+		}
+		p.next()
+		t, err := p.expect(tokenDuration, "range")
+		if err != nil {
+			return nil, err
+		}
+		if vs.Range, err = parseDuration(t.val); err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRightBracket, "range"); err != nil {
+			return nil, err
+		}
+	}
+	if t := p.peek(); t.typ == tokenIdentifier && t.val == "offset" {
+		vs, ok := expr.(*VectorSelector)
+		if !ok {
+			return nil, fmt.Errorf("offset modifier must be preceded by a vector selector")
+		}
+		p.next()
+		t, err := p.expect(tokenDuration, "offset")
+		if err != nil {
+			return nil, err
+		}
+		if vs.Offset, err = parseDuration(t.val); err != nil {
+			return nil, err
+		}
+	}
+	return expr, nil
+}
+
+var durationUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+	"y":  365 * 24 * time.Hour,
+}
+
+var durationRegexp = regexp.MustCompile(`(\d+)(ms|s|m|h|d|w|y)`)
+
+// parseDuration parse duration of PromQL, such as 5m, 1h30m
+func parseDuration(s string) (time.Duration, error) {
+	matches := durationRegexp.FindAllStringSubmatchIndex(s, -1)
+	var d time.Duration
+	var end int
+	for _, m := range matches {
+		if m[0] != end {
+			break
+		}
+		n, _ := strconv.ParseInt(s[m[2]:m[3]], 10, 64)
+		d += time.Duration(n) * durationUnits[s[m[4]:m[5]]]
+		end = m[1]
+	}
+	if end != len(s) || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+func formatDuration(d time.Duration) string {
+	for _, unit := range []string{"y", "w", "d", "h", "m", "s"} {
+		if u := durationUnits[unit]; d%u == 0 {
+			return strconv.FormatInt(int64(d/u), 10) + unit
+		}
+	}
+	return strconv.FormatInt(int64(d/time.Millisecond), 10) + "ms"
+}
@@ -0,0 +1,350 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package espromql
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Plan is the InfluxQL statement compiled from PromQL, which is executed by es-tsql influxql parser.
+// Rows of result are [time, labels..., value].
+type Plan struct {
+	Statement string
+	Metric    string
+	Field     string
+	Labels    []string
+	// Range is the max range of range vector
+	Range  time.Duration
+	Offset time.Duration
+	// NextBucket means value of bucket is computed with the next bucket, such as rate
+	NextBucket bool
+
+	// Scalar expression is evaluated without query
+	Scalar      bool
+	ScalarValue float64
+}
+
+// rangeFunctions PromQL functions on range vector -> es-tsql aggregation,
+// samples of range vector are the documents in the same time bucket.
+var rangeFunctions = map[string]string{
+	"rate":            "diffps",
+	"irate":           "diffps",
+	"increase":        "diff",
+	"delta":           "diff",
+	"avg_over_time":   "avg",
+	"min_over_time":   "min",
+	"max_over_time":   "max",
+	"sum_over_time":   "sum",
+	"count_over_time": "count",
+	"last_over_time":  "last",
+}
+
+// aggregateFunctions PromQL aggregation operators -> es-tsql aggregation
+var aggregateFunctions = map[string]string{
+	"sum":   "sum",
+	"avg":   "avg",
+	"min":   "min",
+	"max":   "max",
+	"count": "count",
+}
+
+// Compile compile PromQL expression to InfluxQL statement.
+// Only one metric is supported in an expression, binary operation can only be used between vector and scalar.
+// Aggregation and functions are evaluated by the documents in every step,
+// so that `sum(rate(x[5m])) by (host)` is the rate of x grouped by host.
+// Series of expression without aggregation are identified by labels of equal matchers.
+func Compile(expr Expr, step time.Duration) (*Plan, error) {
+	c := &compiler{}
+	value, scalar, err := c.compileValue(expr)
+	if err != nil {
+		return nil, err
+	}
+	if scalar {
+		v, err := evalScalar(expr)
+		if err != nil {
+			return nil, err
+		}
+		return &Plan{Scalar: true, ScalarValue: v}, nil
+	}
+
+	vs := c.selector
+	plan := &Plan{
+		Metric:     vs.Metric,
+		Field:      vs.Field,
+		Range:      c.maxRange,
+		Offset:     vs.Offset,
+		NextBucket: c.nextBucket,
+	}
+	if c.grouped {
+		plan.Labels = c.grouping
+	} else {
+		plan.Labels = equalLabels(vs.Matchers)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	for _, label := range plan.Labels {
+		sb.WriteString(quoteIdent(label) + "::tag, ")
+	}
+	sb.WriteString(value)
+	sb.WriteString(" FROM " + quoteIdent(vs.Metric))
+	if len(vs.Matchers) > 0 {
+		var conds []string
+		for _, m := range vs.Matchers {
+			conds = append(conds, compileMatcher(m))
+		}
+		sb.WriteString(" WHERE " + strings.Join(conds, " AND "))
+	}
+	sb.WriteString(" GROUP BY time(")
+	if step > 0 {
+		if step < time.Second {
+			step = time.Second
+		}
+		sb.WriteString(strconv.FormatInt(int64(step/time.Second), 10) + "s")
+	}
+	sb.WriteString(")")
+	for _, label := range plan.Labels {
+		sb.WriteString(", " + quoteIdent(label) + "::tag")
+	}
+	plan.Statement = sb.String()
+	return plan, nil
+}
+
+type compiler struct {
+	selector   *VectorSelector
+	maxRange   time.Duration
+	nextBucket bool
+	grouped    bool
+	grouping   []string
+}
+
+// compileValue return value expression of InfluxQL, and whether it's scalar
+func (c *compiler) compileValue(expr Expr) (string, bool, error) {
+	switch e := expr.(type) {
+	case *NumberLiteral:
+		return e.String(), true, nil
+	case *ParenExpr:
+		v, scalar, err := c.compileValue(e.Expr)
+		if err != nil {
+			return "", false, err
+		}
+		return "(" + v + ")", scalar, nil
+	case *BinaryExpr:
+		lhs, ls, err := c.compileValue(e.LHS)
+		if err != nil {
+			return "", false, err
+		}
+		rhs, rs, err := c.compileValue(e.RHS)
+		if err != nil {
+			return "", false, err
+		}
+		if ls && rs {
+			v, err := evalScalar(e)
+			if err != nil {
+				return "", false, err
+			}
+			return (&NumberLiteral{Val: v}).String(), true, nil
+		}
+		if !ls && !rs {
+			return "", false, fmt.Errorf("binary operation between two vectors is not supported")
+		}
+		if e.Op == "^" {
+			return "", false, fmt.Errorf("operator '^' is only supported between scalars")
+		}
+		return lhs + " " + e.Op + " " + rhs, false, nil
+	case *VectorSelector:
+		if e.Range > 0 {
+			return "", false, fmt.Errorf("range vector %s must be used in function", e.String())
+		}
+		if err := c.setSelector(e); err != nil {
+			return "", false, err
+		}
+		return "last(" + quoteIdent(e.Field) + "::field)", false, nil
+	case *Call:
+		fn, ok := rangeFunctions[e.Func]
+		if !ok {
+			return "", false, fmt.Errorf("function '%s' is not supported", e.Func)
+		}
+		if len(e.Args) != 1 {
+			return "", false, fmt.Errorf("function '%s' expected 1 argument, but got %d", e.Func, len(e.Args))
+		}
+		vs, ok := e.Args[0].(*VectorSelector)
+		if !ok || vs.Range <= 0 {
+			return "", false, fmt.Errorf("function '%s' expected range vector argument", e.Func)
+		}
+		if err := c.setSelector(vs); err != nil {
+			return "", false, err
+		}
+		if vs.Range > c.maxRange {
+			c.maxRange = vs.Range
+		}
+		if fn == "diff" || fn == "diffps" {
+			c.nextBucket = true
+		}
+		return fn + "(" + quoteIdent(vs.Field) + "::field)", false, nil
+	case *AggregateExpr:
+		fn, ok := aggregateFunctions[e.Op]
+		if !ok {
+			return "", false, fmt.Errorf("aggregation '%s' is not supported", e.Op)
+		}
+		if e.Without {
+			return "", false, fmt.Errorf("aggregation with 'without' clause is not supported, use 'by' instead")
+		}
+		if c.grouped {
+			return "", false, fmt.Errorf("nested aggregation is not supported")
+		}
+		c.grouped = true
+		c.grouping = e.Grouping
+		inner := e.Expr
+		for {
+			paren, ok := inner.(*ParenExpr)
+			if !ok {
+				break
+			}
+			inner = paren.Expr
+		}
+		if vs, ok := inner.(*VectorSelector); ok && vs.Range <= 0 {
+			if err := c.setSelector(vs); err != nil {
+				return "", false, err
+			}
+			return fn + "(" + quoteIdent(vs.Field) + "::field)", false, nil
+		}
+		v, scalar, err := c.compileValue(inner)
+		if err != nil {
+			return "", false, err
+		}
+		if scalar {
+			return "", false, fmt.Errorf("aggregation '%s' expected vector argument", e.Op)
+		}
+		return v, false, nil
+	case *StringLiteral:
+		return "", false, fmt.Errorf("string literal %s is not supported", e.String())
+	}
+	return "", false, fmt.Errorf("expression %s is not supported", expr.String())
+}
+
+// CompileSeries compile series selector to InfluxQL statement, which query the latest raw data of series
+func CompileSeries(vs *VectorSelector, limit int) string {
+	var sb strings.Builder
+	sb.WriteString("SELECT * FROM " + quoteIdent(vs.Metric))
+	if len(vs.Matchers) > 0 {
+		var conds []string
+		for _, m := range vs.Matchers {
+			conds = append(conds, compileMatcher(m))
+		}
+		sb.WriteString(" WHERE " + strings.Join(conds, " AND "))
+	}
+	sb.WriteString(" LIMIT " + strconv.Itoa(limit))
+	return sb.String()
+}
+
+func (c *compiler) setSelector(vs *VectorSelector) error {
+	if len(vs.Field) <= 0 {
+		return fmt.Errorf("missing field of metric '%s', metric name should be <metric>:<field>", vs.Metric)
+	}
+	if c.selector != nil {
+		if c.selector.Metric != vs.Metric || c.selector.Field != vs.Field || c.selector.Offset != vs.Offset ||
+			matchersString(c.selector.Matchers) != matchersString(vs.Matchers) {
+			return fmt.Errorf("only support one vector selector in expression")
+		}
+	}
+	c.selector = vs
+	return nil
+}
+
+func evalScalar(expr Expr) (float64, error) {
+	switch e := expr.(type) {
+	case *NumberLiteral:
+		return e.Val, nil
+	case *ParenExpr:
+		return evalScalar(e.Expr)
+	case *BinaryExpr:
+		lhs, err := evalScalar(e.LHS)
+		if err != nil {
+			return 0, err
+		}
+		rhs, err := evalScalar(e.RHS)
+		if err != nil {
+			return 0, err
+		}
+		switch e.Op {
+		case "+":
+			return lhs + rhs, nil
+		case "-":
+			return lhs - rhs, nil
+		case "*":
+			return lhs * rhs, nil
+		case "/":
+			return lhs / rhs, nil
+		case "%":
+			return math.Mod(lhs, rhs), nil
+		case "^":
+			return math.Pow(lhs, rhs), nil
+		}
+	}
+	return 0, fmt.Errorf("expression %s is not scalar", expr.String())
+}
+
+func compileMatcher(m *LabelMatcher) string {
+	key := quoteIdent(m.Name) + "::tag"
+	switch m.Type {
+	case MatchNotEqual:
+		return key + " != " + quoteString(m.Value)
+	case MatchRegexp:
+		return key + " =~ " + quoteRegexp(m.Value)
+	case MatchNotRegexp:
+		return key + " !~ " + quoteRegexp(m.Value)
+	}
+	return key + " = " + quoteString(m.Value)
+}
+
+func equalLabels(matchers []*LabelMatcher) []string {
+	set := make(map[string]bool)
+	var labels []string
+	for _, m := range matchers {
+		if m.Type == MatchEqual && !set[m.Name] {
+			set[m.Name] = true
+			labels = append(labels, m.Name)
+		}
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+func matchersString(matchers []*LabelMatcher) string {
+	var list []string
+	for _, m := range matchers {
+		list = append(list, m.String())
+	}
+	sort.Strings(list)
+	return strings.Join(list, ",")
+}
+
+func quoteIdent(s string) string {
+	return `"` + strings.Replace(strings.Replace(s, `\`, `\\`, -1), `"`, `\"`, -1) + `"`
+}
+
+func quoteString(s string) string {
+	return `'` + strings.Replace(strings.Replace(s, `\`, `\\`, -1), `'`, `\'`, -1) + `'`
+}
+
+func quoteRegexp(s string) string {
+	return `/` + strings.Replace(s, `/`, `\/`, -1) + `/`
+}
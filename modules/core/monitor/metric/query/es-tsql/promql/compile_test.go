@@ -0,0 +1,169 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package espromql
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	tsql "github.com/erda-project/erda/modules/core/monitor/metric/query/es-tsql"
+)
+
+func TestParseExpr(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: `cpu:usage`, want: `cpu:usage`},
+		{input: `cpu:usage{host="a", cluster=~'c.*'}`, want: `cpu:usage{host="a",cluster=~"c.*"}`},
+		{input: `{__name__="cpu:usage", host!="a"}`, want: `cpu:usage{host!="a"}`},
+		{input: `rate(req:count[5m] offset 1h)`, want: `rate(req:count[5m] offset 1h)`},
+		{input: `sum by (host) (rate(req:count[1m30s]))`, want: `sum by (host) (rate(req:count[90s]))`},
+		{input: `sum(rate(req:count[5m])) by (host, path) * 100`, want: `sum by (host, path) (rate(req:count[5m])) * 100`},
+		{input: `1 + 2 * 3`, want: `1 + 2 * 3`},
+		{input: `-cpu:usage`, want: `-1 * cpu:usage`},
+	}
+	for _, tt := range tests {
+		expr, err := ParseExpr(tt.input)
+		if !assert.NoError(t, err, tt.input) {
+			continue
+		}
+		assert.Equal(t, tt.want, expr.String(), tt.input)
+	}
+
+	for _, input := range []string{`cpu:usage{host=}`, `rate(cpu:usage[5x])`, `sum(cpu:usage`, `cpu:usage{host=~"("}`, `(1 + 2)[5m]`} {
+		_, err := ParseExpr(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		input      string
+		step       time.Duration
+		want       string
+		labels     []string
+		nextBucket bool
+	}{
+		{
+			input:  `cpu:usage{host="a", cluster=~"c.*"}`,
+			step:   time.Minute,
+			want:   `SELECT "host"::tag, last("usage"::field) FROM "cpu" WHERE "host"::tag = 'a' AND "cluster"::tag =~ /c.*/ GROUP BY time(60s), "host"::tag`,
+			labels: []string{"host"},
+		},
+		{
+			input:      `sum(rate(req:count{path!="/health"}[5m])) by (host) * 8`,
+			step:       30 * time.Second,
+			want:       `SELECT "host"::tag, diffps("count"::field) * 8 FROM "req" WHERE "path"::tag != '/health' GROUP BY time(30s), "host"::tag`,
+			labels:     []string{"host"},
+			nextBucket: true,
+		},
+		{
+			input: `avg(cpu:usage)`,
+			want:  `SELECT avg("usage"::field) FROM "cpu" GROUP BY time()`,
+		},
+		{
+			input: `max_over_time(cpu:usage{host!~"a|b"}[10m]) / (2 + 3)`,
+			step:  time.Minute,
+			want:  `SELECT max("usage"::field) / (5) FROM "cpu" WHERE "host"::tag !~ /a|b/ GROUP BY time(60s)`,
+		},
+	}
+	for _, tt := range tests {
+		expr, err := ParseExpr(tt.input)
+		if !assert.NoError(t, err, tt.input) {
+			continue
+		}
+		plan, err := Compile(expr, tt.step)
+		if !assert.NoError(t, err, tt.input) {
+			continue
+		}
+		assert.Equal(t, tt.want, plan.Statement, tt.input)
+		assert.Equal(t, tt.labels, plan.Labels, tt.input)
+		assert.Equal(t, tt.nextBucket, plan.NextBucket, tt.input)
+	}
+
+	expr, _ := ParseExpr(`(1 + 2) * 3`)
+	plan, err := Compile(expr, 0)
+	assert.NoError(t, err)
+	assert.True(t, plan.Scalar)
+	assert.Equal(t, float64(9), plan.ScalarValue)
+
+	for _, input := range []string{
+		`cpu`, `cpu:usage + mem:usage`, `topk(3, cpu:usage)`, `sum without (host) (cpu:usage)`,
+		`rate(cpu:usage)`, `cpu:usage[5m]`, `histogram_quantile(0.9, cpu:usage)`, `sum(sum(cpu:usage))`,
+	} {
+		expr, err := ParseExpr(input)
+		if !assert.NoError(t, err, input) {
+			continue
+		}
+		_, err = Compile(expr, time.Minute)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestParser(t *testing.T) {
+	start, end := int64(1600000000000000000), int64(1600003600000000000)
+	parser := tsql.New(start, end, "promql", `sum(rate(req:count{host="a"}[5m] offset 1h)) by (path)`).
+		SetParams(map[string]interface{}{StepKey: "60"})
+	qs, err := parser.ParseQuery()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(qs))
+	assert.Equal(t, "req", qs[0].Sources()[0].Name)
+
+	source, err := qs[0].SearchSource().Source()
+	assert.NoError(t, err)
+	byts, _ := json.Marshal(source)
+	body := string(byts)
+	assert.Contains(t, body, `"interval":60000000000`)
+	assert.Contains(t, body, `"tags.path"`)
+	assert.Contains(t, body, `{"term":{"tags.host":"a"}}`)
+	// offset 1h
+	assert.Contains(t, body, `"from":1599996400000000000`)
+
+	_, err = tsql.New(start, end, "promql", `cpu:usage`).SetParams(map[string]interface{}{StepKey: "-1"}).ParseQuery()
+	assert.Error(t, err)
+}
+
+func TestParseStep(t *testing.T) {
+	d, err := ParseStep("15")
+	assert.NoError(t, err)
+	assert.Equal(t, 15*time.Second, d)
+	d, err = ParseStep("1.5")
+	assert.NoError(t, err)
+	assert.Equal(t, 1500*time.Millisecond, d)
+	d, err = ParseStep("1m30s")
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Second, d)
+	_, err = ParseStep("abc")
+	assert.Error(t, err)
+	_, err = ParseStep("0.5")
+	assert.Error(t, err)
+	_, err = ParseStep("10ms")
+	assert.Error(t, err)
+	_, err = ParseStep("0s")
+	assert.Error(t, err)
+}
+
+func TestCheckResolution(t *testing.T) {
+	start := time.Unix(1600000000, 0)
+	assert.NoError(t, CheckResolution(start, start, time.Second))
+	assert.NoError(t, CheckResolution(start, start.Add(MaxPointsPerSeries*time.Second), time.Second))
+	assert.Error(t, CheckResolution(start, start.Add((MaxPointsPerSeries+1)*time.Second), time.Second))
+	assert.NoError(t, CheckResolution(start, start.Add(30*24*time.Hour), 5*time.Minute))
+	assert.Error(t, CheckResolution(start, start.Add(365*24*time.Hour), time.Minute))
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package espromql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenIdentifier
+	tokenNumber
+	tokenDuration
+	tokenString
+	tokenLeftBrace
+	tokenRightBrace
+	tokenLeftParen
+	tokenRightParen
+	tokenLeftBracket
+	tokenRightBracket
+	tokenComma
+	tokenEQL
+	tokenNEQ
+	tokenEQLRegex
+	tokenNEQRegex
+	tokenADD
+	tokenSUB
+	tokenMUL
+	tokenDIV
+	tokenMOD
+	tokenPOW
+)
+
+var tokenStrings = map[tokenType]string{
+	tokenEOF:          "EOF",
+	tokenLeftBrace:    "{",
+	tokenRightBrace:   "}",
+	tokenLeftParen:    "(",
+	tokenRightParen:   ")",
+	tokenLeftBracket:  "[",
+	tokenRightBracket: "]",
+	tokenComma:        ",",
+	tokenEQL:          "=",
+	tokenNEQ:          "!=",
+	tokenEQLRegex:     "=~",
+	tokenNEQRegex:     "!~",
+	tokenADD:          "+",
+	tokenSUB:          "-",
+	tokenMUL:          "*",
+	tokenDIV:          "/",
+	tokenMOD:          "%",
+	tokenPOW:          "^",
+}
+
+func (t tokenType) String() string {
+	if s, ok := tokenStrings[t]; ok {
+		return s
+	}
+	switch t {
+	case tokenIdentifier:
+		return "identifier"
+	case tokenNumber:
+		return "number"
+	case tokenDuration:
+		return "duration"
+	case tokenString:
+		return "string"
+	}
+	return fmt.Sprintf("token(%d)", int(t))
+}
+
+type token struct {
+	typ tokenType
+	val string
+	pos int
+}
+
+// lex split PromQL into tokens
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '#': // comment
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case isIdentifierStart(c):
+			start := i
+			for i < len(runes) && isIdentifierChar(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{typ: tokenIdentifier, val: string(runes[start:i]), pos: start})
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			// exponent
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') &&
+				i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '+' || runes[i+1] == '-') {
+				i += 2
+				for i < len(runes) && unicode.IsDigit(runes[i]) {
+					i++
+				}
+				tokens = append(tokens, token{typ: tokenNumber, val: string(runes[start:i]), pos: start})
+				continue
+			}
+			// duration, such as 5m, 1h30m
+			if i < len(runes) && isDurationUnit(runes[i]) {
+				for i < len(runes) && (unicode.IsDigit(runes[i]) || isDurationUnit(runes[i])) {
+					i++
+				}
+				tokens = append(tokens, token{typ: tokenDuration, val: string(runes[start:i]), pos: start})
+				continue
+			}
+			tokens = append(tokens, token{typ: tokenNumber, val: string(runes[start:i]), pos: start})
+		case c == '"' || c == '\'' || c == '`':
+			start := i
+			i++
+			for i < len(runes) && runes[i] != c {
+				if runes[i] == '\\' && c != '`' {
+					i++
+				}
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated quoted string at position %d", start)
+			}
+			i++
+			val, err := unquote(string(runes[start:i]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %s", start, err)
+			}
+			tokens = append(tokens, token{typ: tokenString, val: val, pos: start})
+		default:
+			typ, n := lexOperator(runes[i:])
+			if n == 0 {
+				return nil, fmt.Errorf("unexpected character '%c' at position %d", c, i)
+			}
+			tokens = append(tokens, token{typ: typ, val: string(runes[i : i+n]), pos: i})
+			i += n
+		}
+	}
+	tokens = append(tokens, token{typ: tokenEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+func lexOperator(runes []rune) (tokenType, int) {
+	if len(runes) >= 2 {
+		switch string(runes[:2]) {
+		case "!=":
+			return tokenNEQ, 2
+		case "=~":
+			return tokenEQLRegex, 2
+		case "!~":
+			return tokenNEQRegex, 2
+		}
+	}
+	switch runes[0] {
+	case '{':
+		return tokenLeftBrace, 1
+	case '}':
+		return tokenRightBrace, 1
+	case '(':
+		return tokenLeftParen, 1
+	case ')':
+		return tokenRightParen, 1
+	case '[':
+		return tokenLeftBracket, 1
+	case ']':
+		return tokenRightBracket, 1
+	case ',':
+		return tokenComma, 1
+	case '=':
+		return tokenEQL, 1
+	case '+':
+		return tokenADD, 1
+	case '-':
+		return tokenSUB, 1
+	case '*':
+		return tokenMUL, 1
+	case '/':
+		return tokenDIV, 1
+	case '%':
+		return tokenMOD, 1
+	case '^':
+		return tokenPOW, 1
+	}
+	return tokenEOF, 0
+}
+
+func unquote(s string) (string, error) {
+	if s[0] == '`' {
+		return s[1 : len(s)-1], nil
+	}
+	if s[0] == '\'' {
+		s = `"` + strings.Replace(strings.Replace(s[1:len(s)-1], `\'`, `'`, -1), `"`, `\"`, -1) + `"`
+	}
+	return strconv.Unquote(s)
+}
+
+func isIdentifierStart(c rune) bool {
+	return c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentifierChar(c rune) bool {
+	return isIdentifierStart(c) || (c >= '0' && c <= '9')
+}
+
+func isDurationUnit(c rune) bool {
+	switch c {
+	case 's', 'm', 'h', 'd', 'w', 'y':
+		return true
+	}
+	return false
+}
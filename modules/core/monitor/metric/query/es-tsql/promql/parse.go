@@ -0,0 +1,176 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package espromql
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/olivere/elastic"
+
+	tsql "github.com/erda-project/erda/modules/core/monitor/metric/query/es-tsql"
+	esinfluxql "github.com/erda-project/erda/modules/core/monitor/metric/query/es-tsql/influxql"
+)
+
+// StepKey is the key of param to specify query resolution step
+const StepKey = "step"
+
+// defaultMaxTimePoints same as the max points of prometheus range query
+const defaultMaxTimePoints = 11000
+
+// Parser compile PromQL to InfluxQL, and build query by influxql parser, so that they have the same query plan.
+type Parser struct {
+	start, end int64
+	stmt       string
+	step       time.Duration
+	err        error
+
+	filter           *elastic.BoolQuery
+	originalTimeUnit tsql.TimeUnit
+	targetTimeUnit   tsql.TimeUnit
+	timeKey          string
+	maxTimePoints    int64
+}
+
+// New start and end always nanosecond
+func New(start, end int64, stmt string) tsql.Parser {
+	return &Parser{
+		start:            start,
+		end:              end,
+		stmt:             stmt,
+		originalTimeUnit: tsql.Nanosecond,
+		targetTimeUnit:   tsql.UnsetTimeUnit,
+		timeKey:          tsql.TimestampKey,
+		maxTimePoints:    defaultMaxTimePoints,
+	}
+}
+
+func init() {
+	tsql.RegisterParser("promql", New)
+}
+
+// SetFilter .
+func (p *Parser) SetFilter(filter *elastic.BoolQuery) tsql.Parser {
+	p.filter = filter
+	return p
+}
+
+// SetParams only step is used, PromQL has no params.
+func (p *Parser) SetParams(params map[string]interface{}) tsql.Parser {
+	if step, ok := params[StepKey]; ok {
+		p.step, p.err = ParseStep(fmt.Sprint(step))
+	}
+	return p
+}
+
+// SetOriginalTimeUnit .
+func (p *Parser) SetOriginalTimeUnit(unit tsql.TimeUnit) tsql.Parser {
+	p.originalTimeUnit = unit
+	return p
+}
+
+// SetTargetTimeUnit .
+func (p *Parser) SetTargetTimeUnit(unit tsql.TimeUnit) tsql.Parser {
+	p.targetTimeUnit = unit
+	return p
+}
+
+// SetTimeKey .
+func (p *Parser) SetTimeKey(key string) tsql.Parser {
+	p.timeKey = key
+	return p
+}
+
+// SetMaxTimePoints .
+func (p *Parser) SetMaxTimePoints(points int64) tsql.Parser {
+	p.maxTimePoints = points
+	return p
+}
+
+// ParseQuery .
+func (p *Parser) ParseQuery() ([]tsql.Query, error) {
+	parser, err := p.influxqlParser()
+	if err != nil {
+		return nil, err
+	}
+	return parser.ParseQuery()
+}
+
+// ParseRawQuery .
+func (p *Parser) ParseRawQuery() ([]*tsql.Source, *elastic.BoolQuery, *elastic.SearchSource, error) {
+	parser, err := p.influxqlParser()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return parser.ParseRawQuery()
+}
+
+func (p *Parser) influxqlParser() (tsql.Parser, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	expr, err := ParseExpr(p.stmt)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := Compile(expr, p.step)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Scalar {
+		return nil, fmt.Errorf("scalar expression is not supported to query")
+	}
+	// data of offset ago
+	offset := int64(plan.Offset)
+	return esinfluxql.New(p.start-offset, p.end-offset, plan.Statement).
+		SetFilter(p.filter).
+		SetOriginalTimeUnit(p.originalTimeUnit).
+		SetTargetTimeUnit(p.targetTimeUnit).
+		SetTimeKey(p.timeKey).
+		SetMaxTimePoints(p.maxTimePoints), nil
+}
+
+const (
+	// MinStep the minimum query resolution step, metrics are bucketed by seconds
+	MinStep = time.Second
+	// MaxPointsPerSeries same as the limit of prometheus
+	MaxPointsPerSeries = 11000
+)
+
+// ParseStep parse step of prometheus http api, float seconds or duration, such as 15, 1.5, 1m
+func ParseStep(s string) (time.Duration, error) {
+	var d time.Duration
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		if v <= 0 {
+			return 0, fmt.Errorf("zero or negative query resolution step %q", s)
+		}
+		d = time.Duration(v * float64(time.Second))
+	} else if d, err = parseDuration(s); err != nil {
+		return 0, fmt.Errorf("invalid query resolution step %q", s)
+	}
+	if d < MinStep {
+		return 0, fmt.Errorf("query resolution step %q is less than the minimum %s", s, MinStep)
+	}
+	return d, nil
+}
+
+// CheckResolution check the number of points of each series between start and end
+func CheckResolution(start, end time.Time, step time.Duration) error {
+	if step <= 0 || end.Sub(start)/step > MaxPointsPerSeries {
+		return fmt.Errorf("exceeded maximum resolution of %d points per timeseries. Try decreasing the query resolution (?step=XX)", MaxPointsPerSeries)
+	}
+	return nil
+}
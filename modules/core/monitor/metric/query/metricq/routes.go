@@ -24,6 +24,7 @@ import (
 
 func (p *provider) initRoutes(routes httpserver.Router) error {
 	p.initRoutesV1(routes)
+	p.initRoutesPrometheus(routes)
 	// metric query apis
 	routes.GET("/api/query", p.queryMetrics)  // for tsql
 	routes.POST("/api/query", p.queryMetrics) // for tsql
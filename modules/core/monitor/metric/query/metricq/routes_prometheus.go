@@ -0,0 +1,479 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricq
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olivere/elastic"
+
+	"github.com/erda-project/erda-infra/providers/httpserver"
+	tsql "github.com/erda-project/erda/modules/core/monitor/metric/query/es-tsql"
+	espromql "github.com/erda-project/erda/modules/core/monitor/metric/query/es-tsql/promql"
+	api "github.com/erda-project/erda/pkg/common/httpapi"
+)
+
+const (
+	// promLookbackDelta same as default lookback delta of prometheus
+	promLookbackDelta = 5 * time.Minute
+	promSeriesLimit   = 1000
+)
+
+// Prometheus HTTP API compatible, so that Grafana's Prometheus datasource can query metrics directly.
+// Metric name of PromQL is <metric>:<field>, such as docker_container_summary:cpu_usage_percent.
+func (p *provider) initRoutesPrometheus(routes httpserver.Router) {
+	routes.GET("/api/v1/query", p.promQuery)
+	routes.POST("/api/v1/query", p.promQuery)
+	routes.GET("/api/v1/query_range", p.promQueryRange)
+	routes.POST("/api/v1/query_range", p.promQueryRange)
+	routes.GET("/api/v1/series", p.promSeries)
+	routes.POST("/api/v1/series", p.promSeries)
+	routes.GET("/api/v1/labels", p.promLabels)
+	routes.POST("/api/v1/labels", p.promLabels)
+	routes.GET("/api/v1/label/:name/values", p.promLabelValues)
+	routes.POST("/api/v1/read", p.promRemoteRead)
+}
+
+type promResponse struct {
+	Status    string      `json:"status"`
+	Data      interface{} `json:"data,omitempty"`
+	ErrorType string      `json:"errorType,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+type promQueryData struct {
+	ResultType string      `json:"resultType"`
+	Result     interface{} `json:"result"`
+}
+
+type promSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values [][]interface{}   `json:"values,omitempty"`
+	Value  []interface{}     `json:"value,omitempty"`
+}
+
+func promSuccess(data interface{}) interface{} {
+	return api.SuccessRaw(&promResponse{Status: "success", Data: data})
+}
+
+func promBadData(err error) interface{} {
+	return api.SuccessRaw(&promResponse{Status: "error", ErrorType: "bad_data", Error: err.Error()}, http.StatusBadRequest)
+}
+
+func promExecutionError(err error) interface{} {
+	return api.SuccessRaw(&promResponse{Status: "error", ErrorType: "execution", Error: err.Error()}, http.StatusUnprocessableEntity)
+}
+
+func (p *provider) promQuery(r *http.Request) interface{} {
+	if err := r.ParseForm(); err != nil {
+		return promBadData(err)
+	}
+	ts, err := parsePromTime(r.Form.Get("time"), time.Now())
+	if err != nil {
+		return promBadData(err)
+	}
+	query := r.Form.Get("query")
+	expr, err := espromql.ParseExpr(query)
+	if err != nil {
+		return promBadData(err)
+	}
+	plan, err := espromql.Compile(expr, 0)
+	if err != nil {
+		return promBadData(err)
+	}
+	if plan.Scalar {
+		return promSuccess(&promQueryData{
+			ResultType: "scalar",
+			Result:     []interface{}{promTimestamp(ts), formatPromValue(plan.ScalarValue)},
+		})
+	}
+
+	// evaluate the latest bucket before ts
+	window := plan.Range
+	if window <= 0 {
+		window = promLookbackDelta
+	}
+	start := ts.Add(-window)
+	if plan.NextBucket {
+		start = start.Add(-window)
+	}
+	series, err := p.promEvaluate(query, start, start, window)
+	if err != nil {
+		return promExecutionError(err)
+	}
+	result := make([]*promSeries, 0, len(series))
+	for _, s := range series {
+		result = append(result, &promSeries{
+			Metric: s.Metric,
+			Value:  []interface{}{promTimestamp(ts), s.Values[0][1]},
+		})
+	}
+	return promSuccess(&promQueryData{ResultType: "vector", Result: result})
+}
+
+func (p *provider) promQueryRange(r *http.Request) interface{} {
+	if err := r.ParseForm(); err != nil {
+		return promBadData(err)
+	}
+	start, err := parsePromTime(r.Form.Get("start"), time.Time{})
+	if err != nil {
+		return promBadData(err)
+	}
+	end, err := parsePromTime(r.Form.Get("end"), time.Time{})
+	if err != nil {
+		return promBadData(err)
+	}
+	if start.IsZero() || end.IsZero() {
+		return promBadData(fmt.Errorf("start and end are required"))
+	}
+	if end.Before(start) {
+		return promBadData(fmt.Errorf("end timestamp must not be before start time"))
+	}
+	step, err := espromql.ParseStep(r.Form.Get("step"))
+	if err != nil {
+		return promBadData(err)
+	}
+	if err := espromql.CheckResolution(start, end, step); err != nil {
+		return promBadData(err)
+	}
+	series, err := p.promEvaluate(r.Form.Get("query"), start, end, step)
+	if err != nil {
+		return promExecutionError(err)
+	}
+	return promSuccess(&promQueryData{ResultType: "matrix", Result: series})
+}
+
+// promEvaluate evaluate PromQL at start, start+step, ... , end
+func (p *provider) promEvaluate(query string, start, end time.Time, step time.Duration) ([]*promSeries, error) {
+	expr, err := espromql.ParseExpr(query)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := espromql.Compile(expr, step)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Scalar {
+		s := &promSeries{Metric: map[string]string{}}
+		for t := start; !t.After(end); t = t.Add(step) {
+			s.Values = append(s.Values, []interface{}{promTimestamp(t), formatPromValue(plan.ScalarValue)})
+		}
+		return []*promSeries{s}, nil
+	}
+
+	// the last bucket is [end, end+step)
+	queryEnd := end.Add(step)
+	if plan.NextBucket {
+		queryEnd = queryEnd.Add(step)
+	}
+	options := url.Values{}
+	options.Set("start", strconv.FormatInt(toMillisecond(start), 10))
+	options.Set("end", strconv.FormatInt(toMillisecond(queryEnd), 10))
+	options.Set(espromql.StepKey, strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+	options.Set("epoch", "ms")
+	rs, err := p.q.Query("promql", query, nil, options)
+	if err != nil {
+		return nil, err
+	}
+	if rs.ResultSet == nil {
+		return []*promSeries{}, nil
+	}
+
+	var list []*promSeries
+	series := make(map[string]*promSeries)
+	offset := toMillisecond(time.Unix(0, 0).Add(plan.Offset))
+	startMs, endMs := toMillisecond(start), toMillisecond(end)
+	for _, row := range rs.Rows {
+		if len(row) != len(plan.Labels)+2 {
+			return nil, fmt.Errorf("invalid row of result: %v", row)
+		}
+		t, ok := toInt64(row[0])
+		if !ok || t < startMs || t > endMs {
+			continue
+		}
+		v, ok := toFloat64(row[len(row)-1])
+		if !ok {
+			continue
+		}
+		metric := make(map[string]string)
+		var key []string
+		for i, label := range plan.Labels {
+			val := fmt.Sprint(row[i+1])
+			if row[i+1] == nil || len(val) <= 0 {
+				continue
+			}
+			metric[label] = val
+			key = append(key, label+"="+val)
+		}
+		k := strings.Join(key, ",")
+		s, ok := series[k]
+		if !ok {
+			s = &promSeries{Metric: metric}
+			series[k] = s
+			list = append(list, s)
+		}
+		s.Values = append(s.Values, []interface{}{float64(t+offset) / 1000, formatPromValue(v)})
+	}
+	for _, s := range list {
+		sort.Slice(s.Values, func(i, j int) bool { return s.Values[i][0].(float64) < s.Values[j][0].(float64) })
+	}
+	if list == nil {
+		list = []*promSeries{}
+	}
+	return list, nil
+}
+
+func (p *provider) promSeries(r *http.Request) interface{} {
+	if err := r.ParseForm(); err != nil {
+		return promBadData(err)
+	}
+	matches := r.Form["match[]"]
+	if len(matches) <= 0 {
+		return promBadData(fmt.Errorf("no match[] parameter provided"))
+	}
+	start, end, err := parsePromTimeRange(r.Form)
+	if err != nil {
+		return promBadData(err)
+	}
+	series, err := p.querySeries(matches, start, end)
+	if err != nil {
+		return promExecutionError(err)
+	}
+	return promSuccess(series)
+}
+
+func (p *provider) promLabels(r *http.Request) interface{} {
+	if err := r.ParseForm(); err != nil {
+		return promBadData(err)
+	}
+	start, end, err := parsePromTimeRange(r.Form)
+	if err != nil {
+		return promBadData(err)
+	}
+	series, err := p.querySeries(r.Form["match[]"], start, end)
+	if err != nil {
+		return promExecutionError(err)
+	}
+	set := make(map[string]bool)
+	names := []string{}
+	for _, s := range series {
+		for k := range s {
+			if !set[k] {
+				set[k] = true
+				names = append(names, k)
+			}
+		}
+	}
+	sort.Strings(names)
+	return promSuccess(names)
+}
+
+func (p *provider) promLabelValues(r *http.Request, param *struct {
+	Name string `param:"name"`
+}) interface{} {
+	if err := r.ParseForm(); err != nil {
+		return promBadData(err)
+	}
+	start, end, err := parsePromTimeRange(r.Form)
+	if err != nil {
+		return promBadData(err)
+	}
+	series, err := p.querySeries(r.Form["match[]"], start, end)
+	if err != nil {
+		return promExecutionError(err)
+	}
+	set := make(map[string]bool)
+	values := []string{}
+	for _, s := range series {
+		if v, ok := s[param.Name]; ok && !set[v] {
+			set[v] = true
+			values = append(values, v)
+		}
+	}
+	sort.Strings(values)
+	return promSuccess(values)
+}
+
+// querySeries find series from the latest documents, the series of all metrics are returned if matches is empty
+func (p *provider) querySeries(matches []string, start, end time.Time) ([]map[string]string, error) {
+	type search struct {
+		metrics, clusters []string
+		field             string
+		searchSource      *elastic.SearchSource
+	}
+	var searches []*search
+	for _, match := range matches {
+		vs, err := espromql.ParseSelector(match)
+		if err != nil {
+			return nil, err
+		}
+		parser := tsql.New(start.UnixNano(), end.UnixNano(), "influxql", espromql.CompileSeries(vs, promSeriesLimit))
+		sources, _, searchSource, err := parser.ParseRawQuery()
+		if err != nil {
+			return nil, err
+		}
+		s := &search{field: vs.Field, searchSource: searchSource}
+		for _, source := range sources {
+			s.metrics = append(s.metrics, source.Name)
+			if len(source.Database) > 0 {
+				s.clusters = append(s.clusters, source.Database)
+			}
+		}
+		searches = append(searches, s)
+	}
+	if len(matches) <= 0 {
+		searches = append(searches, &search{
+			searchSource: elastic.NewSearchSource().
+				Query(elastic.NewBoolQuery().Filter(elastic.NewRangeQuery(tsql.TimestampKey).Gte(start.UnixNano()).Lte(end.UnixNano()))).
+				Sort(tsql.TimestampKey, false).Size(promSeriesLimit),
+		})
+	}
+
+	set := make(map[string]bool)
+	result := []map[string]string{}
+	for _, s := range searches {
+		s.searchSource.FetchSourceContext(elastic.NewFetchSourceContext(true).Include(tsql.NameKey, "tags", "fields"))
+		resp, err := p.q.QueryRaw(s.metrics, s.clusters, toMillisecond(start), toMillisecond(end), s.searchSource)
+		if err != nil {
+			return nil, err
+		}
+		if resp == nil || resp.Hits == nil {
+			continue
+		}
+		for _, hit := range resp.Hits.Hits {
+			var doc struct {
+				Name   string                 `json:"name"`
+				Tags   map[string]string      `json:"tags"`
+				Fields map[string]interface{} `json:"fields"`
+			}
+			if hit.Source == nil || json.Unmarshal(*hit.Source, &doc) != nil {
+				continue
+			}
+			for field, value := range doc.Fields {
+				if len(s.field) > 0 && field != s.field {
+					continue
+				}
+				if _, ok := value.(float64); !ok {
+					continue
+				}
+				labels := map[string]string{espromql.MetricNameLabel: doc.Name + ":" + field}
+				for k, v := range doc.Tags {
+					labels[k] = v
+				}
+				key := seriesKey(labels)
+				if !set[key] {
+					set[key] = true
+					result = append(result, labels)
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+func seriesKey(labels map[string]string) string {
+	var keys []string
+	for k, v := range labels {
+		keys = append(keys, k+"="+v)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// parsePromTime parse unix timestamp in seconds or RFC3339
+func parsePromTime(s string, def time.Time) (time.Time, error) {
+	if len(s) <= 0 {
+		return def, nil
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		sec, frac := math.Modf(v)
+		return time.Unix(int64(sec), int64(frac*float64(time.Second))), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q to a valid timestamp", s)
+}
+
+func parsePromTimeRange(form url.Values) (time.Time, time.Time, error) {
+	end, err := parsePromTime(form.Get("end"), time.Now())
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	start, err := parsePromTime(form.Get("start"), end.Add(-time.Hour))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
+
+func promTimestamp(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
+
+func formatPromValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func toMillisecond(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch val := v.(type) {
+	case int64:
+		return val, true
+	case int:
+		return int64(val), true
+	case float64:
+		return int64(val), true
+	}
+	return 0, false
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case uint64:
+		return float64(val), true
+	case json.Number:
+		f, err := val.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricq
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+
+	espromql "github.com/erda-project/erda/modules/core/monitor/metric/query/es-tsql/promql"
+)
+
+// promRemoteReadStep resolution of samples if the step of read hints is not set
+const promRemoteReadStep = time.Minute
+
+// labels which are not valid prometheus label names can't be read
+var promLabelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+type promBadReadError struct{ error }
+
+// promRemoteRead Prometheus remote read API, so that Prometheus or Thanos can read metrics by remote_read.
+// Samples of every series are evaluated by the query layer at the step of read hints,
+// the value of a step is the max value of the series in the step.
+func (p *provider) promRemoteRead(w http.ResponseWriter, r *http.Request) {
+	req, err := decodePromReadRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp := &prompb.ReadResponse{Results: make([]*prompb.QueryResult, 0, len(req.Queries))}
+	for _, q := range req.Queries {
+		result, err := p.promReadQuery(q)
+		if err != nil {
+			if _, ok := err.(promBadReadError); ok {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	buf, err := proto.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Set("Content-Encoding", "snappy")
+	w.Write(snappy.Encode(nil, buf))
+}
+
+func decodePromReadRequest(r *http.Request) (*prompb.ReadRequest, error) {
+	defer r.Body.Close()
+	buf, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read body err: %w", err)
+	}
+	buf, err = snappy.Decode(nil, buf)
+	if err != nil {
+		return nil, fmt.Errorf("snappy decode err: %w", err)
+	}
+	var req prompb.ReadRequest
+	if err := proto.Unmarshal(buf, &req); err != nil {
+		return nil, fmt.Errorf("unmarshal body err: %w", err)
+	}
+	return &req, nil
+}
+
+func (p *provider) promReadQuery(q *prompb.Query) (*prompb.QueryResult, error) {
+	result := &prompb.QueryResult{}
+	vs, err := promReadSelector(q.Matchers)
+	if err != nil {
+		return nil, promBadReadError{err}
+	}
+	start, end := time.Unix(0, q.StartTimestampMs*int64(time.Millisecond)), time.Unix(0, q.EndTimestampMs*int64(time.Millisecond))
+	if end.Before(start) {
+		return nil, promBadReadError{fmt.Errorf("end timestamp must not be before start time")}
+	}
+	var step time.Duration
+	if q.Hints != nil {
+		step = time.Duration(q.Hints.StepMs) * time.Millisecond
+	}
+	step = promReadStep(start, end, step)
+
+	// evaluate every series grouped by all of its labels
+	series, err := p.querySeries([]string{vs.String()}, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(series) <= 0 {
+		return result, nil
+	}
+	set := make(map[string]bool)
+	var grouping []string
+	for _, s := range series {
+		for name := range s {
+			if name != espromql.MetricNameLabel && !set[name] && promLabelNameRegexp.MatchString(name) {
+				set[name] = true
+				grouping = append(grouping, name)
+			}
+		}
+	}
+	sort.Strings(grouping)
+	expr := &espromql.AggregateExpr{Op: "max", Expr: vs, Grouping: grouping}
+	list, err := p.promEvaluate(expr.String(), start, end, step)
+	if err != nil {
+		return nil, err
+	}
+
+	name := vs.Metric + ":" + vs.Field
+	for _, s := range list {
+		ts := &prompb.TimeSeries{Labels: []*prompb.Label{{Name: espromql.MetricNameLabel, Value: name}}}
+		for k, v := range s.Metric {
+			ts.Labels = append(ts.Labels, &prompb.Label{Name: k, Value: v})
+		}
+		sort.Slice(ts.Labels, func(i, j int) bool { return ts.Labels[i].Name < ts.Labels[j].Name })
+		for _, value := range s.Values {
+			v, err := strconv.ParseFloat(value[1].(string), 64)
+			if err != nil {
+				continue
+			}
+			ts.Samples = append(ts.Samples, &prompb.Sample{
+				Timestamp: int64(value[0].(float64)*1000 + 0.5),
+				Value:     v,
+			})
+		}
+		result.Timeseries = append(result.Timeseries, ts)
+	}
+	return result, nil
+}
+
+// promReadSelector convert matchers of remote read to vector selector, the metric name is required
+func promReadSelector(matchers []*prompb.LabelMatcher) (*espromql.VectorSelector, error) {
+	vs := &espromql.VectorSelector{}
+	for _, m := range matchers {
+		if m.Name == espromql.MetricNameLabel {
+			if m.Type != prompb.LabelMatcher_EQ {
+				return nil, fmt.Errorf("only equal matcher is supported by %s", espromql.MetricNameLabel)
+			}
+			idx := strings.LastIndex(m.Value, ":")
+			if idx <= 0 || idx >= len(m.Value)-1 {
+				return nil, fmt.Errorf("invalid metric name %q, metric name should be <metric>:<field>", m.Value)
+			}
+			vs.Metric, vs.Field = m.Value[:idx], m.Value[idx+1:]
+			continue
+		}
+		lm := &espromql.LabelMatcher{Name: m.Name, Value: m.Value}
+		switch m.Type {
+		case prompb.LabelMatcher_EQ:
+			lm.Type = espromql.MatchEqual
+		case prompb.LabelMatcher_NEQ:
+			lm.Type = espromql.MatchNotEqual
+		case prompb.LabelMatcher_RE:
+			lm.Type = espromql.MatchRegexp
+		case prompb.LabelMatcher_NRE:
+			lm.Type = espromql.MatchNotRegexp
+		default:
+			return nil, fmt.Errorf("unknown matcher type %s", m.Type)
+		}
+		vs.Matchers = append(vs.Matchers, lm)
+	}
+	if len(vs.Metric) <= 0 {
+		return nil, fmt.Errorf("matcher of %s is required", espromql.MetricNameLabel)
+	}
+	return vs, nil
+}
+
+// promReadStep use the step of hints, and enlarge it to limit the points of every series
+func promReadStep(start, end time.Time, step time.Duration) time.Duration {
+	if step <= 0 {
+		step = promRemoteReadStep
+	}
+	if step < espromql.MinStep {
+		step = espromql.MinStep
+	}
+	if min := end.Sub(start) / espromql.MaxPointsPerSeries; step <= min {
+		step = (min/time.Second + 1) * time.Second
+	}
+	return step
+}
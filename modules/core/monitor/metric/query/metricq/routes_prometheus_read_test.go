@@ -0,0 +1,54 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricq
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+
+	espromql "github.com/erda-project/erda/modules/core/monitor/metric/query/es-tsql/promql"
+)
+
+func TestPromReadSelector(t *testing.T) {
+	vs, err := promReadSelector([]*prompb.LabelMatcher{
+		{Type: prompb.LabelMatcher_EQ, Name: "__name__", Value: "docker_container_summary:cpu_usage_percent"},
+		{Type: prompb.LabelMatcher_EQ, Name: "cluster_name", Value: "erda"},
+		{Type: prompb.LabelMatcher_NRE, Name: "host_ip", Value: "10\\..*"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `docker_container_summary:cpu_usage_percent{cluster_name="erda",host_ip!~"10\\..*"}`, vs.String())
+
+	// metric name is required
+	_, err = promReadSelector([]*prompb.LabelMatcher{{Type: prompb.LabelMatcher_EQ, Name: "cluster_name", Value: "erda"}})
+	assert.Error(t, err)
+	_, err = promReadSelector([]*prompb.LabelMatcher{{Type: prompb.LabelMatcher_RE, Name: "__name__", Value: "docker.*"}})
+	assert.Error(t, err)
+	_, err = promReadSelector([]*prompb.LabelMatcher{{Type: prompb.LabelMatcher_EQ, Name: "__name__", Value: "docker_container_summary"}})
+	assert.Error(t, err)
+}
+
+func TestPromReadStep(t *testing.T) {
+	start := time.Unix(0, 0)
+	assert.Equal(t, promRemoteReadStep, promReadStep(start, start.Add(time.Hour), 0))
+	assert.Equal(t, espromql.MinStep, promReadStep(start, start.Add(time.Hour), time.Millisecond))
+	assert.Equal(t, 15*time.Second, promReadStep(start, start.Add(time.Hour), 15*time.Second))
+
+	// points of every series are limited
+	step := promReadStep(start, start.Add(30*24*time.Hour), 15*time.Second)
+	assert.NoError(t, espromql.CheckResolution(start, start.Add(30*24*time.Hour), step))
+}
@@ -41,6 +41,7 @@ import (
 	_ "github.com/erda-project/erda/modules/core/monitor/metric/query/es-tsql/formats/dict"     //
 	_ "github.com/erda-project/erda/modules/core/monitor/metric/query/es-tsql/formats/influxdb" //
 	_ "github.com/erda-project/erda/modules/core/monitor/metric/query/es-tsql/influxql"         //
+	_ "github.com/erda-project/erda/modules/core/monitor/metric/query/es-tsql/promql"           //
 )
 
 type config struct {
@@ -54,7 +54,7 @@ func (q *queryer) buildTSQLParser(ql, statement string, params map[string]interf
 		}
 		ql = ql[0:idx]
 	}
-	if ql != "influxql" {
+	if ql != "influxql" && ql != "promql" {
 		return nil, 0, 0, nil, fmt.Errorf("not support tsql '%s'", ql)
 	}
 	start, end, err = ParseTimeRange(options.Get("start"), options.Get("end"), options.Get("timestamp"), options.Get("latest"))
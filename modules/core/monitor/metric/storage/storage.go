@@ -20,7 +20,39 @@ import (
 	"github.com/erda-project/erda/modules/core/monitor/storekit"
 )
 
-// Storage .
-type Storage interface {
-	NewWriter(ctx context.Context) (storekit.BatchWriter, error)
-}
+type (
+	// Operator .
+	Operator int32
+	// Filter .
+	Filter struct {
+		Key   string
+		Op    Operator
+		Value interface{}
+	}
+
+	// Selector .
+	Selector struct {
+		Start   int64     // unix nanosecond, inclusive
+		End     int64     // unix nanosecond, exclusive
+		Name    string    // metric name, required
+		Fields  []string  // fields to read, read all fields if empty
+		Filters []*Filter // filters of tags, the key of filter is the tag key
+	}
+
+	// Storage .
+	Storage interface {
+		NewWriter(ctx context.Context) (storekit.BatchWriter, error)
+	}
+
+	// Reader iterate *metric.Metric which match the selector
+	Reader interface {
+		Iterator(ctx context.Context, sel *Selector) (storekit.Iterator, error)
+	}
+)
+
+const (
+	// EQ equal
+	EQ Operator = iota
+	// REGEXP match the regular expression
+	REGEXP
+)
@@ -0,0 +1,318 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tsdb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/tsdb"
+	"github.com/prometheus/tsdb/labels"
+
+	"github.com/erda-project/erda/modules/core/monitor/metric"
+	"github.com/erda-project/erda/modules/core/monitor/metric/storage"
+	"github.com/erda-project/erda/modules/core/monitor/storekit"
+)
+
+func (p *provider) Iterator(ctx context.Context, sel *storage.Selector) (storekit.Iterator, error) {
+	if len(sel.Name) <= 0 {
+		return nil, fmt.Errorf("metric name is required")
+	}
+	matchers := []labels.Matcher{labels.NewEqualMatcher(labelMetricName, sel.Name)}
+	if len(sel.Fields) == 1 {
+		matchers = append(matchers, labels.NewEqualMatcher(labelFieldName, sel.Fields[0]))
+	} else if len(sel.Fields) > 1 {
+		fields := make([]string, len(sel.Fields))
+		for i, field := range sel.Fields {
+			fields[i] = regexp.QuoteMeta(field)
+		}
+		matchers = append(matchers, labels.NewMustRegexpMatcher(labelFieldName, "^(?:"+strings.Join(fields, "|")+")$"))
+	}
+	for _, filter := range sel.Filters {
+		if isReservedLabel(filter.Key) {
+			return nil, fmt.Errorf("invalid filter key %q", filter.Key)
+		}
+		value, ok := filter.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("value of filter %q must be string", filter.Key)
+		}
+		switch filter.Op {
+		case storage.EQ:
+			matchers = append(matchers, labels.NewEqualMatcher(filter.Key, value))
+		case storage.REGEXP:
+			m, err := labels.NewRegexpMatcher(filter.Key, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regexp %q", value)
+			}
+			matchers = append(matchers, m)
+		default:
+			return nil, fmt.Errorf("not supported operator %d of filter %q", filter.Op, filter.Key)
+		}
+	}
+
+	// samples are stored in millisecond, the selector is [Start, End) in nanosecond
+	mint := sel.Start / int64(time.Millisecond)
+	maxt := (sel.End - 1) / int64(time.Millisecond)
+	if mint > maxt {
+		return storekit.EmptyIterator{}, nil
+	}
+	return &metricsIterator{
+		ctx:      ctx,
+		db:       p.db,
+		matchers: matchers,
+		window:   int64(p.Cfg.ReadWindow / time.Millisecond),
+		mint:     mint,
+		maxt:     maxt,
+		start:    mint,
+		end:      maxt,
+	}, nil
+}
+
+type iteratorDir int8
+
+const (
+	iteratorInitial = iota
+	iteratorForward
+	iteratorBackward
+)
+
+// metricsIterator reads samples window by window, only blocks which overlap the window are queried.
+// Samples of series which differ only in field are merged into one metric.
+type metricsIterator struct {
+	ctx      context.Context
+	db       *tsdb.DB
+	matchers []labels.Matcher
+	window   int64
+	mint     int64
+	maxt     int64
+
+	dir   iteratorDir
+	start int64 // start of the unread range, millisecond
+	end   int64 // end of the unread range, millisecond
+
+	buffer []*metric.Metric
+	value  *metric.Metric
+	err    error
+	closed bool
+}
+
+func (it *metricsIterator) First() bool {
+	if it.checkClosed() {
+		return false
+	}
+	it.start, it.end = it.mint, it.maxt
+	it.fetch(iteratorForward)
+	return it.yield()
+}
+
+func (it *metricsIterator) Last() bool {
+	if it.checkClosed() {
+		return false
+	}
+	it.start, it.end = it.mint, it.maxt
+	it.fetch(iteratorBackward)
+	return it.yield()
+}
+
+func (it *metricsIterator) Next() bool {
+	if it.checkClosed() {
+		return false
+	}
+	if it.dir == iteratorBackward {
+		it.err = storekit.ErrOpNotSupported
+		return false
+	}
+	if it.yield() {
+		return true
+	}
+	it.fetch(iteratorForward)
+	return it.yield()
+}
+
+func (it *metricsIterator) Prev() bool {
+	if it.checkClosed() {
+		return false
+	}
+	if it.dir == iteratorForward {
+		it.err = storekit.ErrOpNotSupported
+		return false
+	}
+	if it.yield() {
+		return true
+	}
+	it.fetch(iteratorBackward)
+	return it.yield()
+}
+
+func (it *metricsIterator) Value() storekit.Data { return it.value }
+func (it *metricsIterator) Error() error {
+	if it.err == io.EOF {
+		return nil
+	}
+	return it.err
+}
+
+func (it *metricsIterator) Close() error {
+	it.closed = true
+	it.buffer = nil
+	return nil
+}
+
+func (it *metricsIterator) yield() bool {
+	if len(it.buffer) > 0 {
+		it.value = it.buffer[0]
+		it.buffer = it.buffer[1:]
+		return true
+	}
+	it.value = nil
+	return false
+}
+
+func (it *metricsIterator) checkClosed() bool {
+	if it.closed {
+		if it.err == nil {
+			it.err = storekit.ErrIteratorClosed
+		}
+		return true
+	}
+	select {
+	case <-it.ctx.Done():
+		if it.err == nil {
+			it.err = storekit.ErrIteratorClosed
+		}
+		return true
+	default:
+	}
+	return false
+}
+
+// fetch reads the next window of the unread range until some metrics are read
+func (it *metricsIterator) fetch(dir iteratorDir) {
+	it.buffer = nil
+	it.dir = dir
+	for it.err == nil && len(it.buffer) <= 0 && it.start <= it.end {
+		var mint, maxt int64
+		if dir == iteratorForward {
+			mint, maxt = it.start, it.start+it.window-1
+			if maxt > it.end {
+				maxt = it.end
+			}
+			it.start = maxt + 1
+		} else {
+			mint, maxt = it.end-it.window+1, it.end
+			if mint < it.start {
+				mint = it.start
+			}
+			it.end = mint - 1
+		}
+		it.buffer, it.err = it.read(mint, maxt, dir == iteratorBackward)
+	}
+}
+
+// read returns metrics in [mint, maxt], which are sorted by timestamp
+func (it *metricsIterator) read(mint, maxt int64, reverse bool) ([]*metric.Metric, error) {
+	q, err := it.db.Querier(mint, maxt)
+	if err != nil {
+		return nil, err
+	}
+	defer q.Close()
+	set, err := q.Select(it.matchers...)
+	if err != nil {
+		return nil, err
+	}
+	type metricKey struct {
+		series string
+		ts     int64
+	}
+	type entry struct {
+		metricKey
+		m *metric.Metric
+	}
+	var list []*entry
+	metrics := make(map[metricKey]*entry)
+	for set.Next() {
+		series := set.At()
+		lset := series.Labels()
+		var name, field string
+		tags := make(map[string]string, len(lset))
+		for _, l := range lset {
+			switch l.Name {
+			case labelMetricName:
+				name = l.Value
+			case labelFieldName:
+				field = l.Value
+			case labelRetentionKey:
+			default:
+				tags[l.Name] = l.Value
+			}
+		}
+		key := lset.WithoutEmpty().Map()
+		delete(key, labelFieldName)
+		id := labels.FromMap(key).String()
+
+		samples := series.Iterator()
+		for ok := samples.Seek(mint); ok; ok = samples.Next() {
+			t, v := samples.At()
+			if t > maxt {
+				break
+			}
+			mk := metricKey{series: id, ts: t}
+			e, ok := metrics[mk]
+			if !ok {
+				e = &entry{
+					metricKey: mk,
+					m: &metric.Metric{
+						Name:      name,
+						Timestamp: t * int64(time.Millisecond),
+						Tags:      make(map[string]string, len(tags)),
+						Fields:    make(map[string]interface{}),
+					},
+				}
+				for k, v := range tags {
+					e.m.Tags[k] = v
+				}
+				metrics[mk] = e
+				list = append(list, e)
+			}
+			e.m.Fields[field] = v
+		}
+		if err := samples.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if err := set.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if reverse {
+			a, b = b, a
+		}
+		if a.ts != b.ts {
+			return a.ts < b.ts
+		}
+		return a.series < b.series
+	})
+	result := make([]*metric.Metric, len(list))
+	for i, e := range list {
+		result[i] = e.m
+	}
+	return result, nil
+}
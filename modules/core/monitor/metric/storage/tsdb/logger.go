@@ -0,0 +1,61 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tsdb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/erda-project/erda-infra/base/logs"
+)
+
+// kitLogger adapts logs.Logger to the go-kit logger which is required by tsdb
+type kitLogger struct {
+	log logs.Logger
+}
+
+func (l *kitLogger) Log(keyvals ...interface{}) error {
+	var lvl string
+	sb := &strings.Builder{}
+	for i := 0; i < len(keyvals); i += 2 {
+		if keyvals[i] == level.Key() {
+			if i+1 < len(keyvals) {
+				lvl = fmt.Sprint(keyvals[i+1])
+			}
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		if i+1 < len(keyvals) {
+			fmt.Fprintf(sb, "%v=%v", keyvals[i], keyvals[i+1])
+		} else {
+			fmt.Fprint(sb, keyvals[i])
+		}
+	}
+	switch lvl {
+	case level.ErrorValue().String():
+		l.log.Error(sb.String())
+	case level.WarnValue().String():
+		l.log.Warn(sb.String())
+	case level.DebugValue().String():
+		l.log.Debug(sb.String())
+	default:
+		l.log.Info(sb.String())
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tsdb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/prometheus/tsdb"
+
+	"github.com/erda-project/erda-infra/base/logs"
+	"github.com/erda-project/erda-infra/base/servicehub"
+	"github.com/erda-project/erda/modules/core/monitor/metric/storage"
+	retention "github.com/erda-project/erda/modules/core/monitor/settings/retention-strategy"
+	"github.com/erda-project/erda/modules/core/monitor/storekit"
+)
+
+type (
+	config struct {
+		Path           string        `file:"path" default:"/data/metrics"`
+		BlockDuration  time.Duration `file:"block_duration" default:"2h"`
+		WALCompression bool          `file:"wal_compression" default:"true"`
+		// MaxTTL is the retention of blocks, metrics are never kept longer than it even if ttl of retention strategy is longer.
+		MaxTTL        time.Duration `file:"max_ttl" default:"720h"`
+		DefaultTTL    time.Duration `file:"default_ttl" default:"168h"`
+		CleanInterval time.Duration `file:"clean_interval" default:"30m"`
+		ReadWindow    time.Duration `file:"read_window" default:"1h"`
+	}
+	provider struct {
+		Cfg       *config
+		Log       logs.Logger
+		Retention retention.Interface `autowired:"storage-retention-strategy@metric" optional:"true"`
+
+		db *tsdb.DB
+	}
+)
+
+func (p *provider) Init(ctx servicehub.Context) (err error) {
+	if p.Cfg.BlockDuration < time.Minute {
+		return fmt.Errorf("block_duration must be at least 1m")
+	}
+	if p.Cfg.MaxTTL < p.Cfg.BlockDuration {
+		return fmt.Errorf("max_ttl must not be less than block_duration")
+	}
+	if p.Cfg.ReadWindow <= 0 {
+		return fmt.Errorf("read_window must be positive")
+	}
+	if err := os.MkdirAll(p.Cfg.Path, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create tsdb path %q: %w", p.Cfg.Path, err)
+	}
+	p.db, err = tsdb.Open(p.Cfg.Path, &kitLogger{p.Log}, nil, &tsdb.Options{
+		RetentionDuration: uint64(p.Cfg.MaxTTL / time.Millisecond),
+		BlockRanges:       tsdb.ExponentialBlockRanges(int64(p.Cfg.BlockDuration/time.Millisecond), 3, 5),
+		WALCompression:    p.Cfg.WALCompression,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open tsdb %q: %w", p.Cfg.Path, err)
+	}
+	if p.Retention != nil {
+		ctx.AddTask(func(c context.Context) error {
+			p.Retention.Loading(c)
+			return nil
+		})
+	}
+	ctx.AddTask(p.runClean, servicehub.WithTaskName("tsdb retention clean"))
+	return nil
+}
+
+var (
+	_ storage.Storage = (*provider)(nil)
+	_ storage.Reader  = (*provider)(nil)
+)
+
+func (p *provider) NewWriter(ctx context.Context) (storekit.BatchWriter, error) {
+	return &writer{p: p}, nil
+}
+
+func (p *provider) runClean(ctx context.Context) error {
+	timer := time.NewTimer(p.Cfg.CleanInterval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return p.db.Close()
+		}
+		if err := p.clean(time.Now()); err != nil {
+			p.Log.Errorf("failed to clean expired metrics: %s", err)
+		}
+		timer.Reset(p.Cfg.CleanInterval)
+	}
+}
+
+func init() {
+	servicehub.Register("metric-storage-tsdb", &servicehub.Spec{
+		Services:    []string{"metric-storage-writer", "metric-storage-tsdb-reader"},
+		Description: "embedded time series storage of metric, which is based on prometheus tsdb",
+		ConfigFunc:  func() interface{} { return &config{} },
+		Creator:     func() servicehub.Provider { return &provider{} },
+	})
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tsdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erda-project/erda-infra/base/logs/logrusx"
+	"github.com/erda-project/erda-infra/base/servicehub"
+	"github.com/erda-project/erda/modules/core/monitor/metric"
+	"github.com/erda-project/erda/modules/core/monitor/metric/storage"
+	"github.com/erda-project/erda/modules/core/monitor/storekit"
+)
+
+type mockRetention struct {
+	keys map[string]string // host -> key
+	ttls map[string]time.Duration
+}
+
+func (r *mockRetention) GetTTL(key string) time.Duration {
+	if ttl, ok := r.ttls[key]; ok {
+		return ttl
+	}
+	return r.DefaultTTL()
+}
+func (r *mockRetention) DefaultTTL() time.Duration { return 168 * time.Hour }
+func (r *mockRetention) GetConfigKey(name string, tags map[string]string) string {
+	return r.keys[tags["host"]]
+}
+func (r *mockRetention) GetTTLByTags(name string, tags map[string]string) time.Duration {
+	return r.GetTTL(r.GetConfigKey(name, tags))
+}
+func (r *mockRetention) Loading(ctx context.Context) {}
+
+func newTestProvider(t *testing.T) *provider {
+	p := &provider{
+		Cfg: &config{
+			Path:          t.TempDir(),
+			BlockDuration: 2 * time.Hour,
+			MaxTTL:        720 * time.Hour,
+			DefaultTTL:    168 * time.Hour,
+			ReadWindow:    time.Minute,
+		},
+		Log: logrusx.New(),
+	}
+	require.NoError(t, p.Init(&mockContext{}))
+	t.Cleanup(func() { p.db.Close() })
+	return p
+}
+
+type mockContext struct{ servicehub.Context }
+
+func (c *mockContext) AddTask(fn func(context.Context) error, options ...servicehub.TaskOption) {}
+
+func writeMetrics(t *testing.T, p *provider, list ...*metric.Metric) {
+	w, err := p.NewWriter(context.Background())
+	require.NoError(t, err)
+	vals := make([]storekit.Data, len(list))
+	for i, m := range list {
+		vals[i] = m
+	}
+	n, err := w.WriteN(vals...)
+	require.NoError(t, err)
+	require.Equal(t, len(list), n)
+}
+
+func collect(t *testing.T, it storekit.Iterator, backward bool) (list []*metric.Metric) {
+	defer it.Close()
+	next, move := it.First, it.Next
+	if backward {
+		next, move = it.Last, it.Prev
+	}
+	for ok := next(); ok; ok = move() {
+		list = append(list, it.Value().(*metric.Metric))
+	}
+	require.NoError(t, it.Error())
+	return list
+}
+
+func TestProvider_Iterator(t *testing.T) {
+	p := newTestProvider(t)
+	base := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano()
+	writeMetrics(t, p,
+		&metric.Metric{Name: "host_summary", Timestamp: base, Tags: map[string]string{"host": "a"},
+			Fields: map[string]interface{}{"cpu": 1, "mem": 2.5, "state": "ok"}},
+		&metric.Metric{Name: "host_summary", Timestamp: base, Tags: map[string]string{"host": "b"},
+			Fields: map[string]interface{}{"cpu": 3, "mem": 4.5}},
+		&metric.Metric{Name: "host_summary", Timestamp: base + int64(3*time.Minute), Tags: map[string]string{"host": "a"},
+			Fields: map[string]interface{}{"cpu": 5, "mem": 6.5}},
+		&metric.Metric{Name: "other", Timestamp: base, Tags: map[string]string{"host": "a"},
+			Fields: map[string]interface{}{"cpu": 7}},
+	)
+	// out of order samples are skipped
+	writeMetrics(t, p, &metric.Metric{Name: "host_summary", Timestamp: base + int64(time.Minute), Tags: map[string]string{"host": "a"},
+		Fields: map[string]interface{}{"cpu": 8}})
+
+	sel := &storage.Selector{Start: base, End: base + int64(3*time.Minute) + 1, Name: "host_summary"}
+	it, err := p.Iterator(context.Background(), sel)
+	require.NoError(t, err)
+	list := collect(t, it, false)
+	require.Equal(t, []*metric.Metric{
+		{Name: "host_summary", Timestamp: base, Tags: map[string]string{"host": "a"}, Fields: map[string]interface{}{"cpu": 1.0, "mem": 2.5}},
+		{Name: "host_summary", Timestamp: base, Tags: map[string]string{"host": "b"}, Fields: map[string]interface{}{"cpu": 3.0, "mem": 4.5}},
+		{Name: "host_summary", Timestamp: base + int64(3*time.Minute), Tags: map[string]string{"host": "a"}, Fields: map[string]interface{}{"cpu": 5.0, "mem": 6.5}},
+	}, list)
+
+	it, err = p.Iterator(context.Background(), sel)
+	require.NoError(t, err)
+	reversed := collect(t, it, true)
+	require.Equal(t, []*metric.Metric{list[2], list[1], list[0]}, reversed)
+
+	// End is exclusive
+	it, err = p.Iterator(context.Background(), &storage.Selector{Start: base, End: base + int64(3*time.Minute), Name: "host_summary"})
+	require.NoError(t, err)
+	require.Len(t, collect(t, it, false), 2)
+
+	it, err = p.Iterator(context.Background(), &storage.Selector{
+		Start: base, End: base + int64(time.Hour), Name: "host_summary", Fields: []string{"cpu"},
+		Filters: []*storage.Filter{{Key: "host", Op: storage.REGEXP, Value: "^b$"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []*metric.Metric{
+		{Name: "host_summary", Timestamp: base, Tags: map[string]string{"host": "b"}, Fields: map[string]interface{}{"cpu": 3.0}},
+	}, collect(t, it, false))
+
+	it, err = p.Iterator(context.Background(), &storage.Selector{
+		Start: base, End: base + int64(time.Hour), Name: "host_summary",
+		Filters: []*storage.Filter{{Key: "host", Op: storage.EQ, Value: "a"}},
+	})
+	require.NoError(t, err)
+	require.True(t, it.Last())
+	require.False(t, it.Next())
+	require.Equal(t, storekit.ErrOpNotSupported, it.Error())
+
+	_, err = p.Iterator(context.Background(), &storage.Selector{Start: base, End: base + 1})
+	require.Error(t, err)
+}
+
+func TestProvider_clean(t *testing.T) {
+	p := newTestProvider(t)
+	p.Retention = &mockRetention{
+		keys: map[string]string{"a": "short"},
+		ttls: map[string]time.Duration{"short": time.Hour},
+	}
+	now := time.Now()
+	ts := now.Add(-90 * time.Minute).UnixNano()
+	writeMetrics(t, p,
+		&metric.Metric{Name: "host_summary", Timestamp: ts, Tags: map[string]string{"host": "a"}, Fields: map[string]interface{}{"cpu": 1}},
+		&metric.Metric{Name: "host_summary", Timestamp: ts, Tags: map[string]string{"host": "b"}, Fields: map[string]interface{}{"cpu": 2}},
+		&metric.Metric{Name: "host_summary", Timestamp: ts, Tags: map[string]string{"host": "a", "_ttl": "fixed"}, Fields: map[string]interface{}{"cpu": 3}},
+	)
+	require.NoError(t, p.clean(now))
+
+	it, err := p.Iterator(context.Background(), &storage.Selector{Start: ts, End: now.UnixNano(), Name: "host_summary"})
+	require.NoError(t, err)
+	var cpus []interface{}
+	for _, m := range collect(t, it, false) {
+		cpus = append(cpus, m.Fields["cpu"])
+	}
+	require.ElementsMatch(t, []interface{}{2.0, 3.0}, cpus)
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tsdb
+
+import (
+	"math"
+	"time"
+
+	"github.com/prometheus/tsdb/labels"
+)
+
+// clean deletes metrics which are expired by ttl of retention strategy.
+// Blocks beyond max_ttl are removed by tsdb itself, and fixed metrics are only expired by it.
+func (p *provider) clean(now time.Time) error {
+	q, err := p.db.Querier(math.MinInt64, math.MaxInt64)
+	if err != nil {
+		return err
+	}
+	keys, err := q.LabelValues(labelRetentionKey)
+	q.Close()
+	if err != nil {
+		return err
+	}
+	// series without retention key use the default ttl
+	keys = append(keys, "")
+
+	notFixed := []labels.Matcher{
+		labels.Not(labels.NewEqualMatcher(metricTagTTL, metricTagTTLFixed)),
+		labels.NewEqualMatcher(metricTagMetricID, ""),
+	}
+	var deleted bool
+	for _, key := range keys {
+		ttl := p.getTTL(key)
+		if ttl <= 0 || ttl >= p.Cfg.MaxTTL {
+			continue
+		}
+		maxt := now.Add(-ttl).UnixNano() / int64(time.Millisecond)
+		ms := append([]labels.Matcher{labels.NewEqualMatcher(labelRetentionKey, key)}, notFixed...)
+		if err := p.db.Delete(math.MinInt64, maxt, ms...); err != nil {
+			return err
+		}
+		deleted = true
+	}
+	if deleted {
+		// rewrite blocks with tombstones, so that deleted samples are removed from disk
+		return p.db.CleanTombstones()
+	}
+	return nil
+}
+
+func (p *provider) getTTL(key string) time.Duration {
+	if p.Retention != nil {
+		return p.Retention.GetTTL(key)
+	}
+	return p.Cfg.DefaultTTL
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tsdb
+
+import (
+	"time"
+
+	"github.com/prometheus/tsdb"
+	"github.com/prometheus/tsdb/labels"
+
+	"github.com/erda-project/erda/modules/core/monitor/metric"
+	"github.com/erda-project/erda/modules/core/monitor/storekit"
+)
+
+// every field of a metric is stored as a series, the metric name, field name and retention key are labels of the series besides tags
+const (
+	labelMetricName   = "__name__"
+	labelFieldName    = "__field__"
+	labelRetentionKey = "__retention_key__"
+)
+
+// const value
+const (
+	metricTagMetricID = "_id"
+	metricTagTTL      = "_ttl"
+	metricTagTTLFixed = "fixed"
+)
+
+type writer struct {
+	p *provider
+}
+
+func (w *writer) WriteN(vals ...storekit.Data) (int, error) {
+	app := w.p.db.Appender()
+	var skipped int
+	for _, val := range vals {
+		m := val.(*metric.Metric)
+		ts := getUnixMillisecond(m.Timestamp)
+		base := w.p.seriesLabels(m)
+		for field, value := range m.Fields {
+			v, ok := toFloat64(value)
+			if !ok {
+				continue
+			}
+			lset := append(labels.Labels{{Name: labelFieldName, Value: field}}, base...)
+			if _, err := app.Add(labels.New(lset...), ts, v); err != nil {
+				switch err {
+				case tsdb.ErrOutOfBounds, tsdb.ErrOutOfOrderSample, tsdb.ErrAmendSample:
+					// the sample is too old for the head block, or the series already has a newer one
+					skipped++
+					continue
+				}
+				app.Rollback()
+				return 0, err
+			}
+		}
+	}
+	if err := app.Commit(); err != nil {
+		return 0, err
+	}
+	if skipped > 0 {
+		w.p.Log.Debugf("skipped %d samples which are out of order or out of bounds", skipped)
+	}
+	return len(vals), nil
+}
+
+func (w *writer) Close() error { return nil }
+
+// seriesLabels returns labels shared by series of every field of m
+func (p *provider) seriesLabels(m *metric.Metric) labels.Labels {
+	lset := make(labels.Labels, 0, len(m.Tags)+2)
+	lset = append(lset, labels.Label{Name: labelMetricName, Value: m.Name})
+	for k, v := range m.Tags {
+		if isReservedLabel(k) {
+			continue
+		}
+		lset = append(lset, labels.Label{Name: k, Value: v})
+	}
+	if p.Retention != nil && !isFixed(m.Tags) {
+		if key := p.Retention.GetConfigKey(m.Name, m.Tags); len(key) > 0 {
+			lset = append(lset, labels.Label{Name: labelRetentionKey, Value: key})
+		}
+	}
+	return lset
+}
+
+func isReservedLabel(name string) bool {
+	return name == labelMetricName || name == labelFieldName || name == labelRetentionKey
+}
+
+// isFixed returns whether the metric is a fixed one, which is only expired by max_ttl
+func isFixed(tags map[string]string) bool {
+	if ttl, ok := tags[metricTagTTL]; ok && ttl == metricTagTTLFixed {
+		return true
+	}
+	_, ok := tags[metricTagMetricID]
+	return ok
+}
+
+// toFloat64 convert numeric and bool field to float64, other fields can't be stored
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+const maxUnixMillisecond int64 = 9999999999999
+
+func getUnixMillisecond(ts int64) int64 {
+	if ts > maxUnixMillisecond {
+		return ts / int64(time.Millisecond)
+	}
+	return ts
+}
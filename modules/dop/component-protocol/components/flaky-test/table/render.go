@@ -0,0 +1,268 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package table
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda-infra/base/servicehub"
+	"github.com/erda-project/erda-infra/providers/component-protocol/cpregister/base"
+	"github.com/erda-project/erda-infra/providers/component-protocol/cptype"
+	"github.com/erda-project/erda-infra/providers/component-protocol/utils/cputil"
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/component-protocol/types"
+	"github.com/erda-project/erda/modules/dop/services/flakytest"
+)
+
+const (
+	operationQuarantine = "quarantine"
+	operationRelease    = "release"
+)
+
+type ComponentAction struct {
+	sdk *cptype.SDK
+	svc *flakytest.FlakyTest
+
+	Name       string                 `json:"name"`
+	Type       string                 `json:"type"`
+	Props      map[string]interface{} `json:"props"`
+	State      State                  `json:"state"`
+	Operations map[string]interface{} `json:"operations"`
+	Data       Data                   `json:"data"`
+	InParams   InParams               `json:"-"`
+}
+
+type InParams struct {
+	ApplicationID uint64 `json:"applicationId,omitempty"`
+}
+
+type State struct {
+	Values struct {
+		Branch string `json:"branch"`
+	} `json:"values"`
+}
+
+type Data struct {
+	List []DataItem `json:"list"`
+}
+
+type DataItem struct {
+	Key          string                 `json:"key"`
+	Name         string                 `json:"name"`
+	Suite        string                 `json:"suite"`
+	Score        string                 `json:"score"`
+	Runs         int                    `json:"runs"`
+	Failed       int                    `json:"failed"`
+	Flips        int                    `json:"flips"`
+	FlakyCommits int                    `json:"flakyCommits"`
+	LastFailedAt string                 `json:"lastFailedAt"`
+	Quarantined  string                 `json:"quarantined"`
+	Operate      map[string]interface{} `json:"operate"`
+}
+
+type operationMeta struct {
+	ID        uint64 `json:"id"`
+	Suite     string `json:"suite"`
+	Classname string `json:"classname"`
+	Name      string `json:"name"`
+}
+
+func (i *ComponentAction) setInParams(ctx context.Context) error {
+	b, err := json.Marshal(cputil.SDK(ctx).InParams)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, &i.InParams)
+}
+
+func (i *ComponentAction) GenComponentState(c *cptype.Component) error {
+	if c == nil || c.State == nil {
+		return nil
+	}
+	var state State
+	cont, err := json.Marshal(c.State)
+	if err != nil {
+		logrus.Errorf("marshal component state failed, content:%v, err:%v", c.State, err)
+		return err
+	}
+	err = json.Unmarshal(cont, &state)
+	if err != nil {
+		logrus.Errorf("unmarshal component state failed, content:%v, err:%v", cont, err)
+		return err
+	}
+	i.State = state
+	return nil
+}
+
+func (ca *ComponentAction) handleOperation(event cptype.ComponentEvent) error {
+	if event.Operation != operationQuarantine && event.Operation != operationRelease {
+		return nil
+	}
+	var meta operationMeta
+	cputil.MustObjJSONTransfer(event.OperationData["meta"], &meta)
+	identityInfo := apistructs.IdentityInfo{UserID: ca.sdk.Identity.UserID}
+
+	if event.Operation == operationQuarantine {
+		_, err := ca.svc.CreateQuarantine(apistructs.TestQuarantineCreateRequest{
+			ApplicationID: ca.InParams.ApplicationID,
+			Suite:         meta.Suite,
+			Classname:     meta.Classname,
+			Name:          meta.Name,
+			IdentityInfo:  identityInfo,
+		})
+		return err
+	}
+	_, err := ca.svc.DeleteQuarantine(meta.ID, identityInfo)
+	return err
+}
+
+func (ca *ComponentAction) setData(ctx context.Context) error {
+	tests, err := ca.svc.ListFlakyTests(apistructs.FlakyTestListRequest{
+		AppID:  ca.InParams.ApplicationID,
+		Branch: ca.State.Values.Branch,
+	})
+	if err != nil {
+		return err
+	}
+	quarantines, err := ca.svc.ListQuarantines(ca.InParams.ApplicationID)
+	if err != nil {
+		return err
+	}
+	quarantineIDs := make(map[string]uint64, len(quarantines))
+	for _, q := range quarantines {
+		quarantineIDs[q.Key] = q.ID
+	}
+
+	ca.Data = Data{List: make([]DataItem, 0, len(tests))}
+	for _, test := range tests {
+		item := DataItem{
+			Key:          test.Key,
+			Name:         test.Name,
+			Suite:        test.Suite,
+			Score:        fmt.Sprintf("%.1f%%", test.Score*100),
+			Runs:         test.Runs,
+			Failed:       test.Failed,
+			Flips:        test.Flips,
+			FlakyCommits: test.FlakyCommits,
+			Quarantined:  cputil.I18n(ctx, "no"),
+		}
+		if test.LastFailedAt != nil {
+			item.LastFailedAt = test.LastFailedAt.Format("2006-01-02 15:04:05")
+		}
+		operation := map[string]interface{}{
+			"key":    operationQuarantine,
+			"reload": true,
+			"text":   cputil.I18n(ctx, operationQuarantine),
+			"meta": operationMeta{
+				Suite:     test.Suite,
+				Classname: test.Classname,
+				Name:      test.Name,
+			},
+		}
+		if test.Quarantined {
+			item.Quarantined = cputil.I18n(ctx, "yes")
+			operation = map[string]interface{}{
+				"key":    operationRelease,
+				"reload": true,
+				"text":   cputil.I18n(ctx, operationRelease),
+				"meta":   operationMeta{ID: quarantineIDs[test.Key]},
+			}
+		}
+		item.Operate = map[string]interface{}{
+			"operations": map[string]interface{}{
+				operation["key"].(string): operation,
+			},
+			"renderType": "tableOperation",
+		}
+		ca.Data.List = append(ca.Data.List, item)
+	}
+	return nil
+}
+
+func (ca *ComponentAction) Render(ctx context.Context, c *cptype.Component, scenario cptype.Scenario, event cptype.ComponentEvent, gs *cptype.GlobalStateData) error {
+	if err := ca.GenComponentState(c); err != nil {
+		return err
+	}
+	if err := ca.setInParams(ctx); err != nil {
+		return err
+	}
+	ca.sdk = cputil.SDK(ctx)
+	ca.svc = ctx.Value(types.FlakyTestService).(*flakytest.FlakyTest)
+	ca.Name = "table"
+	ca.Type = "Table"
+
+	if err := ca.handleOperation(event); err != nil {
+		return err
+	}
+
+	ca.Props = map[string]interface{}{
+		"columns": []interface{}{
+			map[string]interface{}{
+				"dataIndex": "name",
+				"title":     cputil.I18n(ctx, "testName"),
+			},
+			map[string]interface{}{
+				"dataIndex": "suite",
+				"title":     cputil.I18n(ctx, "suite"),
+			},
+			map[string]interface{}{
+				"dataIndex": "score",
+				"title":     cputil.I18n(ctx, "flakyScore"),
+			},
+			map[string]interface{}{
+				"dataIndex": "runs",
+				"title":     cputil.I18n(ctx, "runs"),
+			},
+			map[string]interface{}{
+				"dataIndex": "failed",
+				"title":     cputil.I18n(ctx, "failed"),
+			},
+			map[string]interface{}{
+				"dataIndex": "flips",
+				"title":     cputil.I18n(ctx, "flips"),
+			},
+			map[string]interface{}{
+				"dataIndex": "flakyCommits",
+				"title":     cputil.I18n(ctx, "flakyCommits"),
+			},
+			map[string]interface{}{
+				"dataIndex": "lastFailedAt",
+				"title":     cputil.I18n(ctx, "lastFailedAt"),
+			},
+			map[string]interface{}{
+				"dataIndex": "quarantined",
+				"title":     cputil.I18n(ctx, "quarantined"),
+			},
+			map[string]interface{}{
+				"dataIndex": "operate",
+				"title":     cputil.I18n(ctx, "operations"),
+				"fixed":     "right",
+				"width":     120,
+			},
+		},
+		"rowKey": "key",
+	}
+	return ca.setData(ctx)
+}
+
+func init() {
+	base.InitProviderWithCreator("flaky-test", "table", func() servicehub.Provider {
+		return &ComponentAction{}
+	})
+}
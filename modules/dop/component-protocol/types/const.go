@@ -20,6 +20,7 @@ const (
 	IssueStateService      = "issueState"
 	IssueFilterBmService   = "issueFilterBookmark"
 	CodeCoverageService    = "codeCoverage"
+	FlakyTestService       = "flakyTest"
 	IssueService           = "issue"
 	IterationService       = "iteration"
 	ManualTestCaseService  = "manual_test_case"
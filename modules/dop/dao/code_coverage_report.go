@@ -0,0 +1,151 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+// CodeCoverageReport 流水线上传的 go/lcov/cobertura 覆盖率报告
+type CodeCoverageReport struct {
+	dbengine.BaseModel
+
+	ProjectID     uint64            `json:"project_id"`
+	AppID         uint64            `json:"app_id"`
+	PipelineID    uint64            `json:"pipeline_id"`
+	Branch        string            `json:"branch"`
+	CommitID      string            `json:"commit_id"`
+	Format        string            `json:"format"`
+	Coverage      float64           `json:"coverage"`
+	ReportContent CodeCoverageNodes `json:"report_content" sql:"TYPE:json"`
+	Files         CodeCoverageFiles `json:"files" sql:"TYPE:json"`
+	Creator       string            `json:"creator"`
+}
+
+type CodeCoverageFiles []*apistructs.CodeCoverageFile
+
+func (c CodeCoverageFiles) Value() (driver.Value, error) {
+	b, err := json.Marshal(c)
+	return string(b), err
+}
+
+func (c *CodeCoverageFiles) Scan(input interface{}) error {
+	bytes := input.([]byte)
+	if len(bytes) == 0 {
+		return nil
+	}
+	return json.Unmarshal(bytes, c)
+}
+
+func (CodeCoverageReport) TableName() string {
+	return "erda_code_coverage_report"
+}
+
+func (c *CodeCoverageReport) Covert() *apistructs.CodeCoverageReportDto {
+	return &apistructs.CodeCoverageReportDto{
+		ID:            c.ID,
+		ProjectID:     c.ProjectID,
+		AppID:         c.AppID,
+		PipelineID:    c.PipelineID,
+		Branch:        c.Branch,
+		CommitID:      c.CommitID,
+		Format:        c.Format,
+		Coverage:      c.Coverage,
+		ReportContent: c.ReportContent,
+		Creator:       c.Creator,
+		TimeCreated:   c.CreatedAt,
+	}
+}
+
+// CodeCoverageReportShort 列表查询时不加载报告内容和文件行数据
+type CodeCoverageReportShort struct {
+	dbengine.BaseModel
+
+	ProjectID  uint64  `json:"project_id"`
+	AppID      uint64  `json:"app_id"`
+	PipelineID uint64  `json:"pipeline_id"`
+	Branch     string  `json:"branch"`
+	CommitID   string  `json:"commit_id"`
+	Format     string  `json:"format"`
+	Coverage   float64 `json:"coverage"`
+	Creator    string  `json:"creator"`
+}
+
+func (CodeCoverageReportShort) TableName() string {
+	return "erda_code_coverage_report"
+}
+
+func (c *CodeCoverageReportShort) Covert() apistructs.CodeCoverageReportDto {
+	return apistructs.CodeCoverageReportDto{
+		ID:          c.ID,
+		ProjectID:   c.ProjectID,
+		AppID:       c.AppID,
+		PipelineID:  c.PipelineID,
+		Branch:      c.Branch,
+		CommitID:    c.CommitID,
+		Format:      c.Format,
+		Coverage:    c.Coverage,
+		Creator:     c.Creator,
+		TimeCreated: c.CreatedAt,
+	}
+}
+
+// CreateCodeCoverageReport .
+func (client *DBClient) CreateCodeCoverageReport(report *CodeCoverageReport) error {
+	return client.Create(report).Error
+}
+
+// GetCodeCoverageReportByID .
+func (client *DBClient) GetCodeCoverageReportByID(id uint64) (*CodeCoverageReport, error) {
+	var report CodeCoverageReport
+	err := client.Model(&CodeCoverageReport{}).First(&report, id).Error
+	return &report, err
+}
+
+// GetLatestCodeCoverageReport 获取应用某个提交的最新报告, commitID 为空时获取分支的最新报告
+func (client *DBClient) GetLatestCodeCoverageReport(appID uint64, branch, commitID string) (*CodeCoverageReport, error) {
+	var report CodeCoverageReport
+	db := client.Model(&CodeCoverageReport{}).Where("app_id = ?", appID)
+	if commitID != "" {
+		db = db.Where("commit_id = ?", commitID)
+	} else {
+		db = db.Where("branch = ?", branch)
+	}
+	err := db.Order("id DESC").First(&report).Error
+	return &report, err
+}
+
+// ListCodeCoverageReport .
+func (client *DBClient) ListCodeCoverageReport(req apistructs.CodeCoverageReportListRequest) (reports []CodeCoverageReportShort, total uint64, err error) {
+	offset := (req.PageNo - 1) * req.PageSize
+	db := client.Model(&CodeCoverageReportShort{}).
+		Where("app_id = ?", req.AppID)
+	if req.Branch != "" {
+		db = db.Where("branch = ?", req.Branch)
+	}
+	if req.CommitID != "" {
+		db = db.Where("commit_id = ?", req.CommitID)
+	}
+
+	err = db.Order("id DESC").
+		Offset(offset).Limit(req.PageSize).
+		Find(&reports).
+		Offset(0).Limit(-1).Count(&total).Error
+	return
+}
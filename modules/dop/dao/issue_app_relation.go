@@ -45,3 +45,12 @@ func (client *DBClient) DeleteIssueAppRelationsByComment(commentID int64) error
 func (client *DBClient) DeleteIssueAppRelationsByApp(appID int64) error {
 	return client.Where("app_id = ?", appID).Delete(&IssueAppRelation{}).Error
 }
+
+// IssueAppRelationExist 事项是否已经关联了该 MR
+func (client *DBClient) IssueAppRelationExist(issueID, appID, mrID int64) (bool, error) {
+	var count int64
+	err := client.Model(&IssueAppRelation{}).
+		Where("issue_id = ? AND app_id = ? AND mr_id = ?", issueID, appID, mrID).
+		Count(&count).Error
+	return count > 0, err
+}
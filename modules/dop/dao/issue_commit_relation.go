@@ -0,0 +1,47 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import "github.com/erda-project/erda/pkg/database/dbengine"
+
+// IssueCommitRelation 事项与提交的关联, 由提交信息或分支名中的事项引用生成
+type IssueCommitRelation struct {
+	dbengine.BaseModel
+
+	IssueID  int64
+	AppID    int64
+	CommitID string
+	Branch   string
+	StreamID int64
+}
+
+// TableName 表名
+func (IssueCommitRelation) TableName() string {
+	return "erda_issue_commit_relation"
+}
+
+// CreateIssueCommitRelation 创建事项提交关联关系
+func (client *DBClient) CreateIssueCommitRelation(rel *IssueCommitRelation) error {
+	return client.Create(rel).Error
+}
+
+// IssueCommitRelationExist 事项是否已经关联了该提交
+func (client *DBClient) IssueCommitRelationExist(issueID, appID int64, commitID string) (bool, error) {
+	var count int64
+	err := client.Model(&IssueCommitRelation{}).
+		Where("issue_id = ? AND app_id = ? AND commit_id = ?", issueID, appID, commitID).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -77,6 +77,19 @@ func FindTPRecordPagingByAppID(req apistructs.TestRecordPagingRequest) (*Paging,
 	}, nil
 }
 
+// FindTPRecordsWithSuitesByAppID 获取应用最近的测试记录及用例结果, 按 id 倒序
+func FindTPRecordsWithSuitesByAppID(appID uint64, branch string, limit int) ([]*TPRecordDO, error) {
+	var list []*TPRecordDO
+	session := cimysql.Engine.Select("id,branch,commit_id,suites,created_at").Where("app_id = ?", appID)
+	if branch != "" {
+		session = session.And("branch = ?", branch)
+	}
+	if err := session.Desc("id").Limit(limit).Find(&list); err != nil {
+		return nil, errors.Wrapf(err, "find tp records by appID=%d", appID)
+	}
+	return list, nil
+}
+
 func InsertTPRecord(r *TPRecordDO) (*TPRecordDO, error) {
 	var err error
 	var affected int64
@@ -66,6 +66,8 @@ type TPRecordDO struct {
 	Envs            map[string]string        `xorm:"varchar(1024) 'envs'" json:"envs"`
 	Workspace       apistructs.DiceWorkspace `xorm:"workspace" json:"workspace" validate:"required"`
 	Suites          []*apistructs.TestSuite  `xorm:"longtext 'suites'" json:"suites"`
+	// Blocking 是否存在未被隔离的失败用例, 仅在查询详情时计算
+	Blocking *bool `xorm:"-" json:"blocking,omitempty"`
 }
 
 func (TPRecordDO) TableName() string {
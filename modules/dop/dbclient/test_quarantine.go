@@ -0,0 +1,108 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbclient
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/database/cimysql"
+)
+
+// TestQuarantineDO 被隔离的单元测试用例，对应数据库表qa_test_quarantines
+type TestQuarantineDO struct {
+	ID        uint64    `xorm:"pk autoincr 'id'" json:"id"`
+	CreatedAt time.Time `xorm:"created" json:"createdAt"`
+	UpdatedAt time.Time `xorm:"updated" json:"updatedAt"`
+
+	ApplicationID uint64 `xorm:"app_id" json:"applicationId"`
+	TestKey       string `xorm:"test_key" json:"key"`
+	Suite         string `xorm:"suite" json:"suite"`
+	Classname     string `xorm:"classname" json:"classname"`
+	Name          string `xorm:"name" json:"name"`
+	Reason        string `xorm:"reason" json:"reason"`
+	OperatorID    string `xorm:"operator_id" json:"operatorId"`
+}
+
+func (TestQuarantineDO) TableName() string {
+	return "qa_test_quarantines"
+}
+
+func (q *TestQuarantineDO) Convert() apistructs.TestQuarantine {
+	return apistructs.TestQuarantine{
+		ID:            q.ID,
+		ApplicationID: q.ApplicationID,
+		Key:           q.TestKey,
+		Suite:         q.Suite,
+		Classname:     q.Classname,
+		Name:          q.Name,
+		Reason:        q.Reason,
+		OperatorID:    q.OperatorID,
+		CreatedAt:     q.CreatedAt,
+	}
+}
+
+// InsertTestQuarantine 隔离用例
+func InsertTestQuarantine(q *TestQuarantineDO) error {
+	if _, err := cimysql.Engine.InsertOne(q); err != nil {
+		return errors.Errorf("failed to insert test quarantine, appID: %d, key: %s, (%+v)", q.ApplicationID, q.TestKey, err)
+	}
+	return nil
+}
+
+// GetTestQuarantine 根据 id 获取被隔离的用例
+func GetTestQuarantine(id uint64) (*TestQuarantineDO, error) {
+	q := new(TestQuarantineDO)
+	success, err := cimysql.Engine.ID(id).Get(q)
+	if err != nil {
+		return nil, errors.Errorf("failed to get test quarantine, id: %d, (%+v)", id, err)
+	}
+	if !success {
+		return nil, errors.Errorf("test quarantine not found, id: %d", id)
+	}
+	return q, nil
+}
+
+// FindTestQuarantineByKey 根据应用和用例标识获取被隔离的用例, 未隔离时返回 nil
+func FindTestQuarantineByKey(appID uint64, key string) (*TestQuarantineDO, error) {
+	q := new(TestQuarantineDO)
+	success, err := cimysql.Engine.Where("app_id = ? AND test_key = ?", appID, key).Get(q)
+	if err != nil {
+		return nil, errors.Errorf("failed to get test quarantine, appID: %d, key: %s, (%+v)", appID, key, err)
+	}
+	if !success {
+		return nil, nil
+	}
+	return q, nil
+}
+
+// ListTestQuarantinesByAppID 获取应用下所有被隔离的用例
+func ListTestQuarantinesByAppID(appID uint64) ([]*TestQuarantineDO, error) {
+	var list []*TestQuarantineDO
+	if err := cimysql.Engine.Where("app_id = ?", appID).Desc("id").Find(&list); err != nil {
+		return nil, errors.Errorf("failed to list test quarantines, appID: %d, (%+v)", appID, err)
+	}
+	return list, nil
+}
+
+// DeleteTestQuarantine 解除隔离
+func DeleteTestQuarantine(id uint64) error {
+	if _, err := cimysql.Engine.ID(id).Delete(new(TestQuarantineDO)); err != nil {
+		return errors.Errorf("failed to delete test quarantine, id: %d, (%+v)", id, err)
+	}
+	return nil
+}
@@ -93,6 +93,13 @@ func (e *Endpoints) GetTestRecord(ctx context.Context, r *http.Request, vars map
 
 	record.EraseSensitiveInfo()
 
+	// 被隔离的用例失败时不阻塞
+	blocking, err := e.flakyTest.MarkQuarantined(record)
+	if err != nil {
+		return apierrors.ErrGetTestRecord.InternalError(err).ToResp(), nil
+	}
+	record.Blocking = &blocking
+
 	return httpserver.OkResp(record)
 }
 
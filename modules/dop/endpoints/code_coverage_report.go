@@ -0,0 +1,121 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/pkg/user"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+)
+
+// UploadCodeCoverageReport upload go coverprofile, lcov or cobertura report
+func (e *Endpoints) UploadCodeCoverageReport(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrUploadCodeCoverageReport.NotLogin().ToResp(), nil
+	}
+
+	var req apistructs.CodeCoverageReportUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrUploadCodeCoverageReport.InvalidParameter(err).ToResp(), nil
+	}
+	if err = req.Validate(); err != nil {
+		return apierrors.ErrUploadCodeCoverageReport.InvalidParameter(err).ToResp(), nil
+	}
+	req.IdentityInfo = identityInfo
+
+	report, err := e.codeCoverageSvc.UploadReport(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(report)
+}
+
+// ListCodeCoverageReport list code coverage reports of application
+func (e *Endpoints) ListCodeCoverageReport(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	if _, err := user.GetIdentityInfo(r); err != nil {
+		return apierrors.ErrListCodeCoverageReport.NotLogin().ToResp(), nil
+	}
+
+	var req apistructs.CodeCoverageReportListRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrListCodeCoverageReport.InvalidParameter(err).ToResp(), nil
+	}
+	if err := req.Validate(); err != nil {
+		return apierrors.ErrListCodeCoverageReport.InvalidParameter(err).ToResp(), nil
+	}
+
+	data, err := e.codeCoverageSvc.ListCodeCoverageReport(req)
+	if err != nil {
+		return apierrors.ErrListCodeCoverageReport.InternalError(err).ToResp(), nil
+	}
+
+	userIDs := make([]string, 0, len(data.List))
+	for _, v := range data.List {
+		userIDs = append(userIDs, v.Creator)
+	}
+	return httpserver.OkResp(data, userIDs)
+}
+
+// GetCodeCoverageReport get code coverage report
+func (e *Endpoints) GetCodeCoverageReport(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	if _, err := user.GetIdentityInfo(r); err != nil {
+		return apierrors.ErrGetCodeCoverageReport.NotLogin().ToResp(), nil
+	}
+
+	id, err := strconv.ParseUint(vars["id"], 10, 64)
+	if err != nil {
+		return apierrors.ErrGetCodeCoverageReport.InvalidParameter("id").ToResp(), nil
+	}
+
+	report, err := e.codeCoverageSvc.GetCodeCoverageReport(id)
+	if err != nil {
+		return apierrors.ErrGetCodeCoverageReport.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(report, []string{report.Creator})
+}
+
+// GetCodeCoverageMRDelta get changed-lines coverage of merge request
+func (e *Endpoints) GetCodeCoverageMRDelta(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetCodeCoverageMRDelta.NotLogin().ToResp(), nil
+	}
+
+	var req apistructs.CodeCoverageMRDeltaRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrGetCodeCoverageMRDelta.InvalidParameter(err).ToResp(), nil
+	}
+	if err = req.Validate(); err != nil {
+		return apierrors.ErrGetCodeCoverageMRDelta.InvalidParameter(err).ToResp(), nil
+	}
+	req.IdentityInfo = identityInfo
+
+	delta, err := e.codeCoverageSvc.GetMRCoverageDelta(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(delta)
+}
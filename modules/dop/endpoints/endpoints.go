@@ -43,6 +43,7 @@ import (
 	"github.com/erda-project/erda/modules/dop/services/cq"
 	"github.com/erda-project/erda/modules/dop/services/environment"
 	"github.com/erda-project/erda/modules/dop/services/filetree"
+	"github.com/erda-project/erda/modules/dop/services/flakytest"
 	"github.com/erda-project/erda/modules/dop/services/issue"
 	"github.com/erda-project/erda/modules/dop/services/issuepanel"
 	"github.com/erda-project/erda/modules/dop/services/issueproperty"
@@ -283,6 +284,11 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/qa/test/{id}", Method: http.MethodGet, Handler: e.GetTestRecord},
 		{Path: "/api/qa/actions/test-callback", Method: http.MethodPost, Handler: e.TestCallback},
 		{Path: "/api/qa/actions/get-sonar-credential", Method: http.MethodGet, Handler: e.GetSonarCredential},
+		{Path: "/api/qa/flaky-tests", Method: http.MethodGet, Handler: e.ListFlakyTests},
+		{Path: "/api/qa/flaky-tests/actions/history", Method: http.MethodGet, Handler: e.GetFlakyTestHistory},
+		{Path: "/api/qa/test-quarantines", Method: http.MethodPost, Handler: e.CreateTestQuarantine},
+		{Path: "/api/qa/test-quarantines", Method: http.MethodGet, Handler: e.ListTestQuarantines},
+		{Path: "/api/qa/test-quarantines/{id}", Method: http.MethodDelete, Handler: e.DeleteTestQuarantine},
 
 		// pmp api test
 		{Path: "/api/apitests", Method: http.MethodPost, Handler: e.CreateAPITest},
@@ -610,6 +616,10 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/code-coverage/records/actions/list", Method: http.MethodGet, Handler: e.ListCodeCoverageRecord},
 		{Path: "/api/code-coverage/record/{id}", Method: http.MethodGet, Handler: e.GetCodeCoverageRecord},
 		{Path: "/api/code-coverage/actions/status", Method: http.MethodGet, Handler: e.GetCodeCoverageRecordStatus},
+		{Path: "/api/code-coverage/reports/actions/upload", Method: http.MethodPost, Handler: e.UploadCodeCoverageReport},
+		{Path: "/api/code-coverage/reports/actions/list", Method: http.MethodGet, Handler: e.ListCodeCoverageReport},
+		{Path: "/api/code-coverage/reports/actions/mr-delta", Method: http.MethodGet, Handler: e.GetCodeCoverageMRDelta},
+		{Path: "/api/code-coverage/report/{id}", Method: http.MethodGet, Handler: e.GetCodeCoverageReport},
 
 		// test report
 		{Path: "/api/projects/{projectID}/test-reports", Method: http.MethodPost, Handler: e.CreateTestReportRecord},
@@ -716,6 +726,7 @@ type Endpoints struct {
 	app             *application.Application
 	codeCoverageSvc *code_coverage.CodeCoverage
 	testReportSvc   *test_report.TestReport
+	flakyTest       *flakytest.FlakyTest
 
 	ImportChannel chan uint64
 	ExportChannel chan uint64
@@ -1077,6 +1088,12 @@ func WithTestReportRecord(svc *test_report.TestReport) Option {
 	}
 }
 
+func WithFlakyTest(svc *flakytest.FlakyTest) Option {
+	return func(e *Endpoints) {
+		e.flakyTest = svc
+	}
+}
+
 var queryStringDecoder *schema.Decoder
 
 func init() {
@@ -1108,6 +1125,10 @@ func (e *Endpoints) CodeCoverageService() *code_coverage.CodeCoverage {
 	return e.codeCoverageSvc
 }
 
+func (e *Endpoints) FlakyTestService() *flakytest.FlakyTest {
+	return e.flakyTest
+}
+
 func (e *Endpoints) IterationService() *iteration.Iteration {
 	return e.iteration
 }
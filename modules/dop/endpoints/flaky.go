@@ -0,0 +1,115 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/pkg/user"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+)
+
+// ListFlakyTests 列出应用最不稳定的单元测试用例
+func (e *Endpoints) ListFlakyTests(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req apistructs.FlakyTestListRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrListFlakyTests.InvalidParameter(err).ToResp(), nil
+	}
+
+	tests, err := e.flakyTest.ListFlakyTests(req)
+	if err != nil {
+		return apierrors.ErrListFlakyTests.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(tests)
+}
+
+// GetFlakyTestHistory 获取单元测试用例的执行历史
+func (e *Endpoints) GetFlakyTestHistory(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req apistructs.FlakyTestHistoryRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrGetFlakyTestHistory.InvalidParameter(err).ToResp(), nil
+	}
+
+	test, err := e.flakyTest.GetFlakyTestHistory(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(test)
+}
+
+// CreateTestQuarantine 隔离单元测试用例
+func (e *Endpoints) CreateTestQuarantine(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrCreateTestQuarantine.NotLogin().ToResp(), nil
+	}
+	if r.ContentLength == 0 {
+		return apierrors.ErrCreateTestQuarantine.MissingParameter(apierrors.MissingRequestBody).ToResp(), nil
+	}
+	var req apistructs.TestQuarantineCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrCreateTestQuarantine.InvalidParameter(err).ToResp(), nil
+	}
+	req.IdentityInfo = identityInfo
+
+	q, err := e.flakyTest.CreateQuarantine(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(q)
+}
+
+// ListTestQuarantines 列出应用下被隔离的单元测试用例
+func (e *Endpoints) ListTestQuarantines(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req apistructs.TestQuarantineListRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrListTestQuarantines.InvalidParameter(err).ToResp(), nil
+	}
+
+	list, err := e.flakyTest.ListQuarantines(req.AppID)
+	if err != nil {
+		return apierrors.ErrListTestQuarantines.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(list)
+}
+
+// DeleteTestQuarantine 解除单元测试用例的隔离
+func (e *Endpoints) DeleteTestQuarantine(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrDeleteTestQuarantine.NotLogin().ToResp(), nil
+	}
+	id, err := strconv.ParseUint(vars["id"], 10, 64)
+	if err != nil {
+		return apierrors.ErrDeleteTestQuarantine.InvalidParameter(err).ToResp(), nil
+	}
+
+	q, err := e.flakyTest.DeleteQuarantine(id, identityInfo)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(q)
+}
@@ -65,6 +65,7 @@ func (e *Endpoints) ReleaseCallback(ctx context.Context, r *http.Request, vars m
 		refName = strings.TrimPrefix(req.Content.Ref, "refs/tags/")
 	} else {
 		refName = strings.TrimPrefix(req.Content.Ref, "refs/heads/")
+		go e.linkPushIssues(appID, refName, req.Content)
 	}
 
 	// 从 gittar 获取 pipeline.yml
@@ -19,10 +19,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/conf"
 	"github.com/erda-project/erda/modules/dop/services/apierrors"
 	"github.com/erda-project/erda/pkg/http/httpserver"
 )
@@ -58,9 +60,107 @@ func (e *Endpoints) RepoMrEventCallback(ctx context.Context, r *http.Request, va
 	}
 
 	e.TriggerGitNotify(gitEvent.OrgID, gitEvent.ApplicationID, gitEvent.Content.EventName, params)
+
+	switch gitEvent.Content.EventName {
+	case apistructs.GitCreateMREvent, apistructs.GitMergeMREvent:
+		go e.linkMRIssues(gitEvent.Content)
+	}
 	return httpserver.OkResp("")
 }
 
+// linkMRIssues 关联 MR 引用的事项, MR 合入默认分支时将使用了关闭关键字的事项迁移到完成状态
+func (e *Endpoints) linkMRIssues(mr apistructs.MergeRequestInfo) {
+	refs, err := e.issueStream.LinkMR(&mr)
+	if err != nil {
+		logrus.Errorf("failed to link issues to mr, appID: %d, mrID: %d, (%+v)", mr.AppID, mr.RepoMergeId, err)
+		return
+	}
+	if mr.EventName != apistructs.GitMergeMREvent || !conf.IssueSmartRefAutoTransition() {
+		return
+	}
+	var closing []int64
+	for _, ref := range refs {
+		if ref.Closing {
+			closing = append(closing, ref.IssueID)
+		}
+	}
+	if len(closing) == 0 {
+		return
+	}
+	stats, err := e.bdl.GetGittarStats(mr.AppID, mr.MergeUserId)
+	if err != nil {
+		logrus.Errorf("failed to get repo stats, appID: %d, (%+v)", mr.AppID, err)
+		return
+	}
+	if mr.TargetBranch != stats.DefaultBranch {
+		return
+	}
+	for _, issueID := range closing {
+		if err := e.transitIssueToDone(issueID, mr.MergeUserId); err != nil {
+			logrus.Errorf("failed to transit issue to done state, issueID: %d, mrID: %d, (%+v)", issueID, mr.RepoMergeId, err)
+		}
+	}
+}
+
+// transitIssueToDone 以 MR 合并人的身份迁移事项状态, 合并人需要有事项的更新权限
+func (e *Endpoints) transitIssueToDone(issueID int64, userID string) error {
+	issueModel, err := e.db.GetIssue(issueID)
+	if err != nil {
+		return err
+	}
+	state, ok, err := e.issueStream.GetDoneState(issueModel)
+	if err != nil || !ok {
+		return err
+	}
+	if userID != issueModel.Creator && userID != issueModel.Assignee {
+		access, err := e.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+			UserID:   userID,
+			Scope:    apistructs.ProjectScope,
+			ScopeID:  issueModel.ProjectID,
+			Resource: issueModel.Type.GetCorrespondingResource(),
+			Action:   apistructs.UpdateAction,
+		})
+		if err != nil {
+			return err
+		}
+		if !access.Access {
+			logrus.Infof("user %s has no permission to update issue %d, skip transition", userID, issueID)
+			return nil
+		}
+	}
+	return e.issue.UpdateIssue(apistructs.IssueUpdateRequest{
+		ID:           uint64(issueID),
+		State:        &state,
+		IdentityInfo: apistructs.IdentityInfo{UserID: userID},
+	})
+}
+
+// maxLinkPushCommits 一次推送最多处理的提交数, 如强制推送或推送了很长的历史
+const maxLinkPushCommits = 1000
+
+// linkPushIssues 关联推送的提交引用的事项, 新建分支时只处理分支头, 删除分支时不处理
+func (e *Endpoints) linkPushIssues(appID uint64, branch string, push apistructs.GittarPushEventRequest) {
+	if push.Pusher == nil || strings.Trim(push.After, "0") == "" {
+		return
+	}
+	commits := []apistructs.Commit{{ID: push.After}}
+	if strings.Trim(push.Before, "0") != "" {
+		pushed, total, err := e.bdl.ListGittarCompareCommits(push.After, push.Before, int64(appID), push.Pusher.ID, maxLinkPushCommits)
+		if err != nil {
+			logrus.Errorf("failed to get pushed commits, appID: %d, ref: %s, (%+v)", appID, push.Ref, err)
+			return
+		}
+		if total > len(pushed) {
+			logrus.Warnf("too many pushed commits, only the latest %d of %d are linked to issues, appID: %d, ref: %s",
+				len(pushed), total, appID, push.Ref)
+		}
+		commits = pushed
+	}
+	if err := e.issueStream.LinkCommits(appID, branch, push.After, push.Pusher.ID, commits); err != nil {
+		logrus.Errorf("failed to link issues to commits, appID: %d, ref: %s, (%+v)", appID, push.Ref, err)
+	}
+}
+
 // RepoBranchEventCallback Branch事件回调
 func (e *Endpoints) RepoBranchEventCallback(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
 	var gitEvent apistructs.RepoBranchEvent
@@ -51,6 +51,7 @@ import (
 	"github.com/erda-project/erda/modules/dop/services/cq"
 	"github.com/erda-project/erda/modules/dop/services/environment"
 	"github.com/erda-project/erda/modules/dop/services/filetree"
+	"github.com/erda-project/erda/modules/dop/services/flakytest"
 	"github.com/erda-project/erda/modules/dop/services/issue"
 	"github.com/erda-project/erda/modules/dop/services/issuefilterbm"
 	"github.com/erda-project/erda/modules/dop/services/issuepanel"
@@ -135,6 +136,7 @@ func (p *provider) Initialize(ctx servicehub.Context) error {
 		issuefilterbm.WithDBClient(db),
 	))
 	p.Protocol.WithContextValue(types.CodeCoverageService, ep.CodeCoverageService())
+	p.Protocol.WithContextValue(types.FlakyTestService, ep.FlakyTestService())
 	p.Protocol.WithContextValue(types.IssueService, ep.IssueService())
 	p.Protocol.WithContextValue(types.IterationService, ep.IterationService())
 	p.Protocol.WithContextValue(types.ManualTestCaseService, ep.ManualTestCaseService())
@@ -579,6 +581,10 @@ func (p *provider) initEndpoints(db *dao.DBClient) (*endpoints.Endpoints, error)
 		test_report.WithBundle(bdl.Bdl),
 	)
 
+	flakyTestSvc := flakytest.New(
+		flakytest.WithBundle(bdl.Bdl),
+	)
+
 	pipelineSvc := pipeline.New(
 		pipeline.WithBundle(bdl.Bdl),
 		pipeline.WithBranchRuleSvc(branchRule),
@@ -646,6 +652,7 @@ func (p *provider) initEndpoints(db *dao.DBClient) (*endpoints.Endpoints, error)
 		endpoints.WithOrg(o),
 		endpoints.WithCodeCoverageExecRecord(codeCvc),
 		endpoints.WithTestReportRecord(testReportSvc),
+		endpoints.WithFlakyTest(flakyTestSvc),
 	)
 
 	ep.ImportChannel = make(chan uint64)
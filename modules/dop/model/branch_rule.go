@@ -31,6 +31,7 @@ type BranchRule struct {
 	Desc              string //规则说明
 	Workspace         string `json:"workspace"`
 	ArtifactWorkspace string `json:"artifactWorkspace"`
+	RequiredApprovals int    `json:"requiredApprovals"`
 }
 
 // TableName 设置模型对应数据库表名称
@@ -50,5 +51,6 @@ func (rule *BranchRule) ToApiData() *apistructs.BranchRule {
 		Desc:              rule.Desc,
 		Workspace:         rule.Workspace,
 		ArtifactWorkspace: rule.ArtifactWorkspace,
+		RequiredApprovals: rule.RequiredApprovals,
 	}
 }
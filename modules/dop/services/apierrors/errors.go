@@ -180,6 +180,12 @@ var (
 	ErrPagingTestRecords = err("ErrPagingTestRecords", "测试记录分页查询失败")
 	ErrGetTestRecord     = err("ErrGetTestRecord", "查询测试记录详情失败")
 
+	ErrListFlakyTests       = err("ErrListFlakyTests", "查询不稳定用例失败")
+	ErrGetFlakyTestHistory  = err("ErrGetFlakyTestHistory", "查询不稳定用例执行历史失败")
+	ErrCreateTestQuarantine = err("ErrCreateTestQuarantine", "隔离用例失败")
+	ErrListTestQuarantines  = err("ErrListTestQuarantines", "查询隔离用例失败")
+	ErrDeleteTestQuarantine = err("ErrDeleteTestQuarantine", "解除用例隔离失败")
+
 	ErrCreateAPITestEnv = err("ErrCreateAPITestEnv", "创建接口测试环境失败")
 	ErrUpdateAPITestEnv = err("ErrUpdateAPITestEnv", "更新接口测试环境失败")
 	ErrGetAPITestEnv    = err("ErrGetAPITestEnv", "查询接口测试环境失败")
@@ -500,6 +506,10 @@ var (
 	ErrGetCodeCoverageExecRecord    = err("ErrUpdateCodeCoverageExecRecord", "获取代码覆盖率执行记录失败")
 	ErrListCodeCoverageExecRecord   = err("ErrUpdateCodeCoverageExecRecord", "列表获取代码覆盖率执行记录失败")
 	ErSaveCodeCoverageSetting       = err("ErSaveCodeCoverageSetting", "保存代码覆盖率配置失败")
+	ErrUploadCodeCoverageReport     = err("ErrUploadCodeCoverageReport", "上传代码覆盖率报告失败")
+	ErrListCodeCoverageReport       = err("ErrListCodeCoverageReport", "查询代码覆盖率报告列表失败")
+	ErrGetCodeCoverageReport        = err("ErrGetCodeCoverageReport", "获取代码覆盖率报告失败")
+	ErrGetCodeCoverageMRDelta       = err("ErrGetCodeCoverageMRDelta", "获取合并请求变更行覆盖率失败")
 
 	ErrCreateTestReportRecord = err("ErrCreateTestReportRecord", "创建测试报告记录失败")
 	ErrListTestReportRecord   = err("ErrListTestReportRecord", "查询测试报告记录失败")
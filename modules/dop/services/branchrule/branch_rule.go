@@ -109,6 +109,7 @@ func (branchRule *BranchRule) Update(request apistructs.UpdateBranchRuleRequest)
 	rule.Workspace = request.Workspace
 	rule.ArtifactWorkspace = request.ArtifactWorkspace
 	rule.NeedApproval = request.NeedApproval
+	rule.RequiredApprovals = request.RequiredApprovals
 	err = branchRule.CheckRuleValid(&rule)
 	if err != nil {
 		return nil, err
@@ -131,6 +132,7 @@ func (branchRule *BranchRule) Create(request apistructs.CreateBranchRuleRequest)
 		ArtifactWorkspace: request.ArtifactWorkspace,
 		NeedApproval:      request.NeedApproval,
 		Desc:              request.Desc,
+		RequiredApprovals: request.RequiredApprovals,
 	}
 	err := branchRule.CheckRuleValid(&rule)
 	if err != nil {
@@ -173,6 +175,9 @@ func isRuleMatch(sourceRule, targetRule string) bool {
 }
 
 func (branchRule *BranchRule) CheckRuleValid(newBranchRule *model.BranchRule) error {
+	if newBranchRule.RequiredApprovals < 0 {
+		return fmt.Errorf("invalid required approvals %d", newBranchRule.RequiredApprovals)
+	}
 	// check duplicate
 	currentRules, err := branchRule.Query(newBranchRule.ScopeType, newBranchRule.ScopeID)
 	if err != nil {
@@ -0,0 +1,152 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package code_coverage
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+const (
+	diffLineAdd    = "add"
+	diffFileDelete = "delete"
+)
+
+// GetMRCoverageDelta 结合 gittar compare 计算 MR 变更行的覆盖率
+func (svc *CodeCoverage) GetMRCoverageDelta(req apistructs.CodeCoverageMRDeltaRequest) (*apistructs.CodeCoverageDelta, error) {
+	mr, err := svc.bdl.GetMergeRequestDetail(int64(req.AppID), int(req.MergeID), req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var report *dao.CodeCoverageReport
+	if req.ReportID != 0 {
+		report, err = svc.db.GetCodeCoverageReportByID(req.ReportID)
+		if err == nil && report.AppID != req.AppID {
+			return nil, apierrors.ErrGetCodeCoverageMRDelta.InvalidParameter(
+				fmt.Errorf("report %d does not belong to app %d", req.ReportID, req.AppID))
+		}
+	} else {
+		// 优先使用 MR 源分支当前提交的报告, 没有时使用源分支的最新报告
+		report, err = svc.db.GetLatestCodeCoverageReport(req.AppID, mr.SourceBranch, mr.SourceSha)
+		if gorm.IsRecordNotFoundError(err) {
+			report, err = svc.db.GetLatestCodeCoverageReport(req.AppID, mr.SourceBranch, "")
+		}
+	}
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, apierrors.ErrGetCodeCoverageMRDelta.NotFound()
+	}
+	if err != nil {
+		return nil, apierrors.ErrGetCodeCoverageMRDelta.InternalError(err)
+	}
+
+	after := report.CommitID
+	if after == "" {
+		after = mr.SourceSha
+	}
+	compare, err := svc.bdl.GetGittarCompare(after, mr.TargetSha, int64(req.AppID), req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := ComputeCoverageDelta(report.Files, compare.Diff)
+	delta.AppID = req.AppID
+	delta.MergeID = req.MergeID
+	delta.ReportID = report.ID
+	delta.SourceBranch = mr.SourceBranch
+	delta.TargetBranch = mr.TargetBranch
+	delta.CommitID = after
+	delta.Coverage = report.Coverage
+	return delta, nil
+}
+
+// ComputeCoverageDelta 统计 diff 中新增行的覆盖情况, 不在报告中的行(空行, 注释, 非源码文件等)不计入
+func ComputeCoverageDelta(files []*apistructs.CodeCoverageFile, diff apistructs.Diff) *apistructs.CodeCoverageDelta {
+	delta := &apistructs.CodeCoverageDelta{Files: []apistructs.CodeCoverageFileDelta{}}
+	for _, df := range diff.Files {
+		if df.Type == diffFileDelete || df.IsBin {
+			continue
+		}
+		f := matchCoverageFile(files, df.Name)
+		if f == nil {
+			continue
+		}
+		fd := apistructs.CodeCoverageFileDelta{Name: df.Name, UncoveredLines: []int{}}
+		for _, section := range df.Sections {
+			for _, line := range section.Lines {
+				if line.Type != diffLineAdd {
+					continue
+				}
+				hits, ok := f.Lines[line.NewLineNo]
+				if !ok {
+					continue
+				}
+				fd.ChangedLines++
+				if hits > 0 {
+					fd.CoveredLines++
+				} else {
+					fd.UncoveredLines = append(fd.UncoveredLines, line.NewLineNo)
+				}
+			}
+		}
+		if fd.ChangedLines == 0 {
+			continue
+		}
+		sort.Ints(fd.UncoveredLines)
+		fd.DeltaCoverage = percent(fd.CoveredLines, fd.ChangedLines)
+		delta.ChangedLines += fd.ChangedLines
+		delta.CoveredLines += fd.CoveredLines
+		delta.Files = append(delta.Files, fd)
+	}
+	delta.DeltaCoverage = percent(delta.CoveredLines, delta.ChangedLines)
+	return delta
+}
+
+// matchCoverageFile 报告中的路径可能带有 module 路径或构建目录前缀, 也可能相对于 cobertura 的 source 目录,
+// 因此除了完全相同之外, 优先匹配以 diff 路径结尾的文件, 其次匹配 diff 路径以其结尾且最长的文件
+func matchCoverageFile(files []*apistructs.CodeCoverageFile, name string) *apistructs.CodeCoverageFile {
+	name = cleanPath(name)
+	var prefixed, relative *apistructs.CodeCoverageFile
+	for _, f := range files {
+		switch {
+		case f.Path == name:
+			return f
+		case prefixed == nil && strings.HasSuffix(f.Path, "/"+name):
+			prefixed = f
+		case strings.HasSuffix(name, "/"+f.Path) && (relative == nil || len(f.Path) > len(relative.Path)):
+			relative = f
+		}
+	}
+	if prefixed != nil {
+		return prefixed
+	}
+	return relative
+}
+
+func percent(covered, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	value, _ := strconv.ParseFloat(fmt.Sprintf("%.2f", float64(covered)/float64(total)*100), 64)
+	return value
+}
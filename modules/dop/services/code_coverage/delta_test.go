@@ -0,0 +1,89 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package code_coverage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestComputeCoverageDelta(t *testing.T) {
+	files, err := ParseReport(apistructs.LcovFormat, readTestdata(t, "lcov.info"))
+	assert.NoError(t, err)
+
+	diff := apistructs.Diff{
+		Files: []*apistructs.DiffFile{
+			{
+				Name: "src/calc.js",
+				Type: "modified",
+				Sections: []*apistructs.DiffSection{{Lines: []*apistructs.DiffLine{
+					{Type: "section", Content: "@@ -1,3 +1,5 @@"},
+					{Type: "context", OldLineNo: 1, NewLineNo: 1},
+					{Type: "add", OldLineNo: -1, NewLineNo: 2},
+					{Type: "add", OldLineNo: -1, NewLineNo: 3},
+					{Type: "delete", OldLineNo: 2, NewLineNo: -1},
+					{Type: "add", OldLineNo: -1, NewLineNo: 5},
+				}}},
+			},
+			{
+				Name: "src/util/format.js",
+				Type: "delete",
+				Sections: []*apistructs.DiffSection{{Lines: []*apistructs.DiffLine{
+					{Type: "delete", OldLineNo: 3, NewLineNo: -1},
+				}}},
+			},
+			{
+				Name: "README.md",
+				Type: "modified",
+				Sections: []*apistructs.DiffSection{{Lines: []*apistructs.DiffLine{
+					{Type: "add", OldLineNo: -1, NewLineNo: 1},
+				}}},
+			},
+		},
+	}
+
+	delta := ComputeCoverageDelta(files, diff)
+	// 第 3 行不是可执行行, 不计入
+	assert.Equal(t, 2, delta.ChangedLines)
+	assert.Equal(t, 1, delta.CoveredLines)
+	assert.Equal(t, float64(50), delta.DeltaCoverage)
+	assert.Equal(t, []apistructs.CodeCoverageFileDelta{
+		{Name: "src/calc.js", ChangedLines: 2, CoveredLines: 1, DeltaCoverage: 50, UncoveredLines: []int{5}},
+	}, delta.Files)
+}
+
+func TestComputeCoverageDeltaNoChangedLines(t *testing.T) {
+	delta := ComputeCoverageDelta(nil, apistructs.Diff{})
+	assert.Equal(t, 0, delta.ChangedLines)
+	assert.Equal(t, float64(100), delta.DeltaCoverage)
+	assert.Equal(t, 0, len(delta.Files))
+}
+
+func TestMatchCoverageFile(t *testing.T) {
+	files := []*apistructs.CodeCoverageFile{
+		{Path: "github.com/erda-project/demo/pkg/calc/calc.go"},
+		{Path: "calc.go"},
+		{Path: "app/calc.py"},
+		{Path: "calc.py"},
+	}
+	assert.Equal(t, files[1], matchCoverageFile(files, "calc.go"))
+	assert.Equal(t, files[0], matchCoverageFile(files, "pkg/calc/calc.go"))
+	assert.Equal(t, files[2], matchCoverageFile(files, "src/app/calc.py"))
+	assert.Equal(t, files[3], matchCoverageFile(files, "calc.py"))
+	assert.Nil(t, matchCoverageFile(files, "pkg/calc/calc_test.go"))
+}
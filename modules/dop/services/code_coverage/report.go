@@ -0,0 +1,169 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package code_coverage
+
+import (
+	"path"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+// ConvertFilesToReport 将文件行覆盖数据归一化为 CodeTestReport, 以源文件所在目录作为 package, 源文件作为 class
+func ConvertFilesToReport(projectID uint64, projectName string, files []*apistructs.CodeCoverageFile) apistructs.CodeTestReport {
+	report := apistructs.CodeTestReport{
+		ProjectID:   projectID,
+		ProjectName: projectName,
+	}
+	if len(files) == 0 {
+		return report
+	}
+
+	var (
+		packages = make(map[string]*apistructs.ReportPackage)
+		names    []string
+		total    = newCounters()
+	)
+	for _, f := range files {
+		covered, missed := 0, 0
+		for _, hits := range f.Lines {
+			if hits > 0 {
+				covered++
+			} else {
+				missed++
+			}
+		}
+		classCovered, classMissed := 0, 1
+		if covered > 0 {
+			classCovered, classMissed = 1, 0
+		}
+		counters := []apistructs.ReportCounter{
+			{Type: apistructs.LineCounter, Covered: covered, Missed: missed},
+			{Type: apistructs.ClassCounter, Covered: classCovered, Missed: classMissed},
+		}
+
+		name := path.Dir(f.Path)
+		p, ok := packages[name]
+		if !ok {
+			p = &apistructs.ReportPackage{Name: name, Counters: newCounters()}
+			packages[name] = p
+			names = append(names, name)
+		}
+		p.Classes = append(p.Classes, apistructs.ReportClass{
+			Name:           f.Path,
+			SourceFilename: path.Base(f.Path),
+			Counters:       counters,
+		})
+		addCounters(p.Counters, counters)
+		addCounters(total, counters)
+	}
+
+	for _, name := range names {
+		report.Packages = append(report.Packages, *packages[name])
+	}
+	report.Counters = total
+	return report
+}
+
+func newCounters() []apistructs.ReportCounter {
+	return []apistructs.ReportCounter{
+		{Type: apistructs.LineCounter},
+		{Type: apistructs.ClassCounter},
+	}
+}
+
+func addCounters(dst, src []apistructs.ReportCounter) {
+	for _, c := range src {
+		for idx := range dst {
+			if dst[idx].Type == c.Type {
+				dst[idx].Covered += c.Covered
+				dst[idx].Missed += c.Missed
+			}
+		}
+	}
+}
+
+// UploadReport 保存流水线上传的 go coverprofile, lcov 或 cobertura 覆盖率报告
+func (svc *CodeCoverage) UploadReport(req apistructs.CodeCoverageReportUploadRequest) (*apistructs.CodeCoverageReportDto, error) {
+	app, err := svc.bdl.GetApp(req.AppID)
+	if err != nil {
+		return nil, apierrors.ErrGetApp.InternalError(err)
+	}
+	// check permission
+	if !req.IdentityInfo.IsInternalClient() {
+		access, err := svc.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+			UserID:   req.UserID,
+			Scope:    apistructs.ProjectScope,
+			ScopeID:  app.ProjectID,
+			Resource: "codeCoverage",
+			Action:   apistructs.CreateAction,
+		})
+		if err != nil {
+			return nil, apierrors.ErrUploadCodeCoverageReport.InternalError(err)
+		}
+		if !access.Access {
+			return nil, apierrors.ErrUploadCodeCoverageReport.AccessDenied()
+		}
+	}
+
+	files, err := ParseReport(req.Format, []byte(req.Content))
+	if err != nil {
+		return nil, apierrors.ErrUploadCodeCoverageReport.InvalidParameter(err)
+	}
+	root, coverage := apistructs.ConvertReportToTree(ConvertFilesToReport(app.ProjectID, app.Name, files))
+
+	report := dao.CodeCoverageReport{
+		ProjectID:     app.ProjectID,
+		AppID:         req.AppID,
+		PipelineID:    req.PipelineID,
+		Branch:        req.Branch,
+		CommitID:      req.CommitID,
+		Format:        string(req.Format),
+		Coverage:      coverage,
+		ReportContent: root,
+		Files:         files,
+		Creator:       req.UserID,
+	}
+	if err := svc.db.CreateCodeCoverageReport(&report); err != nil {
+		return nil, apierrors.ErrUploadCodeCoverageReport.InternalError(err)
+	}
+	return report.Covert(), nil
+}
+
+// ListCodeCoverageReport 应用上传的覆盖率报告列表
+func (svc *CodeCoverage) ListCodeCoverageReport(req apistructs.CodeCoverageReportListRequest) (*apistructs.CodeCoverageReportData, error) {
+	reports, total, err := svc.db.ListCodeCoverageReport(req)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]apistructs.CodeCoverageReportDto, 0, len(reports))
+	for _, v := range reports {
+		list = append(list, v.Covert())
+	}
+	return &apistructs.CodeCoverageReportData{
+		Total: total,
+		List:  list,
+	}, nil
+}
+
+// GetCodeCoverageReport 获取覆盖率报告详情
+func (svc *CodeCoverage) GetCodeCoverageReport(id uint64) (*apistructs.CodeCoverageReportDto, error) {
+	report, err := svc.db.GetCodeCoverageReportByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return report.Covert(), nil
+}
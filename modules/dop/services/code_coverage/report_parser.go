@@ -0,0 +1,203 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package code_coverage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+// ParseReport 解析流水线上传的覆盖率报告, 返回按路径排序的文件行覆盖数据
+func ParseReport(format apistructs.CodeCoverageReportFormat, data []byte) ([]*apistructs.CodeCoverageFile, error) {
+	files := newFileSet()
+	var err error
+	switch format {
+	case apistructs.GoCoverProfileFormat:
+		err = parseGoCoverProfile(data, files)
+	case apistructs.LcovFormat:
+		err = parseLcov(data, files)
+	case apistructs.CoberturaFormat:
+		err = parseCobertura(data, files)
+	default:
+		err = fmt.Errorf("unsupported code coverage report format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return files.list(), nil
+}
+
+// fileSet 合并同一文件的多段覆盖数据, 同一行取最大命中次数
+type fileSet map[string]*apistructs.CodeCoverageFile
+
+func newFileSet() fileSet {
+	return make(fileSet)
+}
+
+func (s fileSet) add(filename string, line int, hits int64) {
+	filename = cleanPath(filename)
+	f, ok := s[filename]
+	if !ok {
+		f = &apistructs.CodeCoverageFile{Path: filename, Lines: make(map[int]int64)}
+		s[filename] = f
+	}
+	if old, ok := f.Lines[line]; !ok || hits > old {
+		f.Lines[line] = hits
+	}
+}
+
+func (s fileSet) list() []*apistructs.CodeCoverageFile {
+	files := make([]*apistructs.CodeCoverageFile, 0, len(s))
+	for _, f := range s {
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
+
+func cleanPath(filename string) string {
+	filename = path.Clean(strings.ReplaceAll(strings.TrimSpace(filename), "\\", "/"))
+	return strings.TrimPrefix(filename, "/")
+}
+
+// parseGoCoverProfile 解析 go test -coverprofile 的输出, 每行格式为:
+// name.go:line.column,line.column numberOfStatements count
+func parseGoCoverProfile(data []byte, files fileSet) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		// 多个 profile 拼接时会出现多个 mode 行
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		idx := strings.LastIndex(line, ":")
+		if idx <= 0 {
+			return fmt.Errorf("invalid coverprofile line %d: %q", lineNo, line)
+		}
+		fields := strings.Fields(line[idx+1:])
+		if len(fields) != 3 {
+			return fmt.Errorf("invalid coverprofile line %d: %q", lineNo, line)
+		}
+		var startLine, startCol, endLine, endCol int
+		if _, err := fmt.Sscanf(fields[0], "%d.%d,%d.%d", &startLine, &startCol, &endLine, &endCol); err != nil {
+			return fmt.Errorf("invalid coverprofile block at line %d: %v", lineNo, err)
+		}
+		numStmts, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return fmt.Errorf("invalid statement count at line %d: %v", lineNo, err)
+		}
+		count, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid hit count at line %d: %v", lineNo, err)
+		}
+		if numStmts == 0 {
+			continue
+		}
+		for l := startLine; l <= endLine; l++ {
+			files.add(line[:idx], l, count)
+		}
+	}
+	return scanner.Err()
+}
+
+// parseLcov 解析 lcov tracefile, 只关心 SF(源文件), DA(行号,命中次数) 和 end_of_record
+func parseLcov(data []byte, files fileSet) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var (
+		lineNo  int
+		current string
+	)
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "SF:"):
+			current = strings.TrimPrefix(line, "SF:")
+		case strings.HasPrefix(line, "DA:"):
+			if current == "" {
+				return fmt.Errorf("lcov line %d: DA record outside of a source file", lineNo)
+			}
+			// DA:<line number>,<execution count>[,<checksum>]
+			fields := strings.Split(strings.TrimPrefix(line, "DA:"), ",")
+			if len(fields) < 2 {
+				return fmt.Errorf("invalid lcov DA record at line %d: %q", lineNo, line)
+			}
+			l, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return fmt.Errorf("invalid lcov line number at line %d: %v", lineNo, err)
+			}
+			hits, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid lcov execution count at line %d: %v", lineNo, err)
+			}
+			files.add(current, l, hits)
+		case line == "end_of_record":
+			current = ""
+		}
+	}
+	return scanner.Err()
+}
+
+type coberturaCoverage struct {
+	XMLName  xml.Name           `xml:"coverage"`
+	Packages []coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name    string           `xml:"name,attr"`
+	Classes []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Name     string          `xml:"name,attr"`
+	Filename string          `xml:"filename,attr"`
+	Lines    []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number int   `xml:"number,attr"`
+	Hits   int64 `xml:"hits,attr"`
+}
+
+// parseCobertura 解析 cobertura xml, 文件路径使用 class 的 filename, 它相对于 sources 中的某个目录
+func parseCobertura(data []byte, files fileSet) error {
+	var coverage coberturaCoverage
+	if err := xml.Unmarshal(data, &coverage); err != nil {
+		return fmt.Errorf("invalid cobertura xml: %v", err)
+	}
+	for _, p := range coverage.Packages {
+		for _, c := range p.Classes {
+			if c.Filename == "" {
+				return fmt.Errorf("cobertura class %s in package %s has no filename", c.Name, p.Name)
+			}
+			for _, l := range c.Lines {
+				files.add(c.Filename, l.Number, l.Hits)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package code_coverage
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func readTestdata(t *testing.T, name string) []byte {
+	data, err := ioutil.ReadFile("testdata/" + name)
+	assert.NoError(t, err)
+	return data
+}
+
+func TestParseGoCoverProfile(t *testing.T) {
+	files, err := ParseReport(apistructs.GoCoverProfileFormat, readTestdata(t, "coverage.out"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(files))
+
+	assert.Equal(t, "github.com/erda-project/demo/pkg/calc/calc.go", files[0].Path)
+	// 8.12,10.3 在第二个 profile 中被覆盖, 同一行取最大命中次数
+	assert.Equal(t, map[int]int64{3: 1, 4: 1, 5: 1, 7: 1, 8: 1, 9: 1, 10: 1, 11: 1}, files[0].Lines)
+	assert.Equal(t, "github.com/erda-project/demo/pkg/util/util.go", files[1].Path)
+	assert.Equal(t, map[int]int64{3: 0, 4: 0, 5: 0}, files[1].Lines)
+}
+
+func TestParseGoCoverProfileInvalid(t *testing.T) {
+	_, err := ParseReport(apistructs.GoCoverProfileFormat, []byte("mode: set\nfoo.go 1 1\n"))
+	assert.Error(t, err)
+	_, err = ParseReport(apistructs.GoCoverProfileFormat, []byte("mode: set\nfoo.go:1.1,x.2 1 1\n"))
+	assert.Error(t, err)
+}
+
+func TestParseLcov(t *testing.T) {
+	files, err := ParseReport(apistructs.LcovFormat, readTestdata(t, "lcov.info"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(files))
+
+	assert.Equal(t, "builds/demo/src/calc.js", files[0].Path)
+	assert.Equal(t, map[int]int64{1: 1, 2: 1, 5: 0}, files[0].Lines)
+	assert.Equal(t, "builds/demo/src/util/format.js", files[1].Path)
+	assert.Equal(t, map[int]int64{3: 0, 4: 0}, files[1].Lines)
+
+	_, err = ParseReport(apistructs.LcovFormat, []byte("DA:1,1\n"))
+	assert.Error(t, err)
+}
+
+func TestParseCobertura(t *testing.T) {
+	files, err := ParseReport(apistructs.CoberturaFormat, readTestdata(t, "cobertura.xml"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(files))
+
+	assert.Equal(t, "app/__init__.py", files[0].Path)
+	assert.Equal(t, map[int]int64{1: 1, 3: 0}, files[0].Lines)
+	assert.Equal(t, "app/calc.py", files[1].Path)
+	assert.Equal(t, map[int]int64{1: 1, 2: 1, 4: 0}, files[1].Lines)
+
+	_, err = ParseReport(apistructs.CoberturaFormat, []byte("<coverage"))
+	assert.Error(t, err)
+}
+
+func TestParseReportUnsupportedFormat(t *testing.T) {
+	_, err := ParseReport("jacoco", []byte("<report/>"))
+	assert.Error(t, err)
+}
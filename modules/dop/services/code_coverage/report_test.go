@@ -0,0 +1,61 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package code_coverage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestConvertFilesToReport(t *testing.T) {
+	files, err := ParseReport(apistructs.GoCoverProfileFormat, readTestdata(t, "coverage.out"))
+	assert.NoError(t, err)
+
+	report := ConvertFilesToReport(1, "demo", files)
+	assert.Equal(t, 2, len(report.Packages))
+	assert.Equal(t, "github.com/erda-project/demo/pkg/calc", report.Packages[0].Name)
+	assert.Equal(t, "calc.go", report.Packages[0].Classes[0].SourceFilename)
+	assert.Equal(t, []apistructs.ReportCounter{
+		{Type: apistructs.LineCounter, Covered: 8, Missed: 3},
+		{Type: apistructs.ClassCounter, Covered: 1, Missed: 1},
+	}, report.Counters)
+
+	root, coverage := apistructs.ConvertReportToTree(report)
+	assert.Equal(t, 72.73, coverage)
+	assert.Equal(t, 1, len(root))
+	assert.Equal(t, "demo", root[0].Name)
+	assert.Equal(t, float64(11), root[0].Value[apistructs.LineIdx])
+	assert.Equal(t, 2, len(root[0].Nodes))
+	for _, node := range root[0].Nodes {
+		switch node.Name {
+		case "github.com/erda-project/demo/pkg/calc":
+			assert.Equal(t, float64(100), node.Value[apistructs.LinePercentIdx])
+		case "github.com/erda-project/demo/pkg/util":
+			assert.Equal(t, float64(0), node.Value[apistructs.LinePercentIdx])
+			assert.Equal(t, float64(3), node.Value[apistructs.LineIdx])
+		default:
+			t.Errorf("unexpected node: %s", node.Name)
+		}
+	}
+}
+
+func TestConvertFilesToReportEmpty(t *testing.T) {
+	root, coverage := apistructs.ConvertReportToTree(ConvertFilesToReport(1, "demo", nil))
+	assert.Equal(t, 0, len(root))
+	assert.Equal(t, float64(0), coverage)
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flakytest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dbclient"
+)
+
+// TestKey 用例唯一标识, 同一应用下 suite, classname, name 均相同的用例视为同一个用例
+func TestKey(suite, classname, name string) string {
+	sum := sha256.Sum256([]byte(suite + "\n" + classname + "\n" + name))
+	return hex.EncodeToString(sum[:16])
+}
+
+type caseStats struct {
+	test *apistructs.FlakyTest
+	// 每个分支上一次的执行结果, 用于统计翻转次数
+	lastStatus map[string]apistructs.TestStatus
+	// 每个 commit 的执行结果
+	commitStatus map[string]map[apistructs.TestStatus]bool
+	// 相邻执行对数
+	transitions int
+}
+
+// Analyze 按时间顺序统计每个用例在多次测试记录中的执行结果, records 为按 id 倒序的测试记录.
+// 跳过的用例不计入统计, error 视为失败.
+// 返回的用例按不稳定分数倒序, 分数相同时按同 commit 不稳定次数、失败次数倒序.
+func Analyze(records []*dbclient.TPRecordDO) []*apistructs.FlakyTest {
+	var (
+		stats = make(map[string]*caseStats)
+		keys  []string
+	)
+
+	for i := len(records) - 1; i >= 0; i-- {
+		record := records[i]
+		for _, suite := range record.Suites {
+			if suite == nil {
+				continue
+			}
+			for _, test := range suite.Tests {
+				if test == nil {
+					continue
+				}
+				status := outcome(test.Status)
+				if status == "" {
+					continue
+				}
+				key := TestKey(suite.Name, test.Classname, test.Name)
+				s, ok := stats[key]
+				if !ok {
+					s = &caseStats{
+						test: &apistructs.FlakyTest{
+							Key:       key,
+							Suite:     suite.Name,
+							Classname: test.Classname,
+							Name:      test.Name,
+						},
+						lastStatus:   make(map[string]apistructs.TestStatus),
+						commitStatus: make(map[string]map[apistructs.TestStatus]bool),
+					}
+					stats[key] = s
+					keys = append(keys, key)
+				}
+				s.add(record, test, status)
+			}
+		}
+	}
+
+	result := make([]*apistructs.FlakyTest, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, stats[key].finish())
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		if result[i].FlakyCommits != result[j].FlakyCommits {
+			return result[i].FlakyCommits > result[j].FlakyCommits
+		}
+		return result[i].Failed > result[j].Failed
+	})
+	return result
+}
+
+// IsFlaky 用例在同一分支上出现过翻转, 或在同一 commit 上既有成功又有失败
+func IsFlaky(test *apistructs.FlakyTest) bool {
+	return test.Flips > 0 || test.FlakyCommits > 0
+}
+
+func outcome(status apistructs.TestStatus) apistructs.TestStatus {
+	switch status {
+	case apistructs.TestStatusPassed:
+		return apistructs.TestStatusPassed
+	case apistructs.TestStatusFailed, apistructs.TestStatusError:
+		return apistructs.TestStatusFailed
+	default:
+		return ""
+	}
+}
+
+func (s *caseStats) add(record *dbclient.TPRecordDO, test *apistructs.Test, status apistructs.TestStatus) {
+	s.test.Runs++
+	if status == apistructs.TestStatusPassed {
+		s.test.Passed++
+	} else {
+		s.test.Failed++
+		createdAt := record.CreatedAt
+		s.test.LastFailedAt = &createdAt
+	}
+
+	if last, ok := s.lastStatus[record.Branch]; ok {
+		s.transitions++
+		if last != status {
+			s.test.Flips++
+		}
+	}
+	s.lastStatus[record.Branch] = status
+
+	if record.CommitID != "" {
+		if s.commitStatus[record.CommitID] == nil {
+			s.commitStatus[record.CommitID] = make(map[apistructs.TestStatus]bool)
+		}
+		s.commitStatus[record.CommitID][status] = true
+	}
+
+	s.test.History = append(s.test.History, apistructs.FlakyTestRun{
+		RecordID:  record.ID,
+		CommitID:  record.CommitID,
+		Branch:    record.Branch,
+		Status:    test.Status,
+		Duration:  test.Duration,
+		CreatedAt: record.CreatedAt,
+	})
+}
+
+func (s *caseStats) finish() *apistructs.FlakyTest {
+	for _, statuses := range s.commitStatus {
+		if statuses[apistructs.TestStatusPassed] && statuses[apistructs.TestStatusFailed] {
+			s.test.FlakyCommits++
+		}
+	}
+	if s.transitions > 0 {
+		s.test.Score = float64(s.test.Flips) / float64(s.transitions)
+	}
+	return s.test
+}
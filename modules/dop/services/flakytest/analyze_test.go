@@ -0,0 +1,107 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flakytest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dbclient"
+)
+
+func newRecord(id uint64, branch, commitID string, statuses map[string]apistructs.TestStatus) *dbclient.TPRecordDO {
+	suite := &apistructs.TestSuite{Name: "suite"}
+	for _, name := range []string{"a", "b", "c"} {
+		if status, ok := statuses[name]; ok {
+			suite.Tests = append(suite.Tests, &apistructs.Test{Name: name, Classname: "cls", Status: status})
+		}
+	}
+	return &dbclient.TPRecordDO{
+		ID:        id,
+		Branch:    branch,
+		CommitID:  commitID,
+		CreatedAt: time.Unix(int64(id), 0),
+		Suites:    []*apistructs.TestSuite{suite},
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	var (
+		passed  = apistructs.TestStatusPassed
+		failed  = apistructs.TestStatusFailed
+		errored = apistructs.TestStatusError
+		skipped = apistructs.TestStatusSkipped
+	)
+	// 按 id 倒序
+	records := []*dbclient.TPRecordDO{
+		newRecord(5, "master", "c3", map[string]apistructs.TestStatus{"a": passed, "b": failed, "c": passed}),
+		newRecord(4, "feature", "c9", map[string]apistructs.TestStatus{"a": failed, "b": failed, "c": passed}),
+		newRecord(3, "master", "c2", map[string]apistructs.TestStatus{"a": skipped, "b": failed, "c": passed}),
+		newRecord(2, "master", "c1", map[string]apistructs.TestStatus{"a": errored, "b": failed, "c": passed}),
+		newRecord(1, "master", "c1", map[string]apistructs.TestStatus{"a": passed, "b": failed}),
+	}
+
+	tests := Analyze(records)
+	assert.Equal(t, 3, len(tests))
+
+	// a: master 上 pass -> error -> (skip) -> pass, 翻转 2 次, 相邻执行 2 对; feature 上只有一次执行
+	a := tests[0]
+	assert.Equal(t, "a", a.Name)
+	assert.Equal(t, TestKey("suite", "cls", "a"), a.Key)
+	assert.Equal(t, 4, a.Runs)
+	assert.Equal(t, 2, a.Passed)
+	assert.Equal(t, 2, a.Failed)
+	assert.Equal(t, 2, a.Flips)
+	assert.Equal(t, 1, a.FlakyCommits)
+	assert.Equal(t, float64(1), a.Score)
+	assert.Equal(t, time.Unix(4, 0), *a.LastFailedAt)
+	assert.Equal(t, 4, len(a.History))
+	assert.Equal(t, uint64(1), a.History[0].RecordID)
+	assert.Equal(t, errored, a.History[1].Status)
+	assert.True(t, IsFlaky(a))
+
+	// b 一直失败, c 一直成功, 都不是不稳定用例; 失败多的排在前面
+	b, c := tests[1], tests[2]
+	assert.Equal(t, "b", b.Name)
+	assert.Equal(t, 5, b.Failed)
+	assert.Equal(t, float64(0), b.Score)
+	assert.False(t, IsFlaky(b))
+	assert.Equal(t, "c", c.Name)
+	assert.Nil(t, c.LastFailedAt)
+	assert.False(t, IsFlaky(c))
+}
+
+func TestAnalyzeEmpty(t *testing.T) {
+	assert.Equal(t, 0, len(Analyze(nil)))
+}
+
+func TestMarkQuarantined(t *testing.T) {
+	record := newRecord(1, "master", "c1", map[string]apistructs.TestStatus{
+		"a": apistructs.TestStatusFailed,
+		"b": apistructs.TestStatusError,
+		"c": apistructs.TestStatusPassed,
+	})
+	quarantined := map[string]bool{TestKey("suite", "cls", "a"): true}
+	assert.True(t, MarkQuarantined(record.Suites, quarantined))
+	assert.True(t, record.Suites[0].Tests[0].Quarantined)
+	assert.False(t, record.Suites[0].Tests[1].Quarantined)
+
+	quarantined[TestKey("suite", "cls", "b")] = true
+	assert.False(t, MarkQuarantined(record.Suites, quarantined))
+	assert.True(t, record.Suites[0].Tests[1].Quarantined)
+}
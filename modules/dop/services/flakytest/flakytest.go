@@ -0,0 +1,111 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package flakytest 根据单元测试记录统计不稳定用例, 并管理被隔离的用例
+package flakytest
+
+import (
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/modules/dop/dbclient"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+const (
+	defaultRecordLimit = 50
+	maxRecordLimit     = 200
+	defaultLimit       = 20
+)
+
+type FlakyTest struct {
+	bdl *bundle.Bundle
+}
+
+type Option func(*FlakyTest)
+
+func New(options ...Option) *FlakyTest {
+	f := &FlakyTest{}
+	for _, op := range options {
+		op(f)
+	}
+	return f
+}
+
+func WithBundle(bdl *bundle.Bundle) Option {
+	return func(f *FlakyTest) {
+		f.bdl = bdl
+	}
+}
+
+// ListFlakyTests 列出应用最近的测试记录中最不稳定的用例
+func (f *FlakyTest) ListFlakyTests(req apistructs.FlakyTestListRequest) ([]apistructs.FlakyTest, error) {
+	tests, err := f.analyze(req.AppID, req.Branch, req.RecordLimit)
+	if err != nil {
+		return nil, err
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	result := make([]apistructs.FlakyTest, 0)
+	for _, test := range tests {
+		if len(result) >= limit {
+			break
+		}
+		if !IsFlaky(test) || test.Score < req.MinScore {
+			continue
+		}
+		test.History = nil
+		result = append(result, *test)
+	}
+	return result, nil
+}
+
+// GetFlakyTestHistory 获取用例在最近的测试记录中的执行历史
+func (f *FlakyTest) GetFlakyTestHistory(req apistructs.FlakyTestHistoryRequest) (*apistructs.FlakyTest, error) {
+	tests, err := f.analyze(req.AppID, req.Branch, req.RecordLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, test := range tests {
+		if test.Key == req.Key {
+			return test, nil
+		}
+	}
+	return nil, apierrors.ErrGetFlakyTestHistory.NotFound()
+}
+
+func (f *FlakyTest) analyze(appID uint64, branch string, recordLimit int) ([]*apistructs.FlakyTest, error) {
+	if recordLimit <= 0 {
+		recordLimit = defaultRecordLimit
+	}
+	if recordLimit > maxRecordLimit {
+		recordLimit = maxRecordLimit
+	}
+	records, err := dbclient.FindTPRecordsWithSuitesByAppID(appID, branch, recordLimit)
+	if err != nil {
+		return nil, err
+	}
+	quarantined, err := quarantinedKeys(appID)
+	if err != nil {
+		return nil, err
+	}
+
+	tests := Analyze(records)
+	for _, test := range tests {
+		test.Quarantined = quarantined[test.Key]
+	}
+	return tests, nil
+}
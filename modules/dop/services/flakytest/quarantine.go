@@ -0,0 +1,150 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flakytest
+
+import (
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dbclient"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+)
+
+// CreateQuarantine 隔离用例, 隔离后用例失败时测试记录不再阻塞
+func (f *FlakyTest) CreateQuarantine(req apistructs.TestQuarantineCreateRequest) (*apistructs.TestQuarantine, error) {
+	if req.ApplicationID == 0 {
+		return nil, apierrors.ErrCreateTestQuarantine.MissingParameter("applicationId")
+	}
+	if req.Name == "" {
+		return nil, apierrors.ErrCreateTestQuarantine.MissingParameter("name")
+	}
+	if err := f.checkPermission(req.IdentityInfo, req.ApplicationID, apierrors.ErrCreateTestQuarantine); err != nil {
+		return nil, err
+	}
+
+	key := TestKey(req.Suite, req.Classname, req.Name)
+	exist, err := dbclient.FindTestQuarantineByKey(req.ApplicationID, key)
+	if err != nil {
+		return nil, err
+	}
+	if exist != nil {
+		return nil, apierrors.ErrCreateTestQuarantine.AlreadyExists()
+	}
+
+	q := &dbclient.TestQuarantineDO{
+		ApplicationID: req.ApplicationID,
+		TestKey:       key,
+		Suite:         req.Suite,
+		Classname:     req.Classname,
+		Name:          req.Name,
+		Reason:        req.Reason,
+		OperatorID:    req.UserID,
+	}
+	if err := dbclient.InsertTestQuarantine(q); err != nil {
+		return nil, err
+	}
+	result := q.Convert()
+	return &result, nil
+}
+
+// ListQuarantines 列出应用下被隔离的用例
+func (f *FlakyTest) ListQuarantines(appID uint64) ([]apistructs.TestQuarantine, error) {
+	list, err := dbclient.ListTestQuarantinesByAppID(appID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]apistructs.TestQuarantine, 0, len(list))
+	for _, q := range list {
+		result = append(result, q.Convert())
+	}
+	return result, nil
+}
+
+// DeleteQuarantine 解除用例隔离
+func (f *FlakyTest) DeleteQuarantine(id uint64, identityInfo apistructs.IdentityInfo) (*apistructs.TestQuarantine, error) {
+	q, err := dbclient.GetTestQuarantine(id)
+	if err != nil {
+		return nil, apierrors.ErrDeleteTestQuarantine.NotFound()
+	}
+	if err := f.checkPermission(identityInfo, q.ApplicationID, apierrors.ErrDeleteTestQuarantine); err != nil {
+		return nil, err
+	}
+	if err := dbclient.DeleteTestQuarantine(id); err != nil {
+		return nil, err
+	}
+	result := q.Convert()
+	return &result, nil
+}
+
+// MarkQuarantined 标记测试记录中被隔离的用例, 返回是否存在未被隔离的失败用例
+func (f *FlakyTest) MarkQuarantined(record *dbclient.TPRecordDO) (bool, error) {
+	quarantined, err := quarantinedKeys(uint64(record.ApplicationID))
+	if err != nil {
+		return false, err
+	}
+	return MarkQuarantined(record.Suites, quarantined), nil
+}
+
+// MarkQuarantined 标记被隔离的用例, 返回是否存在未被隔离的失败用例
+func MarkQuarantined(suites []*apistructs.TestSuite, quarantined map[string]bool) bool {
+	var blocking bool
+	for _, suite := range suites {
+		if suite == nil {
+			continue
+		}
+		for _, test := range suite.Tests {
+			if test == nil {
+				continue
+			}
+			test.Quarantined = quarantined[TestKey(suite.Name, test.Classname, test.Name)]
+			if !test.Quarantined && outcome(test.Status) == apistructs.TestStatusFailed {
+				blocking = true
+			}
+		}
+	}
+	return blocking
+}
+
+func quarantinedKeys(appID uint64) (map[string]bool, error) {
+	list, err := dbclient.ListTestQuarantinesByAppID(appID)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool, len(list))
+	for _, q := range list {
+		keys[q.TestKey] = true
+	}
+	return keys, nil
+}
+
+// checkPermission 隔离和解除隔离需要应用的编辑权限
+func (f *FlakyTest) checkPermission(identityInfo apistructs.IdentityInfo, appID uint64, apiErr *errorresp.APIError) error {
+	if identityInfo.IsInternalClient() {
+		return nil
+	}
+	access, err := f.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+		UserID:   identityInfo.UserID,
+		Scope:    apistructs.AppScope,
+		ScopeID:  appID,
+		Resource: apistructs.AppResource,
+		Action:   apistructs.UpdateAction,
+	})
+	if err != nil {
+		return err
+	}
+	if !access.Access {
+		return apiErr.AccessDenied()
+	}
+	return nil
+}
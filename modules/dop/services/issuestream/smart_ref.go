@@ -0,0 +1,255 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package issuestream
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+)
+
+// SmartRef 提交信息, 分支名或 MR 标题中对事项的引用
+type SmartRef struct {
+	IssueID int64
+	// Closing 引用前带有 fix, close, resolve 等关键字, MR 合入默认分支后事项可以自动迁移到完成状态
+	Closing bool
+}
+
+var (
+	// 如 "#123", "fix #123", "Closes: #123", "修复 #123"
+	textRefPattern = regexp.MustCompile(`(?i)(?:^|[^\w&/#])(?:(close[sd]?|fix(?:e[sd])?|resolve[sd]?|修复|解决|关闭)\s*:?\s*)?#(\d+)\b`)
+	// 分支名的最后一段以 issue 加事项 ID 开头, 或事项 ID 后跟单词, 如 "bugfix/issue-45", "feature/123-login";
+	// 纯数字 (如 "hotfix/20220121") 多为日期或版本号, 不视为引用
+	branchRefPattern = regexp.MustCompile(`(?i)^(?:issue[-_]?(\d+)(?:[-_]|$)|(\d+)[-_][a-z])`)
+)
+
+const maxCommitMessageLen = 100
+
+// ParseSmartRefs 解析提交信息或 MR 标题中的事项引用
+func ParseSmartRefs(text string) []SmartRef {
+	var refs []SmartRef
+	for _, m := range textRefPattern.FindAllStringSubmatch(text, -1) {
+		id, err := strconv.ParseInt(m[2], 10, 64)
+		if err != nil || id == 0 {
+			continue
+		}
+		refs = append(refs, SmartRef{IssueID: id, Closing: m[1] != ""})
+	}
+	return MergeSmartRefs(refs)
+}
+
+// ParseBranchRefs 解析分支名中的事项引用, 分支名中的引用不会关闭事项
+func ParseBranchRefs(branch string) []SmartRef {
+	refs := ParseSmartRefs(branch)
+	for i := range refs {
+		refs[i].Closing = false
+	}
+	name := branch[strings.LastIndex(branch, "/")+1:]
+	if m := branchRefPattern.FindStringSubmatch(name); m != nil {
+		if id, err := strconv.ParseInt(m[1]+m[2], 10, 64); err == nil && id != 0 {
+			refs = append(refs, SmartRef{IssueID: id})
+		}
+	}
+	return MergeSmartRefs(refs)
+}
+
+// MergeSmartRefs 按出现顺序去重, 同一事项只要有一处使用了关闭关键字即视为关闭
+func MergeSmartRefs(refsList ...[]SmartRef) []SmartRef {
+	var (
+		merged []SmartRef
+		index  = make(map[int64]int)
+	)
+	for _, refs := range refsList {
+		for _, ref := range refs {
+			if i, ok := index[ref.IssueID]; ok {
+				merged[i].Closing = merged[i].Closing || ref.Closing
+				continue
+			}
+			index[ref.IssueID] = len(merged)
+			merged = append(merged, ref)
+		}
+	}
+	return merged
+}
+
+// LinkMR 将 MR 标题和源分支中引用的事项关联到 MR, 返回引用的同项目下的事项
+func (s *IssueStream) LinkMR(mr *apistructs.MergeRequestInfo) ([]SmartRef, error) {
+	refs := MergeSmartRefs(ParseSmartRefs(mr.Title), ParseBranchRefs(mr.SourceBranch))
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	app, err := s.bdl.GetApp(uint64(mr.AppID))
+	if err != nil {
+		return nil, err
+	}
+
+	var linked []SmartRef
+	for _, ref := range refs {
+		ok, err := s.issueInProject(ref.IssueID, app.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		linked = append(linked, ref)
+
+		exist, err := s.db.IssueAppRelationExist(ref.IssueID, mr.AppID, int64(mr.RepoMergeId))
+		if err != nil {
+			return nil, err
+		}
+		if exist {
+			continue
+		}
+		if _, err := s.Create(&apistructs.IssueStreamCreateRequest{
+			IssueID:    ref.IssueID,
+			Operator:   mr.AuthorId,
+			StreamType: apistructs.ISTRelateMR,
+			StreamParams: apistructs.ISTParam{
+				MRInfo: apistructs.MRCommentInfo{
+					AppID:   mr.AppID,
+					MRID:    int64(mr.RepoMergeId),
+					MRTitle: mr.Title,
+				},
+			},
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return linked, nil
+}
+
+// LinkCommits 将推送的提交信息中引用的事项关联到提交, 分支名中引用的事项关联到推送后的分支头 head
+func (s *IssueStream) LinkCommits(appID uint64, branch, head, operator string, commits []apistructs.Commit) error {
+	branchRefs := ParseBranchRefs(branch)
+	var app *apistructs.ApplicationDTO
+	for _, commit := range commits {
+		refs := ParseSmartRefs(commit.CommitMessage)
+		if commit.ID == head {
+			refs = MergeSmartRefs(refs, branchRefs)
+		}
+		if len(refs) == 0 {
+			continue
+		}
+		if app == nil {
+			var err error
+			if app, err = s.bdl.GetApp(appID); err != nil {
+				return err
+			}
+		}
+		for _, ref := range refs {
+			if err := s.linkCommit(app, branch, operator, commit, ref.IssueID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *IssueStream) linkCommit(app *apistructs.ApplicationDTO, branch, operator string, commit apistructs.Commit, issueID int64) error {
+	ok, err := s.issueInProject(issueID, app.ProjectID)
+	if err != nil || !ok {
+		return err
+	}
+	exist, err := s.db.IssueCommitRelationExist(issueID, int64(app.ID), commit.ID)
+	if err != nil || exist {
+		return err
+	}
+
+	shortID := commit.ID
+	if len(shortID) > 8 {
+		shortID = shortID[:8]
+	}
+	streamID, err := s.Create(&apistructs.IssueStreamCreateRequest{
+		IssueID:    issueID,
+		Operator:   operator,
+		StreamType: apistructs.ISTRelateCommit,
+		StreamParams: apistructs.ISTParam{
+			AppName:       app.Name,
+			CommitID:      shortID,
+			CommitMessage: commitTitle(commit.CommitMessage),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return s.db.CreateIssueCommitRelation(&dao.IssueCommitRelation{
+		IssueID:  issueID,
+		AppID:    int64(app.ID),
+		CommitID: commit.ID,
+		Branch:   branch,
+		StreamID: streamID,
+	})
+}
+
+// GetDoneState 按项目配置的状态流转, 获取事项从当前状态可以直接迁移到的完成状态: 缺陷为已解决, 其它类型为已完成
+func (s *IssueStream) GetDoneState(issue dao.Issue) (int64, bool, error) {
+	target := apistructs.IssueStateBelongDone
+	if issue.Type == apistructs.IssueTypeBug {
+		target = apistructs.IssueStateBelongResolved
+	}
+	relations, err := s.db.GetIssuesStateRelations(issue.ProjectID, issue.Type)
+	if err != nil {
+		return 0, false, err
+	}
+
+	reachable := make(map[int64]bool)
+	for _, r := range relations {
+		if r.ID != issue.State {
+			continue
+		}
+		// 已经处于完成, 已解决, 无需修复或关闭状态的事项不再迁移
+		switch r.Belong {
+		case apistructs.IssueStateBelongDone, apistructs.IssueStateBelongResolved,
+			apistructs.IssueStateBelongWontfix, apistructs.IssueStateBelongClosed:
+			return 0, false, nil
+		}
+		if r.EndStateID != 0 {
+			reachable[r.EndStateID] = true
+		}
+	}
+	// relations 按状态的 index 排序, 取第一个可达的完成状态
+	for _, r := range relations {
+		if r.Belong == target && reachable[r.ID] {
+			return r.ID, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+func (s *IssueStream) issueInProject(issueID int64, projectID uint64) (bool, error) {
+	issue, err := s.db.GetIssue(issueID)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return issue.ProjectID == projectID, nil
+}
+
+// commitTitle 提交信息的第一行, 超长时截断
+func commitTitle(message string) string {
+	title := strings.TrimSpace(strings.SplitN(strings.TrimSpace(message), "\n", 2)[0])
+	if r := []rune(title); len(r) > maxCommitMessageLen {
+		title = string(r[:maxCommitMessageLen]) + "..."
+	}
+	return title
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package issuestream
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSmartRefs(t *testing.T) {
+	tests := []struct {
+		text string
+		want []SmartRef
+	}{
+		{"", nil},
+		{"update readme", nil},
+		{"#12 add login page", []SmartRef{{IssueID: 12}}},
+		{"fix #12, close #34", []SmartRef{{IssueID: 12, Closing: true}, {IssueID: 34, Closing: true}}},
+		{"Resolves: #5", []SmartRef{{IssueID: 5, Closing: true}}},
+		{"修复 #7 登录失败", []SmartRef{{IssueID: 7, Closing: true}}},
+		{"refs #8, fixes #8", []SmartRef{{IssueID: 8, Closing: true}}},
+		{"see &#39; and a/#9 and ##10 and #0", nil},
+		{"prefix#11 and #12abc", nil},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ParseSmartRefs(tt.text), tt.text)
+	}
+}
+
+func TestParseBranchRefs(t *testing.T) {
+	tests := []struct {
+		branch string
+		want   []SmartRef
+	}{
+		{"master", nil},
+		{"feature/123-login", []SmartRef{{IssueID: 123}}},
+		{"bugfix/issue-45", []SmartRef{{IssueID: 45}}},
+		{"feature/ISSUE_46_fix", []SmartRef{{IssueID: 46}}},
+		{"hotfix/fix-#47", []SmartRef{{IssueID: 47}}},
+		{"release/1.2", nil},
+		{"feature/v2-login", nil},
+		{"hotfix/20220121", nil},
+		{"release/20220121_2", nil},
+		{"feature/issue48", []SmartRef{{IssueID: 48}}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ParseBranchRefs(tt.branch), tt.branch)
+	}
+}
+
+func TestMergeSmartRefs(t *testing.T) {
+	merged := MergeSmartRefs(
+		[]SmartRef{{IssueID: 1}, {IssueID: 2, Closing: true}},
+		[]SmartRef{{IssueID: 1, Closing: true}, {IssueID: 3}},
+	)
+	assert.Equal(t, []SmartRef{{IssueID: 1, Closing: true}, {IssueID: 2, Closing: true}, {IssueID: 3}}, merged)
+}
+
+func Test_commitTitle(t *testing.T) {
+	assert.Equal(t, "fix #1 login", commitTitle("  fix #1 login\n\nsome detail\n"))
+	long := strings.Repeat("提交", 60)
+	assert.Equal(t, strings.Repeat("提交", 50)+"...", commitTitle(long))
+}
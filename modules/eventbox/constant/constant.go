@@ -37,4 +37,9 @@ var (
 	// webhook
 	WebhookLabelKey = "/WEBHOOK"
 	WebhookDir      = filepath.Join(EventboxDir, "webhook")
+
+	// webhook delivery
+	WebhookDeliveryDir = filepath.Join(EventboxDir, "webhook_delivery")
+	WebhookRetryDir    = filepath.Join(EventboxDir, "webhook_retry")
+	WebhookPayloadDir  = filepath.Join(EventboxDir, "webhook_payload")
 )
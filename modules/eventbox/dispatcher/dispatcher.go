@@ -38,11 +38,16 @@ import (
 	"github.com/erda-project/erda/modules/eventbox/subscriber/dingtalk_worknotice"
 	emailsubscriber "github.com/erda-project/erda/modules/eventbox/subscriber/email"
 	fakesubscriber "github.com/erda-project/erda/modules/eventbox/subscriber/fake"
+	feishusubscriber "github.com/erda-project/erda/modules/eventbox/subscriber/feishu"
 	groupsubscriber "github.com/erda-project/erda/modules/eventbox/subscriber/group"
 	httpsubscriber "github.com/erda-project/erda/modules/eventbox/subscriber/http"
 	mbox "github.com/erda-project/erda/modules/eventbox/subscriber/mbox"
+	slacksubscriber "github.com/erda-project/erda/modules/eventbox/subscriber/slack"
 	smssubscriber "github.com/erda-project/erda/modules/eventbox/subscriber/sms"
+	teamssubscriber "github.com/erda-project/erda/modules/eventbox/subscriber/teams"
 	vmssubscriber "github.com/erda-project/erda/modules/eventbox/subscriber/vms"
+	webhooksubscriber "github.com/erda-project/erda/modules/eventbox/subscriber/webhook"
+	wecomsubscriber "github.com/erda-project/erda/modules/eventbox/subscriber/wecom"
 	"github.com/erda-project/erda/modules/eventbox/webhook"
 	"github.com/erda-project/erda/modules/eventbox/websocket"
 	"github.com/erda-project/erda/modules/pkg/user"
@@ -89,6 +94,10 @@ func New(dingtalk interfaces.DingTalkApiClientFactory) (Dispatcher, error) {
 	bundleS := bundle.New(bundle.WithCoreServices())
 	dingdingS := dingdingsubscriber.New(conf.Proxy())
 	dingdingWorknoticeS := dingdingworknoticesubscriber.New(conf.Proxy())
+	slackS := slacksubscriber.New(conf.Proxy())
+	teamsS := teamssubscriber.New(conf.Proxy())
+	feishuS := feishusubscriber.New(conf.Proxy())
+	wecomS := wecomsubscriber.New(conf.Proxy())
 	mboxS := mbox.New(bundle.New(bundle.WithCoreServices()))
 	emailS := emailsubscriber.New(conf.SmtpHost(), conf.SmtpPort(), conf.SmtpUser(), conf.SmtpPassword(),
 		conf.SmtpDisplayUser(), conf.SmtpIsSSL(), conf.SMTPInsecureSkipVerify(), bundleS)
@@ -104,6 +113,10 @@ func New(dingtalk interfaces.DingTalkApiClientFactory) (Dispatcher, error) {
 	if err != nil {
 		return nil, err
 	}
+	webhookS, err := webhooksubscriber.New()
+	if err != nil {
+		return nil, err
+	}
 
 	wh, err := webhook.NewWebHookHTTP()
 	if err != nil {
@@ -127,6 +140,11 @@ func New(dingtalk interfaces.DingTalkApiClientFactory) (Dispatcher, error) {
 	dispatcher.RegisterSubscriber(mboxS)
 	dispatcher.RegisterSubscriber(groupS)
 	dispatcher.RegisterSubscriber(dingWorkNotice)
+	dispatcher.RegisterSubscriber(webhookS)
+	dispatcher.RegisterSubscriber(slackS)
+	dispatcher.RegisterSubscriber(teamsS)
+	dispatcher.RegisterSubscriber(feishuS)
+	dispatcher.RegisterSubscriber(wecomS)
 
 	for name := range dispatcher.subscribers {
 		dispatcher.subscriberspool[name] = goroutinepool.New(conf.PoolSize())
@@ -72,7 +72,13 @@ func (w *WebhookFilter) Filter(m *types.Message) *errors.DispatchError {
 	}
 
 	urls := []string{}
+	hookIDs := []string{}
 	for _, h := range hs {
+		// 用户的 webhook 签名投递并记录投递结果，钉钉机器人仍直接发送
+		if parsed, err := url.Parse(h.URL); err == nil && urltype(parsed) == normalURL {
+			hookIDs = append(hookIDs, h.ID)
+			continue
+		}
 		urls = append(urls, h.URL)
 	}
 	for _, h := range internalHs {
@@ -83,6 +89,10 @@ func (w *WebhookFilter) Filter(m *types.Message) *errors.DispatchError {
 		derr.FilterErr = err
 		return derr
 	}
+	if err := replaceDeliveryLabel(m, hookIDs); err != nil {
+		derr.FilterErr = err
+		return derr
+	}
 	if err := replaceContent(m, *eventLabel); err != nil {
 		derr.FilterErr = err
 		return derr
@@ -153,6 +163,23 @@ func replaceLabel(m *types.Message, urls []string) error {
 	return nil
 }
 
+func replaceDeliveryLabel(m *types.Message, hookIDs []string) error {
+	if len(hookIDs) == 0 {
+		return nil
+	}
+	key := types.LabelKey(webhook.DeliveryLabel).NormalizeLabelKey()
+	raw, err := json.Marshal(m.Labels[key])
+	if err != nil {
+		return err
+	}
+	dest := []string{}
+	if err := json.Unmarshal(raw, &dest); err != nil {
+		return err
+	}
+	m.Labels[key] = append(dest, hookIDs...)
+	return nil
+}
+
 type urltp int
 
 const (
@@ -43,8 +43,8 @@ func testWebhookFilter(t *testing.T, f Filter) {
 	derr := f.Filter(&m)
 	assert.True(t, derr.IsOK())
 
-	http := m.Labels[types.LabelKey("/HTTP")]
-	assert.NotNil(t, http, fmt.Sprintf("%+v", m))
+	delivery := m.Labels[types.LabelKey("/WEBHOOK_DELIVERY")]
+	assert.NotNil(t, delivery, fmt.Sprintf("%+v", m))
 
 	raw, err := json.Marshal(m.Content)
 	assert.Nil(t, err)
@@ -72,8 +72,8 @@ func testWebhookFilterWithEnv(t *testing.T, f Filter) {
 	derr := f.Filter(&m)
 	assert.True(t, derr.IsOK())
 
-	http := m.Labels[types.LabelKey("/HTTP")]
-	assert.NotNil(t, http, fmt.Sprintf("%+v", m))
+	delivery := m.Labels[types.LabelKey("/WEBHOOK_DELIVERY")]
+	assert.NotNil(t, delivery, fmt.Sprintf("%+v", m))
 
 	raw, err := json.Marshal(m.Content)
 	assert.Nil(t, err)
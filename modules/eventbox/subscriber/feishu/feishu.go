@@ -0,0 +1,184 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feishu
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/eventbox/subscriber"
+	"github.com/erda-project/erda/modules/eventbox/subscriber/dingding"
+	"github.com/erda-project/erda/modules/eventbox/types"
+	"github.com/erda-project/erda/pkg/http/httpclient"
+)
+
+var (
+	FeishuSendErr   = errors.New("send Feishu fail")
+	FeishuBadURLErr = errors.New("bad Feishu URL")
+)
+
+// example feishu message, 开启签名校验时需要带上 timestamp 和 sign:
+// {
+//     "timestamp": "1599360473",
+//     "sign": "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx=",
+//     "msg_type": "interactive",
+//     "card": {
+//         "config": {"wide_screen_mode": true},
+//         "header": {"title": {"tag": "plain_text", "content": "title"}},
+//         "elements": [{"tag": "markdown", "content": "**bold** [link](https://erda.cloud)"}]
+//     }
+// }
+type CardText struct {
+	Tag     string `json:"tag"`
+	Content string `json:"content"`
+}
+type CardHeader struct {
+	Title CardText `json:"title"`
+}
+type CardConfig struct {
+	WideScreenMode bool `json:"wide_screen_mode"`
+}
+type Card struct {
+	Config   CardConfig  `json:"config"`
+	Header   *CardHeader `json:"header,omitempty"`
+	Elements []CardText  `json:"elements"`
+}
+type FeishuMessage struct {
+	Timestamp string `json:"timestamp,omitempty"`
+	Sign      string `json:"sign,omitempty"`
+	MsgType   string `json:"msg_type"`
+	Card      Card   `json:"card"`
+}
+
+type FeishuSubscriber struct {
+	proxy string
+}
+
+func New(proxy string) subscriber.Subscriber {
+	return &FeishuSubscriber{
+		proxy: proxy,
+	}
+}
+
+// example URL: https://open.feishu.cn/open-apis/bot/v2/hook/xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+func (s *FeishuSubscriber) Publish(dest string, content string, time int64, msg *types.Message) []error {
+	var targets []apistructs.Target
+	if err := json.Unmarshal([]byte(dest), &targets); err != nil {
+		return []error{errors.New("illegal dest")}
+	}
+	title := subscriber.MarkdownTitle(msg)
+	content = dingding.PrettyPrint(content, false)
+	errs := []error{}
+	for _, target := range targets {
+		m := NewFeishuMessage(title, content)
+		if target.Secret != "" {
+			m.Timestamp, m.Sign = Sign(target.Secret)
+		}
+		if err := s.FeishuSend(target.Receiver, m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// NewFeishuMessage 生成消息卡片，卡片的 markdown 不支持标题语法
+func NewFeishuMessage(title, content string) *FeishuMessage {
+	card := Card{
+		Config:   CardConfig{WideScreenMode: true},
+		Elements: []CardText{{Tag: "markdown", Content: subscriber.MarkdownHeadingToBold(content)}},
+	}
+	if title != "" {
+		card.Header = &CardHeader{Title: CardText{Tag: "plain_text", Content: title}}
+	}
+	return &FeishuMessage{
+		MsgType: "interactive",
+		Card:    card,
+	}
+}
+
+// Sign 返回签名用的时间戳(秒)和签名，签名为以 "timestamp\nsecret" 为密钥对空串计算的 HmacSHA256 的 base64
+func Sign(secret string) (string, string) {
+	timestamp := fmt.Sprintf("%d", time.Now().Unix())
+	return timestamp, sign(timestamp, secret)
+}
+
+func sign(timestamp, secret string) string {
+	h := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func (s *FeishuSubscriber) Status() interface{} {
+	return nil
+}
+
+func (s *FeishuSubscriber) Name() string {
+	return "FEISHU"
+}
+
+func (s *FeishuSubscriber) FeishuSend(u string, m *FeishuMessage) error {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Host == "" {
+		return errors.Wrapf(FeishuBadURLErr, "url: %s", u)
+	}
+	var buf bytes.Buffer
+	resp, err := httpclient.New(httpclient.WithHTTPS(), httpclient.WithProxy(s.proxy), httpclient.WithDialerKeepAlive(30*time.Second)).
+		Post(parsed.Host).
+		Path(parsed.Path).
+		Header("Content-Type", "application/json;charset=utf-8").
+		JSONBody(m).Do().
+		Body(&buf)
+	if err != nil {
+		err = errors.Errorf("Feishu publish: %v, err: %v", parsed.Host, err)
+		logrus.Error(err)
+		return errors.Wrap(FeishuSendErr, err.Error())
+	}
+	if !resp.IsOK() {
+		err = errors.Errorf("Feishu publish: %v, httpcode: %d", parsed.Host, resp.StatusCode())
+		logrus.Error(err)
+		return errors.Wrap(FeishuSendErr, err.Error())
+	}
+	// 新版接口返回 code 和 msg，旧版返回 StatusCode 和 StatusMessage
+	var r struct {
+		Code          int    `json:"code"`
+		Msg           string `json:"msg"`
+		StatusCode    int    `json:"StatusCode"`
+		StatusMessage string `json:"StatusMessage"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &r); err != nil {
+		logrus.Warningf("Feishu publish: %v, bad response body: %v", parsed.Host, buf.String())
+		return nil
+	}
+	if r.Code != 0 {
+		err = errors.Errorf("Feishu publish: %v, Feishu code: %d, msg: %s", parsed.Host, r.Code, r.Msg)
+		logrus.Error(err)
+		return errors.Wrap(FeishuSendErr, err.Error())
+	}
+	if r.StatusCode != 0 {
+		err = errors.Errorf("Feishu publish: %v, Feishu code: %d, msg: %s", parsed.Host, r.StatusCode, r.StatusMessage)
+		logrus.Error(err)
+		return errors.Wrap(FeishuSendErr, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feishu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	// base64(HmacSHA256(key: "1599360473\ndemo", data: ""))
+	assert.Equal(t, "l1N0gAcBjdwBvGm1xMjOF0XSyaLRpR7tuO5dHfhAYc8=", sign("1599360473", "demo"))
+	ts, s := Sign("demo")
+	assert.Equal(t, sign(ts, "demo"), s)
+}
+
+func TestNewFeishuMessage(t *testing.T) {
+	m := NewFeishuMessage("title", "### header\ncontent")
+	assert.Equal(t, "interactive", m.MsgType)
+	assert.Equal(t, "title", m.Card.Header.Title.Content)
+	assert.Equal(t, "**header**\ncontent", m.Card.Elements[0].Content)
+	assert.Nil(t, NewFeishuMessage("", "content").Card.Header)
+}
@@ -239,6 +239,20 @@ func (d *GroupSubscriber) Publish(dest string, content string, time int64, msg *
 			if len(groupDetail.WebHookList) > 0 {
 				d.routeMessage(msg, &chr)
 			}
+		} else if label, targets := imTargets(channel.Name, groupDetail); label != "" {
+			msg := &types.Message{
+				Content: template.Render(channel.Template, channel.Params),
+				Time:    time,
+				Labels: map[types.LabelKey]interface{}{
+					label: targets,
+					"MARKDOWN": map[string]string{
+						"title": template.Render(channel.Params["title"], channel.Params),
+					},
+				},
+			}
+			if len(targets) > 0 {
+				d.routeMessage(msg, &chr)
+			}
 		} else if channel.Name == "dingtalk_work_notice" {
 			mobiles := []string{}
 			for _, user := range groupDetail.Users {
@@ -262,6 +276,21 @@ func (d *GroupSubscriber) Publish(dest string, content string, time int64, msg *
 	return errs
 }
 
+// imTargets 返回 IM 机器人渠道对应的 label 和通知组中的机器人地址，非 IM 渠道返回空
+func imTargets(channel string, groupDetail *apistructs.NotifyGroupDetail) (types.LabelKey, []apistructs.Target) {
+	switch apistructs.NotifyTargetType(channel) {
+	case apistructs.SlackNotifyTarget:
+		return "SLACK", groupDetail.SlackList
+	case apistructs.TeamsNotifyTarget:
+		return "TEAMS", groupDetail.TeamsList
+	case apistructs.FeishuNotifyTarget:
+		return "FEISHU", groupDetail.FeishuList
+	case apistructs.WeComNotifyTarget:
+		return "WECOM", groupDetail.WeComList
+	}
+	return "", nil
+}
+
 func (d *GroupSubscriber) Status() interface{} {
 	return nil
 }
@@ -0,0 +1,48 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subscriber
+
+import (
+	"encoding/json"
+	"regexp"
+
+	"github.com/erda-project/erda/modules/eventbox/types"
+)
+
+// MarkdownTitle 获取 MARKDOWN label 中的标题，没有则返回空
+func MarkdownTitle(m *types.Message) string {
+	md, ok := m.Labels[types.LabelKey("MARKDOWN").NormalizeLabelKey()]
+	if !ok {
+		return ""
+	}
+	raw, err := json.Marshal(md)
+	if err != nil {
+		return ""
+	}
+	var v struct {
+		Title string `json:"title"`
+	}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return ""
+	}
+	return v.Title
+}
+
+var markdownHeading = regexp.MustCompile(`(?m)^#{1,6}\s*(.+?)\s*$`)
+
+// MarkdownHeadingToBold 将 markdown 标题转换为加粗，用于不支持标题语法的消息卡片
+func MarkdownHeadingToBold(content string) string {
+	return markdownHeading.ReplaceAllString(content, "**$1**")
+}
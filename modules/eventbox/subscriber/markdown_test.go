@@ -0,0 +1,35 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subscriber
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/modules/eventbox/types"
+)
+
+func TestMarkdownTitle(t *testing.T) {
+	m := &types.Message{Labels: map[types.LabelKey]interface{}{
+		"/MARKDOWN": map[string]string{"title": "告警"},
+	}}
+	assert.Equal(t, "告警", MarkdownTitle(m))
+	assert.Equal(t, "", MarkdownTitle(&types.Message{}))
+}
+
+func TestMarkdownHeadingToBold(t *testing.T) {
+	assert.Equal(t, "**title**\ncontent # not heading", MarkdownHeadingToBold("### title  \ncontent # not heading"))
+}
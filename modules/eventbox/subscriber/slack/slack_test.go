@@ -0,0 +1,38 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToMrkdwn(t *testing.T) {
+	assert.Equal(t, "*告警*\n实例: *mysql*\n<https://erda.cloud/record|查看记录>",
+		ToMrkdwn("### 告警\n实例: **mysql**\n[查看记录](https://erda.cloud/record)"))
+}
+
+func TestNewSlackMessage(t *testing.T) {
+	m := NewSlackMessage("title", "**content**")
+	assert.Equal(t, "title", m.Text)
+	assert.Equal(t, 2, len(m.Blocks))
+	assert.Equal(t, "header", m.Blocks[0].Type)
+	assert.Equal(t, "*content*", m.Blocks[1].Text.Text)
+
+	m = NewSlackMessage("", "content")
+	assert.Equal(t, "content", m.Text)
+	assert.Equal(t, 1, len(m.Blocks))
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package teams
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/eventbox/subscriber"
+	"github.com/erda-project/erda/modules/eventbox/subscriber/dingding"
+	"github.com/erda-project/erda/modules/eventbox/types"
+	"github.com/erda-project/erda/pkg/http/httpclient"
+)
+
+var (
+	TeamsSendErr   = errors.New("send Teams fail")
+	TeamsBadURLErr = errors.New("bad Teams URL")
+)
+
+// example teams message, the adaptive card TextBlock supports a subset of markdown:
+// {
+//     "type": "message",
+//     "attachments": [{
+//         "contentType": "application/vnd.microsoft.card.adaptive",
+//         "content": {
+//             "$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+//             "type": "AdaptiveCard",
+//             "version": "1.4",
+//             "body": [
+//                 {"type": "TextBlock", "text": "title", "weight": "Bolder", "size": "Medium", "wrap": true},
+//                 {"type": "TextBlock", "text": "**bold** [link](https://erda.cloud)", "wrap": true}
+//             ]
+//         }
+//     }]
+// }
+type TextBlock struct {
+	Type   string `json:"type"`
+	Text   string `json:"text"`
+	Weight string `json:"weight,omitempty"`
+	Size   string `json:"size,omitempty"`
+	Wrap   bool   `json:"wrap"`
+}
+type AdaptiveCard struct {
+	Schema  string      `json:"$schema"`
+	Type    string      `json:"type"`
+	Version string      `json:"version"`
+	Body    []TextBlock `json:"body"`
+}
+type Attachment struct {
+	ContentType string       `json:"contentType"`
+	Content     AdaptiveCard `json:"content"`
+}
+type TeamsMessage struct {
+	Type        string       `json:"type"`
+	Attachments []Attachment `json:"attachments"`
+}
+
+type TeamsSubscriber struct {
+	proxy string
+}
+
+func New(proxy string) subscriber.Subscriber {
+	return &TeamsSubscriber{
+		proxy: proxy,
+	}
+}
+
+// example URL: https://xxx.webhook.office.com/webhookb2/xxx/IncomingWebhook/xxx/xxx
+func (s *TeamsSubscriber) Publish(dest string, content string, time int64, msg *types.Message) []error {
+	var targets []apistructs.Target
+	if err := json.Unmarshal([]byte(dest), &targets); err != nil {
+		return []error{errors.New("illegal dest")}
+	}
+	m := NewTeamsMessage(subscriber.MarkdownTitle(msg), dingding.PrettyPrint(content, false))
+	errs := []error{}
+	for _, target := range targets {
+		if err := s.TeamsSend(target.Receiver, m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// NewTeamsMessage 生成 adaptive card 消息
+func NewTeamsMessage(title, content string) *TeamsMessage {
+	card := AdaptiveCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+	}
+	if title != "" {
+		card.Body = append(card.Body, TextBlock{Type: "TextBlock", Text: title, Weight: "Bolder", Size: "Medium", Wrap: true})
+	}
+	if content != "" {
+		card.Body = append(card.Body, TextBlock{Type: "TextBlock", Text: subscriber.MarkdownHeadingToBold(content), Wrap: true})
+	}
+	return &TeamsMessage{
+		Type: "message",
+		Attachments: []Attachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content:     card,
+		}},
+	}
+}
+
+func (s *TeamsSubscriber) Status() interface{} {
+	return nil
+}
+
+func (s *TeamsSubscriber) Name() string {
+	return "TEAMS"
+}
+
+func (s *TeamsSubscriber) TeamsSend(u string, m *TeamsMessage) error {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Host == "" {
+		return errors.Wrapf(TeamsBadURLErr, "url: %s", u)
+	}
+	var buf bytes.Buffer
+	resp, err := httpclient.New(httpclient.WithHTTPS(), httpclient.WithProxy(s.proxy), httpclient.WithDialerKeepAlive(30*time.Second)).
+		Post(parsed.Host).
+		Path(parsed.Path).
+		Params(parsed.Query()).
+		Header("Content-Type", "application/json;charset=utf-8").
+		JSONBody(m).Do().
+		Body(&buf)
+	if err != nil {
+		err = errors.Errorf("Teams publish: %v, err: %v", parsed.Host, err)
+		logrus.Error(err)
+		return errors.Wrap(TeamsSendErr, err.Error())
+	}
+	// incoming webhook 返回 200，workflows 返回 202
+	if !resp.IsOK() {
+		err = errors.Errorf("Teams publish: %v, httpcode: %d, body: %s", parsed.Host, resp.StatusCode(), buf.String())
+		logrus.Error(err)
+		return errors.Wrap(TeamsSendErr, err.Error())
+	}
+	return nil
+}
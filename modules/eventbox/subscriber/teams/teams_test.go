@@ -0,0 +1,86 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package teams
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTeamsMessage(t *testing.T) {
+	m := NewTeamsMessage("title", "### header\n**content**")
+	raw, err := json.Marshal(m)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"type": "message",
+		"attachments": [{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": {
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type": "AdaptiveCard",
+				"version": "1.4",
+				"body": [
+					{"type": "TextBlock", "text": "title", "weight": "Bolder", "size": "Medium", "wrap": true},
+					{"type": "TextBlock", "text": "**header**\n**content**", "wrap": true}
+				]
+			}
+		}]
+	}`, string(raw))
+
+	m = NewTeamsMessage("", "content")
+	assert.Equal(t, 1, len(m.Attachments[0].Content.Body))
+	assert.Equal(t, "content", m.Attachments[0].Content.Body[0].Text)
+}
+
+func TestTeamsSend(t *testing.T) {
+	status := http.StatusAccepted
+	var got struct {
+		path  string
+		sig   string
+		ctype string
+		body  TeamsMessage
+	}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		got.path = req.URL.Path
+		got.sig = req.URL.Query().Get("sig")
+		got.ctype = req.Header.Get("Content-Type")
+		raw, _ := ioutil.ReadAll(req.Body)
+		json.Unmarshal(raw, &got.body)
+		rw.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	s := &TeamsSubscriber{}
+	m := NewTeamsMessage("title", "content")
+	err := s.TeamsSend(srv.URL+"/workflows/xxx/triggers/manual/paths/invoke?api-version=2016-06-01&sig=abc", m)
+	assert.Nil(t, err)
+	assert.Equal(t, "/workflows/xxx/triggers/manual/paths/invoke", got.path)
+	assert.Equal(t, "abc", got.sig)
+	assert.Contains(t, got.ctype, "application/json")
+	assert.Equal(t, *m, got.body)
+
+	status = http.StatusBadRequest
+	err = s.TeamsSend(srv.URL+"/webhookb2/xxx", m)
+	assert.Equal(t, TeamsSendErr, errors.Cause(err))
+
+	err = s.TeamsSend("not-a-url", m)
+	assert.Equal(t, TeamsBadURLErr, errors.Cause(err))
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/modules/eventbox/monitor"
+	"github.com/erda-project/erda/modules/eventbox/subscriber"
+	"github.com/erda-project/erda/modules/eventbox/types"
+	"github.com/erda-project/erda/modules/eventbox/webhook"
+)
+
+// hook IDs
+type Dest []string
+
+// WebhookSubscriber 签名投递事件到用户的 webhook，并记录投递结果，失败的投递会自动重试
+type WebhookSubscriber struct {
+	impl *webhook.WebHookImpl
+}
+
+func New() (subscriber.Subscriber, error) {
+	impl, err := webhook.NewWebHookImpl()
+	if err != nil {
+		return nil, err
+	}
+	go impl.LoopRetryDeliveries(context.Background())
+	return &WebhookSubscriber{impl: impl}, nil
+}
+
+func (s *WebhookSubscriber) Publish(dest string, content string, timestamp int64, msg *types.Message) []error {
+	monitor.Notify(monitor.MonitorInfo{Tp: monitor.HTTPOutput})
+
+	var d Dest
+	if err := json.NewDecoder(bytes.NewReader([]byte(dest))).Decode(&d); err != nil {
+		return []error{err}
+	}
+	errs := []error{}
+	for _, hookID := range d {
+		delivery, err := s.impl.DeliverByID(hookID, []byte(content))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		logrus.Infof("succ webhook delivery: %s, hook: %s", delivery.ID, hookID)
+	}
+	return errs
+}
+
+func (s *WebhookSubscriber) Status() interface{} {
+	return nil
+}
+
+func (s *WebhookSubscriber) Name() string {
+	return webhook.DeliveryLabel
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wecom
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/eventbox/subscriber"
+	"github.com/erda-project/erda/modules/eventbox/subscriber/dingding"
+	"github.com/erda-project/erda/modules/eventbox/types"
+	"github.com/erda-project/erda/pkg/http/httpclient"
+)
+
+var (
+	WeComSendErr   = errors.New("send WeCom fail")
+	WeComBadURLErr = errors.New("bad WeCom URL")
+)
+
+// markdown 内容最长 4096 个字节
+const maxMarkdownLen = 4096
+
+// example wecom message:
+// {
+//     "msgtype": "markdown",
+//     "markdown": {
+//         "content": "### title\n**bold** [link](https://erda.cloud)"
+//     }
+// }
+type WeComMarkdown struct {
+	Content string `json:"content"`
+}
+type WeComMessage struct {
+	Msgtype  string        `json:"msgtype"`
+	Markdown WeComMarkdown `json:"markdown"`
+}
+
+type WeComSubscriber struct {
+	proxy string
+}
+
+func New(proxy string) subscriber.Subscriber {
+	return &WeComSubscriber{
+		proxy: proxy,
+	}
+}
+
+// example URL: https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=xxxx
+func (s *WeComSubscriber) Publish(dest string, content string, time int64, msg *types.Message) []error {
+	var targets []apistructs.Target
+	if err := json.Unmarshal([]byte(dest), &targets); err != nil {
+		return []error{errors.New("illegal dest")}
+	}
+	m := NewWeComMessage(subscriber.MarkdownTitle(msg), dingding.PrettyPrint(content, false))
+	errs := []error{}
+	for _, target := range targets {
+		if err := s.WeComSend(target.Receiver, m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// NewWeComMessage 生成 markdown 消息，标题作为一级标题，超长的内容会被截断
+func NewWeComMessage(title, content string) *WeComMessage {
+	if title != "" {
+		content = "### " + title + "\n" + content
+	}
+	if len(content) > maxMarkdownLen {
+		content = content[:maxMarkdownLen-3]
+		// 避免截断出不完整的 utf8 字符
+		for !utf8.ValidString(content) {
+			content = content[:len(content)-1]
+		}
+		content += "..."
+	}
+	return &WeComMessage{
+		Msgtype:  "markdown",
+		Markdown: WeComMarkdown{Content: content},
+	}
+}
+
+func (s *WeComSubscriber) Status() interface{} {
+	return nil
+}
+
+func (s *WeComSubscriber) Name() string {
+	return "WECOM"
+}
+
+func (s *WeComSubscriber) WeComSend(u string, m *WeComMessage) error {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Host == "" {
+		return errors.Wrapf(WeComBadURLErr, "url: %s", u)
+	}
+	if parsed.Query().Get("key") == "" {
+		err = errors.Errorf("WeCom publish: %v, not provide key", parsed.Host)
+		logrus.Error(err)
+		return errors.Wrap(WeComBadURLErr, err.Error())
+	}
+	var buf bytes.Buffer
+	resp, err := httpclient.New(httpclient.WithHTTPS(), httpclient.WithProxy(s.proxy), httpclient.WithDialerKeepAlive(30*time.Second)).
+		Post(parsed.Host).
+		Path(parsed.Path).
+		Params(parsed.Query()).
+		Header("Content-Type", "application/json;charset=utf-8").
+		JSONBody(m).Do().
+		Body(&buf)
+	if err != nil {
+		err = errors.Errorf("WeCom publish: %v, err: %v", parsed.Host, err)
+		logrus.Error(err)
+		return errors.Wrap(WeComSendErr, err.Error())
+	}
+	if !resp.IsOK() {
+		err = errors.Errorf("WeCom publish: %v, httpcode: %d", parsed.Host, resp.StatusCode())
+		logrus.Error(err)
+		return errors.Wrap(WeComSendErr, err.Error())
+	}
+	var r struct {
+		Errcode int    `json:"errcode"`
+		Errmsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &r); err != nil {
+		logrus.Warningf("WeCom publish: %v, bad response body: %v", parsed.Host, buf.String())
+		return nil
+	}
+	if r.Errcode != 0 {
+		err = errors.Errorf("WeCom publish: %v, WeCom errcode: %d, errmsg: %s", parsed.Host, r.Errcode, r.Errmsg)
+		logrus.Error(err)
+		return errors.Wrap(WeComSendErr, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wecom
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWeComMessage(t *testing.T) {
+	m := NewWeComMessage("title", "content")
+	assert.Equal(t, "markdown", m.Msgtype)
+	assert.Equal(t, "### title\ncontent", m.Markdown.Content)
+
+	m = NewWeComMessage("", strings.Repeat("告警", maxMarkdownLen))
+	assert.True(t, len(m.Markdown.Content) <= maxMarkdownLen)
+	assert.True(t, utf8.ValidString(m.Markdown.Content))
+	assert.True(t, strings.HasSuffix(m.Markdown.Content, "..."))
+}
@@ -0,0 +1,369 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/eventbox/constant"
+	"github.com/erda-project/erda/pkg/http/httpclient"
+	"github.com/erda-project/erda/pkg/jsonstore"
+)
+
+type Delivery = apistructs.WebhookDelivery
+type DeliveryAttempt = apistructs.WebhookDeliveryAttempt
+
+// headers of the webhook request, the receiver verifies the request by
+// hex(HMAC-SHA256(secret, "<timestamp>.<body>")) == strings.TrimPrefix(<signature>, "sha256=")
+const (
+	SignatureHeader = "X-Erda-Webhook-Signature"
+	TimestampHeader = "X-Erda-Webhook-Timestamp"
+	DeliveryHeader  = "X-Erda-Webhook-Delivery"
+	EventHeader     = "X-Erda-Webhook-Event"
+)
+
+// DeliveryLabel the label of message which is delivered to the hooks by ID
+const DeliveryLabel = "WEBHOOK_DELIVERY"
+
+const (
+	// maxDeliveryRetries the delivery is dead after so many failed retries
+	maxDeliveryRetries = 5
+	// retryBaseInterval the n-th retry happens after retryBaseInterval * 2^(n-1)
+	retryBaseInterval = 30 * time.Second
+	// maxDeliveriesPerHook only the latest deliveries are kept
+	maxDeliveriesPerHook = 100
+	// maxResponseBodyLen response body longer than it is truncated in delivery log
+	maxResponseBodyLen = 512
+	// maxPayloadLen the payload of failed delivery is kept for retry and redelivery only if it is not longer than it,
+	// otherwise the delivery is dead at once
+	maxPayloadLen = 64 * 1024
+
+	deliveryTimeout = 10 * time.Second
+)
+
+// Sign returns the signature of the payload, which is sent in SignatureHeader
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// DeliverByID delivers the event message to the hook, failed delivery is retried by LoopRetryDeliveries
+func (w *WebHookImpl) DeliverByID(hookID string, payload []byte) (Delivery, error) {
+	h, err := w.getHook(hookID)
+	if err != nil {
+		return Delivery{}, errors.Wrap(InternalServerErr, fmt.Sprintf("get hook: %v, err: %v", hookID, err))
+	}
+	event := EventMessage{}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return Delivery{}, errors.Wrap(BadRequestErr, fmt.Sprintf("bad event message: %v", err))
+	}
+	d := Delivery{
+		ID:          genDeliveryID(),
+		HookID:      h.ID,
+		Event:       event.Event,
+		URL:         h.URL,
+		Status:      apistructs.WebhookDeliveryStatusPending,
+		PayloadSize: len(payload),
+		Attempts:    []DeliveryAttempt{},
+		CreatedAt:   nowTimestamp(),
+	}
+	if err := w.attempt(h, &d, payload); err != nil {
+		return d, err
+	}
+	w.pruneDeliveries(h.ID)
+	if d.Status != apistructs.WebhookDeliveryStatusSucceeded {
+		return d, deliveryErr(d)
+	}
+	return d, nil
+}
+
+// ListDeliveries lists the deliveries of the hook, the latest first
+func (w *WebHookImpl) ListDeliveries(realOrg, hookID string) ([]Delivery, error) {
+	if _, err := w.InspectHook(realOrg, hookID); err != nil {
+		return nil, err
+	}
+	keys, err := w.deliveryKeys(hookID)
+	if err != nil {
+		return nil, errors.Wrap(InternalServerErr, fmt.Sprintf("list deliveries: %v", err))
+	}
+	r := []Delivery{}
+	for i := len(keys) - 1; i >= 0; i-- {
+		d := Delivery{}
+		if err := w.deliveries.Get(context.Background(), keys[i], &d); err != nil {
+			if err == jsonstore.NotFoundErr {
+				continue
+			}
+			return nil, errors.Wrap(InternalServerErr, fmt.Sprintf("get delivery: %v, err: %v", keys[i], err))
+		}
+		r = append(r, d)
+	}
+	return r, nil
+}
+
+// Redeliver delivers the payload of the delivery again, e.g. a dead one after the receiver is fixed,
+// the delivery is retried as a new one if the attempt fails.
+// Only failed deliveries can be redelivered, the payload of succeeded ones is not kept.
+func (w *WebHookImpl) Redeliver(realOrg, hookID, deliveryID string) (Delivery, error) {
+	if _, err := w.InspectHook(realOrg, hookID); err != nil {
+		return Delivery{}, err
+	}
+	h, err := w.getHook(hookID)
+	if err != nil {
+		return Delivery{}, errors.Wrap(InternalServerErr, err.Error())
+	}
+	d := Delivery{}
+	if err := w.deliveries.Get(context.Background(), mkDeliveryKey(hookID, deliveryID), &d); err != nil {
+		if err == jsonstore.NotFoundErr {
+			return Delivery{}, fmt.Errorf("not found")
+		}
+		return Delivery{}, errors.Wrap(InternalServerErr, err.Error())
+	}
+	var payload string
+	if err := w.deliveries.Get(context.Background(), mkPayloadKey(hookID, deliveryID), &payload); err != nil {
+		if err == jsonstore.NotFoundErr {
+			return Delivery{}, errors.Wrap(BadRequestErr, "payload of the delivery is not kept, only failed delivery can be redelivered")
+		}
+		return Delivery{}, errors.Wrap(InternalServerErr, err.Error())
+	}
+	var unused interface{}
+	w.deliveries.Remove(context.Background(), mkRetryIndex(hookID, deliveryID), &unused) // ignore err
+	d.Status = apistructs.WebhookDeliveryStatusPending
+	d.Retries = 0
+	d.NextRetryAt = ""
+	// redeliver to the current url of the hook
+	d.URL = h.URL
+	err = w.attempt(h, &d, []byte(payload))
+	return d, err
+}
+
+// LoopRetryDeliveries retries the failed deliveries when it is time
+func (w *WebHookImpl) LoopRetryDeliveries(ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.retryDeliveries(time.Now())
+		}
+	}
+}
+
+func (w *WebHookImpl) retryDeliveries(now time.Time) {
+	keys, err := w.deliveries.ListKeys(context.Background(), constant.WebhookRetryDir)
+	if err != nil {
+		logrus.Errorf("list webhook retry index: %v", err)
+		return
+	}
+	for _, k := range keys {
+		parts := strings.Split(strings.TrimPrefix(k, constant.WebhookRetryDir+"/"), "/")
+		if len(parts) != 2 {
+			continue
+		}
+		hookID, deliveryID := parts[0], parts[1]
+		d := Delivery{}
+		if err := w.deliveries.Get(context.Background(), mkDeliveryKey(hookID, deliveryID), &d); err != nil {
+			var unused interface{}
+			w.deliveries.Remove(context.Background(), k, &unused) // ignore err
+			continue
+		}
+		nextRetryAt, err := time.ParseInLocation("2006-01-02 15:04:05", d.NextRetryAt, cst)
+		if err == nil && now.Before(nextRetryAt) {
+			continue
+		}
+		// claim the retry by removing the index, in case of other eventbox instances
+		var owner string
+		if err := w.deliveries.Remove(context.Background(), k, &owner); err != nil || owner == "" {
+			continue
+		}
+		h, err := w.getHook(hookID)
+		if err != nil {
+			// the hook is deleted
+			continue
+		}
+		var payload string
+		if err := w.deliveries.Get(context.Background(), mkPayloadKey(hookID, deliveryID), &payload); err != nil {
+			logrus.Errorf("get webhook delivery payload: %v, err: %v", k, err)
+			d.Status = apistructs.WebhookDeliveryStatusDead
+			d.NextRetryAt = ""
+			w.deliveries.Put(context.Background(), mkDeliveryKey(hookID, deliveryID), d) // ignore err
+			continue
+		}
+		if err := w.attempt(h, &d, []byte(payload)); err != nil {
+			logrus.Errorf("retry webhook delivery: %v, err: %v", k, err)
+		} else if d.Status != apistructs.WebhookDeliveryStatusSucceeded {
+			logrus.Warnf("retry webhook delivery: %v, err: %v", k, deliveryErr(d))
+		}
+	}
+}
+
+// attempt posts the payload to the hook once, and saves the result to the delivery log,
+// the error returned is only about saving, the result of the attempt is in the status of delivery.
+// The payload is kept only while the delivery is failed.
+func (w *WebHookImpl) attempt(h Hook, d *Delivery, payload []byte) error {
+	a := w.post(h, d, payload)
+	d.Attempts = append(d.Attempts, a)
+	d.UpdatedAt = nowTimestamp()
+	if a.Error == "" && a.ResponseStatus/100 == 2 {
+		d.Status = apistructs.WebhookDeliveryStatusSucceeded
+		d.NextRetryAt = ""
+	} else if d.Retries >= maxDeliveryRetries || len(payload) > maxPayloadLen {
+		d.Status = apistructs.WebhookDeliveryStatusDead
+		d.NextRetryAt = ""
+	} else {
+		d.Retries++
+		d.Status = apistructs.WebhookDeliveryStatusRetrying
+		d.NextRetryAt = time.Now().Add(retryInterval(d.Retries)).In(cst).Format("2006-01-02 15:04:05")
+	}
+	if err := w.deliveries.Put(context.Background(), mkDeliveryKey(d.HookID, d.ID), d); err != nil {
+		return errors.Wrap(InternalServerErr, fmt.Sprintf("save delivery: %v", err))
+	}
+	if d.Status == apistructs.WebhookDeliveryStatusSucceeded {
+		var unused interface{}
+		w.deliveries.Remove(context.Background(), mkPayloadKey(d.HookID, d.ID), &unused) // ignore err
+	} else if len(payload) <= maxPayloadLen {
+		if err := w.deliveries.Put(context.Background(), mkPayloadKey(d.HookID, d.ID), string(payload)); err != nil {
+			return errors.Wrap(InternalServerErr, fmt.Sprintf("save delivery payload: %v", err))
+		}
+	}
+	if d.Status == apistructs.WebhookDeliveryStatusRetrying {
+		if err := w.deliveries.Put(context.Background(), mkRetryIndex(d.HookID, d.ID), d.HookID); err != nil {
+			return errors.Wrap(InternalServerErr, fmt.Sprintf("save delivery retry index: %v", err))
+		}
+	}
+	return nil
+}
+
+func (w *WebHookImpl) post(h Hook, d *Delivery, payload []byte) DeliveryAttempt {
+	now := time.Now()
+	a := DeliveryAttempt{
+		RequestHeaders: map[string]string{
+			"Content-Type":  "application/json",
+			TimestampHeader: strconv.FormatInt(now.Unix(), 10),
+			DeliveryHeader:  d.ID,
+			EventHeader:     d.Event,
+		},
+		DeliveredAt: now.In(cst).Format("2006-01-02 15:04:05"),
+	}
+	if h.Secret != "" {
+		a.RequestHeaders[SignatureHeader] = Sign(h.Secret, now.Unix(), payload)
+	}
+	u, err := url.Parse(d.URL)
+	if err != nil {
+		a.Error = fmt.Sprintf("bad hook url: %v", err)
+		return a
+	}
+	opt := []httpclient.OpOption{httpclient.WithTimeout(deliveryTimeout, deliveryTimeout)}
+	if u.Scheme == "https" {
+		opt = append(opt, httpclient.WithHTTPS())
+	}
+	req := httpclient.New(opt...).Post(u.Host).Path(u.Path).Params(u.Query())
+	for k, v := range a.RequestHeaders {
+		req = req.Header(k, v)
+	}
+	var body bytes.Buffer
+	resp, err := req.RawBody(bytes.NewReader(payload)).Do().Body(&body)
+	a.Latency = time.Since(now).Milliseconds()
+	if err != nil {
+		a.Error = err.Error()
+		return a
+	}
+	a.ResponseStatus = resp.StatusCode()
+	a.ResponseBody = body.String()
+	if len(a.ResponseBody) > maxResponseBodyLen {
+		a.ResponseBody = a.ResponseBody[:maxResponseBodyLen]
+	}
+	return a
+}
+
+// pruneDeliveries removes the oldest deliveries of the hook
+func (w *WebHookImpl) pruneDeliveries(hookID string) {
+	keys, err := w.deliveryKeys(hookID)
+	if err != nil || len(keys) <= maxDeliveriesPerHook {
+		return
+	}
+	for _, k := range keys[:len(keys)-maxDeliveriesPerHook] {
+		var unused interface{}
+		parts := strings.Split(k, "/")
+		w.deliveries.Remove(context.Background(), mkRetryIndex(hookID, parts[len(parts)-1]), &unused) // ignore err
+		w.deliveries.Remove(context.Background(), mkPayloadKey(hookID, parts[len(parts)-1]), &unused) // ignore err
+		w.deliveries.Remove(context.Background(), k, &unused)                                         // ignore err
+	}
+}
+
+// deliveryKeys returns the delivery keys of the hook, the oldest first
+func (w *WebHookImpl) deliveryKeys(hookID string) ([]string, error) {
+	keys, err := w.deliveries.ListKeys(context.Background(), strings.Join([]string{constant.WebhookDeliveryDir, hookID}, "/")+"/")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func deliveryErr(d Delivery) error {
+	a := d.Attempts[len(d.Attempts)-1]
+	return errors.Errorf("deliver to url: %s failed, status: %s, response: %d, err: %s",
+		d.URL, d.Status, a.ResponseStatus, a.Error)
+}
+
+// retryInterval returns the interval before the n-th retry
+func retryInterval(n int) time.Duration {
+	if n < 1 {
+		n = 1
+	}
+	return retryBaseInterval * time.Duration(1<<uint(n-1))
+}
+
+// webhook delivery dir structure
+// /<webhookdeliverydir>/<hookID>/<deliveryID> -> <delivery>
+// /<webhookretrydir>/<hookID>/<deliveryID> -> <hookID>
+// /<webhookpayloaddir>/<hookID>/<deliveryID> -> <payload>, only for failed deliveries
+
+func mkDeliveryKey(hookID, deliveryID string) string {
+	return strings.Join([]string{constant.WebhookDeliveryDir, hookID, deliveryID}, "/")
+}
+
+func mkRetryIndex(hookID, deliveryID string) string {
+	return strings.Join([]string{constant.WebhookRetryDir, hookID, deliveryID}, "/")
+}
+
+func mkPayloadKey(hookID, deliveryID string) string {
+	return strings.Join([]string{constant.WebhookPayloadDir, hookID, deliveryID}, "/")
+}
+
+// genDeliveryID generates the delivery id which is sorted by creation time
+func genDeliveryID() string {
+	return fmt.Sprintf("%019d-%s", time.Now().UnixNano(), genID()[:6])
+}
@@ -0,0 +1,248 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/eventbox/constant"
+	"github.com/erda-project/erda/pkg/jsonstore"
+)
+
+func newTestImpl(t *testing.T) *WebHookImpl {
+	js, err := jsonstore.New(jsonstore.UseMemStore())
+	assert.Nil(t, err)
+	deliveries, err := jsonstore.New(jsonstore.UseMemStore())
+	assert.Nil(t, err)
+	return &WebHookImpl{js: js, deliveries: deliveries}
+}
+
+const testSecret = "test-secret"
+
+func createTestHook(t *testing.T, impl *WebHookImpl, url string) Hook {
+	return createTestHookWithSecret(t, impl, url, testSecret)
+}
+
+func createTestHookWithSecret(t *testing.T, impl *WebHookImpl, url, secret string) Hook {
+	id, err := impl.CreateHook("1", CreateHookRequest{
+		Name:   "test-hook",
+		Events: []string{"test-event"},
+		URL:    url,
+		Active: true,
+		Secret: secret,
+		HookLocation: HookLocation{
+			Org:         "1",
+			Project:     "2",
+			Application: "3",
+		},
+	})
+	assert.Nil(t, err)
+	h, err := impl.getHook(string(id))
+	assert.Nil(t, err)
+	return h
+}
+
+func testPayload(t *testing.T) []byte {
+	m := MkEventMessage(EventLabel{Event: "test-event", OrgID: "1"}, []byte(`{"a":1}`))
+	payload, err := json.Marshal(m)
+	assert.Nil(t, err)
+	return payload
+}
+
+func TestSign(t *testing.T) {
+	s := Sign("secret", 1600000000, []byte(`{}`))
+	assert.Equal(t, s, Sign("secret", 1600000000, []byte(`{}`)))
+	assert.NotEqual(t, s, Sign("secret", 1600000001, []byte(`{}`)))
+	assert.NotEqual(t, s, Sign("other", 1600000000, []byte(`{}`)))
+	assert.Len(t, s, len("sha256=")+64)
+}
+
+func TestDeliverByID(t *testing.T) {
+	impl := newTestImpl(t)
+	var verified bool
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		ts, _ := strconv.ParseInt(req.Header.Get(TimestampHeader), 10, 64)
+		verified = req.Header.Get(SignatureHeader) == Sign(testSecret, ts, body) &&
+			req.Header.Get(EventHeader) == "test-event"
+		rw.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	h := createTestHook(t, impl, srv.URL+"/hook")
+	assert.Equal(t, testSecret, h.Secret)
+
+	d, err := impl.DeliverByID(h.ID, testPayload(t))
+	assert.Nil(t, err)
+	assert.True(t, verified)
+	assert.Equal(t, apistructs.WebhookDeliveryStatusSucceeded, d.Status)
+	assert.Equal(t, 1, len(d.Attempts))
+	assert.Equal(t, http.StatusOK, d.Attempts[0].ResponseStatus)
+	assert.Equal(t, "ok", d.Attempts[0].ResponseBody)
+	assert.Equal(t, len(testPayload(t)), d.PayloadSize)
+
+	// payload of succeeded delivery is not kept
+	var payload string
+	assert.Equal(t, jsonstore.NotFoundErr, impl.deliveries.Get(context.Background(), mkPayloadKey(h.ID, d.ID), &payload))
+	_, err = impl.Redeliver("1", h.ID, d.ID)
+	assert.NotNil(t, err)
+
+	ds, err := impl.ListDeliveries("1", h.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ds))
+	assert.Equal(t, d.ID, ds[0].ID)
+
+	// other org cannot see the deliveries
+	_, err = impl.ListDeliveries("9", h.ID)
+	assert.NotNil(t, err)
+}
+
+func TestHookSecret(t *testing.T) {
+	impl := newTestImpl(t)
+	var signature string
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		signature = req.Header.Get(SignatureHeader)
+	}))
+	defer srv.Close()
+
+	// not signed without secret
+	h := createTestHookWithSecret(t, impl, srv.URL, "")
+	_, err := impl.DeliverByID(h.ID, testPayload(t))
+	assert.Nil(t, err)
+	assert.Empty(t, signature)
+	h, err = impl.getHook(h.ID)
+	assert.Nil(t, err)
+	assert.Empty(t, h.Secret)
+	inspect, err := impl.InspectHook("1", h.ID)
+	assert.Nil(t, err)
+	assert.Empty(t, inspect.Secret)
+
+	// set the secret
+	_, err = impl.EditHook("1", h.ID, EditHookRequest{Secret: "my-secret", Active: true})
+	assert.Nil(t, err)
+	h, err = impl.getHook(h.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "my-secret", h.Secret)
+	_, err = impl.DeliverByID(h.ID, testPayload(t))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, signature)
+
+	// secret is not changed if not provided
+	_, err = impl.EditHook("1", h.ID, EditHookRequest{URL: srv.URL, Active: true})
+	assert.Nil(t, err)
+	h, err = impl.getHook(h.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "my-secret", h.Secret)
+
+	// secret is masked in inspect and list
+	inspect, err = impl.InspectHook("1", h.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "******cret", inspect.Secret)
+	hs, err := impl.ListHooks(HookLocation{Org: "1", Project: "2", Application: "3"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(hs))
+	assert.Equal(t, inspect.Secret, hs[0].Secret)
+}
+
+func TestDeliveryRetryAndRedeliver(t *testing.T) {
+	impl := newTestImpl(t)
+	fail := true
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if fail {
+			rw.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+	h := createTestHook(t, impl, srv.URL)
+
+	d, err := impl.DeliverByID(h.ID, testPayload(t))
+	assert.NotNil(t, err)
+	assert.Equal(t, apistructs.WebhookDeliveryStatusRetrying, d.Status)
+	assert.Equal(t, 1, d.Retries)
+
+	// not the time to retry
+	impl.retryDeliveries(time.Now())
+	ds, err := impl.ListDeliveries("1", h.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ds[0].Attempts))
+
+	for i := 0; i < maxDeliveryRetries; i++ {
+		impl.retryDeliveries(time.Now().Add(24 * time.Hour))
+	}
+	ds, err = impl.ListDeliveries("1", h.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, apistructs.WebhookDeliveryStatusDead, ds[0].Status)
+	assert.Equal(t, maxDeliveryRetries+1, len(ds[0].Attempts))
+	assert.Equal(t, http.StatusInternalServerError, ds[0].Attempts[maxDeliveryRetries].ResponseStatus)
+
+	// dead delivery is not retried any more
+	impl.retryDeliveries(time.Now().Add(24 * time.Hour))
+	ds, err = impl.ListDeliveries("1", h.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, maxDeliveryRetries+1, len(ds[0].Attempts))
+
+	// payload of dead delivery is kept for redelivery
+	var payload string
+	assert.Nil(t, impl.deliveries.Get(context.Background(), mkPayloadKey(h.ID, d.ID), &payload))
+	assert.Equal(t, string(testPayload(t)), payload)
+
+	fail = false
+	d, err = impl.Redeliver("1", h.ID, d.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, apistructs.WebhookDeliveryStatusSucceeded, d.Status)
+	assert.Equal(t, maxDeliveryRetries+2, len(d.Attempts))
+
+	_, err = impl.Redeliver("1", h.ID, "not-exist")
+	assert.NotNil(t, err)
+}
+
+func TestDeliverLargePayload(t *testing.T) {
+	impl := newTestImpl(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	h := createTestHook(t, impl, srv.URL)
+
+	m := MkEventMessage(EventLabel{Event: "test-event", OrgID: "1"}, []byte(`"`+strings.Repeat("a", maxPayloadLen)+`"`))
+	payload, err := json.Marshal(m)
+	assert.Nil(t, err)
+	d, err := impl.DeliverByID(h.ID, payload)
+	assert.NotNil(t, err)
+	assert.Equal(t, apistructs.WebhookDeliveryStatusDead, d.Status)
+	assert.Equal(t, len(payload), d.PayloadSize)
+
+	var kept string
+	assert.Equal(t, jsonstore.NotFoundErr, impl.deliveries.Get(context.Background(), mkPayloadKey(h.ID, d.ID), &kept))
+	keys, err := impl.deliveries.ListKeys(context.Background(), constant.WebhookRetryDir)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(keys))
+}
+
+func TestRetryInterval(t *testing.T) {
+	assert.Equal(t, retryBaseInterval, retryInterval(1))
+	assert.Equal(t, 4*retryBaseInterval, retryInterval(3))
+}
@@ -217,6 +217,45 @@ func (w *WebHookHTTP) DeleteHook(ctx context.Context, req *http.Request, vars ma
 		Compose: true,
 	}, nil
 }
+func (w *WebHookHTTP) ListDeliveries(ctx context.Context, req *http.Request, vars map[string]string) (stypes.Responser, error) {
+	id := vars["id"]
+	orgID := extractOrgIDHeader(req)
+	r, err := w.impl.ListDeliveries(orgID, id)
+	if err != nil {
+		logrus.Error(err)
+		return stypes.HTTPResponse{
+			Error: &stypes.ErrorResponse{
+				Code: toCode(errors.Cause(err)),
+				Msg:  err.Error(),
+			},
+			Compose: true,
+		}, nil
+	}
+	return stypes.HTTPResponse{
+		Content: apistructs.WebhookListDeliveriesResponseData(r),
+		Compose: true,
+	}, nil
+}
+func (w *WebHookHTTP) Redeliver(ctx context.Context, req *http.Request, vars map[string]string) (stypes.Responser, error) {
+	id := vars["id"]
+	deliveryID := vars["deliveryID"]
+	orgID := extractOrgIDHeader(req)
+	r, err := w.impl.Redeliver(orgID, id, deliveryID)
+	if err != nil {
+		logrus.Error(err)
+		return stypes.HTTPResponse{
+			Error: &stypes.ErrorResponse{
+				Code: toCode(errors.Cause(err)),
+				Msg:  err.Error(),
+			},
+			Compose: true,
+		}, nil
+	}
+	return stypes.HTTPResponse{
+		Content: r,
+		Compose: true,
+	}, nil
+}
 
 type ListHookEventsResponse = apistructs.WebhookListEventsResponseData
 
@@ -371,6 +410,8 @@ func (w *WebHookHTTP) GetHTTPEndPoints() []stypes.Endpoint {
 		{"/webhooks/{id}", http.MethodPut, check(w.EditHook)},
 		{"/webhooks/{id}/actions/ping", http.MethodPost, check(w.PingHook)},
 		{"/webhooks/{id}", http.MethodDelete, check(w.DeleteHook)},
+		{"/webhooks/{id}/deliveries", http.MethodGet, check(w.ListDeliveries)},
+		{"/webhooks/{id}/deliveries/{deliveryID}/actions/redeliver", http.MethodPost, check(w.Redeliver)},
 		{"/webhook_events", http.MethodGet, w.ListHookEvents},
 	}
 }
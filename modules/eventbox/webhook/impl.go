@@ -36,6 +36,8 @@ var InternalServerErr = errors.New("internal server error")
 
 type WebHookImpl struct {
 	js jsonstore.JsonStore
+	// deliveries stores the delivery log and the retry index
+	deliveries jsonstore.JsonStore
 }
 
 func NewWebHookImpl() (*WebHookImpl, error) {
@@ -43,7 +45,11 @@ func NewWebHookImpl() (*WebHookImpl, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &WebHookImpl{js: js}, nil
+	deliveries, err := jsonstore.New(jsonstore.UseEtcdStore())
+	if err != nil {
+		return nil, err
+	}
+	return &WebHookImpl{js: js, deliveries: deliveries}, nil
 }
 
 type Hook = apistructs.Hook
@@ -90,7 +96,7 @@ func (w *WebHookImpl) ListHooks(location HookLocation) (ListHooksResponse, error
 				errors.Wrap(InternalServerErr, fmt.Sprintf("list hooks fail: get hook: %v", mkHookEtcdName(id)))
 		}
 		if envSatisfy(location.Env, h.Env) {
-			r = append(r, h)
+			r = append(r, maskHookSecret(h))
 		}
 	}
 	return r, nil
@@ -103,7 +109,16 @@ func (w *WebHookImpl) InspectHook(realOrg, id string) (InspectHookResponse, erro
 	if realOrg != "" && !hookCheckOrg(h, realOrg) { // illegal org, return not found
 		return InspectHookResponse{}, fmt.Errorf("not found")
 	}
-	return InspectHookResponse(h), nil
+	return InspectHookResponse(maskHookSecret(h)), nil
+}
+
+// getHook 获取 hook 用于投递，secret 不做掩码
+func (w *WebHookImpl) getHook(id string) (Hook, error) {
+	h := Hook{}
+	if err := w.js.Get(context.Background(), mkHookEtcdName(id), &h); err != nil {
+		return Hook{}, err
+	}
+	return h, nil
 }
 
 func (w *WebHookImpl) CreateHook(realOrg string, h CreateHookRequest) (CreateHookResponse, error) {
@@ -197,6 +212,9 @@ func (w *WebHookImpl) EditHook(realOrg, id string, e EditHookRequest) (EditHookR
 	}
 
 	h.Active = e.Active
+	if e.Secret != "" {
+		h.Secret = e.Secret
+	}
 	h.UpdatedAt = nowTimestamp()
 
 	if err := w.js.Put(context.Background(), mkHookEtcdName(id), h); err != nil {
@@ -303,8 +321,20 @@ func genID() string {
 	return uuid.Generate()[0:12]
 }
 
+// maskHookSecret 查询接口只返回 secret 的末 4 位
+func maskHookSecret(h Hook) Hook {
+	if len(h.Secret) > 4 {
+		h.Secret = "******" + h.Secret[len(h.Secret)-4:]
+	} else if h.Secret != "" {
+		h.Secret = "******"
+	}
+	return h
+}
+
+var cst = time.FixedZone("CST", 8*3600)
+
 func nowTimestamp() string {
-	return time.Now().In(time.FixedZone("CST", 8*3600)).Format("2006-01-02 15:04:05")
+	return time.Now().In(cst).Format("2006-01-02 15:04:05")
 }
 
 func removeEvents(origin, remove []string) []string {
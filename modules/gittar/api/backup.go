@@ -51,6 +51,11 @@ func Backup(ctx *webcontext.Context) {
 		return
 	}
 	path := helper.OutPutArchive(ctx, branch, format)
+	if err := helper.EmbedLFSObjects(ctx, path); err != nil {
+		ctx.Abort(err)
+		helper.OutPutArchiveDelete(ctx, path)
+		return
+	}
 	f, err := os.Open(path)
 	if err != nil {
 		ctx.Abort(err)
@@ -0,0 +1,327 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/gittar/models"
+	"github.com/erda-project/erda/modules/gittar/pkg/lfs"
+	"github.com/erda-project/erda/modules/gittar/webcontext"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// LFSBatch 实现lfs batch api, 只支持basic transfer
+func LFSBatch(ctx *webcontext.Context) {
+	if models.LFSStorage() == nil {
+		lfsAbort(ctx, http.StatusNotImplemented, "git lfs is disabled")
+		return
+	}
+	var request lfs.BatchRequest
+	if err := json.NewDecoder(ctx.GetRequestBody()).Decode(&request); err != nil {
+		lfsAbort(ctx, http.StatusUnprocessableEntity, "request body parse failed")
+		return
+	}
+	if len(request.Transfers) > 0 && !strutil.Exist(request.Transfers, lfs.TransferBasic) {
+		lfsAbort(ctx, http.StatusUnprocessableEntity, "only basic transfer is supported")
+		return
+	}
+	if request.HashAlgo != "" && request.HashAlgo != "sha256" {
+		lfsAbort(ctx, http.StatusConflict, "only sha256 hash algorithm is supported")
+		return
+	}
+
+	switch request.Operation {
+	case lfs.OperationDownload:
+	case lfs.OperationUpload:
+		if err := checkLFSUpload(ctx); err != nil {
+			lfsAbort(ctx, http.StatusForbidden, err.Error())
+			return
+		}
+	default:
+		lfsAbort(ctx, http.StatusUnprocessableEntity, "invalid operation: "+request.Operation)
+		return
+	}
+
+	var oids []string
+	for _, object := range request.Objects {
+		if lfs.ValidOid(object.Oid) {
+			oids = append(oids, object.Oid)
+		}
+	}
+	exists, err := ctx.Service.GetLFSObjects(ctx.Repository.ID, oids)
+	if err != nil {
+		lfsAbort(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if request.Operation == lfs.OperationUpload {
+		var uploadSize int64
+		for _, object := range request.Objects {
+			if _, ok := exists[object.Oid]; !ok && object.Size > 0 {
+				uploadSize += object.Size
+			}
+		}
+		if !checkLFSQuota(ctx, uploadSize) {
+			return
+		}
+	}
+
+	baseURL := lfsBaseURL(ctx)
+	header := map[string]string{}
+	if authorization := ctx.GetHeader("Authorization"); authorization != "" {
+		header["Authorization"] = authorization
+	}
+	response := &lfs.BatchResponse{
+		Transfer: lfs.TransferBasic,
+		Objects:  []*lfs.ObjectResponse{},
+		HashAlgo: "sha256",
+	}
+	for _, object := range request.Objects {
+		item := &lfs.ObjectResponse{
+			Pointer:       *object,
+			Authenticated: true,
+		}
+		response.Objects = append(response.Objects, item)
+		if !lfs.ValidOid(object.Oid) || object.Size < 0 {
+			item.Error = &lfs.ObjectError{Code: http.StatusUnprocessableEntity, Message: "invalid object"}
+			continue
+		}
+		existObject, exist := exists[object.Oid]
+		href := baseURL + "/objects/" + object.Oid
+		if request.Operation == lfs.OperationDownload {
+			if !exist {
+				item.Error = &lfs.ObjectError{Code: http.StatusNotFound, Message: "object does not exist"}
+				continue
+			}
+			item.Size = existObject.Size
+			item.Actions = map[string]*lfs.Action{
+				lfs.OperationDownload: {Href: href, Header: header},
+			}
+			continue
+		}
+		// 已存在的对象不需要重复上传
+		if exist {
+			continue
+		}
+		item.Actions = map[string]*lfs.Action{
+			lfs.OperationUpload: {Href: href, Header: header},
+			"verify":            {Href: baseURL + "/verify", Header: header},
+		}
+	}
+	lfsJSON(ctx, http.StatusOK, response)
+}
+
+// LFSDownload basic transfer下载对象
+func LFSDownload(ctx *webcontext.Context) {
+	if models.LFSStorage() == nil {
+		lfsAbort(ctx, http.StatusNotImplemented, "git lfs is disabled")
+		return
+	}
+	oid := ctx.Param("oid")
+	if !lfs.ValidOid(oid) {
+		lfsAbort(ctx, http.StatusUnprocessableEntity, "invalid oid")
+		return
+	}
+	object, rd, err := ctx.Service.OpenLFSObject(ctx.Repository.ID, oid)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			lfsAbort(ctx, http.StatusNotFound, "object does not exist")
+			return
+		}
+		lfsAbort(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer rd.Close()
+	ctx.Header("Content-Type", "application/octet-stream")
+	ctx.Header("Content-Length", strconv.FormatInt(object.Size, 10))
+	ctx.Status(http.StatusOK)
+	if _, err := io.Copy(ctx.GetWriter(), rd); err != nil {
+		logrus.Errorf("failed to write lfs object %s: %v", oid, err)
+	}
+}
+
+// LFSUpload basic transfer上传对象, 校验sha256和大小后保存
+func LFSUpload(ctx *webcontext.Context) {
+	if models.LFSStorage() == nil {
+		lfsAbort(ctx, http.StatusNotImplemented, "git lfs is disabled")
+		return
+	}
+	oid := ctx.Param("oid")
+	if !lfs.ValidOid(oid) {
+		lfsAbort(ctx, http.StatusUnprocessableEntity, "invalid oid")
+		return
+	}
+	if err := checkLFSUpload(ctx); err != nil {
+		lfsAbort(ctx, http.StatusForbidden, err.Error())
+		return
+	}
+	if _, err := ctx.Service.GetLFSObject(ctx.Repository.ID, oid); err == nil {
+		ctx.Status(http.StatusOK)
+		return
+	}
+	if size := ctx.HttpRequest().ContentLength; size > 0 {
+		if !checkLFSQuota(ctx, size) {
+			return
+		}
+	}
+	remaining, err := ctx.Service.GetLFSRemainingQuota(ctx.Repository)
+	if err != nil {
+		lfsAbort(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// 最多读取剩余配额+1字节, 超出配额时不再继续写临时文件
+	var body io.Reader = ctx.GetRequestBody()
+	if remaining >= 0 {
+		body = io.LimitReader(body, remaining+1)
+	}
+
+	tmp, err := ioutil.TempFile("", "gittar-lfs-")
+	if err != nil {
+		lfsAbort(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer os.Remove(tmp.Name())
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), body)
+	tmp.Close()
+	if err != nil {
+		lfsAbort(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if remaining >= 0 && size > remaining {
+		lfsAbort(ctx, http.StatusInsufficientStorage, models.ERROR_LFS_QUOTA_EXCEEDED.Error())
+		return
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != oid {
+		lfsAbort(ctx, http.StatusUnprocessableEntity, "object hash mismatch")
+		return
+	}
+	if !checkLFSQuota(ctx, size) {
+		return
+	}
+	if err := ctx.Service.CreateLFSObject(ctx.Repository.ID, &lfs.Pointer{Oid: oid, Size: size}, tmp.Name()); err != nil {
+		lfsAbort(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+	ctx.Status(http.StatusOK)
+}
+
+// LFSVerify 上传完成后客户端确认对象已保存
+func LFSVerify(ctx *webcontext.Context) {
+	var pointer lfs.Pointer
+	if err := json.NewDecoder(ctx.GetRequestBody()).Decode(&pointer); err != nil {
+		lfsAbort(ctx, http.StatusUnprocessableEntity, "request body parse failed")
+		return
+	}
+	object, err := ctx.Service.GetLFSObject(ctx.Repository.ID, pointer.Oid)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			lfsAbort(ctx, http.StatusNotFound, "object does not exist")
+			return
+		}
+		lfsAbort(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if object.Size != pointer.Size {
+		lfsAbort(ctx, http.StatusUnprocessableEntity, "object size mismatch")
+		return
+	}
+	lfsJSON(ctx, http.StatusOK, &pointer)
+}
+
+// GetLFSSettings 获取仓库lfs配额和用量
+func GetLFSSettings(ctx *webcontext.Context) {
+	settings, err := ctx.Service.GetRepoLFSSettings(ctx.Repository)
+	if err != nil {
+		ctx.Abort(err)
+		return
+	}
+	ctx.Success(settings)
+}
+
+// UpdateLFSSettings 修改仓库lfs配额
+func UpdateLFSSettings(ctx *webcontext.Context) {
+	var request apistructs.RepoLFSSettings
+	if err := ctx.BindJSON(&request); err != nil {
+		ctx.AbortWithStatus(400, errors.New("request body parse failed"))
+		return
+	}
+	settings, err := ctx.Service.UpdateRepoLFSSettings(ctx.Repository, ctx.User, &request)
+	if err != nil {
+		ctx.Abort(err)
+		return
+	}
+	ctx.Success(settings)
+}
+
+func checkLFSUpload(ctx *webcontext.Context) error {
+	isLocked, err := ctx.Service.GetRepoLocked(ctx.Repository.ProjectId, ctx.Repository.ApplicationId)
+	if err != nil {
+		return err
+	}
+	if isLocked {
+		return ERROR_REPO_LOCKED
+	}
+	return ctx.CheckPermission(models.PermissionPush)
+}
+
+func checkLFSQuota(ctx *webcontext.Context, size int64) bool {
+	err := ctx.Service.CheckLFSQuota(ctx.Repository, size)
+	if err == nil {
+		return true
+	}
+	if err == models.ERROR_LFS_QUOTA_EXCEEDED {
+		lfsAbort(ctx, http.StatusInsufficientStorage, err.Error())
+	} else {
+		lfsAbort(ctx, http.StatusInternalServerError, err.Error())
+	}
+	return false
+}
+
+// lfsBaseURL 根据请求地址生成传输地址, 如 http://host/org-project/app.git/info/lfs
+func lfsBaseURL(ctx *webcontext.Context) string {
+	path := ctx.HttpRequest().URL.Path
+	if i := strings.Index(path, "/info/lfs"); i >= 0 {
+		path = path[:i]
+	}
+	return ctx.EchoContext.Scheme() + "://" + ctx.Host() + path + "/info/lfs"
+}
+
+func lfsJSON(ctx *webcontext.Context, code int, data interface{}) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		code = http.StatusInternalServerError
+		body, _ = json.Marshal(&lfs.ErrorResponse{Message: err.Error()})
+	}
+	ctx.EchoContext.Blob(code, lfs.MediaType, body)
+}
+
+func lfsAbort(ctx *webcontext.Context, code int, message string) {
+	lfsJSON(ctx, code, &lfs.ErrorResponse{Message: message})
+}
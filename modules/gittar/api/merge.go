@@ -37,8 +37,21 @@ func CheckMergeStatus(ctx *webcontext.Context) {
 
 	sourceBranch := ctx.Query("sourceBranch")
 	targetBranch := ctx.Query("targetBranch")
+	strategy := apistructs.MergeStrategy(ctx.Query("strategy"))
+	if strategy == "" {
+		settings, err := ctx.Service.GetRepoMergeSettings(ctx.Repository)
+		if err != nil {
+			ctx.Abort(err)
+			return
+		}
+		strategy = settings.DefaultStrategy
+	}
+	if !strategy.Valid() {
+		ctx.Abort(errors.New("invalid merge strategy: " + strategy.String()))
+		return
+	}
 
-	conflictInfo, err := ctx.Repository.GetMergeStatus(sourceBranch, targetBranch)
+	conflictInfo, err := ctx.Repository.GetMergeStatusWithStrategy(sourceBranch, targetBranch, strategy)
 
 	if err != nil {
 		ctx.Abort(err)
@@ -49,6 +62,31 @@ func CheckMergeStatus(ctx *webcontext.Context) {
 
 }
 
+// GetMergeSettings 获取仓库的mr合并设置
+func GetMergeSettings(ctx *webcontext.Context) {
+	settings, err := ctx.Service.GetRepoMergeSettings(ctx.Repository)
+	if err != nil {
+		ctx.Abort(err)
+		return
+	}
+	ctx.Success(settings)
+}
+
+// UpdateMergeSettings 更新仓库的默认合并方式和允许的合并方式
+func UpdateMergeSettings(ctx *webcontext.Context) {
+	var settings apistructs.RepoMergeSettings
+	if err := ctx.BindJSON(&settings); err != nil {
+		ctx.Abort(err)
+		return
+	}
+	result, err := ctx.Service.UpdateRepoMergeSettings(ctx.Repository, ctx.User, &settings)
+	if err != nil {
+		ctx.Abort(err)
+		return
+	}
+	ctx.Success(result)
+}
+
 func GetMergeTemplates(ctx *webcontext.Context) {
 	branch, err := ctx.Repository.GetDefaultBranch()
 	if err != nil {
@@ -351,6 +389,51 @@ func ReopenMR(ctx *webcontext.Context) {
 	ctx.Success(result)
 }
 
+// GetMRApproval 获取mr审批状态
+func GetMRApproval(ctx *webcontext.Context) {
+	id := ctx.ParamInt32("id", 0)
+	if id == 0 {
+		ctx.Abort(ERROR_ARG_ID)
+		return
+	}
+	result, err := ctx.Service.GetMRApprovalState(ctx.Repository, id)
+	if err != nil {
+		ctx.Abort(err)
+		return
+	}
+	ctx.Success(result)
+}
+
+// ApproveMR 审批通过mr
+func ApproveMR(ctx *webcontext.Context) {
+	id := ctx.ParamInt32("id", 0)
+	if id == 0 {
+		ctx.Abort(ERROR_ARG_ID)
+		return
+	}
+	result, err := ctx.Service.ApproveMR(ctx.Repository, ctx.User, id)
+	if err != nil {
+		ctx.Abort(err)
+		return
+	}
+	ctx.Success(result)
+}
+
+// RevokeMRApproval 撤销对mr的审批
+func RevokeMRApproval(ctx *webcontext.Context) {
+	id := ctx.ParamInt32("id", 0)
+	if id == 0 {
+		ctx.Abort(ERROR_ARG_ID)
+		return
+	}
+	result, err := ctx.Service.RevokeMRApproval(ctx.Repository, ctx.User, id)
+	if err != nil {
+		ctx.Abort(err)
+		return
+	}
+	ctx.Success(result)
+}
+
 func QueryNotes(ctx *webcontext.Context) {
 	id := ctx.ParamInt32("id", 0)
 	if id == 0 {
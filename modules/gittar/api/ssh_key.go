@@ -0,0 +1,61 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/gittar/webcontext"
+)
+
+// ListSSHKeys 获取当前用户的ssh公钥
+func ListSSHKeys(ctx *webcontext.Context) {
+	keys, err := ctx.Service.ListSSHKeys(ctx.User)
+	if err != nil {
+		ctx.Abort(err)
+		return
+	}
+	ctx.Success(keys)
+}
+
+// AddSSHKey 添加ssh公钥
+func AddSSHKey(ctx *webcontext.Context) {
+	var request apistructs.GittarCreateSSHKeyRequest
+	if err := ctx.BindJSON(&request); err != nil {
+		ctx.AbortWithStatus(400, errors.New("request body parse failed"))
+		return
+	}
+	key, err := ctx.Service.AddSSHKey(ctx.User, &request)
+	if err != nil {
+		ctx.Abort(err)
+		return
+	}
+	ctx.Success(key)
+}
+
+// DeleteSSHKey 删除ssh公钥
+func DeleteSSHKey(ctx *webcontext.Context) {
+	id := ctx.ParamInt32("id", 0)
+	if id == 0 {
+		ctx.Abort(ERROR_ARG_ID)
+		return
+	}
+	if err := ctx.Service.DeleteSSHKey(ctx.User, int64(id)); err != nil {
+		ctx.Abort(err)
+		return
+	}
+	ctx.Success("")
+}
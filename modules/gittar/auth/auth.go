@@ -188,6 +188,27 @@ func AuthenticateV3(c *webcontext.Context) {
 	doAuth(c, repo, repoName)
 }
 
+// AuthenticateUser 只校验用户身份, 用于和仓库无关的用户接口, 如ssh公钥管理
+func AuthenticateUser(c *webcontext.Context) {
+	userIdStr := c.GetHeader(httputil.UserHeader)
+	if userIdStr == "" {
+		c.AbortWithStatus(http.StatusUnauthorized, NO_AUTH_ERROR)
+		return
+	}
+	userInfoDto, err := uc.FindUserById(userIdStr)
+	if err != nil {
+		c.AbortWithStatus(500, err)
+		return
+	}
+	c.Set("user", &models.User{
+		Name:     userInfoDto.Username,
+		NickName: userInfoDto.NickName,
+		Email:    userInfoDto.Email,
+		Id:       userIdStr,
+	})
+	c.Next()
+}
+
 func doAuth(c *webcontext.Context, repo *models.Repo, repoName string) {
 	// Git Protocol version v2
 	version := c.GetHeader("Git-Protocol")
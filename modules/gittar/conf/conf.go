@@ -49,6 +49,24 @@ type Conf struct {
 	GitGCMaxNum              int    `env:"GIT_GC_MAX_NUM" default:"1"`
 	GitGCCronExpression      string `env:"GIT_GC_CRON_EXPRESSION" default:"0 0 1 * * ?"`
 
+	// lfs config
+	LFSEnabled bool `env:"GITTAR_LFS_ENABLED" default:"true"`
+	// LFSStorage local 或 oss, oss同时支持minio
+	LFSStorage      string `env:"GITTAR_LFS_STORAGE" default:"local"`
+	LFSLocalPath    string `env:"GITTAR_LFS_LOCAL_PATH" default:"/repository/.lfs"`
+	LFSOssEndpoint  string `env:"GITTAR_LFS_OSS_ENDPOINT"`
+	LFSOssAccessKey string `env:"GITTAR_LFS_OSS_ACCESS_KEY"`
+	LFSOssSecretKey string `env:"GITTAR_LFS_OSS_SECRET_KEY"`
+	LFSOssBucket    string `env:"GITTAR_LFS_OSS_BUCKET"`
+	LFSOssPrefix    string `env:"GITTAR_LFS_OSS_PREFIX" default:"gittar-lfs"`
+	// LFSRepoQuota 每个仓库默认的lfs存储配额, 单位Byte, 小于等于0表示不限制
+	LFSRepoQuota int64 `env:"GITTAR_LFS_REPO_QUOTA" default:"10737418240"`
+
+	// ssh config
+	SSHEnabled     bool   `env:"GITTAR_SSH_ENABLED" default:"false"`
+	SSHListenPort  string `env:"GITTAR_SSH_PORT" default:"2222"`
+	SSHHostKeyPath string `env:"GITTAR_SSH_HOST_KEY" default:"/repository/.ssh/gittar_host_key"`
+
 	// ory/kratos config
 	OryEnabled             bool   `default:"false" env:"ORY_ENABLED"`
 	OryKratosAddr          string `default:"kratos-public" env:"ORY_KRATOS_ADDR"`
@@ -172,6 +190,66 @@ func GitGCCronExpression() string {
 	return cfg.GitGCCronExpression
 }
 
+// LFSEnabled 是否开启git lfs
+func LFSEnabled() bool {
+	return cfg.LFSEnabled
+}
+
+// LFSStorage lfs对象存储类型, local或oss
+func LFSStorage() string {
+	return cfg.LFSStorage
+}
+
+// LFSLocalPath lfs对象本地存储目录
+func LFSLocalPath() string {
+	return cfg.LFSLocalPath
+}
+
+// LFSOssEndpoint lfs对象存储oss/minio地址
+func LFSOssEndpoint() string {
+	return cfg.LFSOssEndpoint
+}
+
+// LFSOssAccessKey lfs对象存储oss/minio access key
+func LFSOssAccessKey() string {
+	return cfg.LFSOssAccessKey
+}
+
+// LFSOssSecretKey lfs对象存储oss/minio secret key
+func LFSOssSecretKey() string {
+	return cfg.LFSOssSecretKey
+}
+
+// LFSOssBucket lfs对象存储bucket
+func LFSOssBucket() string {
+	return cfg.LFSOssBucket
+}
+
+// LFSOssPrefix lfs对象在bucket中的路径前缀
+func LFSOssPrefix() string {
+	return cfg.LFSOssPrefix
+}
+
+// LFSRepoQuota 仓库默认的lfs存储配额
+func LFSRepoQuota() int64 {
+	return cfg.LFSRepoQuota
+}
+
+// SSHEnabled 是否开启ssh协议
+func SSHEnabled() bool {
+	return cfg.SSHEnabled
+}
+
+// SSHListenPort ssh监听端口
+func SSHListenPort() string {
+	return cfg.SSHListenPort
+}
+
+// SSHHostKeyPath ssh host key路径, 文件不存在时自动生成
+func SSHHostKeyPath() string {
+	return cfg.SSHHostKeyPath
+}
+
 func OryEnabled() bool {
 	return cfg.OryEnabled
 }
@@ -162,22 +162,7 @@ func RunProcess(service string, c *webcontext.Context) {
 			return
 		}
 		logrus.Infof("push header:" + string(header))
-		re := regexp.MustCompile(
-			`(?mi)(?P<before>[0-9a-fA-F]{40}) (?P<after>[0-9a-fA-F]{40}) (?P<ref>refs\/(heads|tags)\/.*?)\0`,
-		)
-
-		for _, matches := range re.FindAllSubmatch(header, -1) {
-			pushEvent := &models.PayloadPushEvent{
-				Before:            string(matches[1]),
-				After:             string(matches[2]),
-				Ref:               string(bytes.Trim(matches[3], "\x00")),
-				IsTag:             string(matches[4]) == "tags",
-				Pusher:            c.MustGet("user").(*models.User),
-				TotalCommitsCount: 0,
-			}
-			pushEvent.IsDelete = pushEvent.After == gitmodule.INIT_COMMIT_ID
-			pushEvents = append(pushEvents, pushEvent)
-		}
+		pushEvents = ParsePushEvents(header, c.MustGet("user").(*models.User))
 
 		repository := c.MustGet("repository").(*gitmodule.Repository)
 		if preReceiveHook(pushEvents, c) {
@@ -200,6 +185,28 @@ func RunProcess(service string, c *webcontext.Context) {
 	}
 }
 
+var pushCommandRegexp = regexp.MustCompile(
+	`(?mi)(?P<before>[0-9a-fA-F]{40}) (?P<after>[0-9a-fA-F]{40}) (?P<ref>refs\/(heads|tags)\/.*?)\0`,
+)
+
+// ParsePushEvents 从send-pack的命令头中解析推送的分支和tag
+func ParsePushEvents(header []byte, pusher *models.User) []*models.PayloadPushEvent {
+	var pushEvents []*models.PayloadPushEvent
+	for _, matches := range pushCommandRegexp.FindAllSubmatch(header, -1) {
+		pushEvent := &models.PayloadPushEvent{
+			Before:            string(matches[1]),
+			After:             string(matches[2]),
+			Ref:               string(bytes.Trim(matches[3], "\x00")),
+			IsTag:             string(matches[4]) == "tags",
+			Pusher:            pusher,
+			TotalCommitsCount: 0,
+		}
+		pushEvent.IsDelete = pushEvent.After == gitmodule.INIT_COMMIT_ID
+		pushEvents = append(pushEvents, pushEvent)
+	}
+	return pushEvents
+}
+
 func RunArchive(c *webcontext.Context, ref string, format string) {
 	c.EchoContext.Response().Header().Add("Content-Disposition", "attachment; filename="+
 		c.Repository.ProjectName+"-"+
@@ -25,6 +25,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
 	"github.com/erda-project/erda/modules/gittar/event"
 	"github.com/erda-project/erda/modules/gittar/models"
 	"github.com/erda-project/erda/modules/gittar/pkg/gitmodule"
@@ -33,53 +34,59 @@ import (
 
 // protect branch
 func preReceiveHook(pushEvents []*models.PayloadPushEvent, c *webcontext.Context) bool {
+	if ref, err := CheckPushPermission(pushEvents, c.Repository, c.User, c.Service); err != nil {
+		c.Status(200)
+		c.GetWriter().Write(NewReportStatus(
+			"unpack ok",
+			"ng "+ref,
+			err.Error()))
+		return false
+	}
+	return true
+}
+
+// CheckPushPermission 检查用户推送分支和tag的权限, 返回无权限的ref
+func CheckPushPermission(pushEvents []*models.PayloadPushEvent, repository *gitmodule.Repository, user *models.User, svc *models.Service) (string, error) {
+	checkPermission := func(permission models.Permission) error {
+		return svc.CheckPermission(repository, user, permission, nil)
+	}
 	for _, pushEvent := range pushEvents {
 		var err error
 		if pushEvent.IsTag {
 			//tag校验
 			if pushEvent.IsDelete {
-				err = c.CheckPermission(models.PermissionDeleteTAG)
+				err = checkPermission(models.PermissionDeleteTAG)
 			} else {
-				err = c.CheckPermission(models.PermissionCreateTAG)
+				err = checkPermission(models.PermissionCreateTAG)
 			}
 		} else {
 			//分支校验
 			if pushEvent.IsDelete {
-				err = c.CheckPermission(models.PermissionDeleteBranch)
+				err = checkPermission(models.PermissionDeleteBranch)
 			} else {
-				err = c.CheckPermission(models.PermissionPush)
+				err = checkPermission(models.PermissionPush)
 			}
 		}
 		if err != nil {
-			c.Status(200)
-			c.GetWriter().Write(NewReportStatus(
-				"unpack ok",
-				"ng "+pushEvent.Ref,
-				err.Error()))
-			return false
+			return pushEvent.Ref, err
 		}
 
 		if !pushEvent.IsTag {
 			branch := strings.TrimPrefix(pushEvent.Ref, gitmodule.BRANCH_PREFIX)
 			//保护分支权限
-			if c.Repository.IsProtectBranch(branch) {
+			if repository.IsProtectBranch(branch) {
 				//是否可以推送保护分支
-				if err := c.CheckPermission(models.PermissionPushProtectBranch); err != nil {
-					c.Status(200)
-					c.GetWriter().Write(NewReportStatus(
-						"unpack ok",
-						"ng "+pushEvent.Ref,
-						err.Error()))
-					return false
+				if err := checkPermission(models.PermissionPushProtectBranch); err != nil {
+					return pushEvent.Ref, err
 				}
 
 				//是否可以覆盖推送保护分支
 				isForcePush := false
 				//有可能是未push上来的新commit,不做异常判断，只根据是否为空判断
-				beforeCommit, _ := c.Repository.GetCommit(pushEvent.Before)
-				lastCommit, _ := c.Repository.GetCommit(pushEvent.After)
+				beforeCommit, _ := repository.GetCommit(pushEvent.Before)
+				lastCommit, _ := repository.GetCommit(pushEvent.After)
 				if beforeCommit != nil && lastCommit != nil {
-					baseCommit, err := c.Repository.GetMergeBase(beforeCommit, lastCommit)
+					baseCommit, err := repository.GetMergeBase(beforeCommit, lastCommit)
 					if err == nil {
 						if baseCommit != nil && baseCommit.ID == lastCommit.ID {
 							isForcePush = true
@@ -89,7 +96,7 @@ func preReceiveHook(pushEvents []*models.PayloadPushEvent, c *webcontext.Context
 					//TODO 有一些情况遗漏
 					//全新推送
 					if pushEvent.Before == gitmodule.INIT_COMMIT_ID {
-						_, err := c.Repository.GetBranchCommit(branch)
+						_, err := repository.GetBranchCommit(branch)
 						//如果master已经有commit，判断全新覆盖force push
 						if err == nil {
 							isForcePush = true
@@ -98,30 +105,28 @@ func preReceiveHook(pushEvents []*models.PayloadPushEvent, c *webcontext.Context
 				}
 
 				if isForcePush {
-					if err := c.CheckPermission(models.PermissionPushProtectBranchForce); err != nil {
-						c.Status(200)
-						c.GetWriter().Write(NewReportStatus(
-							"unpack ok",
-							"ng "+pushEvent.Ref,
-							err.Error()))
-						return false
+					if err := checkPermission(models.PermissionPushProtectBranchForce); err != nil {
+						return pushEvent.Ref, err
 					}
 				}
 			}
 		}
 
 	}
-	return true
+	return "", nil
 }
 
 // trigger event
 func PostReceiveHook(pushEvents []*models.PayloadPushEvent, c *webcontext.Context) {
-	pusher := c.User
-	repository := c.Repository
+	PostReceive(pushEvents, c.Repository, c.User, c.Service, c.Bundle)
+}
+
+// PostReceive 推送成功后更新仓库大小, 触发事件和webhook, 同步mr
+func PostReceive(pushEvents []*models.PayloadPushEvent, repository *gitmodule.Repository, pusher *models.User, svc *models.Service, bdl *bundle.Bundle) {
 
 	size, err := repository.CalcRepoSize()
 	if err == nil {
-		c.Service.UpdateRepoSizeCache(repository.ID, size)
+		svc.UpdateRepoSizeCache(repository.ID, size)
 	}
 
 	repo, err := git.OpenRepository(repository.DiskPath())
@@ -168,7 +173,7 @@ func PostReceiveHook(pushEvents []*models.PayloadPushEvent, c *webcontext.Contex
 		logrus.Infof("%v", pushEvent)
 
 		//trigger eventbox event
-		err := c.Bundle.CreateEvent(&apistructs.EventCreateRequest{
+		err := bdl.CreateEvent(&apistructs.EventCreateRequest{
 			EventHeader: apistructs.EventHeader{
 				ApplicationID: strconv.FormatInt(repository.ApplicationId, 10),
 				ProjectID:     strconv.FormatInt(repository.ProjectId, 10),
@@ -184,13 +189,13 @@ func PostReceiveHook(pushEvents []*models.PayloadPushEvent, c *webcontext.Contex
 		}
 
 		//project system hook
-		projectHooks, err := c.Service.GetProjectHooksByEvent(repository, models.HOOK_EVENT_PUSH, true)
+		projectHooks, err := svc.GetProjectHooksByEvent(repository, models.HOOK_EVENT_PUSH, true)
 		if err != nil {
 			logrus.Error("error get project hooks")
 			continue
 		}
 
-		systemHooks, err := c.Service.GetSystemHooksByEvent(models.HOOK_EVENT_PUSH, true)
+		systemHooks, err := svc.GetSystemHooksByEvent(models.HOOK_EVENT_PUSH, true)
 		if err != nil {
 			logrus.Error("error get system hooks")
 			continue
@@ -213,7 +218,7 @@ func PostReceiveHook(pushEvents []*models.PayloadPushEvent, c *webcontext.Contex
 					Url:            hook.Url,
 					Event:          models.HOOK_EVENT_PUSH,
 				}
-				err := c.Service.CreateHookTask(task)
+				err := svc.CreateHookTask(task)
 				if err != nil {
 					logrus.Errorf("create hookTask error %v %v", err, task)
 					continue
@@ -225,7 +230,7 @@ func PostReceiveHook(pushEvents []*models.PayloadPushEvent, c *webcontext.Contex
 		//更新mr表
 		if !pushEvent.IsTag {
 			branch := strings.TrimPrefix(pushEvent.Ref, gitmodule.BRANCH_PREFIX)
-			err := c.Service.SyncMergeRequest(repository, branch, pushEvent.After, pusher.Id, flag)
+			err := svc.SyncMergeRequest(repository, branch, pushEvent.After, pusher.Id, flag)
 			if err != nil {
 				logrus.Errorf("error sync merge request repo:%s ref:%s err:%s",
 					repository.Path, pushEvent.Ref, err)
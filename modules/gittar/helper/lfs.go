@@ -0,0 +1,105 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/erda-project/erda/modules/gittar/models"
+	"github.com/erda-project/erda/modules/gittar/pkg/lfs"
+	"github.com/erda-project/erda/modules/gittar/webcontext"
+)
+
+// EmbedLFSObjects 将zip备份文件中的lfs pointer文件替换为对应的lfs对象
+func EmbedLFSObjects(c *webcontext.Context, archive string) error {
+	if models.LFSStorage() == nil {
+		return nil
+	}
+	_, count, err := c.Service.GetLFSUsage(c.Repository.ID)
+	if err != nil || count == 0 {
+		return err
+	}
+
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	tmp := archive + ".lfs"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	writer := zip.NewWriter(out)
+	for _, file := range reader.File {
+		if err := copyZipEntry(c, writer, file); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, archive)
+}
+
+func copyZipEntry(c *webcontext.Context, writer *zip.Writer, file *zip.File) error {
+	rd, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rd.Close()
+
+	var content io.Reader = rd
+	if !file.FileInfo().IsDir() && file.UncompressedSize64 <= lfs.MaxPointerSize {
+		data, err := ioutil.ReadAll(rd)
+		if err != nil {
+			return err
+		}
+		if pointer, ok := lfs.ParsePointer(data); ok {
+			_, object, err := c.Service.OpenLFSObject(c.Repository.ID, pointer.Oid)
+			if err == nil {
+				defer object.Close()
+				header := file.FileHeader
+				header.Method = zip.Deflate
+				w, err := writer.CreateHeader(&header)
+				if err != nil {
+					return err
+				}
+				_, err = io.Copy(w, object)
+				return err
+			}
+		}
+		content = bytes.NewReader(data)
+	}
+
+	header := file.FileHeader
+	w, err := writer.CreateHeader(&header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, content)
+	return err
+}
@@ -15,6 +15,7 @@
 package gittar
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/labstack/echo"
@@ -29,9 +30,12 @@ import (
 	"github.com/erda-project/erda/modules/gittar/models"
 	"github.com/erda-project/erda/modules/gittar/pkg/gc"
 	"github.com/erda-project/erda/modules/gittar/pkg/gitmodule"
+	"github.com/erda-project/erda/modules/gittar/pkg/lfs"
 	"github.com/erda-project/erda/modules/gittar/profiling"
+	"github.com/erda-project/erda/modules/gittar/sshd"
 	"github.com/erda-project/erda/modules/gittar/uc"
 	"github.com/erda-project/erda/modules/gittar/webcontext"
+	"github.com/erda-project/erda/pkg/cloudstorage"
 	"github.com/erda-project/erda/pkg/discover"
 	"github.com/erda-project/erda/pkg/ucauth"
 	// "terminus.io/dice/telemetry/promxp"
@@ -76,6 +80,14 @@ func (p *provider) Initialize() error {
 	}
 	uc.InitializeUcClient(dbClient.DBEngine.DB)
 
+	if conf.LFSEnabled() {
+		lfsStorage, err := newLFSStorage()
+		if err != nil {
+			panic(err)
+		}
+		models.WithLFSStorage(lfsStorage)
+	}
+
 	webcontext.WithDB(dbClient)
 	webcontext.WithBundle(diceBundle)
 	webcontext.WithUCAuth(ucUserAuth)
@@ -100,6 +112,13 @@ func (p *provider) Initialize() error {
 		apiGroup.GET("/health", webcontext.WrapHandler(api.Health))
 	}
 
+	userGroup := e.Group("/_user", webcontext.WrapMiddlewareHandler(auth.AuthenticateUser))
+	{
+		userGroup.GET("/ssh-keys", webcontext.WrapHandler(api.ListSSHKeys))
+		userGroup.POST("/ssh-keys", webcontext.WrapHandler(api.AddSSHKey))
+		userGroup.DELETE("/ssh-keys/:id", webcontext.WrapHandler(api.DeleteSSHKey))
+	}
+
 	debugGroup := e.Group("/_debug")
 	profiling.WrapGroup(debugGroup)
 
@@ -145,9 +164,36 @@ func (p *provider) Initialize() error {
 	// start hook task consumer
 	models.Init(dbClient)
 
+	if conf.SSHEnabled() {
+		sshServer, err := sshd.New(dbClient, diceBundle, conf.SSHHostKeyPath())
+		if err != nil {
+			panic(err)
+		}
+		go func() {
+			if err := sshServer.ListenAndServe(":" + conf.SSHListenPort()); err != nil {
+				logrus.Errorf("gittar ssh server stopped: %v", err)
+			}
+		}()
+	}
+
 	return e.Start(":" + conf.ListenPort())
 }
 
+func newLFSStorage() (lfs.Storage, error) {
+	switch conf.LFSStorage() {
+	case "local":
+		return lfs.NewLocalStorage(conf.LFSLocalPath()), nil
+	case "oss":
+		client, err := cloudstorage.New(conf.LFSOssEndpoint(), conf.LFSOssAccessKey(), conf.LFSOssSecretKey())
+		if err != nil {
+			return nil, err
+		}
+		return lfs.NewCloudStorage(client, conf.LFSOssBucket(), conf.LFSOssPrefix()), nil
+	default:
+		return nil, fmt.Errorf("invalid lfs storage: %s", conf.LFSStorage())
+	}
+}
+
 func addApiRoutes(g *echo.Group) {
 	g.DELETE("", webcontext.WrapHandler(api.DeleteRepo))
 
@@ -162,6 +208,12 @@ func addApiRoutes(g *echo.Group) {
 	// implements the service_rpc function
 	g.POST("/git-:service", webcontext.WrapHandler(api.ServiceRepoRPC))
 
+	// git lfs batch api and basic transfer
+	g.POST("/info/lfs/objects/batch", webcontext.WrapHandler(api.LFSBatch))
+	g.GET("/info/lfs/objects/:oid", webcontext.WrapHandler(api.LFSDownload))
+	g.PUT("/info/lfs/objects/:oid", webcontext.WrapHandler(api.LFSUpload))
+	g.POST("/info/lfs/verify", webcontext.WrapHandler(api.LFSVerify))
+
 	g.GET("/commits/*", webcontext.WrapHandlerWithRepoCheck(api.GetRepoCommits))
 	g.POST("/commits", webcontext.WrapHandler(api.CreateCommit))
 
@@ -191,6 +243,10 @@ func addApiRoutes(g *echo.Group) {
 	//merge request
 	g.GET("/merge-stats", webcontext.WrapHandler(api.CheckMergeStatus))
 	g.GET("/merge-templates", webcontext.WrapHandler(api.GetMergeTemplates))
+	g.GET("/lfs-settings", webcontext.WrapHandler(api.GetLFSSettings))
+	g.PUT("/lfs-settings", webcontext.WrapHandler(api.UpdateLFSSettings))
+	g.GET("/merge-settings", webcontext.WrapHandler(api.GetMergeSettings))
+	g.PUT("/merge-settings", webcontext.WrapHandler(api.UpdateMergeSettings))
 	g.GET("/merge-requests/:id", webcontext.WrapHandler(api.GetMergeRequestDetail))
 	g.GET("/merge-requests", webcontext.WrapHandler(api.GetMergeRequests))
 	g.POST("/merge-requests", webcontext.WrapHandler(api.CreateMergeRequest))
@@ -198,6 +254,9 @@ func addApiRoutes(g *echo.Group) {
 	g.POST("/merge-requests/:id/merge", webcontext.WrapHandler(api.Merge))
 	g.POST("/merge-requests/:id/close", webcontext.WrapHandler(api.CloseMR))
 	g.POST("/merge-requests/:id/reopen", webcontext.WrapHandler(api.ReopenMR))
+	g.GET("/merge-requests/:id/approvals", webcontext.WrapHandler(api.GetMRApproval))
+	g.POST("/merge-requests/:id/approve", webcontext.WrapHandler(api.ApproveMR))
+	g.POST("/merge-requests/:id/revoke-approval", webcontext.WrapHandler(api.RevokeMRApproval))
 	g.GET("/merge-requests/:id/notes", webcontext.WrapHandler(api.QueryNotes))
 	g.POST("/merge-requests/:id/notes", webcontext.WrapHandler(api.CreateNotes))
 	g.POST("/check-runs", webcontext.WrapHandler(api.CreateCheckRun))
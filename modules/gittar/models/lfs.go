@@ -0,0 +1,237 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/gittar/conf"
+	"github.com/erda-project/erda/modules/gittar/pkg/gitmodule"
+	"github.com/erda-project/erda/modules/gittar/pkg/lfs"
+)
+
+var ERROR_LFS_QUOTA_EXCEEDED = errors.New("lfs storage quota exceeded")
+var ERROR_LFS_QUOTA_NEED_ADMIN = errors.New("only system administrators can set unlimited lfs quota or quota above the system default")
+
+var lfsStorage lfs.Storage
+
+func WithLFSStorage(storage lfs.Storage) {
+	lfsStorage = storage
+}
+
+func LFSStorage() lfs.Storage {
+	return lfsStorage
+}
+
+// LFSObject 仓库中已上传的lfs对象
+type LFSObject struct {
+	ID        int64
+	RepoID    int64  `gorm:"unique_index:uk_repo_oid"`
+	Oid       string `gorm:"unique_index:uk_repo_oid"`
+	Size      int64
+	CreatedAt time.Time
+}
+
+// EffectiveLFSQuota 仓库未设置配额时使用系统默认配额, 小于等于0表示不限制
+func (r *Repo) EffectiveLFSQuota() int64 {
+	if r.LFSQuota != 0 {
+		return r.LFSQuota
+	}
+	return conf.LFSRepoQuota()
+}
+
+// exceedsDefaultLFSQuota 配额是否比系统默认配额更宽松
+func exceedsDefaultLFSQuota(quota int64) bool {
+	if quota == 0 {
+		return false
+	}
+	defaultQuota := conf.LFSRepoQuota()
+	if defaultQuota <= 0 {
+		// 系统默认不限制, 设置任何配额都更严格
+		return false
+	}
+	return quota < 0 || quota > defaultQuota
+}
+
+// GetLFSObjects 查询仓库中已存在的lfs对象, 返回oid到对象的映射
+func (svc *Service) GetLFSObjects(repoID int64, oids []string) (map[string]*LFSObject, error) {
+	result := map[string]*LFSObject{}
+	if len(oids) == 0 {
+		return result, nil
+	}
+	var objects []LFSObject
+	err := svc.db.Where("repo_id = ? and oid in (?)", repoID, oids).Find(&objects).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range objects {
+		result[objects[i].Oid] = &objects[i]
+	}
+	return result, nil
+}
+
+func (svc *Service) GetLFSObject(repoID int64, oid string) (*LFSObject, error) {
+	var object LFSObject
+	err := svc.db.Where("repo_id = ? and oid = ?", repoID, oid).First(&object).Error
+	if err != nil {
+		return nil, err
+	}
+	return &object, nil
+}
+
+// CreateLFSObject 保存已校验过oid和大小的文件
+func (svc *Service) CreateLFSObject(repoID int64, pointer *lfs.Pointer, file string) error {
+	_, err := svc.GetLFSObject(repoID, pointer.Oid)
+	if err == nil {
+		return nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return err
+	}
+	if err := lfsStorage.Put(repoID, pointer.Oid, file); err != nil {
+		return err
+	}
+	return svc.db.Create(&LFSObject{
+		RepoID: repoID,
+		Oid:    pointer.Oid,
+		Size:   pointer.Size,
+	}).Error
+}
+
+// GetLFSUsage 仓库lfs对象的总大小和数量
+func (svc *Service) GetLFSUsage(repoID int64) (int64, int64, error) {
+	var size, count int64
+	err := svc.db.Model(&LFSObject{}).Where("repo_id = ?", repoID).
+		Select("COALESCE(SUM(size), 0), COUNT(*)").Row().Scan(&size, &count)
+	if err != nil {
+		return 0, 0, err
+	}
+	return size, count, nil
+}
+
+// GetLFSRemainingQuota 仓库剩余的lfs配额, 不限制时返回-1
+func (svc *Service) GetLFSRemainingQuota(repo *gitmodule.Repository) (int64, error) {
+	repoModel, err := svc.GetRepoById(repo.ID)
+	if err != nil {
+		return 0, err
+	}
+	quota := repoModel.EffectiveLFSQuota()
+	if quota <= 0 {
+		return -1, nil
+	}
+	used, _, err := svc.GetLFSUsage(repo.ID)
+	if err != nil {
+		return 0, err
+	}
+	if used >= quota {
+		return 0, nil
+	}
+	return quota - used, nil
+}
+
+// CheckLFSQuota 检查新增size大小的对象后是否超过仓库配额
+func (svc *Service) CheckLFSQuota(repo *gitmodule.Repository, size int64) error {
+	remaining, err := svc.GetLFSRemainingQuota(repo)
+	if err != nil {
+		return err
+	}
+	if remaining >= 0 && size > remaining {
+		return ERROR_LFS_QUOTA_EXCEEDED
+	}
+	return nil
+}
+
+func (svc *Service) GetRepoLFSSettings(repo *gitmodule.Repository) (*apistructs.RepoLFSSettings, error) {
+	repoModel, err := svc.GetRepoById(repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	size, count, err := svc.GetLFSUsage(repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &apistructs.RepoLFSSettings{
+		Quota:          repoModel.LFSQuota,
+		EffectiveQuota: repoModel.EffectiveLFSQuota(),
+		Size:           size,
+		Objects:        count,
+	}, nil
+}
+
+// UpdateRepoLFSSettings 修改仓库lfs配额
+// 0表示使用系统默认配额, 不限制(小于0)或超过系统默认配额只允许系统管理员设置
+func (svc *Service) UpdateRepoLFSSettings(repo *gitmodule.Repository, user *User, settings *apistructs.RepoLFSSettings) (*apistructs.RepoLFSSettings, error) {
+	if err := svc.CheckPermission(repo, user, PermissionRepoSetting, nil); err != nil {
+		return nil, err
+	}
+	if exceedsDefaultLFSQuota(settings.Quota) {
+		if err := svc.CheckSysAdminPermission(user); err != nil {
+			return nil, ERROR_LFS_QUOTA_NEED_ADMIN
+		}
+	}
+	err := svc.db.Table("dice_repos").Where("id = ?", repo.ID).Update("lfs_quota", settings.Quota).Error
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetRepoLFSSettings(repo)
+}
+
+// RemoveLFSObjects 删除仓库的所有lfs对象
+func (svc *Service) RemoveLFSObjects(repoID int64) error {
+	var objects []LFSObject
+	err := svc.db.Where("repo_id = ?", repoID).Find(&objects).Error
+	if err != nil {
+		return err
+	}
+	if len(objects) == 0 {
+		return nil
+	}
+	err = svc.db.Where("repo_id = ?", repoID).Delete(&LFSObject{}).Error
+	if err != nil {
+		return err
+	}
+	if lfsStorage == nil {
+		logrus.Warnf("lfs is disabled, %d lfs objects of repo %d are not deleted from storage", len(objects), repoID)
+		return nil
+	}
+	go func() {
+		for _, object := range objects {
+			if err := lfsStorage.Delete(repoID, object.Oid); err != nil {
+				logrus.Errorf("failed to delete lfs object %s: %v", lfs.ObjectPath(repoID, object.Oid), err)
+			}
+		}
+	}()
+	return nil
+}
+
+// OpenLFSObject 读取仓库中的lfs对象
+func (svc *Service) OpenLFSObject(repoID int64, oid string) (*LFSObject, io.ReadCloser, error) {
+	object, err := svc.GetLFSObject(repoID, oid)
+	if err != nil {
+		return nil, nil, err
+	}
+	rd, err := lfsStorage.Open(repoID, oid)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open lfs object %s: %v", oid, err)
+	}
+	return object, rd, nil
+}
@@ -0,0 +1,308 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/gittar/pkg/codeowners"
+	"github.com/erda-project/erda/modules/gittar/pkg/gitmodule"
+	"github.com/erda-project/erda/modules/gittar/uc"
+)
+
+// MergeApproval mr审批记录
+type MergeApproval struct {
+	ID        int64
+	RepoID    int64 `gorm:"index:idx_repo_id"`
+	MergeID   int64 // MergeRequest.ID
+	UserID    string
+	UserName  string
+	UserEmail string
+	// CommitSha 审批时源分支的提交, 源分支有新的提交后审批失效
+	CommitSha string
+	CreatedAt time.Time
+}
+
+func (svc *Service) ApproveMR(repo *gitmodule.Repository, user *User, mergeId int) (*apistructs.MergeRequestApprovalState, error) {
+	var mergeRequest MergeRequest
+	err := svc.db.Where("repo_id = ? and repo_merge_id=?", repo.ID, mergeId).First(&mergeRequest).Error
+	if err != nil {
+		return nil, err
+	}
+	if mergeRequest.State != MERGE_REQUEST_OPEN {
+		return nil, errors.New(mergeRequest.State + " 状态无法审批")
+	}
+	if mergeRequest.AuthorId == user.Id {
+		return nil, errors.New("can not approve your own merge request")
+	}
+	err = svc.CheckPermission(repo, user, PermissionApproveMR, getMrUserRole(mergeRequest, user.Id))
+	if err != nil {
+		return nil, err
+	}
+
+	sourceCommit, err := repo.GetBranchCommit(mergeRequest.SourceBranch)
+	if err != nil {
+		return nil, err
+	}
+	var approval MergeApproval
+	err = svc.db.Where("merge_id = ? and user_id = ?", mergeRequest.ID, user.Id).First(&approval).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return nil, err
+	}
+	if err != nil {
+		err = svc.db.Create(&MergeApproval{
+			RepoID:    repo.ID,
+			MergeID:   mergeRequest.ID,
+			UserID:    user.Id,
+			UserName:  user.Name,
+			UserEmail: user.Email,
+			CommitSha: sourceCommit.ID,
+		}).Error
+	} else if approval.CommitSha != sourceCommit.ID {
+		// 审批过旧的提交, 重新审批最新的提交
+		err = svc.db.Model(&approval).Updates(map[string]interface{}{
+			"commit_sha": sourceCommit.ID,
+			"created_at": time.Now(),
+		}).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return svc.getApprovalState(repo, &mergeRequest)
+}
+
+func (svc *Service) RevokeMRApproval(repo *gitmodule.Repository, user *User, mergeId int) (*apistructs.MergeRequestApprovalState, error) {
+	var mergeRequest MergeRequest
+	err := svc.db.Where("repo_id = ? and repo_merge_id=?", repo.ID, mergeId).First(&mergeRequest).Error
+	if err != nil {
+		return nil, err
+	}
+	if mergeRequest.State != MERGE_REQUEST_OPEN {
+		return nil, errors.New(mergeRequest.State + " 状态无法撤销审批")
+	}
+	err = svc.db.Where("merge_id = ? and user_id = ?", mergeRequest.ID, user.Id).Delete(&MergeApproval{}).Error
+	if err != nil {
+		return nil, err
+	}
+	return svc.getApprovalState(repo, &mergeRequest)
+}
+
+func (svc *Service) GetMRApprovalState(repo *gitmodule.Repository, mergeId int) (*apistructs.MergeRequestApprovalState, error) {
+	var mergeRequest MergeRequest
+	err := svc.db.Where("repo_id = ? and repo_merge_id=?", repo.ID, mergeId).First(&mergeRequest).Error
+	if err != nil {
+		return nil, err
+	}
+	return svc.getApprovalState(repo, &mergeRequest)
+}
+
+// getApprovalState 源分支当前提交的审批状态
+func (svc *Service) getApprovalState(repo *gitmodule.Repository, mergeRequest *MergeRequest) (*apistructs.MergeRequestApprovalState, error) {
+	sourceCommit, err := repo.GetBranchCommit(mergeRequest.SourceBranch)
+	if err != nil {
+		return nil, err
+	}
+	return svc.getCommitApprovalState(repo, mergeRequest, sourceCommit)
+}
+
+// getCommitApprovalState 审批人数需要满足目标保护分支规则的要求, 改动文件匹配的每个CODEOWNERS规则需要其中一个owner审批
+// 只有审批的提交与sourceCommit一致时审批才有效
+func (svc *Service) getCommitApprovalState(repo *gitmodule.Repository, mergeRequest *MergeRequest, sourceCommit *gitmodule.Commit) (*apistructs.MergeRequestApprovalState, error) {
+	var approvals []MergeApproval
+	err := svc.db.Where("merge_id = ? and commit_sha = ?", mergeRequest.ID, sourceCommit.ID).Order("id").Find(&approvals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	state := &apistructs.MergeRequestApprovalState{
+		Approvals:  []apistructs.MergeRequestApproval{},
+		CodeOwners: []apistructs.CodeOwnersGroup{},
+	}
+	for _, approval := range approvals {
+		item := apistructs.MergeRequestApproval{
+			UserID:    approval.UserID,
+			CreatedAt: approval.CreatedAt,
+		}
+		dto, err := uc.FindUserByIdWithDesensitize(approval.UserID)
+		if err == nil {
+			item.User = dto
+		} else {
+			logrus.Errorf("get user from uc error: %v", err)
+		}
+		state.Approvals = append(state.Approvals, item)
+	}
+
+	targetBranch, err := repo.GetValidBranch(mergeRequest.TargetBranch)
+	if err != nil {
+		return nil, err
+	}
+	if targetBranch.IsProtect {
+		state.RequiredApprovals = targetBranch.RequiredApprovals
+	}
+	state.Approved = len(approvals) >= state.RequiredApprovals
+
+	groups, err := svc.getCodeOwnersGroups(repo, mergeRequest, sourceCommit)
+	if err != nil {
+		return nil, err
+	}
+	var reviewers []MergeReviewer
+	err = svc.db.Where("merge_id = ?", mergeRequest.ID).Find(&reviewers).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		for _, reviewer := range reviewers {
+			if reviewer.Pattern == group.Pattern {
+				group.Reviewer = reviewer.UserID
+				break
+			}
+		}
+		for _, approval := range approvals {
+			if group.rule.HasOwner(approval.UserID, approval.UserName, approval.UserEmail) {
+				group.Approved = true
+				break
+			}
+		}
+		if !group.Approved {
+			state.Approved = false
+		}
+		state.CodeOwners = append(state.CodeOwners, group.CodeOwnersGroup)
+	}
+	return state, nil
+}
+
+type codeOwnersGroup struct {
+	apistructs.CodeOwnersGroup
+	rule *codeowners.Rule
+}
+
+// getCodeOwnersGroups 使用目标分支的CODEOWNERS文件, 匹配sourceCommit的改动文件
+func (svc *Service) getCodeOwnersGroups(repo *gitmodule.Repository, mergeRequest *MergeRequest, sourceCommit *gitmodule.Commit) ([]*codeOwnersGroup, error) {
+	targetCommit, err := repo.GetBranchCommit(mergeRequest.TargetBranch)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := repo.GetCodeOwners(targetCommit.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CODEOWNERS: %v", err)
+	}
+	if owners == nil {
+		return nil, nil
+	}
+	files, err := repo.GetChangedFiles(sourceCommit, targetCommit)
+	if err != nil {
+		return nil, err
+	}
+	rules, paths := owners.Groups(files)
+	var groups []*codeOwnersGroup
+	for i, rule := range rules {
+		groups = append(groups, &codeOwnersGroup{
+			CodeOwnersGroup: apistructs.CodeOwnersGroup{
+				Pattern: rule.Pattern,
+				Owners:  rule.Owners,
+				Paths:   paths[i],
+			},
+			rule: rule,
+		})
+	}
+	return groups, nil
+}
+
+// MergeReviewer 根据CODEOWNERS自动指定的mr审批人, 每个匹配改动文件的规则指定一个
+type MergeReviewer struct {
+	ID      int64
+	RepoID  int64 `gorm:"index:idx_repo_id"`
+	MergeID int64 // MergeRequest.ID
+	// Pattern 审批人负责的CODEOWNERS规则
+	Pattern   string
+	UserID    string
+	CreatedAt time.Time
+}
+
+// assignCodeOwnerReviewers 为每个匹配改动文件的CODEOWNERS规则指定一个审批人, 已指定审批人的规则不再重新指定
+// CODEOWNERS中的owner可以是用户id, 用户名或邮箱, 只有用户id可以被自动指定
+// 未指定处理人时, 将第一个审批人设置为处理人
+func (svc *Service) assignCodeOwnerReviewers(repo *gitmodule.Repository, mergeRequest *MergeRequest, sourceCommit *gitmodule.Commit) error {
+	groups, err := svc.getCodeOwnersGroups(repo, mergeRequest, sourceCommit)
+	if err != nil {
+		return err
+	}
+	var reviewers []MergeReviewer
+	err = svc.db.Where("merge_id = ?", mergeRequest.ID).Order("id").Find(&reviewers).Error
+	if err != nil {
+		return err
+	}
+	assigned := make(map[string]bool)
+	for _, reviewer := range reviewers {
+		assigned[reviewer.Pattern] = true
+	}
+	for _, group := range groups {
+		if assigned[group.Pattern] {
+			continue
+		}
+		userID := pickCodeOwnerReviewer(group.Owners, mergeRequest.AuthorId, reviewers)
+		if userID == "" {
+			continue
+		}
+		reviewer := MergeReviewer{
+			RepoID:  mergeRequest.RepoID,
+			MergeID: mergeRequest.ID,
+			Pattern: group.Pattern,
+			UserID:  userID,
+		}
+		if err := svc.db.Create(&reviewer).Error; err != nil {
+			return err
+		}
+		assigned[group.Pattern] = true
+		reviewers = append(reviewers, reviewer)
+	}
+	if mergeRequest.AssigneeId == "" && len(reviewers) > 0 {
+		mergeRequest.AssigneeId = reviewers[0].UserID
+		return svc.db.Model(mergeRequest).Update("assignee_id", mergeRequest.AssigneeId).Error
+	}
+	return nil
+}
+
+// pickCodeOwnerReviewer 优先选择已经是其他规则审批人的owner, 减少审批人数, 否则选择第一个用户id
+func pickCodeOwnerReviewer(owners []string, authorID string, reviewers []MergeReviewer) string {
+	var candidates []string
+	for _, owner := range owners {
+		if owner == authorID {
+			continue
+		}
+		if _, err := strconv.ParseUint(owner, 10, 64); err != nil {
+			continue
+		}
+		candidates = append(candidates, owner)
+	}
+	for _, candidate := range candidates {
+		for _, reviewer := range reviewers {
+			if reviewer.UserID == candidate {
+				return candidate
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	return candidates[0]
+}
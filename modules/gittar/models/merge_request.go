@@ -64,6 +64,8 @@ type MergeQueryCondition struct {
 type MergeOptions struct {
 	RemoveSourceBranch bool   `json:"removeSourceBranch"`
 	CommitMessage      string `json:"CommitMessage"`
+	// MergeStrategy 合并方式, 为空时使用仓库的默认合并方式
+	MergeStrategy apistructs.MergeStrategy `json:"mergeStrategy"`
 }
 
 //MergeRequest model
@@ -90,6 +92,7 @@ type MergeRequest struct {
 	CloseAt            *time.Time
 	Score              int `gorm:"size:150;index:idx_score"`
 	ScoreNum           int `gorm:"size:150;index:idx_score_num"`
+	MergeStrategy      string
 }
 
 type MrCheckRun struct {
@@ -123,6 +126,7 @@ func (mergeRequest *MergeRequest) ToInfo(repo *gitmodule.Repository) *apistructs
 	result.AppID = repo.ApplicationId
 	result.Score = mergeRequest.Score
 	result.ScoreNum = mergeRequest.ScoreNum
+	result.MergeStrategy = mergeRequest.MergeStrategy
 
 	if mergeRequest.SourceBranch != "" && mergeRequest.TargetBranch != "" {
 		result.DefaultCommitMessage = fmt.Sprintf("Merge branch '%s' into '%s'", mergeRequest.SourceBranch, mergeRequest.TargetBranch)
@@ -207,6 +211,9 @@ func (svc *Service) CreateMergeRequest(repo *gitmodule.Repository, user *User, i
 	if err != nil {
 		return nil, err
 	}
+	if err := svc.assignCodeOwnerReviewers(repo, &mergeRequest, sourceCommit); err != nil {
+		logrus.Errorf("failed to assign code owner reviewers of mr %d: %v", mergeRequest.RepoMergeId, err)
+	}
 
 	info.RepoMergeId = mergeRequest.RepoMergeId
 	info.AuthorUser = &apistructs.UserInfoDto{
@@ -297,6 +304,12 @@ func (svc *Service) GetMergeRequestDetail(repo *gitmodule.Repository, mergeId in
 		}
 	}
 	result := mergeRequest.ToInfo(repo)
+	if mergeRequest.State == MERGE_REQUEST_OPEN {
+		result.Approval, err = svc.getApprovalState(repo, &mergeRequest)
+		if err != nil {
+			logrus.Errorf("failed to get approval state of mr %d: %v", mergeRequest.RepoMergeId, err)
+		}
+	}
 	result.IsCheckRunValid, err = svc.IsCheckRunsValid(repo, mergeRequest.ID)
 	return result, err
 }
@@ -315,6 +328,19 @@ func (svc *Service) SyncMergeRequest(repo *gitmodule.Repository, branch string,
 		if err != nil {
 			return err
 		}
+		if flag {
+			// 源分支有新的提交, 之前的审批失效
+			err = svc.db.Where("merge_id = ? and commit_sha <> ?", mergeRequest.ID, commitID).Delete(&MergeApproval{}).Error
+			if err != nil {
+				return err
+			}
+			// 新的改动文件可能匹配新的CODEOWNERS规则
+			if sourceCommit, err := repo.GetCommit(commitID); err == nil {
+				if err := svc.assignCodeOwnerReviewers(repo, &mergeRequest, sourceCommit); err != nil {
+					logrus.Errorf("failed to assign code owner reviewers of mr %d: %v", mergeRequest.RepoMergeId, err)
+				}
+			}
+		}
 		mrInfo := mergeRequest.ToInfo(repo)
 		if flag {
 			go func(mergeRequest MergeRequest) {
@@ -426,7 +452,22 @@ func (svc *Service) Merge(repo *gitmodule.Repository, user *User, mergeId int, m
 		return nil, err
 	}
 
-	mergeStatus, err := repo.GetMergeStatus(mergeRequest.SourceBranch, mergeRequest.TargetBranch)
+	repoModel, err := svc.GetRepoById(repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	strategy := mergeOptions.MergeStrategy
+	if strategy == "" {
+		strategy = repoModel.MergeSettings().DefaultStrategy
+	}
+	if !strategy.Valid() {
+		return nil, errors.New("invalid merge strategy: " + strategy.String())
+	}
+	if !repoModel.IsMergeStrategyAllowed(strategy) {
+		return nil, errors.New("merge strategy is not allowed: " + strategy.String())
+	}
+
+	mergeStatus, err := repo.GetMergeStatusWithStrategy(mergeRequest.SourceBranch, mergeRequest.TargetBranch, strategy)
 	if err != nil {
 		return nil, err
 	}
@@ -435,11 +476,35 @@ func (svc *Service) Merge(repo *gitmodule.Repository, user *User, mergeId int, m
 	}
 
 	if mergeStatus.HasConflict {
+		if mergeStatus.ErrorMsg != "" {
+			return nil, errors.New(mergeStatus.ErrorMsg)
+		}
 		return nil, errors.New("has conflict")
 	}
 
-	if repo.IsProtectBranch(mergeRequest.TargetBranch) ||
-		(repo.IsProtectBranch(mergeRequest.SourceBranch) && mergeRequest.RemoveSourceBranch) {
+	sourceCommit, err := repo.GetBranchCommit(mergeRequest.SourceBranch)
+	if err != nil {
+		return nil, err
+	}
+	// 审批状态和合并的内容都使用同一个源分支提交, 审批后源分支的新提交不会被合并
+	approval, err := svc.getCommitApprovalState(repo, &mergeRequest, sourceCommit)
+	if err != nil {
+		return nil, err
+	}
+	if !approval.Approved {
+		return nil, errors.New("merge request is not approved")
+	}
+
+	// 获取分支规则失败时拒绝合并, 避免绕过保护分支校验
+	targetBranch, err := repo.GetValidBranch(mergeRequest.TargetBranch)
+	if err != nil {
+		return nil, err
+	}
+	sourceBranch, err := repo.GetValidBranch(mergeRequest.SourceBranch)
+	if err != nil {
+		return nil, err
+	}
+	if targetBranch.IsProtect || (sourceBranch.IsProtect && mergeRequest.RemoveSourceBranch) {
 		err = svc.CheckPermission(repo, user, PermissionPushProtectBranch, nil)
 		if err != nil {
 			return nil, err
@@ -447,18 +512,18 @@ func (svc *Service) Merge(repo *gitmodule.Repository, user *User, mergeId int, m
 	}
 
 	if mergeOptions.CommitMessage == "" {
-		mergeOptions.CommitMessage = fmt.Sprintf("Merge branch '%s' into '%s'", mergeRequest.SourceBranch, mergeRequest.TargetBranch)
-	}
-	_, err = repo.GetBranchCommit(mergeRequest.SourceBranch)
-	if err != nil {
-		return nil, err
+		if strategy == apistructs.MergeStrategySquash {
+			mergeOptions.CommitMessage = mergeRequest.Title
+		} else {
+			mergeOptions.CommitMessage = fmt.Sprintf("Merge branch '%s' into '%s'", mergeRequest.SourceBranch, mergeRequest.TargetBranch)
+		}
 	}
-
-	commit, err := repo.Merge(mergeRequest.SourceBranch, mergeRequest.TargetBranch, user.ToGitSignature(), mergeOptions.CommitMessage)
+	commit, err := repo.MergeCommitWithStrategy(sourceCommit.ID, mergeRequest.TargetBranch, strategy, user.ToGitSignature(), mergeOptions.CommitMessage)
 
 	now := time.Now()
 	if err == nil {
 		mergeRequest.State = MERGE_REQUEST_MERGED
+		mergeRequest.MergeStrategy = strategy.String()
 		mergeRequest.MergeCommitSha = commit.ID
 		mergeRequest.MergeAt = &now
 		mergeRequest.MergeUserId = user.Id
@@ -471,7 +536,10 @@ func (svc *Service) Merge(repo *gitmodule.Repository, user *User, mergeId int, m
 	}
 
 	if mergeOptions.RemoveSourceBranch {
-		repo.DeleteBranch(mergeRequest.SourceBranch)
+		// 源分支有未合并的新提交时保留源分支
+		if currentSourceCommit, err := repo.GetBranchCommit(mergeRequest.SourceBranch); err == nil && currentSourceCommit.ID == sourceCommit.ID {
+			repo.DeleteBranch(mergeRequest.SourceBranch)
+		}
 	}
 
 	return commit, nil
@@ -532,6 +600,8 @@ func (svc *Service) RemoveMR(repository *Repo) error {
 	req := &MergeRequest{}
 	svc.db.Where("repo_id =? ", repository.ID).Delete(&req)
 	svc.RemoveCheckRuns(req.ID)
+	svc.db.Where("repo_id =? ", repository.ID).Delete(&MergeApproval{})
+	svc.db.Where("repo_id =? ", repository.ID).Delete(&MergeReviewer{})
 	return nil
 }
 
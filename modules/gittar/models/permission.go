@@ -43,3 +43,21 @@ func (svc *Service) CheckPermission(repo *gitmodule.Repository, user *User, perm
 	}
 	return nil
 }
+
+// CheckSysAdminPermission 检查用户是否为系统管理员
+func (svc *Service) CheckSysAdminPermission(user *User) error {
+	checkPermission, err := svc.bundle.CheckPermission(&apistructs.PermissionCheckRequest{
+		UserID:   user.Id,
+		Scope:    apistructs.SysScope,
+		ScopeID:  1,
+		Resource: apistructs.OrgResource,
+		Action:   apistructs.CreateAction,
+	})
+	if err != nil {
+		return err
+	}
+	if !checkPermission.Access {
+		return fmt.Errorf("no permission: sys admin for user: %s", user.NickName)
+	}
+	return nil
+}
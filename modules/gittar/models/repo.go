@@ -20,6 +20,7 @@ import (
 	"os"
 	"path"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/patrickmn/go-cache"
@@ -45,6 +46,12 @@ type Repo struct {
 	Size        int64
 	IsExternal  bool
 	Config      string
+	// DefaultMergeStrategy mr默认合并方式, 为空时使用merge
+	DefaultMergeStrategy string
+	// AllowedMergeStrategies 允许的合并方式, 逗号分隔, 为空时允许所有方式
+	AllowedMergeStrategies string
+	// LFSQuota lfs存储配额, 单位Byte, 0表示使用系统默认配额, 小于0表示不限制
+	LFSQuota int64
 }
 
 func (Repo) TableName() string {
@@ -141,6 +148,78 @@ func (svc *Service) SetLocked(repo *gitmodule.Repository, user *User, info *apis
 	return info, nil
 }
 
+// MergeSettings 返回仓库的mr合并设置
+func (r *Repo) MergeSettings() *apistructs.RepoMergeSettings {
+	settings := &apistructs.RepoMergeSettings{
+		DefaultStrategy: apistructs.MergeStrategy(r.DefaultMergeStrategy),
+	}
+	if settings.DefaultStrategy == "" {
+		settings.DefaultStrategy = apistructs.MergeStrategyMerge
+	}
+	for _, strategy := range strings.Split(r.AllowedMergeStrategies, ",") {
+		if strategy = strings.TrimSpace(strategy); strategy != "" {
+			settings.AllowedStrategies = append(settings.AllowedStrategies, apistructs.MergeStrategy(strategy))
+		}
+	}
+	if len(settings.AllowedStrategies) == 0 {
+		settings.AllowedStrategies = apistructs.MergeStrategies
+	}
+	return settings
+}
+
+// IsMergeStrategyAllowed 合并方式是否被仓库允许
+func (r *Repo) IsMergeStrategyAllowed(strategy apistructs.MergeStrategy) bool {
+	for _, allowed := range r.MergeSettings().AllowedStrategies {
+		if allowed == strategy {
+			return true
+		}
+	}
+	return false
+}
+
+func (svc *Service) GetRepoMergeSettings(repo *gitmodule.Repository) (*apistructs.RepoMergeSettings, error) {
+	repoModel, err := svc.GetRepoById(repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	return repoModel.MergeSettings(), nil
+}
+
+func (svc *Service) UpdateRepoMergeSettings(repo *gitmodule.Repository, user *User, settings *apistructs.RepoMergeSettings) (*apistructs.RepoMergeSettings, error) {
+	if err := svc.CheckPermission(repo, user, PermissionRepoSetting, nil); err != nil {
+		return nil, err
+	}
+	if len(settings.AllowedStrategies) == 0 {
+		settings.AllowedStrategies = apistructs.MergeStrategies
+	}
+	allowed := make([]string, 0, len(settings.AllowedStrategies))
+	for _, strategy := range settings.AllowedStrategies {
+		if !strategy.Valid() {
+			return nil, errors.New("invalid merge strategy: " + strategy.String())
+		}
+		allowed = append(allowed, strategy.String())
+	}
+	if settings.DefaultStrategy == "" {
+		settings.DefaultStrategy = settings.AllowedStrategies[0]
+	}
+	repoModel := &Repo{
+		DefaultMergeStrategy:   settings.DefaultStrategy.String(),
+		AllowedMergeStrategies: strings.Join(allowed, ","),
+	}
+	if !repoModel.IsMergeStrategyAllowed(settings.DefaultStrategy) {
+		return nil, errors.New("default merge strategy is not allowed: " + settings.DefaultStrategy.String())
+	}
+
+	err := svc.db.Table("dice_repos").Where("id = ?", repo.ID).Updates(map[string]interface{}{
+		"default_merge_strategy":   repoModel.DefaultMergeStrategy,
+		"allowed_merge_strategies": repoModel.AllowedMergeStrategies,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	return repoModel.MergeSettings(), nil
+}
+
 func (svc *Service) DeleteRepo(repo *Repo) error {
 	repoPath := repo.DiskPath()
 	logrus.Infof("remove gitRepo %v", repoPath)
@@ -167,7 +246,10 @@ func (svc *Service) DeleteRepo(repo *Repo) error {
 		return err
 	}
 	err = svc.RemoveMR(repo)
-	return err
+	if err != nil {
+		return err
+	}
+	return svc.RemoveLFSObjects(repo.ID)
 }
 
 func (svc *Service) UpdateRepoSizeCache(id int64, size int64) error {
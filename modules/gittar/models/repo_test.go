@@ -0,0 +1,79 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestRepo_MergeSettings(t *testing.T) {
+	tt := []struct {
+		name        string
+		repo        Repo
+		wantDefault apistructs.MergeStrategy
+		wantAllowed []apistructs.MergeStrategy
+	}{
+		{
+			name:        "empty settings",
+			repo:        Repo{},
+			wantDefault: apistructs.MergeStrategyMerge,
+			wantAllowed: apistructs.MergeStrategies,
+		},
+		{
+			name:        "default strategy only",
+			repo:        Repo{DefaultMergeStrategy: "squash"},
+			wantDefault: apistructs.MergeStrategySquash,
+			wantAllowed: apistructs.MergeStrategies,
+		},
+		{
+			name:        "allowed strategies",
+			repo:        Repo{DefaultMergeStrategy: "rebase", AllowedMergeStrategies: "rebase, fast_forward,"},
+			wantDefault: apistructs.MergeStrategyRebase,
+			wantAllowed: []apistructs.MergeStrategy{apistructs.MergeStrategyRebase, apistructs.MergeStrategyFastForward},
+		},
+		{
+			name:        "blank allowed strategies",
+			repo:        Repo{AllowedMergeStrategies: " , "},
+			wantDefault: apistructs.MergeStrategyMerge,
+			wantAllowed: apistructs.MergeStrategies,
+		},
+	}
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			settings := v.repo.MergeSettings()
+			assert.Equal(t, v.wantDefault, settings.DefaultStrategy)
+			assert.Equal(t, v.wantAllowed, settings.AllowedStrategies)
+		})
+	}
+}
+
+func TestRepo_IsMergeStrategyAllowed(t *testing.T) {
+	all := Repo{}
+	for _, strategy := range apistructs.MergeStrategies {
+		assert.True(t, all.IsMergeStrategyAllowed(strategy))
+	}
+	assert.False(t, all.IsMergeStrategyAllowed(apistructs.MergeStrategy("octopus")))
+
+	limited := Repo{AllowedMergeStrategies: "squash,rebase"}
+	assert.True(t, limited.IsMergeStrategyAllowed(apistructs.MergeStrategySquash))
+	assert.True(t, limited.IsMergeStrategyAllowed(apistructs.MergeStrategyRebase))
+	assert.False(t, limited.IsMergeStrategyAllowed(apistructs.MergeStrategyMerge))
+	assert.False(t, limited.IsMergeStrategyAllowed(apistructs.MergeStrategyFastForward))
+	assert.False(t, limited.IsMergeStrategyAllowed(""))
+}
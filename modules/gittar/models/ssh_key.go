@@ -0,0 +1,129 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+// SSHKey 用户ssh公钥
+type SSHKey struct {
+	ID          int64
+	UserID      string `gorm:"index:idx_user_id"`
+	Title       string
+	Fingerprint string `gorm:"unique_index:uk_fingerprint"`
+	Content     string
+	LastUsedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (key *SSHKey) ToDTO() *apistructs.GittarSSHKey {
+	return &apistructs.GittarSSHKey{
+		ID:          key.ID,
+		UserID:      key.UserID,
+		Title:       key.Title,
+		Fingerprint: key.Fingerprint,
+		Key:         key.Content,
+		CreatedAt:   key.CreatedAt,
+		LastUsedAt:  key.LastUsedAt,
+	}
+}
+
+// AddSSHKey 添加ssh公钥, 同一个公钥只能被一个用户使用
+func (svc *Service) AddSSHKey(user *User, request *apistructs.GittarCreateSSHKeyRequest) (*apistructs.GittarSSHKey, error) {
+	publicKey, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(request.Key)))
+	if err != nil {
+		return nil, errors.New("invalid ssh public key")
+	}
+	title := strings.TrimSpace(request.Title)
+	if title == "" {
+		title = comment
+	}
+	if title == "" {
+		return nil, errors.New("title is empty")
+	}
+	fingerprint := ssh.FingerprintSHA256(publicKey)
+
+	var count int
+	err = svc.db.Model(&SSHKey{}).Where("fingerprint = ?", fingerprint).Count(&count).Error
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("ssh key has already been taken")
+	}
+
+	key := &SSHKey{
+		UserID:      user.Id,
+		Title:       title,
+		Fingerprint: fingerprint,
+		Content:     strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey))),
+	}
+	err = svc.db.Create(key).Error
+	if err != nil {
+		return nil, err
+	}
+	return key.ToDTO(), nil
+}
+
+// ListSSHKeys 获取用户的ssh公钥列表
+func (svc *Service) ListSSHKeys(user *User) ([]*apistructs.GittarSSHKey, error) {
+	var keys []SSHKey
+	err := svc.db.Where("user_id = ?", user.Id).Order("id").Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+	result := []*apistructs.GittarSSHKey{}
+	for i := range keys {
+		result = append(result, keys[i].ToDTO())
+	}
+	return result, nil
+}
+
+// DeleteSSHKey 删除用户的ssh公钥
+func (svc *Service) DeleteSSHKey(user *User, id int64) error {
+	var key SSHKey
+	err := svc.db.Where("id = ? and user_id = ?", id, user.Id).First(&key).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New("ssh key not found")
+		}
+		return err
+	}
+	return svc.db.Delete(&key).Error
+}
+
+// GetSSHKeyByPublicKey ssh认证时根据公钥查找对应记录
+func (svc *Service) GetSSHKeyByPublicKey(publicKey ssh.PublicKey) (*SSHKey, error) {
+	var key SSHKey
+	err := svc.db.Where("fingerprint = ?", ssh.FingerprintSHA256(publicKey)).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// TouchSSHKey 更新公钥最后使用时间
+func (svc *Service) TouchSSHKey(id int64) error {
+	return svc.db.Model(&SSHKey{}).Where("id = ?", id).UpdateColumn("last_used_at", time.Now()).Error
+}
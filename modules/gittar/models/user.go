@@ -31,6 +31,7 @@ const (
 	PermissionCloseMR                Permission = "CLOSE_MR"
 	PermissionCreateMR               Permission = "CREATE_MR"
 	PermissionMergeMR                Permission = "MERGE_MR"
+	PermissionApproveMR              Permission = "APPROVE_MR"
 	PermissionEditMR                 Permission = "EDIT_MR"
 	PermissionArchive                Permission = "ARCHIVE"
 	PermissionClone                  Permission = "CLONE"
@@ -38,6 +39,7 @@ const (
 	PermissionPushProtectBranch      Permission = "PUSH_PROTECT_BRANCH"
 	PermissionPushProtectBranchForce Permission = "PUSH_PROTECT_BRANCH_FORCE"
 	PermissionRepoLocked             Permission = "REPO_LOCKED"
+	PermissionRepoSetting            Permission = "REPO_SETTING"
 )
 
 var NO_PERMISSION_ERROR = errors.New("no permission")
@@ -54,6 +56,7 @@ func init() {
 		PermissionCloseMR,
 		PermissionCreateMR,
 		PermissionMergeMR,
+		PermissionApproveMR,
 		PermissionPush,
 		PermissionPushProtectBranch,
 		PermissionPushProtectBranchForce,
@@ -67,6 +70,7 @@ func init() {
 		PermissionDeleteTAG,
 		PermissionCloseMR,
 		PermissionCreateMR,
+		PermissionApproveMR,
 		PermissionPushProtectBranch,
 		PermissionPush,
 		PermissionArchive,
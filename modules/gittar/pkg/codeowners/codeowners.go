@@ -0,0 +1,171 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package codeowners parses the CODEOWNERS file, the syntax is the same as github:
+//
+//	# comment
+//	*                 @lead
+//	/docs/            @writer docs@example.com
+//	*.go              @gopher
+//	/modules/**/api/  @api-owner
+//
+// For a path, the last matching rule takes precedence.
+package codeowners
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Paths are the locations of CODEOWNERS file in repository, the first existed one is used.
+var Paths = []string{".erda/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// Rule is a line of CODEOWNERS file.
+type Rule struct {
+	Pattern string
+	Owners  []string
+	Line    int
+
+	re *regexp.Regexp
+}
+
+// Match returns whether the path is matched by the rule.
+func (r *Rule) Match(path string) bool {
+	return r.re.MatchString(strings.TrimPrefix(path, "/"))
+}
+
+// HasOwner returns whether any of the names is owner of the rule, names can be user id, username or email.
+func (r *Rule) HasOwner(names ...string) bool {
+	for _, owner := range r.Owners {
+		for _, name := range names {
+			if name != "" && strings.EqualFold(owner, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CodeOwners is the parsed CODEOWNERS file.
+type CodeOwners struct {
+	Rules []*Rule
+}
+
+// Parse parses the content of CODEOWNERS file.
+func Parse(content []byte) (*CodeOwners, error) {
+	c := &CodeOwners{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	var line int
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if idx := strings.Index(text, " #"); idx >= 0 {
+			text = text[:idx]
+		}
+		fields := strings.Fields(text)
+		re, err := compile(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q at line %d: %s", fields[0], line, err)
+		}
+		rule := &Rule{Pattern: fields[0], Line: line, re: re}
+		for _, owner := range fields[1:] {
+			rule.Owners = append(rule.Owners, strings.TrimPrefix(owner, "@"))
+		}
+		c.Rules = append(c.Rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Match returns the last rule matched the path, returns nil if not matched.
+// The rule without owners matched means the path has no owner.
+func (c *CodeOwners) Match(path string) *Rule {
+	for i := len(c.Rules) - 1; i >= 0; i-- {
+		if c.Rules[i].Match(path) {
+			return c.Rules[i]
+		}
+	}
+	return nil
+}
+
+// Groups returns the owner groups of the paths, every group should be approved by one of its owners.
+// The paths of every group are returned in the same order.
+func (c *CodeOwners) Groups(paths []string) ([]*Rule, [][]string) {
+	var rules []*Rule
+	var groupPaths [][]string
+	index := make(map[*Rule]int)
+	for _, path := range paths {
+		rule := c.Match(path)
+		if rule == nil || len(rule.Owners) <= 0 {
+			continue
+		}
+		idx, ok := index[rule]
+		if !ok {
+			idx = len(rules)
+			index[rule] = idx
+			rules = append(rules, rule)
+			groupPaths = append(groupPaths, nil)
+		}
+		groupPaths[idx] = append(groupPaths[idx], path)
+	}
+	return rules, groupPaths
+}
+
+// compile converts the gitignore style pattern to regexp.
+func compile(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	// pattern contains slash at the beginning or middle is relative to the root
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var sb strings.Builder
+	if anchored {
+		sb.WriteString("^")
+	} else {
+		sb.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		ch := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case ch == '*':
+			sb.WriteString("[^/]*")
+		case ch == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	if dirOnly {
+		sb.WriteString("/.*$")
+	} else {
+		// matches the file, or all files in the directory
+		sb.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(sb.String())
+}
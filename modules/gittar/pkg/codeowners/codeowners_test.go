@@ -0,0 +1,98 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testContent = `
+# default owners
+*                   @lead
+
+*.go                @gopher dev@example.com # go files
+/docs/              @writer
+api/                @api
+/modules/**/dao/    @dba
+/vendor/
+`
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(testContent))
+	assert.NoError(t, err)
+	if assert.Equal(t, 6, len(c.Rules)) {
+		assert.Equal(t, "*.go", c.Rules[1].Pattern)
+		assert.Equal(t, []string{"gopher", "dev@example.com"}, c.Rules[1].Owners)
+		assert.Equal(t, 5, c.Rules[1].Line)
+		assert.Equal(t, 0, len(c.Rules[5].Owners))
+	}
+}
+
+func TestCodeOwners_Match(t *testing.T) {
+	c, err := Parse([]byte(testContent))
+	assert.NoError(t, err)
+	tests := []struct {
+		path    string
+		pattern string
+	}{
+		{path: "README.md", pattern: "*"},
+		{path: "main.go", pattern: "*.go"},
+		{path: "cmd/erda/main.go", pattern: "*.go"},
+		{path: "docs/guide.md", pattern: "/docs/"},
+		{path: "modules/docs/guide.md", pattern: "*"},
+		{path: "modules/gittar/api/merge.go", pattern: "api/"},
+		{path: "api/proto/a.proto", pattern: "api/"},
+		{path: "modules/dop/dao/issue.go", pattern: "/modules/**/dao/"},
+		{path: "modules/dop/services/dao/issue.go", pattern: "/modules/**/dao/"},
+		{path: "vendor/a/b.go", pattern: "/vendor/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rule := c.Match(tt.path)
+			if assert.NotNil(t, rule) {
+				assert.Equal(t, tt.pattern, rule.Pattern)
+			}
+		})
+	}
+
+	c, err = Parse([]byte("docs/*.md @writer\n"))
+	assert.NoError(t, err)
+	assert.NotNil(t, c.Match("docs/a.md"))
+	assert.Nil(t, c.Match("docs/sub/a.md"))
+	assert.Nil(t, c.Match("a/docs/a.md"))
+}
+
+func TestCodeOwners_Groups(t *testing.T) {
+	c, err := Parse([]byte(testContent))
+	assert.NoError(t, err)
+	rules, paths := c.Groups([]string{"main.go", "docs/a.md", "vendor/a.go", "pkg/b.go", "Makefile"})
+	if assert.Equal(t, 3, len(rules)) {
+		assert.Equal(t, "*.go", rules[0].Pattern)
+		assert.Equal(t, []string{"main.go", "pkg/b.go"}, paths[0])
+		assert.Equal(t, "/docs/", rules[1].Pattern)
+		assert.Equal(t, []string{"docs/a.md"}, paths[1])
+		assert.Equal(t, "*", rules[2].Pattern)
+		assert.Equal(t, []string{"Makefile"}, paths[2])
+	}
+}
+
+func TestRule_HasOwner(t *testing.T) {
+	rule := &Rule{Owners: []string{"gopher", "dev@example.com"}}
+	assert.True(t, rule.HasOwner("1000", "Gopher"))
+	assert.True(t, rule.HasOwner("", "dev@example.com"))
+	assert.False(t, rule.HasOwner("", "someone"))
+}
@@ -67,16 +67,24 @@ const (
 )
 
 func (repo *Repository) IsProtectBranch(branch string) bool {
+	validBranch, err := repo.GetValidBranch(branch)
+	if err != nil {
+		return false
+	}
+	return validBranch.IsProtect
+}
+
+// GetValidBranch 获取分支匹配的分支规则, 获取规则失败时返回错误, 调用方不能将其当作非保护分支处理
+func (repo *Repository) GetValidBranch(branch string) (*apistructs.ValidBranch, error) {
 	// repo是http请求级别的实例，一个请求中不重复更新规则
 	if repo.branchRules == nil {
 		rules, err := repo.Bundle.GetAppBranchRules(uint64(repo.ApplicationId))
 		if err != nil {
-			return false
+			return nil, fmt.Errorf("failed to get branch rules: %v", err)
 		}
 		repo.branchRules = rules
 	}
-	gitReference := diceworkspace.GetValidBranchByGitReference(branch, repo.branchRules)
-	return gitReference.IsProtect
+	return diceworkspace.GetValidBranchByGitReference(branch, repo.branchRules), nil
 }
 
 func (repo *Repository) IsProtectBranchWithRules(branch string, rules []*apistructs.BranchRule) bool {
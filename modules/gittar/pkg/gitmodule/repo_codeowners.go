@@ -0,0 +1,63 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitmodule
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	"github.com/erda-project/erda/modules/gittar/pkg/codeowners"
+)
+
+// GetCodeOwners 读取commit中的CODEOWNERS文件, 文件不存在时返回nil
+func (repo *Repository) GetCodeOwners(commitID string) (*codeowners.CodeOwners, error) {
+	for _, path := range codeowners.Paths {
+		treeEntry, err := repo.GetTreeEntryByPath(commitID, path)
+		if err == ERROR_PATH_NOT_FOUND {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if treeEntry.IsDir() {
+			continue
+		}
+		rd, err := treeEntry.Blob().Data()
+		if err != nil {
+			return nil, err
+		}
+		content, err := ioutil.ReadAll(rd)
+		if err != nil {
+			return nil, err
+		}
+		return codeowners.Parse(content)
+	}
+	return nil, nil
+}
+
+// GetChangedFiles 获取ourCommit相对于和theirCommit的合并基础的改动文件
+func (repo *Repository) GetChangedFiles(ourCommit *Commit, theirCommit *Commit) ([]string, error) {
+	stdout, err := NewCommand("diff", "--name-only", "-z", theirCommit.ID+"..."+ourCommit.ID).RunInDirBytes(repo.DiskPath())
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, file := range bytes.Split(stdout, []byte{0}) {
+		if len(file) > 0 {
+			files = append(files, string(file))
+		}
+	}
+	return files, nil
+}
@@ -18,15 +18,22 @@ package gitmodule
 
 import (
 	"errors"
+	"strings"
 
 	git "github.com/libgit2/git2go/v30"
+
+	"github.com/erda-project/erda/apistructs"
 )
 
+// ErrRebaseWithMergeCommits 源分支包含merge提交时无法变基合并, 否则merge提交会被丢弃
+var ErrRebaseWithMergeCommits = errors.New("源分支包含合并提交,无法变基合并,请使用其他合并方式")
+
 type MergeStatusInfo struct {
 	HasConflict bool   `json:"hasConflict"`
 	IsMerged    bool   `json:"isMerged"`
 	HasError    bool   `json:"hasError"`
 	ErrorMsg    string `json:"errorMsg"`
+	Strategy    string `json:"strategy"`
 }
 
 type MergeInfo struct {
@@ -69,6 +76,16 @@ func (repo *Repository) getMergeInfo(ourBranch string, theirBranch string) (*Mer
 	if err != nil {
 		return nil, err
 	}
+	info, err := repo.getCommitMergeInfo(ourCommit, theirBranch)
+	if err != nil {
+		return nil, err
+	}
+	info.OurBranch = ourBranch
+	return info, nil
+}
+
+// getCommitMergeInfo 将指定提交合并到theirBranch的信息
+func (repo *Repository) getCommitMergeInfo(ourCommit *Commit, theirBranch string) (*MergeInfo, error) {
 	theirCommit, err := repo.GetBranchCommit(theirBranch)
 	if err != nil {
 		return nil, err
@@ -113,7 +130,6 @@ func (repo *Repository) getMergeInfo(ourBranch string, theirBranch string) (*Mer
 	}
 
 	return &MergeInfo{
-		OurBranch:   ourBranch,
 		TheirBranch: theirBranch,
 		OurCommit:   ourCommit,
 		TheirCommit: theirCommit,
@@ -125,17 +141,25 @@ func (repo *Repository) getMergeInfo(ourBranch string, theirBranch string) (*Mer
 
 }
 func (repo *Repository) GetMergeStatus(ourBranch string, theirBranch string) (*MergeStatusInfo, error) {
+	return repo.GetMergeStatusWithStrategy(ourBranch, theirBranch, apistructs.MergeStrategyMerge)
+}
+
+// GetMergeStatusWithStrategy 按合并方式检查是否可以合并
+// merge/squash 检查两个分支合并是否冲突, rebase 检查每个提交依次变基到目标分支是否冲突, fast_forward 要求目标分支没有新的提交
+func (repo *Repository) GetMergeStatusWithStrategy(ourBranch string, theirBranch string, strategy apistructs.MergeStrategy) (*MergeStatusInfo, error) {
 
 	info, err := repo.getMergeInfo(ourBranch, theirBranch)
 	if err != nil {
 		return &MergeStatusInfo{
 			HasError: true,
 			ErrorMsg: err.Error(),
+			Strategy: strategy.String(),
 		}, nil
 	}
 
 	result := &MergeStatusInfo{
 		HasConflict: false,
+		Strategy:    strategy.String(),
 	}
 	//没有commits差异 认已经合并
 	commitsCount, err := repo.CommitsCountBetween(info.OurCommit, info.BaseCommit)
@@ -152,23 +176,53 @@ func (repo *Repository) GetMergeStatus(ourBranch string, theirBranch string) (*M
 		return nil, err
 	}
 
-	options, _ := git.DefaultMergeOptions()
-	index, err := rawRepo.MergeTrees(info.BaseTree, info.OurTree, info.TheirTree, &options)
-	if err != nil {
-		return nil, err
+	switch strategy {
+	case apistructs.MergeStrategyMerge, apistructs.MergeStrategySquash:
+		options, _ := git.DefaultMergeOptions()
+		index, err := rawRepo.MergeTrees(info.BaseTree, info.OurTree, info.TheirTree, &options)
+		if err != nil {
+			return nil, err
+		}
+		result.HasConflict = index.HasConflicts()
+	case apistructs.MergeStrategyRebase:
+		if info.BaseCommit.ID == info.TheirCommit.ID {
+			return result, nil
+		}
+		hasMerges, err := repo.hasMergeCommits(info)
+		if err != nil {
+			return nil, err
+		}
+		if hasMerges {
+			result.HasConflict = true
+			result.ErrorMsg = ErrRebaseWithMergeCommits.Error()
+			return result, nil
+		}
+		_, hasConflict, err := repo.replayCommits(info, nil)
+		if err != nil {
+			return nil, err
+		}
+		result.HasConflict = hasConflict
+	case apistructs.MergeStrategyFastForward:
+		if info.BaseCommit.ID != info.TheirCommit.ID {
+			result.HasConflict = true
+			result.ErrorMsg = "目标分支有新的提交,无法快进合并"
+		}
+	default:
+		return nil, errors.New("invalid merge strategy: " + strategy.String())
 	}
-	result.HasConflict = index.HasConflicts()
 
 	return result, nil
 }
 
 func (repo *Repository) Merge(ourBranch string, theirBranch string, signature *Signature, message string) (*Commit, error) {
-
 	info, err := repo.getMergeInfo(ourBranch, theirBranch)
 	if err != nil {
 		return nil, err
 	}
+	return repo.merge(info, signature, message)
+}
 
+func (repo *Repository) merge(info *MergeInfo, signature *Signature, message string) (*Commit, error) {
 	rawRepo, err := repo.GetRawRepo()
 	if err != nil {
 		return nil, err
@@ -220,7 +274,7 @@ func (repo *Repository) Merge(ourBranch string, theirBranch string, signature *S
 	if err != nil {
 		return nil, err
 	}
-	newOid, err := rawRepo.CreateCommit(BRANCH_PREFIX+theirBranch, sig, sig, message, newTree, parentCommit, parentCommit2)
+	newOid, err := rawRepo.CreateCommit(BRANCH_PREFIX+info.TheirBranch, sig, sig, message, newTree, parentCommit, parentCommit2)
 
 	if err != nil {
 		return nil, err
@@ -228,3 +282,221 @@ func (repo *Repository) Merge(ourBranch string, theirBranch string, signature *S
 	return repo.GetCommit(newOid.String())
 
 }
+
+// MergeWithStrategy 按合并方式将ourBranch合并到theirBranch
+func (repo *Repository) MergeWithStrategy(ourBranch string, theirBranch string, strategy apistructs.MergeStrategy, signature *Signature, message string) (*Commit, error) {
+	ourCommit, err := repo.GetBranchCommit(ourBranch)
+	if err != nil {
+		return nil, err
+	}
+	return repo.MergeCommitWithStrategy(ourCommit.ID, theirBranch, strategy, signature, message)
+}
+
+// MergeCommitWithStrategy 按合并方式将指定提交合并到theirBranch, 合并的内容不受ourBranch之后新提交的影响
+func (repo *Repository) MergeCommitWithStrategy(ourCommitID string, theirBranch string, strategy apistructs.MergeStrategy, signature *Signature, message string) (*Commit, error) {
+	ourCommit, err := repo.GetCommit(ourCommitID)
+	if err != nil {
+		return nil, err
+	}
+	info, err := repo.getCommitMergeInfo(ourCommit, theirBranch)
+	if err != nil {
+		return nil, err
+	}
+	switch strategy {
+	case apistructs.MergeStrategyMerge:
+		return repo.merge(info, signature, message)
+	case apistructs.MergeStrategySquash:
+		return repo.squashMerge(info, signature, message)
+	case apistructs.MergeStrategyRebase:
+		return repo.rebaseMerge(info, signature)
+	case apistructs.MergeStrategyFastForward:
+		return repo.fastForwardMerge(info)
+	default:
+		return nil, errors.New("invalid merge strategy: " + strategy.String())
+	}
+}
+
+// squashMerge 将ourBranch的所有改动压缩成一个提交, 提交到theirBranch
+func (repo *Repository) squashMerge(info *MergeInfo, signature *Signature, message string) (*Commit, error) {
+	rawRepo, err := repo.GetRawRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := git.DefaultMergeOptions()
+	if err != nil {
+		return nil, err
+	}
+	index, err := rawRepo.MergeTrees(info.BaseTree, info.OurTree, info.TheirTree, &options)
+	if err != nil {
+		return nil, err
+	}
+	if index.HasConflicts() {
+		return nil, errors.New("has conflict")
+	}
+	newTreeOid, err := index.WriteTreeTo(rawRepo)
+	if err != nil {
+		return nil, err
+	}
+	newTree, err := rawRepo.LookupTree(newTreeOid)
+	if err != nil {
+		return nil, err
+	}
+
+	sig := &git.Signature{
+		Name:  signature.Name,
+		Email: signature.Email,
+		When:  signature.When,
+	}
+
+	parentOid, err := git.NewOid(info.TheirCommit.ID)
+	if err != nil {
+		return nil, err
+	}
+	parentCommit, err := rawRepo.LookupCommit(parentOid)
+	if err != nil {
+		return nil, err
+	}
+	newOid, err := rawRepo.CreateCommit(BRANCH_PREFIX+info.TheirBranch, sig, sig, message, newTree, parentCommit)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetCommit(newOid.String())
+}
+
+// rebaseMerge 将ourBranch的提交依次变基到theirBranch, 保留原作者, 提交者为合并人
+func (repo *Repository) rebaseMerge(info *MergeInfo, signature *Signature) (*Commit, error) {
+	// 目标分支没有新的提交, 直接快进
+	if info.BaseCommit.ID == info.TheirCommit.ID {
+		return repo.updateBranch(info.TheirBranch, info.OurCommit.ID, info.TheirCommit.ID)
+	}
+	hasMerges, err := repo.hasMergeCommits(info)
+	if err != nil {
+		return nil, err
+	}
+	if hasMerges {
+		return nil, ErrRebaseWithMergeCommits
+	}
+
+	sig := &git.Signature{
+		Name:  signature.Name,
+		Email: signature.Email,
+		When:  signature.When,
+	}
+	head, hasConflict, err := repo.replayCommits(info, sig)
+	if err != nil {
+		return nil, err
+	}
+	if hasConflict {
+		return nil, errors.New("has conflict")
+	}
+	return repo.updateBranch(info.TheirBranch, head.String(), info.TheirCommit.ID)
+}
+
+// fastForwardMerge 将theirBranch快进到ourBranch
+func (repo *Repository) fastForwardMerge(info *MergeInfo) (*Commit, error) {
+	if info.BaseCommit.ID != info.TheirCommit.ID {
+		return nil, errors.New("can not fast-forward")
+	}
+	return repo.updateBranch(info.TheirBranch, info.OurCommit.ID, info.TheirCommit.ID)
+}
+
+// hasMergeCommits ourBranch独有的提交中是否包含merge提交
+func (repo *Repository) hasMergeCommits(info *MergeInfo) (bool, error) {
+	stdout, err := NewCommand("rev-list", "--merges", "--count",
+		info.TheirCommit.ID+".."+info.OurCommit.ID).RunInDir(repo.DiskPath())
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(stdout) != "0", nil
+}
+
+// replayCommits 类似cherry-pick, 将ourBranch独有的提交依次应用到theirBranch上
+// 不支持merge提交, 调用前需通过hasMergeCommits检查
+// committer为nil时只检查是否冲突, 不创建提交
+func (repo *Repository) replayCommits(info *MergeInfo, committer *git.Signature) (*git.Oid, bool, error) {
+	rawRepo, err := repo.GetRawRepo()
+	if err != nil {
+		return nil, false, err
+	}
+	stdout, err := NewCommand("rev-list", "--reverse",
+		info.TheirCommit.ID+".."+info.OurCommit.ID).RunInDir(repo.DiskPath())
+	if err != nil {
+		return nil, false, err
+	}
+
+	headOid, err := git.NewOid(info.TheirCommit.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	head, err := rawRepo.LookupCommit(headOid)
+	if err != nil {
+		return nil, false, err
+	}
+	headTree := info.TheirTree
+
+	options, err := git.DefaultMergeOptions()
+	if err != nil {
+		return nil, false, err
+	}
+	for _, sha := range strings.Fields(stdout) {
+		oid, err := git.NewOid(sha)
+		if err != nil {
+			return nil, false, err
+		}
+		commit, err := rawRepo.LookupCommit(oid)
+		if err != nil {
+			return nil, false, err
+		}
+		if commit.ParentCount() > 1 {
+			return nil, false, ErrRebaseWithMergeCommits
+		}
+		tree, err := commit.Tree()
+		if err != nil {
+			return nil, false, err
+		}
+		var parentTree *git.Tree
+		if commit.ParentCount() > 0 {
+			parentTree, err = commit.Parent(0).Tree()
+			if err != nil {
+				return nil, false, err
+			}
+		}
+		index, err := rawRepo.MergeTrees(parentTree, headTree, tree, &options)
+		if err != nil {
+			return nil, false, err
+		}
+		if index.HasConflicts() {
+			return nil, true, nil
+		}
+		treeOid, err := index.WriteTreeTo(rawRepo)
+		if err != nil {
+			return nil, false, err
+		}
+		headTree, err = rawRepo.LookupTree(treeOid)
+		if err != nil {
+			return nil, false, err
+		}
+		if committer == nil {
+			continue
+		}
+		newOid, err := rawRepo.CreateCommit("", commit.Author(), committer, commit.Message(), headTree, head)
+		if err != nil {
+			return nil, false, err
+		}
+		head, err = rawRepo.LookupCommit(newOid)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return head.Id(), false, nil
+}
+
+// updateBranch 仅当分支仍指向oldCommitID时更新到newCommitID
+func (repo *Repository) updateBranch(branch string, newCommitID string, oldCommitID string) (*Commit, error) {
+	_, err := NewCommand("update-ref", BRANCH_PREFIX+branch, newCommitID, oldCommitID).RunInDir(repo.DiskPath())
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetCommit(newCommitID)
+}
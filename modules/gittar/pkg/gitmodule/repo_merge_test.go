@@ -0,0 +1,227 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !codeanalysis
+
+package gitmodule
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	git "github.com/libgit2/git2go/v30"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+var testMergeSignature = &Signature{
+	Name:  "merger",
+	Email: "merger@erda.cloud",
+	When:  time.Date(2022, 01, 21, 10, 0, 0, 0, time.UTC),
+}
+
+// createMergeTestRepo 创建裸仓库, master 和 feature 从同一个提交分叉:
+// master: base - m1
+// feature: base - f1 - f2
+func createMergeTestRepo(t *testing.T) (*Repository, *git.Repository) {
+	dir, err := ioutil.TempDir("", "merge")
+	checkFatal(t, err)
+	raw, err := git.InitRepository(dir, true)
+	checkFatal(t, err)
+
+	base := commitTestFile(t, raw, "master", "base.txt", "base\n")
+	commitTestFile(t, raw, "master", "master.txt", "master\n", base)
+	f1 := commitTestFile(t, raw, "feature", "f1.txt", "f1\n", base)
+	commitTestFile(t, raw, "feature", "f2.txt", "f2\n", f1)
+
+	return &Repository{RootPath: filepath.Dir(dir), Path: filepath.Base(dir)}, raw
+}
+
+// commitTestFile 在parents[0]的tree上写入文件并提交到分支
+func commitTestFile(t *testing.T, raw *git.Repository, branch, name, content string, parents ...*git.Commit) *git.Commit {
+	var (
+		builder *git.TreeBuilder
+		err     error
+	)
+	if len(parents) > 0 {
+		tree, err := parents[0].Tree()
+		checkFatal(t, err)
+		builder, err = raw.TreeBuilderFromTree(tree)
+		checkFatal(t, err)
+	} else {
+		builder, err = raw.TreeBuilder()
+		checkFatal(t, err)
+	}
+	blob, err := raw.CreateBlobFromBuffer([]byte(content))
+	checkFatal(t, err)
+	checkFatal(t, builder.Insert(name, blob, git.FilemodeBlob))
+	treeID, err := builder.Write()
+	checkFatal(t, err)
+	tree, err := raw.LookupTree(treeID)
+	checkFatal(t, err)
+
+	author := &git.Signature{Name: "author", Email: "author@erda.cloud", When: time.Now()}
+	oid, err := raw.CreateCommit("", author, author, "add "+name, tree, parents...)
+	checkFatal(t, err)
+	_, err = raw.References.Create(BRANCH_PREFIX+branch, oid, true, "")
+	checkFatal(t, err)
+	commit, err := raw.LookupCommit(oid)
+	checkFatal(t, err)
+	return commit
+}
+
+func branchTestCommit(t *testing.T, raw *git.Repository, branch string) *git.Commit {
+	ref, err := raw.References.Lookup(BRANCH_PREFIX + branch)
+	checkFatal(t, err)
+	commit, err := raw.LookupCommit(ref.Target())
+	checkFatal(t, err)
+	return commit
+}
+
+func assertTreeFiles(t *testing.T, commit *git.Commit, files ...string) {
+	tree, err := commit.Tree()
+	checkFatal(t, err)
+	assert.Equal(t, uint64(len(files)), tree.EntryCount())
+	for _, f := range files {
+		assert.NotNil(t, tree.EntryByName(f), f)
+	}
+}
+
+func cleanupMergeTestRepo(raw *git.Repository) {
+	os.RemoveAll(raw.Path())
+	raw.Free()
+}
+
+func TestRepository_MergeWithStrategy_Merge(t *testing.T) {
+	repo, raw := createMergeTestRepo(t)
+	defer cleanupMergeTestRepo(raw)
+
+	commit, err := repo.MergeWithStrategy("feature", "master", apistructs.MergeStrategyMerge, testMergeSignature, "merge feature")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(commit.Parents))
+
+	head := branchTestCommit(t, raw, "master")
+	assert.Equal(t, commit.ID, head.Id().String())
+	assertTreeFiles(t, head, "base.txt", "master.txt", "f1.txt", "f2.txt")
+}
+
+func TestRepository_MergeWithStrategy_Squash(t *testing.T) {
+	repo, raw := createMergeTestRepo(t)
+	defer cleanupMergeTestRepo(raw)
+	oldHead := branchTestCommit(t, raw, "master")
+
+	_, err := repo.MergeWithStrategy("feature", "master", apistructs.MergeStrategySquash, testMergeSignature, "squash feature")
+	assert.NoError(t, err)
+
+	head := branchTestCommit(t, raw, "master")
+	assert.Equal(t, uint(1), head.ParentCount())
+	assert.Equal(t, oldHead.Id().String(), head.ParentId(0).String())
+	assert.Equal(t, "squash feature", head.Message())
+	assertTreeFiles(t, head, "base.txt", "master.txt", "f1.txt", "f2.txt")
+}
+
+func TestRepository_MergeWithStrategy_Rebase(t *testing.T) {
+	repo, raw := createMergeTestRepo(t)
+	defer cleanupMergeTestRepo(raw)
+	oldHead := branchTestCommit(t, raw, "master")
+
+	status, err := repo.GetMergeStatusWithStrategy("feature", "master", apistructs.MergeStrategyRebase)
+	assert.NoError(t, err)
+	assert.False(t, status.HasConflict)
+
+	_, err = repo.MergeWithStrategy("feature", "master", apistructs.MergeStrategyRebase, testMergeSignature, "")
+	assert.NoError(t, err)
+
+	// master: base - m1 - f1' - f2'
+	f2 := branchTestCommit(t, raw, "master")
+	assert.Equal(t, "add f2.txt", f2.Message())
+	assert.Equal(t, "author", f2.Author().Name)
+	assert.Equal(t, testMergeSignature.Name, f2.Committer().Name)
+	assertTreeFiles(t, f2, "base.txt", "master.txt", "f1.txt", "f2.txt")
+	f1 := f2.Parent(0)
+	assert.Equal(t, "add f1.txt", f1.Message())
+	assert.Equal(t, oldHead.Id().String(), f1.ParentId(0).String())
+}
+
+func TestRepository_MergeWithStrategy_RebaseWithMergeCommits(t *testing.T) {
+	repo, raw := createMergeTestRepo(t)
+	defer cleanupMergeTestRepo(raw)
+	oldHead := branchTestCommit(t, raw, "master")
+
+	// feature 合并了另一个分支, 变基会丢弃该merge提交
+	feature := branchTestCommit(t, raw, "feature")
+	other := commitTestFile(t, raw, "other", "other.txt", "other\n", feature.Parent(0))
+	commitTestFile(t, raw, "feature", "other.txt", "other\n", feature, other)
+
+	status, err := repo.GetMergeStatusWithStrategy("feature", "master", apistructs.MergeStrategyRebase)
+	assert.NoError(t, err)
+	assert.True(t, status.HasConflict)
+	assert.Equal(t, ErrRebaseWithMergeCommits.Error(), status.ErrorMsg)
+
+	_, err = repo.MergeWithStrategy("feature", "master", apistructs.MergeStrategyRebase, testMergeSignature, "")
+	assert.Equal(t, ErrRebaseWithMergeCommits, err)
+	assert.Equal(t, oldHead.Id().String(), branchTestCommit(t, raw, "master").Id().String())
+}
+
+func TestRepository_MergeWithStrategy_FastForward(t *testing.T) {
+	repo, raw := createMergeTestRepo(t)
+	defer cleanupMergeTestRepo(raw)
+
+	// master有新的提交, 无法快进
+	status, err := repo.GetMergeStatusWithStrategy("feature", "master", apistructs.MergeStrategyFastForward)
+	assert.NoError(t, err)
+	assert.True(t, status.HasConflict)
+	_, err = repo.MergeWithStrategy("feature", "master", apistructs.MergeStrategyFastForward, testMergeSignature, "")
+	assert.Error(t, err)
+
+	// 将master合并到feature后可以快进
+	_, err = repo.MergeWithStrategy("master", "feature", apistructs.MergeStrategyMerge, testMergeSignature, "merge master")
+	assert.NoError(t, err)
+	feature := branchTestCommit(t, raw, "feature")
+
+	status, err = repo.GetMergeStatusWithStrategy("feature", "master", apistructs.MergeStrategyFastForward)
+	assert.NoError(t, err)
+	assert.False(t, status.HasConflict)
+	_, err = repo.MergeWithStrategy("feature", "master", apistructs.MergeStrategyFastForward, testMergeSignature, "")
+	assert.NoError(t, err)
+	assert.Equal(t, feature.Id().String(), branchTestCommit(t, raw, "master").Id().String())
+}
+
+func TestRepository_MergeCommitWithStrategy(t *testing.T) {
+	repo, raw := createMergeTestRepo(t)
+	defer cleanupMergeTestRepo(raw)
+
+	// 审批后feature有新的提交, 只合并审批的提交
+	approved := branchTestCommit(t, raw, "feature")
+	commitTestFile(t, raw, "feature", "f3.txt", "f3\n", approved)
+
+	commit, err := repo.MergeCommitWithStrategy(approved.Id().String(), "master", apistructs.MergeStrategyMerge, testMergeSignature, "merge feature")
+	assert.NoError(t, err)
+	assert.Equal(t, approved.Id().String(), commit.Parents[1])
+
+	head := branchTestCommit(t, raw, "master")
+	assertTreeFiles(t, head, "base.txt", "master.txt", "f1.txt", "f2.txt")
+}
+
+func TestRepository_MergeWithStrategy_Invalid(t *testing.T) {
+	repo, raw := createMergeTestRepo(t)
+	defer cleanupMergeTestRepo(raw)
+
+	_, err := repo.MergeWithStrategy("feature", "master", apistructs.MergeStrategy("octopus"), testMergeSignature, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lfs git lfs batch api 和 basic transfer 的协议定义及对象存储
+// https://github.com/git-lfs/git-lfs/blob/main/docs/api/batch.md
+package lfs
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	MediaType = "application/vnd.git-lfs+json"
+
+	OperationDownload = "download"
+	OperationUpload   = "upload"
+
+	TransferBasic = "basic"
+
+	// MaxPointerSize pointer文件的最大长度
+	MaxPointerSize = 1024
+
+	pointerVersion = "version https://git-lfs.github.com/spec/v1"
+)
+
+var oidRegexp = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ValidOid oid为sha256的十六进制小写字符串
+func ValidOid(oid string) bool {
+	return oidRegexp.MatchString(oid)
+}
+
+// BatchRequest batch api请求
+type BatchRequest struct {
+	Operation string     `json:"operation"`
+	Transfers []string   `json:"transfers,omitempty"`
+	Ref       *Ref       `json:"ref,omitempty"`
+	Objects   []*Pointer `json:"objects"`
+	HashAlgo  string     `json:"hash_algo,omitempty"`
+}
+
+type Ref struct {
+	Name string `json:"name"`
+}
+
+// Pointer lfs对象的oid和大小, 也是仓库中pointer文件的内容
+type Pointer struct {
+	Oid  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+// BatchResponse batch api响应
+type BatchResponse struct {
+	Transfer string            `json:"transfer,omitempty"`
+	Objects  []*ObjectResponse `json:"objects"`
+	HashAlgo string            `json:"hash_algo,omitempty"`
+}
+
+type ObjectResponse struct {
+	Pointer
+	Authenticated bool               `json:"authenticated,omitempty"`
+	Actions       map[string]*Action `json:"actions,omitempty"`
+	Error         *ObjectError       `json:"error,omitempty"`
+}
+
+type Action struct {
+	Href      string            `json:"href"`
+	Header    map[string]string `json:"header,omitempty"`
+	ExpiresIn int               `json:"expires_in,omitempty"`
+}
+
+type ObjectError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ErrorResponse batch和basic transfer接口的错误响应
+type ErrorResponse struct {
+	Message string `json:"message"`
+}
+
+// ParsePointer 解析pointer文件, 不是合法的pointer时返回false
+//
+//	version https://git-lfs.github.com/spec/v1
+//	oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393
+//	size 12345
+func ParsePointer(content []byte) (*Pointer, bool) {
+	if len(content) > MaxPointerSize || !bytes.HasPrefix(content, []byte(pointerVersion+"\n")) {
+		return nil, false
+	}
+	pointer := &Pointer{Size: -1}
+	for _, line := range strings.Split(string(content), "\n") {
+		kv := strings.SplitN(line, " ", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "oid":
+			pointer.Oid = strings.TrimPrefix(kv[1], "sha256:")
+		case "size":
+			size, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return nil, false
+			}
+			pointer.Size = size
+		}
+	}
+	if !ValidOid(pointer.Oid) || pointer.Size < 0 {
+		return nil, false
+	}
+	return pointer, true
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lfs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testOid = "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393"
+
+func TestParsePointer(t *testing.T) {
+	pointer, ok := ParsePointer([]byte("version https://git-lfs.github.com/spec/v1\noid sha256:" + testOid + "\nsize 12345\n"))
+	if assert.True(t, ok) {
+		assert.Equal(t, testOid, pointer.Oid)
+		assert.Equal(t, int64(12345), pointer.Size)
+	}
+
+	_, ok = ParsePointer([]byte("version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize 12345\n"))
+	assert.False(t, ok)
+	_, ok = ParsePointer([]byte("version https://git-lfs.github.com/spec/v1\noid sha256:" + testOid + "\n"))
+	assert.False(t, ok)
+	_, ok = ParsePointer([]byte("package main\n"))
+	assert.False(t, ok)
+}
+
+func TestValidOid(t *testing.T) {
+	assert.True(t, ValidOid(testOid))
+	assert.False(t, ValidOid("../../etc/passwd"))
+	assert.False(t, ValidOid("4D7A214614AB2935C943F9E0FF69D22EADBB8F32B1258DAAA5E2CA24D17E2393"))
+}
+
+func TestLocalStorage(t *testing.T) {
+	root, err := ioutil.TempDir("", "lfs-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	file := filepath.Join(root, "upload")
+	assert.NoError(t, ioutil.WriteFile(file, []byte("content"), 0644))
+
+	s := NewLocalStorage(filepath.Join(root, "objects"))
+	assert.NoError(t, s.Put(1, testOid, file))
+	_, err = os.Stat(filepath.Join(root, "objects", "1", "4d", "7a", testOid))
+	assert.NoError(t, err)
+
+	rd, err := s.Open(1, testOid)
+	if assert.NoError(t, err) {
+		content, err := ioutil.ReadAll(rd)
+		rd.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, "content", string(content))
+	}
+
+	_, err = s.Open(2, testOid)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, s.Delete(1, testOid))
+	assert.NoError(t, s.Delete(1, testOid))
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lfs
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"github.com/erda-project/erda/pkg/cloudstorage"
+)
+
+// Storage lfs对象存储, 对象按仓库隔离, 便于统计配额和删除仓库
+type Storage interface {
+	// Put 保存已校验过的本地文件
+	Put(repoID int64, oid string, file string) error
+	Open(repoID int64, oid string) (io.ReadCloser, error)
+	Delete(repoID int64, oid string) error
+}
+
+// ObjectPath 对象的相对路径, 如 12/4d/7a/4d7a2146...
+func ObjectPath(repoID int64, oid string) string {
+	return path.Join(strconv.FormatInt(repoID, 10), oid[0:2], oid[2:4], oid)
+}
+
+type localStorage struct {
+	root string
+}
+
+// NewLocalStorage 使用本地磁盘存储
+func NewLocalStorage(root string) Storage {
+	return &localStorage{root: root}
+}
+
+func (s *localStorage) Put(repoID int64, oid string, file string) error {
+	target := filepath.Join(s.root, filepath.FromSlash(ObjectPath(repoID, oid)))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(file, target); err == nil {
+		return nil
+	}
+	// 跨设备时无法rename, 复制到临时文件后再rename保证写入的原子性
+	src, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmp := target + ".tmp"
+	dst, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, target)
+}
+
+func (s *localStorage) Open(repoID int64, oid string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.root, filepath.FromSlash(ObjectPath(repoID, oid))))
+}
+
+func (s *localStorage) Delete(repoID int64, oid string) error {
+	err := os.Remove(filepath.Join(s.root, filepath.FromSlash(ObjectPath(repoID, oid))))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+type cloudStorage struct {
+	client cloudstorage.Client
+	bucket string
+	prefix string
+}
+
+// NewCloudStorage 使用oss或minio存储
+func NewCloudStorage(client cloudstorage.Client, bucket, prefix string) Storage {
+	return &cloudStorage{client: client, bucket: bucket, prefix: prefix}
+}
+
+func (s *cloudStorage) objectName(repoID int64, oid string) string {
+	return path.Join(s.prefix, ObjectPath(repoID, oid))
+}
+
+func (s *cloudStorage) Put(repoID int64, oid string, file string) error {
+	_, err := s.client.UploadFile(s.bucket, s.objectName(repoID, oid), file)
+	return err
+}
+
+func (s *cloudStorage) Open(repoID int64, oid string) (io.ReadCloser, error) {
+	return s.client.OpenFile(s.bucket, s.objectName(repoID, oid))
+}
+
+func (s *cloudStorage) Delete(repoID int64, oid string) error {
+	return s.client.DeleteFile(s.bucket, s.objectName(repoID, oid))
+}
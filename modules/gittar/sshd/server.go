@@ -0,0 +1,169 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sshd 内嵌的ssh服务, 提供git-upload-pack和git-receive-pack
+package sshd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/modules/gittar/models"
+	"github.com/erda-project/erda/modules/gittar/uc"
+)
+
+const (
+	extUserID = "user-id"
+	extKeyID  = "key-id"
+)
+
+// Server ssh服务, 使用用户在gittar中添加的公钥认证
+type Server struct {
+	config *ssh.ServerConfig
+	svc    *models.Service
+	bundle *bundle.Bundle
+}
+
+// New 创建ssh服务, hostKeyPath不存在时自动生成ed25519 host key
+func New(db *models.DBClient, bdl *bundle.Bundle, hostKeyPath string) (*Server, error) {
+	signer, err := loadHostKey(hostKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ssh host key: %v", err)
+	}
+	s := &Server{
+		svc:    models.NewService(db, bdl),
+		bundle: bdl,
+	}
+	s.config = &ssh.ServerConfig{
+		PublicKeyCallback: s.authPublicKey,
+		ServerVersion:     "SSH-2.0-Gittar",
+	}
+	s.config.AddHostKey(signer)
+	return s, nil
+}
+
+// ListenAndServe 监听addr并处理ssh连接
+func (s *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("gittar ssh server listening on %s", addr)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Temporary() {
+				logrus.Warnf("ssh accept error: %v", err)
+				continue
+			}
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *Server) authPublicKey(meta ssh.ConnMetadata, publicKey ssh.PublicKey) (*ssh.Permissions, error) {
+	key, err := s.svc.GetSSHKeyByPublicKey(publicKey)
+	if err != nil {
+		logrus.Debugf("ssh auth failed remote:%s fingerprint:%s err:%v",
+			meta.RemoteAddr(), ssh.FingerprintSHA256(publicKey), err)
+		return nil, errors.New("unknown public key")
+	}
+	return &ssh.Permissions{
+		Extensions: map[string]string{
+			extUserID: key.UserID,
+			extKeyID:  strconv.FormatInt(key.ID, 10),
+		},
+	}, nil
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+	sshConn, channels, requests, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		logrus.Debugf("ssh handshake failed remote:%s err:%v", conn.RemoteAddr(), err)
+		return
+	}
+	defer sshConn.Close()
+	go ssh.DiscardRequests(requests)
+
+	userID := sshConn.Permissions.Extensions[extUserID]
+	userInfoDto, err := uc.FindUserById(userID)
+	if err != nil {
+		logrus.Errorf("ssh get user %s from uc error: %v", userID, err)
+		return
+	}
+	user := &models.User{
+		Id:       userID,
+		Name:     userInfoDto.Username,
+		NickName: userInfoDto.NickName,
+		Email:    userInfoDto.Email,
+	}
+	keyID, _ := strconv.ParseInt(sshConn.Permissions.Extensions[extKeyID], 10, 64)
+	if err := s.svc.TouchSSHKey(keyID); err != nil {
+		logrus.Errorf("failed to update ssh key last used time: %v", err)
+	}
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			logrus.Errorf("ssh accept channel error: %v", err)
+			continue
+		}
+		go s.handleSession(channel, requests, user)
+	}
+}
+
+func loadHostKey(path string) (ssh.Signer, error) {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		if err != nil {
+			return nil, err
+		}
+		content = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(path, content, 0600); err != nil {
+			return nil, err
+		}
+		logrus.Infof("ssh host key generated: %s", path)
+	} else if err != nil {
+		return nil, err
+	}
+	return ssh.ParsePrivateKey(content)
+}
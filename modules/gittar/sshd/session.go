@@ -0,0 +1,245 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sshd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/gittar/conf"
+	"github.com/erda-project/erda/modules/gittar/helper"
+	"github.com/erda-project/erda/modules/gittar/models"
+	"github.com/erda-project/erda/modules/gittar/pkg/gitmodule"
+)
+
+const (
+	serviceUploadPack  = "upload-pack"
+	serviceReceivePack = "receive-pack"
+)
+
+// handleSession 处理一个session channel, 只支持git命令的exec请求
+func (s *Server) handleSession(channel ssh.Channel, requests <-chan *ssh.Request, user *models.User) {
+	defer channel.Close()
+	var gitProtocol string
+	for req := range requests {
+		switch req.Type {
+		case "env":
+			var env struct{ Name, Value string }
+			if err := ssh.Unmarshal(req.Payload, &env); err == nil && env.Name == "GIT_PROTOCOL" {
+				gitProtocol = env.Value
+			}
+			req.Reply(true, nil)
+		case "exec":
+			var payload struct{ Command string }
+			if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+			code := s.runCommand(channel, user, payload.Command, gitProtocol)
+			sendExitStatus(channel, code)
+			return
+		case "shell":
+			req.Reply(true, nil)
+			fmt.Fprintf(channel.Stderr(), "Hi %s! You've successfully authenticated, but gittar does not provide shell access.\n", user.Name)
+			sendExitStatus(channel, 1)
+			return
+		default:
+			req.Reply(false, nil)
+		}
+	}
+}
+
+func (s *Server) runCommand(channel ssh.Channel, user *models.User, command, gitProtocol string) int {
+	service, repoPath, err := parseGitCommand(command)
+	if err != nil {
+		fmt.Fprintf(channel.Stderr(), "%v\n", err)
+		return 1
+	}
+	repository, err := s.openRepository(repoPath, user)
+	if err != nil {
+		logrus.Infof("ssh %s repo:%s user:%s err:%v", service, repoPath, user.Name, err)
+		fmt.Fprintf(channel.Stderr(), "%v\n", err)
+		return 1
+	}
+	logrus.Infof("ssh %s repo:%s user:%s", service, repoPath, user.Name)
+
+	if service == serviceReceivePack {
+		err = s.receivePack(channel, repository, user, gitProtocol)
+	} else {
+		err = runGit(channel, channel, channel.Stderr(), gitProtocol, service, repository.DiskPath())
+	}
+	if err != nil {
+		logrus.Errorf("ssh %s repo:%s user:%s err:%v", service, repoPath, user.Name, err)
+		return 1
+	}
+	return 0
+}
+
+// receivePack 与http推送一致, 先读取推送命令做权限校验, 再交给receive-pack处理, 完成后触发推送事件
+func (s *Server) receivePack(channel ssh.Channel, repository *gitmodule.Repository, user *models.User, gitProtocol string) error {
+	isLocked, err := s.svc.GetRepoLocked(repository.ProjectId, repository.ApplicationId)
+	if err != nil {
+		return err
+	}
+	if isLocked {
+		fmt.Fprintln(channel.Stderr(), "repo locked")
+		return errors.New("repo locked")
+	}
+
+	err = runGit(channel, nil, channel.Stderr(), gitProtocol,
+		serviceReceivePack, "--stateless-rpc", "--advertise-refs", repository.DiskPath())
+	if err != nil {
+		return err
+	}
+
+	header, err := helper.ReadGitSendPackHeader(ioutil.NopCloser(channel))
+	if err != nil {
+		return err
+	}
+	pushEvents := helper.ParsePushEvents(header, user)
+	if len(pushEvents) == 0 {
+		// 客户端没有需要推送的内容
+		return nil
+	}
+	if ref, err := helper.CheckPushPermission(pushEvents, repository, user, s.svc); err != nil {
+		channel.Write(helper.NewReportStatus("unpack ok", "ng "+ref, err.Error()))
+		return nil
+	}
+
+	err = runGit(channel, io.MultiReader(bytes.NewReader(header), channel), channel.Stderr(), gitProtocol,
+		serviceReceivePack, "--stateless-rpc", repository.DiskPath())
+	if err != nil {
+		return err
+	}
+	go helper.PostReceive(pushEvents, repository, user, s.svc, s.bundle)
+	return nil
+}
+
+// openRepository 查找仓库并校验用户是否有应用的访问权限
+func (s *Server) openRepository(repoPath string, user *models.User) (*gitmodule.Repository, error) {
+	repo, err := s.findRepo(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("repository %s not found", repoPath)
+	}
+	if repo.IsExternal {
+		return nil, errors.New("external repository is not supported over ssh")
+	}
+	permission, err := s.bundle.ScopeRoleAccess(user.Id, &apistructs.ScopeRoleAccessRequest{
+		Scope: apistructs.Scope{
+			Type: apistructs.AppScope,
+			ID:   strconv.FormatInt(repo.AppID, 10),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !permission.Access {
+		return nil, errors.New("no permission to access")
+	}
+
+	repository, err := gitmodule.OpenRepositoryWithInit(conf.RepoRoot(), repo.Path)
+	if err != nil {
+		return nil, err
+	}
+	repository.ID = repo.ID
+	repository.ProjectId = repo.ProjectID
+	repository.ProjectName = repo.ProjectName
+	repository.ApplicationId = repo.AppID
+	repository.ApplicationName = repo.AppName
+	repository.OrgId = repo.OrgID
+	repository.Size = repo.Size
+	repository.Url = conf.GittarUrl() + "/" + repo.Path
+	repository.Bundle = s.bundle
+	return repository, nil
+}
+
+// findRepo 支持和http相同的仓库路径, org-project/app 或 org/dop/project/app
+func (s *Server) findRepo(repoPath string) (*models.Repo, error) {
+	parts := strings.Split(repoPath, "/")
+	switch {
+	case len(parts) == 2:
+		return s.svc.GetRepoByPath(repoPath)
+	case len(parts) == 4 && parts[1] == "dop":
+		org, err := s.bundle.GetOrg(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		return s.svc.GetRepoByNames(int64(org.ID), parts[2], parts[3])
+	default:
+		return nil, fmt.Errorf("invalid repository path: %s", repoPath)
+	}
+}
+
+// parseGitCommand 解析 git-upload-pack '/org-project/app.git' 格式的命令
+func parseGitCommand(command string) (string, string, error) {
+	fields := strings.SplitN(strings.TrimSpace(command), " ", 2)
+	if len(fields) != 2 {
+		return "", "", fmt.Errorf("unsupported command: %s", command)
+	}
+	var service string
+	switch fields[0] {
+	case "git-" + serviceUploadPack:
+		service = serviceUploadPack
+	case "git-" + serviceReceivePack:
+		service = serviceReceivePack
+	default:
+		return "", "", fmt.Errorf("unsupported command: %s", fields[0])
+	}
+	repoPath := strings.Trim(strings.TrimSpace(fields[1]), "'\"")
+	repoPath = strings.TrimSuffix(strings.Trim(path.Clean("/"+repoPath), "/"), ".git")
+	if repoPath == "" {
+		return "", "", errors.New("repository path is empty")
+	}
+	return service, repoPath, nil
+}
+
+// runGit 执行git命令, stdin不等待客户端关闭, 进程退出即返回
+func runGit(stdout io.Writer, stdin io.Reader, stderr io.Writer, gitProtocol string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	if len(gitProtocol) > 0 {
+		cmd.Env = append(os.Environ(), "GIT_PROTOCOL="+gitProtocol)
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if stdin != nil {
+		pipe, err := cmd.StdinPipe()
+		if err != nil {
+			return err
+		}
+		go func() {
+			io.Copy(pipe, stdin)
+			pipe.Close()
+		}()
+	}
+	return cmd.Run()
+}
+
+func sendExitStatus(channel ssh.Channel, code int) {
+	status := struct{ Status uint32 }{uint32(code)}
+	channel.SendRequest("exit-status", false, ssh.Marshal(&status))
+}
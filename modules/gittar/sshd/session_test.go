@@ -0,0 +1,51 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sshd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGitCommand(t *testing.T) {
+	tests := []struct {
+		command  string
+		service  string
+		repoPath string
+		wantErr  bool
+	}{
+		{command: "git-upload-pack '/erda-dice/gittar.git'", service: "upload-pack", repoPath: "erda-dice/gittar"},
+		{command: "git-receive-pack 'erda-dice/gittar'", service: "receive-pack", repoPath: "erda-dice/gittar"},
+		{command: "git-upload-pack '/erda/dop/dice/gittar.git'", service: "upload-pack", repoPath: "erda/dop/dice/gittar"},
+		{command: "git-upload-pack '/../erda-dice/gittar.git'", service: "upload-pack", repoPath: "erda-dice/gittar"},
+		{command: "git-upload-archive '/erda-dice/gittar.git'", wantErr: true},
+		{command: "ls -al", wantErr: true},
+		{command: "git-upload-pack", wantErr: true},
+		{command: "git-upload-pack '/'", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			service, repoPath, err := parseGitCommand(tt.command)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.service, service)
+			assert.Equal(t, tt.repoPath, repoPath)
+		})
+	}
+}
@@ -16,6 +16,8 @@
 package conf
 
 import (
+	"time"
+
 	"github.com/erda-project/erda/pkg/envconf"
 	"github.com/erda-project/erda/pkg/kms/kmstypes"
 )
@@ -26,6 +28,9 @@ type Conf struct {
 	Debug         bool               `env:"DEBUG" default:"false"`
 	KmsStoreKind  kmstypes.StoreKind `env:"KMS_STORE_KIND" default:"ETCD"`
 	EtcdEndpoints string             `env:"ETCD_ENDPOINTS" required:"false"`
+
+	// KeyRotationCheckInterval 检查密钥是否需要自动轮转的间隔
+	KeyRotationCheckInterval time.Duration `env:"KEY_ROTATION_CHECK_INTERVAL" default:"1m"`
 }
 
 var cfg Conf
@@ -58,3 +63,7 @@ func KmsStoreKind() kmstypes.StoreKind {
 func EtcdEndpoints() string {
 	return cfg.EtcdEndpoints
 }
+
+func KeyRotationCheckInterval() time.Duration {
+	return cfg.KeyRotationCheckInterval
+}
@@ -20,14 +20,21 @@ import (
 )
 
 var (
-	ErrCheckIdentity    = err("ErrCheckIdentity", "身份校验失败")
-	ErrParseRequest     = err("ErrParseRequest", "解析请求失败")
-	ErrCreateKey        = err("ErrCreateKey", "创建 KMS 用户主密钥失败")
-	ErrEncrypt          = err("ErrEncrypt", "对称加密失败")
-	ErrDecrypt          = err("ErrDecrypt", "对称解密失败")
-	ErrGenerateDataKey  = err("ErrGenerateDataKey", "生成数据加密密钥失败")
-	ErrRotateKeyVersion = err("ErrRotateKeyVersion", "轮转密钥版本失败")
-	ErrDescribeKey      = err("ErrDescribeKey", "查询用户主密钥失败")
+	ErrCheckIdentity        = err("ErrCheckIdentity", "身份校验失败")
+	ErrParseRequest         = err("ErrParseRequest", "解析请求失败")
+	ErrCreateKey            = err("ErrCreateKey", "创建 KMS 用户主密钥失败")
+	ErrEncrypt              = err("ErrEncrypt", "对称加密失败")
+	ErrDecrypt              = err("ErrDecrypt", "对称解密失败")
+	ErrGenerateDataKey      = err("ErrGenerateDataKey", "生成数据加密密钥失败")
+	ErrRotateKeyVersion     = err("ErrRotateKeyVersion", "轮转密钥版本失败")
+	ErrUpdateRotationPolicy = err("ErrUpdateRotationPolicy", "更新密钥轮转策略失败")
+	ErrReEncrypt            = err("ErrReEncrypt", "重新加密失败")
+	ErrDescribeKey          = err("ErrDescribeKey", "查询用户主密钥失败")
+	ErrGetPublicKey         = err("ErrGetPublicKey", "获取公钥失败")
+	ErrAsymmetricEncrypt    = err("ErrAsymmetricEncrypt", "非对称加密失败")
+	ErrAsymmetricDecrypt    = err("ErrAsymmetricDecrypt", "非对称解密失败")
+	ErrSign                 = err("ErrSign", "签名失败")
+	ErrVerify               = err("ErrVerify", "验签失败")
 )
 
 func err(template, defaultValue string) *errorresp.APIError {
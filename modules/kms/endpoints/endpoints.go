@@ -18,6 +18,7 @@ package endpoints
 import (
 	"net/http"
 
+	"github.com/erda-project/erda/bundle"
 	"github.com/erda-project/erda/pkg/http/httpserver"
 	"github.com/erda-project/erda/pkg/kms"
 )
@@ -25,6 +26,7 @@ import (
 // Endpoints 定义 endpoint 方法
 type Endpoints struct {
 	KmsMgr *kms.Manager
+	bdl    *bundle.Bundle
 }
 
 type Option func(*Endpoints)
@@ -46,6 +48,12 @@ func WithKmsManager(mgr *kms.Manager) Option {
 	}
 }
 
+func WithBundle(bdl *bundle.Bundle) Option {
+	return func(e *Endpoints) {
+		e.bdl = bdl
+	}
+}
+
 // Routes 返回 endpoints 的所有 endpoint 方法，也就是 route.
 func (e *Endpoints) Routes() []httpserver.Endpoint {
 	return []httpserver.Endpoint{
@@ -57,6 +65,13 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/kms/decrypt", Method: http.MethodPost, Handler: e.KmsDecrypt},
 		{Path: "/api/kms/generate-data-key", Method: http.MethodPost, Handler: e.KmsGenerateDataKey},
 		{Path: "/api/kms/rotate-key-version", Method: http.MethodPost, Handler: e.KmsRotateKeyVersion},
+		{Path: "/api/kms/update-rotation-policy", Method: http.MethodPost, Handler: e.KmsUpdateRotationPolicy},
+		{Path: "/api/kms/re-encrypt", Method: http.MethodPost, Handler: e.KmsReEncrypt},
 		{Path: "/api/kms/describe-key", Method: http.MethodGet, Handler: e.KmsRotateKeyVersion},
+		{Path: "/api/kms/get-public-key", Method: http.MethodGet, Handler: e.KmsGetPublicKey},
+		{Path: "/api/kms/asymmetric-encrypt", Method: http.MethodPost, Handler: e.KmsAsymmetricEncrypt},
+		{Path: "/api/kms/asymmetric-decrypt", Method: http.MethodPost, Handler: e.KmsAsymmetricDecrypt},
+		{Path: "/api/kms/sign", Method: http.MethodPost, Handler: e.KmsSign},
+		{Path: "/api/kms/verify", Method: http.MethodPost, Handler: e.KmsVerify},
 	}
 }
@@ -19,7 +19,9 @@ import (
 	"net/http"
 
 	"github.com/erda-project/erda/modules/kms/endpoints/apierrors"
+	"github.com/erda-project/erda/modules/kms/rotation"
 	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httputil"
 	"github.com/erda-project/erda/pkg/kms/kmstypes"
 )
 
@@ -87,10 +89,59 @@ func (e *Endpoints) KmsRotateKeyVersion(ctx context.Context, r *http.Request, va
 	if err != nil {
 		return apierrors.ErrRotateKeyVersion.InvalidParameter(err).ToResp(), nil
 	}
+	descResp, err := plugin.DescribeKey(ctx, &kmstypes.DescribeKeyRequest{KeyID: req.KeyID})
+	if err != nil {
+		return apierrors.ErrRotateKeyVersion.InvalidParameter(err).ToResp(), nil
+	}
 	rotateResp, err := plugin.RotateKeyVersion(ctx, &req)
+
+	// audit
+	var keyVersionID string
+	if rotateResp != nil {
+		keyVersionID = rotateResp.KeyMetadata.PrimaryKeyVersionID
+	}
+	e.audit(rotation.NewAuditRequest(req.KeyID, descResp.KeyMetadata.PrimaryKeyVersionID, keyVersionID,
+		rotation.TriggerManual, r.Header.Get(httputil.UserHeader), err))
+
 	if err != nil {
 		return apierrors.ErrRotateKeyVersion.InternalError(err).ToResp(), nil
 	}
 
 	return httpserver.OkResp(rotateResp)
 }
+
+func (e *Endpoints) KmsUpdateRotationPolicy(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req kmstypes.UpdateRotationPolicyRequest
+	if err := e.parseRequestBody(r, &req); err != nil {
+		return err.ToResp(), nil
+	}
+
+	plugin, err := e.getPluginByKeyID(req.KeyID)
+	if err != nil {
+		return apierrors.ErrUpdateRotationPolicy.InvalidParameter(err).ToResp(), nil
+	}
+	policyResp, err := plugin.UpdateRotationPolicy(ctx, &req)
+	if err != nil {
+		return apierrors.ErrUpdateRotationPolicy.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(policyResp)
+}
+
+func (e *Endpoints) KmsReEncrypt(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req kmstypes.ReEncryptRequest
+	if err := e.parseRequestBody(r, &req); err != nil {
+		return err.ToResp(), nil
+	}
+
+	plugin, err := e.getPluginByKeyID(req.KeyID)
+	if err != nil {
+		return apierrors.ErrReEncrypt.InvalidParameter(err).ToResp(), nil
+	}
+	reEncryptResp, err := plugin.ReEncrypt(ctx, &req)
+	if err != nil {
+		return apierrors.ErrReEncrypt.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(reEncryptResp)
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/kms/conf"
 	"github.com/erda-project/erda/modules/kms/endpoints/apierrors"
 	"github.com/erda-project/erda/modules/pkg/user"
@@ -74,3 +75,13 @@ func (e *Endpoints) checkIdentity(r *http.Request) (err error) {
 	}
 	return fmt.Errorf("not internal client")
 }
+
+// audit 创建审计事件，失败不影响请求结果
+func (e *Endpoints) audit(req *apistructs.AuditCreateRequest) {
+	if e.bdl == nil {
+		return
+	}
+	if err := e.bdl.CreateAuditEvent(req); err != nil {
+		logrus.Errorf("failed to create audit event, template: %s, err: %v", req.TemplateName, err)
+	}
+}
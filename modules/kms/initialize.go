@@ -18,8 +18,10 @@ package kms
 import (
 	"github.com/sirupsen/logrus"
 
+	"github.com/erda-project/erda/bundle"
 	"github.com/erda-project/erda/modules/kms/conf"
 	"github.com/erda-project/erda/modules/kms/endpoints"
+	"github.com/erda-project/erda/modules/kms/rotation"
 	"github.com/erda-project/erda/pkg/http/httpserver"
 	"github.com/erda-project/erda/pkg/kms"
 	"github.com/erda-project/erda/pkg/kms/stores/etcd"
@@ -41,7 +43,21 @@ func Initialize() error {
 		return err
 	}
 
-	ep := endpoints.New(endpoints.WithKmsManager(kmsMgr))
+	bdl := bundle.New(bundle.WithCoreServices())
+
+	ep := endpoints.New(
+		endpoints.WithKmsManager(kmsMgr),
+		endpoints.WithBundle(bdl),
+	)
+
+	// automatic key rotation
+	rotator := rotation.New(
+		rotation.WithKmsManager(kmsMgr),
+		rotation.WithStoreKind(conf.KmsStoreKind()),
+		rotation.WithInterval(conf.KeyRotationCheckInterval()),
+		rotation.WithBundle(bdl),
+	)
+	go rotator.Run()
 
 	server := httpserver.New(conf.ListenAddr())
 	server.RegisterEndpoint(ep.Routes())
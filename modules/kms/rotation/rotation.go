@@ -0,0 +1,247 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rotation 定时检查并自动轮转到期的 KMS 用户主密钥
+package rotation
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/pkg/dlock"
+	"github.com/erda-project/erda/pkg/kms"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+const (
+	// 多实例部署时只有抢到锁的实例进行轮转
+	lockKey = "/dice/kms/rotation"
+
+	// systemUserID 自动轮转的审计事件操作人
+	systemUserID = "1100"
+
+	TriggerAutomatic = "automatic"
+	TriggerManual    = "manual"
+
+	// maxRetryBackoff 轮转失败后重试的最大间隔
+	maxRetryBackoff = 24 * time.Hour
+)
+
+type Rotator struct {
+	mgr       *kms.Manager
+	storeKind kmstypes.StoreKind
+	interval  time.Duration
+	audit     func(*apistructs.AuditCreateRequest) error
+
+	// failures 轮转失败的密钥，到 nextRetry 之前不再重试，避免每次检查都产生失败的审计事件
+	failures map[string]*rotateFailure
+}
+
+type rotateFailure struct {
+	count     int
+	nextRetry time.Time
+}
+
+type Option func(*Rotator)
+
+// New 创建 Rotator 对象.
+func New(options ...Option) *Rotator {
+	r := &Rotator{interval: time.Minute, failures: make(map[string]*rotateFailure)}
+
+	for _, op := range options {
+		op(r)
+	}
+
+	return r
+}
+
+func WithKmsManager(mgr *kms.Manager) Option {
+	return func(r *Rotator) {
+		r.mgr = mgr
+	}
+}
+
+func WithStoreKind(kind kmstypes.StoreKind) Option {
+	return func(r *Rotator) {
+		r.storeKind = kind
+	}
+}
+
+// WithInterval 检查密钥是否到期的间隔
+func WithInterval(interval time.Duration) Option {
+	return func(r *Rotator) {
+		r.interval = interval
+	}
+}
+
+func WithBundle(bdl *bundle.Bundle) Option {
+	return func(r *Rotator) {
+		r.audit = bdl.CreateAuditEvent
+	}
+}
+
+// Run 抢锁后定时轮转到期的密钥，锁丢失后重新抢锁，阻塞调用
+func (r *Rotator) Run() {
+	for {
+		ctx, cancel := context.WithCancel(context.Background())
+		lock, err := dlock.New(lockKey, func() { cancel() })
+		if err != nil {
+			logrus.Errorf("[alert] failed to create kms rotation lock, err: %v", err)
+			cancel()
+			time.Sleep(r.interval)
+			continue
+		}
+		if err := lock.Lock(ctx); err != nil {
+			logrus.Errorf("failed to lock %s, err: %v", lockKey, err)
+			lock.Close()
+			cancel()
+			time.Sleep(r.interval)
+			continue
+		}
+		logrus.Infof("got kms rotation lock, begin to rotate keys")
+		r.loop(ctx)
+		if err := lock.UnlockAndClose(); err != nil {
+			logrus.Errorf("failed to unlock %s, err: %v", lockKey, err)
+		}
+		cancel()
+	}
+}
+
+func (r *Rotator) loop(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logrus.Warnf("kms rotation lock lost, stop rotating keys")
+			return
+		case now := <-ticker.C:
+			r.RotateDueKeys(ctx, now)
+		}
+	}
+}
+
+// RotateDueKeys 轮转所有到期的密钥
+func (r *Rotator) RotateDueKeys(ctx context.Context, now time.Time) {
+	store, err := r.mgr.GetStore(r.storeKind)
+	if err != nil {
+		logrus.Errorf("failed to get kms store, err: %v", err)
+		return
+	}
+	for _, kind := range r.mgr.ListPluginKinds() {
+		plugin, err := r.mgr.GetPlugin(kind, r.storeKind)
+		if err != nil {
+			logrus.Errorf("failed to get kms plugin, kind: %s, err: %v", kind, err)
+			continue
+		}
+		r.rotateDueKeys(ctx, store, plugin, now)
+	}
+}
+
+func (r *Rotator) rotateDueKeys(ctx context.Context, store kmstypes.Store, plugin kmstypes.Plugin, now time.Time) {
+	keyIDs, err := store.ListKeysByKind(plugin.Kind())
+	if err != nil {
+		logrus.Errorf("failed to list kms keys, kind: %s, err: %v", plugin.Kind(), err)
+		return
+	}
+	for _, keyID := range keyIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		keyInfo, err := store.GetKey(keyID)
+		if err != nil {
+			logrus.Errorf("failed to get kms key, keyID: %s, err: %v", keyID, err)
+			continue
+		}
+		if keyInfo.GetKeyState() != kmstypes.KeyStateEnabled || !keyInfo.GetRotationPolicy().IsDue(now) {
+			delete(r.failures, keyID)
+			continue
+		}
+		if failure, ok := r.failures[keyID]; ok && now.Before(failure.nextRetry) {
+			continue
+		}
+		previousKeyVersionID := keyInfo.GetPrimaryKeyVersion().GetVersionID()
+		var keyVersionID string
+		resp, err := plugin.RotateKeyVersion(ctx, &kmstypes.RotateKeyVersionRequest{KeyID: keyID})
+		if err != nil {
+			failure := r.recordFailure(keyID, now)
+			logrus.Errorf("failed to rotate kms key automatically, keyID: %s, failures: %d, next retry: %s, err: %v",
+				keyID, failure.count, failure.nextRetry.Format(time.RFC3339), err)
+		} else {
+			delete(r.failures, keyID)
+			keyVersionID = resp.KeyMetadata.PrimaryKeyVersionID
+			logrus.Infof("kms key rotated automatically, keyID: %s, keyVersionID: %s -> %s", keyID, previousKeyVersionID, keyVersionID)
+		}
+		r.sendAudit(NewAuditRequest(keyID, previousKeyVersionID, keyVersionID, TriggerAutomatic, "", err))
+	}
+}
+
+// recordFailure 记录轮转失败，重试间隔从检查间隔开始指数增长，最大为 maxRetryBackoff
+func (r *Rotator) recordFailure(keyID string, now time.Time) *rotateFailure {
+	failure, ok := r.failures[keyID]
+	if !ok {
+		failure = &rotateFailure{}
+		r.failures[keyID] = failure
+	}
+	failure.count++
+	backoff := maxRetryBackoff
+	if failure.count < 32 {
+		if d := r.interval * time.Duration(1<<uint(failure.count-1)); d > 0 && d < maxRetryBackoff {
+			backoff = d
+		}
+	}
+	failure.nextRetry = now.Add(backoff)
+	return failure
+}
+
+func (r *Rotator) sendAudit(req *apistructs.AuditCreateRequest) {
+	if r.audit == nil {
+		return
+	}
+	if err := r.audit(req); err != nil {
+		logrus.Errorf("failed to create kms rotation audit, keyID: %v, err: %v", req.Context["keyID"], err)
+	}
+}
+
+// NewAuditRequest 生成密钥轮转的审计事件，userID 为空时使用系统用户
+func NewAuditRequest(keyID, previousKeyVersionID, keyVersionID, trigger, userID string, rotateErr error) *apistructs.AuditCreateRequest {
+	if userID == "" {
+		userID = systemUserID
+	}
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	audit := apistructs.Audit{
+		UserID:       userID,
+		ScopeType:    apistructs.SysScope,
+		TemplateName: apistructs.RotateKMSKeyTemplate,
+		Result:       apistructs.SuccessfulResult,
+		StartTime:    now,
+		EndTime:      now,
+		Context: map[string]interface{}{
+			"keyID":                keyID,
+			"previousKeyVersionID": previousKeyVersionID,
+			"keyVersionID":         keyVersionID,
+			"trigger":              trigger,
+		},
+	}
+	if rotateErr != nil {
+		audit.Result = apistructs.FailureResult
+		audit.ErrorMsg = rotateErr.Error()
+	}
+	return &apistructs.AuditCreateRequest{Audit: audit}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+	"github.com/erda-project/erda/pkg/kms/plugins/dicekms"
+	"github.com/erda-project/erda/pkg/kms/stores/memory"
+)
+
+func TestRotateDueKeys(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	plugin := &dicekms.Dice{}
+	plugin.SetStore(store)
+
+	createKey := func(interval string) kmstypes.KeyMetadata {
+		createResp, err := plugin.CreateKey(ctx, &kmstypes.CreateKeyRequest{
+			PluginKind:            kmstypes.PluginKind_DICE_KMS,
+			CustomerMasterKeySpec: kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT,
+			KeyUsage:              kmstypes.KeyUsage_ENCRYPT_DECRYPT,
+		})
+		assert.NoError(t, err)
+		if interval == "" {
+			return createResp.KeyMetadata
+		}
+		policyResp, err := plugin.UpdateRotationPolicy(ctx, &kmstypes.UpdateRotationPolicyRequest{
+			KeyID:                   createResp.KeyMetadata.KeyID,
+			EnableAutomaticRotation: true,
+			RotationInterval:        interval,
+		})
+		assert.NoError(t, err)
+		return policyResp.KeyMetadata
+	}
+	noPolicy := createKey("")
+	daily := createKey("1d")
+	monthly := createKey("30d")
+
+	var audits []*apistructs.AuditCreateRequest
+	r := New()
+	r.audit = func(req *apistructs.AuditCreateRequest) error {
+		audits = append(audits, req)
+		return errors.New("fake audit error")
+	}
+
+	// nothing due
+	r.rotateDueKeys(ctx, store, plugin, time.Now())
+	assert.Equal(t, 0, len(audits))
+
+	// only daily key due
+	r.rotateDueKeys(ctx, store, plugin, time.Now().Add(25*time.Hour))
+	assert.Equal(t, 1, len(audits))
+	audit := audits[0].Audit
+	assert.Equal(t, apistructs.RotateKMSKeyTemplate, audit.TemplateName)
+	assert.Equal(t, apistructs.SuccessfulResult, audit.Result)
+	assert.Equal(t, systemUserID, audit.UserID)
+	assert.Equal(t, daily.KeyID, audit.Context["keyID"])
+	assert.Equal(t, daily.PrimaryKeyVersionID, audit.Context["previousKeyVersionID"])
+	assert.Equal(t, TriggerAutomatic, audit.Context["trigger"])
+
+	for _, metadata := range []kmstypes.KeyMetadata{noPolicy, daily, monthly} {
+		keyInfo, err := store.GetKey(metadata.KeyID)
+		assert.NoError(t, err)
+		if metadata.KeyID == daily.KeyID {
+			assert.Equal(t, audit.Context["keyVersionID"], keyInfo.GetPrimaryKeyVersion().GetVersionID())
+			assert.True(t, keyInfo.GetRotationPolicy().NextRotationTime.After(*daily.RotationPolicy.NextRotationTime))
+			continue
+		}
+		assert.Equal(t, metadata.PrimaryKeyVersionID, keyInfo.GetPrimaryKeyVersion().GetVersionID())
+	}
+
+	// next rotation of daily key starts from the last rotation
+	r.rotateDueKeys(ctx, store, plugin, time.Now().Add(time.Hour))
+	assert.Equal(t, 1, len(audits))
+}
+
+type failedPlugin struct {
+	*dicekms.Dice
+}
+
+func (p *failedPlugin) RotateKeyVersion(ctx context.Context, req *kmstypes.RotateKeyVersionRequest) (*kmstypes.RotateKeyVersionResponse, error) {
+	return nil, errors.New("fake rotate error")
+}
+
+func TestRotateDueKeysBackoff(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	dice := &dicekms.Dice{}
+	dice.SetStore(store)
+	createResp, err := dice.CreateKey(ctx, &kmstypes.CreateKeyRequest{
+		PluginKind:            kmstypes.PluginKind_DICE_KMS,
+		CustomerMasterKeySpec: kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT,
+		KeyUsage:              kmstypes.KeyUsage_ENCRYPT_DECRYPT,
+	})
+	assert.NoError(t, err)
+	_, err = dice.UpdateRotationPolicy(ctx, &kmstypes.UpdateRotationPolicyRequest{
+		KeyID:                   createResp.KeyMetadata.KeyID,
+		EnableAutomaticRotation: true,
+		RotationInterval:        "1d",
+	})
+	assert.NoError(t, err)
+
+	var audits []*apistructs.AuditCreateRequest
+	r := New()
+	r.audit = func(req *apistructs.AuditCreateRequest) error {
+		audits = append(audits, req)
+		return nil
+	}
+	plugin := &failedPlugin{Dice: dice}
+
+	now := time.Now().Add(25 * time.Hour)
+	r.rotateDueKeys(ctx, store, plugin, now)
+	assert.Equal(t, 1, len(audits))
+	assert.Equal(t, apistructs.FailureResult, audits[0].Result)
+
+	// not retried until the backoff passes
+	r.rotateDueKeys(ctx, store, plugin, now.Add(r.interval/2))
+	assert.Equal(t, 1, len(audits))
+	r.rotateDueKeys(ctx, store, plugin, now.Add(r.interval))
+	assert.Equal(t, 2, len(audits))
+	// the backoff is doubled
+	r.rotateDueKeys(ctx, store, plugin, now.Add(2*r.interval))
+	assert.Equal(t, 2, len(audits))
+	r.rotateDueKeys(ctx, store, plugin, now.Add(3*r.interval))
+	assert.Equal(t, 3, len(audits))
+
+	// the backoff is capped
+	for i := 0; i < 40; i++ {
+		r.recordFailure(createResp.KeyMetadata.KeyID, now)
+	}
+	assert.Equal(t, now.Add(maxRetryBackoff), r.failures[createResp.KeyMetadata.KeyID].nextRetry)
+
+	// the failure is cleared after rotated
+	r.rotateDueKeys(ctx, store, dice, now.Add(maxRetryBackoff))
+	assert.Equal(t, 4, len(audits))
+	assert.Equal(t, apistructs.SuccessfulResult, audits[3].Result)
+	assert.Equal(t, 0, len(r.failures))
+}
+
+func TestNewAuditRequest(t *testing.T) {
+	req := NewAuditRequest("key", "v1", "", TriggerManual, "2", errors.New("rotate failed"))
+	assert.Equal(t, "2", req.UserID)
+	assert.Equal(t, apistructs.SysScope, req.ScopeType)
+	assert.Equal(t, apistructs.FailureResult, req.Result)
+	assert.Equal(t, "rotate failed", req.ErrorMsg)
+	assert.Equal(t, TriggerManual, req.Context["trigger"])
+}
@@ -16,6 +16,7 @@ package model
 
 type Processor interface {
 	Component
+	// Process returns nil data if the data are held by the processor, and they will not be passed to the next.
 	Process(data ObservableData) (ObservableData, error)
 }
 
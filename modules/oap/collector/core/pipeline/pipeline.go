@@ -140,6 +140,13 @@ func (p *Pipeline) startProcessors(ctx context.Context, in <-chan model.Observab
 					continue
 				}
 				data = tmp
+				if data == nil {
+					break
+				}
+			}
+			// the data are held by processor
+			if data == nil {
+				continue
 			}
 			// wait forever
 			select {
@@ -67,19 +67,22 @@ func (p *provider) initComponents() error {
 			return err
 		}
 
+		var pipe *pipeline.Pipeline
 		switch item.DataType {
 		case model.MetricDataType:
-			pipe := pipeline.NewPipeline(p.Log.Sub("MetricsPipeline"))
-			err := pipe.InitComponents(rs, ps, es)
-			if err != nil {
-				return fmt.Errorf("init components err: %w", err)
-			}
-			p.pipelines = append(p.pipelines, pipe)
+			pipe = pipeline.NewPipeline(p.Log.Sub("MetricsPipeline"))
 		case model.TraceDataType:
+			pipe = pipeline.NewPipeline(p.Log.Sub("TracesPipeline"))
 		case model.LogDataType:
+			pipe = pipeline.NewPipeline(p.Log.Sub("LogsPipeline"))
 		default:
 			return fmt.Errorf("unsupported data_type: %s", item.DataType)
 		}
+		err = pipe.InitComponents(rs, ps, es)
+		if err != nil {
+			return fmt.Errorf("init components err: %w", err)
+		}
+		p.pipelines = append(p.pipelines, pipe)
 	}
 	return nil
 }
@@ -17,14 +17,18 @@ package all
 import (
 	// receivers
 	_ "github.com/erda-project/erda/modules/oap/collector/plugins/receivers/dummy"
+	_ "github.com/erda-project/erda/modules/oap/collector/plugins/receivers/kafka"
 	_ "github.com/erda-project/erda/modules/oap/collector/plugins/receivers/promremotewrite"
 
 	// processors
 	_ "github.com/erda-project/erda/modules/oap/collector/plugins/processors/aggregator"
 	_ "github.com/erda-project/erda/modules/oap/collector/plugins/processors/k8s-tagger"
 	_ "github.com/erda-project/erda/modules/oap/collector/plugins/processors/modifier"
+	_ "github.com/erda-project/erda/modules/oap/collector/plugins/processors/tail-sampling"
 
 	// exporters
 	_ "github.com/erda-project/erda/modules/oap/collector/plugins/exporters/collector"
+	_ "github.com/erda-project/erda/modules/oap/collector/plugins/exporters/otlp"
+	_ "github.com/erda-project/erda/modules/oap/collector/plugins/exporters/promremotewrite"
 	_ "github.com/erda-project/erda/modules/oap/collector/plugins/exporters/stdout"
 )
@@ -0,0 +1,179 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/erda-project/erda/modules/oap/collector/common/compressor"
+)
+
+const (
+	protocolGRPC = "grpc"
+	protocolHTTP = "http"
+
+	compressionGzip = "gzip"
+	compressionNone = "none"
+)
+
+// signal describes the endpoints of metrics, traces and logs
+type signal struct {
+	name string
+	// path of http
+	path string
+	// method of grpc
+	method string
+}
+
+var (
+	metricsSignal = signal{name: "metrics", path: "/v1/metrics", method: "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"}
+	tracesSignal  = signal{name: "traces", path: "/v1/traces", method: "/opentelemetry.proto.collector.trace.v1.TraceService/Export"}
+	logsSignal    = signal{name: "logs", path: "/v1/logs", method: "/opentelemetry.proto.collector.logs.v1.LogsService/Export"}
+)
+
+type client interface {
+	// Export send the encoded export request of the signal
+	Export(ctx context.Context, s signal, body []byte) error
+	Close() error
+}
+
+// rawCodec passes the encoded request through, and discards the response
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	buf, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+	return buf, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error { return nil }
+
+func (rawCodec) Name() string { return "proto" }
+
+type grpcClient struct {
+	conn    *grpc.ClientConn
+	headers metadata.MD
+	opts    []grpc.CallOption
+}
+
+// newGRPCClient dial without blocking, the connection is established in background,
+// so the collector can start while the endpoint is down, and the export is retried until it's available.
+func newGRPCClient(cfg *config) (*grpcClient, error) {
+	var opts []grpc.DialOption
+	if cfg.Insecure {
+		opts = append(opts, grpc.WithInsecure())
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})))
+	}
+	conn, err := grpc.Dial(cfg.Endpoint, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c := &grpcClient{
+		conn:    conn,
+		headers: metadata.New(cfg.Headers),
+		opts:    []grpc.CallOption{grpc.ForceCodec(rawCodec{})},
+	}
+	if cfg.Compression == compressionGzip {
+		c.opts = append(c.opts, grpc.UseCompressor(gzip.Name))
+	}
+	return c, nil
+}
+
+func (c *grpcClient) Export(ctx context.Context, s signal, body []byte) error {
+	var resp []byte
+	return c.conn.Invoke(metadata.NewOutgoingContext(ctx, c.headers), s.method, body, &resp, c.opts...)
+}
+
+func (c *grpcClient) Close() error {
+	return c.conn.Close()
+}
+
+type httpClient struct {
+	client   *http.Client
+	endpoint string
+	headers  map[string]string
+	cp       compressor.Compressor
+}
+
+func newHTTPClient(cfg *config) *httpClient {
+	c := &httpClient{
+		client:   &http.Client{Timeout: cfg.Timeout},
+		endpoint: strings.TrimRight(cfg.Endpoint, "/"),
+		headers:  cfg.Headers,
+	}
+	if !strings.HasPrefix(c.endpoint, "http://") && !strings.HasPrefix(c.endpoint, "https://") {
+		if cfg.Insecure {
+			c.endpoint = "http://" + c.endpoint
+		} else {
+			c.endpoint = "https://" + c.endpoint
+		}
+	}
+	if cfg.Compression == compressionGzip {
+		c.cp = compressor.NewGzipEncoder(3)
+	}
+	return c
+}
+
+func (c *httpClient) Export(ctx context.Context, s signal, buf []byte) error {
+	var err error
+	if c.cp != nil {
+		buf, err = c.cp.Compress(buf)
+		if err != nil {
+			return fmt.Errorf("compress err: %w", err)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+s.path, bytes.NewReader(buf))
+	if err != nil {
+		return fmt.Errorf("create request err: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	if c.cp != nil {
+		req.Header.Set("Content-Encoding", compressionGzip)
+	}
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("server returned HTTP status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	return nil
+}
+
+func (c *httpClient) Close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,266 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	lpb "github.com/erda-project/erda-proto-go/oap/logs/pb"
+	mpb "github.com/erda-project/erda-proto-go/oap/metrics/pb"
+	tpb "github.com/erda-project/erda-proto-go/oap/trace/pb"
+	"github.com/erda-project/erda/modules/oap/collector/receivers/common"
+)
+
+// metricsFromRemoteWrite is the metric name of data from prometheus remote write receiver,
+// the field name is the original name of metric.
+const metricsFromRemoteWrite = "prometheus_remote_write"
+
+// spanKinds is the reverse of the span kinds mapping of opentelemetry receiver
+var spanKinds = map[string]tracepb.Span_SpanKind{
+	"local":    tracepb.Span_SPAN_KIND_INTERNAL,
+	"server":   tracepb.Span_SPAN_KIND_SERVER,
+	"client":   tracepb.Span_SPAN_KIND_CLIENT,
+	"producer": tracepb.Span_SPAN_KIND_PRODUCER,
+	"consumer": tracepb.Span_SPAN_KIND_CONSUMER,
+}
+
+// resourceGroups group the items by the attributes which are the keys of resource
+type resourceGroups struct {
+	keys   []string
+	index  map[string]int
+	groups []*resourceGroup
+}
+
+type resourceGroup struct {
+	resource *resourcepb.Resource
+	items    []int
+}
+
+func newResourceGroups(keys []string) *resourceGroups {
+	return &resourceGroups{keys: keys, index: make(map[string]int)}
+}
+
+// add put the item into group, and returns the attributes not belong to resource.
+func (g *resourceGroups) add(item int, attrs map[string]string, exclude ...string) []*commonpb.KeyValue {
+	var sb strings.Builder
+	var resource []*commonpb.KeyValue
+	for _, key := range g.keys {
+		if v, ok := attrs[key]; ok {
+			resource = append(resource, stringKeyValue(key, v))
+			sb.WriteString(key)
+			sb.WriteByte(0xff)
+			sb.WriteString(v)
+			sb.WriteByte(0xff)
+		}
+	}
+	idx, ok := g.index[sb.String()]
+	if !ok {
+		idx = len(g.groups)
+		g.index[sb.String()] = idx
+		g.groups = append(g.groups, &resourceGroup{resource: &resourcepb.Resource{Attributes: resource}})
+	}
+	g.groups[idx].items = append(g.groups[idx].items, item)
+	return toKeyValues(attrs, append(exclude, g.keys...)...)
+}
+
+func stringKeyValue(k, v string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: k, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}}
+}
+
+func toKeyValues(attrs map[string]string, exclude ...string) []*commonpb.KeyValue {
+	list := make([]*commonpb.KeyValue, 0, len(attrs))
+	for k, v := range attrs {
+		if contains(exclude, k) {
+			continue
+		}
+		list = append(list, stringKeyValue(k, v))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func convertMetrics(metrics []*mpb.Metric, resourceKeys []string) []*metricspb.ResourceMetrics {
+	groups := newResourceGroups(resourceKeys)
+	list := make([][]*metricspb.Metric, len(metrics))
+	for i, m := range metrics {
+		attrs := groups.add(i, m.Attributes)
+		fields := make([]string, 0, len(m.DataPoints))
+		for k := range m.DataPoints {
+			fields = append(fields, k)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			dp := &metricspb.NumberDataPoint{Attributes: attrs, TimeUnixNano: m.TimeUnixNano}
+			switch v := m.DataPoints[field].GetKind().(type) {
+			case *structpb.Value_NumberValue:
+				dp.Value = &metricspb.NumberDataPoint_AsDouble{AsDouble: v.NumberValue}
+			case *structpb.Value_BoolValue:
+				var n int64
+				if v.BoolValue {
+					n = 1
+				}
+				dp.Value = &metricspb.NumberDataPoint_AsInt{AsInt: n}
+			default:
+				continue
+			}
+			name := field
+			if m.Name != metricsFromRemoteWrite {
+				name = m.Name + "_" + field
+			}
+			list[i] = append(list[i], &metricspb.Metric{
+				Name: name,
+				Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: []*metricspb.NumberDataPoint{dp}}},
+			})
+		}
+	}
+	var resources []*metricspb.ResourceMetrics
+	for _, g := range groups.groups {
+		ilm := &metricspb.InstrumentationLibraryMetrics{}
+		for _, item := range g.items {
+			ilm.Metrics = append(ilm.Metrics, list[item]...)
+		}
+		if len(ilm.Metrics) <= 0 {
+			continue
+		}
+		resources = append(resources, &metricspb.ResourceMetrics{
+			Resource:                      g.resource,
+			InstrumentationLibraryMetrics: []*metricspb.InstrumentationLibraryMetrics{ilm},
+		})
+	}
+	return resources
+}
+
+func convertSpans(spans []*tpb.Span, resourceKeys []string) []*tracepb.ResourceSpans {
+	groups := newResourceGroups(resourceKeys)
+	list := make([]*tracepb.Span, len(spans))
+	libraries := make([]*commonpb.InstrumentationLibrary, len(spans))
+	for i, s := range spans {
+		attrs := groups.add(i, s.Attributes, common.TAG_INSTRUMENT, common.TAG_INSTRUMENT_VERSION, common.TAG_SPAN_KIND)
+		span := &tracepb.Span{
+			TraceId:           decodeID(s.TraceID),
+			SpanId:            decodeID(s.SpanID),
+			ParentSpanId:      decodeID(s.ParentSpanID),
+			Name:              s.Name,
+			Kind:              spanKinds[s.Attributes[common.TAG_SPAN_KIND]],
+			StartTimeUnixNano: s.StartTimeUnixNano,
+			EndTimeUnixNano:   s.EndTimeUnixNano,
+			Attributes:        attrs,
+		}
+		if s.Attributes["error"] == "true" {
+			span.Status = &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR}
+		}
+		list[i] = span
+		libraries[i] = &commonpb.InstrumentationLibrary{
+			Name:    s.Attributes[common.TAG_INSTRUMENT],
+			Version: s.Attributes[common.TAG_INSTRUMENT_VERSION],
+		}
+	}
+	var resources []*tracepb.ResourceSpans
+	for _, g := range groups.groups {
+		rs := &tracepb.ResourceSpans{Resource: g.resource}
+		index := make(map[string]*tracepb.InstrumentationLibrarySpans)
+		for _, item := range g.items {
+			lib := libraries[item]
+			key := lib.Name + "/" + lib.Version
+			ils, ok := index[key]
+			if !ok {
+				ils = &tracepb.InstrumentationLibrarySpans{InstrumentationLibrary: lib}
+				index[key] = ils
+				rs.InstrumentationLibrarySpans = append(rs.InstrumentationLibrarySpans, ils)
+			}
+			ils.Spans = append(ils.Spans, list[item])
+		}
+		resources = append(resources, rs)
+	}
+	return resources
+}
+
+func convertLogs(logs []*lpb.Log, resourceKeys []string) []*logspb.ResourceLogs {
+	groups := newResourceGroups(resourceKeys)
+	list := make([]*logspb.LogRecord, len(logs))
+	for i, l := range logs {
+		record := &logspb.LogRecord{
+			TimeUnixNano: l.TimeUnixNano,
+			Name:         l.Name,
+			SeverityText: l.Severity,
+			Body:         &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: l.Content}},
+			Attributes:   groups.add(i, l.Attributes),
+		}
+		if l.Relations != nil {
+			record.TraceId = decodeID(l.Relations.TraceID)
+		}
+		list[i] = record
+	}
+	var resources []*logspb.ResourceLogs
+	for _, g := range groups.groups {
+		ill := &logspb.InstrumentationLibraryLogs{}
+		for _, item := range g.items {
+			ill.Logs = append(ill.Logs, list[item])
+		}
+		resources = append(resources, &logspb.ResourceLogs{
+			Resource:                   g.resource,
+			InstrumentationLibraryLogs: []*logspb.InstrumentationLibraryLogs{ill},
+		})
+	}
+	return resources
+}
+
+// marshalRequest encode the resources as Export(Metrics|Trace|Logs)ServiceRequest,
+// all of them contain only one field: repeated resources = 1.
+// the generated packages of collector services are not used, they require a newer grpc.
+func marshalRequest(resources []proto.Message) ([]byte, error) {
+	var buf []byte
+	for _, r := range resources {
+		b, err := proto.Marshal(r)
+		if err != nil {
+			return nil, err
+		}
+		buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, b)
+	}
+	return buf, nil
+}
+
+// decodeID decode the hex id, ids in other formats such as uuid are dropped the '-'.
+func decodeID(id string) []byte {
+	if len(id) <= 0 {
+		return nil
+	}
+	b, err := hex.DecodeString(strings.ReplaceAll(id, "-", ""))
+	if err != nil {
+		return []byte(id)
+	}
+	return b
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	oappb "github.com/erda-project/erda-proto-go/oap/common/pb"
+	lpb "github.com/erda-project/erda-proto-go/oap/logs/pb"
+	mpb "github.com/erda-project/erda-proto-go/oap/metrics/pb"
+	tpb "github.com/erda-project/erda-proto-go/oap/trace/pb"
+)
+
+func testMetrics() []*mpb.Metric {
+	return []*mpb.Metric{
+		{
+			Name:         "docker_container_summary",
+			TimeUnixNano: 1640936985459000000,
+			Attributes:   map[string]string{"cluster_name": "dev", "pod_name": "demo"},
+			DataPoints: map[string]*structpb.Value{
+				"mem_usage": structpb.NewNumberValue(1552384),
+				"ready":     structpb.NewBoolValue(true),
+				"state":     structpb.NewStringValue("running"),
+			},
+		},
+		{
+			Name:         "prometheus_remote_write",
+			TimeUnixNano: 1640936985459000000,
+			Attributes:   map[string]string{"cluster_name": "test", "job": "cadvisor"},
+			DataPoints: map[string]*structpb.Value{
+				"container_cpu_usage_seconds_total": structpb.NewNumberValue(500),
+			},
+		},
+		{
+			Name:         "docker_container_summary",
+			TimeUnixNano: 1640936985459000000,
+			Attributes:   map[string]string{"cluster_name": "dev", "pod_name": "demo-2"},
+			DataPoints: map[string]*structpb.Value{
+				"mem_usage": structpb.NewNumberValue(1024),
+			},
+		},
+	}
+}
+
+func Test_convertMetrics(t *testing.T) {
+	resources := convertMetrics(testMetrics(), []string{"cluster_name"})
+	if !assert.Equal(t, 2, len(resources)) {
+		return
+	}
+
+	assert.Equal(t, []*commonpb.KeyValue{stringKeyValue("cluster_name", "dev")}, resources[0].Resource.Attributes)
+	metrics := resources[0].InstrumentationLibraryMetrics[0].Metrics
+	if assert.Equal(t, 3, len(metrics)) {
+		assert.Equal(t, "docker_container_summary_mem_usage", metrics[0].Name)
+		dp := metrics[0].GetGauge().DataPoints[0]
+		assert.Equal(t, 1552384.0, dp.GetAsDouble())
+		assert.Equal(t, uint64(1640936985459000000), dp.TimeUnixNano)
+		assert.Equal(t, []*commonpb.KeyValue{stringKeyValue("pod_name", "demo")}, dp.Attributes)
+
+		assert.Equal(t, "docker_container_summary_ready", metrics[1].Name)
+		assert.Equal(t, int64(1), metrics[1].GetGauge().DataPoints[0].GetAsInt())
+
+		assert.Equal(t, "docker_container_summary_mem_usage", metrics[2].Name)
+		assert.Equal(t, []*commonpb.KeyValue{stringKeyValue("pod_name", "demo-2")}, metrics[2].GetGauge().DataPoints[0].Attributes)
+	}
+
+	metrics = resources[1].InstrumentationLibraryMetrics[0].Metrics
+	if assert.Equal(t, 1, len(metrics)) {
+		assert.Equal(t, "container_cpu_usage_seconds_total", metrics[0].Name)
+	}
+}
+
+func Test_convertSpans(t *testing.T) {
+	resources := convertSpans([]*tpb.Span{
+		{
+			TraceID:           "0af7651916cd43dd8448eb211c80319c",
+			SpanID:            "b7ad6b7169203331",
+			ParentSpanID:      "",
+			Name:              "GET /api/users",
+			StartTimeUnixNano: 1,
+			EndTimeUnixNano:   2,
+			Attributes: map[string]string{
+				"service_name":       "user-service",
+				"instrument":         "io.opentelemetry.http",
+				"instrument_version": "1.0",
+				"span_kind":          "server",
+				"error":              "true",
+			},
+		},
+		{
+			TraceID:      "a6d7a8b3-b5c2-4f5e-9b86-ff7d3b3c0d6e",
+			SpanID:       "not-hex",
+			ParentSpanID: "b7ad6b7169203331",
+			Name:         "SELECT",
+			Attributes: map[string]string{
+				"service_name": "user-service",
+				"span_kind":    "client",
+			},
+		},
+	}, []string{"service_name"})
+	if !assert.Equal(t, 1, len(resources)) {
+		return
+	}
+	assert.Equal(t, []*commonpb.KeyValue{stringKeyValue("service_name", "user-service")}, resources[0].Resource.Attributes)
+
+	libs := resources[0].InstrumentationLibrarySpans
+	if !assert.Equal(t, 2, len(libs)) {
+		return
+	}
+	assert.Equal(t, "io.opentelemetry.http", libs[0].InstrumentationLibrary.Name)
+	assert.Equal(t, "1.0", libs[0].InstrumentationLibrary.Version)
+
+	span := libs[0].Spans[0]
+	assert.Equal(t, []byte{0x0a, 0xf7, 0x65, 0x19, 0x16, 0xcd, 0x43, 0xdd, 0x84, 0x48, 0xeb, 0x21, 0x1c, 0x80, 0x31, 0x9c}, span.TraceId)
+	assert.Equal(t, 8, len(span.SpanId))
+	assert.Nil(t, span.ParentSpanId)
+	assert.Equal(t, tracepb.Span_SPAN_KIND_SERVER, span.Kind)
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, span.Status.Code)
+	assert.Equal(t, []*commonpb.KeyValue{stringKeyValue("error", "true")}, span.Attributes)
+
+	span = libs[1].Spans[0]
+	assert.Equal(t, 16, len(span.TraceId))
+	assert.Equal(t, []byte("not-hex"), span.SpanId)
+	assert.Equal(t, tracepb.Span_SPAN_KIND_CLIENT, span.Kind)
+	assert.Equal(t, 0, len(span.Attributes))
+}
+
+func Test_convertLogs(t *testing.T) {
+	resources := convertLogs([]*lpb.Log{
+		{
+			TimeUnixNano: 1,
+			Name:         "container",
+			Severity:     "ERROR",
+			Content:      "something wrong",
+			Relations:    &oappb.Relation{TraceID: "0af7651916cd43dd8448eb211c80319c"},
+			Attributes:   map[string]string{"stream": "stderr"},
+		},
+	}, nil)
+	if !assert.Equal(t, 1, len(resources)) {
+		return
+	}
+	record := resources[0].InstrumentationLibraryLogs[0].Logs[0]
+	assert.Equal(t, "ERROR", record.SeverityText)
+	assert.Equal(t, "something wrong", record.Body.GetStringValue())
+	assert.Equal(t, 16, len(record.TraceId))
+	assert.Equal(t, []*commonpb.KeyValue{stringKeyValue("stream", "stderr")}, record.Attributes)
+}
+
+func Test_marshalRequest(t *testing.T) {
+	resources := convertMetrics(testMetrics(), []string{"cluster_name"})
+	list := make([]proto.Message, len(resources))
+	for i, r := range resources {
+		list[i] = r
+	}
+	buf, err := marshalRequest(list)
+	assert.NoError(t, err)
+
+	got := unmarshalResourceMetrics(t, buf)
+	if assert.Equal(t, len(resources), len(got)) {
+		for i := range got {
+			assert.True(t, proto.Equal(resources[i], got[i]))
+		}
+	}
+}
+
+func unmarshalResourceMetrics(t *testing.T, buf []byte) []*metricspb.ResourceMetrics {
+	var list []*metricspb.ResourceMetrics
+	for len(buf) > 0 {
+		num, typ, n := protowire.ConsumeTag(buf)
+		if !assert.True(t, n > 0) || !assert.Equal(t, protowire.Number(1), num) || !assert.Equal(t, protowire.BytesType, typ) {
+			return nil
+		}
+		buf = buf[n:]
+		b, n := protowire.ConsumeBytes(buf)
+		if !assert.True(t, n > 0) {
+			return nil
+		}
+		buf = buf[n:]
+		rm := &metricspb.ResourceMetrics{}
+		assert.NoError(t, proto.Unmarshal(b, rm))
+		list = append(list, rm)
+	}
+	return list
+}
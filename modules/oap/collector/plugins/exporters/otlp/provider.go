@@ -0,0 +1,148 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/erda-project/erda-infra/base/logs"
+	"github.com/erda-project/erda-infra/base/servicehub"
+	lpb "github.com/erda-project/erda-proto-go/oap/logs/pb"
+	mpb "github.com/erda-project/erda-proto-go/oap/metrics/pb"
+	tpb "github.com/erda-project/erda-proto-go/oap/trace/pb"
+	"github.com/erda-project/erda/modules/oap/collector/core/model"
+	"github.com/erda-project/erda/modules/oap/collector/plugins"
+)
+
+var providerName = plugins.WithPrefixExporter("otlp")
+
+type config struct {
+	// Protocol is grpc or http(protobuf over http)
+	Protocol string `file:"protocol" default:"grpc"`
+	// Endpoint is host:port for grpc, or the base url for http, "/v1/metrics", "/v1/traces" and "/v1/logs" are appended to it
+	Endpoint    string            `file:"endpoint"`
+	Insecure    bool              `file:"insecure" default:"true"`
+	Timeout     time.Duration     `file:"timeout" default:"10s"`
+	Headers     map[string]string `file:"headers"`
+	Compression string            `file:"compression" default:"gzip"`
+	// ResourceKeys are the tags moved to attributes of resource, such as cluster_name, service_name
+	ResourceKeys []string `file:"resource_keys"`
+}
+
+// +provider
+type provider struct {
+	Cfg *config
+	Log logs.Logger
+
+	client client
+}
+
+func (p *provider) ComponentID() model.ComponentID {
+	return model.ComponentID(providerName)
+}
+
+func (p *provider) Connect() error {
+	switch p.Cfg.Protocol {
+	case protocolGRPC:
+		c, err := newGRPCClient(p.Cfg)
+		if err != nil {
+			return err
+		}
+		p.client = c
+	case protocolHTTP:
+		p.client = newHTTPClient(p.Cfg)
+	default:
+		return fmt.Errorf("invalid protocol: %q", p.Cfg.Protocol)
+	}
+	return nil
+}
+
+func (p *provider) Close() error {
+	if p.client == nil {
+		return nil
+	}
+	return p.client.Close()
+}
+
+func (p *provider) Export(od model.ObservableData) error {
+	var s signal
+	var resources []proto.Message
+	switch data := od.SourceData().(type) {
+	case []*mpb.Metric:
+		s = metricsSignal
+		for _, r := range convertMetrics(data, p.Cfg.ResourceKeys) {
+			resources = append(resources, r)
+		}
+	case []*tpb.Span:
+		s = tracesSignal
+		for _, r := range convertSpans(data, p.Cfg.ResourceKeys) {
+			resources = append(resources, r)
+		}
+	case []*lpb.Log:
+		s = logsSignal
+		for _, r := range convertLogs(data, p.Cfg.ResourceKeys) {
+			resources = append(resources, r)
+		}
+	default:
+		return fmt.Errorf("unsupported data type: %T", data)
+	}
+	if len(resources) <= 0 {
+		return nil
+	}
+	body, err := marshalRequest(resources)
+	if err != nil {
+		return fmt.Errorf("marshal %s err: %w", s.name, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.Cfg.Timeout)
+	defer cancel()
+	if err := p.client.Export(ctx, s, body); err != nil {
+		return fmt.Errorf("export %s err: %w", s.name, err)
+	}
+	return nil
+}
+
+func (p *provider) Init(ctx servicehub.Context) error {
+	if len(p.Cfg.Endpoint) <= 0 {
+		return fmt.Errorf("endpoint is required")
+	}
+	switch p.Cfg.Compression {
+	case compressionGzip, compressionNone:
+	default:
+		return fmt.Errorf("invalid compression: %q", p.Cfg.Compression)
+	}
+	if err := p.Connect(); err != nil {
+		return fmt.Errorf("try connect to remote err: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	servicehub.Register(providerName, &servicehub.Spec{
+		Services: []string{
+			providerName,
+		},
+		Description: "here is description of erda.oap.collector.exporter.otlp",
+		ConfigFunc: func() interface{} {
+			return &config{}
+		},
+		Creator: func() servicehub.Provider {
+			return &provider{}
+		},
+	})
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/erda-project/erda-infra/base/logs/logrusx"
+	"github.com/erda-project/erda/modules/oap/collector/core/model"
+)
+
+func Test_provider_Export_HTTP(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "abc", r.Header.Get("Authorization"))
+		gr, err := gzip.NewReader(r.Body)
+		if !assert.NoError(t, err) {
+			return
+		}
+		buf, _ := io.ReadAll(gr)
+		if r.URL.Path == "/v1/metrics" {
+			assert.Equal(t, 2, len(unmarshalResourceMetrics(t, buf)))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	p := &provider{Cfg: &config{
+		Protocol:     protocolHTTP,
+		Endpoint:     server.URL + "/",
+		Timeout:      time.Second,
+		Headers:      map[string]string{"Authorization": "abc"},
+		Compression:  compressionGzip,
+		ResourceKeys: []string{"cluster_name"},
+	}, Log: logrusx.New()}
+	assert.NoError(t, p.Connect())
+	defer p.Close()
+
+	assert.NoError(t, p.Export(&model.Metrics{Metrics: testMetrics()}))
+	assert.NoError(t, p.Export(&model.Traces{}))
+	assert.Equal(t, []string{"/v1/metrics"}, paths)
+}
+
+func Test_provider_Export_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	p := &provider{Cfg: &config{
+		Protocol:    protocolHTTP,
+		Endpoint:    server.URL,
+		Timeout:     time.Second,
+		Compression: compressionNone,
+	}, Log: logrusx.New()}
+	assert.NoError(t, p.Connect())
+	err := p.Export(&model.Metrics{Metrics: testMetrics()})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "bad request")
+}
+
+type recvCodec struct{ rawCodec }
+
+func (recvCodec) Unmarshal(data []byte, v interface{}) error {
+	*(v.(*[]byte)) = append([]byte(nil), data...)
+	return nil
+}
+
+func (recvCodec) String() string { return "proto" }
+
+func Test_provider_Export_GRPC(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	var method string
+	var body []byte
+	server := grpc.NewServer(grpc.CustomCodec(recvCodec{}), grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+		method, _ = grpc.MethodFromServerStream(stream)
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		assert.Equal(t, []string{"abc"}, md.Get("authorization"))
+		if err := stream.RecvMsg(&body); err != nil {
+			return err
+		}
+		return stream.SendMsg([]byte{})
+	}))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	p := &provider{Cfg: &config{
+		Protocol:     protocolGRPC,
+		Endpoint:     lis.Addr().String(),
+		Insecure:     true,
+		Timeout:      time.Second,
+		Headers:      map[string]string{"authorization": "abc"},
+		Compression:  compressionGzip,
+		ResourceKeys: []string{"cluster_name"},
+	}, Log: logrusx.New()}
+	assert.NoError(t, p.Connect())
+	defer p.Close()
+
+	assert.NoError(t, p.Export(&model.Metrics{Metrics: testMetrics()}))
+	assert.Equal(t, metricsSignal.method, method)
+	assert.Equal(t, 2, len(unmarshalResourceMetrics(t, body)))
+}
+
+func Test_provider_Connect_GRPC_EndpointDown(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	endpoint := lis.Addr().String()
+	lis.Close()
+
+	p := &provider{Cfg: &config{
+		Protocol: protocolGRPC,
+		Endpoint: endpoint,
+		Insecure: true,
+		Timeout:  100 * time.Millisecond,
+	}, Log: logrusx.New()}
+	// connect doesn't wait for the endpoint, export fails and can be retried
+	assert.NoError(t, p.Connect())
+	defer p.Close()
+	assert.Error(t, p.Export(&model.Metrics{Metrics: testMetrics()}))
+}
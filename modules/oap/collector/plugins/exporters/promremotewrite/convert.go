@@ -0,0 +1,145 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promremotewrite
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	pmodel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	mpb "github.com/erda-project/erda-proto-go/oap/metrics/pb"
+	"github.com/erda-project/erda/modules/oap/collector/core/model"
+)
+
+// metricsFromRemoteWrite is the metric name of data from prometheus remote write receiver,
+// the field name is the original name of time series.
+const metricsFromRemoteWrite = "prometheus_remote_write"
+
+func (p *provider) convertToTimeSeries(od model.ObservableData) []*prompb.TimeSeries {
+	metrics, ok := od.SourceData().([]*mpb.Metric)
+	if !ok {
+		return nil
+	}
+	series := make([]*prompb.TimeSeries, 0, len(metrics))
+	for _, m := range metrics {
+		labels := sanitizeLabels(m.Attributes, p.Cfg.ExternalLabels)
+		ts := int64(m.TimeUnixNano / 1000000)
+		for field, v := range m.DataPoints {
+			value, ok := toFloat64(v)
+			if !ok {
+				continue
+			}
+			name := field
+			if m.Name != metricsFromRemoteWrite {
+				name = m.Name + "_" + field
+			}
+			series = append(series, &prompb.TimeSeries{
+				Labels:  withMetricName(labels, sanitizeMetricName(name)),
+				Samples: []*prompb.Sample{{Value: value, Timestamp: ts}},
+			})
+		}
+	}
+	return series
+}
+
+func toFloat64(v *structpb.Value) (float64, bool) {
+	switch val := v.GetKind().(type) {
+	case *structpb.Value_NumberValue:
+		return val.NumberValue, !math.IsInf(val.NumberValue, 0)
+	case *structpb.Value_BoolValue:
+		if val.BoolValue {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// sanitizeLabels convert tags to sorted labels, labels which have the same name after sanitized are joined by ';'.
+func sanitizeLabels(tags, external map[string]string) []*prompb.Label {
+	values := make(map[string][]string, len(tags)+len(external))
+	for k, v := range tags {
+		if len(v) <= 0 || k == pmodel.MetricNameLabel {
+			continue
+		}
+		name := sanitizeLabelName(k)
+		values[name] = append(values[name], v)
+	}
+	for k, v := range external {
+		name := sanitizeLabelName(k)
+		if _, ok := values[name]; !ok && len(v) > 0 {
+			values[name] = []string{v}
+		}
+	}
+	labels := make([]*prompb.Label, 0, len(values)+1)
+	for name, list := range values {
+		sort.Strings(list)
+		labels = append(labels, &prompb.Label{Name: name, Value: strings.Join(list, ";")})
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+	return labels
+}
+
+func withMetricName(labels []*prompb.Label, name string) []*prompb.Label {
+	list := make([]*prompb.Label, 0, len(labels)+1)
+	list = append(list, &prompb.Label{Name: pmodel.MetricNameLabel, Value: name})
+	list = append(list, labels...)
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// sanitizeLabelName replace the characters not in [a-zA-Z0-9_] with '_', and label name can't start with a digit.
+func sanitizeLabelName(name string) string {
+	return sanitize(name, false)
+}
+
+// sanitizeMetricName replace the characters not in [a-zA-Z0-9_:] with '_', and metric name can't start with a digit.
+func sanitizeMetricName(name string) string {
+	return sanitize(name, true)
+}
+
+func sanitize(name string, colon bool) string {
+	if len(name) <= 0 {
+		return "_"
+	}
+	var sb strings.Builder
+	if name[0] >= '0' && name[0] <= '9' {
+		sb.WriteByte('_')
+	}
+	for _, c := range name {
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || (colon && c == ':') {
+			sb.WriteRune(c)
+		} else {
+			sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
+
+func splitBatches(series []*prompb.TimeSeries, size int) [][]*prompb.TimeSeries {
+	if size <= 0 || len(series) <= size {
+		return [][]*prompb.TimeSeries{series}
+	}
+	batches := make([][]*prompb.TimeSeries, 0, (len(series)+size-1)/size)
+	for len(series) > size {
+		batches = append(batches, series[:size])
+		series = series[size:]
+	}
+	return append(batches, series)
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promremotewrite
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/erda-project/erda-infra/base/logs"
+	"github.com/erda-project/erda-infra/base/servicehub"
+	"github.com/erda-project/erda/modules/oap/collector/core/model"
+	"github.com/erda-project/erda/modules/oap/collector/plugins"
+)
+
+var providerName = plugins.WithPrefixExporter("prometheus-remote-write")
+
+type config struct {
+	// URL of remote write endpoint, remote write is disabled if it's empty
+	URL               string            `file:"url"`
+	Timeout           time.Duration     `file:"timeout" default:"5s"`
+	Headers           map[string]string `file:"headers"`
+	ExternalLabels    map[string]string `file:"external_labels"`
+	MaxSamplesPerSend int               `file:"max_samples_per_send" default:"500"`
+	Retry             struct {
+		MaxRetries int           `file:"max_retries" default:"3"`
+		MinBackoff time.Duration `file:"min_backoff" default:"30ms"`
+		MaxBackoff time.Duration `file:"max_backoff" default:"5s"`
+	} `file:"retry"`
+}
+
+// +provider
+type provider struct {
+	Cfg *config
+	Log logs.Logger
+
+	client *http.Client
+}
+
+func (p *provider) ComponentID() model.ComponentID {
+	return model.ComponentID(providerName)
+}
+
+func (p *provider) Connect() error {
+	p.client = &http.Client{
+		Timeout: p.Cfg.Timeout,
+	}
+	return nil
+}
+
+func (p *provider) Close() error {
+	return nil
+}
+
+func (p *provider) Export(od model.ObservableData) error {
+	series := p.convertToTimeSeries(od)
+	if len(series) <= 0 {
+		return nil
+	}
+	if len(p.Cfg.URL) <= 0 {
+		return nil
+	}
+	for _, batch := range splitBatches(series, p.Cfg.MaxSamplesPerSend) {
+		if err := p.send(batch); err != nil {
+			return fmt.Errorf("remote write err: %w", err)
+		}
+	}
+	return nil
+}
+
+func (p *provider) Init(ctx servicehub.Context) error {
+	if err := p.Connect(); err != nil {
+		return fmt.Errorf("try connect to remote err: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	servicehub.Register(providerName, &servicehub.Spec{
+		Services: []string{
+			providerName,
+		},
+		Description: "here is description of erda.oap.collector.exporter.prometheus-remote-write",
+		ConfigFunc: func() interface{} {
+			return &config{}
+		},
+		Creator: func() servicehub.Provider {
+			return &provider{}
+		},
+	})
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promremotewrite
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/erda-project/erda-infra/base/logs/logrusx"
+	mpb "github.com/erda-project/erda-proto-go/oap/metrics/pb"
+	"github.com/erda-project/erda/modules/oap/collector/core/model"
+)
+
+func newTestProvider(url string) *provider {
+	p := &provider{Cfg: &config{
+		URL:               url,
+		Timeout:           time.Second,
+		ExternalLabels:    map[string]string{"cluster": "test", "host": "external"},
+		MaxSamplesPerSend: 2,
+	}, Log: logrusx.New()}
+	p.Cfg.Retry.MaxRetries = 2
+	p.Cfg.Retry.MinBackoff = time.Millisecond
+	p.Cfg.Retry.MaxBackoff = 2 * time.Millisecond
+	p.Connect()
+	return p
+}
+
+func testMetrics() *model.Metrics {
+	return &model.Metrics{Metrics: []*mpb.Metric{
+		{
+			Name:         "docker_container_summary",
+			TimeUnixNano: 1640936985459000000,
+			Attributes:   map[string]string{"host": "node-1", "pod.name": "demo", "pod/name": "x", "empty": ""},
+			DataPoints: map[string]*structpb.Value{
+				"mem_usage": structpb.NewNumberValue(1552384),
+				"ready":     structpb.NewBoolValue(true),
+				"state":     structpb.NewStringValue("running"),
+			},
+		},
+		{
+			Name:         "prometheus_remote_write",
+			TimeUnixNano: 1640936985459000000,
+			Attributes:   map[string]string{"job": "cadvisor"},
+			DataPoints: map[string]*structpb.Value{
+				"container_cpu_usage_seconds_total": structpb.NewNumberValue(500),
+			},
+		},
+	}}
+}
+
+func Test_provider_convertToTimeSeries(t *testing.T) {
+	p := newTestProvider("")
+	series := p.convertToTimeSeries(testMetrics())
+	assert.Equal(t, 3, len(series))
+
+	got := make(map[string]*prompb.TimeSeries)
+	for _, ts := range series {
+		got[ts.Labels[0].Value] = ts
+	}
+	assert.Equal(t, []*prompb.Label{
+		{Name: "__name__", Value: "docker_container_summary_mem_usage"},
+		{Name: "cluster", Value: "test"},
+		{Name: "host", Value: "node-1"},
+		{Name: "pod_name", Value: "demo;x"},
+	}, got["docker_container_summary_mem_usage"].Labels)
+	assert.Equal(t, []*prompb.Sample{{Value: 1552384, Timestamp: 1640936985459}}, got["docker_container_summary_mem_usage"].Samples)
+	assert.Equal(t, float64(1), got["docker_container_summary_ready"].Samples[0].Value)
+	assert.Equal(t, []*prompb.Label{
+		{Name: "__name__", Value: "container_cpu_usage_seconds_total"},
+		{Name: "cluster", Value: "test"},
+		{Name: "host", Value: "external"},
+		{Name: "job", Value: "cadvisor"},
+	}, got["container_cpu_usage_seconds_total"].Labels)
+}
+
+func Test_sanitize(t *testing.T) {
+	assert.Equal(t, "pod_name", sanitizeLabelName("pod.name"))
+	assert.Equal(t, "_1xx", sanitizeLabelName("1xx"))
+	assert.Equal(t, "a_b", sanitizeLabelName("a:b"))
+	assert.Equal(t, "a:b", sanitizeMetricName("a:b"))
+	assert.Equal(t, "_", sanitizeMetricName(""))
+}
+
+func Test_splitBatches(t *testing.T) {
+	series := make([]*prompb.TimeSeries, 5)
+	batches := splitBatches(series, 2)
+	assert.Equal(t, 3, len(batches))
+	assert.Equal(t, 1, len(batches[2]))
+	assert.Equal(t, 1, len(splitBatches(series, 0)))
+}
+
+func Test_provider_Export(t *testing.T) {
+	var requests, received int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first request fails, and it should be retried
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, remoteWriteVersion, r.Header.Get("X-Prometheus-Remote-Write-Version"))
+		buf, _ := io.ReadAll(r.Body)
+		buf, err := snappy.Decode(nil, buf)
+		assert.NoError(t, err)
+		var wr prompb.WriteRequest
+		assert.NoError(t, proto.Unmarshal(buf, &wr))
+		atomic.AddInt32(&received, int32(len(wr.Timeseries)))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	p := newTestProvider(server.URL)
+	assert.NoError(t, p.Export(testMetrics()))
+	assert.Equal(t, int32(3), requests)
+	assert.Equal(t, int32(3), received)
+}
+
+func Test_provider_Export_NotRecoverable(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.Error(w, "out of order sample", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	p := newTestProvider(server.URL)
+	p.Cfg.MaxSamplesPerSend = 0
+	err := p.Export(testMetrics())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "out of order sample")
+	assert.Equal(t, int32(1), requests)
+}
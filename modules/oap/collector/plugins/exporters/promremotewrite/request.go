@@ -0,0 +1,94 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promremotewrite
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+const (
+	remoteWriteVersion = "0.1.0"
+	maxErrMsgLen       = 256
+)
+
+// recoverableError is the error which can be retried, such as network errors and 5xx responses.
+type recoverableError struct {
+	error
+}
+
+func (p *provider) send(series []*prompb.TimeSeries) error {
+	buf, err := proto.Marshal(&prompb.WriteRequest{Timeseries: series})
+	if err != nil {
+		return fmt.Errorf("marshal err: %w", err)
+	}
+	// remote write uses the snappy block format
+	buf = snappy.Encode(nil, buf)
+
+	backoff := p.Cfg.Retry.MinBackoff
+	for retries := 0; ; retries++ {
+		err := p.doRequest(buf)
+		if err == nil {
+			return nil
+		}
+		var re recoverableError
+		if !errors.As(err, &re) || retries >= p.Cfg.Retry.MaxRetries {
+			return err
+		}
+		p.Log.Warnf("remote write failed, retry after %s: %s", backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > p.Cfg.Retry.MaxBackoff {
+			backoff = p.Cfg.Retry.MaxBackoff
+		}
+	}
+}
+
+func (p *provider) doRequest(buf []byte) error {
+	req, err := http.NewRequest(http.MethodPost, p.Cfg.URL, bytes.NewReader(buf))
+	if err != nil {
+		return fmt.Errorf("create request err: %w", err)
+	}
+	for k, v := range p.Cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", remoteWriteVersion)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return recoverableError{fmt.Errorf("do request err: %w", err)}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrMsgLen))
+	err = fmt.Errorf("server returned HTTP status %s: %s", resp.Status, bytes.TrimSpace(body))
+	if resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests {
+		return recoverableError{err}
+	}
+	return err
+}
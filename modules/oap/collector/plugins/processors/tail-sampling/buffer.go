@@ -0,0 +1,151 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tailsampling
+
+import (
+	"sync"
+	"time"
+
+	tpb "github.com/erda-project/erda-proto-go/oap/trace/pb"
+)
+
+type traceEntry struct {
+	traceID   string
+	firstSeen time.Time
+	spans     []*tpb.Span
+}
+
+type decision struct {
+	sampled   bool
+	decidedAt time.Time
+}
+
+// traceBuffer holds the spans by trace id until the traces are decided,
+// and remembers the decisions for the late spans.
+type traceBuffer struct {
+	policies  *policiesConfig
+	maxTraces int
+	maxSpans  int
+	stats     *statistics
+
+	lock      sync.Mutex
+	traces    map[string]*traceEntry
+	queue     []*traceEntry // ordered by firstSeen
+	numSpans  int
+	decisions map[string]*decision
+	decided   []string // ordered by decidedAt
+}
+
+func newTraceBuffer(policies *policiesConfig, maxTraces, maxSpans int, stats *statistics) *traceBuffer {
+	return &traceBuffer{
+		policies:  policies,
+		maxTraces: maxTraces,
+		maxSpans:  maxSpans,
+		stats:     stats,
+		traces:    make(map[string]*traceEntry),
+		decisions: make(map[string]*decision),
+	}
+}
+
+// add buffers the spans, returns the spans should be forwarded,
+// they are late spans of sampled traces, or spans of traces decided early because of the memory limits.
+func (b *traceBuffer) add(spans []*tpb.Span, now time.Time) []*tpb.Span {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	var out []*tpb.Span
+	for _, span := range spans {
+		if d, ok := b.decisions[span.TraceID]; ok {
+			b.stats.lateSpan(d.sampled)
+			if d.sampled {
+				out = append(out, span)
+			}
+			continue
+		}
+		entry, ok := b.traces[span.TraceID]
+		if !ok {
+			entry = &traceEntry{traceID: span.TraceID, firstSeen: now}
+			b.traces[span.TraceID] = entry
+			b.queue = append(b.queue, entry)
+		}
+		entry.spans = append(entry.spans, span)
+		b.numSpans++
+	}
+	for len(b.traces) > 0 && (len(b.traces) > b.maxTraces || b.numSpans > b.maxSpans) {
+		b.stats.forcedDecision()
+		out = append(out, b.decideOldest(now)...)
+	}
+	b.stats.buffered(len(b.traces), b.numSpans)
+	return out
+}
+
+// flush decides the traces which have been waited for the duration, returns the spans of sampled traces.
+func (b *traceBuffer) flush(now time.Time, wait time.Duration) []*tpb.Span {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	var out []*tpb.Span
+	for len(b.queue) > 0 {
+		entry := b.queue[0]
+		if b.traces[entry.traceID] != entry {
+			b.queue = b.queue[1:]
+			continue
+		}
+		if now.Sub(entry.firstSeen) < wait {
+			break
+		}
+		out = append(out, b.decideOldest(now)...)
+	}
+	b.stats.buffered(len(b.traces), b.numSpans)
+	return out
+}
+
+func (b *traceBuffer) decideOldest(now time.Time) []*tpb.Span {
+	for len(b.queue) > 0 {
+		entry := b.queue[0]
+		b.queue[0] = nil
+		b.queue = b.queue[1:]
+		if b.traces[entry.traceID] != entry {
+			continue
+		}
+		delete(b.traces, entry.traceID)
+		b.numSpans -= len(entry.spans)
+
+		sampled, policy := b.policies.decide(entry.traceID, entry.spans)
+		b.stats.decision(sampled, policy, len(entry.spans))
+		b.decisions[entry.traceID] = &decision{sampled: sampled, decidedAt: now}
+		b.decided = append(b.decided, entry.traceID)
+		if sampled {
+			return entry.spans
+		}
+		return nil
+	}
+	return nil
+}
+
+// expire forgets the decisions made before the ttl, the spans arrived later are treated as new traces.
+func (b *traceBuffer) expire(now time.Time, ttl time.Duration) int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	var n int
+	for n < len(b.decided) {
+		d, ok := b.decisions[b.decided[n]]
+		if ok && now.Sub(d.decidedAt) < ttl {
+			break
+		}
+		delete(b.decisions, b.decided[n])
+		n++
+	}
+	b.decided = b.decided[n:]
+	return n
+}
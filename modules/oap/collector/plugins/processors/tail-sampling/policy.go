@@ -0,0 +1,111 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tailsampling
+
+import (
+	"time"
+
+	"github.com/cespare/xxhash"
+
+	tpb "github.com/erda-project/erda-proto-go/oap/trace/pb"
+)
+
+// tagServiceName is the span attribute of the service, the same as tagServiceName of the receivers
+const tagServiceName = "service_name"
+
+const (
+	policyErrors        = "errors"
+	policyLatency       = "latency"
+	policyServices      = "services"
+	policyTagpass       = "tagpass"
+	policyProbabilistic = "probabilistic"
+	policyNone          = "none"
+)
+
+type policiesConfig struct {
+	// Errors keeps the traces contain span with tag error=true
+	Errors bool `file:"errors" default:"true"`
+	// LatencyThreshold keeps the traces which duration is greater than it, disabled if it's 0
+	LatencyThreshold time.Duration `file:"latency_threshold"`
+	// Services keeps the traces pass through these services
+	Services []string `file:"services"`
+	// Tagpass keeps the traces contain span matched any of these tags
+	Tagpass map[string][]string `file:"tagpass"`
+	// Probabilistic is the percentage of the remaining traces to keep, range [0, 100]
+	Probabilistic float64 `file:"probabilistic" default:"0"`
+}
+
+// decide evaluates the policies in order, returns whether the trace is sampled and the matched policy.
+func (c *policiesConfig) decide(traceID string, spans []*tpb.Span) (bool, string) {
+	if c.Errors && hasError(spans) {
+		return true, policyErrors
+	}
+	if c.LatencyThreshold > 0 && duration(spans) > c.LatencyThreshold {
+		return true, policyLatency
+	}
+	if len(c.Services) > 0 && matchTags(spans, map[string][]string{tagServiceName: c.Services}) {
+		return true, policyServices
+	}
+	if len(c.Tagpass) > 0 && matchTags(spans, c.Tagpass) {
+		return true, policyTagpass
+	}
+	if c.Probabilistic > 0 && xxhash.Sum64String(traceID)%10000 < uint64(c.Probabilistic*100) {
+		return true, policyProbabilistic
+	}
+	return false, policyNone
+}
+
+func hasError(spans []*tpb.Span) bool {
+	for _, span := range spans {
+		if span.Attributes["error"] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// duration returns the time from the earliest start to the latest end of spans.
+func duration(spans []*tpb.Span) time.Duration {
+	var start, end uint64
+	for _, span := range spans {
+		if start == 0 || span.StartTimeUnixNano < start {
+			start = span.StartTimeUnixNano
+		}
+		if span.EndTimeUnixNano > end {
+			end = span.EndTimeUnixNano
+		}
+	}
+	if end <= start {
+		return 0
+	}
+	return time.Duration(end - start)
+}
+
+func matchTags(spans []*tpb.Span, tags map[string][]string) bool {
+	for _, span := range spans {
+		for k, list := range tags {
+			val, ok := span.Attributes[k]
+			if !ok {
+				continue
+			}
+			for _, v := range list {
+				if v == val {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
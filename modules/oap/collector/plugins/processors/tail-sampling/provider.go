@@ -0,0 +1,146 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tailsampling
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/erda-project/erda-infra/base/logs"
+	"github.com/erda-project/erda-infra/base/servicehub"
+	tpb "github.com/erda-project/erda-proto-go/oap/trace/pb"
+	"github.com/erda-project/erda/modules/oap/collector/core/model"
+	"github.com/erda-project/erda/modules/oap/collector/plugins"
+)
+
+var providerName = plugins.WithPrefixProcessor("tail-sampling")
+
+type config struct {
+	// DecisionWait is the time to wait the spans of a trace since the first span arrived
+	DecisionWait time.Duration `file:"decision_wait" default:"10s"`
+	// NumTraces and MaxSpans limit the memory, the oldest traces are decided early if exceeded
+	NumTraces int `file:"num_traces" default:"50000"`
+	MaxSpans  int `file:"max_spans" default:"1000000"`
+	// DecisionCacheTTL is how long the decisions are kept for late spans
+	DecisionCacheTTL time.Duration `file:"decision_cache_ttl" default:"1m"`
+	// ReportInterval is the interval to log the statistics, disabled if it's 0
+	ReportInterval time.Duration  `file:"report_interval" default:"1m"`
+	Policies       policiesConfig `file:"policies"`
+}
+
+// +provider
+type provider struct {
+	Cfg *config
+	Log logs.Logger
+
+	lock     sync.RWMutex
+	consumer model.ObservableDataConsumerFunc
+	buffer   *traceBuffer
+	stats    *statistics
+}
+
+func (p *provider) ComponentID() model.ComponentID {
+	return model.ComponentID(providerName)
+}
+
+// Process holds the spans until the traces are decided, only spans of sampled traces are sent to exporters.
+// The processor should be the last one of pipeline, the sampled traces are consumed directly.
+func (p *provider) Process(data model.ObservableData) (model.ObservableData, error) {
+	spans, ok := data.SourceData().([]*tpb.Span)
+	if !ok {
+		return data, nil
+	}
+	out := p.buffer.add(spans, time.Now())
+	if len(out) <= 0 {
+		return nil, nil
+	}
+	return &model.Traces{Spans: out}, nil
+}
+
+func (p *provider) StartProcessor(consumer model.ObservableDataConsumerFunc) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.consumer = consumer
+}
+
+func (p *provider) getConsumer() model.ObservableDataConsumerFunc {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.consumer
+}
+
+func (p *provider) Init(ctx servicehub.Context) error {
+	if p.Cfg.DecisionWait <= 0 {
+		return fmt.Errorf("decision_wait must be positive")
+	}
+	if p.Cfg.Policies.Probabilistic < 0 || p.Cfg.Policies.Probabilistic > 100 {
+		return fmt.Errorf("invalid probabilistic %v, it should be in range [0, 100]", p.Cfg.Policies.Probabilistic)
+	}
+	p.stats = newStatistics()
+	p.buffer = newTraceBuffer(&p.Cfg.Policies, p.Cfg.NumTraces, p.Cfg.MaxSpans, p.stats)
+	return nil
+}
+
+func (p *provider) Run(ctx context.Context) error {
+	interval := time.Second
+	if p.Cfg.DecisionWait < interval {
+		interval = p.Cfg.DecisionWait
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	lastReport := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		now := time.Now()
+		if p.Cfg.ReportInterval > 0 && now.Sub(lastReport) >= p.Cfg.ReportInterval {
+			p.Log.Infof("tail sampling statistics: %s", p.stats)
+			lastReport = now
+		}
+		if n := p.buffer.expire(now, p.Cfg.DecisionCacheTTL); n > 0 {
+			p.Log.Debugf("forget %d decisions", n)
+		}
+		// keep the decided spans in buffer until the processor is started
+		consumer := p.getConsumer()
+		if consumer == nil {
+			continue
+		}
+		spans := p.buffer.flush(now, p.Cfg.DecisionWait)
+		if len(spans) <= 0 {
+			continue
+		}
+		consumer(&model.Traces{Spans: spans})
+	}
+}
+
+func init() {
+	servicehub.Register(providerName, &servicehub.Spec{
+		Services: []string{
+			providerName,
+		},
+		Description: "here is description of erda.oap.collector.processor.tail-sampling",
+		ConfigFunc: func() interface{} {
+			return &config{}
+		},
+		Creator: func() servicehub.Provider {
+			return &provider{}
+		},
+	})
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tailsampling
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda-infra/base/logs/logrusx"
+	tpb "github.com/erda-project/erda-proto-go/oap/trace/pb"
+	"github.com/erda-project/erda/modules/oap/collector/core/model"
+)
+
+func testSpan(traceID, spanID string, start, end time.Duration, attrs map[string]string) *tpb.Span {
+	return &tpb.Span{
+		TraceID:           traceID,
+		SpanID:            spanID,
+		StartTimeUnixNano: uint64(start),
+		EndTimeUnixNano:   uint64(end),
+		Attributes:        attrs,
+	}
+}
+
+func Test_policiesConfig_decide(t *testing.T) {
+	policies := &policiesConfig{
+		Errors:           true,
+		LatencyThreshold: time.Second,
+		Services:         []string{"order"},
+		Tagpass:          map[string][]string{"http_status_code": {"500", "503"}},
+	}
+	tests := []struct {
+		name    string
+		spans   []*tpb.Span
+		sampled bool
+		policy  string
+	}{
+		{
+			name:    "error",
+			spans:   []*tpb.Span{testSpan("t", "1", 0, 1, map[string]string{"error": "true"})},
+			sampled: true,
+			policy:  policyErrors,
+		},
+		{
+			name: "latency",
+			spans: []*tpb.Span{
+				testSpan("t", "1", 100, 200, nil),
+				testSpan("t", "2", 150, 100+2*time.Second, nil),
+			},
+			sampled: true,
+			policy:  policyLatency,
+		},
+		{
+			name:    "service",
+			spans:   []*tpb.Span{testSpan("t", "1", 0, 1, map[string]string{"service_name": "order"})},
+			sampled: true,
+			policy:  policyServices,
+		},
+		{
+			name:    "tagpass",
+			spans:   []*tpb.Span{testSpan("t", "1", 0, 1, map[string]string{"http_status_code": "503"})},
+			sampled: true,
+			policy:  policyTagpass,
+		},
+		{
+			name:    "none",
+			spans:   []*tpb.Span{testSpan("t", "1", 0, 1, map[string]string{"service_name": "user", "error": "false"})},
+			sampled: false,
+			policy:  policyNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sampled, policy := policies.decide("t", tt.spans)
+			assert.Equal(t, tt.sampled, sampled)
+			assert.Equal(t, tt.policy, policy)
+		})
+	}
+}
+
+func Test_policiesConfig_probabilistic(t *testing.T) {
+	spans := []*tpb.Span{testSpan("t", "1", 0, 1, nil)}
+	all := &policiesConfig{Probabilistic: 100}
+	none := &policiesConfig{Probabilistic: 0}
+	half := &policiesConfig{Probabilistic: 50}
+	var sampled int
+	for i := 0; i < 10000; i++ {
+		id := fmt.Sprintf("%032x", i)
+		ok, _ := all.decide(id, spans)
+		assert.True(t, ok)
+		ok, _ = none.decide(id, spans)
+		assert.False(t, ok)
+		ok, _ = half.decide(id, spans)
+		if ok {
+			sampled++
+		}
+		// the decision of the same trace is stable
+		again, _ := half.decide(id, spans)
+		assert.Equal(t, ok, again)
+	}
+	assert.InDelta(t, 5000, sampled, 300)
+}
+
+func newTestProvider(numTraces, maxSpans int) *provider {
+	p := &provider{Cfg: &config{
+		DecisionWait:     10 * time.Second,
+		NumTraces:        numTraces,
+		MaxSpans:         maxSpans,
+		DecisionCacheTTL: time.Minute,
+		Policies:         policiesConfig{Errors: true},
+	}, Log: logrusx.New()}
+	p.stats = newStatistics()
+	p.buffer = newTraceBuffer(&p.Cfg.Policies, p.Cfg.NumTraces, p.Cfg.MaxSpans, p.stats)
+	return p
+}
+
+func Test_provider_Process(t *testing.T) {
+	p := newTestProvider(100, 1000)
+
+	// metrics are passed through
+	metrics := &model.Metrics{}
+	od, err := p.Process(metrics)
+	assert.NoError(t, err)
+	assert.Equal(t, metrics, od)
+
+	// spans are held
+	od, err = p.Process(&model.Traces{Spans: []*tpb.Span{
+		testSpan("a", "1", 0, 1, nil),
+		testSpan("b", "1", 0, 1, nil),
+	}})
+	assert.NoError(t, err)
+	assert.Nil(t, od)
+	od, err = p.Process(&model.Traces{Spans: []*tpb.Span{
+		testSpan("a", "2", 0, 1, map[string]string{"error": "true"}),
+	}})
+	assert.NoError(t, err)
+	assert.Nil(t, od)
+
+	// not the time to decide
+	now := time.Now()
+	assert.Equal(t, 0, len(p.buffer.flush(now, p.Cfg.DecisionWait)))
+
+	spans := p.buffer.flush(now.Add(p.Cfg.DecisionWait), p.Cfg.DecisionWait)
+	if assert.Equal(t, 2, len(spans)) {
+		assert.Equal(t, "a", spans[0].TraceID)
+		assert.Equal(t, "a", spans[1].TraceID)
+	}
+	assert.Equal(t, 0, len(p.buffer.traces))
+	assert.Equal(t, 0, p.buffer.numSpans)
+
+	// late spans follow the decisions
+	od, err = p.Process(&model.Traces{Spans: []*tpb.Span{
+		testSpan("a", "3", 0, 1, nil),
+		testSpan("b", "2", 0, 1, nil),
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, []*tpb.Span{testSpan("a", "3", 0, 1, nil)}, od.SourceData())
+
+	// forget the decisions
+	assert.Equal(t, 0, p.buffer.expire(now.Add(p.Cfg.DecisionWait), time.Minute))
+	assert.Equal(t, 2, p.buffer.expire(now.Add(p.Cfg.DecisionWait+time.Minute), time.Minute))
+	od, err = p.Process(&model.Traces{Spans: []*tpb.Span{testSpan("a", "4", 0, 1, nil)}})
+	assert.NoError(t, err)
+	assert.Nil(t, od)
+	assert.Equal(t, 1, len(p.buffer.traces))
+}
+
+func Test_provider_Process_MemoryLimit(t *testing.T) {
+	p := newTestProvider(2, 3)
+
+	// exceed num_traces, the oldest trace is decided
+	od, err := p.Process(&model.Traces{Spans: []*tpb.Span{
+		testSpan("a", "1", 0, 1, map[string]string{"error": "true"}),
+		testSpan("b", "1", 0, 1, nil),
+		testSpan("c", "1", 0, 1, nil),
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, []*tpb.Span{testSpan("a", "1", 0, 1, map[string]string{"error": "true"})}, od.SourceData())
+	assert.Equal(t, 2, len(p.buffer.traces))
+
+	// exceed max_spans
+	od, err = p.Process(&model.Traces{Spans: []*tpb.Span{
+		testSpan("c", "2", 0, 1, nil),
+		testSpan("c", "3", 0, 1, nil),
+	}})
+	assert.NoError(t, err)
+	assert.Nil(t, od)
+	assert.Equal(t, 1, len(p.buffer.traces))
+	assert.Equal(t, 3, p.buffer.numSpans)
+	_, ok := p.buffer.traces["c"]
+	assert.True(t, ok)
+	assert.Equal(t, "traces: {not_sampled/none: 1, sampled/errors: 1}, spans: {not_sampled: 1, sampled: 1}, late_spans: {}, "+
+		"forced_decisions: 2, buffered_traces: 1, buffered_spans: 3", p.stats.String())
+}
+
+func Test_provider_Run(t *testing.T) {
+	p := newTestProvider(100, 1000)
+	p.Cfg.DecisionWait = 10 * time.Millisecond
+	_, err := p.Process(&model.Traces{Spans: []*tpb.Span{testSpan("a", "1", 0, 1, map[string]string{"error": "true"})}})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Run(ctx)
+		close(done)
+	}()
+
+	// decided spans are kept until the processor is started
+	time.Sleep(30 * time.Millisecond)
+	consumed := make(chan model.ObservableData, 1)
+	p.StartProcessor(func(od model.ObservableData) {
+		consumed <- od
+	})
+	select {
+	case od := <-consumed:
+		assert.Equal(t, 1, len(od.SourceData().([]*tpb.Span)))
+	case <-time.After(5 * time.Second):
+		t.Error("spans are not consumed")
+	}
+	cancel()
+	<-done
+}
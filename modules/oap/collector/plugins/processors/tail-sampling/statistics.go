@@ -0,0 +1,96 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tailsampling
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// statistics counts the decisions of the processor, it's reported by logs periodically.
+// The prometheus client is not used here, its metrics.proto conflicts with the one of oap.
+type statistics struct {
+	lock           sync.Mutex
+	traces         map[string]int64
+	spans          map[string]int64
+	lateSpans      map[string]int64
+	forced         int64
+	bufferedTraces int
+	bufferedSpans  int
+}
+
+func newStatistics() *statistics {
+	return &statistics{
+		traces:    make(map[string]int64),
+		spans:     make(map[string]int64),
+		lateSpans: make(map[string]int64),
+	}
+}
+
+func decisionLabel(sampled bool) string {
+	if sampled {
+		return "sampled"
+	}
+	return "not_sampled"
+}
+
+func (s *statistics) decision(sampled bool, policy string, spans int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.traces[decisionLabel(sampled)+"/"+policy]++
+	s.spans[decisionLabel(sampled)] += int64(spans)
+}
+
+func (s *statistics) lateSpan(sampled bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.lateSpans[decisionLabel(sampled)]++
+}
+
+func (s *statistics) forcedDecision() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.forced++
+}
+
+func (s *statistics) buffered(traces, spans int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.bufferedTraces = traces
+	s.bufferedSpans = spans
+}
+
+// String returns the counters since the processor started
+func (s *statistics) String() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return fmt.Sprintf("traces: {%s}, spans: {%s}, late_spans: {%s}, forced_decisions: %d, buffered_traces: %d, buffered_spans: %d",
+		formatCounters(s.traces), formatCounters(s.spans), formatCounters(s.lateSpans), s.forced, s.bufferedTraces, s.bufferedSpans)
+}
+
+func formatCounters(counters map[string]int64) string {
+	keys := make([]string, 0, len(counters))
+	for k := range counters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	items := make([]string, 0, len(keys))
+	for _, k := range keys {
+		items = append(items, fmt.Sprintf("%s: %d", k, counters[k]))
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	commonpb "github.com/erda-project/erda-proto-go/oap/common/pb"
+	lpb "github.com/erda-project/erda-proto-go/oap/logs/pb"
+	mpb "github.com/erda-project/erda-proto-go/oap/metrics/pb"
+	tpb "github.com/erda-project/erda-proto-go/oap/trace/pb"
+	"github.com/erda-project/erda/modules/core/monitor/log"
+	"github.com/erda-project/erda/modules/core/monitor/metric"
+	"github.com/erda-project/erda/modules/oap/collector/core/model"
+)
+
+// decodeMetric decode the message of spot-metrics
+func (p *provider) decodeMetric(key, value []byte, topic *string, timestamp time.Time) (interface{}, error) {
+	data := &metric.Metric{}
+	if err := json.Unmarshal(value, data); err != nil {
+		p.Log.Warnf("failed to decode metric: %v", err)
+		return nil, err
+	}
+	dataPoints := make(map[string]*structpb.Value, len(data.Fields))
+	for k, v := range data.Fields {
+		val, err := structpb.NewValue(v)
+		if err != nil {
+			continue
+		}
+		dataPoints[k] = val
+	}
+	return &mpb.Metric{
+		Name:         data.Name,
+		TimeUnixNano: uint64(data.Timestamp),
+		Attributes:   data.Tags,
+		DataPoints:   dataPoints,
+	}, nil
+}
+
+// decodeSpan decode the message of erda-spans
+func (p *provider) decodeSpan(key, value []byte, topic *string, timestamp time.Time) (interface{}, error) {
+	data := &tpb.Span{}
+	if err := json.Unmarshal(value, data); err != nil {
+		p.Log.Warnf("failed to decode span: %v", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// decodeLog decode the message of spot-container-log and spot-job-log
+func (p *provider) decodeLog(key, value []byte, topic *string, timestamp time.Time) (interface{}, error) {
+	data := &log.LabeledLog{}
+	if err := json.Unmarshal(value, data); err != nil {
+		p.Log.Warnf("failed to decode log: %v", err)
+		return nil, err
+	}
+	attrs := make(map[string]string, len(data.Tags)+len(data.Labels)+3)
+	for k, v := range data.Labels {
+		attrs[k] = v
+	}
+	for k, v := range data.Tags {
+		attrs[k] = v
+	}
+	attrs["id"] = data.ID
+	attrs["stream"] = data.Stream
+	attrs["offset"] = strconv.FormatInt(data.Offset, 10)
+	item := &lpb.Log{
+		TimeUnixNano: uint64(data.Timestamp),
+		Name:         data.Source,
+		Severity:     data.Tags["level"],
+		Attributes:   attrs,
+		Content:      data.Content,
+	}
+	traceID, ok := data.Tags["trace_id"]
+	if !ok {
+		traceID = data.Tags["request-id"]
+	}
+	if len(traceID) > 0 {
+		item.Relations = &commonpb.Relation{TraceID: traceID}
+	}
+	return item, nil
+}
+
+func (p *provider) toObservableData(list []interface{}) model.ObservableData {
+	switch p.Cfg.DataType {
+	case model.TraceDataType:
+		spans := make([]*tpb.Span, len(list))
+		for i, item := range list {
+			spans[i] = item.(*tpb.Span)
+		}
+		return &model.Traces{Spans: spans}
+	case model.LogDataType:
+		logs := make([]*lpb.Log, len(list))
+		for i, item := range list {
+			logs[i] = item.(*lpb.Log)
+		}
+		return &model.Logs{Logs: logs}
+	}
+	metrics := make([]*mpb.Metric, len(list))
+	for i, item := range list {
+		metrics[i] = item.(*mpb.Metric)
+	}
+	return &model.Metrics{Metrics: metrics}
+}
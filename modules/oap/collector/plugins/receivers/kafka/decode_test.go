@@ -0,0 +1,80 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda-infra/base/logs/logrusx"
+	lpb "github.com/erda-project/erda-proto-go/oap/logs/pb"
+	mpb "github.com/erda-project/erda-proto-go/oap/metrics/pb"
+	tpb "github.com/erda-project/erda-proto-go/oap/trace/pb"
+	"github.com/erda-project/erda/modules/oap/collector/core/model"
+)
+
+func Test_provider_decodeMetric(t *testing.T) {
+	p := &provider{Cfg: &config{DataType: model.MetricDataType}, Log: logrusx.New()}
+	data, err := p.decodeMetric(nil, []byte(`{"name":"docker_container_summary","timestamp":1640936985459000000,"tags":{"cluster_name":"dev"},"fields":{"mem_usage":1024,"state":"running"}}`), nil, time.Now())
+	assert.NoError(t, err)
+	m := data.(*mpb.Metric)
+	assert.Equal(t, "docker_container_summary", m.Name)
+	assert.Equal(t, uint64(1640936985459000000), m.TimeUnixNano)
+	assert.Equal(t, map[string]string{"cluster_name": "dev"}, m.Attributes)
+	assert.Equal(t, float64(1024), m.DataPoints["mem_usage"].GetNumberValue())
+	assert.Equal(t, "running", m.DataPoints["state"].GetStringValue())
+
+	_, err = p.decodeMetric(nil, []byte(`{`), nil, time.Now())
+	assert.Error(t, err)
+
+	od := p.toObservableData([]interface{}{m})
+	assert.Equal(t, []*mpb.Metric{m}, od.SourceData())
+}
+
+func Test_provider_decodeSpan(t *testing.T) {
+	p := &provider{Cfg: &config{DataType: model.TraceDataType}, Log: logrusx.New()}
+	data, err := p.decodeSpan(nil, []byte(`{"traceID":"0af7651916cd43dd8448eb211c80319c","spanID":"b7ad6b7169203331","name":"GET /","attributes":{"span_kind":"server"}}`), nil, time.Now())
+	assert.NoError(t, err)
+	span := data.(*tpb.Span)
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", span.TraceID)
+	assert.Equal(t, "server", span.Attributes["span_kind"])
+
+	od := p.toObservableData([]interface{}{span})
+	assert.Equal(t, []*tpb.Span{span}, od.SourceData())
+}
+
+func Test_provider_decodeLog(t *testing.T) {
+	p := &provider{Cfg: &config{DataType: model.LogDataType}, Log: logrusx.New()}
+	data, err := p.decodeLog(nil, []byte(`{"source":"container","id":"abc","stream":"stderr","content":"something wrong","offset":10,"timestamp":1640936985459000000,"tags":{"level":"ERROR","request-id":"0af7651916cd43dd8448eb211c80319c"},"labels":{"cluster_name":"dev"}}`), nil, time.Now())
+	assert.NoError(t, err)
+	l := data.(*lpb.Log)
+	assert.Equal(t, "container", l.Name)
+	assert.Equal(t, "ERROR", l.Severity)
+	assert.Equal(t, "something wrong", l.Content)
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", l.Relations.TraceID)
+	assert.Equal(t, map[string]string{
+		"cluster_name": "dev",
+		"level":        "ERROR",
+		"request-id":   "0af7651916cd43dd8448eb211c80319c",
+		"id":           "abc",
+		"stream":       "stderr",
+		"offset":       "10",
+	}, l.Attributes)
+
+	od := p.toObservableData([]interface{}{l})
+	assert.Equal(t, []*lpb.Log{l}, od.SourceData())
+}
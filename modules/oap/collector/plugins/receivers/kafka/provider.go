@@ -0,0 +1,163 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/erda-project/erda-infra/base/logs"
+	"github.com/erda-project/erda-infra/base/servicehub"
+	kafkaInf "github.com/erda-project/erda-infra/providers/kafka"
+	"github.com/erda-project/erda/modules/oap/collector/core/model"
+	"github.com/erda-project/erda/modules/oap/collector/plugins"
+)
+
+var providerName = plugins.WithPrefixReceiver("kafka")
+
+type config struct {
+	// DataType is the format of messages, metric(spot-metrics), trace(erda-spans) or log(spot-container-log, spot-job-log)
+	DataType    model.DataType             `file:"data_type" default:"metric"`
+	Consumer    kafkaInf.BatchReaderConfig `file:"consumer"`
+	Parallelism int                        `file:"parallelism" default:"1"`
+	BatchSize   int                        `file:"batch_size" default:"100"`
+	ReadTimeout time.Duration              `file:"read_timeout" default:"1s"`
+}
+
+// +provider
+type provider struct {
+	Cfg   *config
+	Log   logs.Logger
+	Kafka kafkaInf.Interface `autowired:"kafka"`
+
+	decoder kafkaInf.Decoder
+	lock    sync.RWMutex
+
+	consumerFunc model.ObservableDataConsumerFunc
+}
+
+func (p *provider) RegisterConsumer(consumer model.ObservableDataConsumerFunc) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.consumerFunc = consumer
+}
+
+func (p *provider) getConsumer() model.ObservableDataConsumerFunc {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.consumerFunc
+}
+
+func (p *provider) ComponentID() model.ComponentID {
+	return model.ComponentID(providerName)
+}
+
+func (p *provider) Init(ctx servicehub.Context) error {
+	switch p.Cfg.DataType {
+	case model.MetricDataType:
+		p.decoder = p.decodeMetric
+	case model.TraceDataType:
+		p.decoder = p.decodeSpan
+	case model.LogDataType:
+		p.decoder = p.decodeLog
+	default:
+		return fmt.Errorf("unsupported data_type: %s", p.Cfg.DataType)
+	}
+	for i := 0; i < p.Cfg.Parallelism; i++ {
+		ctx.AddTask(p.consume, servicehub.WithTaskName(fmt.Sprintf("consumer(%d)", i)))
+	}
+	return nil
+}
+
+func (p *provider) consume(ctx context.Context) error {
+	// messages are not read until the pipeline is started, avoid to commit the messages without consumer
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for p.getConsumer() == nil {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	r, err := p.Kafka.NewBatchReader(&p.Cfg.Consumer, kafkaInf.WithReaderDecoder(p.decoder))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	buf := make([]interface{}, p.Cfg.BatchSize)
+	var backoff time.Duration
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+		n, err := r.ReadN(buf, p.Cfg.ReadTimeout)
+		if err != nil {
+			backoff = nextReadBackoff(backoff)
+			p.Log.Errorf("failed to read from kafka: %s, retry after %s", err, backoff)
+			select {
+			case <-time.After(backoff):
+				continue // continue read
+			case <-ctx.Done():
+				return nil
+			}
+		}
+		backoff = 0
+		if n <= 0 {
+			continue
+		}
+		p.getConsumer()(p.toObservableData(buf[:n]))
+		if err := r.Confirm(); err != nil {
+			p.Log.Errorf("failed to confirm from kafka: %s", err)
+			return err
+		}
+	}
+}
+
+const (
+	minReadBackoff = 100 * time.Millisecond
+	maxReadBackoff = 30 * time.Second
+)
+
+// nextReadBackoff double the backoff after each read error, avoid spinning while the broker is down
+func nextReadBackoff(backoff time.Duration) time.Duration {
+	if backoff < minReadBackoff {
+		return minReadBackoff
+	}
+	if backoff *= 2; backoff > maxReadBackoff {
+		return maxReadBackoff
+	}
+	return backoff
+}
+
+func init() {
+	servicehub.Register(providerName, &servicehub.Spec{
+		Services: []string{
+			providerName,
+		},
+		Description: "here is description of erda.oap.collector.receiver.kafka",
+		ConfigFunc: func() interface{} {
+			return &config{}
+		},
+		Creator: func() servicehub.Provider {
+			return &provider{}
+		},
+	})
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_nextReadBackoff(t *testing.T) {
+	var backoff time.Duration
+	var list []time.Duration
+	for i := 0; i < 12; i++ {
+		backoff = nextReadBackoff(backoff)
+		list = append(list, backoff)
+	}
+	assert.Equal(t, minReadBackoff, list[0])
+	assert.Equal(t, 2*minReadBackoff, list[1])
+	assert.Equal(t, maxReadBackoff, list[len(list)-1])
+}
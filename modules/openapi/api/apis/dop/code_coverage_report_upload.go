@@ -0,0 +1,34 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dop
+
+import (
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/openapi/api/apis"
+)
+
+var CODE_COVERAGE_REPORT_UPLOAD = apis.ApiSpec{
+	Path:         "/api/code-coverage/reports/actions/upload",
+	BackendPath:  "/api/code-coverage/reports/actions/upload",
+	Host:         "dop.marathon.l4lb.thisdcos.directory:9527",
+	Scheme:       "http",
+	Method:       "POST",
+	CheckLogin:   true,
+	CheckToken:   true,
+	Doc:          "summary: 上传 go coverprofile, lcov 或 cobertura 覆盖率报告",
+	RequestType:  apistructs.CodeCoverageReportUploadRequest{},
+	ResponseType: apistructs.CodeCoverageReportUploadResponse{},
+	IsOpenAPI:    true,
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"net/http"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/openapi/api/apis"
+)
+
+var PIPELINE_TASK_UPLOAD_CACHE = apis.ApiSpec{
+	Path:         "/api/pipelines/<pipelineID>/tasks/<taskID>/actions/upload-cache",
+	BackendPath:  "/api/pipelines/<pipelineID>/tasks/<taskID>/actions/upload-cache",
+	Host:         "pipeline.marathon.l4lb.thisdcos.directory:3081",
+	Scheme:       "http",
+	Method:       http.MethodPut,
+	CheckLogin:   false,
+	CheckToken:   true,
+	ChunkAPI:     true,
+	RequestType:  apistructs.PipelineActionCacheUploadRequest{},
+	ResponseType: apistructs.PipelineActionCacheUploadResponse{},
+	Doc:          "summary: task 调用 pipeline 上传远程缓存",
+}
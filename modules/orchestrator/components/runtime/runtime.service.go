@@ -352,6 +352,7 @@ func fillInspectDataWithServiceGroup(data *pb.RuntimeInspect, targetService dice
 	sg *apistructs.ServiceGroup, domainMap map[string][]string, status string) {
 	statusServiceMap := map[string]string{}
 	replicaMap := map[string]int{}
+	autoscaledMap := map[string]*pb.Deployments{}
 	resourceMap := map[string]*pb.Resources{}
 	statusMap := make(map[string]*pb.StatusMap)
 	if sg != nil {
@@ -369,6 +370,12 @@ func fillInspectDataWithServiceGroup(data *pb.RuntimeInspect, targetService dice
 		for _, v := range sg.Services {
 			statusServiceMap[v.Name] = string(v.StatusDesc.Status)
 			replicaMap[v.Name] = v.Scale
+			if v.Autoscaling != nil {
+				autoscaledMap[v.Name] = &pb.Deployments{
+					CurrentReplicas: uint64(v.CurrentReplicas),
+					DesiredReplicas: uint64(v.DesiredReplicas),
+				}
+			}
 			resourceMap[v.Name] = &pb.Resources{
 				Cpu:  v.Resources.Cpu,
 				Mem:  int64(int(v.Resources.Mem)),
@@ -410,6 +417,14 @@ func fillInspectDataWithServiceGroup(data *pb.RuntimeInspect, targetService dice
 		if sgReplicas, ok := replicaMap[k]; ok {
 			runtimeInspectService.Deployments.Replicas = uint64(sgReplicas)
 		}
+		if autoscaled, ok := autoscaledMap[k]; ok {
+			runtimeInspectService.Deployments.CurrentReplicas = autoscaled.CurrentReplicas
+			runtimeInspectService.Deployments.DesiredReplicas = autoscaled.DesiredReplicas
+			if autoscaled.CurrentReplicas > 0 {
+				// the running replicas are decided by autoscaler
+				runtimeInspectService.Deployments.Replicas = autoscaled.CurrentReplicas
+			}
+		}
 		if sgResources, ok := resourceMap[k]; ok {
 			runtimeInspectService.Resources = sgResources
 		}
@@ -76,6 +76,8 @@ type DeploymentExtra struct {
 	CancelEndAt         *time.Time `json:"cancelEndAt,omitempty"`
 	ForceCanceled       bool       `json:"forceCanceled,omitempty"`
 	AutoTimeout         bool       `json:"autoTimeout,omitempty"`
+	// Rollout progress of canary or blue-green deployment, nil for rolling update
+	Rollout *apistructs.DeploymentRollout `json:"rollout,omitempty"`
 }
 
 func (ex DeploymentExtra) Value() (driver.Value, error) {
@@ -133,6 +135,26 @@ func (db *DBClient) UpdateDeployment(deployment *Deployment) error {
 	return nil
 }
 
+// UpdateDeploymentRollout locks the deployment row and saves it after updated by fn,
+// so the rollout progress saved by the deploy loop and the promote requested by user are not overwritten by each other
+func (db *DBClient) UpdateDeploymentRollout(id uint64, fn func(deployment *Deployment) error) error {
+	tx := db.Begin()
+	defer tx.RollbackUnlessCommitted()
+
+	var deployment Deployment
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", id).First(&deployment).Error; err != nil {
+		return errors.Wrapf(err, "failed to get deployment %d", id)
+	}
+	if err := fn(&deployment); err != nil {
+		return err
+	}
+	if err := tx.Save(&deployment).Error; err != nil {
+		return errors.Wrapf(err, "failed to update deployment, id: %v, runtimeId: %v",
+			deployment.ID, deployment.RuntimeId)
+	}
+	return tx.Commit().Error
+}
+
 func (db *DBClient) GetDeployment(id uint64) (*Deployment, error) {
 	var deployment Deployment
 	if err := db.
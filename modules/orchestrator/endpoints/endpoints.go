@@ -205,6 +205,8 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/runtimes/{runtimeID}/actions/redeploy-action", Method: http.MethodPost, Handler: e.RedeployRuntimeAction},
 		{Path: "/api/runtimes/{runtimeID}/actions/rollback", Method: http.MethodPost, Handler: e.RollbackRuntimeAction},
 		{Path: "/api/runtimes/{runtimeID}/actions/rollback-action", Method: http.MethodPost, Handler: e.RollbackRuntimeAction},
+		{Path: "/api/runtimes/{runtimeID}/actions/promote", Method: http.MethodPost, Handler: e.PromoteRuntime},
+		{Path: "/api/runtimes/{runtimeID}/actions/abort", Method: http.MethodPost, Handler: e.AbortRuntime},
 		{Path: "/api/runtimes/actions/bulk-get-status", Method: http.MethodGet, Handler: e.epBulkGetRuntimeStatusDetail},
 		{Path: "/api/runtimes/actions/update-pre-overlay", Method: http.MethodPut, Handler: e.epUpdateOverlay},
 		{Path: "/api/runtimes/actions/batch-update-pre-overlay", Method: http.MethodPut, Handler: e.BatchUpdateOverlay},
@@ -228,6 +228,42 @@ func (e *Endpoints) RollbackRuntimeAction(ctx context.Context, r *http.Request,
 	return httpserver.OkResp(data)
 }
 
+// PromoteRuntime 推进金丝雀或蓝绿发布
+func (e *Endpoints) PromoteRuntime(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	operator, err := user.GetUserID(r)
+	if err != nil {
+		return apierrors.ErrPromoteRuntime.NotLogin().ToResp(), nil
+	}
+	v := vars["runtimeID"]
+	runtimeID, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return apierrors.ErrPromoteRuntime.InvalidParameter(strutil.Concat("runtimeID: ", v)).ToResp(), nil
+	}
+	data, err := e.deployment.PromoteRollout(operator, runtimeID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(data)
+}
+
+// AbortRuntime 终止金丝雀或蓝绿发布并回滚
+func (e *Endpoints) AbortRuntime(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	operator, err := user.GetUserID(r)
+	if err != nil {
+		return apierrors.ErrAbortRuntime.NotLogin().ToResp(), nil
+	}
+	v := vars["runtimeID"]
+	runtimeID, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return apierrors.ErrAbortRuntime.InvalidParameter(strutil.Concat("runtimeID: ", v)).ToResp(), nil
+	}
+	data, err := e.deployment.AbortRollout(operator, runtimeID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(data)
+}
+
 // Rollback 回滚应用实例
 func (e *Endpoints) RollbackRuntime(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
 	orgID, err := getOrgID(r)
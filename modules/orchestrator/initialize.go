@@ -172,6 +172,7 @@ func (p *provider) initEndpoints(db *dbclient.DBClient) (*endpoints.Endpoints, e
 		deployment.WithMigration(migration),
 		deployment.WithEncrypt(encrypt),
 		deployment.WithResource(resource),
+		deployment.WithRuntime(rt),
 	)
 
 	// init domain service
@@ -25,6 +25,8 @@ var (
 	ErrDeleteRuntime   = err("ErrDeleteRuntime", "删除应用实例失败")
 	ErrDeployRuntime   = err("ErrDeployRuntime", "部署失败")
 	ErrRollbackRuntime = err("ErrRollbackRuntime", "回滚失败")
+	ErrPromoteRuntime  = err("ErrPromoteRuntime", "推进发布失败")
+	ErrAbortRuntime    = err("ErrAbortRuntime", "终止发布失败")
 	ErrListRuntime     = err("ErrListRuntime", "查询应用实例列表失败")
 	ErrGetRuntime      = err("ErrGetRuntime", "查询应用实例失败")
 	ErrUpdateRuntime   = err("ErrUpdateRuntime", "更新应用实例失败")
@@ -34,6 +34,7 @@ import (
 	"github.com/erda-project/erda/modules/orchestrator/services/apierrors"
 	"github.com/erda-project/erda/modules/orchestrator/services/migration"
 	"github.com/erda-project/erda/modules/orchestrator/services/resource"
+	"github.com/erda-project/erda/modules/orchestrator/services/runtime"
 	"github.com/erda-project/erda/modules/pkg/user"
 	"github.com/erda-project/erda/pkg/crypto/encryption"
 	"github.com/erda-project/erda/pkg/parser/diceyml"
@@ -49,6 +50,7 @@ type Deployment struct {
 	resource  *resource.Resource
 	migration *migration.Migration
 	encrypt   *encryption.EnvEncrypt
+	runtime   *runtime.Runtime
 }
 
 // Option 部署对象配置选项
@@ -112,6 +114,13 @@ func WithResource(resource *resource.Resource) Option {
 	}
 }
 
+// WithRuntime 配置 runtime service
+func WithRuntime(rt *runtime.Runtime) Option {
+	return func(d *Deployment) {
+		d.runtime = rt
+	}
+}
+
 func (d *Deployment) ContinueDeploy(deploymentID uint64) error {
 	// prepare the context
 	fsm := NewFSMContext(deploymentID, d.db, d.evMgr, d.bdl, d.addon, d.migration, d.encrypt, d.resource)
@@ -131,12 +140,17 @@ func (d *Deployment) ContinueDeploy(deploymentID uint64) error {
 	case apistructs.DeploymentStatusWaiting:
 		return fsm.continueWaiting()
 	case apistructs.DeploymentStatusDeploying:
-		return fsm.continueDeploying()
+		if err := fsm.continueDeploying(); err != nil {
+			return err
+		}
 	case apistructs.DeploymentStatusCanceling:
-		return fsm.continueCanceling()
+		if err := fsm.continueCanceling(); err != nil {
+			return err
+		}
 	default:
 		return nil
 	}
+	return d.rollbackRuntime(fsm, fsm.Deployment.Operator)
 }
 
 func (d *Deployment) CancelLastDeploy(runtimeID uint64, operator string, force bool) error {
@@ -377,6 +391,7 @@ func (d *Deployment) GetStatus(deploymentID uint64) (*apistructs.DeploymentStatu
 		FailCause:    deployment.FailCause,
 		ModuleErrMsg: statusMap,
 		Runtime:      rt,
+		Rollout:      deployment.Extra.Rollout,
 	}, nil
 }
 
@@ -73,6 +73,10 @@ type DeployFSMContext struct {
 	migration *migration.Migration
 	resource  *resource.Resource
 	encrypt   *encryption.EnvEncrypt
+
+	// needRollback the new version of a canary or blue-green deployment is removed,
+	// the runtime should be rolled back to the last successful deployment
+	needRollback bool
 }
 
 // TODO: context should base on deployment service
@@ -157,6 +161,10 @@ func (fsm *DeployFSMContext) IsEnabledProjectNamespace() bool {
 }
 
 func (fsm *DeployFSMContext) timeout() (bool, error) {
+	// rollout may pause for a long time, and it has its own health check deadline
+	if fsm.Deployment.Phase == apistructs.DeploymentPhaseRollout {
+		return false, nil
+	}
 	now := time.Now()
 	if now.Sub(fsm.Deployment.UpdatedAt) > 1*time.Hour {
 		fsm.Deployment.Extra.AutoTimeout = true
@@ -243,6 +251,8 @@ func (fsm *DeployFSMContext) continueDeploying() error {
 		return fsm.continuePhasePreService()
 	case apistructs.DeploymentPhaseService:
 		return fsm.continuePhaseService()
+	case apistructs.DeploymentPhaseRollout:
+		return fsm.continuePhaseRollout()
 	case apistructs.DeploymentPhaseRegister:
 		return fsm.continuePhaseRegister()
 	case apistructs.DeploymentPhaseCompleted:
@@ -257,6 +267,9 @@ func (fsm *DeployFSMContext) continueCanceling() error {
 		return nil
 	}
 	if fsm.Deployment.Extra.CancelStartAt == nil {
+		if fsm.Deployment.Phase == apistructs.DeploymentPhaseRollout {
+			return fsm.abortRollout()
+		}
 		if fsm.Deployment.Phase == apistructs.DeploymentPhaseService {
 			now := time.Now()
 			// set start at before invoke scheduler (if error occur, we can keep the startAt)
@@ -402,6 +415,7 @@ func (fsm *DeployFSMContext) continuePhasePreService() error {
 
 	// do start deploying
 	fsm.pushLog(`service deploying...`)
+	fsm.initRollout()
 	if err := fsm.deployService(); err != nil {
 		return fsm.failDeploy(err)
 	}
@@ -618,6 +632,10 @@ func (fsm *DeployFSMContext) continuePhaseService() error {
 		fsm.Deployment.Phase != apistructs.DeploymentPhaseService {
 		return nil
 	}
+	// canary or blue-green deployment checks the new version in rollout phase
+	if fsm.Deployment.Extra.Rollout != nil {
+		return fsm.pushOnPhase(apistructs.DeploymentPhaseRollout)
+	}
 	fsm.pushLog(" * checking service...")
 	if p, err := fsm.checkServiceReady(); err != nil {
 		return fsm.failDeploy(err)
@@ -896,6 +914,9 @@ func (fsm *DeployFSMContext) deployService() error {
 	if err != nil {
 		return err
 	}
+	if fsm.Deployment.Extra.Rollout != nil {
+		setRolloutLabels(&group.DiceYml, fsm.Deployment.Extra.Rollout, apistructs.RolloutPhaseProgressing)
+	}
 
 	// precheck，检查标签匹配，如果没有机器能匹配上，走下去也是pending的
 	precheckResp, err := fsm.bdl.PrecheckServiceGroup(apistructs.ServiceGroupPrecheckRequest(group))
@@ -0,0 +1,375 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/orchestrator/dbclient"
+	"github.com/erda-project/erda/modules/orchestrator/services/apierrors"
+	"github.com/erda-project/erda/modules/pkg/user"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+	"github.com/erda-project/erda/pkg/parser/diceyml"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// rolloutHealthTimeout the new version which is not healthy in time fails the rollout,
+// and the runtime is rolled back to the last successful deployment
+const rolloutHealthTimeout = 10 * time.Minute
+
+// rolloutServices returns the strategy of each service deployed by canary or blue-green
+func rolloutServices(obj *diceyml.Object) map[string]string {
+	services := map[string]string{}
+	for name, service := range obj.Services {
+		if service.Deployments.Strategy.Progressive() {
+			services[name] = service.Deployments.Strategy.Type
+		}
+	}
+	return services
+}
+
+// rolloutSteps canary has as many steps as defined, blue-green has only one step before promote
+func rolloutSteps(obj *diceyml.Object) int {
+	steps := 0
+	for _, service := range obj.Services {
+		n := 0
+		switch {
+		case !service.Deployments.Strategy.Progressive():
+			continue
+		case service.Deployments.Strategy.Type == diceyml.DeploymentStrategyCanary:
+			n = len(service.Deployments.Strategy.Steps)
+		default:
+			n = 1
+		}
+		if n > steps {
+			steps = n
+		}
+	}
+	return steps
+}
+
+// rolloutPause how long the step pauses after the new version is ready,
+// manual is true if any service waits for manual promote at the step
+func rolloutPause(obj *diceyml.Object, step int) (pause time.Duration, manual bool) {
+	for _, service := range obj.Services {
+		strategy := service.Deployments.Strategy
+		if !strategy.Progressive() {
+			continue
+		}
+		if strategy.Type == diceyml.DeploymentStrategyBlueGreen {
+			if step == 0 {
+				manual = true
+			}
+			continue
+		}
+		if step >= len(strategy.Steps) {
+			continue
+		}
+		if strategy.Steps[step].Pause == 0 {
+			manual = true
+			continue
+		}
+		if d := time.Duration(strategy.Steps[step].Pause) * time.Second; d > pause {
+			pause = d
+		}
+	}
+	return pause, manual
+}
+
+// setRolloutLabels tells the scheduler how to update the progressive services
+func setRolloutLabels(obj *diceyml.Object, rollout *apistructs.DeploymentRollout, phase string) {
+	for name, strategy := range rollout.Services {
+		service, ok := obj.Services[name]
+		if !ok || service == nil {
+			continue
+		}
+		if service.Labels == nil {
+			service.Labels = map[string]string{}
+		}
+		service.Labels[apistructs.LabelRolloutStrategy] = strategy
+		service.Labels[apistructs.LabelRolloutPhase] = phase
+		if strategy == diceyml.DeploymentStrategyCanary && service.Deployments.Strategy != nil {
+			steps := service.Deployments.Strategy.Steps
+			if len(steps) == 0 {
+				continue
+			}
+			// services with fewer steps keep the weight of their last step
+			step := rollout.Step
+			if step >= len(steps) {
+				step = len(steps) - 1
+			}
+			service.Labels[apistructs.LabelRolloutWeight] = strconv.Itoa(steps[step].Weight)
+		}
+	}
+}
+
+// initRollout decides whether the services are deployed by canary or blue-green before deploying
+func (fsm *DeployFSMContext) initRollout() {
+	if fsm.Deployment.Extra.Rollout != nil {
+		return
+	}
+	// the first deployment has no old version to run beside,
+	// redeploy and rollback use a release which has already run
+	if !fsm.Runtime.Deployed || fsm.Deployment.Type == "REDEPLOY" {
+		return
+	}
+	services := rolloutServices(fsm.Spec)
+	if len(services) == 0 {
+		return
+	}
+	now := time.Now()
+	fsm.Deployment.Extra.Rollout = &apistructs.DeploymentRollout{
+		Services:    services,
+		Steps:       rolloutSteps(fsm.Spec),
+		StepStartAt: &now,
+	}
+	fsm.pushLog(fmt.Sprintf("progressive deployment: %v", services))
+}
+
+func (fsm *DeployFSMContext) continuePhaseRollout() error {
+	if fsm.Deployment.Status != apistructs.DeploymentStatusDeploying ||
+		fsm.Deployment.Phase != apistructs.DeploymentPhaseRollout {
+		return nil
+	}
+	rollout := fsm.Deployment.Extra.Rollout
+	if rollout == nil {
+		return fsm.pushOnPhase(apistructs.DeploymentPhaseRegister)
+	}
+	now := time.Now()
+	if !fsm.checkRolloutReady() {
+		if rollout.StepStartAt != nil && now.Sub(*rollout.StepStartAt) > rolloutHealthTimeout {
+			return fsm.failRollout(errors.Errorf("new version is not healthy in %s", rolloutHealthTimeout))
+		}
+		return nil
+	}
+	if rollout.Promoted {
+		// blue-green switches the traffic back to the stable deployment which runs the new version now
+		if err := fsm.updateRollout(apistructs.RolloutPhaseComplete); err != nil {
+			fsm.pushLog(fmt.Sprintf("failed to complete rollout, (%v)", err))
+			return err
+		}
+		fsm.pushLog("rollout completed, all traffic is switched to the new version")
+		return fsm.pushOnPhase(apistructs.DeploymentPhaseRegister)
+	}
+	pause, manual := rolloutPause(fsm.Spec, rollout.Step)
+	if rollout.StepReadyAt == nil {
+		rollout.StepReadyAt = &now
+		rollout.WaitPromote = manual
+		fsm.pushLog(fmt.Sprintf("rollout step %d/%d is ready", rollout.Step+1, rollout.Steps))
+		if manual {
+			fsm.pushLog("waiting for promote...")
+		}
+		return fsm.saveRollout()
+	}
+	if !rollout.PromoteRequested && (manual || now.Sub(*rollout.StepReadyAt) < pause) {
+		return nil
+	}
+	return fsm.nextRolloutStep()
+}
+
+// checkRolloutReady true means the new version of current step is healthy
+func (fsm *DeployFSMContext) checkRolloutReady() bool {
+	rollout := fsm.Deployment.Extra.Rollout
+	if rollout.StepStartAt != nil && time.Now().Before(rollout.StepStartAt.Add(30*time.Second)) {
+		// too early to check
+		return false
+	}
+	sg, err := fsm.getServiceGroup()
+	if err != nil {
+		fsm.pushLog(fmt.Sprintf("failed to get service status, %v", err))
+		return false
+	}
+	return sg.Status == apistructs.StatusReady || sg.Status == apistructs.StatusHealthy
+}
+
+// nextRolloutStep increases the canary weight, or promotes the new version after the last step
+func (fsm *DeployFSMContext) nextRolloutStep() error {
+	rollout := fsm.Deployment.Extra.Rollout
+	phase := apistructs.RolloutPhaseProgressing
+	rollout.Step++
+	if rollout.Step >= rollout.Steps {
+		rollout.Promoted = true
+		phase = apistructs.RolloutPhasePromote
+		fsm.pushLog("promoting the new version...")
+	} else {
+		fsm.pushLog(fmt.Sprintf("rollout step %d/%d starting...", rollout.Step+1, rollout.Steps))
+	}
+	now := time.Now()
+	rollout.StepStartAt = &now
+	rollout.StepReadyAt = nil
+	rollout.WaitPromote = false
+	rollout.PromoteRequested = false
+	if err := fsm.updateRollout(phase); err != nil {
+		return fsm.failRollout(err)
+	}
+	return fsm.saveRollout()
+}
+
+// saveRollout saves the rollout progress of the deploy loop,
+// the promote requested by user for the current step after the deployment was loaded is kept
+func (fsm *DeployFSMContext) saveRollout() error {
+	rollout := fsm.Deployment.Extra.Rollout
+	return fsm.db.UpdateDeploymentRollout(fsm.Deployment.ID, func(deployment *dbclient.Deployment) error {
+		if current := deployment.Extra.Rollout; current != nil && current.PromoteRequested && current.Step == rollout.Step {
+			rollout.PromoteRequested = true
+		}
+		deployment.Extra.Rollout = rollout
+		return nil
+	})
+}
+
+// updateRollout sends the service group with rollout labels to scheduler
+func (fsm *DeployFSMContext) updateRollout(phase string) error {
+	projectAddons, err := fsm.db.GetAliveProjectAddons(strconv.FormatUint(fsm.Runtime.ProjectID, 10), fsm.Runtime.ClusterName, fsm.Runtime.Workspace)
+	if err != nil {
+		return err
+	}
+	projectAddonTenants, err := fsm.db.ListAddonInstanceTenantByProjectIDs([]uint64{fsm.Runtime.ProjectID}, fsm.Runtime.Workspace)
+	if err != nil {
+		return err
+	}
+	group := apistructs.ServiceGroupCreateV2Request{}
+	if _, _, err := fsm.generateDeployServiceRequest(&group, *projectAddons, projectAddonTenants); err != nil {
+		return err
+	}
+	setRolloutLabels(&group.DiceYml, fsm.Deployment.Extra.Rollout, phase)
+	return fsm.UpdateServiceGroupWithLoop(group)
+}
+
+// failRollout removes the new version and fails the deployment
+func (fsm *DeployFSMContext) failRollout(oriErr error) error {
+	if err := fsm.updateRollout(apistructs.RolloutPhaseAbort); err != nil {
+		fsm.pushLog(fmt.Sprintf("failed to remove the new version, (%v)", err))
+	}
+	fsm.needRollback = true
+	return fsm.failDeploy(oriErr)
+}
+
+// abortRollout removes the new version and cancels the deployment
+func (fsm *DeployFSMContext) abortRollout() error {
+	fsm.pushLog("aborting rollout...")
+	if err := fsm.updateRollout(apistructs.RolloutPhaseAbort); err != nil {
+		return err
+	}
+	fsm.needRollback = true
+	return fsm.pushOnCanceled()
+}
+
+// rollbackRuntime rolls back the runtime to the last successful deployment after the new version is removed,
+// so the service group in scheduler is the same as the running one again
+func (d *Deployment) rollbackRuntime(fsm *DeployFSMContext, operator string) error {
+	if !fsm.needRollback {
+		return nil
+	}
+	deployments, err := d.db.FindSuccessfulDeployments(fsm.Runtime.ID, 1)
+	if err != nil {
+		return err
+	}
+	if len(deployments) == 0 {
+		fsm.pushLog("no successful deployment to rollback to")
+		return nil
+	}
+	fsm.pushLog(fmt.Sprintf("rolling back to deployment %d...", deployments[0].ID))
+	if _, err := d.runtime.Rollback(user.ID(operator), fsm.App.OrgID, fsm.Runtime.ID, deployments[0].ID); err != nil {
+		fsm.pushLog(fmt.Sprintf("failed to rollback, (%v)", err))
+		return err
+	}
+	fsm.Deployment.Extra.Rollout.RolledBack = true
+	return fsm.db.UpdateDeployment(fsm.Deployment)
+}
+
+// loadRollout loads the deployment in rollout phase of the runtime
+func (d *Deployment) loadRollout(operator user.ID, runtimeID uint64, apiErr *errorresp.APIError) (*DeployFSMContext, error) {
+	deployment, err := d.db.FindLastDeployment(runtimeID)
+	if err != nil {
+		return nil, apiErr.InternalError(err)
+	}
+	if deployment == nil {
+		return nil, apiErr.NotFound()
+	}
+	fsm := NewFSMContext(deployment.ID, d.db, d.evMgr, d.bdl, d.addon, d.migration, d.encrypt, d.resource)
+	if err := fsm.Load(); err != nil {
+		return nil, apiErr.InternalError(err)
+	}
+	perm, err := d.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+		UserID:   operator.String(),
+		Scope:    apistructs.AppScope,
+		ScopeID:  fsm.Runtime.ApplicationID,
+		Resource: "runtime-" + strutil.ToLower(fsm.Runtime.Workspace),
+		Action:   apistructs.OperateAction,
+	})
+	if err != nil {
+		return nil, apiErr.InternalError(err)
+	}
+	if !perm.Access {
+		return nil, apiErr.AccessDenied()
+	}
+	if fsm.Deployment.Status != apistructs.DeploymentStatusDeploying ||
+		fsm.Deployment.Phase != apistructs.DeploymentPhaseRollout || fsm.Deployment.Extra.Rollout == nil {
+		return nil, apiErr.InvalidState("没有进行中的金丝雀或蓝绿发布")
+	}
+	return fsm, nil
+}
+
+// PromoteRollout 推进金丝雀发布到下一步, 或将蓝绿发布的流量切换到新版本
+func (d *Deployment) PromoteRollout(operator user.ID, runtimeID uint64) (*apistructs.DeploymentRollout, error) {
+	fsm, err := d.loadRollout(operator, runtimeID, apierrors.ErrPromoteRuntime)
+	if err != nil {
+		return nil, err
+	}
+	// the deploy loop may save the deployment at the same time, so only the promote request is updated on the latest row
+	var rollout *apistructs.DeploymentRollout
+	err = d.db.UpdateDeploymentRollout(fsm.Deployment.ID, func(deployment *dbclient.Deployment) error {
+		rollout = deployment.Extra.Rollout
+		if deployment.Status != apistructs.DeploymentStatusDeploying ||
+			deployment.Phase != apistructs.DeploymentPhaseRollout || rollout == nil {
+			return apierrors.ErrPromoteRuntime.InvalidState("没有进行中的金丝雀或蓝绿发布")
+		}
+		if rollout.Promoted {
+			return apierrors.ErrPromoteRuntime.InvalidState("新版本已全量发布")
+		}
+		rollout.PromoteRequested = true
+		return nil
+	})
+	if err != nil {
+		if apiErr, ok := err.(*errorresp.APIError); ok {
+			return nil, apiErr
+		}
+		return nil, apierrors.ErrPromoteRuntime.InternalError(err)
+	}
+	fsm.pushLog(fmt.Sprintf("rollout promoted by %s", operator))
+	return rollout, nil
+}
+
+// AbortRollout 终止金丝雀或蓝绿发布, 删除新版本并回滚到上一次成功的部署
+func (d *Deployment) AbortRollout(operator user.ID, runtimeID uint64) (*apistructs.DeploymentRollout, error) {
+	fsm, err := d.loadRollout(operator, runtimeID, apierrors.ErrAbortRuntime)
+	if err != nil {
+		return nil, err
+	}
+	fsm.pushLog(fmt.Sprintf("rollout aborted by %s", operator))
+	if err := fsm.abortRollout(); err != nil {
+		return nil, apierrors.ErrAbortRuntime.InternalError(err)
+	}
+	if err := d.rollbackRuntime(fsm, operator.String()); err != nil {
+		return nil, apierrors.ErrAbortRuntime.InternalError(err)
+	}
+	return fsm.Deployment.Extra.Rollout, nil
+}
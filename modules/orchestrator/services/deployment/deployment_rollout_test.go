@@ -0,0 +1,228 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/modules/orchestrator/dbclient"
+	"github.com/erda-project/erda/pkg/parser/diceyml"
+)
+
+const rolloutYml = `version: 2.0
+services:
+  web:
+    image: nginx
+    resources:
+      cpu: 0.1
+      mem: 128
+    deployments:
+      replicas: 4
+      strategy:
+        type: canary
+        steps:
+        - weight: 20
+          pause: 60
+        - weight: 50
+  admin:
+    image: nginx
+    resources:
+      cpu: 0.1
+      mem: 128
+    deployments:
+      replicas: 2
+      strategy:
+        type: blue-green
+  worker:
+    image: nginx
+    resources:
+      cpu: 0.1
+      mem: 128
+    deployments:
+      replicas: 1
+`
+
+func genRolloutSpec(t *testing.T) *diceyml.Object {
+	y, err := diceyml.New([]byte(rolloutYml), true)
+	assert.NoError(t, err)
+	return y.Obj()
+}
+
+func TestRolloutPlan(t *testing.T) {
+	obj := genRolloutSpec(t)
+	assert.Equal(t, map[string]string{"web": "canary", "admin": "blue-green"}, rolloutServices(obj))
+	assert.Equal(t, 2, rolloutSteps(obj))
+
+	pause, manual := rolloutPause(obj, 0)
+	assert.Equal(t, time.Minute, pause)
+	assert.True(t, manual)
+
+	pause, manual = rolloutPause(obj, 1)
+	assert.Equal(t, time.Duration(0), pause)
+	assert.True(t, manual)
+}
+
+func TestSetRolloutLabels(t *testing.T) {
+	obj := genRolloutSpec(t)
+	rollout := &apistructs.DeploymentRollout{Services: rolloutServices(obj), Steps: 2}
+
+	setRolloutLabels(obj, rollout, apistructs.RolloutPhaseProgressing)
+	assert.Equal(t, "20", obj.Services["web"].Labels[apistructs.LabelRolloutWeight])
+	assert.Equal(t, "blue-green", obj.Services["admin"].Labels[apistructs.LabelRolloutStrategy])
+	assert.Equal(t, "", obj.Services["worker"].Labels[apistructs.LabelRolloutStrategy])
+
+	rollout.Step = 5
+	setRolloutLabels(obj, rollout, apistructs.RolloutPhasePromote)
+	assert.Equal(t, "50", obj.Services["web"].Labels[apistructs.LabelRolloutWeight])
+	assert.Equal(t, apistructs.RolloutPhasePromote, obj.Services["admin"].Labels[apistructs.LabelRolloutPhase])
+}
+
+func patchServiceGroupStatus(status apistructs.StatusCode) {
+	var bdl *bundle.Bundle
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "InspectServiceGroupWithTimeout", func(*bundle.Bundle, string, string) (*apistructs.ServiceGroup, error) {
+		return &apistructs.ServiceGroup{StatusDesc: apistructs.StatusDesc{Status: status}}, nil
+	})
+}
+
+// patchUpdateDeploymentRollout keeps the deployment row in memory, the promote made by user can be simulated on it
+func patchUpdateDeploymentRollout(row *dbclient.Deployment) {
+	var db *dbclient.DBClient
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "UpdateDeploymentRollout", func(_ *dbclient.DBClient, _ uint64, fn func(*dbclient.Deployment) error) error {
+		deployment := *row
+		if deployment.Extra.Rollout != nil {
+			rollout := *deployment.Extra.Rollout
+			deployment.Extra.Rollout = &rollout
+		}
+		if err := fn(&deployment); err != nil {
+			return err
+		}
+		*row = deployment
+		return nil
+	})
+}
+
+func recordUpdateRollout() *[]string {
+	phases := &[]string{}
+	monkey.Patch((*DeployFSMContext).updateRollout, func(_ *DeployFSMContext, phase string) error {
+		*phases = append(*phases, phase)
+		return nil
+	})
+	return phases
+}
+
+func TestFSMContinuePhaseRollout(t *testing.T) {
+	f := genFakeFSM()
+	f.Spec = genRolloutSpec(t)
+	f.Deployment.Status = apistructs.DeploymentStatusDeploying
+	f.Deployment.Phase = apistructs.DeploymentPhaseRollout
+	startAt := time.Now().Add(-time.Minute)
+	f.Deployment.Extra.Rollout = &apistructs.DeploymentRollout{
+		Services:    rolloutServices(f.Spec),
+		Steps:       rolloutSteps(f.Spec),
+		StepStartAt: &startAt,
+	}
+
+	defer monkey.UnpatchAll()
+	c := recordUpdateDeployment()
+	_ = recordEvent()
+	_ = recordDLog()
+	patchServiceGroupStatus(apistructs.StatusReady)
+	row := *f.Deployment
+	patchUpdateDeploymentRollout(&row)
+	phases := recordUpdateRollout()
+
+	// step 1 is ready, and waits for manual promote
+	assert.NoError(t, f.continuePhaseRollout())
+	assert.NotNil(t, row.Extra.Rollout.StepReadyAt)
+	assert.True(t, row.Extra.Rollout.WaitPromote)
+	assert.NoError(t, f.continuePhaseRollout())
+	assert.Equal(t, 0, f.Deployment.Extra.Rollout.Step)
+
+	// the new version is promoted, blue-green switches back to the stable deployment before registering
+	f.Deployment.Extra.Rollout.Promoted = true
+	assert.NoError(t, f.continuePhaseRollout())
+	assert.Equal(t, []string{apistructs.RolloutPhaseComplete}, *phases)
+	assert.Equal(t, apistructs.DeploymentPhaseRegister, f.Deployment.Phase)
+	assert.Equal(t, 1, len(collectUpdateDeployment(c)))
+}
+
+func TestFSMSaveRolloutKeepsPromoteRequest(t *testing.T) {
+	f := genFakeFSM()
+	f.Spec = genRolloutSpec(t)
+	f.Deployment.Status = apistructs.DeploymentStatusDeploying
+	f.Deployment.Phase = apistructs.DeploymentPhaseRollout
+	startAt := time.Now().Add(-time.Minute)
+	f.Deployment.Extra.Rollout = &apistructs.DeploymentRollout{
+		Services:    rolloutServices(f.Spec),
+		Steps:       rolloutSteps(f.Spec),
+		StepStartAt: &startAt,
+	}
+
+	defer monkey.UnpatchAll()
+	_ = recordDLog()
+	patchServiceGroupStatus(apistructs.StatusReady)
+	row := *f.Deployment
+	patchUpdateDeploymentRollout(&row)
+	phases := recordUpdateRollout()
+
+	// user promotes after the deploy loop loaded the deployment, the step is ready at the same time
+	row.Extra.Rollout = &apistructs.DeploymentRollout{PromoteRequested: true}
+	assert.NoError(t, f.continuePhaseRollout())
+	assert.True(t, row.Extra.Rollout.PromoteRequested)
+	assert.NotNil(t, row.Extra.Rollout.StepReadyAt)
+
+	// the next loop loads the promote request and goes on the next step
+	f.Deployment.Extra.Rollout = row.Extra.Rollout
+	assert.NoError(t, f.continuePhaseRollout())
+	assert.Equal(t, []string{apistructs.RolloutPhaseProgressing}, *phases)
+	assert.Equal(t, 1, row.Extra.Rollout.Step)
+	assert.False(t, row.Extra.Rollout.PromoteRequested)
+}
+
+func TestFSMFailRolloutOnHealthTimeout(t *testing.T) {
+	f := genFakeFSM()
+	f.Spec = genRolloutSpec(t)
+	f.Deployment.Status = apistructs.DeploymentStatusDeploying
+	f.Deployment.Phase = apistructs.DeploymentPhaseRollout
+	startAt := time.Now().Add(-rolloutHealthTimeout - time.Minute)
+	f.Deployment.Extra.Rollout = &apistructs.DeploymentRollout{
+		Services:    rolloutServices(f.Spec),
+		Steps:       rolloutSteps(f.Spec),
+		StepStartAt: &startAt,
+	}
+
+	defer monkey.UnpatchAll()
+	patchUpdateDeploymentStatusToRuntimeAndOrder(f)
+	_ = recordUpdateDeployment()
+	_ = recordEvent()
+	_ = recordDLog()
+	patchServiceGroupStatus(apistructs.StatusUnHealthy)
+	var db *dbclient.DBClient
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "GetAliveProjectAddons", func(*dbclient.DBClient, string, string, string) (*[]dbclient.AddonInstanceRouting, error) {
+		return nil, errors.New("fake error")
+	})
+
+	assert.NoError(t, f.continuePhaseRollout())
+	assert.Equal(t, apistructs.DeploymentStatusFailed, f.Deployment.Status)
+	assert.True(t, f.needRollback)
+}
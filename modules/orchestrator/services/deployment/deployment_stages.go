@@ -79,6 +79,13 @@ func (d *Deployment) DeployStageServices(deploymentID uint64) (*apistructs.Deplo
 			if err != nil {
 				break
 			}
+			fallthrough
+		case apistructs.DeploymentPhaseRollout:
+			err = fsm.continuePhaseRollout()
+			if err != nil {
+				break
+			}
+			err = d.rollbackRuntime(fsm, fsm.Deployment.Operator)
 		}
 	default:
 		return nil, errors.Errorf("DeployStageServices: deployment status != DEPLOYING")
@@ -1565,6 +1565,7 @@ func fillRuntimeDataWithServiceGroup(data *apistructs.RuntimeInspectDTO, targetS
 	sg *apistructs.ServiceGroup, domainMap map[string][]string, status string) {
 	statusServiceMap := map[string]string{}
 	replicaMap := map[string]int{}
+	autoscaledMap := map[string]apistructs.RuntimeServiceDeploymentsDTO{}
 	resourceMap := map[string]apistructs.RuntimeServiceResourceDTO{}
 	statusMap := map[string]map[string]string{}
 	if sg != nil {
@@ -1581,6 +1582,12 @@ func fillRuntimeDataWithServiceGroup(data *apistructs.RuntimeInspectDTO, targetS
 		for _, v := range sg.Services {
 			statusServiceMap[v.Name] = string(v.StatusDesc.Status)
 			replicaMap[v.Name] = v.Scale
+			if v.Autoscaling != nil {
+				autoscaledMap[v.Name] = apistructs.RuntimeServiceDeploymentsDTO{
+					CurrentReplicas: v.CurrentReplicas,
+					DesiredReplicas: v.DesiredReplicas,
+				}
+			}
 			resourceMap[v.Name] = apistructs.RuntimeServiceResourceDTO{
 				CPU:  v.Resources.Cpu,
 				Mem:  int(v.Resources.Mem),
@@ -1621,6 +1628,14 @@ func fillRuntimeDataWithServiceGroup(data *apistructs.RuntimeInspectDTO, targetS
 		if sgReplicas, ok := replicaMap[k]; ok {
 			runtimeInspectService.Deployments.Replicas = sgReplicas
 		}
+		if autoscaled, ok := autoscaledMap[k]; ok {
+			runtimeInspectService.Deployments.CurrentReplicas = autoscaled.CurrentReplicas
+			runtimeInspectService.Deployments.DesiredReplicas = autoscaled.DesiredReplicas
+			if autoscaled.CurrentReplicas > 0 {
+				// the running replicas are decided by autoscaler
+				runtimeInspectService.Deployments.Replicas = autoscaled.CurrentReplicas
+			}
+		}
 		if sgResources, ok := resourceMap[k]; ok {
 			runtimeInspectService.Resources = sgResources
 		}
@@ -83,6 +83,31 @@ func TestFillRuntimeDataWithServiceGroup(t *testing.T) {
 	assert.Equal(t, 2, data.Services["fake-service"].Deployments.Replicas)
 }
 
+func TestFillRuntimeDataWithAutoscaledServiceGroup(t *testing.T) {
+	data := apistructs.RuntimeInspectDTO{Services: map[string]*apistructs.RuntimeInspectServiceDTO{}}
+	targetService := diceyml.Services{"web": &diceyml.Service{}}
+	sg := apistructs.ServiceGroup{
+		Dice: apistructs.Dice{
+			Services: []apistructs.Service{{
+				Name:            "web",
+				Scale:           2,
+				Resources:       apistructs.Resources{Cpu: 0.5, Mem: 512},
+				Autoscaling:     &diceyml.Autoscaling{MinReplicas: 2, MaxReplicas: 10, CPU: 70},
+				CurrentReplicas: 4,
+				DesiredReplicas: 6,
+				StatusDesc:      apistructs.StatusDesc{Status: apistructs.StatusHealthy},
+			}},
+		},
+		StatusDesc: apistructs.StatusDesc{Status: apistructs.StatusHealthy},
+	}
+
+	fillRuntimeDataWithServiceGroup(&data, targetService, &sg, map[string][]string{}, "OK")
+	assert.Equal(t, 4, data.Services["web"].Deployments.Replicas)
+	assert.Equal(t, 4, data.Services["web"].Deployments.CurrentReplicas)
+	assert.Equal(t, 6, data.Services["web"].Deployments.DesiredReplicas)
+	assert.Equal(t, 2.0, data.Resources.CPU)
+}
+
 func TestGetRollbackConfig(t *testing.T) {
 	var bdl *bundle.Bundle
 	monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "GetAllProjects",
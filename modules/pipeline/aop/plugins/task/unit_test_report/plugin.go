@@ -22,6 +22,13 @@ import (
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/pipeline/aop"
 	"github.com/erda-project/erda/modules/pipeline/aop/aoptypes"
+	"github.com/erda-project/erda/pkg/qaparser"
+	_ "github.com/erda-project/erda/pkg/qaparser/ctrf"
+	_ "github.com/erda-project/erda/pkg/qaparser/gotestjson"
+	_ "github.com/erda-project/erda/pkg/qaparser/junitxml"
+	_ "github.com/erda-project/erda/pkg/qaparser/surefilexml"
+	_ "github.com/erda-project/erda/pkg/qaparser/testngxml"
+	"github.com/erda-project/erda/pkg/qaparser/types"
 )
 
 const taskType = "unit-test"
@@ -46,6 +53,8 @@ func (p *provider) Handle(ctx *aoptypes.TuneContext) error {
 	}
 
 	var meta = map[string]interface{}{}
+	// action 也可以直接上报原始的测试报告, 由 parserType 对应的 parser 解析
+	var parserType, report string
 	for _, v := range metadata {
 		var err error
 		switch v.Name {
@@ -61,12 +70,29 @@ func (p *provider) Handle(ctx *aoptypes.TuneContext) error {
 			var suites []apistructs.TestSuite
 			err = json.Unmarshal([]byte(v.Value), &suites)
 			meta["suites"] = suites
+		case "parserType":
+			parserType = v.Value
+		case "report":
+			report = v.Value
 		}
 		if err != nil {
 			return fmt.Errorf("unmarshal unit-test report error: %v", err)
 		}
 	}
 
+	if _, ok := meta["suites"]; !ok && report != "" {
+		suites, totals, err := parseReport(types.TestParserType(parserType), []byte(report))
+		if err != nil {
+			return fmt.Errorf("parse unit-test report error: %v", err)
+		}
+		meta["suites"] = suites
+		meta["totals"] = totals
+		if results, ok := meta["results"].(apistructs.TestResults); ok && results.ParserType == "" {
+			results.ParserType = parserType
+			meta["results"] = results
+		}
+	}
+
 	meta["taskId"] = ctx.SDK.Task.ID
 
 	_, err := ctx.SDK.Report.Create(apistructs.PipelineReportCreateRequest{
@@ -81,6 +107,22 @@ func (p *provider) Handle(ctx *aoptypes.TuneContext) error {
 	return nil
 }
 
+// parseReport parses the raw test report and aggregates the totals of all suites
+func parseReport(parserType types.TestParserType, report []byte) ([]apistructs.TestSuite, apistructs.TestTotals, error) {
+	totals := qaparser.Totals{TestTotals: &apistructs.TestTotals{}}
+	totals.SetStatuses(qaparser.NewStatuses(0, 0, 0, 0))
+	parsed, err := qaparser.GetManager().Ingest(parserType, report)
+	if err != nil {
+		return nil, *totals.TestTotals, err
+	}
+	suites := make([]apistructs.TestSuite, 0, len(parsed))
+	for _, suite := range parsed {
+		suites = append(suites, *suite)
+		totals.Add(suite.Totals)
+	}
+	return suites, *totals.TestTotals, nil
+}
+
 func (p *provider) Init(ctx servicehub.Context) error {
 	err := aop.RegisterTunePoint(p)
 	if err != nil {
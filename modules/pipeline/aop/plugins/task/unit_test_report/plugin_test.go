@@ -0,0 +1,45 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unit_test_report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/qaparser/types"
+)
+
+func TestParseReport(t *testing.T) {
+	report := `{"Action":"pass","Package":"a","Test":"TestA","Elapsed":0.5}
+{"Action":"fail","Package":"a","Test":"TestB","Elapsed":1}
+{"Action":"fail","Package":"a","Elapsed":1.5}
+{"Action":"skip","Package":"b","Test":"TestC"}
+{"Action":"pass","Package":"b","Elapsed":0}
+`
+	suites, totals, err := parseReport(types.GoTest, []byte(report))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(suites))
+	assert.Equal(t, 3, totals.Tests)
+	assert.Equal(t, 1500*time.Millisecond, totals.Duration)
+	assert.Equal(t, 1, totals.Statuses[apistructs.TestStatusPassed])
+	assert.Equal(t, 1, totals.Statuses[apistructs.TestStatusFailed])
+	assert.Equal(t, 1, totals.Statuses[apistructs.TestStatusSkipped])
+
+	_, _, err = parseReport("UNKNOWN", []byte(report))
+	assert.Error(t, err)
+}
@@ -46,6 +46,15 @@ type Conf struct {
 	BuildCacheCleanJobCron string        `env:"BUILD_CACHE_CLEAN_JOB_CRON" default:"0 0 0 * * ?"`
 	BuildCacheExpireIn     time.Duration `env:"BUILD_CACHE_EXPIRE_IN" default:"168h"`
 
+	// action remote cache, 未配置 endpoint 时不启用远程缓存
+	ActionCacheStorageEndpoint  string `env:"ACTION_CACHE_STORAGE_ENDPOINT"`
+	ActionCacheStorageAccessKey string `env:"ACTION_CACHE_STORAGE_ACCESS_KEY"`
+	ActionCacheStorageSecretKey string `env:"ACTION_CACHE_STORAGE_SECRET_KEY"`
+	ActionCacheStorageBucket    string `env:"ACTION_CACHE_STORAGE_BUCKET" default:"erda-pipeline"`
+	ActionCacheStoragePrefix    string `env:"ACTION_CACHE_STORAGE_PREFIX" default:"action-caches"`
+	// ActionCacheProjectQuota 每个项目的远程缓存配额, 单位 Byte, 小于等于 0 表示不限制
+	ActionCacheProjectQuota int64 `env:"ACTION_CACHE_PROJECT_QUOTA" default:"10737418240"`
+
 	// bundle
 	GittarAddr         string `env:"GITTAR_ADDR" required:"false"`
 	OpenAPIAddr        string `env:"OPENAPI_ADDR" required:"false"`
@@ -202,6 +211,41 @@ func BuildCacheExpireIn() time.Duration {
 	return cfg.BuildCacheExpireIn
 }
 
+// ActionCacheStorageEndpoint 返回 action 远程缓存对象存储 oss/minio 地址.
+func ActionCacheStorageEndpoint() string {
+	return cfg.ActionCacheStorageEndpoint
+}
+
+// ActionCacheStorageAccessKey 返回 action 远程缓存对象存储 access key.
+func ActionCacheStorageAccessKey() string {
+	return cfg.ActionCacheStorageAccessKey
+}
+
+// ActionCacheStorageSecretKey 返回 action 远程缓存对象存储 secret key.
+func ActionCacheStorageSecretKey() string {
+	return cfg.ActionCacheStorageSecretKey
+}
+
+// ActionCacheStorageBucket 返回 action 远程缓存对象存储 bucket.
+func ActionCacheStorageBucket() string {
+	return cfg.ActionCacheStorageBucket
+}
+
+// ActionCacheStoragePrefix 返回 action 远程缓存在 bucket 中的路径前缀.
+func ActionCacheStoragePrefix() string {
+	return cfg.ActionCacheStoragePrefix
+}
+
+// ActionCacheProjectQuota 返回每个项目的远程缓存配额, 超出后按 LRU 淘汰.
+func ActionCacheProjectQuota() int64 {
+	return cfg.ActionCacheProjectQuota
+}
+
+// ActionRemoteCacheEnabled 返回是否启用了 action 远程缓存.
+func ActionRemoteCacheEnabled() bool {
+	return cfg.ActionCacheStorageEndpoint != ""
+}
+
 // GittarAddr 返回 gittar 的集群内部地址.
 func GittarAddr() string {
 	return cfg.GittarAddr
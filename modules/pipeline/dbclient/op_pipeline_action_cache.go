@@ -0,0 +1,101 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbclient
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/modules/pipeline/spec"
+)
+
+func (client *Client) GetActionCache(projectID uint64, keyHash string, ops ...SessionOption) (cache spec.PipelineActionCache, exist bool, err error) {
+	session := client.NewSession(ops...)
+	defer session.Close()
+
+	exist, err = session.Where("project_id = ? AND key_hash = ?", projectID, keyHash).Get(&cache)
+	if err != nil {
+		return spec.PipelineActionCache{}, false, errors.Wrapf(err, "failed to get action cache, projectID [%d], keyHash [%s]", projectID, keyHash)
+	}
+	return cache, exist, nil
+}
+
+// GetLatestActionCacheByKeyPrefix 返回 key 以 prefix 开头的最新创建的缓存
+func (client *Client) GetLatestActionCacheByKeyPrefix(projectID uint64, prefix string, ops ...SessionOption) (cache spec.PipelineActionCache, exist bool, err error) {
+	session := client.NewSession(ops...)
+	defer session.Close()
+
+	exist, err = session.Where("project_id = ?", projectID).
+		And("cache_key LIKE ?", escapeLike(prefix)+"%").
+		Desc("created_at", "id").
+		Get(&cache)
+	if err != nil {
+		return spec.PipelineActionCache{}, false, errors.Wrapf(err, "failed to get action cache by key prefix, projectID [%d], prefix [%s]", projectID, prefix)
+	}
+	return cache, exist, nil
+}
+
+func (client *Client) CreateActionCache(cache *spec.PipelineActionCache, ops ...SessionOption) error {
+	session := client.NewSession(ops...)
+	defer session.Close()
+
+	_, err := session.InsertOne(cache)
+	return errors.Wrapf(err, "failed to create action cache, projectID [%d], key [%s]", cache.ProjectID, cache.CacheKey)
+}
+
+func (client *Client) UpdateActionCache(cache *spec.PipelineActionCache, ops ...SessionOption) error {
+	session := client.NewSession(ops...)
+	defer session.Close()
+
+	_, err := session.ID(cache.ID).Cols("size", "last_used_at").Update(cache)
+	return errors.Wrapf(err, "failed to update action cache, id [%d]", cache.ID)
+}
+
+func (client *Client) UpdateActionCacheLastUsedAt(id uint64, lastUsedAt time.Time, ops ...SessionOption) error {
+	session := client.NewSession(ops...)
+	defer session.Close()
+
+	_, err := session.ID(id).Cols("last_used_at").Update(&spec.PipelineActionCache{LastUsedAt: lastUsedAt})
+	return errors.Wrapf(err, "failed to update action cache last used time, id [%d]", id)
+}
+
+// ListActionCachesByProjectID 按最近使用时间升序返回项目下的所有缓存, 最久未使用的在前
+func (client *Client) ListActionCachesByProjectID(projectID uint64, ops ...SessionOption) ([]spec.PipelineActionCache, error) {
+	session := client.NewSession(ops...)
+	defer session.Close()
+
+	var caches []spec.PipelineActionCache
+	if err := session.Where("project_id = ?", projectID).Asc("last_used_at", "id").Find(&caches); err != nil {
+		return nil, errors.Wrapf(err, "failed to list action caches, projectID [%d]", projectID)
+	}
+	return caches, nil
+}
+
+func (client *Client) DeleteActionCache(id uint64, ops ...SessionOption) error {
+	session := client.NewSession(ops...)
+	defer session.Close()
+
+	_, err := session.ID(id).Delete(&spec.PipelineActionCache{})
+	return errors.Wrapf(err, "failed to delete action cache, id [%d]", id)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike 转义 LIKE 语句中的通配符
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
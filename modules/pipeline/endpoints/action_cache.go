@@ -0,0 +1,127 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/services/apierrors"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// 以下接口只有 action-agent 会使用 token 方式调用，校验已由 openapi checkToken 完成
+
+func (e *Endpoints) queryActionCache(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+
+	p, task, err := e.getActionCacheTask(apierrors.ErrQueryActionCache, vars)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	var req apistructs.PipelineActionCacheQueryRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrQueryActionCache.InvalidParameter(err).ToResp(), nil
+	}
+
+	result, err := e.actionCacheSvc.Query(p, task, req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(result)
+}
+
+func (e *Endpoints) downloadActionCache(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	p, _, err := e.getActionCacheTask(apierrors.ErrDownloadActionCache, vars)
+	if err != nil {
+		return errorresp.ErrWrite(err, w)
+	}
+	var req apistructs.PipelineActionCacheDownloadRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrDownloadActionCache.InvalidParameter(err).Write(w)
+	}
+
+	rc, size, err := e.actionCacheSvc.Download(p, req)
+	if err != nil {
+		return errorresp.ErrWrite(err, w)
+	}
+	defer rc.Close()
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	if _, err := io.Copy(w, rc); err != nil {
+		logrus.Errorf("failed to download action cache, pipelineID: %d, key: %s, err: %v", p.ID, req.Key, err)
+		return err
+	}
+	return nil
+}
+
+func (e *Endpoints) uploadActionCache(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+
+	p, task, err := e.getActionCacheTask(apierrors.ErrUploadActionCache, vars)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	var req apistructs.PipelineActionCacheUploadRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrUploadActionCache.InvalidParameter(err).ToResp(), nil
+	}
+
+	result, err := e.actionCacheSvc.Upload(p, task, req, r.Body)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(result)
+}
+
+// getActionCacheTask 校验远程缓存已启用，并返回路径参数对应的 pipeline 和 task
+func (e *Endpoints) getActionCacheTask(apiErr *errorresp.APIError, vars map[string]string) (*spec.Pipeline, *spec.PipelineTask, error) {
+	if e.actionCacheSvc == nil {
+		return nil, nil, apiErr.InvalidState("action remote cache is not enabled")
+	}
+
+	pipelineIDStr := vars[pathPipelineID]
+	pipelineID, err := strconv.ParseUint(pipelineIDStr, 10, 64)
+	if err != nil {
+		return nil, nil, apiErr.InvalidParameter(strutil.Concat(pathPipelineID, ": ", pipelineIDStr))
+	}
+	taskIDStr := vars[pathTaskID]
+	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	if err != nil {
+		return nil, nil, apiErr.InvalidParameter(strutil.Concat(pathTaskID, ": ", taskIDStr))
+	}
+
+	p, err := e.dbClient.GetPipeline(pipelineID)
+	if err != nil {
+		return nil, nil, apiErr.InternalError(err)
+	}
+	task, err := e.dbClient.GetPipelineTask(taskID)
+	if err != nil {
+		return nil, nil, apiErr.InternalError(err)
+	}
+	if task.PipelineID != p.ID {
+		return nil, nil, apiErr.InvalidParameter("task not belong to pipeline")
+	}
+	return &p, &task, nil
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/erda-project/erda/modules/pipeline/pipengine/reconciler"
 	"github.com/erda-project/erda/modules/pipeline/pkg/clusterinfo"
 	"github.com/erda-project/erda/modules/pipeline/services/actionagentsvc"
+	"github.com/erda-project/erda/modules/pipeline/services/actioncachesvc"
 	"github.com/erda-project/erda/modules/pipeline/services/appsvc"
 	"github.com/erda-project/erda/modules/pipeline/services/buildartifactsvc"
 	"github.com/erda-project/erda/modules/pipeline/services/buildcachesvc"
@@ -47,6 +48,7 @@ type Endpoints struct {
 	buildArtifactSvc *buildartifactsvc.BuildArtifactSvc
 	buildCacheSvc    *buildcachesvc.BuildCacheSvc
 	actionAgentSvc   *actionagentsvc.ActionAgentSvc
+	actionCacheSvc   *actioncachesvc.ActionCacheSvc
 	extMarketSvc     *extmarketsvc.ExtMarketSvc
 	reportSvc        *reportsvc.ReportSvc
 	queueManage      *queuemanage.QueueManage
@@ -112,6 +114,13 @@ func WithActionAgentSvc(svc *actionagentsvc.ActionAgentSvc) Option {
 	}
 }
 
+// WithActionCacheSvc 未启用远程缓存时 svc 为 nil
+func WithActionCacheSvc(svc *actioncachesvc.ActionCacheSvc) Option {
+	return func(e *Endpoints) {
+		e.actionCacheSvc = svc
+	}
+}
+
 func WithExtMarketSvc(svc *extmarketsvc.ExtMarketSvc) Option {
 	return func(e *Endpoints) {
 		e.extMarketSvc = svc
@@ -181,10 +190,14 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		// tasks
 		{Path: "/api/pipelines/{pipelineID}/tasks/{taskID}", Method: http.MethodGet, Handler: e.pipelineTaskDetail},
 		{Path: "/api/pipelines/{pipelineID}/tasks/{taskID}/actions/get-bootstrap-info", Method: http.MethodGet, Handler: e.taskBootstrapInfo},
+		{Path: "/api/pipelines/{pipelineID}/tasks/{taskID}/actions/query-cache", Method: http.MethodGet, Handler: e.queryActionCache},
+		{Path: "/api/pipelines/{pipelineID}/tasks/{taskID}/actions/download-cache", Method: http.MethodGet, WriterHandler: e.downloadActionCache},
+		{Path: "/api/pipelines/{pipelineID}/tasks/{taskID}/actions/upload-cache", Method: http.MethodPut, Handler: e.uploadActionCache},
 
 		// pipeline related actions
 		{Path: "/api/pipelines/actions/batch-create", Method: http.MethodPost, Handler: e.pipelineBatchCreate},
 		{Path: "/api/pipelines/actions/pipeline-yml-graph", Method: http.MethodPost, Handler: e.pipelineYmlGraph},
+		{Path: "/api/pipelines/actions/dry-run", Method: http.MethodPost, Handler: e.pipelineDryRun},
 		{Path: "/api/pipelines/actions/statistics", Method: http.MethodGet, Handler: e.pipelineStatistic},
 		{Path: "/api/pipelines/actions/task-view", Method: http.MethodGet, Handler: e.pipelineTaskView},
 
@@ -367,6 +367,30 @@ func (e *Endpoints) pipelineYmlGraph(ctx context.Context, r *http.Request, vars
 	return httpserver.OkResp(graph)
 }
 
+// pipelineDryRun return the plan of pipeline, nothing is created or run
+func (e *Endpoints) pipelineDryRun(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+
+	var req apistructs.PipelineDryRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logrus.Errorf("[alert] failed to decode request body: %v", err)
+		return apierrors.ErrDryRunPipeline.InvalidParameter("request body").ToResp(), nil
+	}
+
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	req.IdentityInfo = identityInfo
+
+	plan, err := e.pipelineSvc.DryRun(&req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(plan)
+}
+
 // pipelineStatistic pipeline 状态分类统计
 func (e *Endpoints) pipelineStatistic(ctx context.Context, r *http.Request, vars map[string]string) (
 	httpserver.Responser, error) {
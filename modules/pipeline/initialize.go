@@ -40,6 +40,7 @@ import (
 	"github.com/erda-project/erda/modules/pipeline/pkg/clusterinfo"
 	"github.com/erda-project/erda/modules/pipeline/pkg/pipelinefunc"
 	"github.com/erda-project/erda/modules/pipeline/services/actionagentsvc"
+	"github.com/erda-project/erda/modules/pipeline/services/actioncachesvc"
 	"github.com/erda-project/erda/modules/pipeline/services/appsvc"
 	"github.com/erda-project/erda/modules/pipeline/services/buildartifactsvc"
 	"github.com/erda-project/erda/modules/pipeline/services/buildcachesvc"
@@ -51,6 +52,7 @@ import (
 	"github.com/erda-project/erda/modules/pipeline/services/queuemanage"
 	"github.com/erda-project/erda/modules/pipeline/services/reportsvc"
 	"github.com/erda-project/erda/modules/pkg/websocket"
+	"github.com/erda-project/erda/pkg/cloudstorage"
 	"github.com/erda-project/erda/pkg/dumpstack"
 	"github.com/erda-project/erda/pkg/http/httpserver"
 	"github.com/erda-project/erda/pkg/jsonstore"
@@ -127,6 +129,10 @@ func (p *provider) do() error {
 	appSvc := appsvc.New(bdl)
 	buildArtifactSvc := buildartifactsvc.New(dbClient)
 	buildCacheSvc := buildcachesvc.New(dbClient)
+	actionCacheSvc, err := newActionCacheSvc(dbClient)
+	if err != nil {
+		return err
+	}
 	permissionSvc := permissionsvc.New(bdl)
 	crondSvc := crondsvc.New(dbClient, bdl, js)
 	actionAgentSvc := actionagentsvc.New(dbClient, bdl, js, etcdctl)
@@ -188,6 +194,7 @@ func (p *provider) do() error {
 		endpoints.WithPermissionSvc(permissionSvc),
 		endpoints.WithCrondSvc(crondSvc),
 		endpoints.WithActionAgentSvc(actionAgentSvc),
+		endpoints.WithActionCacheSvc(actionCacheSvc),
 		endpoints.WithExtMarketSvc(extMarketSvc),
 		endpoints.WithPipelineCronSvc(pipelineCronSvc),
 		endpoints.WithPipelineSvc(pipelineSvc),
@@ -245,3 +252,16 @@ func registerSnippetClient(dbclient *dbclient.Client) error {
 	pipeline_snippet_client.SetSnippetClientMap(clientMap)
 	return nil
 }
+
+// newActionCacheSvc 未配置对象存储时不启用远程缓存, 返回 nil
+func newActionCacheSvc(dbClient *dbclient.Client) (*actioncachesvc.ActionCacheSvc, error) {
+	if !conf.ActionRemoteCacheEnabled() {
+		return nil, nil
+	}
+	client, err := cloudstorage.New(conf.ActionCacheStorageEndpoint(), conf.ActionCacheStorageAccessKey(), conf.ActionCacheStorageSecretKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to init action cache storage, err: %v", err)
+	}
+	return actioncachesvc.New(dbClient, client, conf.ActionCacheStorageBucket(), conf.ActionCacheStoragePrefix(),
+		conf.ActionCacheProjectQuota()), nil
+}
@@ -60,7 +60,7 @@ func parseConfFromTask(task *spec.PipelineTask) (EnvConfig, error) {
 //   save metadata
 func Do(ctx context.Context, task *spec.PipelineTask) {
 	logger := newLogger().
-		WithContext(context.WithValue(context.Background(), CtxKeyCollectorLogID, task.Extra.UUID)).
+		WithContext(context.WithValue(context.Background(), CtxKeyCollectorLogID, task.GetLogID())).
 		WithField(FieldOrgName, task.Extra.Labels[apistructs.EnvDiceOrgName]).
 		WithField(FieldOrgID, task.Extra.Labels[apistructs.EnvDiceOrgID])
 	ctx = context.WithValue(ctx, CtxKeyLogger, logger)
@@ -227,10 +227,20 @@ func (k *K8sJob) Create(ctx context.Context, action *spec.PipelineTask) (data in
 		return nil, errors.Wrapf(err, "failed to create k8s job")
 	}
 
+	// services, the task can access services by hostname after all services are healthy
+	hostAliases, err := k.createServices(ctx, action, job)
+	if err != nil {
+		return nil, err
+	}
+	kubeJob.Spec.Template.Spec.HostAliases = hostAliases
+
 	_, err = k.client.ClientSet.BatchV1().Jobs(job.Namespace).Create(ctx, kubeJob, metav1.CreateOptions{})
 	if err != nil {
 		errMsg := fmt.Sprintf("failed to create k8s job, name: %s, err: %v", kubeJob.Name, err)
 		logrus.Errorf(errMsg)
+		if len(action.Extra.Action.Services) > 0 {
+			k.cleanServicesOnError(action.ID, job)
+		}
 		return nil, errors.Errorf(errMsg)
 	}
 
@@ -285,6 +295,12 @@ func (k *K8sJob) Remove(ctx context.Context, task *spec.PipelineTask) (data inte
 		}
 	}
 
+	if len(task.Extra.Action.Services) > 0 {
+		if err := k.RemoveServices(ctx, task); err != nil {
+			return nil, err
+		}
+	}
+
 	// if user customize namespace, shouldn't delete namespace
 	if os.Getenv(ENABLE_SPECIFIED_K8S_NAMESPACE) == "" && !job.NotPipelineControlledNs {
 		jobs, err := k.client.ClientSet.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
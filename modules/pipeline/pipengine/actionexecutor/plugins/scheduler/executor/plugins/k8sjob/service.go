@@ -0,0 +1,294 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sjob
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/actionexecutor/plugins/scheduler/logic"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+	"github.com/erda-project/erda/pkg/schedule/schedulepolicy/constraintbuilders"
+	"github.com/erda-project/erda/pkg/schedule/schedulepolicy/labelconfig"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+const (
+	// service pods of task are selected by task id, so services of previous attempts can be removed together
+	serviceLabelTaskID = labelconfig.K8SLabelPrefix + "pipeline-task-id"
+	serviceLabelName   = labelconfig.K8SLabelPrefix + "pipeline-task-service"
+
+	serviceCheckInterval = 2 * time.Second
+)
+
+// waiting reasons which means the service can never be healthy
+var serviceFatalWaitingReasons = map[string]string{
+	"ErrImagePull":      errPullImage,
+	"ImagePullBackOff":  errPullImage,
+	"InvalidImageName":  errInvalidImageName,
+	"ErrImageNeverPull": errImageNeverPull,
+}
+
+// createServices create service pods declared in task and wait until all services are healthy.
+// Return host aliases, so the task can access services by service name.
+func (k *K8sJob) createServices(ctx context.Context, task *spec.PipelineTask, job apistructs.JobFromUser) (hostAliases []corev1.HostAlias, err error) {
+	services := task.Extra.Action.Services
+	if len(services) == 0 {
+		return nil, nil
+	}
+
+	// services of previous attempts (loop or retry) are useless
+	if err := k.removeServicePods(ctx, job.Namespace, task.ID, job.Name); err != nil {
+		return nil, err
+	}
+
+	scheduleInfo2, _, err := logic.GetScheduleInfo(k.cluster, string(k.Name()), string(Kind), job)
+	if err != nil {
+		return nil, err
+	}
+
+	// services already created are useless if any service failed to create or become healthy
+	defer func() {
+		if err != nil {
+			k.cleanServicesOnError(task.ID, job)
+		}
+	}()
+
+	names := apistructs.GetPipelineTaskServiceNames(services)
+	for _, name := range names {
+		pod := generateServicePod(task, job, name, services[name], &scheduleInfo2)
+		if _, err := k.client.ClientSet.CoreV1().Pods(job.Namespace).Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+			if !k8serrors.IsAlreadyExists(err) {
+				return nil, errors.Errorf("failed to create service %s, pod: %s, err: %v", name, pod.Name, err)
+			}
+			logrus.Warnf("service %s of task %s already exists, pod: %s", name, job.Name, pod.Name)
+		}
+	}
+
+	begin := time.Now()
+	for _, name := range names {
+		deadline := begin.Add(time.Duration(services[name].GetWaitTimeoutSec()) * time.Second)
+		podIP, err := k.waitServiceHealthy(ctx, job.Namespace, makeServicePodName(job.Name, name), deadline)
+		if err != nil {
+			return nil, errors.Errorf("service %s is not healthy, err: %v", name, err)
+		}
+		hostAliases = append(hostAliases, corev1.HostAlias{IP: podIP, Hostnames: []string{name}})
+	}
+	logrus.Infof("services of task %s are healthy, cost: %s", job.Name, time.Since(begin).String())
+	return hostAliases, nil
+}
+
+// cleanServicesOnError remove service pods of the job when the job failed to create,
+// use a new context because ctx may be already canceled.
+func (k *K8sJob) cleanServicesOnError(taskID uint64, job apistructs.JobFromUser) {
+	if err := k.removeServicePods(context.Background(), job.Namespace, taskID, ""); err != nil {
+		logrus.Errorf("failed to clean services of job %s, err: %v", job.Name, err)
+	}
+}
+
+// RemoveServices remove all service pods of task.
+func (k *K8sJob) RemoveServices(ctx context.Context, task *spec.PipelineTask) error {
+	return k.removeServicePods(ctx, task.Extra.Namespace, task.ID, "")
+}
+
+// removeServicePods remove service pods of task, pods belong to excludeJobName are retained.
+func (k *K8sJob) removeServicePods(ctx context.Context, namespace string, taskID uint64, excludeJobName string) error {
+	pods, err := k.client.ClientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%d", serviceLabelTaskID, taskID),
+	})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Errorf("failed to list service pods, taskID: %d, err: %v", taskID, err)
+	}
+	for _, pod := range pods.Items {
+		if excludeJobName != "" && pod.Name == makeServicePodName(excludeJobName, pod.Labels[serviceLabelName]) {
+			continue
+		}
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		logrus.Debugf("start to delete service pod %s", pod.Name)
+		if err := k.client.ClientSet.CoreV1().Pods(namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return errors.Errorf("failed to remove service pod, name: %s, err: %v", pod.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// waitServiceHealthy wait until the pod is ready, return pod ip.
+func (k *K8sJob) waitServiceHealthy(ctx context.Context, namespace, podName string, deadline time.Time) (string, error) {
+	ticker := time.NewTicker(serviceCheckInterval)
+	defer ticker.Stop()
+	var lastMsg string
+	for {
+		pod, err := k.client.ClientSet.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			lastMsg = err.Error()
+		} else {
+			ready, msg, fatal := analyzeServicePod(pod)
+			if ready {
+				return pod.Status.PodIP, nil
+			}
+			if fatal {
+				return "", errors.New(msg)
+			}
+			lastMsg = msg
+		}
+		if time.Now().After(deadline) {
+			return "", errors.Errorf("wait timeout, last message: %s", lastMsg)
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// analyzeServicePod return whether the pod is ready, the latest message, and whether the pod can never be ready.
+func analyzeServicePod(pod *corev1.Pod) (ready bool, msg string, fatal bool) {
+	if pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded {
+		return false, fmt.Sprintf("service exited, phase: %s, reason: %s", pod.Status.Phase, pod.Status.Reason), true
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting == nil {
+			continue
+		}
+		if cmt, ok := serviceFatalWaitingReasons[cs.State.Waiting.Reason]; ok {
+			return false, fmt.Sprintf("%s: %s", cmt, cs.State.Waiting.Message), true
+		}
+		msg = fmt.Sprintf("%s: %s", cs.State.Waiting.Reason, cs.State.Waiting.Message)
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue && pod.Status.PodIP != "" {
+			return true, "", false
+		}
+		if cond.Status != corev1.ConditionTrue && cond.Message != "" && msg == "" {
+			msg = cond.Message
+		}
+	}
+	if msg == "" {
+		msg = fmt.Sprintf("phase: %s", pod.Status.Phase)
+	}
+	return false, msg, false
+}
+
+func generateServicePod(task *spec.PipelineTask, job apistructs.JobFromUser, name string, service *apistructs.PipelineTaskService,
+	scheduleInfo2 *apistructs.ScheduleInfo2) *corev1.Pod {
+	resources := service.GetResources()
+	cpu := resource.MustParse(strutil.Concat(strconv.Itoa(int(resources.CPU*1000)), "m"))
+	memory := resource.MustParse(strutil.Concat(strconv.Itoa(resources.Mem), "Mi"))
+
+	container := corev1.Container{
+		Name:  name,
+		Image: service.Image,
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    cpu,
+				corev1.ResourceMemory: memory,
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    cpu,
+				corev1.ResourceMemory: memory,
+			},
+		},
+		ImagePullPolicy: logic.GetPullImagePolicy(),
+		ReadinessProbe:  generateServiceProbe(service),
+	}
+	if service.Cmd != "" {
+		container.Command = []string{"sh", "-c", service.Cmd}
+	}
+	var envKeys []string
+	for key := range service.Envs {
+		envKeys = append(envKeys, key)
+	}
+	sort.Strings(envKeys)
+	for _, key := range envKeys {
+		container.Env = append(container.Env, corev1.EnvVar{Name: key, Value: service.Envs[key]})
+	}
+	for _, port := range service.Ports {
+		container.Ports = append(container.Ports, corev1.ContainerPort{ContainerPort: int32(port)})
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      makeServicePodName(job.Name, name),
+			Namespace: job.Namespace,
+			Labels: map[string]string{
+				serviceLabelTaskID: strconv.FormatUint(task.ID, 10),
+				serviceLabelName:   name,
+			},
+		},
+		Spec: corev1.PodSpec{
+			Tolerations:        logic.GenTolerations(),
+			ImagePullSecrets:   []corev1.LocalObjectReference{{Name: apistructs.AliyunRegistry}},
+			Affinity:           &constraintbuilders.K8S(scheduleInfo2, nil, nil, nil).Affinity,
+			Containers:         []corev1.Container{container},
+			RestartPolicy:      corev1.RestartPolicyAlways,
+			EnableServiceLinks: func(enable bool) *bool { return &enable }(false),
+		},
+	}
+}
+
+// generateServiceProbe check the first port by tcp if health check not declared
+func generateServiceProbe(service *apistructs.PipelineTaskService) *corev1.Probe {
+	hc := service.HealthCheck
+	if hc == nil {
+		hc = &apistructs.PipelineTaskServiceHealthCheck{}
+	}
+	probe := &corev1.Probe{
+		InitialDelaySeconds: hc.InitialDelaySec,
+		PeriodSeconds:       hc.PeriodSec,
+		TimeoutSeconds:      apistructs.PipelineTaskServiceDefaultPeriodSec,
+		FailureThreshold:    1,
+	}
+	if probe.PeriodSeconds <= 0 {
+		probe.PeriodSeconds = apistructs.PipelineTaskServiceDefaultPeriodSec
+	}
+	switch {
+	case hc.Exec != "":
+		probe.Exec = &corev1.ExecAction{Command: []string{"sh", "-c", hc.Exec}}
+	case hc.HTTPPort > 0:
+		probe.HTTPGet = &corev1.HTTPGetAction{Path: hc.HTTPPath, Port: intstr.FromInt(hc.HTTPPort), Scheme: corev1.URISchemeHTTP}
+	case hc.TCPPort > 0:
+		probe.TCPSocket = &corev1.TCPSocketAction{Port: intstr.FromInt(hc.TCPPort)}
+	case len(service.Ports) > 0:
+		probe.TCPSocket = &corev1.TCPSocketAction{Port: intstr.FromInt(service.Ports[0])}
+	default:
+		// no way to check, ready when container running
+		return nil
+	}
+	return probe
+}
+
+func makeServicePodName(jobName, serviceName string) string {
+	return strutil.Concat(jobName, "-", serviceName)
+}
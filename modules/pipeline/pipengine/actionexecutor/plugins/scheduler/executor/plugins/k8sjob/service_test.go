@@ -0,0 +1,98 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sjob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+)
+
+func Test_generateServiceProbe(t *testing.T) {
+	// default check the first port
+	probe := generateServiceProbe(&apistructs.PipelineTaskService{Image: "redis", Ports: []int{6379}})
+	assert.NotNil(t, probe.TCPSocket)
+	assert.Equal(t, 6379, probe.TCPSocket.Port.IntValue())
+	assert.Equal(t, int32(apistructs.PipelineTaskServiceDefaultPeriodSec), probe.PeriodSeconds)
+
+	probe = generateServiceProbe(&apistructs.PipelineTaskService{
+		Image:       "mysql",
+		Ports:       []int{3306},
+		HealthCheck: &apistructs.PipelineTaskServiceHealthCheck{Exec: "mysqladmin ping", PeriodSec: 5},
+	})
+	assert.Nil(t, probe.TCPSocket)
+	assert.Equal(t, []string{"sh", "-c", "mysqladmin ping"}, probe.Exec.Command)
+	assert.Equal(t, int32(5), probe.PeriodSeconds)
+
+	probe = generateServiceProbe(&apistructs.PipelineTaskService{
+		Image:       "nginx",
+		HealthCheck: &apistructs.PipelineTaskServiceHealthCheck{HTTPPort: 80, HTTPPath: "/health"},
+	})
+	assert.Equal(t, "/health", probe.HTTPGet.Path)
+
+	// no port and no health check
+	assert.Nil(t, generateServiceProbe(&apistructs.PipelineTaskService{Image: "busybox"}))
+}
+
+func Test_generateServicePod(t *testing.T) {
+	task := &spec.PipelineTask{ID: 1}
+	job := apistructs.JobFromUser{Name: "pipeline-task-1-retry-2", Namespace: "pipeline-1"}
+	pod := generateServicePod(task, job, "mysql", &apistructs.PipelineTaskService{
+		Image: "mysql:5.7",
+		Envs:  map[string]string{"MYSQL_ROOT_PASSWORD": "root", "MYSQL_DATABASE": "test"},
+		Ports: []int{3306},
+	}, &apistructs.ScheduleInfo2{})
+	assert.Equal(t, "pipeline-task-1-retry-2-mysql", pod.Name)
+	assert.Equal(t, "pipeline-1", pod.Namespace)
+	assert.Equal(t, "1", pod.Labels[serviceLabelTaskID])
+	assert.Equal(t, "mysql", pod.Labels[serviceLabelName])
+	container := pod.Spec.Containers[0]
+	assert.Equal(t, "mysql:5.7", container.Image)
+	assert.Equal(t, "MYSQL_DATABASE", container.Env[0].Name)
+	assert.Equal(t, int32(3306), container.Ports[0].ContainerPort)
+	assert.Equal(t, "512Mi", container.Resources.Limits.Memory().String())
+	assert.NotNil(t, container.ReadinessProbe)
+}
+
+func Test_analyzeServicePod(t *testing.T) {
+	ready, _, fatal := analyzeServicePod(&corev1.Pod{Status: corev1.PodStatus{
+		Phase:      corev1.PodRunning,
+		PodIP:      "10.0.0.1",
+		Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+	}})
+	assert.True(t, ready)
+	assert.False(t, fatal)
+
+	ready, msg, fatal := analyzeServicePod(&corev1.Pod{Status: corev1.PodStatus{
+		Phase: corev1.PodPending,
+		ContainerStatuses: []corev1.ContainerStatus{{State: corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"},
+		}}},
+	}})
+	assert.False(t, ready)
+	assert.True(t, fatal)
+	assert.Contains(t, msg, errPullImage)
+
+	ready, _, fatal = analyzeServicePod(&corev1.Pod{Status: corev1.PodStatus{
+		Phase:      corev1.PodRunning,
+		Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse, Message: "containers with unready status"}},
+	}})
+	assert.False(t, ready)
+	assert.False(t, fatal)
+}
@@ -49,6 +49,12 @@ type TaskExecutor interface {
 	Inspect(ctx context.Context, task *spec.PipelineTask) (apistructs.TaskInspect, error)
 }
 
+// TaskServiceExecutor is implemented by task executors which support service containers declared in task.
+// Services are started and become healthy in Create, and removed by RemoveServices.
+type TaskServiceExecutor interface {
+	RemoveServices(ctx context.Context, task *spec.PipelineTask) error
+}
+
 type CreateFn func(name Name, clusterName string, cluster apistructs.ClusterInfo) (TaskExecutor, error)
 
 var Factory = map[Kind]CreateFn{}
@@ -192,7 +192,8 @@ func TransferToSchedulerJob(task *spec.PipelineTask) (job apistructs.JobFromUser
 func MakeVolume(task *spec.PipelineTask) []diceyml.Volume {
 	diceVolumes := make([]diceyml.Volume, 0)
 	for _, vo := range task.Extra.Volumes {
-		if vo.Type == string(spec.StoreTypeDiceVolumeFake) || vo.Type == string(spec.StoreTypeDiceCacheNFS) {
+		if vo.Type == string(spec.StoreTypeDiceVolumeFake) || vo.Type == string(spec.StoreTypeDiceCacheNFS) ||
+			vo.Type == string(spec.StoreTypeDiceCacheRemote) {
 			// fake volume,没有实际挂载行为,不传给scheduler
 			continue
 		}
@@ -196,6 +196,9 @@ func (s *Sched) Create(ctx context.Context, action *spec.PipelineTask) (data int
 	if err != nil {
 		return nil, err
 	}
+	if err = checkTaskServices(shouldDispatch, taskExecutor, action); err != nil {
+		return nil, err
+	}
 	if !shouldDispatch {
 		logrus.Debugf("task executor %s execute create, actionInfo: %s", taskExecutor.Name(), printActionInfo(action))
 		return nil, nil
@@ -267,6 +270,9 @@ func (s *Sched) Start(ctx context.Context, action *spec.PipelineTask) (data inte
 	if err != nil {
 		return nil, err
 	}
+	if err = checkTaskServices(shouldDispatch, taskExecutor, action); err != nil {
+		return nil, err
+	}
 	if !shouldDispatch {
 		logrus.Debugf("task executor %s execute start, actionInfo: %s", taskExecutor.Name(), printActionInfo(action))
 		return taskExecutor.Create(ctx, action)
@@ -532,6 +538,43 @@ func (s *Sched) BatchDelete(ctx context.Context, actions []*spec.PipelineTask) (
 	return "", nil
 }
 
+// RemoveServices remove service containers started alongside the task.
+func (s *Sched) RemoveServices(ctx context.Context, action *spec.PipelineTask) (err error) {
+	if len(action.Extra.Action.Services) == 0 {
+		return nil
+	}
+
+	defer wrapError(&err, "remove services", action)
+
+	if err = validateAction(action); err != nil {
+		return err
+	}
+
+	shouldDispatch, taskExecutor, err := s.GetTaskExecutor(action.Type, action.Extra.ClusterName, action)
+	if err != nil {
+		return err
+	}
+	serviceExecutor, ok := taskExecutor.(tasktypes.TaskServiceExecutor)
+	if shouldDispatch || !ok {
+		return nil
+	}
+	logrus.Debugf("task executor %s execute remove services, actionInfo: %s", taskExecutor.Name(), printActionInfo(action))
+	return serviceExecutor.RemoveServices(ctx, action)
+}
+
+// checkTaskServices services only supported by task executors which implement TaskServiceExecutor
+func checkTaskServices(shouldDispatch bool, taskExecutor tasktypes.TaskExecutor, action *spec.PipelineTask) error {
+	if len(action.Extra.Action.Services) == 0 {
+		return nil
+	}
+	if !shouldDispatch {
+		if _, ok := taskExecutor.(tasktypes.TaskServiceExecutor); ok {
+			return nil
+		}
+	}
+	return errors.Errorf("services is not supported by the executor of cluster %s", action.Extra.ClusterName)
+}
+
 func transferStatus(status string) apistructs.PipelineStatus {
 	switch status {
 
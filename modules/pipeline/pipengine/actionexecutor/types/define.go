@@ -46,6 +46,11 @@ type ActionExecutor interface {
 	BatchDelete(ctx context.Context, actions []*spec.PipelineTask) (interface{}, error)
 }
 
+// ServiceRemover is implemented by action executors which support service containers declared in action.
+type ServiceRemover interface {
+	RemoveServices(ctx context.Context, action *spec.PipelineTask) error
+}
+
 const kindNameFormat = `^[A-Z0-9]+$`
 
 var formatter = regexp.MustCompile(kindNameFormat)
@@ -23,6 +23,7 @@ import (
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/pipeline/spec"
 	"github.com/erda-project/erda/pkg/parser/diceyml"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
 )
 
 const (
@@ -48,27 +49,11 @@ func HandleTaskCacheVolumes(p *spec.Pipeline, task *spec.PipelineTask, diceYmlJo
 	var volumes []apistructs.MetadataField
 	var binds diceyml.Binds
 	for _, cache := range caches {
-		// 根据指定的绝对目录名生成一个 hash 的文件名
-		hasher := sha256.New()
-		hasher.Write([]byte(cache.Path))
-		hash := hex.EncodeToString(hasher.Sum(nil))
-
-		// key 为空就根据 hash 值和一些前缀生成一个固定的挂载目录
-		key := cache.Key
-		if key == "" {
-			key = filepath.Join(mountPoint, TaskCacheBasePath, projectID, appID, hash)
-		} else {
-			// key 不为空就需要根据占位符替换成固定的挂载目录，其中只有非占位符之间是用户可自定义的一部分
-			key = strings.ReplaceAll(key, " ", "")
-			// 用户自定义，且 projectID 为空，appID 为空, 可以不用 projectID 和 appID 为前缀
-			if projectID == "" && appID == "" {
-				key = strings.ReplaceAll(key, TaskCachePathBasePath, filepath.Join(mountPoint+TaskCacheBasePath))
-				key = strings.ReplaceAll(key, TaskCachePathEndPath, hash)
-			} else {
-				key = strings.ReplaceAll(key, TaskCachePathBasePath, filepath.Join(mountPoint, TaskCacheBasePath, projectID, appID))
-				key = strings.ReplaceAll(key, TaskCachePathEndPath, hash)
-			}
+		// 远程缓存由 HandleTaskRemoteCaches 处理
+		if IsRemoteCache(cache) {
+			continue
 		}
+		key, hash := MakeTaskCacheKey(projectID, appID, cache, mountPoint)
 
 		labels := make(map[string]string)
 		labels[VoLabelKeyContainerPath] = key
@@ -91,3 +76,28 @@ func HandleTaskCacheVolumes(p *spec.Pipeline, task *spec.PipelineTask, diceYmlJo
 	// add binds
 	diceYmlJob.Binds = append(diceYmlJob.Binds, binds...)
 }
+
+// MakeTaskCacheKey 根据缓存配置生成挂载目录 (key) 和 path 的 hash 值
+func MakeTaskCacheKey(projectID, appID string, cache pipelineyml.ActionCache, mountPoint string) (key, hash string) {
+	// 根据指定的绝对目录名生成一个 hash 的文件名
+	hasher := sha256.New()
+	hasher.Write([]byte(cache.Path))
+	hash = hex.EncodeToString(hasher.Sum(nil))
+
+	// key 为空就根据 hash 值和一些前缀生成一个固定的挂载目录
+	key = cache.Key
+	if key == "" {
+		return filepath.Join(mountPoint, TaskCacheBasePath, projectID, appID, hash), hash
+	}
+	// key 不为空就需要根据占位符替换成固定的挂载目录，其中只有非占位符之间是用户可自定义的一部分
+	key = strings.ReplaceAll(key, " ", "")
+	// 用户自定义，且 projectID 为空，appID 为空, 可以不用 projectID 和 appID 为前缀
+	if projectID == "" && appID == "" {
+		key = strings.ReplaceAll(key, TaskCachePathBasePath, filepath.Join(mountPoint+TaskCacheBasePath))
+		key = strings.ReplaceAll(key, TaskCachePathEndPath, hash)
+	} else {
+		key = strings.ReplaceAll(key, TaskCachePathBasePath, filepath.Join(mountPoint, TaskCacheBasePath, projectID, appID))
+		key = strings.ReplaceAll(key, TaskCachePathEndPath, hash)
+	}
+	return key, hash
+}
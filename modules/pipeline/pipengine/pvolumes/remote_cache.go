@@ -0,0 +1,164 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pvolumes
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+)
+
+const (
+	TaskCacheKey         = "action_cache_key"
+	TaskCacheRestoreKeys = "action_cache_restore_keys"
+)
+
+// IsRemoteCache 指定了 key 且 key 中不包含 {{basePath}} {{endPath}} 占位符的缓存为远程缓存，
+// 远程缓存不依赖共享存储, 由 action agent 通过 openapi 上传和下载
+func IsRemoteCache(cache pipelineyml.ActionCache) bool {
+	key := strings.ReplaceAll(cache.Key, " ", "")
+	return key != "" && !strings.Contains(key, TaskCachePathBasePath) && !strings.Contains(key, TaskCachePathEndPath)
+}
+
+// HandleTaskRemoteCaches 将远程缓存添加到 task 的 InStorages 和 OutStorages 中,
+// key 中的模板需要在容器中根据文件内容渲染, 所以原样传给 action agent
+func HandleTaskRemoteCaches(task *spec.PipelineTask) {
+	var storages []apistructs.MetadataField
+	for _, cache := range task.Extra.Action.Caches {
+		if !IsRemoteCache(cache) {
+			continue
+		}
+		hasher := sha256.New()
+		hasher.Write([]byte(cache.Path))
+		hash := hex.EncodeToString(hasher.Sum(nil))
+
+		labels := make(map[string]string)
+		labels[TaskCachePath] = cache.Path
+		labels[TaskCacheKey] = cache.Key
+		if len(cache.RestoreKeys) > 0 {
+			labels[TaskCacheRestoreKeys] = strings.Join(cache.RestoreKeys, "\n")
+		}
+		storages = append(storages, apistructs.MetadataField{
+			Name:   TaskCacheMame + "_" + hash,
+			Type:   string(spec.StoreTypeDiceCacheRemote),
+			Value:  cache.Path,
+			Labels: labels,
+		})
+	}
+
+	task.Context.InStorages = append(task.Context.InStorages, storages...)
+	task.Context.OutStorages = append(task.Context.OutStorages, storages...)
+}
+
+// GetRemoteCacheRestoreKeys 从 storage labels 中获取 restoreKeys
+func GetRemoteCacheRestoreKeys(labels map[string]string) []string {
+	if labels[TaskCacheRestoreKeys] == "" {
+		return nil
+	}
+	return strings.Split(labels[TaskCacheRestoreKeys], "\n")
+}
+
+// ParseCacheKey 校验远程缓存 key 的模板语法
+func ParseCacheKey(key string) error {
+	_, err := template.New("key").Funcs(cacheKeyFuncs("")).Option("missingkey=error").Parse(key)
+	return err
+}
+
+// RenderCacheKey 渲染远程缓存 key, 如 go-{{ hashFiles "go.sum" }}, 相对路径基于 wd
+func RenderCacheKey(key, wd string) (string, error) {
+	tmpl, err := template.New("key").Funcs(cacheKeyFuncs(wd)).Option("missingkey=error").Parse(key)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func cacheKeyFuncs(wd string) template.FuncMap {
+	return template.FuncMap{
+		"hashFiles": func(patterns ...string) (string, error) {
+			return HashFiles(wd, patterns...)
+		},
+	}
+}
+
+// HashFiles 计算匹配 patterns 的所有文件的 sha256, 文件按路径排序后依次计算每个文件内容的 sha256 再汇总,
+// 保证相同内容得到相同的 hash, 没有匹配的文件时返回空字符串
+func HashFiles(wd string, patterns ...string) (string, error) {
+	files := make(map[string]struct{})
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(wd, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return "", err
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return "", err
+			}
+			if info.Mode().IsRegular() {
+				files[match] = struct{}{}
+			}
+		}
+	}
+	if len(files) == 0 {
+		return "", nil
+	}
+
+	sorted := make([]string, 0, len(files))
+	for file := range files {
+		sorted = append(sorted, file)
+	}
+	sort.Strings(sorted)
+
+	hasher := sha256.New()
+	for _, file := range sorted {
+		sum, err := hashFile(file)
+		if err != nil {
+			return "", err
+		}
+		hasher.Write(sum)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func hashFile(file string) ([]byte, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return nil, err
+	}
+	return hasher.Sum(nil), nil
+}
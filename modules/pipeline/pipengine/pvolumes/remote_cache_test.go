@@ -0,0 +1,94 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pvolumes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/modules/pipeline/spec"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+)
+
+func TestIsRemoteCache(t *testing.T) {
+	assert.False(t, IsRemoteCache(pipelineyml.ActionCache{Path: "/root/.m2"}))
+	assert.False(t, IsRemoteCache(pipelineyml.ActionCache{Path: "/root/.m2", Key: "{{basePath}}/maven/{{endPath}}"}))
+	assert.False(t, IsRemoteCache(pipelineyml.ActionCache{Path: "/root/.m2", Key: "{{ basePath }}/maven/{{ endPath }}"}))
+	assert.True(t, IsRemoteCache(pipelineyml.ActionCache{Path: "/root/.m2", Key: `maven-{{ hashFiles "pom.xml" }}`}))
+}
+
+func TestHandleTaskRemoteCaches(t *testing.T) {
+	task := &spec.PipelineTask{}
+	task.Extra.Action.Caches = []pipelineyml.ActionCache{
+		{Path: "/root/.m2"},
+		{Path: "/go/pkg/mod", Key: `go-{{ hashFiles "go.sum" }}`, RestoreKeys: []string{"go-"}},
+	}
+	HandleTaskRemoteCaches(task)
+	assert.Equal(t, 1, len(task.Context.InStorages))
+	assert.Equal(t, task.Context.InStorages, task.Context.OutStorages)
+	storage := task.Context.InStorages[0]
+	assert.Equal(t, string(spec.StoreTypeDiceCacheRemote), storage.Type)
+	assert.Equal(t, "/go/pkg/mod", storage.Labels[TaskCachePath])
+	assert.Equal(t, `go-{{ hashFiles "go.sum" }}`, storage.Labels[TaskCacheKey])
+	assert.Equal(t, []string{"go-"}, GetRemoteCacheRestoreKeys(storage.Labels))
+	assert.Nil(t, GetRemoteCacheRestoreKeys(nil))
+}
+
+func TestRenderCacheKey(t *testing.T) {
+	wd, err := ioutil.TempDir("", "cache-key")
+	assert.NoError(t, err)
+	defer os.RemoveAll(wd)
+	assert.NoError(t, os.MkdirAll(filepath.Join(wd, "sub"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(wd, "go.sum"), []byte("a"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(wd, "sub", "go.sum"), []byte("b"), 0644))
+
+	// 固定内容的 hash
+	key, err := RenderCacheKey(`go-{{ hashFiles "go.sum" }}`, wd)
+	assert.NoError(t, err)
+	assert.Equal(t, "go-bf5d3affb73efd2ec6c36ad3112dd933efed63c4e1cbffcfa88e2759c144f2d8", key)
+
+	// 绝对路径和相对路径结果相同
+	abs, err := RenderCacheKey(`go-{{ hashFiles "`+filepath.Join(wd, "go.sum")+`" }}`, "/")
+	assert.NoError(t, err)
+	assert.Equal(t, key, abs)
+
+	// 多个文件与顺序无关
+	k1, err := RenderCacheKey(`{{ hashFiles "go.sum" "sub/go.sum" }}`, wd)
+	assert.NoError(t, err)
+	k2, err := RenderCacheKey(`{{ hashFiles "*/go.sum" "go.sum" }}`, wd)
+	assert.NoError(t, err)
+	assert.Equal(t, k1, k2)
+	assert.NotEqual(t, "go-"+k1, key)
+
+	// 内容变化后 key 变化
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(wd, "go.sum"), []byte("c"), 0644))
+	changed, err := RenderCacheKey(`go-{{ hashFiles "go.sum" }}`, wd)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, changed)
+
+	// 没有匹配的文件
+	empty, err := RenderCacheKey(`go-{{ hashFiles "none.sum" }}`, wd)
+	assert.NoError(t, err)
+	assert.Equal(t, "go-", empty)
+
+	_, err = RenderCacheKey(`go-{{ hashFiles "go.sum" }`, wd)
+	assert.Error(t, err)
+	assert.Error(t, ParseCacheKey(`go-{{ unknown "go.sum" }}`))
+	assert.NoError(t, ParseCacheKey(`go-{{ hashFiles "go.sum" }}`))
+}
@@ -17,9 +17,11 @@ package reconciler
 import (
 	"sort"
 
+	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/pipeline/pipengine/reconciler/rlog"
 	"github.com/erda-project/erda/modules/pipeline/spec"
 	"github.com/erda-project/erda/pkg/dag"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
 	"github.com/erda-project/erda/pkg/strutil"
 )
 
@@ -48,16 +50,21 @@ func (r *Reconciler) getSchedulableTasks(p *spec.Pipeline, tasks []*spec.Pipelin
 		return nil, err
 	}
 
-	// transfer schedulable nodes to tasks
-	taskMap := make(map[string]*spec.PipelineTask)
+	// transfer schedulable nodes to tasks, keep the order of tasks so matrix tasks are scheduled in the expanded order
+	var candidateTasks, processingTasks []*spec.PipelineTask
 	for _, task := range tasks {
-		taskMap[task.Name] = task
+		if _, ok := schedulableNodeFromDAG[task.Name]; !ok {
+			continue
+		}
+		// if task is already processing by another goroutine, skip
+		if _, alreadyProcessing := r.processingTasks.Load(buildTaskDagName(p.ID, task.Name)); alreadyProcessing {
+			processingTasks = append(processingTasks, task)
+			continue
+		}
+		candidateTasks = append(candidateTasks, task)
 	}
 	var schedulableTasks []*spec.PipelineTask
-	for nodeName := range schedulableNodeFromDAG {
-		// get task by nodeName
-		task := taskMap[nodeName]
-		// if task is already processing by another goroutine, skip
+	for _, task := range limitMatrixParallel(p, candidateTasks, processingTasks) {
 		if _, alreadyProcessing := r.processingTasks.LoadOrStore(buildTaskDagName(p.ID, task.Name), true); alreadyProcessing {
 			continue
 		}
@@ -80,3 +87,34 @@ func (r *Reconciler) getSchedulableTasks(p *spec.Pipeline, tasks []*spec.Pipelin
 
 	return schedulableTasks, nil
 }
+
+// limitMatrixParallel limit the running tasks expanded from the same matrix action by max_parallel.
+// Processing tasks and started tasks (e.g. reconciled again after restart) count as running,
+// started tasks are always schedulable so they can be continued.
+func limitMatrixParallel(p *spec.Pipeline, candidateTasks, processingTasks []*spec.PipelineTask) []*spec.PipelineTask {
+	running := make(map[pipelineyml.ActionAlias]int)
+	for _, task := range processingTasks {
+		running[task.Extra.Action.MatrixSource]++
+	}
+	for _, task := range candidateTasks {
+		if task.Status != apistructs.PipelineStatusAnalyzed {
+			running[task.Extra.Action.MatrixSource]++
+		}
+	}
+
+	var tasks []*spec.PipelineTask
+	for _, task := range candidateTasks {
+		action := task.Extra.Action
+		if action.MatrixSource == "" || action.MatrixMaxParallel <= 0 || task.Status != apistructs.PipelineStatusAnalyzed {
+			tasks = append(tasks, task)
+			continue
+		}
+		if running[action.MatrixSource] >= action.MatrixMaxParallel {
+			rlog.TInfof(p.ID, task.ID, "matrix %s reached max_parallel %d, wait for another task done", action.MatrixSource, action.MatrixMaxParallel)
+			continue
+		}
+		running[action.MatrixSource]++
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/pipeline/spec"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
 )
 
 func TestGetSchedulableTasks(t *testing.T) {
@@ -61,3 +62,52 @@ func TestGetSchedulableTasks(t *testing.T) {
 		})
 	}
 }
+
+func TestGetSchedulableTasksMatrixMaxParallel(t *testing.T) {
+	r := Reconciler{
+		processingTasks: sync.Map{},
+	}
+	p := &spec.Pipeline{PipelineBase: spec.PipelineBase{ID: 1}}
+	newMatrixTask := func(name string, status apistructs.PipelineStatus) *spec.PipelineTask {
+		return &spec.PipelineTask{Name: name, Status: status, Extra: spec.PipelineTaskExtra{
+			RunAfter: []string{},
+			Action:   pipelineyml.Action{Alias: pipelineyml.ActionAlias(name), MatrixSource: "build", MatrixMaxParallel: 2},
+		}}
+	}
+	tasks := []*spec.PipelineTask{
+		newMatrixTask("build-1", apistructs.PipelineStatusAnalyzed),
+		newMatrixTask("build-2", apistructs.PipelineStatusAnalyzed),
+		newMatrixTask("build-3", apistructs.PipelineStatusAnalyzed),
+		{Name: "other", Status: apistructs.PipelineStatusAnalyzed, Extra: spec.PipelineTaskExtra{RunAfter: []string{}}},
+	}
+
+	schedulableTasks, err := r.getSchedulableTasks(p, tasks)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"build-1", "build-2", "other"}, taskNames(schedulableTasks))
+
+	// build-1 is still processing, build-3 waits
+	r.processingTasks.Delete(buildTaskDagName(p.ID, "build-2"))
+	r.processingTasks.Delete(buildTaskDagName(p.ID, "other"))
+	tasks[1].Status = apistructs.PipelineStatusSuccess
+	tasks[3].Status = apistructs.PipelineStatusSuccess
+	schedulableTasks, err = r.getSchedulableTasks(p, tasks)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"build-3"}, taskNames(schedulableTasks))
+
+	// started tasks are continued after restart even if over max_parallel
+	r = Reconciler{processingTasks: sync.Map{}}
+	for _, task := range tasks {
+		task.Status = apistructs.PipelineStatusRunning
+	}
+	schedulableTasks, err = r.getSchedulableTasks(p, tasks)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"build-1", "build-2", "build-3", "other"}, taskNames(schedulableTasks))
+}
+
+func taskNames(tasks []*spec.PipelineTask) []string {
+	var names []string
+	for _, task := range tasks {
+		names = append(names, task.Name)
+	}
+	return names
+}
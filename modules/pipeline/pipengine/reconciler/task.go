@@ -37,6 +37,10 @@ var (
 func reconcileTask(tr *taskrun.TaskRun) error {
 	rlog.TDebugf(tr.P.ID, tr.Task.ID, "start reconcile task")
 	defer rlog.TDebugf(tr.P.ID, tr.Task.ID, "end reconcile task")
+	if tr.Task.Extra.Action.MatrixSource != "" {
+		rlog.TInfof(tr.P.ID, tr.Task.ID, "reconcile matrix task, source: %s, combination: %v",
+			tr.Task.Extra.Action.MatrixSource, tr.Task.Extra.Action.MatrixCombination)
+	}
 	// do metric
 	go metrics.TaskGaugeProcessingAdd(*tr.Task, 1)
 	defer func() {
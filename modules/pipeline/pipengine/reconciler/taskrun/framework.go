@@ -118,6 +118,11 @@ func (tr *TaskRun) waitOp(itr TaskOp, o *Elem) (result error) {
 			})
 		}
 
+		// retry
+		if err := tr.handleTaskRetry(); err != nil {
+			errs = append(errs, fmt.Sprintf("%v", err))
+		}
+
 		// loop
 		if err := tr.handleTaskLoop(); err != nil {
 			// append err loop
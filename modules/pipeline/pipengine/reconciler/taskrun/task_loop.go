@@ -114,8 +114,13 @@ func (tr *TaskRun) resetTaskForLoop() {
 
 	// reset task status
 	tr.Task.Extra.LoopOptions.LoopedTimes++
+	tr.resetTaskForRerun()
+}
+
+// resetTaskForRerun reset task status, time, volume and tr flags, used by loop and retry
+func (tr *TaskRun) resetTaskForRerun() {
 	tr.Task.Status = apistructs.PipelineStatusAnalyzed
-	// reset time for loop, all based on the last time
+	// reset time for rerun, all based on the last time
 	tr.Task.CostTimeSec = -1
 	tr.Task.QueueTimeSec = -1
 	tr.Task.Extra.TimeBeginQueue = time.Time{}
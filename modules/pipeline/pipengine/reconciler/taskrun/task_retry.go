@@ -0,0 +1,156 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskrun
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/reconciler/rlog"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+	"github.com/erda-project/erda/pkg/loop"
+)
+
+// handleTaskRetry Determine whether the failed task needs to retry by retry policy; if necessary, wait for backoff, record the attempt and reset the task
+func (tr *TaskRun) handleTaskRetry() error {
+	// only failed task can retry
+	if !tr.Task.Status.IsFailedStatus() {
+		return nil
+	}
+
+	// No retry configuration skip
+	retryOpt := tr.Task.Extra.RetryOptions
+	if retryOpt == nil || retryOpt.Retry == nil {
+		return nil
+	}
+
+	// The end state of the pipeline does not retry tasks
+	tr.EnsureFetchLatestPipelineStatus()
+	if tr.QueriedPipelineStatus.IsEndStatus() {
+		rlog.TWarnf(tr.P.ID, tr.Task.ID, "pipeline is already end status (%s), not try to retry task", tr.QueriedPipelineStatus)
+		return nil
+	}
+
+	retryOn, match := retryOpt.Retry.Match(tr.Task.Status, tr.Task.Inspect.ExitCode, getTaskErrMsgs(tr.Task))
+	if !match {
+		rlog.TDebugf(tr.P.ID, tr.Task.ID, "task status %s doesn't match retry policy, not retry", tr.Task.Status)
+		return nil
+	}
+	// The maximum number of attempts has been reached, stop retry
+	if tr.Task.GetAttempt() >= retryOpt.Retry.MaxAttempts {
+		rlog.TInfof(tr.P.ID, tr.Task.ID, "retry reached max attempts %d, stop retry", retryOpt.Retry.MaxAttempts)
+		return nil
+	}
+	rlog.TInfof(tr.P.ID, tr.Task.ID, "task attempt %d failed (status: %s, retry on: %s), continue retry",
+		tr.Task.GetAttempt(), tr.Task.Status, retryOn)
+
+	// take a snapshot of the finished attempt before backoff, its end time should not include the sleep
+	attempt := tr.makeTaskAttempt(retryOn)
+	if !tr.waitRetryBackoff() {
+		return nil
+	}
+
+	// record attempt only when the next attempt actually runs, and before reset to avoid missing task info
+	tr.Task.Extra.RetryOptions.Attempts = append(tr.Task.Extra.RetryOptions.Attempts, attempt)
+	if err := tr.reportTaskForRetry(); err != nil {
+		rlog.Errorf("failed to report task-retry, pipelineID: %d, taskID: %d, err: %v", tr.P.ID, tr.Task.ID, err)
+	}
+
+	tr.resetTaskForRetry()
+	return nil
+}
+
+// makeTaskAttempt make attempt info from the current finished attempt
+func (tr *TaskRun) makeTaskAttempt(retryOn apistructs.PipelineTaskRetryOn) *apistructs.PipelineTaskAttempt {
+	timeEnd := tr.Task.TimeEnd
+	if timeEnd.IsZero() {
+		timeEnd = time.Now()
+	}
+	return &apistructs.PipelineTaskAttempt{
+		Attempt:     tr.Task.GetAttempt(),
+		Status:      tr.Task.Status,
+		RetryOn:     retryOn,
+		LogID:       tr.Task.GetLogID(),
+		ExitCode:    tr.Task.Inspect.ExitCode,
+		Errors:      tr.Task.Inspect.Errors,
+		CostTimeSec: tr.Task.CostTimeSec,
+		TimeBegin:   tr.Task.TimeBegin,
+		TimeEnd:     timeEnd,
+	}
+}
+
+// reportTaskForRetry record retried task info
+func (tr *TaskRun) reportTaskForRetry() error {
+	meta := map[string]interface{}{
+		fmt.Sprintf("task-%d-retry-%d", tr.Task.ID, tr.Task.GetAttempt()): *tr.Task,
+	}
+	return tr.DBClient.CreatePipelineReport(&spec.PipelineReport{
+		PipelineID: tr.P.ID,
+		Type:       apistructs.PipelineReportRetryMetaKey,
+		Meta:       meta,
+	})
+}
+
+// waitRetryBackoff sleep for backoff, return false if the pipeline is already end after waiting
+func (tr *TaskRun) waitRetryBackoff() bool {
+	// Calculate sleep time
+	interval := getRetryBackoffInterval(tr.Task.Extra.RetryOptions.Retry, tr.Task.GetAttempt())
+	rlog.TDebugf(tr.P.ID, tr.Task.ID, "sleep %s before retry", interval.String())
+	time.Sleep(interval)
+
+	// sleep time may be very long, after waiting, check the latest status again
+	tr.EnsureFetchLatestPipelineStatus()
+	if tr.QueriedPipelineStatus.IsEndStatus() {
+		rlog.TWarnf(tr.P.ID, tr.Task.ID, "pipeline is already end status (%s), not retry task after sleep", tr.QueriedPipelineStatus)
+		return false
+	}
+	return true
+}
+
+// getRetryBackoffInterval the first retry after attempt 1 waits the initial interval, then declines by ratio
+func getRetryBackoffInterval(retry *apistructs.PipelineTaskRetry, attempt uint64) time.Duration {
+	backoff := retry.GetBackoff()
+	return loop.New(
+		loop.WithInterval(time.Second*time.Duration(backoff.IntervalSec)),
+		loop.WithDeclineRatio(backoff.DeclineRatio),
+		loop.WithDeclineLimit(time.Second*time.Duration(backoff.DeclineLimitSec)),
+	).CalculateInterval(attempt)
+}
+
+func (tr *TaskRun) resetTaskForRetry() {
+	// next attempt
+	tr.Task.Extra.RetryOptions.Attempt = tr.Task.GetAttempt() + 1
+	tr.resetTaskForRerun()
+
+	// errors and exit code belong to the previous attempt, which already recorded
+	tr.Task.Inspect = apistructs.PipelineTaskInspect{}
+	if err := tr.DBClient.UpdatePipelineTaskInspect(tr.Task.ID, tr.Task.Inspect); err != nil {
+		rlog.TWarnf(tr.P.ID, tr.Task.ID, "failed to clean task inspect, err: %v", err)
+	}
+}
+
+func getTaskErrMsgs(task *spec.PipelineTask) []string {
+	var msgs []string
+	for _, e := range task.Inspect.Errors {
+		if e != nil {
+			msgs = append(msgs, e.Msg)
+		}
+	}
+	if task.Inspect.Events != "" {
+		msgs = append(msgs, task.Inspect.Events)
+	}
+	return msgs
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskrun
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/bmizerany/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/dbclient"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+)
+
+func Test_getRetryBackoffInterval(t *testing.T) {
+	retry := &apistructs.PipelineTaskRetry{
+		MaxAttempts: 5,
+		Backoff:     &apistructs.PipelineTaskRetryBackoff{IntervalSec: 10, DeclineRatio: 2, DeclineLimitSec: 30},
+	}
+	// the first retry after attempt 1 also waits
+	assert.Equal(t, 10*time.Second, getRetryBackoffInterval(retry, 1))
+	assert.Equal(t, 20*time.Second, getRetryBackoffInterval(retry, 2))
+	assert.Equal(t, 30*time.Second, getRetryBackoffInterval(retry, 3))
+}
+
+func TestTaskRun_handleTaskRetry(t *testing.T) {
+	tests := []struct {
+		name            string
+		statusAfterWait apistructs.PipelineStatus
+		wantAttempt     uint64
+		wantAttempts    int
+		wantStatus      apistructs.PipelineStatus
+	}{
+		{
+			name:            "retry after backoff",
+			statusAfterWait: apistructs.PipelineStatusRunning,
+			wantAttempt:     2,
+			wantAttempts:    1,
+			wantStatus:      apistructs.PipelineStatusAnalyzed,
+		},
+		{
+			name:            "pipeline end during backoff",
+			statusAfterWait: apistructs.PipelineStatusStopByUser,
+			wantAttempt:     1,
+			wantAttempts:    0,
+			wantStatus:      apistructs.PipelineStatusFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var client *dbclient.Client
+			patch1 := monkey.PatchInstanceMethod(reflect.TypeOf(client), "CreatePipelineReport", func(client *dbclient.Client, report *spec.PipelineReport, ops ...dbclient.SessionOption) error {
+				return nil
+			})
+			defer patch1.Unpatch()
+			patch2 := monkey.PatchInstanceMethod(reflect.TypeOf(client), "CleanPipelineTaskResult", func(client *dbclient.Client, id uint64, ops ...dbclient.SessionOption) error {
+				return nil
+			})
+			defer patch2.Unpatch()
+			patch3 := monkey.PatchInstanceMethod(reflect.TypeOf(client), "UpdatePipelineTaskInspect", func(client *dbclient.Client, id uint64, inspect apistructs.PipelineTaskInspect) error {
+				return nil
+			})
+			defer patch3.Unpatch()
+
+			var slept []time.Duration
+			patch4 := monkey.Patch(time.Sleep, func(d time.Duration) {
+				slept = append(slept, d)
+			})
+			defer patch4.Unpatch()
+
+			// the first fetch is before backoff, the second is after backoff
+			var tr = &TaskRun{}
+			fetched := 0
+			patch5 := monkey.PatchInstanceMethod(reflect.TypeOf(tr), "EnsureFetchLatestPipelineStatus", func(tr *TaskRun) {
+				fetched++
+				tr.QueriedPipelineStatus = apistructs.PipelineStatusRunning
+				if len(slept) > 0 {
+					tr.QueriedPipelineStatus = tt.statusAfterWait
+				}
+			})
+			defer patch5.Unpatch()
+
+			tr.P = &spec.Pipeline{PipelineBase: spec.PipelineBase{ID: 1}}
+			tr.DBClient = client
+			tr.Task = &spec.PipelineTask{
+				ID:     1,
+				Status: apistructs.PipelineStatusFailed,
+				Extra: spec.PipelineTaskExtra{
+					RetryOptions: &apistructs.PipelineTaskRetryOptions{
+						Retry:   &apistructs.PipelineTaskRetry{MaxAttempts: 3},
+						Attempt: apistructs.TaskAttemptBegin,
+					},
+				},
+			}
+
+			if err := tr.handleTaskRetry(); err != nil {
+				t.Errorf("handleTaskRetry() error = %v", err)
+			}
+			assert.Equal(t, 2, fetched)
+			assert.Equal(t, []time.Duration{10 * time.Second}, slept)
+			assert.Equal(t, tt.wantAttempt, tr.Task.GetAttempt())
+			assert.Equal(t, tt.wantAttempts, len(tr.Task.Extra.RetryOptions.Attempts))
+			assert.Equal(t, tt.wantStatus, tr.Task.Status)
+			if tt.wantAttempts > 0 {
+				attempt := tr.Task.Extra.RetryOptions.Attempts[0]
+				assert.Equal(t, uint64(1), attempt.Attempt)
+				assert.Equal(t, apistructs.PipelineStatusFailed, attempt.Status)
+			}
+		})
+	}
+}
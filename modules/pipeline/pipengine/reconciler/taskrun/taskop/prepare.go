@@ -235,6 +235,10 @@ func (pre *prepare) makeTaskRun() (needRetry bool, err error) {
 		newK := strings.Replace(strings.Replace(strings.ToUpper(k), ".", "_", -1), "-", "_", -1)
 		task.Extra.PrivateEnvs["ACTION_"+newK] = fmt.Sprintf("%v", v)
 	}
+	// matrix values -> envs
+	for k, v := range pipelineyml.MatrixEnvs(action.MatrixCombination) {
+		task.Extra.PrivateEnvs[k] = v
+	}
 	// secrets -> envs
 	for k, v := range p.Snapshot.Secrets {
 		newK := strings.Replace(strings.Replace(strings.ToUpper(k), ".", "_", -1), "-", "_", -1)
@@ -259,7 +263,7 @@ func (pre *prepare) makeTaskRun() (needRetry bool, err error) {
 	task.Extra.PublicEnvs["PIPELINE_ID"] = strconv.FormatUint(p.ID, 10)
 	task.Extra.PublicEnvs["PIPELINE_TASK_ID"] = fmt.Sprintf("%v", task.ID)
 	task.Extra.PublicEnvs["PIPELINE_TASK_NAME"] = task.Name
-	task.Extra.PublicEnvs[PipelineTaskLogID] = task.Extra.UUID
+	task.Extra.PublicEnvs[PipelineTaskLogID] = task.GetLogID()
 	task.Extra.PublicEnvs[PipelineDebugMode] = "false"
 	task.Extra.PrivateEnvs[actionagent.CONTEXTDIR] = pvolumes.ContainerContextDir
 	task.Extra.PrivateEnvs[actionagent.WORKDIR] = pvolumes.MakeTaskContainerWorkdir(task.Name)
@@ -281,7 +285,7 @@ func (pre *prepare) makeTaskRun() (needRetry bool, err error) {
 	if task.Extra.Labels == nil {
 		task.Extra.Labels = make(map[string]string)
 	}
-	task.Extra.Labels[apistructs.TerminusDefineTag] = task.Extra.UUID
+	task.Extra.Labels[apistructs.TerminusDefineTag] = task.GetLogID()
 
 	// --- image ---
 	// 所有 action，包括 custom-script，都需要在 ext market 注册；
@@ -515,6 +519,15 @@ makeOutStorages:
 		task.Extra.LoopOptions = getLoopOptions(*specYmlJob, action.Loop)
 	}
 
+	// retry
+	// 若 retryOptions != nil，说明已经是在重试了，不能重新赋值
+	if task.Extra.RetryOptions == nil && action.Retry != nil {
+		task.Extra.RetryOptions = &apistructs.PipelineTaskRetryOptions{
+			Retry:   action.Retry,
+			Attempt: apistructs.TaskAttemptBegin, // 当前这次运行即为 1
+		}
+	}
+
 	// dedup context
 	task.Context.Dedup()
 	// cmd
@@ -529,6 +542,11 @@ makeOutStorages:
 		task.Status = apistructs.PipelineStatusBorn
 	}
 
+	// 远程缓存不依赖共享存储
+	if conf.ActionRemoteCacheEnabled() && task.ExecutorKind == spec.PipelineTaskExecutorKindScheduler {
+		pvolumes.HandleTaskRemoteCaches(task)
+	}
+
 	if (p.Extra.StorageConfig.EnableNFSVolume() || p.Extra.StorageConfig.EnableShareVolume()) && task.ExecutorKind == spec.PipelineTaskExecutorKindScheduler {
 		// 处理 task caches
 		pvolumes.HandleTaskCacheVolumes(p, task, diceYmlJob, mountPoint)
@@ -687,6 +705,24 @@ func (pre *prepare) generateOpenapiTokenForPullBootstrapInfo(task *spec.Pipeline
 					Method: http.MethodPost,
 					Schema: "http",
 				},
+				// PIPELINE_TASK_QUERY_CACHE
+				{
+					Path:   "/api/pipelines/<pipelineID>/tasks/<taskID>/actions/query-cache",
+					Method: http.MethodGet,
+					Schema: "http",
+				},
+				// PIPELINE_TASK_DOWNLOAD_CACHE
+				{
+					Path:   "/api/pipelines/<pipelineID>/tasks/<taskID>/actions/download-cache",
+					Method: http.MethodGet,
+					Schema: "http",
+				},
+				// PIPELINE_TASK_UPLOAD_CACHE
+				{
+					Path:   "/api/pipelines/<pipelineID>/tasks/<taskID>/actions/upload-cache",
+					Method: http.MethodPut,
+					Schema: "http",
+				},
 			},
 			Metadata: map[string]string{
 				"pipelineID":            strconv.FormatUint(task.PipelineID, 10),
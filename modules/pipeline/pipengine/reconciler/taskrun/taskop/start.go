@@ -183,7 +183,8 @@ func injectVolumeID(tr *taskrun.TaskRun, data interface{}) error {
 
 	// 遍历 task.Context.OutStorages，注入 volumeID
 	for i, declaredVolume := range tr.Task.Context.OutStorages {
-		if declaredVolume.Type == string(spec.StoreTypeDiceVolumeFake) || declaredVolume.Type == string(spec.StoreTypeDiceCacheNFS) {
+		if declaredVolume.Type == string(spec.StoreTypeDiceVolumeFake) || declaredVolume.Type == string(spec.StoreTypeDiceCacheNFS) ||
+			declaredVolume.Type == string(spec.StoreTypeDiceCacheRemote) {
 			// fake volume 没有实际逻辑，只是为了被引用到
 			continue
 		}
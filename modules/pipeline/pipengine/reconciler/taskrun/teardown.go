@@ -20,6 +20,7 @@ import (
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/pipeline/aop"
 	"github.com/erda-project/erda/modules/pipeline/aop/aoptypes"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/actionexecutor/types"
 	"github.com/erda-project/erda/modules/pipeline/pipengine/reconciler/rlog"
 	"github.com/erda-project/erda/pkg/strutil"
 )
@@ -45,6 +46,24 @@ func (tr *TaskRun) Teardown() {
 				tr.P.ID, tr.Task.ID, tr.Task.Name, token, err)
 		}
 	}
+
+	// remove service containers
+	tr.TeardownServices()
+}
+
+// TeardownServices remove service containers started alongside the task.
+func (tr *TaskRun) TeardownServices() {
+	if len(tr.Task.Extra.Action.Services) == 0 {
+		return
+	}
+	remover, ok := tr.Executor.(types.ServiceRemover)
+	if !ok {
+		return
+	}
+	if err := remover.RemoveServices(tr.Ctx, tr.Task); err != nil {
+		logrus.Errorf("[alert] reconciler: pipelineID: %d, taskID: %d, task %q failed to remove services, err: %v",
+			tr.P.ID, tr.Task.ID, tr.Task.Name, err)
+	}
 }
 
 func (tr *TaskRun) TeardownConcurrencyCount() {
@@ -27,21 +27,40 @@ func MakeJobIDSliceWithLoopedTimes(action *spec.PipelineTask) []string {
 		for i := apistructs.TaskLoopTimeBegin; i <= int(action.Extra.LoopOptions.LoopedTimes); i++ {
 			JobIDSlice = append(JobIDSlice, parseUUID(action.Extra.UUID, i))
 		}
+	} else {
+		JobIDSlice = append(JobIDSlice, action.Extra.UUID)
+	}
+	// retried attempts
+	attempt := action.GetAttempt()
+	if attempt <= apistructs.TaskAttemptBegin {
 		return JobIDSlice
 	}
-	JobIDSlice = append(JobIDSlice, action.Extra.UUID)
-	return JobIDSlice
+	var retriedJobIDSlice []string
+	for _, jobID := range JobIDSlice {
+		for i := apistructs.TaskAttemptBegin + 1; i <= int(attempt); i++ {
+			retriedJobIDSlice = append(retriedJobIDSlice, parseRetryUUID(jobID, i))
+		}
+	}
+	return append(JobIDSlice, retriedJobIDSlice...)
 }
 
 func parseUUID(uuid string, index int) string {
 	return fmt.Sprintf("%s-loop-%d", uuid, index)
 }
 
+func parseRetryUUID(uuid string, attempt int) string {
+	return fmt.Sprintf("%s-retry-%d", uuid, attempt)
+}
+
 func MakeJobID(action *spec.PipelineTask) string {
+	jobID := action.Extra.UUID
 	if isLoop(action) {
-		return parseUUID(action.Extra.UUID, int(action.Extra.LoopOptions.LoopedTimes))
+		jobID = parseUUID(action.Extra.UUID, int(action.Extra.LoopOptions.LoopedTimes))
+	}
+	if attempt := action.GetAttempt(); attempt > apistructs.TaskAttemptBegin {
+		jobID = parseRetryUUID(jobID, int(attempt))
 	}
-	return action.Extra.UUID
+	return jobID
 }
 
 func isLoop(action *spec.PipelineTask) bool {
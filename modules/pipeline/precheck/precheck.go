@@ -25,6 +25,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/conf"
 	"github.com/erda-project/erda/modules/pipeline/pipengine/pvolumes"
 	"github.com/erda-project/erda/modules/pipeline/precheck/checkers/actionchecker/api_register"
 	"github.com/erda-project/erda/modules/pipeline/precheck/checkers/actionchecker/buildpack"
@@ -222,6 +223,16 @@ func CheckCaches(actualAction *pipelineyml.Action, labels map[string]string) []s
 			continue
 		}
 
+		if pvolumes.IsRemoteCache(v) {
+			checkResults = append(checkResults, checkRemoteCache(actualAction, v)...)
+			continue
+		}
+
+		if len(v.RestoreKeys) > 0 {
+			checkResults = append(checkResults, fmt.Sprintf("taskName: %s, cache path: %s error: %s ",
+				actualAction.Alias, v.Path, "restoreKeys only support remote cache key, such as go-{{ hashFiles \"go.sum\" }}"))
+		}
+
 		if v.Key != "" {
 
 			v.Key = strings.ReplaceAll(v.Key, " ", "")
@@ -242,6 +253,23 @@ func CheckCaches(actualAction *pipelineyml.Action, labels map[string]string) []s
 	return checkResults
 }
 
+// checkRemoteCache 校验远程缓存已启用且 key 的模板语法正确
+func checkRemoteCache(actualAction *pipelineyml.Action, cache pipelineyml.ActionCache) []string {
+	var checkResults []string
+	if !conf.ActionRemoteCacheEnabled() {
+		checkResults = append(checkResults, fmt.Sprintf("taskName: %s, cache key: %s error: %s ",
+			actualAction.Alias, cache.Key, "remote cache is not enabled, key should be start with "+pvolumes.TaskCachePathBasePath))
+		return checkResults
+	}
+	for _, key := range append([]string{cache.Key}, cache.RestoreKeys...) {
+		if err := pvolumes.ParseCacheKey(key); err != nil {
+			checkResults = append(checkResults, fmt.Sprintf("taskName: %s, cache key: %s error: %v ",
+				actualAction.Alias, key, err))
+		}
+	}
+	return checkResults
+}
+
 func checkRequiredParams(actualAction pipelineyml.Action, actionSpec apistructs.ActionSpec) []string {
 	checkResults := make([]string, 0)
 
@@ -0,0 +1,308 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package actioncachesvc 实现 action 的远程缓存.
+// 缓存按项目隔离, 以 key 寻址保存在对象存储 (oss/minio) 中, 项目缓存总大小超过配额时按 LRU 淘汰.
+package actioncachesvc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/dbclient"
+	"github.com/erda-project/erda/modules/pipeline/services/apierrors"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+	"github.com/erda-project/erda/pkg/cloudstorage"
+)
+
+// MaxKeyLength 缓存 key 的最大长度
+const MaxKeyLength = 512
+
+type ActionCacheSvc struct {
+	dbClient *dbclient.Client
+	storage  cloudstorage.Client
+	bucket   string
+	prefix   string
+	quota    int64
+}
+
+// New 创建远程缓存服务, quota 为每个项目的缓存配额, 单位 Byte, 小于等于 0 表示不限制
+func New(dbClient *dbclient.Client, storage cloudstorage.Client, bucket, prefix string, quota int64) *ActionCacheSvc {
+	return &ActionCacheSvc{
+		dbClient: dbClient,
+		storage:  storage,
+		bucket:   bucket,
+		prefix:   prefix,
+		quota:    quota,
+	}
+}
+
+// KeyHash 返回缓存 key 的 sha256
+func KeyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ObjectPath 缓存对象的相对路径, 如 12/4d/4d7a2146...
+func ObjectPath(projectID uint64, keyHash string) string {
+	return path.Join(strconv.FormatUint(projectID, 10), keyHash[0:2], keyHash)
+}
+
+func (s *ActionCacheSvc) objectName(projectID uint64, keyHash string) string {
+	return path.Join(s.prefix, ObjectPath(projectID, keyHash))
+}
+
+// getProjectID 远程缓存按项目隔离, 没有项目的流水线共用 0
+func getProjectID(p *spec.Pipeline) uint64 {
+	id, _ := strconv.ParseUint(p.GetLabel(apistructs.LabelProjectID), 10, 64)
+	return id
+}
+
+func validateKey(path, key string) error {
+	if path == "" {
+		return fmt.Errorf("missing cache path")
+	}
+	if key == "" {
+		return fmt.Errorf("missing cache key")
+	}
+	if len(key) > MaxKeyLength {
+		return fmt.Errorf("cache key is too long, max length is %d", MaxKeyLength)
+	}
+	return nil
+}
+
+// Query 查询可以恢复的缓存, 优先完全匹配 key, 否则依次使用 restoreKeys 前缀匹配最新的缓存, 结果记录在 task inspect 中
+func (s *ActionCacheSvc) Query(p *spec.Pipeline, task *spec.PipelineTask, req apistructs.PipelineActionCacheQueryRequest) (*apistructs.PipelineTaskActionCache, error) {
+	if err := validateKey(req.Path, req.Key); err != nil {
+		return nil, apierrors.ErrQueryActionCache.InvalidParameter(err)
+	}
+	result := &apistructs.PipelineTaskActionCache{
+		Path:   req.Path,
+		Key:    req.Key,
+		Status: apistructs.ActionCacheStatusMiss,
+	}
+
+	cache, found, err := s.match(getProjectID(p), req.Key, req.RestoreKeys)
+	if err != nil {
+		return nil, apierrors.ErrQueryActionCache.InternalError(err)
+	}
+	if found {
+		result.MatchedKey = cache.CacheKey
+		result.Size = cache.Size
+		result.Status = apistructs.ActionCacheStatusPartialHit
+		if cache.CacheKey == req.Key {
+			result.Status = apistructs.ActionCacheStatusHit
+		}
+		if err := s.dbClient.UpdateActionCacheLastUsedAt(cache.ID, time.Now()); err != nil {
+			logrus.Errorf("[alert] %v", err)
+		}
+	}
+
+	if err := s.recordTaskActionCache(task.ID, result); err != nil {
+		logrus.Errorf("[alert] failed to record action cache result, taskID: %d, err: %v", task.ID, err)
+	}
+	return result, nil
+}
+
+func (s *ActionCacheSvc) match(projectID uint64, key string, restoreKeys []string) (spec.PipelineActionCache, bool, error) {
+	cache, found, err := s.dbClient.GetActionCache(projectID, KeyHash(key))
+	if err != nil || found {
+		return cache, found, err
+	}
+	for _, restoreKey := range restoreKeys {
+		if restoreKey == "" {
+			continue
+		}
+		cache, found, err = s.dbClient.GetLatestActionCacheByKeyPrefix(projectID, restoreKey)
+		if err != nil || found {
+			return cache, found, err
+		}
+	}
+	return spec.PipelineActionCache{}, false, nil
+}
+
+// Download 打开 key 对应的缓存, 调用方负责关闭
+func (s *ActionCacheSvc) Download(p *spec.Pipeline, req apistructs.PipelineActionCacheDownloadRequest) (io.ReadCloser, int64, error) {
+	if err := validateKey(req.Path, req.Key); err != nil {
+		return nil, 0, apierrors.ErrDownloadActionCache.InvalidParameter(err)
+	}
+	keyHash := KeyHash(req.Key)
+	cache, found, err := s.dbClient.GetActionCache(getProjectID(p), keyHash)
+	if err != nil {
+		return nil, 0, apierrors.ErrDownloadActionCache.InternalError(err)
+	}
+	if !found {
+		return nil, 0, apierrors.ErrDownloadActionCache.NotFound()
+	}
+	rc, err := s.storage.OpenFile(s.bucket, s.objectName(cache.ProjectID, keyHash))
+	if err != nil {
+		return nil, 0, apierrors.ErrDownloadActionCache.InternalError(err)
+	}
+	return rc, cache.Size, nil
+}
+
+// Upload 保存 task 执行后产生的缓存, 相同 key 的缓存会被覆盖, 保存后淘汰超出项目配额的缓存
+func (s *ActionCacheSvc) Upload(p *spec.Pipeline, task *spec.PipelineTask, req apistructs.PipelineActionCacheUploadRequest, body io.Reader) (*apistructs.PipelineTaskActionCache, error) {
+	if err := validateKey(req.Path, req.Key); err != nil {
+		return nil, apierrors.ErrUploadActionCache.InvalidParameter(err)
+	}
+
+	tmpFile, err := ioutil.TempFile("", "action-cache")
+	if err != nil {
+		return nil, apierrors.ErrUploadActionCache.InternalError(err)
+	}
+	defer func() {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+	}()
+	// 超出配额的缓存无法保存, 多读一个字节用于判断是否超出配额
+	src := body
+	if s.quota > 0 {
+		src = io.LimitReader(body, s.quota+1)
+	}
+	size, err := io.Copy(tmpFile, src)
+	if err != nil {
+		return nil, apierrors.ErrUploadActionCache.InternalError(err)
+	}
+	if s.quota > 0 && size > s.quota {
+		return nil, apierrors.ErrUploadActionCache.InvalidParameter(
+			fmt.Errorf("cache size exceeds project quota %d bytes", s.quota))
+	}
+	if err := tmpFile.Close(); err != nil {
+		return nil, apierrors.ErrUploadActionCache.InternalError(err)
+	}
+
+	projectID := getProjectID(p)
+	keyHash := KeyHash(req.Key)
+	if _, err := s.storage.UploadFile(s.bucket, s.objectName(projectID, keyHash), tmpFile.Name()); err != nil {
+		return nil, apierrors.ErrUploadActionCache.InternalError(err)
+	}
+	cache, err := s.save(projectID, req.Key, keyHash, size)
+	if err != nil {
+		return nil, apierrors.ErrUploadActionCache.InternalError(err)
+	}
+	s.evict(projectID, cache.ID)
+
+	result := &apistructs.PipelineTaskActionCache{
+		Path:   req.Path,
+		Key:    req.Key,
+		Status: apistructs.ActionCacheStatusMiss,
+	}
+	if queried := task.Inspect.GetActionCache(req.Path); queried != nil {
+		result = queried
+	}
+	result.SavedSize = size
+	if err := s.recordTaskActionCache(task.ID, result); err != nil {
+		logrus.Errorf("[alert] failed to record action cache result, taskID: %d, err: %v", task.ID, err)
+	}
+	return result, nil
+}
+
+func (s *ActionCacheSvc) save(projectID uint64, key, keyHash string, size int64) (*spec.PipelineActionCache, error) {
+	now := time.Now()
+	cache, found, err := s.dbClient.GetActionCache(projectID, keyHash)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		cache = spec.PipelineActionCache{
+			ProjectID:  projectID,
+			CacheKey:   key,
+			KeyHash:    keyHash,
+			Size:       size,
+			LastUsedAt: now,
+		}
+		if err := s.dbClient.CreateActionCache(&cache); err == nil {
+			return &cache, nil
+		}
+		// 并发保存相同 key 时唯一索引冲突, 更新已存在的记录
+		if cache, found, err = s.dbClient.GetActionCache(projectID, keyHash); err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, errors.Errorf("failed to save action cache, projectID [%d], key [%s]", projectID, key)
+		}
+	}
+	cache.Size = size
+	cache.LastUsedAt = now
+	if err := s.dbClient.UpdateActionCache(&cache); err != nil {
+		return nil, err
+	}
+	return &cache, nil
+}
+
+// evict 淘汰项目下最久未使用的缓存, 直到缓存总大小不超过配额, keepID 为刚保存的缓存, 不会被淘汰
+func (s *ActionCacheSvc) evict(projectID uint64, keepID uint64) {
+	if s.quota <= 0 {
+		return
+	}
+	caches, err := s.dbClient.ListActionCachesByProjectID(projectID)
+	if err != nil {
+		logrus.Errorf("[alert] failed to evict action caches, projectID: %d, err: %v", projectID, err)
+		return
+	}
+	for _, cache := range selectEvictions(caches, s.quota, keepID) {
+		if err := s.storage.DeleteFile(s.bucket, s.objectName(cache.ProjectID, cache.KeyHash)); err != nil {
+			logrus.Errorf("[alert] failed to delete action cache object, projectID: %d, key: %s, err: %v",
+				cache.ProjectID, cache.CacheKey, err)
+			continue
+		}
+		if err := s.dbClient.DeleteActionCache(cache.ID); err != nil {
+			logrus.Errorf("[alert] %v", err)
+			continue
+		}
+		logrus.Infof("evicted action cache, projectID: %d, key: %s, size: %d", cache.ProjectID, cache.CacheKey, cache.Size)
+	}
+}
+
+// selectEvictions 返回需要淘汰的缓存, caches 需按最近使用时间升序排列
+func selectEvictions(caches []spec.PipelineActionCache, quota int64, keepID uint64) []spec.PipelineActionCache {
+	var total int64
+	for _, cache := range caches {
+		total += cache.Size
+	}
+	var evictions []spec.PipelineActionCache
+	for _, cache := range caches {
+		if total <= quota {
+			break
+		}
+		if cache.ID == keepID {
+			continue
+		}
+		evictions = append(evictions, cache)
+		total -= cache.Size
+	}
+	return evictions
+}
+
+// recordTaskActionCache 将缓存使用情况记录到 task inspect 中, 重新查询 task 避免覆盖其他更新
+func (s *ActionCacheSvc) recordTaskActionCache(taskID uint64, result *apistructs.PipelineTaskActionCache) error {
+	task, err := s.dbClient.GetPipelineTask(taskID)
+	if err != nil {
+		return err
+	}
+	task.Inspect.SetActionCache(result)
+	return s.dbClient.UpdatePipelineTaskInspect(taskID, task.Inspect)
+}
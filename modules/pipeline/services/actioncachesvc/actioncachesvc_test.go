@@ -0,0 +1,80 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actioncachesvc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/modules/pipeline/spec"
+)
+
+func TestObjectPath(t *testing.T) {
+	keyHash := KeyHash("go-abc")
+	assert.Equal(t, 64, len(keyHash))
+	assert.Equal(t, "12/"+keyHash[0:2]+"/"+keyHash, ObjectPath(12, keyHash))
+
+	s := New(nil, nil, "bucket", "action-caches", 0)
+	assert.Equal(t, "action-caches/0/"+keyHash[0:2]+"/"+keyHash, s.objectName(0, keyHash))
+}
+
+func TestValidateKey(t *testing.T) {
+	assert.NoError(t, validateKey("/root/.m2", "maven-abc"))
+	assert.Error(t, validateKey("", "maven-abc"))
+	assert.Error(t, validateKey("/root/.m2", ""))
+	long := make([]byte, MaxKeyLength+1)
+	for i := range long {
+		long[i] = 'a'
+	}
+	assert.Error(t, validateKey("/root/.m2", string(long)))
+}
+
+func TestSelectEvictions(t *testing.T) {
+	// 按最近使用时间升序
+	caches := []spec.PipelineActionCache{
+		{ID: 1, Size: 30},
+		{ID: 2, Size: 30},
+		{ID: 3, Size: 30},
+		{ID: 4, Size: 30},
+	}
+
+	// 未超出配额
+	assert.Empty(t, selectEvictions(caches, 120, 4))
+
+	// 淘汰最久未使用的
+	evictions := selectEvictions(caches, 100, 4)
+	assert.Equal(t, 1, len(evictions))
+	assert.Equal(t, uint64(1), evictions[0].ID)
+
+	evictions = selectEvictions(caches, 50, 4)
+	assert.Equal(t, []uint64{1, 2, 3}, ids(evictions))
+
+	// 刚保存的缓存即使最久未使用也不会被淘汰
+	evictions = selectEvictions(caches, 60, 1)
+	assert.Equal(t, []uint64{2, 3}, ids(evictions))
+
+	// 只剩刚保存的缓存时停止淘汰
+	evictions = selectEvictions(caches, 10, 2)
+	assert.Equal(t, []uint64{1, 3, 4}, ids(evictions))
+}
+
+func ids(caches []spec.PipelineActionCache) []uint64 {
+	var result []uint64
+	for _, cache := range caches {
+		result = append(result, cache.ID)
+	}
+	return result
+}
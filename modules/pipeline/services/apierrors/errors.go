@@ -55,6 +55,7 @@ var (
 	ErrGetTaskBootstrapInfo  = err("ErrGetPipelineTaskBootstrapInfo", "获取任务启动信息失败")
 	ErrGetPipelineOutputs    = err("ErrGetPipelineOutputs", "获取流水线输出失败")
 	ErrPreCheckPipeline      = err("ErrPreCheckPipeline", "流水线前置校验失败")
+	ErrDryRunPipeline        = err("ErrDryRunPipeline", "流水线预演失败")
 	ErrGetOpenapiOAuth2Token = err("ErrGetOpenapiOAuth2Token", "申请 openapi oauth2 token 失败")
 	ErrQuerySnippetYaml      = err("ErrQuerySnippetYaml", "查询嵌套流水线片段失败")
 
@@ -91,6 +92,10 @@ var (
 	ErrQueryDicehub     = err("ErrQueryDicehub", "查询 Dicehub 失败")
 	ErrReportBuildCache = err("ErrReportBuildCache", "上报构建缓存失败")
 
+	ErrQueryActionCache    = err("ErrQueryActionCache", "查询 Action 缓存失败")
+	ErrDownloadActionCache = err("ErrDownloadActionCache", "下载 Action 缓存失败")
+	ErrUploadActionCache   = err("ErrUploadActionCache", "上传 Action 缓存失败")
+
 	ErrCallback = err("ErrCallback", "回调平台失败")
 
 	ErrDownloadActionAgent = err("ErrDownloadActionAgent", "下载 Action Agent 失败")
@@ -80,7 +80,7 @@ func (s *PipelineSvc) DealPipelineCallbackOfAction(data []byte) (err error) {
 }
 
 func (s *PipelineSvc) appendPipelineTaskInspect(p *spec.Pipeline, task *spec.PipelineTask, cb apistructs.ActionCallback) error {
-	if len(cb.Errors) == 0 && cb.MachineStat == nil {
+	if len(cb.Errors) == 0 && cb.MachineStat == nil && cb.ExitCode == nil {
 		return nil
 	}
 	// TODO action agent should add err start time and end time
@@ -95,6 +95,10 @@ func (s *PipelineSvc) appendPipelineTaskInspect(p *spec.Pipeline, task *spec.Pip
 	if cb.MachineStat != nil {
 		task.Inspect.MachineStat = cb.MachineStat
 	}
+	// exit code
+	if cb.ExitCode != nil {
+		task.Inspect.ExitCode = cb.ExitCode
+	}
 
 	if err := s.dbClient.UpdatePipelineTaskInspect(task.ID, task.Inspect); err != nil {
 		return err
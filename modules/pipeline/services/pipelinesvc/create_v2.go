@@ -139,13 +139,27 @@ func logCompatibleFailed(key, value string, err error) {
 }
 
 func (s *PipelineSvc) makePipelineFromRequestV2(req *apistructs.PipelineCreateRequestV2) (*spec.Pipeline, error) {
+	p, pipelineYml, err := s.parsePipelineFromRequestV2(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.UpdatePipelineCron(p, req.CronStartFrom, req.ConfigManageNamespaces, pipelineYml.Spec().CronCompensator); err != nil {
+		return nil, apierrors.ErrCreatePipeline.InternalError(err)
+	}
+
+	return p, nil
+}
+
+// parsePipelineFromRequestV2 make pipeline from request without any side effect, such as updating pipeline cron.
+func (s *PipelineSvc) parsePipelineFromRequestV2(req *apistructs.PipelineCreateRequestV2) (*spec.Pipeline, *pipelineyml.PipelineYml, error) {
 	p := &spec.Pipeline{}
 
 	// 解析 pipeline yml 文件，生成最终 pipeline yml 文件
 	// 只解析最外层，获取 storage 和 cron 信息
 	pipelineYml, err := pipelineyml.New([]byte(req.PipelineYml), pipelineyml.WithEnvs(req.Envs))
 	if err != nil {
-		return nil, apierrors.ErrParsePipelineYml.InternalError(err)
+		return nil, nil, apierrors.ErrParsePipelineYml.InternalError(err)
 	}
 
 	p.PipelineYml = req.PipelineYml
@@ -202,7 +216,7 @@ func (s *PipelineSvc) makePipelineFromRequestV2(req *apistructs.PipelineCreateRe
 
 	version, err := pipelineyml.GetVersion([]byte(p.PipelineYml))
 	if err != nil {
-		return nil, apierrors.ErrParsePipelineYml.InvalidParameter(errors.Errorf("version (%v)", err))
+		return nil, nil, apierrors.ErrParsePipelineYml.InvalidParameter(errors.Errorf("version (%v)", err))
 	}
 	p.Extra.Version = version
 
@@ -247,7 +261,7 @@ func (s *PipelineSvc) makePipelineFromRequestV2(req *apistructs.PipelineCreateRe
 	if v, ok := labels[apistructs.LabelPipelineCronTriggerTime]; ok {
 		nano, err := strconv.ParseInt(v, 10, 64)
 		if err != nil {
-			return nil, apierrors.ErrCreatePipeline.InvalidParameter(err)
+			return nil, nil, apierrors.ErrCreatePipeline.InvalidParameter(err)
 		}
 		cronTriggerTime := time.Unix(0, nano)
 		p.Extra.CronTriggerTime = &cronTriggerTime
@@ -261,11 +275,11 @@ func (s *PipelineSvc) makePipelineFromRequestV2(req *apistructs.PipelineCreateRe
 	if v, ok := labels[apistructs.LabelPipelineCronID]; ok {
 		cronID, err := strconv.ParseUint(v, 10, 64)
 		if err != nil {
-			return nil, apierrors.ErrCreatePipeline.InvalidParameter(err)
+			return nil, nil, apierrors.ErrCreatePipeline.InvalidParameter(err)
 		}
 		pc, err := s.dbClient.GetPipelineCron(cronID)
 		if err != nil {
-			return nil, apierrors.ErrGetPipelineCron.InvalidParameter(err)
+			return nil, nil, apierrors.ErrGetPipelineCron.InvalidParameter(err)
 		}
 		p.CronID = &pc.ID
 		p.Extra.CronExpr = pc.CronExpr
@@ -285,10 +299,6 @@ func (s *PipelineSvc) makePipelineFromRequestV2(req *apistructs.PipelineCreateRe
 	// gc
 	p.Extra.GC = req.GC
 
-	if err := s.UpdatePipelineCron(p, req.CronStartFrom, req.ConfigManageNamespaces, pipelineYml.Spec().CronCompensator); err != nil {
-		return nil, apierrors.ErrCreatePipeline.InternalError(err)
-	}
-
 	// defined outputs
 	for _, output := range pipelineYml.Spec().Outputs {
 		p.Extra.DefinedOutputs = append(p.Extra.DefinedOutputs,
@@ -316,7 +326,7 @@ func (s *PipelineSvc) makePipelineFromRequestV2(req *apistructs.PipelineCreateRe
 		}
 	}
 
-	return p, nil
+	return p, pipelineYml, nil
 }
 
 // 非定时触发的，如果有定时配置，需要插入或更新 pipeline_crons enable 配置
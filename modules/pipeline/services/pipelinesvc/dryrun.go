@@ -0,0 +1,263 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinesvc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/conf"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/pvolumes"
+	"github.com/erda-project/erda/modules/pipeline/precheck"
+	"github.com/erda-project/erda/modules/pipeline/precheck/prechecktype"
+	"github.com/erda-project/erda/modules/pipeline/services/apierrors"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+	"github.com/erda-project/erda/pkg/expression"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+)
+
+// dryRunMaxSnippetDepth avoid infinite expanding when snippets reference each other
+const dryRunMaxSnippetDepth = 5
+
+// DryRun return the fully materialized plan of pipeline, without creating any records or scheduling any tasks.
+func (s *PipelineSvc) DryRun(req *apistructs.PipelineDryRunRequest) (*apistructs.PipelineDryRunResult, error) {
+	if err := s.validateCreateRequest(req); err != nil {
+		return nil, err
+	}
+	setDefault(req)
+
+	p, _, err := s.parsePipelineFromRequestV2(req)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterInfo, err := s.retryQueryClusterInfo(p.ClusterName, p.ID)
+	if err != nil {
+		return nil, apierrors.ErrGetCluster.InternalError(err)
+	}
+	mountPoint := clusterInfo.Get(apistructs.DICE_STORAGE_MOUNTPOINT)
+
+	return s.dryRunPipeline(p, req.RunParams, mountPoint, 0)
+}
+
+func (s *PipelineSvc) dryRunPipeline(p *spec.Pipeline, runParams []apistructs.PipelineRunParam, mountPoint string, depth int) (*apistructs.PipelineDryRunResult, error) {
+	var result apistructs.PipelineDryRunResult
+
+	// run params, default value used if not passed
+	realRunParams, err := getRealRunParams(runParams, p.PipelineYml)
+	if err != nil {
+		return nil, err
+	}
+	p.Snapshot.RunPipelineParams = realRunParams.ToPipelineRunParamsWithValue()
+	result.RunParams = p.Snapshot.RunPipelineParams
+
+	// secrets are rendered as ((key)), values never appear in plan
+	secrets, _, holdOnKeys, _, err := s.FetchSecrets(p)
+	if err != nil {
+		return nil, apierrors.ErrDryRunPipeline.InternalError(err)
+	}
+	platformSecrets, err := s.FetchPlatformSecrets(p, holdOnKeys)
+	if err != nil {
+		return nil, apierrors.ErrDryRunPipeline.InternalError(err)
+	}
+	maskedSecrets := make(map[string]string)
+	for _, name := range pipelineyml.ListSecretNames([]byte(p.PipelineYml)) {
+		_, found := secrets[name]
+		if _, ok := platformSecrets[name]; ok {
+			found = true
+		}
+		result.Secrets = append(result.Secrets, apistructs.PipelineDryRunSecret{Name: name, Found: found})
+		maskedSecrets[name] = "((" + name + "))"
+	}
+
+	// all actions can be referenced, outputs can only be known at runtime
+	refs, aliases, err := makeDryRunRefs(p)
+	if err != nil {
+		return nil, apierrors.ErrParsePipelineYml.InvalidParameter(err)
+	}
+	pipelineYml, err := pipelineyml.New(
+		[]byte(p.PipelineYml),
+		pipelineyml.WithEnvs(p.Snapshot.Envs),
+		pipelineyml.WithSecrets(maskedSecrets),
+		pipelineyml.WithAliasesToCheckRefOp(p.Labels, aliases...),
+		pipelineyml.WithRefs(refs),
+		pipelineyml.WithAllowMissingCustomScriptOutputs(true),
+		pipelineyml.WithActionTypeMapping(conf.ActionTypeMapping()),
+		pipelineyml.WithFlatParams(true),
+		pipelineyml.WithRunParams(p.Snapshot.RunPipelineParams),
+		pipelineyml.WithTriggerLabels(p.Labels),
+	)
+	if err != nil {
+		return nil, apierrors.ErrParsePipelineYml.InvalidParameter(err)
+	}
+	materializedYml, err := pipelineyml.GenerateYml(pipelineYml.Spec())
+	if err != nil {
+		return nil, apierrors.ErrDryRunPipeline.InternalError(err)
+	}
+	result.PipelineYml = string(materializedYml)
+	result.Warns = pipelineYml.Warns()
+
+	// stages and tasks only in memory
+	var stages []spec.PipelineStage
+	for si := range pipelineYml.Spec().Stages {
+		stages = append(stages, spec.PipelineStage{
+			PipelineID:  p.ID,
+			Status:      apistructs.PipelineStatusAnalyzed,
+			CostTimeSec: -1,
+			Extra:       spec.PipelineStageExtra{StageOrder: si},
+		})
+	}
+	tasks, err := s.MergePipelineYmlTasks(pipelineYml, nil, p, stages, nil)
+	if err != nil {
+		return nil, err
+	}
+	var taskStageIndexes []int
+	pipelineYml.Spec().LoopStagesActions(func(stageIndex int, action *pipelineyml.Action) {
+		taskStageIndexes = append(taskStageIndexes, stageIndex)
+	})
+
+	result.Stages = make([][]*apistructs.PipelineDryRunTask, len(stages))
+	for i := range tasks {
+		task := &tasks[i]
+		dryRunTask := makeDryRunTask(p, task, mountPoint)
+		if task.IsSnippet && task.Status != apistructs.PipelineStatusDisabled {
+			if depth >= dryRunMaxSnippetDepth {
+				result.Warns = append(result.Warns, fmt.Sprintf("snippet %s is not expanded, exceed max depth %d", task.Name, dryRunMaxSnippetDepth))
+			} else {
+				dryRunTask.Snippet, err = s.dryRunSnippetPipeline(p, task, mountPoint, depth+1)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		stageIndex := taskStageIndexes[i]
+		result.Stages[stageIndex] = append(result.Stages[stageIndex], dryRunTask)
+	}
+
+	// precheck, show message is returned instead of saved
+	itemsForCheck, _, err := s.makePreCheckItems(p, tasks, p.GetUserID())
+	if err != nil {
+		return nil, err
+	}
+	_, showMessage := precheck.PreCheck(prechecktype.InitContext(), []byte(p.PipelineYml), itemsForCheck)
+	result.PreCheck = &showMessage
+
+	return &result, nil
+}
+
+// dryRunSnippetPipeline expand snippet task same as reconciler, but without creating snippet pipeline
+func (s *PipelineSvc) dryRunSnippetPipeline(p *spec.Pipeline, snippetTask *spec.PipelineTask, mountPoint string, depth int) (*apistructs.PipelineDryRunResult, error) {
+	snippetConfig := snippetTask.Extra.Action.SnippetConfig
+	if snippetConfig == nil {
+		return nil, apierrors.ErrDryRunPipeline.InvalidParameter(fmt.Errorf("snippet task %s missing snippet_config", snippetTask.Name))
+	}
+	yamlContent, err := s.queryPipelineYAMLBySnippetConfig(&apistructs.SnippetConfig{
+		Source: snippetConfig.Source,
+		Name:   snippetConfig.Name,
+		Labels: snippetConfig.Labels,
+	})
+	if err != nil {
+		return nil, apierrors.ErrQuerySnippetYaml.InternalError(err)
+	}
+
+	snippetPipelineCreateReq := makeSnippetPipelineCreateRequest(p, snippetTask, yamlContent)
+	if err := s.validateCreateRequest(&snippetPipelineCreateReq); err != nil {
+		return nil, apierrors.ErrDryRunPipeline.InvalidParameter(err)
+	}
+	snippetP, _, err := s.parsePipelineFromRequestV2(&snippetPipelineCreateReq)
+	if err != nil {
+		return nil, err
+	}
+	snippetP.IsSnippet = true
+	return s.dryRunPipeline(snippetP, snippetPipelineCreateReq.RunParams, mountPoint, depth)
+}
+
+// makeDryRunRefs every namespace can be referenced as workdir of action
+func makeDryRunRefs(p *spec.Pipeline) (pipelineyml.Refs, []pipelineyml.ActionAlias, error) {
+	pipelineYml, err := pipelineyml.New([]byte(p.PipelineYml), pipelineyml.WithEnvs(p.Snapshot.Envs))
+	if err != nil {
+		return nil, nil, err
+	}
+	refs := pipelineyml.Refs{}
+	var aliases []pipelineyml.ActionAlias
+	pipelineYml.Spec().LoopStagesActions(func(stage int, action *pipelineyml.Action) {
+		aliases = append(aliases, action.Alias)
+		for _, namespace := range action.Namespaces {
+			refs[namespace] = pvolumes.MakeTaskContainerWorkdir(namespace)
+		}
+	})
+	return refs, aliases, nil
+}
+
+func makeDryRunTask(p *spec.Pipeline, task *spec.PipelineTask, mountPoint string) *apistructs.PipelineDryRunTask {
+	action := task.Extra.Action
+	dryRunTask := &apistructs.PipelineDryRunTask{
+		Name:       task.Name,
+		Type:       task.Type,
+		Version:    action.Version,
+		Image:      action.Image,
+		Status:     task.Status,
+		Needs:      task.Extra.RunAfter,
+		Params:     action.Params,
+		Commands:   action.Commands,
+		TimeoutSec: int64(task.Extra.Timeout / time.Second),
+		Loop:       action.Loop,
+		Retry:      action.Retry,
+		If:         action.If,
+	}
+	if task.Extra.Timeout < 0 {
+		dryRunTask.TimeoutSec = -1
+	}
+	for _, cache := range action.Caches {
+		// 远程缓存的 key 在 action agent 中渲染
+		if pvolumes.IsRemoteCache(cache) {
+			dryRunTask.Caches = append(dryRunTask.Caches, apistructs.ActionCache{Key: cache.Key, Path: cache.Path, RestoreKeys: cache.RestoreKeys})
+			continue
+		}
+		key, _ := pvolumes.MakeTaskCacheKey(p.GetLabel(apistructs.LabelProjectID), p.GetLabel(apistructs.LabelAppID), cache, mountPoint)
+		dryRunTask.Caches = append(dryRunTask.Caches, apistructs.ActionCache{Key: key, Path: cache.Path})
+	}
+	if len(action.Services) > 0 {
+		dryRunTask.Services = apistructs.GetPipelineTaskServiceNames(action.Services)
+	}
+	dryRunTask.Condition, dryRunTask.ConditionMsg = evalDryRunCondition(action.If)
+	return dryRunTask
+}
+
+// evalDryRunCondition evaluate `if` of task same as prepare,
+// condition depends on outputs or secrets can only be evaluated at runtime.
+func evalDryRunCondition(condition string) (apistructs.PipelineDryRunCondition, string) {
+	if condition == "" {
+		return apistructs.PipelineDryRunConditionRun, ""
+	}
+	inner := expression.ReplacePlaceholder(strings.TrimSpace(condition))
+	if strings.Contains(inner, expression.Outputs+".") || strings.Contains(inner, expression.OldLeftPlaceholder) {
+		return apistructs.PipelineDryRunConditionUnknown, "condition depends on outputs of other tasks, can only be evaluated at runtime"
+	}
+	if strings.Contains(inner, "((") {
+		return apistructs.PipelineDryRunConditionUnknown, "condition depends on secrets, can only be evaluated at runtime"
+	}
+
+	sign := expression.Reconcile(condition)
+	if sign.Err != nil {
+		return apistructs.PipelineDryRunConditionInvalid, sign.Err.Error()
+	}
+	if sign.Sign == expression.TaskJumpOver {
+		return apistructs.PipelineDryRunConditionSkip, sign.Msg
+	}
+	return apistructs.PipelineDryRunConditionRun, ""
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinesvc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+)
+
+func TestEvalDryRunCondition(t *testing.T) {
+	tests := []struct {
+		condition string
+		want      apistructs.PipelineDryRunCondition
+	}{
+		{condition: "", want: apistructs.PipelineDryRunConditionRun},
+		{condition: "${{ 1 == 1 }}", want: apistructs.PipelineDryRunConditionRun},
+		{condition: "${{ 'master' == 'develop' }}", want: apistructs.PipelineDryRunConditionSkip},
+		{condition: "${{ outputs.build.status == 'ok' }}", want: apistructs.PipelineDryRunConditionUnknown},
+		{condition: "${{ '((branch))' == 'master' }}", want: apistructs.PipelineDryRunConditionUnknown},
+		{condition: "${{ 1 == }}", want: apistructs.PipelineDryRunConditionInvalid},
+	}
+	for _, tt := range tests {
+		got, _ := evalDryRunCondition(tt.condition)
+		assert.Equal(t, tt.want, got, tt.condition)
+	}
+}
+
+func TestMakeDryRunTask(t *testing.T) {
+	p := &spec.Pipeline{PipelineBase: spec.PipelineBase{ID: 1}}
+	p.Labels = map[string]string{apistructs.LabelProjectID: "1", apistructs.LabelAppID: "2"}
+	task := &spec.PipelineTask{
+		Name:   "build",
+		Type:   "custom-script",
+		Status: apistructs.PipelineStatusAnalyzed,
+		Extra: spec.PipelineTaskExtra{
+			Timeout:  time.Minute,
+			RunAfter: []string{"checkout"},
+			Action: pipelineyml.Action{
+				Alias:    "build",
+				Commands: []string{"make"},
+				Caches:   []pipelineyml.ActionCache{{Path: "/root/.m2"}},
+				If:       "${{ 'a' == 'b' }}",
+				Services: map[string]*apistructs.PipelineTaskService{
+					"redis": {Image: "redis"},
+					"mysql": {Image: "mysql"},
+				},
+			},
+		},
+	}
+	dryRunTask := makeDryRunTask(p, task, "/netdata")
+	assert.Equal(t, "build", dryRunTask.Name)
+	assert.Equal(t, []string{"checkout"}, dryRunTask.Needs)
+	assert.Equal(t, int64(60), dryRunTask.TimeoutSec)
+	assert.Equal(t, []string{"mysql", "redis"}, dryRunTask.Services)
+	assert.Equal(t, apistructs.PipelineDryRunConditionSkip, dryRunTask.Condition)
+	assert.Equal(t, 1, len(dryRunTask.Caches))
+	assert.Contains(t, dryRunTask.Caches[0].Key, "/netdata/actions/caches/1/2/")
+	assert.Equal(t, "/root/.m2", dryRunTask.Caches[0].Path)
+
+	task.Extra.Timeout = pipelineyml.TimeoutDuration4Forever
+	assert.Equal(t, int64(-1), makeDryRunTask(p, task, "/netdata").TimeoutSec)
+}
@@ -39,6 +39,50 @@ func (s *PipelineSvc) PreCheck(pipelineYml *pipelineyml.PipelineYml, p *spec.Pip
 		return apierrors.ErrPreCheckPipeline.InternalError(err)
 	}
 
+	itemsForCheck, actionSpecs, err := s.makePreCheckItems(p, tasks, userID)
+	if err != nil {
+		return err
+	}
+
+	if p.Extra.StorageConfig.EnableShareVolume() {
+		for _, task := range tasks {
+			typeVersion := task.Extra.Action.GetActionTypeVersion()
+			value, exist := actionSpecs[typeVersion].Labels["new_workspace"]
+			if exist && value == "true" {
+				// action带有new_workspace标签,使用独立目录
+				p.Extra.TaskWorkspaces = append(p.Extra.TaskWorkspaces, task.Name)
+			}
+		}
+		err = s.dbClient.UpdatePipelineExtraByPipelineID(p.ID, &p.PipelineExtra)
+		if err != nil {
+			return apierrors.ErrPreCheckPipeline.InternalError(err)
+		}
+	}
+
+	precheckCtx := prechecktype.InitContext()
+	abort, showMessage := precheck.PreCheck(precheckCtx, []byte(p.PipelineYml), itemsForCheck)
+	if len(showMessage.Stacks) > 0 {
+		if err := s.dbClient.UpdatePipelineShowMessage(p.ID, showMessage); err != nil {
+			return apierrors.ErrPreCheckPipeline.InternalError(err)
+		}
+	}
+	if abort {
+		return apierrors.ErrPreCheckPipeline.InvalidParameter("precheck failed")
+	}
+
+	analyzedCrossCluster, ok := prechecktype.GetContextResult(precheckCtx, prechecktype.CtxResultKeyCrossCluster).(bool)
+	if ok {
+		p.Snapshot.AnalyzedCrossCluster = &analyzedCrossCluster
+		if err := s.dbClient.StoreAnalyzedCrossCluster(p.ID, analyzedCrossCluster); err != nil {
+			return apierrors.ErrPreCheckPipeline.InternalError(err)
+		}
+	}
+
+	return nil
+}
+
+// makePreCheckItems query dice.yml, action specs and secrets for precheck, without any side effect.
+func (s *PipelineSvc) makePreCheckItems(p *spec.Pipeline, tasks []spec.PipelineTask, userID string) (prechecktype.ItemsForCheck, map[string]*apistructs.ActionSpec, error) {
 	// ItemsForCheck
 	itemsForCheck := prechecktype.ItemsForCheck{
 		PipelineYml:               p.PipelineYml,
@@ -60,9 +104,9 @@ func (s *PipelineSvc) PreCheck(pipelineYml *pipelineyml.PipelineYml, p *spec.Pip
 			logrus.Error(err)
 		}
 	}
-	err = setItemForCheckRealDiceYml(p, &itemsForCheck, userID)
+	err := setItemForCheckRealDiceYml(p, &itemsForCheck, userID)
 	if err != nil {
-		return err
+		return prechecktype.ItemsForCheck{}, nil, err
 	}
 
 	// 从 extension marketplace 获取 action
@@ -81,7 +125,7 @@ func (s *PipelineSvc) PreCheck(pipelineYml *pipelineyml.PipelineYml, p *spec.Pip
 	}
 	_, actionSpecs, err := s.extMarketSvc.SearchActions(extSearchReq)
 	if err != nil {
-		return apierrors.ErrPreCheckPipeline.InternalError(err)
+		return prechecktype.ItemsForCheck{}, nil, apierrors.ErrPreCheckPipeline.InternalError(err)
 	}
 	for typeVersion, actionSpec := range actionSpecs {
 		if actionSpec != nil {
@@ -89,55 +133,21 @@ func (s *PipelineSvc) PreCheck(pipelineYml *pipelineyml.PipelineYml, p *spec.Pip
 		}
 	}
 
-	if p.Extra.StorageConfig.EnableShareVolume() {
-		for _, task := range tasks {
-			typeVersion := task.Extra.Action.GetActionTypeVersion()
-			value, exist := actionSpecs[typeVersion].Labels["new_workspace"]
-			if exist && value == "true" {
-				// action带有new_workspace标签,使用独立目录
-				p.Extra.TaskWorkspaces = append(p.Extra.TaskWorkspaces, task.Name)
-			}
-		}
-		err = s.dbClient.UpdatePipelineExtraByPipelineID(p.ID, &p.PipelineExtra)
-		if err != nil {
-			return apierrors.ErrPreCheckPipeline.InternalError(err)
-		}
-	}
-
 	// secrets
 	secrets, _, holdOnKeys, _, err := s.FetchSecrets(p)
 	if err != nil {
-		return apierrors.ErrPreCheckPipeline.InternalError(err)
+		return prechecktype.ItemsForCheck{}, nil, apierrors.ErrPreCheckPipeline.InternalError(err)
 	}
 	platformSecrets, err := s.FetchPlatformSecrets(p, holdOnKeys)
 	if err != nil {
-		return apierrors.ErrPreCheckPipeline.InternalError(err)
+		return prechecktype.ItemsForCheck{}, nil, apierrors.ErrPreCheckPipeline.InternalError(err)
 	}
 	itemsForCheck.Secrets = platformSecrets
 	for k, v := range secrets {
 		itemsForCheck.Secrets[k] = v
 	}
 
-	precheckCtx := prechecktype.InitContext()
-	abort, showMessage := precheck.PreCheck(precheckCtx, []byte(p.PipelineYml), itemsForCheck)
-	if len(showMessage.Stacks) > 0 {
-		if err := s.dbClient.UpdatePipelineShowMessage(p.ID, showMessage); err != nil {
-			return apierrors.ErrPreCheckPipeline.InternalError(err)
-		}
-	}
-	if abort {
-		return apierrors.ErrPreCheckPipeline.InvalidParameter("precheck failed")
-	}
-
-	analyzedCrossCluster, ok := prechecktype.GetContextResult(precheckCtx, prechecktype.CtxResultKeyCrossCluster).(bool)
-	if ok {
-		p.Snapshot.AnalyzedCrossCluster = &analyzedCrossCluster
-		if err := s.dbClient.StoreAnalyzedCrossCluster(p.ID, analyzedCrossCluster); err != nil {
-			return apierrors.ErrPreCheckPipeline.InternalError(err)
-		}
-	}
-
-	return nil
+	return itemsForCheck, actionSpecs, nil
 }
 
 // 用户可能在 release 中设置了 dice_development_yml,dice_test_yml,dice_staging_yml,dice_production_yml 等不同环境的 dice.yml, 但是对应的校验也要转化
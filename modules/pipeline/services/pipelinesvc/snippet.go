@@ -277,6 +277,23 @@ func (s *PipelineSvc) batchQueryPipelineYAMLBySnippetConfigs(snippetConfigs []ap
 
 // createSnippetPipeline4Create 为 snippetTask 创建流水线对象
 func (s *PipelineSvc) MakeSnippetPipeline4Create(p *spec.Pipeline, snippetTask *spec.PipelineTask, yamlContent string) (*spec.Pipeline, error) {
+	snippetPipelineCreateReq := makeSnippetPipelineCreateRequest(p, snippetTask, yamlContent)
+	if err := s.validateCreateRequest(&snippetPipelineCreateReq); err != nil {
+		return nil, apierrors.ErrCreateSnippetPipeline.InternalError(err)
+	}
+	snippetP, err := s.makePipelineFromRequestV2(&snippetPipelineCreateReq)
+	if err != nil {
+		return nil, err
+	}
+	snippetP.IsSnippet = true
+	snippetP.ParentPipelineID = &p.ID
+	snippetP.ParentTaskID = &snippetTask.ID
+	snippetP.Extra.SnippetChain = append(p.Extra.SnippetChain, p.ID)
+	return snippetP, nil
+}
+
+// makeSnippetPipelineCreateRequest transfer snippetTask to pipeline create request
+func makeSnippetPipelineCreateRequest(p *spec.Pipeline, snippetTask *spec.PipelineTask, yamlContent string) apistructs.PipelineCreateRequestV2 {
 	snippetConfig := snippetTask.Extra.Action.SnippetConfig
 	// runParams
 	var runParams []apistructs.PipelineRunParam
@@ -292,7 +309,7 @@ func (s *PipelineSvc) MakeSnippetPipeline4Create(p *spec.Pipeline, snippetTask *
 	for k, v := range snippetConfig.Labels {
 		labels[k] = v
 	}
-	snippetPipelineCreateReq := apistructs.PipelineCreateRequestV2{
+	return apistructs.PipelineCreateRequestV2{
 		PipelineYml:            yamlContent,
 		ClusterName:            snippetTask.Extra.ClusterName,
 		PipelineYmlName:        snippetConfig.Name,
@@ -305,16 +322,4 @@ func (s *PipelineSvc) MakeSnippetPipeline4Create(p *spec.Pipeline, snippetTask *
 		RunParams:              runParams,
 		IdentityInfo:           p.GenIdentityInfo(),
 	}
-	if err := s.validateCreateRequest(&snippetPipelineCreateReq); err != nil {
-		return nil, apierrors.ErrCreateSnippetPipeline.InternalError(err)
-	}
-	snippetP, err := s.makePipelineFromRequestV2(&snippetPipelineCreateReq)
-	if err != nil {
-		return nil, err
-	}
-	snippetP.IsSnippet = true
-	snippetP.ParentPipelineID = &p.ID
-	snippetP.ParentTaskID = &snippetTask.ID
-	snippetP.Extra.SnippetChain = append(p.Extra.SnippetChain, p.ID)
-	return snippetP, nil
 }
@@ -0,0 +1,36 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"time"
+)
+
+// PipelineActionCache represents `pipeline_action_caches` table.
+// 远程缓存按项目隔离, 相同项目下 key 唯一, 缓存内容 (.tar) 保存在对象存储中.
+type PipelineActionCache struct {
+	ID         uint64 `xorm:"pk autoincr"`
+	ProjectID  uint64
+	CacheKey   string
+	KeyHash    string // sha256(CacheKey), 用于唯一索引和对象存储路径
+	Size       int64
+	LastUsedAt time.Time
+	CreatedAt  time.Time `xorm:"created"`
+	UpdatedAt  time.Time `xorm:"updated"`
+}
+
+func (*PipelineActionCache) TableName() string {
+	return "pipeline_action_caches"
+}
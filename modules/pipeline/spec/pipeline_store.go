@@ -23,6 +23,7 @@ var (
 	StoreTypeDiceVolumeLocal StoreType = "dice-local-volume"
 	StoreTypeDiceVolumeFake  StoreType = "dice-fake-volume"
 	StoreTypeDiceCacheNFS    StoreType = "dice-cache-nfs-volume"
+	StoreTypeDiceCacheRemote StoreType = "dice-cache-remote"
 )
 
 const (
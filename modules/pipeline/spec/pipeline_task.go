@@ -104,6 +104,8 @@ type PipelineTaskExtra struct {
 
 	LoopOptions *apistructs.PipelineTaskLoopOptions `json:"loopOptions,omitempty"` // 开始执行后保证不为空
 
+	RetryOptions *apistructs.PipelineTaskRetryOptions `json:"retryOptions,omitempty"` // 声明 retry 时开始执行后不为空
+
 	AppliedResources apistructs.PipelineAppliedResources `json:"appliedResources,omitempty"`
 
 	EncryptSecretKeys []string `json:"encryptSecretKeys"` // the encrypt envs' key list
@@ -215,6 +217,9 @@ func (pt *PipelineTask) Convert2DTO() *apistructs.PipelineTaskDTO {
 			UUID:           pt.Extra.UUID,
 			AllowFailure:   pt.Extra.AllowFailure,
 			TaskContainers: pt.Extra.TaskContainers,
+
+			MatrixSource:      pt.Extra.Action.MatrixSource.String(),
+			MatrixCombination: pt.Extra.Action.MatrixCombination,
 		},
 		Labels:       pt.Extra.Action.Labels,
 		CostTimeSec:  pt.CostTimeSec,
@@ -228,12 +233,19 @@ func (pt *PipelineTask) Convert2DTO() *apistructs.PipelineTaskDTO {
 		SnippetPipelineID:     pt.SnippetPipelineID,
 		SnippetPipelineDetail: pt.SnippetPipelineDetail,
 	}
+	if pt.Extra.RetryOptions != nil {
+		// uuid is used to query logs, return the log id of current attempt
+		task.Extra.UUID = pt.GetLogID()
+		task.Extra.Attempt = pt.Extra.RetryOptions.Attempt
+		task.Extra.Attempts = pt.Extra.RetryOptions.Attempts
+	}
 	task.Result.Metadata = pt.GetMetadata()
 	pt.Inspect.ConvertErrors()
 	task.Result.MachineStat = pt.Inspect.MachineStat
 	task.Result.Inspect = pt.Inspect.Inspect
 	task.Result.Events = pt.Inspect.Events
 	task.Result.Errors = pt.Inspect.Errors
+	task.Result.ActionCaches = pt.Inspect.ActionCaches
 	// handle metadata
 	for _, field := range task.Result.Metadata {
 		field.Level = field.GetLevel()
@@ -285,10 +297,30 @@ type ExecutorDoneChanData struct {
 }
 
 func (pt *PipelineTask) GenerateExecutorDoneChanDataVersion() string {
-	if pt.Extra.LoopOptions == nil {
-		return fmt.Sprintf("%s-%d", CtxExecutorChDataVersionPrefix, pt.ID)
+	version := fmt.Sprintf("%s-%d", CtxExecutorChDataVersionPrefix, pt.ID)
+	if pt.Extra.LoopOptions != nil {
+		version = fmt.Sprintf("%s-loop-%d", version, pt.Extra.LoopOptions.LoopedTimes)
+	}
+	if attempt := pt.GetAttempt(); attempt > apistructs.TaskAttemptBegin {
+		version = fmt.Sprintf("%s-retry-%d", version, attempt)
+	}
+	return version
+}
+
+// GetAttempt return current attempt of task, begin from 1.
+func (pt *PipelineTask) GetAttempt() uint64 {
+	if pt.Extra.RetryOptions == nil || pt.Extra.RetryOptions.Attempt < apistructs.TaskAttemptBegin {
+		return apistructs.TaskAttemptBegin
+	}
+	return pt.Extra.RetryOptions.Attempt
+}
+
+// GetLogID return log id of current attempt, the first attempt use task uuid directly.
+func (pt *PipelineTask) GetLogID() string {
+	if attempt := pt.GetAttempt(); attempt > apistructs.TaskAttemptBegin {
+		return fmt.Sprintf("%s-retry-%d", pt.Extra.UUID, attempt)
 	}
-	return fmt.Sprintf("%s-%d-loop-%d", CtxExecutorChDataVersionPrefix, pt.ID, pt.Extra.LoopOptions.LoopedTimes)
+	return pt.Extra.UUID
 }
 
 func (pt *PipelineTask) CheckExecutorDoneChanDataVersion(actualVersion string) error {
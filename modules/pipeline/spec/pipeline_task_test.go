@@ -191,3 +191,20 @@ func TestCheckExecutorVersion(t *testing.T) {
 	assert.Equal(t, loopTask.CheckExecutorDoneChanDataVersion(actualVersion), nil)
 	assert.Equal(t, loopTask.CheckExecutorDoneChanDataVersion(errVersion).Error(), "executor data expected version: executor-done-chan-data-version-1-loop-100, actual version: executor-done-chan-data-version-1-loop-99")
 }
+
+func TestGenerateExecutorVersionWithRetry(t *testing.T) {
+	retryTask := PipelineTask{ID: 1, Extra: PipelineTaskExtra{
+		UUID: "pipeline-task-1",
+		RetryOptions: &apistructs.PipelineTaskRetryOptions{
+			Attempt: 1,
+		},
+	}}
+	assert.Equal(t, retryTask.GenerateExecutorDoneChanDataVersion(), "executor-done-chan-data-version-1")
+	assert.Equal(t, retryTask.GetLogID(), "pipeline-task-1")
+
+	retryTask.Extra.RetryOptions.Attempt = 3
+	retryTask.Extra.LoopOptions = &apistructs.PipelineTaskLoopOptions{LoopedTimes: 2}
+	assert.Equal(t, retryTask.GenerateExecutorDoneChanDataVersion(), "executor-done-chan-data-version-1-loop-2-retry-3")
+	assert.Equal(t, retryTask.GetAttempt(), uint64(3))
+	assert.Equal(t, retryTask.GetLogID(), "pipeline-task-1-retry-3")
+}
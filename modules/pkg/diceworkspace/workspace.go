@@ -40,6 +40,7 @@ func GetValidBranchByGitReference(ref string, branchRules []*apistructs.BranchRu
 					IsTriggerPipeline: branchRule.IsTriggerPipeline,
 					Workspace:         branchRule.Workspace,
 					ArtifactWorkspace: branchRule.ArtifactWorkspace,
+					RequiredApprovals: branchRule.RequiredApprovals,
 				}
 			}
 		}
@@ -50,7 +50,17 @@ func (k *Kubernetes) createDeployment(ctx context.Context, service *apistructs.S
 	if err != nil {
 		return errors.Errorf("failed to generate deployment struct, name: %s, (%v)", service.Name, err)
 	}
+	if deployment.Spec.Replicas != nil {
+		replicas := autoscaledReplicas(service, *deployment.Spec.Replicas)
+		deployment.Spec.Replicas = &replicas
+	}
+	if err = k.doCreateDeployment(ctx, deployment, service); err != nil {
+		return err
+	}
+	return k.updateHPA(ctx, deployment, service)
+}
 
+func (k *Kubernetes) doCreateDeployment(ctx context.Context, deployment *appsv1.Deployment, service *apistructs.Service) error {
 	_, projectID, workspace, runtimeID := extractContainerEnvs(deployment.Spec.Template.Spec.Containers)
 	cpu, mem := getRequestsResources(deployment.Spec.Template.Spec.Containers)
 	if deployment.Spec.Replicas != nil {
@@ -89,6 +99,12 @@ func (k *Kubernetes) getDeploymentStatusFromMap(service *apistructs.Service, dep
 	// http://localhost:8080/apis/extensions/v1beta1/namespaces/default/deployments/myk8stest6
 	// http://localhost:8080/apis/extensions/v1beta1/namespaces/default/deployments/myk8stest6/status
 	deploymentName := getDeployName(service)
+	// during canary or blue-green deployment, the status of the new version is reported
+	if _, phase := rolloutOf(service); phase == apistructs.RolloutPhaseProgressing {
+		if _, ok := deployments[getPreviewDeployName(service)]; ok {
+			deploymentName = getPreviewDeployName(service)
+		}
+	}
 
 	if deployment, ok := deployments[deploymentName]; ok {
 
@@ -163,6 +179,25 @@ func (k *Kubernetes) putDeployment(ctx context.Context, deployment *appsv1.Deplo
 	return nil
 }
 
+// updateDeployment updates the deployment of the service to the desired one,
+// keeps the replicas scaled by hpa and updates the hpa with the deployment
+func (k *Kubernetes) updateDeployment(ctx context.Context, service *apistructs.Service, sg *apistructs.ServiceGroup) error {
+	desiredDeployment, err := k.newDeployment(service, sg)
+	if err != nil {
+		return err
+	}
+	k.keepAutoscaledReplicas(ctx, desiredDeployment, service)
+	if err = k.putDeployment(ctx, desiredDeployment, service); err != nil {
+		logrus.Debugf("failed to update deployment, name: %s, (%v)", service.Name, err)
+		return err
+	}
+	if err = k.updateHPA(ctx, desiredDeployment, service); err != nil {
+		logrus.Errorf("failed to update hpa, name: %s, (%v)", service.Name, err)
+		return err
+	}
+	return nil
+}
+
 func (k *Kubernetes) getDeploymentDeltaResource(ctx context.Context, deploy *appsv1.Deployment) (deltaCPU, deltaMemory int64, err error) {
 	oldDeploy, err := k.k8sClient.ClientSet.AppsV1().Deployments(deploy.Namespace).Get(ctx, deploy.Name, metav1.GetOptions{})
 	if err != nil {
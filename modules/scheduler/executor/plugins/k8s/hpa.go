@@ -0,0 +1,165 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+// newHPA generates the HorizontalPodAutoscaler which scales the deployment of the service
+func newHPA(service *apistructs.Service, deployment *appsv1.Deployment) *autoscalingv2beta2.HorizontalPodAutoscaler {
+	as := service.Autoscaling
+	minReplicas := int32(as.MinReplicas)
+	hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HorizontalPodAutoscaler",
+			APIVersion: "autoscaling/v2beta2",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deployment.Name,
+			Namespace: deployment.Namespace,
+			Labels:    map[string]string{"app": service.Name},
+		},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
+				Kind:       "Deployment",
+				Name:       deployment.Name,
+				APIVersion: "apps/v1",
+			},
+			MinReplicas: &minReplicas,
+			MaxReplicas: int32(as.MaxReplicas),
+		},
+	}
+	if as.CPU > 0 {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, resourceMetric(apiv1.ResourceCPU, as.CPU))
+	}
+	if as.Mem > 0 {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, resourceMetric(apiv1.ResourceMemory, as.Mem))
+	}
+	for _, m := range as.Metrics {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, autoscalingv2beta2.MetricSpec{
+			Type: autoscalingv2beta2.PodsMetricSourceType,
+			Pods: &autoscalingv2beta2.PodsMetricSource{
+				Metric: autoscalingv2beta2.MetricIdentifier{Name: m.Name},
+				Target: autoscalingv2beta2.MetricTarget{
+					Type:         autoscalingv2beta2.AverageValueMetricType,
+					AverageValue: resource.NewMilliQuantity(int64(m.Target*1000), resource.DecimalSI),
+				},
+			},
+		})
+	}
+	return hpa
+}
+
+func resourceMetric(name apiv1.ResourceName, utilization int) autoscalingv2beta2.MetricSpec {
+	target := int32(utilization)
+	return autoscalingv2beta2.MetricSpec{
+		Type: autoscalingv2beta2.ResourceMetricSourceType,
+		Resource: &autoscalingv2beta2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2beta2.MetricTarget{
+				Type:               autoscalingv2beta2.UtilizationMetricType,
+				AverageUtilization: &target,
+			},
+		},
+	}
+}
+
+// autoscaledReplicas keeps the replicas in the range of autoscaling,
+// so the replicas scaled by autoscaler won't be reset when updating the deployment
+func autoscaledReplicas(service *apistructs.Service, current int32) int32 {
+	if service.Autoscaling == nil {
+		return current
+	}
+	if current < int32(service.Autoscaling.MinReplicas) {
+		return int32(service.Autoscaling.MinReplicas)
+	}
+	if current > int32(service.Autoscaling.MaxReplicas) {
+		return int32(service.Autoscaling.MaxReplicas)
+	}
+	return current
+}
+
+// keepAutoscaledReplicas sets the replicas of the desired deployment to the running one if the service is autoscaled
+func (k *Kubernetes) keepAutoscaledReplicas(ctx context.Context, deployment *appsv1.Deployment, service *apistructs.Service) {
+	if service.Autoscaling == nil || deployment.Spec.Replicas == nil {
+		return
+	}
+	replicas := *deployment.Spec.Replicas
+	running, err := k.k8sClient.ClientSet.AppsV1().Deployments(deployment.Namespace).Get(ctx, deployment.Name, metav1.GetOptions{})
+	if err == nil && running.Spec.Replicas != nil {
+		replicas = *running.Spec.Replicas
+	}
+	replicas = autoscaledReplicas(service, replicas)
+	deployment.Spec.Replicas = &replicas
+}
+
+// updateHPA creates or updates the HorizontalPodAutoscaler of the service, and deletes it if autoscaling is disabled
+func (k *Kubernetes) updateHPA(ctx context.Context, deployment *appsv1.Deployment, service *apistructs.Service) error {
+	if service.Autoscaling == nil {
+		return k.deleteHPA(ctx, deployment.Namespace, deployment.Name)
+	}
+	desired := newHPA(service, deployment)
+	hpaClient := k.k8sClient.ClientSet.AutoscalingV2beta2().HorizontalPodAutoscalers(deployment.Namespace)
+	running, err := hpaClient.Get(ctx, desired.Name, metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return errors.Errorf("failed to get hpa, name: %s, (%v)", desired.Name, err)
+		}
+		if _, err = hpaClient.Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+			return errors.Errorf("failed to create hpa, name: %s, (%v)", desired.Name, err)
+		}
+		return nil
+	}
+	desired.ResourceVersion = running.ResourceVersion
+	if _, err = hpaClient.Update(ctx, desired, metav1.UpdateOptions{}); err != nil {
+		return errors.Errorf("failed to update hpa, name: %s, (%v)", desired.Name, err)
+	}
+	return nil
+}
+
+// deleteHPA deletes the HorizontalPodAutoscaler, ignoring not found
+func (k *Kubernetes) deleteHPA(ctx context.Context, namespace, name string) error {
+	err := k.k8sClient.ClientSet.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Errorf("failed to delete hpa, namespace: %s, name: %s, (%v)", namespace, name, err)
+	}
+	return nil
+}
+
+// setAutoscaledReplicas fills the current and desired replicas reported by the autoscaler of the service
+func (k *Kubernetes) setAutoscaledReplicas(ctx context.Context, namespace string, service *apistructs.Service) {
+	if service.Autoscaling == nil {
+		return
+	}
+	hpa, err := k.k8sClient.ClientSet.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace).Get(ctx, getDeployName(service), metav1.GetOptions{})
+	if err != nil {
+		logrus.Warnf("failed to get hpa, namespace: %s, name: %s, (%v)", namespace, getDeployName(service), err)
+		return
+	}
+	service.CurrentReplicas = int(hpa.Status.CurrentReplicas)
+	service.DesiredReplicas = int(hpa.Status.DesiredReplicas)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/parser/diceyml"
+)
+
+func TestNewHPA(t *testing.T) {
+	service := &apistructs.Service{
+		Name: "web",
+		Autoscaling: &diceyml.Autoscaling{
+			MinReplicas: 2,
+			MaxReplicas: 10,
+			CPU:         70,
+			Metrics:     []diceyml.AutoscalingMetric{{Name: "http_requests_per_second", Target: 0.5}},
+		},
+	}
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "project-1-dev"}}
+
+	hpa := newHPA(service, deployment)
+	assert.Equal(t, "web-1", hpa.Name)
+	assert.Equal(t, "project-1-dev", hpa.Namespace)
+	assert.Equal(t, "web-1", hpa.Spec.ScaleTargetRef.Name)
+	assert.Equal(t, int32(2), *hpa.Spec.MinReplicas)
+	assert.Equal(t, int32(10), hpa.Spec.MaxReplicas)
+	assert.Equal(t, 2, len(hpa.Spec.Metrics))
+	assert.Equal(t, apiv1.ResourceCPU, hpa.Spec.Metrics[0].Resource.Name)
+	assert.Equal(t, int32(70), *hpa.Spec.Metrics[0].Resource.Target.AverageUtilization)
+	assert.Equal(t, autoscalingv2beta2.PodsMetricSourceType, hpa.Spec.Metrics[1].Type)
+	assert.Equal(t, "500m", hpa.Spec.Metrics[1].Pods.Target.AverageValue.String())
+}
+
+func TestAutoscaledReplicas(t *testing.T) {
+	service := &apistructs.Service{}
+	assert.Equal(t, int32(1), autoscaledReplicas(service, 1))
+
+	service.Autoscaling = &diceyml.Autoscaling{MinReplicas: 2, MaxReplicas: 5}
+	assert.Equal(t, int32(2), autoscaledReplicas(service, 1))
+	assert.Equal(t, int32(3), autoscaledReplicas(service, 3))
+	assert.Equal(t, int32(5), autoscaledReplicas(service, 8))
+}
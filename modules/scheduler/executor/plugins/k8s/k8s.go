@@ -768,7 +768,7 @@ func (k *Kubernetes) tryDelete(namespace, name string) error {
 			namespace, name, err2)
 	}
 
-	return nil
+	return k.deleteHPA(context.Background(), namespace, name)
 }
 
 func (k *Kubernetes) getClusterIP(namespace, name string) (string, error) {
@@ -833,13 +833,17 @@ func (k *Kubernetes) updateOneByOne(ctx context.Context, sg *apistructs.ServiceG
 					return err
 				}
 			default:
-				// then update the deployment
-				desiredDeployment, err := k.newDeployment(&svc, sg)
-				if err != nil {
-					return err
+				// canary or blue-green deployment runs the new version beside the old one until promoted
+				if previewName := getPreviewDeployName(&svc); previewName != "" {
+					if err = k.rolloutDeployment(ctx, &svc, sg); err != nil {
+						logrus.Errorf("failed to rollout deployment in update interface, name: %s, (%v)", svc.Name, err)
+						return err
+					}
+					runtimeServiceMap[previewName] = RuntimeServiceRetain
+					break
 				}
-				if err = k.putDeployment(ctx, desiredDeployment, &svc); err != nil {
-					logrus.Debugf("failed to update deployment in update interface, name: %s, (%v)", svc.Name, err)
+				// then update the deployment
+				if err = k.updateDeployment(ctx, &svc, sg); err != nil {
 					return err
 				}
 			}
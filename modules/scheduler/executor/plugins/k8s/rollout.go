@@ -0,0 +1,228 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8serror"
+	"github.com/erda-project/erda/pkg/parser/diceyml"
+)
+
+const (
+	canaryDeploySuffix  = "-canary"
+	previewDeploySuffix = "-preview"
+	// LabelRolloutTrack distinguishes canary pods from the stable ones
+	LabelRolloutTrack = "rollout-track"
+)
+
+// rolloutOf returns the progressive strategy and rollout phase of the service,
+// both are empty if the service is rolling updated
+func rolloutOf(service *apistructs.Service) (string, string) {
+	strategy := service.Labels[apistructs.LabelRolloutStrategy]
+	if strategy != diceyml.DeploymentStrategyCanary && strategy != diceyml.DeploymentStrategyBlueGreen {
+		return "", ""
+	}
+	return strategy, service.Labels[apistructs.LabelRolloutPhase]
+}
+
+// getPreviewDeployName name of the deployment which runs the new version beside the stable one
+func getPreviewDeployName(service *apistructs.Service) string {
+	strategy, _ := rolloutOf(service)
+	switch strategy {
+	case diceyml.DeploymentStrategyCanary:
+		return getDeployName(service) + canaryDeploySuffix
+	case diceyml.DeploymentStrategyBlueGreen:
+		return getDeployName(service) + previewDeploySuffix
+	}
+	return ""
+}
+
+// canaryReplicas k8s service balances traffic between stable and canary pods,
+// so the canary replicas are chosen to make canary/(stable+canary) reach the weight
+func canaryReplicas(scale, weight int) int32 {
+	if weight >= 100 {
+		weight = 99
+	}
+	replicas := (scale*weight + 100 - weight - 1) / (100 - weight)
+	if replicas < 1 {
+		replicas = 1
+	}
+	return int32(replicas)
+}
+
+func (k *Kubernetes) newPreviewDeployment(service *apistructs.Service, sg *apistructs.ServiceGroup) (*appsv1.Deployment, error) {
+	deployment, err := k.newDeployment(service, sg)
+	if err != nil {
+		return nil, err
+	}
+	name := getPreviewDeployName(service)
+	deployment.Name = name
+	deployment.Spec.Template.Name = name
+
+	strategy, _ := rolloutOf(service)
+	switch strategy {
+	case diceyml.DeploymentStrategyCanary:
+		weight, err := strconv.Atoi(service.Labels[apistructs.LabelRolloutWeight])
+		if err != nil {
+			return nil, errors.Errorf("invalid canary weight of service %s: %s", service.Name, service.Labels[apistructs.LabelRolloutWeight])
+		}
+		replicas := canaryReplicas(service.Scale, weight)
+		deployment.Spec.Replicas = &replicas
+		// canary pods keep the "app" label, so they are selected by the k8s service together with the stable ones
+		deployment.Spec.Selector.MatchLabels[LabelRolloutTrack] = diceyml.DeploymentStrategyCanary
+		deployment.Spec.Template.Labels[LabelRolloutTrack] = diceyml.DeploymentStrategyCanary
+	case diceyml.DeploymentStrategyBlueGreen:
+		// preview pods receive no traffic from the k8s service until promoted
+		deployment.Spec.Selector.MatchLabels["app"] = name
+		deployment.Spec.Template.Labels["app"] = name
+	}
+	return deployment, nil
+}
+
+// rolloutDeployment updates a service deployed by canary or blue-green strategy.
+//
+// canary:
+// progressing: create or update the canary deployment, the stable deployment is untouched
+// promote, complete: update the stable deployment to the new version and delete the canary deployment
+//
+// blue-green, the stable deployment is blue and the preview deployment is green:
+// progressing: create or update the green deployment and the preview k8s service selecting it
+// promote: switch the selector of the k8s services to green, then update blue to the new version out of traffic
+// complete: blue is ready with the new version, switch the selector back to blue and delete green
+//
+// abort: switch the selector back to the stable deployment and delete the preview resources
+func (k *Kubernetes) rolloutDeployment(ctx context.Context, service *apistructs.Service, sg *apistructs.ServiceGroup) error {
+	strategy, phase := rolloutOf(service)
+	previewName := getPreviewDeployName(service)
+	switch phase {
+	case apistructs.RolloutPhaseProgressing:
+		preview, err := k.newPreviewDeployment(service, sg)
+		if err != nil {
+			return err
+		}
+		if _, err := k.getDeployment(service.Namespace, previewName); err != nil {
+			if !k8serror.NotFound(err) {
+				return err
+			}
+			logrus.Infof("create %s deployment %s on namespace %s", strategy, previewName, service.Namespace)
+			err = k.doCreateDeployment(ctx, preview, service)
+		} else {
+			err = k.putDeployment(ctx, preview, service)
+		}
+		if err != nil || strategy != diceyml.DeploymentStrategyBlueGreen {
+			return err
+		}
+		return k.putPreviewService(service)
+	case apistructs.RolloutPhasePromote, apistructs.RolloutPhaseComplete:
+		if strategy == diceyml.DeploymentStrategyBlueGreen && phase == apistructs.RolloutPhasePromote {
+			if _, err := k.getDeployment(service.Namespace, previewName); err != nil {
+				if !k8serror.NotFound(err) {
+					return err
+				}
+				return errors.Errorf("failed to promote service %s, deployment %s not found", service.Name, previewName)
+			}
+			logrus.Infof("switch traffic of service %s to deployment %s on namespace %s", service.Name, previewName, service.Namespace)
+			if err := k.switchServiceSelector(service, previewName); err != nil {
+				return err
+			}
+		}
+		// the stable deployment is updated the same way as a normal deployment, so the hpa keeps working
+		if err := k.updateDeployment(ctx, service, sg); err != nil {
+			return err
+		}
+		if strategy == diceyml.DeploymentStrategyBlueGreen && phase == apistructs.RolloutPhasePromote {
+			// green keeps serving until blue is ready with the new version
+			return nil
+		}
+		return k.deleteRolloutPreview(service)
+	case apistructs.RolloutPhaseAbort:
+		return k.deleteRolloutPreview(service)
+	default:
+		return errors.Errorf("invalid rollout phase of service %s: %s", service.Name, phase)
+	}
+}
+
+// putPreviewService creates or updates the k8s service which selects the pods of the green deployment,
+// so the new version of blue-green deployment can be verified before promoted
+func (k *Kubernetes) putPreviewService(service *apistructs.Service) error {
+	if len(service.Ports) == 0 {
+		return nil
+	}
+	previewName := getPreviewDeployName(service)
+	preview := *service
+	preview.Name = previewName
+	return k.CreateOrPutService(&preview, map[string]string{"app": previewName})
+}
+
+// switchServiceSelector switches the k8s services of the service to the pods with the app label,
+// the project service is switched too if the runtime is deployed in project namespace
+func (k *Kubernetes) switchServiceSelector(service *apistructs.Service, app string) error {
+	if len(service.Ports) == 0 {
+		return nil
+	}
+	names := []string{service.Name}
+	if service.ProjectServiceName != "" {
+		names = append(names, service.ProjectServiceName)
+	}
+	for _, name := range names {
+		svc, err := k.GetService(service.Namespace, name)
+		if err != nil {
+			if k8serror.NotFound(err) {
+				continue
+			}
+			return errors.Errorf("failed to get service, namespace: %s, name: %s, (%v)", service.Namespace, name, err)
+		}
+		if svc.Spec.Selector["app"] == app {
+			continue
+		}
+		if svc.Spec.Selector == nil {
+			svc.Spec.Selector = map[string]string{}
+		}
+		svc.Spec.Selector["app"] = app
+		if err := k.PutService(svc); err != nil {
+			return errors.Errorf("failed to switch service, namespace: %s, name: %s, (%v)", service.Namespace, name, err)
+		}
+	}
+	return nil
+}
+
+// deleteRolloutPreview switches the traffic back to the stable deployment,
+// then deletes the preview deployment and the preview k8s service
+func (k *Kubernetes) deleteRolloutPreview(service *apistructs.Service) error {
+	previewName := getPreviewDeployName(service)
+	if strategy, _ := rolloutOf(service); strategy == diceyml.DeploymentStrategyBlueGreen {
+		if err := k.switchServiceSelector(service, service.Name); err != nil {
+			return err
+		}
+		if err := k.DeleteService(service.Namespace, previewName); err != nil {
+			return errors.Errorf("failed to delete service, namespace: %s, name: %s, (%v)", service.Namespace, previewName, err)
+		}
+	}
+	return k.deletePreviewDeployment(service.Namespace, previewName)
+}
+
+func (k *Kubernetes) deletePreviewDeployment(namespace, name string) error {
+	if err := k.deleteDeployment(namespace, name); err != nil && !k8serror.NotFound(err) {
+		return errors.Errorf("failed to delete deployment, namespace: %s, name: %s, (%v)", namespace, name, err)
+	}
+	return nil
+}
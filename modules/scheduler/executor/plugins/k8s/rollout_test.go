@@ -0,0 +1,225 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8serror"
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8sservice"
+	"github.com/erda-project/erda/pkg/parser/diceyml"
+)
+
+func TestCanaryReplicas(t *testing.T) {
+	assert.Equal(t, int32(1), canaryReplicas(4, 20))
+	assert.Equal(t, int32(4), canaryReplicas(4, 50))
+	assert.Equal(t, int32(1), canaryReplicas(1, 1))
+	assert.Equal(t, int32(9), canaryReplicas(1, 90))
+}
+
+func TestGetPreviewDeployName(t *testing.T) {
+	service := &apistructs.Service{Name: "web", Labels: map[string]string{}}
+	assert.Equal(t, "", getPreviewDeployName(service))
+
+	service.Labels[apistructs.LabelRolloutStrategy] = "canary"
+	assert.Equal(t, "web-canary", getPreviewDeployName(service))
+
+	service.Labels[apistructs.LabelRolloutStrategy] = "blue-green"
+	service.ProjectServiceName = "web-1"
+	assert.Equal(t, "web-1-preview", getPreviewDeployName(service))
+
+	service.Labels[apistructs.LabelRolloutStrategy] = "rolling"
+	assert.Equal(t, "", getPreviewDeployName(service))
+}
+
+func TestGetDeploymentStatusFromMapWithRollout(t *testing.T) {
+	k := &Kubernetes{}
+	service := &apistructs.Service{Name: "web", Labels: map[string]string{
+		apistructs.LabelRolloutStrategy: "canary",
+		apistructs.LabelRolloutPhase:    apistructs.RolloutPhaseProgressing,
+	}}
+	ready := appsv1.Deployment{Status: appsv1.DeploymentStatus{
+		Replicas: 1, ReadyReplicas: 1, AvailableReplicas: 1, UpdatedReplicas: 1,
+		Conditions: []appsv1.DeploymentCondition{{Type: "Available", Status: "True"}},
+	}}
+	progressing := appsv1.Deployment{Status: appsv1.DeploymentStatus{
+		Replicas: 1, UpdatedReplicas: 1,
+		Conditions: []appsv1.DeploymentCondition{{Type: "Progressing", Status: "True"}},
+	}}
+
+	status, err := k.getDeploymentStatusFromMap(service, map[string]appsv1.Deployment{
+		"web":        ready,
+		"web-canary": progressing,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, apistructs.StatusUnknown, status.Status)
+
+	service.Labels[apistructs.LabelRolloutPhase] = apistructs.RolloutPhasePromote
+	status, err = k.getDeploymentStatusFromMap(service, map[string]appsv1.Deployment{
+		"web":        ready,
+		"web-canary": progressing,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, apistructs.StatusReady, status.Status)
+}
+
+// fakeRolloutCluster keeps the deployments and services in memory, deployments record the image they run
+type fakeRolloutCluster struct {
+	deployments map[string]string
+	services    map[string]*apiv1.Service
+	// hpaUpdated records the deployments whose hpa is updated
+	hpaUpdated []string
+}
+
+func newFakeRolloutCluster(k *Kubernetes) *fakeRolloutCluster {
+	c := &fakeRolloutCluster{
+		deployments: map[string]string{"web": "nginx:1"},
+		services: map[string]*apiv1.Service{"web": {
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       apiv1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+		}},
+	}
+	monkey.Patch((*Kubernetes).newDeployment, func(_ *Kubernetes, service *apistructs.Service, _ *apistructs.ServiceGroup) (*appsv1.Deployment, error) {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: getDeployName(service), Namespace: service.Namespace},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": service.Name}},
+				Template: apiv1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": service.Name}},
+					Spec:       apiv1.PodSpec{Containers: []apiv1.Container{{Image: service.Image}}},
+				},
+			},
+		}, nil
+	})
+	monkey.Patch((*Kubernetes).getDeployment, func(_ *Kubernetes, _, name string) (*appsv1.Deployment, error) {
+		if _, ok := c.deployments[name]; !ok {
+			return nil, k8serror.ErrNotFound
+		}
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name}}, nil
+	})
+	putDeployment := func(_ *Kubernetes, _ context.Context, deployment *appsv1.Deployment, _ *apistructs.Service) error {
+		c.deployments[deployment.Name] = deployment.Spec.Template.Spec.Containers[0].Image
+		return nil
+	}
+	monkey.Patch((*Kubernetes).doCreateDeployment, putDeployment)
+	monkey.Patch((*Kubernetes).putDeployment, putDeployment)
+	monkey.Patch((*Kubernetes).keepAutoscaledReplicas, func(_ *Kubernetes, _ context.Context, _ *appsv1.Deployment, _ *apistructs.Service) {})
+	monkey.Patch((*Kubernetes).updateHPA, func(_ *Kubernetes, _ context.Context, deployment *appsv1.Deployment, _ *apistructs.Service) error {
+		c.hpaUpdated = append(c.hpaUpdated, deployment.Name)
+		return nil
+	})
+	monkey.Patch((*Kubernetes).deleteDeployment, func(_ *Kubernetes, _, name string) error {
+		delete(c.deployments, name)
+		return nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(k.service), "Get", func(_ *k8sservice.Service, _, name string) (*apiv1.Service, error) {
+		svc, ok := c.services[name]
+		if !ok {
+			return nil, k8serror.ErrNotFound
+		}
+		return svc.DeepCopy(), nil
+	})
+	putService := func(_ *k8sservice.Service, svc *apiv1.Service) error {
+		c.services[svc.Name] = svc.DeepCopy()
+		return nil
+	}
+	monkey.PatchInstanceMethod(reflect.TypeOf(k.service), "Create", putService)
+	monkey.PatchInstanceMethod(reflect.TypeOf(k.service), "Put", putService)
+	monkey.PatchInstanceMethod(reflect.TypeOf(k.service), "Delete", func(_ *k8sservice.Service, _, name string) error {
+		delete(c.services, name)
+		return nil
+	})
+	return c
+}
+
+func (c *fakeRolloutCluster) selector(name string) string {
+	svc, ok := c.services[name]
+	if !ok {
+		return ""
+	}
+	return svc.Spec.Selector["app"]
+}
+
+func newBlueGreenService(phase string) *apistructs.Service {
+	return &apistructs.Service{
+		Name:      "web",
+		Namespace: "default",
+		Image:     "nginx:2",
+		Ports:     []diceyml.ServicePort{{Port: 80, Protocol: "TCP"}},
+		Labels: map[string]string{
+			apistructs.LabelRolloutStrategy: diceyml.DeploymentStrategyBlueGreen,
+			apistructs.LabelRolloutPhase:    phase,
+		},
+	}
+}
+
+func TestRolloutDeploymentBlueGreen(t *testing.T) {
+	k := &Kubernetes{service: k8sservice.New()}
+	defer monkey.UnpatchAll()
+	c := newFakeRolloutCluster(k)
+	ctx := context.Background()
+
+	// green runs beside blue, and is reachable by the preview service only
+	assert.NoError(t, k.rolloutDeployment(ctx, newBlueGreenService(apistructs.RolloutPhaseProgressing), nil))
+	assert.Equal(t, map[string]string{"web": "nginx:1", "web-preview": "nginx:2"}, c.deployments)
+	assert.Equal(t, "web", c.selector("web"))
+	assert.Equal(t, "web-preview", c.selector("web-preview"))
+	assert.Empty(t, c.hpaUpdated)
+
+	// traffic is switched to green at once, then blue is updated out of traffic
+	assert.NoError(t, k.rolloutDeployment(ctx, newBlueGreenService(apistructs.RolloutPhasePromote), nil))
+	assert.Equal(t, "web-preview", c.selector("web"))
+	assert.Equal(t, map[string]string{"web": "nginx:2", "web-preview": "nginx:2"}, c.deployments)
+	// blue is updated like a normal deployment, together with its hpa
+	assert.Equal(t, []string{"web"}, c.hpaUpdated)
+
+	// blue runs the new version, traffic is switched back and green is removed
+	assert.NoError(t, k.rolloutDeployment(ctx, newBlueGreenService(apistructs.RolloutPhaseComplete), nil))
+	assert.Equal(t, "web", c.selector("web"))
+	assert.Equal(t, map[string]string{"web": "nginx:2"}, c.deployments)
+	_, ok := c.services["web-preview"]
+	assert.False(t, ok)
+}
+
+func TestRolloutDeploymentBlueGreenAbort(t *testing.T) {
+	k := &Kubernetes{service: k8sservice.New()}
+	defer monkey.UnpatchAll()
+	c := newFakeRolloutCluster(k)
+	ctx := context.Background()
+
+	assert.NoError(t, k.rolloutDeployment(ctx, newBlueGreenService(apistructs.RolloutPhaseProgressing), nil))
+	// promote fails after the traffic is switched, abort switches it back to blue
+	c.services["web"].Spec.Selector["app"] = "web-preview"
+	assert.NoError(t, k.rolloutDeployment(ctx, newBlueGreenService(apistructs.RolloutPhaseAbort), nil))
+	assert.Equal(t, "web", c.selector("web"))
+	assert.Equal(t, map[string]string{"web": "nginx:1"}, c.deployments)
+	_, ok := c.services["web-preview"]
+	assert.False(t, ok)
+
+	// promote without green is refused, so blue is never left without traffic
+	err := k.rolloutDeployment(ctx, newBlueGreenService(apistructs.RolloutPhasePromote), nil)
+	assert.Error(t, err)
+	assert.Equal(t, "web", c.selector("web"))
+	assert.Equal(t, map[string]string{"web": "nginx:1"}, c.deployments)
+}
@@ -98,6 +98,19 @@ func (k *Kubernetes) destroyRuntimeByProjectNamespace(ns string, sg *apistructs.
 		if err != nil && !util.IsNotFound(err) {
 			return fmt.Errorf("delete resource %s, %s error: %v", service.WorkLoad, service.ProjectServiceName, err)
 		}
+		if previewName := getPreviewDeployName(&service); previewName != "" {
+			if err := k.DeleteService(ns, previewName); err != nil {
+				return fmt.Errorf("delete service %s error: %v", previewName, err)
+			}
+			if err := k.deletePreviewDeployment(ns, previewName); err != nil {
+				return err
+			}
+		}
+		if service.Autoscaling != nil {
+			if err := k.deleteHPA(context.Background(), ns, service.ProjectServiceName); err != nil {
+				return err
+			}
+		}
 
 		labelSelector := map[string]string{
 			"app": service.Name,
@@ -324,6 +337,7 @@ func (k *Kubernetes) inspectStateless(sg *apistructs.ServiceGroup) (*apistructs.
 	}
 
 	for i, svc := range sg.Services {
+		k.setAutoscaledReplicas(context.Background(), ns, &sg.Services[i])
 		serviceName := getServiceName(&svc)
 		if len(svc.Ports) == 0 {
 			continue
@@ -280,6 +280,7 @@ func convertServiceGroup(req apistructs.ServiceGroupCreateV2Request, clusterinfo
 			Selectors:        service.Deployments.Selectors,
 			WorkLoad:         service.Deployments.Workload,
 			DeploymentLabels: service.Deployments.Labels,
+			Autoscaling:      service.Deployments.Autoscaling,
 			Binds:            binds,
 			Volumes:          volumes,
 			Hosts:            service.Hosts,
@@ -16,6 +16,7 @@ package cloudstorage
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -27,6 +28,8 @@ import (
 type Client interface {
 	UploadFile(bucketName, objectName, file string) (string, error)
 	DownloadFile(bucketName, objectName string) ([]byte, error)
+	OpenFile(bucketName, objectName string) (io.ReadCloser, error)
+	DeleteFile(bucketName, objectName string) error
 	GetFileUrl(bucketName, objectName string) (string, error)
 	HealthCheck() error
 }
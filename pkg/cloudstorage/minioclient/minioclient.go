@@ -16,6 +16,7 @@ package minioclient
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -87,6 +88,14 @@ func (c *MinioClient) DownloadFile(bucketName, objectName string) ([]byte, error
 	return data, nil
 }
 
+func (c *MinioClient) OpenFile(bucketName, objectName string) (io.ReadCloser, error) {
+	return c.client.GetObject(bucketName, objectName, minio.GetObjectOptions{})
+}
+
+func (c *MinioClient) DeleteFile(bucketName, objectName string) error {
+	return c.client.RemoveObject(bucketName, objectName)
+}
+
 func (c *MinioClient) GetFileUrl(bucketName, objectName string) (string, error) {
 	info, err := c.client.StatObject(bucketName, objectName, minio.StatObjectOptions{})
 	if err != nil {
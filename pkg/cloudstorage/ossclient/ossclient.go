@@ -86,6 +86,22 @@ func (c *OssClient) DownloadFile(bucketName, objectName string) ([]byte, error)
 	return data, nil
 }
 
+func (c *OssClient) OpenFile(bucketName, objectName string) (io.ReadCloser, error) {
+	bucket, err := c.client.Bucket(bucketName)
+	if err != nil {
+		return nil, err
+	}
+	return bucket.GetObject(objectName)
+}
+
+func (c *OssClient) DeleteFile(bucketName, objectName string) error {
+	bucket, err := c.client.Bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	return bucket.DeleteObject(objectName)
+}
+
 func (c *OssClient) GetFileUrl(bucketName, objectName string) (string, error) {
 	bucket, err := c.client.Bucket(bucketName)
 	if err != nil {
@@ -44,6 +44,7 @@ const (
 	Base64Decode = "base64-decode"
 	TriggerLabel = "triggers"
 	I18n         = "i18n"
+	Matrix       = "matrix"
 )
 
 const (
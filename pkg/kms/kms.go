@@ -17,6 +17,7 @@ package kms
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/erda-project/erda/pkg/kms/kmstypes"
@@ -104,6 +105,16 @@ func (m *Manager) GetPlugin(pluginKind kmstypes.PluginKind, storeKind kmstypes.S
 	return plugin, nil
 }
 
+// ListPluginKinds return kinds of all registered plugins
+func (m *Manager) ListPluginKinds() []kmstypes.PluginKind {
+	var kinds []kmstypes.PluginKind
+	for kind := range m.plugins {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i] < kinds[j] })
+	return kinds
+}
+
 func (m *Manager) GetStore(storeKind kmstypes.StoreKind) (kmstypes.Store, error) {
 	store, ok := m.stores[storeKind]
 	if !ok || store == nil {
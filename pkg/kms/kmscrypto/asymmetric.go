@@ -0,0 +1,123 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kmscrypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// GenerateRsaKey generate rsa private key with specified bits.
+func GenerateRsaKey(bits int) (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, bits)
+}
+
+// GenerateEcdsaKey generate ecdsa private key on specified curve.
+func GenerateEcdsaKey(curve elliptic.Curve) (*ecdsa.PrivateKey, error) {
+	return ecdsa.GenerateKey(curve, rand.Reader)
+}
+
+// MarshalPrivateKey convert private key to PKCS #8 DER.
+// Use ParsePrivateKey to parse.
+func MarshalPrivateKey(key crypto.Signer) ([]byte, error) {
+	return x509.MarshalPKCS8PrivateKey(key)
+}
+
+// ParsePrivateKey parse PKCS #8 DER to rsa or ecdsa private key.
+func ParsePrivateKey(der []byte) (crypto.Signer, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("not supported private key type: %T", key)
+	}
+}
+
+// MarshalPublicKeyPem convert public key to PEM encoded PKIX public key.
+// Use ParsePublicKeyPem to parse.
+func MarshalPublicKeyPem(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// ParsePublicKeyPem parse PEM encoded PKIX public key.
+func ParsePublicKeyPem(pemStr string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(pemStr))
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("invalid public key pem")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// RsaOaepEncrypt encrypt plaintext by RSAES-OAEP with SHA-256.
+func RsaOaepEncrypt(pub *rsa.PublicKey, plaintext []byte) ([]byte, error) {
+	return rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, plaintext, nil)
+}
+
+// RsaOaepDecrypt decrypt ciphertext encrypted by RsaOaepEncrypt.
+func RsaOaepDecrypt(key *rsa.PrivateKey, ciphertext []byte) ([]byte, error) {
+	return rsa.DecryptOAEP(sha256.New(), rand.Reader, key, ciphertext, nil)
+}
+
+// SignDigest sign digest by private key.
+// For rsa key, use RSASSA-PSS if pss is true, otherwise use RSASSA-PKCS1-v1_5;
+// for ecdsa key, signature is ASN.1 DER encoded.
+func SignDigest(key crypto.Signer, hash crypto.Hash, pss bool, digest []byte) ([]byte, error) {
+	if len(digest) != hash.Size() {
+		return nil, fmt.Errorf("invalid digest length: %d, expect: %d", len(digest), hash.Size())
+	}
+	var opts crypto.SignerOpts = hash
+	if _, ok := key.(*rsa.PrivateKey); ok && pss {
+		opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}
+	}
+	return key.Sign(rand.Reader, digest, opts)
+}
+
+// VerifyDigest verify signature generated by SignDigest.
+func VerifyDigest(pub crypto.PublicKey, hash crypto.Hash, pss bool, digest, signature []byte) error {
+	if len(digest) != hash.Size() {
+		return fmt.Errorf("invalid digest length: %d, expect: %d", len(digest), hash.Size())
+	}
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		if pss {
+			return rsa.VerifyPSS(k, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto, Hash: hash})
+		}
+		return rsa.VerifyPKCS1v15(k, hash, digest, signature)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, digest, signature) {
+			return fmt.Errorf("ecdsa: verification error")
+		}
+		return nil
+	default:
+		return fmt.Errorf("not supported public key type: %T", pub)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kmscrypto
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRsaOaep(t *testing.T) {
+	key, err := GenerateRsaKey(2048)
+	assert.NoError(t, err)
+
+	der, err := MarshalPrivateKey(key)
+	assert.NoError(t, err)
+	parsedKey, err := ParsePrivateKey(der)
+	assert.NoError(t, err)
+	pubPem, err := MarshalPublicKeyPem(parsedKey.Public())
+	assert.NoError(t, err)
+	pub, err := ParsePublicKeyPem(pubPem)
+	assert.NoError(t, err)
+
+	plaintext := []byte("hello world")
+	ciphertext, err := RsaOaepEncrypt(pub.(*rsa.PublicKey), plaintext)
+	assert.NoError(t, err)
+	decrypted, err := RsaOaepDecrypt(parsedKey.(*rsa.PrivateKey), ciphertext)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestSignDigest(t *testing.T) {
+	rsaKey, err := GenerateRsaKey(2048)
+	assert.NoError(t, err)
+	ecKey, err := GenerateEcdsaKey(elliptic.P384())
+	assert.NoError(t, err)
+
+	sum256 := sha256.Sum256([]byte("hello world"))
+	sum384 := sha512.Sum384([]byte("hello world"))
+	tampered := sha256.Sum256([]byte("hello world!"))
+
+	cases := []struct {
+		name   string
+		key    crypto.Signer
+		hash   crypto.Hash
+		pss    bool
+		digest []byte
+	}{
+		{"rsa pss", rsaKey, crypto.SHA256, true, sum256[:]},
+		{"rsa pkcs1v15", rsaKey, crypto.SHA256, false, sum256[:]},
+		{"ecdsa", ecKey, crypto.SHA384, false, sum384[:]},
+	}
+	for _, c := range cases {
+		sig, err := SignDigest(c.key, c.hash, c.pss, c.digest)
+		assert.NoError(t, err, c.name)
+		assert.NoError(t, VerifyDigest(c.key.Public(), c.hash, c.pss, c.digest, sig), c.name)
+		if c.hash == crypto.SHA256 {
+			assert.Error(t, VerifyDigest(c.key.Public(), c.hash, c.pss, tampered[:], sig), c.name)
+		}
+	}
+
+	// pss signature cannot be verified as pkcs1v15
+	sig, err := SignDigest(rsaKey, crypto.SHA256, true, sum256[:])
+	assert.NoError(t, err)
+	assert.Error(t, VerifyDigest(rsaKey.Public(), crypto.SHA256, false, sum256[:], sig))
+
+	// wrong digest length
+	_, err = SignDigest(rsaKey, crypto.SHA256, true, sum384[:])
+	assert.Error(t, err)
+}
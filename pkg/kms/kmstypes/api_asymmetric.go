@@ -14,19 +14,187 @@
 
 package kmstypes
 
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+type (
+	EncryptionAlgorithmSpec string
+	SigningAlgorithmSpec    string
+	MessageType             string
+)
+
+type GetPublicKeyRequest struct {
+	KeyID string `json:"keyID,omitempty"`
+	// Optional. Use primary key version if not specified.
+	KeyVersionID string `json:"keyVersionID,omitempty"`
+}
+
+func (req *GetPublicKeyRequest) ValidateRequest() error {
+	if req.KeyID == "" {
+		return fmt.Errorf("missing keyID")
+	}
+	return nil
+}
+
+type PublicKey struct {
+	KeyID        string `json:"keyID,omitempty"`
+	KeyVersionID string `json:"keyVersionID,omitempty"`
+	// PEM encoded PKIX public key
+	Pem string `json:"pem,omitempty"`
+	// Algorithm is the key spec of the key pair, such as RSA_2048, EC_P256
+	Algorithm            string                    `json:"algorithm,omitempty"`
+	KeyUsage             KeyUsage                  `json:"keyUsage,omitempty"`
+	EncryptionAlgorithms []EncryptionAlgorithmSpec `json:"encryptionAlgorithms,omitempty"`
+	SigningAlgorithms    []SigningAlgorithmSpec    `json:"signingAlgorithms,omitempty"`
+}
+
+type AsymmetricEncryptRequest struct {
+	KeyID string `json:"keyID,omitempty"`
+	// Required. The data to encrypt.
+	// A base64-encoded string.
+	PlaintextBase64 string `json:"plaintextBase64,omitempty"`
+}
+
+func (req *AsymmetricEncryptRequest) ValidateRequest() error {
+	if req.KeyID == "" {
+		return fmt.Errorf("missing keyID")
+	}
+	if len(req.PlaintextBase64) == 0 {
+		return fmt.Errorf("missing plaintextBase64")
+	}
+	if _, err := base64.StdEncoding.DecodeString(req.PlaintextBase64); err != nil {
+		return fmt.Errorf("cannot decode base64 plaintext, err: %v", err)
+	}
+	return nil
+}
+
+type AsymmetricEncryptResponse struct {
+	KeyID string `json:"keyID,omitempty"`
+	// The key version used to encrypt, must be provided when decrypt.
+	KeyVersionID        string                  `json:"keyVersionID,omitempty"`
+	EncryptionAlgorithm EncryptionAlgorithmSpec `json:"encryptionAlgorithm,omitempty"`
+	// The encrypted data.
+	// A base64-encoded string.
+	CiphertextBase64 string `json:"ciphertextBase64,omitempty"`
+}
+
 type AsymmetricDecryptRequest struct {
-	KeyID            string `json:"keyID,omitempty"`
-	CiphertextBase64 []byte `json:"ciphertextBase64,omitempty"`
+	KeyID string `json:"keyID,omitempty"`
+	// Optional. Use primary key version if not specified.
+	KeyVersionID string `json:"keyVersionID,omitempty"`
+	// The encrypted data.
+	// A base64-encoded string.
+	CiphertextBase64 string `json:"ciphertextBase64,omitempty"`
+}
+
+func (req *AsymmetricDecryptRequest) ValidateRequest() error {
+	if req.KeyID == "" {
+		return fmt.Errorf("missing keyID")
+	}
+	if len(req.CiphertextBase64) == 0 {
+		return fmt.Errorf("missing ciphertextBase64")
+	}
+	if _, err := base64.StdEncoding.DecodeString(req.CiphertextBase64); err != nil {
+		return fmt.Errorf("cannot decode base64 ciphertext, err: %v", err)
+	}
+	return nil
 }
+
 type AsymmetricDecryptResponse struct {
-	PlaintextBase64 []byte `json:"plaintextBase64,omitempty"`
+	PlaintextBase64 string `json:"plaintextBase64,omitempty"`
 }
 
-type GetPublicKeyRequest struct {
+type SignRequest struct {
 	KeyID string `json:"keyID,omitempty"`
+	// Optional. Use default algorithm of key spec if not specified.
+	SigningAlgorithm SigningAlgorithmSpec `json:"signingAlgorithm,omitempty"`
+	// Optional. RAW or DIGEST, default is RAW.
+	// RAW message must be no larger than 4KiB, sign large data such as release artifacts by DIGEST.
+	MessageType MessageType `json:"messageType,omitempty"`
+	// A base64-encoded string.
+	MessageBase64 string `json:"messageBase64,omitempty"`
 }
 
-type PublicKey struct {
-	Pem       string `json:"pem,omitempty"`
-	Algorithm string `json:"algorithm,omitempty"`
+func (req *SignRequest) ValidateRequest() error {
+	if req.KeyID == "" {
+		return fmt.Errorf("missing keyID")
+	}
+	if req.MessageType == "" {
+		req.MessageType = MessageType_RAW
+	}
+	if err := req.MessageType.Validate(); err != nil {
+		return err
+	}
+	if len(req.MessageBase64) == 0 {
+		return fmt.Errorf("missing messageBase64")
+	}
+	if _, err := base64.StdEncoding.DecodeString(req.MessageBase64); err != nil {
+		return fmt.Errorf("cannot decode base64 message, err: %v", err)
+	}
+	return nil
+}
+
+type SignResponse struct {
+	KeyID string `json:"keyID,omitempty"`
+	// The key version used to sign, must be provided when verify.
+	KeyVersionID     string               `json:"keyVersionID,omitempty"`
+	SigningAlgorithm SigningAlgorithmSpec `json:"signingAlgorithm,omitempty"`
+	// A base64-encoded string.
+	SignatureBase64 string `json:"signatureBase64,omitempty"`
+}
+
+type VerifyRequest struct {
+	KeyID string `json:"keyID,omitempty"`
+	// Optional. Use primary key version if not specified.
+	KeyVersionID string `json:"keyVersionID,omitempty"`
+	// Optional. Use default algorithm of key spec if not specified.
+	SigningAlgorithm SigningAlgorithmSpec `json:"signingAlgorithm,omitempty"`
+	// Optional. RAW or DIGEST, default is RAW.
+	MessageType MessageType `json:"messageType,omitempty"`
+	// A base64-encoded string.
+	MessageBase64 string `json:"messageBase64,omitempty"`
+	// A base64-encoded string.
+	SignatureBase64 string `json:"signatureBase64,omitempty"`
+}
+
+func (req *VerifyRequest) ValidateRequest() error {
+	if req.KeyID == "" {
+		return fmt.Errorf("missing keyID")
+	}
+	if req.MessageType == "" {
+		req.MessageType = MessageType_RAW
+	}
+	if err := req.MessageType.Validate(); err != nil {
+		return err
+	}
+	if len(req.MessageBase64) == 0 {
+		return fmt.Errorf("missing messageBase64")
+	}
+	if _, err := base64.StdEncoding.DecodeString(req.MessageBase64); err != nil {
+		return fmt.Errorf("cannot decode base64 message, err: %v", err)
+	}
+	if len(req.SignatureBase64) == 0 {
+		return fmt.Errorf("missing signatureBase64")
+	}
+	if _, err := base64.StdEncoding.DecodeString(req.SignatureBase64); err != nil {
+		return fmt.Errorf("cannot decode base64 signature, err: %v", err)
+	}
+	return nil
+}
+
+type VerifyResponse struct {
+	KeyID            string               `json:"keyID,omitempty"`
+	KeyVersionID     string               `json:"keyVersionID,omitempty"`
+	SigningAlgorithm SigningAlgorithmSpec `json:"signingAlgorithm,omitempty"`
+	SignatureValid   bool                 `json:"signatureValid"`
+}
+
+func (t MessageType) Validate() error {
+	switch t {
+	case MessageType_RAW, MessageType_DIGEST:
+		return nil
+	}
+	return fmt.Errorf("invalid messageType: %s", t)
 }
@@ -30,11 +30,15 @@ const (
 
 	StoreKind_ETCD  StoreKind = "ETCD"
 	StoreKind_MYSQL StoreKind = "MYSQL"
+	// StoreKind_MEMORY only for test
+	StoreKind_MEMORY StoreKind = "MEMORY"
 
 	CustomerMasterKeySpec_SYMMETRIC_DEFAULT   CustomerMasterKeySpec = "SYMMETRIC_DEFAULT" // AES-256-GCM ; default
 	CustomerMasterKeySpec_ASYMMETRIC_RSA_2048 CustomerMasterKeySpec = "RSA_2048"
 	CustomerMasterKeySpec_ASYMMETRIC_RSA_3072 CustomerMasterKeySpec = "RSA_3072"
 	CustomerMasterKeySpec_ASYMMETRIC_RSA_4096 CustomerMasterKeySpec = "RSA_4096"
+	CustomerMasterKeySpec_ASYMMETRIC_EC_P256  CustomerMasterKeySpec = "EC_P256" // only for SIGN_VERIFY
+	CustomerMasterKeySpec_ASYMMETRIC_EC_P384  CustomerMasterKeySpec = "EC_P384" // only for SIGN_VERIFY
 
 	KeyUsage_ENCRYPT_DECRYPT KeyUsage = "ENCRYPT_DECRYPT"
 	KeyUsage_SIGN_VERIFY     KeyUsage = "SIGN_VERIFY"
@@ -44,4 +48,14 @@ const (
 	KeyStatePendingDeletion KeyState = "PendingDeletion"
 	KeyStatePendingImport   KeyState = "PendingImport"
 	KeyStateUnavailable     KeyState = "Unavailable"
+
+	EncryptionAlgorithm_RSAES_OAEP_SHA_256 EncryptionAlgorithmSpec = "RSAES_OAEP_SHA_256"
+
+	SigningAlgorithm_RSASSA_PSS_SHA_256        SigningAlgorithmSpec = "RSASSA_PSS_SHA_256"
+	SigningAlgorithm_RSASSA_PKCS1_V1_5_SHA_256 SigningAlgorithmSpec = "RSASSA_PKCS1_V1_5_SHA_256"
+	SigningAlgorithm_ECDSA_SHA_256             SigningAlgorithmSpec = "ECDSA_SHA_256"
+	SigningAlgorithm_ECDSA_SHA_384             SigningAlgorithmSpec = "ECDSA_SHA_384"
+
+	MessageType_RAW    MessageType = "RAW"
+	MessageType_DIGEST MessageType = "DIGEST"
 )
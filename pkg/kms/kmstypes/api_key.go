@@ -22,6 +22,29 @@ type (
 	KeyState              string
 )
 
+// IsSymmetric return if the key spec is symmetric
+func (spec CustomerMasterKeySpec) IsSymmetric() bool {
+	return spec == CustomerMasterKeySpec_SYMMETRIC_DEFAULT
+}
+
+// IsRSA return if the key spec is rsa key pair
+func (spec CustomerMasterKeySpec) IsRSA() bool {
+	switch spec {
+	case CustomerMasterKeySpec_ASYMMETRIC_RSA_2048, CustomerMasterKeySpec_ASYMMETRIC_RSA_3072, CustomerMasterKeySpec_ASYMMETRIC_RSA_4096:
+		return true
+	}
+	return false
+}
+
+// IsECC return if the key spec is elliptic curve key pair
+func (spec CustomerMasterKeySpec) IsECC() bool {
+	switch spec {
+	case CustomerMasterKeySpec_ASYMMETRIC_EC_P256, CustomerMasterKeySpec_ASYMMETRIC_EC_P384:
+		return true
+	}
+	return false
+}
+
 type (
 	KeyMetadata struct {
 		KeyID                 string                `json:"keyID,omitempty"`
@@ -30,6 +53,7 @@ type (
 		KeyUsage              KeyUsage              `json:"keyUsage,omitempty"`
 		KeyState              KeyState              `json:"keyState,omitempty"`
 		Description           string                `json:"description,omitempty"`
+		RotationPolicy        *KeyRotationPolicy    `json:"rotationPolicy,omitempty"`
 	}
 
 	KeyListEntry struct {
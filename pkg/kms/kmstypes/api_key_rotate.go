@@ -14,7 +14,16 @@
 
 package kmstypes
 
-import "fmt"
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinRotationInterval is the minimum interval of automatic rotation
+const MinRotationInterval = 24 * time.Hour
 
 type RotateKeyVersionRequest struct {
 	KeyID string `json:"keyID,omitempty"`
@@ -30,3 +39,128 @@ func (req *RotateKeyVersionRequest) ValidateRequest() error {
 type RotateKeyVersionResponse struct {
 	KeyMetadata KeyMetadata `json:"keyMetadata,omitempty"`
 }
+
+// KeyRotationPolicy 密钥自动轮转策略，到达 NextRotationTime 后由后台任务自动轮转密钥版本
+type KeyRotationPolicy struct {
+	Enabled bool `json:"enabled"`
+	// RotationInterval such as 30d, 720h, at least 24h
+	RotationInterval string     `json:"rotationInterval,omitempty"`
+	NextRotationTime *time.Time `json:"nextRotationTime,omitempty"`
+	LastRotationTime *time.Time `json:"lastRotationTime,omitempty"`
+}
+
+// ParseRotationInterval parse rotation interval, support day unit `d` and units of time.ParseDuration
+func ParseRotationInterval(s string) (time.Duration, error) {
+	var (
+		d   time.Duration
+		err error
+	)
+	if strings.HasSuffix(s, "d") {
+		var days int64
+		days, err = strconv.ParseInt(strings.TrimSuffix(s, "d"), 10, 64)
+		d = time.Duration(days) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid rotation interval: %s", s)
+	}
+	if d < MinRotationInterval {
+		return 0, fmt.Errorf("rotation interval %s is less than %s", s, MinRotationInterval)
+	}
+	return d, nil
+}
+
+// IsDue return if key should be rotated automatically
+func (p *KeyRotationPolicy) IsDue(now time.Time) bool {
+	if p == nil || !p.Enabled || p.NextRotationTime == nil {
+		return false
+	}
+	return !now.Before(*p.NextRotationTime)
+}
+
+// Rotated update last and next rotation time after key version rotated
+func (p *KeyRotationPolicy) Rotated(now time.Time) {
+	if p == nil {
+		return
+	}
+	p.LastRotationTime = &now
+	p.Schedule(now)
+}
+
+// Schedule calculate next rotation time from the given time
+func (p *KeyRotationPolicy) Schedule(from time.Time) {
+	if p == nil {
+		return
+	}
+	if !p.Enabled {
+		p.NextRotationTime = nil
+		return
+	}
+	interval, err := ParseRotationInterval(p.RotationInterval)
+	if err != nil {
+		p.NextRotationTime = nil
+		return
+	}
+	next := from.Add(interval)
+	p.NextRotationTime = &next
+}
+
+type UpdateRotationPolicyRequest struct {
+	KeyID                   string `json:"keyID,omitempty"`
+	EnableAutomaticRotation bool   `json:"enableAutomaticRotation"`
+	// Required if enableAutomaticRotation is true. Such as 30d, 720h, at least 24h.
+	RotationInterval string `json:"rotationInterval,omitempty"`
+}
+
+func (req *UpdateRotationPolicyRequest) ValidateRequest() error {
+	if req.KeyID == "" {
+		return fmt.Errorf("missing keyID")
+	}
+	if !req.EnableAutomaticRotation {
+		return nil
+	}
+	if req.RotationInterval == "" {
+		return fmt.Errorf("missing rotationInterval")
+	}
+	if _, err := ParseRotationInterval(req.RotationInterval); err != nil {
+		return err
+	}
+	return nil
+}
+
+type UpdateRotationPolicyResponse struct {
+	KeyMetadata KeyMetadata `json:"keyMetadata,omitempty"`
+}
+
+type ReEncryptRequest struct {
+	KeyID string `json:"keyID,omitempty"`
+	// Only for asymmetric key, the key version used to encrypt the ciphertext.
+	// Symmetric ciphertext already contains the key version.
+	SourceKeyVersionID string `json:"sourceKeyVersionID,omitempty"`
+	// The encrypted data.
+	// A base64-encoded string.
+	CiphertextBase64 string `json:"ciphertextBase64,omitempty"`
+}
+
+func (req *ReEncryptRequest) ValidateRequest() error {
+	if req.KeyID == "" {
+		return fmt.Errorf("missing keyID")
+	}
+	if len(req.CiphertextBase64) == 0 {
+		return fmt.Errorf("missing ciphertextBase64")
+	}
+	if _, err := base64.StdEncoding.DecodeString(req.CiphertextBase64); err != nil {
+		return fmt.Errorf("cannot decode base64 ciphertext, err: %v", err)
+	}
+	return nil
+}
+
+type ReEncryptResponse struct {
+	KeyID string `json:"keyID,omitempty"`
+	// The primary key version used to re-encrypt.
+	KeyVersionID string `json:"keyVersionID,omitempty"`
+	// The re-encrypted data.
+	// A base64-encoded string.
+	CiphertextBase64 string `json:"ciphertextBase64,omitempty"`
+}
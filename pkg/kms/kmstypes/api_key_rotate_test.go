@@ -0,0 +1,60 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kmstypes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRotationInterval(t *testing.T) {
+	d, err := ParseRotationInterval("7d")
+	assert.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, d)
+
+	d, err = ParseRotationInterval("36h")
+	assert.NoError(t, err)
+	assert.Equal(t, 36*time.Hour, d)
+
+	_, err = ParseRotationInterval("1h")
+	assert.Error(t, err)
+	_, err = ParseRotationInterval("xd")
+	assert.Error(t, err)
+}
+
+func TestKeyRotationPolicy(t *testing.T) {
+	var nilPolicy *KeyRotationPolicy
+	assert.False(t, nilPolicy.IsDue(time.Now()))
+	nilPolicy.Rotated(time.Now())
+
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	p := &KeyRotationPolicy{Enabled: true, RotationInterval: "1d"}
+	assert.False(t, p.IsDue(now))
+	p.Schedule(now)
+	assert.Equal(t, now.Add(24*time.Hour), *p.NextRotationTime)
+	assert.False(t, p.IsDue(now.Add(time.Hour)))
+	assert.True(t, p.IsDue(now.Add(24*time.Hour)))
+
+	p.Rotated(now.Add(25 * time.Hour))
+	assert.Equal(t, now.Add(25*time.Hour), *p.LastRotationTime)
+	assert.Equal(t, now.Add(49*time.Hour), *p.NextRotationTime)
+
+	p.Enabled = false
+	p.Schedule(now)
+	assert.Nil(t, p.NextRotationTime)
+	assert.False(t, p.IsDue(now.Add(100*time.Hour)))
+}
@@ -41,6 +41,9 @@ type KeyInfo interface {
 	GetDescription() string
 	SetDescription(string)
 
+	GetRotationPolicy() *KeyRotationPolicy
+	SetRotationPolicy(*KeyRotationPolicy)
+
 	GetCreatedAt() *time.Time
 	SetCreatedAt(time.Time)
 	GetUpdatedAt() *time.Time
@@ -55,6 +58,7 @@ func GetKeyMetadata(keyInfo KeyInfo) KeyMetadata {
 		KeyUsage:              keyInfo.GetKeyUsage(),
 		KeyState:              keyInfo.GetKeyState(),
 		Description:           keyInfo.GetDescription(),
+		RotationPolicy:        keyInfo.GetRotationPolicy(),
 	}
 }
 
@@ -67,6 +71,11 @@ type KeyVersionInfo interface {
 	GetSymmetricKeyBase64() string
 	SetSymmetricKeyBase64(string)
 
+	GetPublicKeyPem() string
+	SetPublicKeyPem(string)
+	GetPrivateKeyBase64() string
+	SetPrivateKeyBase64(string)
+
 	GetCreatedAt() *time.Time
 	SetCreatedAt(time.Time)
 
@@ -104,32 +113,37 @@ type Key struct {
 	KeyUsage          KeyUsage              `json:"keyUsage,omitempty"`
 	KeyState          KeyState              `json:"keyState,omitempty"`
 	Description       string                `json:"description,omitempty"`
+	RotationPolicy    *KeyRotationPolicy    `json:"rotationPolicy,omitempty"`
 	CreatedAt         *time.Time            `json:"createdAt,omitempty"`
 	UpdatedAt         *time.Time            `json:"updatedAt,omitempty"`
 }
 
-func (k *Key) New() KeyInfo                          { return &Key{} }
-func (k *Key) GetPluginKind() PluginKind             { return k.PluginKind }
-func (k *Key) SetPluginKind(pluginKind PluginKind)   { k.PluginKind = pluginKind }
-func (k *Key) GetKeyID() string                      { return k.KeyID }
-func (k *Key) SetKeyID(keyID string)                 { k.KeyID = keyID }
-func (k *Key) GetKeySpec() CustomerMasterKeySpec     { return k.KeySpec }
-func (k *Key) SetKeySpec(spec CustomerMasterKeySpec) { k.KeySpec = spec }
-func (k *Key) GetKeyUsage() KeyUsage                 { return k.KeyUsage }
-func (k *Key) SetKeyUsage(usage KeyUsage)            { k.KeyUsage = usage }
-func (k *Key) GetKeyState() KeyState                 { return k.KeyState }
-func (k *Key) SetKeyState(state KeyState)            { k.KeyState = state }
-func (k *Key) GetDescription() string                { return k.Description }
-func (k *Key) SetDescription(desc string)            { k.Description = desc }
-func (k *Key) GetCreatedAt() *time.Time              { return k.CreatedAt }
-func (k *Key) SetCreatedAt(t time.Time)              { k.CreatedAt = &t }
-func (k *Key) GetUpdatedAt() *time.Time              { return k.UpdatedAt }
-func (k *Key) SetUpdatedAt(t time.Time)              { k.UpdatedAt = &t }
-func (k *Key) GetPrimaryKeyVersion() KeyVersionInfo  { return &k.PrimaryKeyVersion }
+func (k *Key) New() KeyInfo                           { return &Key{} }
+func (k *Key) GetPluginKind() PluginKind              { return k.PluginKind }
+func (k *Key) SetPluginKind(pluginKind PluginKind)    { k.PluginKind = pluginKind }
+func (k *Key) GetKeyID() string                       { return k.KeyID }
+func (k *Key) SetKeyID(keyID string)                  { k.KeyID = keyID }
+func (k *Key) GetKeySpec() CustomerMasterKeySpec      { return k.KeySpec }
+func (k *Key) SetKeySpec(spec CustomerMasterKeySpec)  { k.KeySpec = spec }
+func (k *Key) GetKeyUsage() KeyUsage                  { return k.KeyUsage }
+func (k *Key) SetKeyUsage(usage KeyUsage)             { k.KeyUsage = usage }
+func (k *Key) GetKeyState() KeyState                  { return k.KeyState }
+func (k *Key) SetKeyState(state KeyState)             { k.KeyState = state }
+func (k *Key) GetDescription() string                 { return k.Description }
+func (k *Key) SetDescription(desc string)             { k.Description = desc }
+func (k *Key) GetRotationPolicy() *KeyRotationPolicy  { return k.RotationPolicy }
+func (k *Key) SetRotationPolicy(p *KeyRotationPolicy) { k.RotationPolicy = p }
+func (k *Key) GetCreatedAt() *time.Time               { return k.CreatedAt }
+func (k *Key) SetCreatedAt(t time.Time)               { k.CreatedAt = &t }
+func (k *Key) GetUpdatedAt() *time.Time               { return k.UpdatedAt }
+func (k *Key) SetUpdatedAt(t time.Time)               { k.UpdatedAt = &t }
+func (k *Key) GetPrimaryKeyVersion() KeyVersionInfo   { return &k.PrimaryKeyVersion }
 func (k *Key) SetPrimaryKeyVersion(version KeyVersionInfo) {
 	k.PrimaryKeyVersion = KeyVersion{
 		VersionID:          version.GetVersionID(),
 		SymmetricKeyBase64: version.GetSymmetricKeyBase64(),
+		PublicKeyPem:       version.GetPublicKeyPem(),
+		PrivateKeyBase64:   version.GetPrivateKeyBase64(),
 		CreatedAt:          version.GetCreatedAt(),
 		UpdatedAt:          version.GetUpdatedAt(),
 	}
@@ -138,9 +152,12 @@ func (k *Key) SetPrimaryKeyVersion(version KeyVersionInfo) {
 type KeyVersion struct {
 	VersionID string `json:"versionID,omitempty"`
 	// base64 encoded
-	SymmetricKeyBase64 string     `json:"symmetricKeyBase64,omitempty"`
-	CreatedAt          *time.Time `json:"createdAt,omitempty"`
-	UpdatedAt          *time.Time `json:"updatedAt,omitempty"`
+	SymmetricKeyBase64 string `json:"symmetricKeyBase64,omitempty"`
+	// asymmetric key pair, public key is PEM encoded, private key is base64 encoded PKCS #8 DER
+	PublicKeyPem     string     `json:"publicKeyPem,omitempty"`
+	PrivateKeyBase64 string     `json:"privateKeyBase64,omitempty"`
+	CreatedAt        *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt        *time.Time `json:"updatedAt,omitempty"`
 }
 
 func (k *KeyVersion) New() KeyVersionInfo            { return &KeyVersion{} }
@@ -148,6 +165,10 @@ func (k *KeyVersion) GetVersionID() string           { return k.VersionID }
 func (k *KeyVersion) SetVersionID(s string)          { k.VersionID = s }
 func (k *KeyVersion) GetSymmetricKeyBase64() string  { return k.SymmetricKeyBase64 }
 func (k *KeyVersion) SetSymmetricKeyBase64(s string) { k.SymmetricKeyBase64 = s }
+func (k *KeyVersion) GetPublicKeyPem() string        { return k.PublicKeyPem }
+func (k *KeyVersion) SetPublicKeyPem(s string)       { k.PublicKeyPem = s }
+func (k *KeyVersion) GetPrivateKeyBase64() string    { return k.PrivateKeyBase64 }
+func (k *KeyVersion) SetPrivateKeyBase64(s string)   { k.PrivateKeyBase64 = s }
 func (k *KeyVersion) GetCreatedAt() *time.Time       { return k.CreatedAt }
 func (k *KeyVersion) SetCreatedAt(t time.Time)       { k.CreatedAt = &t }
 func (k *KeyVersion) GetUpdatedAt() *time.Time       { return k.UpdatedAt }
@@ -32,6 +32,10 @@ type BasePlugin interface {
 	CreateKey(ctx context.Context, req *CreateKeyRequest) (*CreateKeyResponse, error)
 	DescribeKey(ctx context.Context, req *DescribeKeyRequest) (*DescribeKeyResponse, error)
 	ListKeys(ctx context.Context, req *ListKeysRequest) (*ListKeysResponse, error)
+	// UpdateRotationPolicy enable or disable automatic rotation of CMK
+	UpdateRotationPolicy(ctx context.Context, req *UpdateRotationPolicyRequest) (*UpdateRotationPolicyResponse, error)
+	// ReEncrypt decrypts ciphertext encrypted by old key version and encrypts it by the primary key version
+	ReEncrypt(ctx context.Context, req *ReEncryptRequest) (*ReEncryptResponse, error)
 }
 
 // SymmetricPlugin 对称加密插件
@@ -58,14 +62,23 @@ type SymmetricPlugin interface {
 
 // AsymmetricPlugin 非对称加密插件
 // 加密流程：
-// 1. GetPublicKey 获取公钥
+// 1. GetPublicKey 获取公钥，或直接调用 AsymmetricEncrypt
 // 2. 使用公钥加密数据
 // 3. 存储加密后的数据以及密钥版本
 // 解密流程：
-// 1. 调用 AsymmetricDecrypt，传入密文和 解密
+// 1. 调用 AsymmetricDecrypt，传入密文和密钥版本进行解密
+// 签名流程：
+// 1. 调用 Sign 对消息或消息摘要签名，存储签名以及密钥版本
+// 2. 调用 Verify 验签，或使用 GetPublicKey 获取的公钥在本地验签
 type AsymmetricPlugin interface {
 	GetPublicKey(ctx context.Context, req *GetPublicKeyRequest) (*PublicKey, error)
+	// AsymmetricEncrypt encrypts data by the public key of the primary key version, key usage must be ENCRYPT_DECRYPT.
+	AsymmetricEncrypt(ctx context.Context, req *AsymmetricEncryptRequest) (*AsymmetricEncryptResponse, error)
 	// AsymmetricDecrypt decrypts data that was encrypted with a public key retrieved from GetPublicKey
 	// corresponding to a CryptoKeyVersion with CryptoKey.purpose ASYMMETRIC_DECRYPT.
 	AsymmetricDecrypt(ctx context.Context, req *AsymmetricDecryptRequest) (*AsymmetricDecryptResponse, error)
+	// Sign signs message or message digest by the private key of the primary key version, key usage must be SIGN_VERIFY.
+	Sign(ctx context.Context, req *SignRequest) (*SignResponse, error)
+	// Verify verifies signature by the public key of the specified key version.
+	Verify(ctx context.Context, req *VerifyRequest) (*VerifyResponse, error)
 }
@@ -34,6 +34,9 @@ type Store interface {
 	// GetKeyVersion use keyID and keyVersionID to find keyVersion
 	GetKeyVersion(keyID, keyVersionID string) (KeyVersionInfo, error)
 
-	// RotateKeyVersion rotate key version
+	// RotateKeyVersion rotate key version, update last and next rotation time of rotation policy if exists
 	RotateKeyVersion(keyID string, newKeyVersionInfo KeyVersionInfo) (KeyVersionInfo, error)
+
+	// UpdateKeyRotationPolicy update rotation policy of CMK
+	UpdateKeyRotationPolicy(keyID string, policy *KeyRotationPolicy) (KeyInfo, error)
 }
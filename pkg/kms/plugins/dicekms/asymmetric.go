@@ -0,0 +1,353 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dicekms
+
+import (
+	"context"
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/erda-project/erda/pkg/crypto/uuid"
+	"github.com/erda-project/erda/pkg/kms/kmscrypto"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+	"github.com/erda-project/erda/pkg/kms/log"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// signing algorithms supported by key spec, the first one is default
+var signingAlgorithms = map[kmstypes.CustomerMasterKeySpec][]kmstypes.SigningAlgorithmSpec{
+	kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_2048: {kmstypes.SigningAlgorithm_RSASSA_PSS_SHA_256, kmstypes.SigningAlgorithm_RSASSA_PKCS1_V1_5_SHA_256},
+	kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_3072: {kmstypes.SigningAlgorithm_RSASSA_PSS_SHA_256, kmstypes.SigningAlgorithm_RSASSA_PKCS1_V1_5_SHA_256},
+	kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_4096: {kmstypes.SigningAlgorithm_RSASSA_PSS_SHA_256, kmstypes.SigningAlgorithm_RSASSA_PKCS1_V1_5_SHA_256},
+	kmstypes.CustomerMasterKeySpec_ASYMMETRIC_EC_P256:  {kmstypes.SigningAlgorithm_ECDSA_SHA_256},
+	kmstypes.CustomerMasterKeySpec_ASYMMETRIC_EC_P384:  {kmstypes.SigningAlgorithm_ECDSA_SHA_384},
+}
+
+// max length of RAW message to sign, sign digest for larger data
+const maxRawMessageLen = 4096
+
+// checkKeySpecAndUsage EC key pair only supports SIGN_VERIFY, symmetric key only supports ENCRYPT_DECRYPT
+func checkKeySpecAndUsage(spec kmstypes.CustomerMasterKeySpec, usage kmstypes.KeyUsage) error {
+	switch {
+	case spec.IsSymmetric():
+		if usage != kmstypes.KeyUsage_ENCRYPT_DECRYPT {
+			return fmt.Errorf("not supported key usage: %s for key spec: %s", usage, spec)
+		}
+	case spec.IsRSA():
+		if usage != kmstypes.KeyUsage_ENCRYPT_DECRYPT && usage != kmstypes.KeyUsage_SIGN_VERIFY {
+			return fmt.Errorf("not supported key usage: %s for key spec: %s", usage, spec)
+		}
+	case spec.IsECC():
+		if usage != kmstypes.KeyUsage_SIGN_VERIFY {
+			return fmt.Errorf("not supported key usage: %s for key spec: %s", usage, spec)
+		}
+	default:
+		return fmt.Errorf("not supported key spec: %s", spec)
+	}
+	return nil
+}
+
+// checkStoreSupportsKeySpec only the etcd store (and the memory store for test) persists the key pairs of asymmetric keys
+func checkStoreSupportsKeySpec(kind kmstypes.StoreKind, spec kmstypes.CustomerMasterKeySpec) error {
+	if spec.IsSymmetric() || kind == kmstypes.StoreKind_ETCD || kind == kmstypes.StoreKind_MEMORY {
+		return nil
+	}
+	return fmt.Errorf("key spec: %s is not supported by store: %s, asymmetric keys require store: %s",
+		spec, kind, kmstypes.StoreKind_ETCD)
+}
+
+// generateKeyVersion generate new key version with symmetric key or key pair according to key spec
+func generateKeyVersion(spec kmstypes.CustomerMasterKeySpec) (kmstypes.KeyVersion, error) {
+	keyVersion := kmstypes.KeyVersion{
+		VersionID: uuid.UUID(),
+	}
+	if spec.IsSymmetric() {
+		symmetricKeyBytes, err := kmscrypto.GenerateAes256Key()
+		if err != nil {
+			return keyVersion, fmt.Errorf("failed to generate symmetric key, err: %v", err)
+		}
+		keyVersion.SymmetricKeyBase64 = base64.StdEncoding.EncodeToString(symmetricKeyBytes)
+		return keyVersion, nil
+	}
+
+	var (
+		privateKey crypto.Signer
+		err        error
+	)
+	switch spec {
+	case kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_2048:
+		privateKey, err = kmscrypto.GenerateRsaKey(2048)
+	case kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_3072:
+		privateKey, err = kmscrypto.GenerateRsaKey(3072)
+	case kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_4096:
+		privateKey, err = kmscrypto.GenerateRsaKey(4096)
+	case kmstypes.CustomerMasterKeySpec_ASYMMETRIC_EC_P256:
+		privateKey, err = kmscrypto.GenerateEcdsaKey(elliptic.P256())
+	case kmstypes.CustomerMasterKeySpec_ASYMMETRIC_EC_P384:
+		privateKey, err = kmscrypto.GenerateEcdsaKey(elliptic.P384())
+	default:
+		return keyVersion, fmt.Errorf("not supported key spec: %s", spec)
+	}
+	if err != nil {
+		return keyVersion, fmt.Errorf("failed to generate key pair, err: %v", err)
+	}
+	privateKeyDER, err := kmscrypto.MarshalPrivateKey(privateKey)
+	if err != nil {
+		return keyVersion, fmt.Errorf("failed to marshal private key, err: %v", err)
+	}
+	publicKeyPem, err := kmscrypto.MarshalPublicKeyPem(privateKey.Public())
+	if err != nil {
+		return keyVersion, fmt.Errorf("failed to marshal public key, err: %v", err)
+	}
+	keyVersion.PrivateKeyBase64 = base64.StdEncoding.EncodeToString(privateKeyDER)
+	keyVersion.PublicKeyPem = publicKeyPem
+	return keyVersion, nil
+}
+
+// getAsymmetricKey return key info and specified key version, use primary key version if keyVersionID is empty
+func (d *Dice) getAsymmetricKey(keyID, keyVersionID string, usage kmstypes.KeyUsage) (kmstypes.KeyInfo, kmstypes.KeyVersionInfo, error) {
+	keyInfo, err := d.store.GetKey(keyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if keyInfo.GetKeySpec().IsSymmetric() {
+		return nil, nil, fmt.Errorf("key spec %s is not asymmetric", keyInfo.GetKeySpec())
+	}
+	if usage != "" && keyInfo.GetKeyUsage() != usage {
+		return nil, nil, fmt.Errorf("key usage is %s, expect: %s", keyInfo.GetKeyUsage(), usage)
+	}
+	if keyVersionID == "" || keyVersionID == keyInfo.GetPrimaryKeyVersion().GetVersionID() {
+		return keyInfo, keyInfo.GetPrimaryKeyVersion(), nil
+	}
+	keyVersionInfo, err := d.store.GetKeyVersion(keyInfo.GetKeyID(), keyVersionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return keyInfo, keyVersionInfo, nil
+}
+
+// getSigningAlgorithm return default signing algorithm of key spec if alg is empty
+func getSigningAlgorithm(spec kmstypes.CustomerMasterKeySpec, alg kmstypes.SigningAlgorithmSpec) (kmstypes.SigningAlgorithmSpec, error) {
+	algs := signingAlgorithms[spec]
+	if len(algs) == 0 {
+		return "", fmt.Errorf("key spec %s not supports sign", spec)
+	}
+	if alg == "" {
+		return algs[0], nil
+	}
+	for _, a := range algs {
+		if a == alg {
+			return alg, nil
+		}
+	}
+	return "", fmt.Errorf("signing algorithm %s not supported by key spec %s", alg, spec)
+}
+
+// getDigest return hash, pss and the digest of message
+func getDigest(alg kmstypes.SigningAlgorithmSpec, messageType kmstypes.MessageType, messageBase64 string) (crypto.Hash, bool, []byte, error) {
+	var (
+		hash crypto.Hash
+		pss  bool
+	)
+	switch alg {
+	case kmstypes.SigningAlgorithm_RSASSA_PSS_SHA_256:
+		hash, pss = crypto.SHA256, true
+	case kmstypes.SigningAlgorithm_RSASSA_PKCS1_V1_5_SHA_256, kmstypes.SigningAlgorithm_ECDSA_SHA_256:
+		hash = crypto.SHA256
+	case kmstypes.SigningAlgorithm_ECDSA_SHA_384:
+		hash = crypto.SHA384
+	default:
+		return 0, false, nil, fmt.Errorf("not supported signing algorithm: %s", alg)
+	}
+	message, err := base64.StdEncoding.DecodeString(messageBase64)
+	if err != nil {
+		return 0, false, nil, err
+	}
+	if messageType == kmstypes.MessageType_DIGEST {
+		return hash, pss, message, nil
+	}
+	if err := strutil.Validate(string(message), strutil.MaxLenValidator(maxRawMessageLen)); err != nil {
+		return 0, false, nil, fmt.Errorf("raw message too large, use digest instead, err: %v", err)
+	}
+	h := hash.New()
+	h.Write(message)
+	return hash, pss, h.Sum(nil), nil
+}
+
+func parsePrivateKey(keyVersionInfo kmstypes.KeyVersionInfo) (crypto.Signer, error) {
+	der, err := base64.StdEncoding.DecodeString(keyVersionInfo.GetPrivateKeyBase64())
+	if err != nil {
+		return nil, err
+	}
+	return kmscrypto.ParsePrivateKey(der)
+}
+
+func (d *Dice) GetPublicKey(ctx context.Context, req *kmstypes.GetPublicKeyRequest) (*kmstypes.PublicKey, error) {
+	keyInfo, keyVersionInfo, err := d.getAsymmetricKey(req.KeyID, req.KeyVersionID, "")
+	if err != nil {
+		return nil, err
+	}
+	resp := kmstypes.PublicKey{
+		KeyID:        keyInfo.GetKeyID(),
+		KeyVersionID: keyVersionInfo.GetVersionID(),
+		Pem:          keyVersionInfo.GetPublicKeyPem(),
+		Algorithm:    string(keyInfo.GetKeySpec()),
+		KeyUsage:     keyInfo.GetKeyUsage(),
+	}
+	switch keyInfo.GetKeyUsage() {
+	case kmstypes.KeyUsage_ENCRYPT_DECRYPT:
+		resp.EncryptionAlgorithms = []kmstypes.EncryptionAlgorithmSpec{kmstypes.EncryptionAlgorithm_RSAES_OAEP_SHA_256}
+	case kmstypes.KeyUsage_SIGN_VERIFY:
+		resp.SigningAlgorithms = signingAlgorithms[keyInfo.GetKeySpec()]
+	}
+	return &resp, nil
+}
+
+func (d *Dice) AsymmetricEncrypt(ctx context.Context, req *kmstypes.AsymmetricEncryptRequest) (*kmstypes.AsymmetricEncryptResponse, error) {
+	plaintextBytes, err := base64.StdEncoding.DecodeString(req.PlaintextBase64)
+	if err != nil {
+		return nil, err
+	}
+
+	keyInfo, keyVersionInfo, err := d.getAsymmetricKey(req.KeyID, "", kmstypes.KeyUsage_ENCRYPT_DECRYPT)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := kmscrypto.ParsePublicKeyPem(keyVersionInfo.GetPublicKeyPem())
+	if err != nil {
+		return nil, err
+	}
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("key spec %s not supports encrypt", keyInfo.GetKeySpec())
+	}
+	ciphertext, err := kmscrypto.RsaOaepEncrypt(rsaPub, plaintextBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kmstypes.AsymmetricEncryptResponse{
+		KeyID:               req.KeyID,
+		KeyVersionID:        keyVersionInfo.GetVersionID(),
+		EncryptionAlgorithm: kmstypes.EncryptionAlgorithm_RSAES_OAEP_SHA_256,
+		CiphertextBase64:    base64.StdEncoding.EncodeToString(ciphertext),
+	}, nil
+}
+
+func (d *Dice) AsymmetricDecrypt(ctx context.Context, req *kmstypes.AsymmetricDecryptRequest) (resp *kmstypes.AsymmetricDecryptResponse, err error) {
+	log.WithTraceID(ctx).Infof("asymmetric decrypt request, keyID: %s, keyVersionID: %s", req.KeyID, req.KeyVersionID)
+
+	_, keyVersionInfo, kerr := d.getAsymmetricKey(req.KeyID, req.KeyVersionID, kmstypes.KeyUsage_ENCRYPT_DECRYPT)
+	if kerr != nil {
+		return nil, kerr
+	}
+
+	defer func() {
+		// not expose concrete error to frontend, log err and return `broken ciphertext`
+		if err != nil {
+			log.WithTraceID(ctx).Errorf("parse ciphertext failed, err: %v", err)
+			resp = nil
+			err = fmt.Errorf("broken ciphertext")
+		}
+	}()
+
+	ciphertextBytes, err := base64.StdEncoding.DecodeString(req.CiphertextBase64)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := parsePrivateKey(keyVersionInfo)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := privateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not rsa key")
+	}
+	plaintextBytes, err := kmscrypto.RsaOaepDecrypt(rsaKey, ciphertextBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &kmstypes.AsymmetricDecryptResponse{PlaintextBase64: base64.StdEncoding.EncodeToString(plaintextBytes)}
+	return resp, nil
+}
+
+func (d *Dice) Sign(ctx context.Context, req *kmstypes.SignRequest) (*kmstypes.SignResponse, error) {
+	keyInfo, keyVersionInfo, err := d.getAsymmetricKey(req.KeyID, "", kmstypes.KeyUsage_SIGN_VERIFY)
+	if err != nil {
+		return nil, err
+	}
+	alg, err := getSigningAlgorithm(keyInfo.GetKeySpec(), req.SigningAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	hash, pss, digest, err := getDigest(alg, req.MessageType, req.MessageBase64)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := parsePrivateKey(keyVersionInfo)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := kmscrypto.SignDigest(privateKey, hash, pss, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kmstypes.SignResponse{
+		KeyID:            req.KeyID,
+		KeyVersionID:     keyVersionInfo.GetVersionID(),
+		SigningAlgorithm: alg,
+		SignatureBase64:  base64.StdEncoding.EncodeToString(signature),
+	}, nil
+}
+
+func (d *Dice) Verify(ctx context.Context, req *kmstypes.VerifyRequest) (*kmstypes.VerifyResponse, error) {
+	keyInfo, keyVersionInfo, err := d.getAsymmetricKey(req.KeyID, req.KeyVersionID, kmstypes.KeyUsage_SIGN_VERIFY)
+	if err != nil {
+		return nil, err
+	}
+	alg, err := getSigningAlgorithm(keyInfo.GetKeySpec(), req.SigningAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	hash, pss, digest, err := getDigest(alg, req.MessageType, req.MessageBase64)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.StdEncoding.DecodeString(req.SignatureBase64)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := kmscrypto.ParsePublicKeyPem(keyVersionInfo.GetPublicKeyPem())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := kmstypes.VerifyResponse{
+		KeyID:            req.KeyID,
+		KeyVersionID:     keyVersionInfo.GetVersionID(),
+		SigningAlgorithm: alg,
+	}
+	if err := kmscrypto.VerifyDigest(pub, hash, pss, digest, signature); err != nil {
+		log.WithTraceID(ctx).Infof("invalid signature, keyID: %s, keyVersionID: %s, err: %v", req.KeyID, keyVersionInfo.GetVersionID(), err)
+		return &resp, nil
+	}
+	resp.SignatureValid = true
+	return &resp, nil
+}
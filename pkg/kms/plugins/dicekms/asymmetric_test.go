@@ -0,0 +1,210 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dicekms
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/pkg/kms/kmscrypto"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+	"github.com/erda-project/erda/pkg/kms/stores/memory"
+)
+
+func newTestDice() *Dice {
+	d := &Dice{}
+	d.SetStore(memory.New())
+	return d
+}
+
+func TestCreateKeySpecAndUsage(t *testing.T) {
+	d := newTestDice()
+	ctx := context.Background()
+	cases := []struct {
+		spec    kmstypes.CustomerMasterKeySpec
+		usage   kmstypes.KeyUsage
+		wantErr bool
+	}{
+		{kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT, kmstypes.KeyUsage_ENCRYPT_DECRYPT, false},
+		{kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT, kmstypes.KeyUsage_SIGN_VERIFY, true},
+		{kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_2048, kmstypes.KeyUsage_ENCRYPT_DECRYPT, false},
+		{kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_2048, kmstypes.KeyUsage_SIGN_VERIFY, false},
+		{kmstypes.CustomerMasterKeySpec_ASYMMETRIC_EC_P256, kmstypes.KeyUsage_SIGN_VERIFY, false},
+		{kmstypes.CustomerMasterKeySpec_ASYMMETRIC_EC_P256, kmstypes.KeyUsage_ENCRYPT_DECRYPT, true},
+		{"RSA_1024", kmstypes.KeyUsage_SIGN_VERIFY, true},
+	}
+	for _, c := range cases {
+		_, err := d.CreateKey(ctx, &kmstypes.CreateKeyRequest{
+			PluginKind:            kmstypes.PluginKind_DICE_KMS,
+			CustomerMasterKeySpec: c.spec,
+			KeyUsage:              c.usage,
+		})
+		assert.Equal(t, c.wantErr, err != nil, "%s %s", c.spec, c.usage)
+	}
+}
+
+type mysqlStore struct {
+	*memory.Store
+}
+
+func (s *mysqlStore) GetKind() kmstypes.StoreKind {
+	return kmstypes.StoreKind_MYSQL
+}
+
+func TestCreateAsymmetricKeyUnsupportedStore(t *testing.T) {
+	d := &Dice{}
+	d.SetStore(&mysqlStore{Store: memory.New()})
+	ctx := context.Background()
+	_, err := d.CreateKey(ctx, &kmstypes.CreateKeyRequest{
+		PluginKind:            kmstypes.PluginKind_DICE_KMS,
+		CustomerMasterKeySpec: kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_2048,
+		KeyUsage:              kmstypes.KeyUsage_SIGN_VERIFY,
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not supported by store: MYSQL")
+
+	_, err = d.CreateKey(ctx, &kmstypes.CreateKeyRequest{
+		PluginKind:            kmstypes.PluginKind_DICE_KMS,
+		CustomerMasterKeySpec: kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT,
+		KeyUsage:              kmstypes.KeyUsage_ENCRYPT_DECRYPT,
+	})
+	assert.NoError(t, err)
+}
+
+func TestAsymmetricEncryptDecrypt(t *testing.T) {
+	d := newTestDice()
+	ctx := context.Background()
+	createResp, err := d.CreateKey(ctx, &kmstypes.CreateKeyRequest{
+		PluginKind:            kmstypes.PluginKind_DICE_KMS,
+		CustomerMasterKeySpec: kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_2048,
+		KeyUsage:              kmstypes.KeyUsage_ENCRYPT_DECRYPT,
+	})
+	assert.NoError(t, err)
+	keyID := createResp.KeyMetadata.KeyID
+
+	// symmetric api not supported
+	_, err = d.Encrypt(ctx, &kmstypes.EncryptRequest{KeyID: keyID, PlaintextBase64: "aGVsbG8="})
+	assert.Error(t, err)
+
+	pub, err := d.GetPublicKey(ctx, &kmstypes.GetPublicKeyRequest{KeyID: keyID})
+	assert.NoError(t, err)
+	assert.Equal(t, createResp.KeyMetadata.PrimaryKeyVersionID, pub.KeyVersionID)
+	assert.Equal(t, "RSA_2048", pub.Algorithm)
+	_, err = kmscrypto.ParsePublicKeyPem(pub.Pem)
+	assert.NoError(t, err)
+
+	encryptResp, err := d.AsymmetricEncrypt(ctx, &kmstypes.AsymmetricEncryptRequest{KeyID: keyID, PlaintextBase64: "aGVsbG8="})
+	assert.NoError(t, err)
+	assert.Equal(t, pub.KeyVersionID, encryptResp.KeyVersionID)
+
+	// rotate, old ciphertext can still be decrypted with old key version
+	rotateResp, err := d.RotateKeyVersion(ctx, &kmstypes.RotateKeyVersionRequest{KeyID: keyID})
+	assert.NoError(t, err)
+	assert.NotEqual(t, pub.KeyVersionID, rotateResp.KeyMetadata.PrimaryKeyVersionID)
+	newPub, err := d.GetPublicKey(ctx, &kmstypes.GetPublicKeyRequest{KeyID: keyID})
+	assert.NoError(t, err)
+	assert.NotEqual(t, pub.Pem, newPub.Pem)
+
+	decryptResp, err := d.AsymmetricDecrypt(ctx, &kmstypes.AsymmetricDecryptRequest{
+		KeyID:            keyID,
+		KeyVersionID:     encryptResp.KeyVersionID,
+		CiphertextBase64: encryptResp.CiphertextBase64,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "aGVsbG8=", decryptResp.PlaintextBase64)
+
+	// decrypt by primary key version
+	_, err = d.AsymmetricDecrypt(ctx, &kmstypes.AsymmetricDecryptRequest{KeyID: keyID, CiphertextBase64: encryptResp.CiphertextBase64})
+	assert.EqualError(t, err, "broken ciphertext")
+
+	// sign not supported
+	_, err = d.Sign(ctx, &kmstypes.SignRequest{KeyID: keyID, MessageType: kmstypes.MessageType_RAW, MessageBase64: "aGVsbG8="})
+	assert.Error(t, err)
+}
+
+func TestSignVerify(t *testing.T) {
+	d := newTestDice()
+	ctx := context.Background()
+	digest := sha256.Sum256([]byte("release artifact"))
+	digestBase64 := base64.StdEncoding.EncodeToString(digest[:])
+
+	for _, spec := range []kmstypes.CustomerMasterKeySpec{
+		kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_2048,
+		kmstypes.CustomerMasterKeySpec_ASYMMETRIC_EC_P256,
+	} {
+		createResp, err := d.CreateKey(ctx, &kmstypes.CreateKeyRequest{
+			PluginKind:            kmstypes.PluginKind_DICE_KMS,
+			CustomerMasterKeySpec: spec,
+			KeyUsage:              kmstypes.KeyUsage_SIGN_VERIFY,
+		})
+		assert.NoError(t, err)
+		keyID := createResp.KeyMetadata.KeyID
+
+		for _, alg := range signingAlgorithms[spec] {
+			signResp, err := d.Sign(ctx, &kmstypes.SignRequest{
+				KeyID:            keyID,
+				SigningAlgorithm: alg,
+				MessageType:      kmstypes.MessageType_DIGEST,
+				MessageBase64:    digestBase64,
+			})
+			assert.NoError(t, err, alg)
+			assert.Equal(t, alg, signResp.SigningAlgorithm)
+
+			// signature of digest equals to signature of raw message
+			verifyResp, err := d.Verify(ctx, &kmstypes.VerifyRequest{
+				KeyID:            keyID,
+				KeyVersionID:     signResp.KeyVersionID,
+				SigningAlgorithm: alg,
+				MessageType:      kmstypes.MessageType_RAW,
+				MessageBase64:    base64.StdEncoding.EncodeToString([]byte("release artifact")),
+				SignatureBase64:  signResp.SignatureBase64,
+			})
+			assert.NoError(t, err, alg)
+			assert.True(t, verifyResp.SignatureValid, alg)
+
+			verifyResp, err = d.Verify(ctx, &kmstypes.VerifyRequest{
+				KeyID:            keyID,
+				SigningAlgorithm: alg,
+				MessageType:      kmstypes.MessageType_RAW,
+				MessageBase64:    base64.StdEncoding.EncodeToString([]byte("tampered artifact")),
+				SignatureBase64:  signResp.SignatureBase64,
+			})
+			assert.NoError(t, err, alg)
+			assert.False(t, verifyResp.SignatureValid, alg)
+		}
+
+		// default algorithm
+		signResp, err := d.Sign(ctx, &kmstypes.SignRequest{KeyID: keyID, MessageType: kmstypes.MessageType_DIGEST, MessageBase64: digestBase64})
+		assert.NoError(t, err)
+		assert.Equal(t, signingAlgorithms[spec][0], signResp.SigningAlgorithm)
+
+		// algorithm not match key spec
+		_, err = d.Sign(ctx, &kmstypes.SignRequest{
+			KeyID:            keyID,
+			SigningAlgorithm: kmstypes.SigningAlgorithm_ECDSA_SHA_384,
+			MessageType:      kmstypes.MessageType_DIGEST,
+			MessageBase64:    digestBase64,
+		})
+		assert.Error(t, err)
+
+		// encrypt not supported
+		_, err = d.AsymmetricEncrypt(ctx, &kmstypes.AsymmetricEncryptRequest{KeyID: keyID, PlaintextBase64: "aGVsbG8="})
+		assert.Error(t, err)
+	}
+}
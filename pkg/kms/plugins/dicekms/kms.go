@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -63,28 +64,18 @@ func (d *Dice) CreateKey(ctx context.Context, req *kmstypes.CreateKeyRequest) (*
 		return nil, fmt.Errorf("invalid pluginKind: %s, expect: %s", req.PluginKind, kmstypes.PluginKind_DICE_KMS)
 	}
 
-	// key spec
-	if req.CustomerMasterKeySpec != kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT {
-		return nil, fmt.Errorf("not supported key spec: %s", req.CustomerMasterKeySpec)
+	// key spec and key usage
+	if err := checkKeySpecAndUsage(req.CustomerMasterKeySpec, req.KeyUsage); err != nil {
+		return nil, err
 	}
-
-	// key usage
-	if req.KeyUsage != kmstypes.KeyUsage_ENCRYPT_DECRYPT {
-		return nil, fmt.Errorf("not supported key usage: %s", req.KeyUsage)
+	if err := checkStoreSupportsKeySpec(d.store.GetKind(), req.CustomerMasterKeySpec); err != nil {
+		return nil, err
 	}
 
 	// write key to store
-	primaryKeyVersion := kmstypes.KeyVersion{
-		VersionID: uuid.UUID(),
-	}
-	switch req.CustomerMasterKeySpec {
-	case kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT:
-		symmetricKeyBytes, err := kmscrypto.GenerateAes256Key()
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate symmetric key, err: %v", err)
-		}
-		symmetricKeyBase64 := base64.StdEncoding.EncodeToString(symmetricKeyBytes)
-		primaryKeyVersion.SymmetricKeyBase64 = symmetricKeyBase64
+	primaryKeyVersion, err := generateKeyVersion(req.CustomerMasterKeySpec)
+	if err != nil {
+		return nil, err
 	}
 	key := kmstypes.Key{
 		PluginKind:        kmstypes.PluginKind_DICE_KMS,
@@ -95,7 +86,7 @@ func (d *Dice) CreateKey(ctx context.Context, req *kmstypes.CreateKeyRequest) (*
 		KeyState:          kmstypes.KeyStateEnabled,
 		Description:       req.Description,
 	}
-	err := d.store.CreateKey(&key)
+	err = d.store.CreateKey(&key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create key in store, err: %v", err)
 	}
@@ -146,6 +137,9 @@ func (d *Dice) Encrypt(ctx context.Context, req *kmstypes.EncryptRequest) (*kmst
 	if err != nil {
 		return nil, err
 	}
+	if !keyInfo.GetKeySpec().IsSymmetric() {
+		return nil, fmt.Errorf("key spec %s not supports symmetric encrypt, use asymmetric encrypt instead", keyInfo.GetKeySpec())
+	}
 
 	// encrypt
 	additionalData := additionalData{
@@ -186,6 +180,9 @@ func (d *Dice) Decrypt(ctx context.Context, req *kmstypes.DecryptRequest) (resp
 	if kerr != nil {
 		return nil, kerr
 	}
+	if !keyInfo.GetKeySpec().IsSymmetric() {
+		return nil, fmt.Errorf("key spec %s not supports symmetric decrypt, use asymmetric decrypt instead", keyInfo.GetKeySpec())
+	}
 
 	defer func() {
 		// not expose concrete error to frontend, log err and return `broken ciphertext`
@@ -268,14 +265,16 @@ func (d *Dice) GenerateDataKey(ctx context.Context, req *kmstypes.GenerateDataKe
 }
 
 func (d *Dice) RotateKeyVersion(ctx context.Context, req *kmstypes.RotateKeyVersionRequest) (*kmstypes.RotateKeyVersionResponse, error) {
-	// generate new symmetric key
-	symmetricKey, err := kmscrypto.GenerateAes256Key()
+	// key info
+	keyInfo, err := d.store.GetKey(req.KeyID)
 	if err != nil {
 		return nil, err
 	}
-	newKeyVersion := kmstypes.KeyVersion{
-		VersionID:          uuid.UUID(),
-		SymmetricKeyBase64: base64.StdEncoding.EncodeToString(symmetricKey),
+
+	// generate new symmetric key or key pair according to key spec
+	newKeyVersion, err := generateKeyVersion(keyInfo.GetKeySpec())
+	if err != nil {
+		return nil, err
 	}
 
 	// rotate key version
@@ -283,7 +282,7 @@ func (d *Dice) RotateKeyVersion(ctx context.Context, req *kmstypes.RotateKeyVers
 	if err != nil {
 		return nil, err
 	}
-	keyInfo, err := d.store.GetKey(req.KeyID)
+	keyInfo, err = d.store.GetKey(req.KeyID)
 	if err != nil {
 		return nil, err
 	}
@@ -292,10 +291,92 @@ func (d *Dice) RotateKeyVersion(ctx context.Context, req *kmstypes.RotateKeyVers
 	return &resp, nil
 }
 
-func (d *Dice) GetPublicKey(ctx context.Context, req *kmstypes.GetPublicKeyRequest) (*kmstypes.PublicKey, error) {
-	panic("implement me")
+func (d *Dice) UpdateRotationPolicy(ctx context.Context, req *kmstypes.UpdateRotationPolicyRequest) (*kmstypes.UpdateRotationPolicyResponse, error) {
+	keyInfo, err := d.store.GetKey(req.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := kmstypes.KeyRotationPolicy{
+		Enabled:          req.EnableAutomaticRotation,
+		RotationInterval: req.RotationInterval,
+	}
+	if oldPolicy := keyInfo.GetRotationPolicy(); oldPolicy != nil {
+		policy.LastRotationTime = oldPolicy.LastRotationTime
+		// keep old interval when disable automatic rotation
+		if policy.RotationInterval == "" {
+			policy.RotationInterval = oldPolicy.RotationInterval
+		}
+	}
+	// next rotation starts from now
+	policy.Schedule(time.Now())
+
+	keyInfo, err = d.store.UpdateKeyRotationPolicy(req.KeyID, &policy)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := kmstypes.UpdateRotationPolicyResponse{KeyMetadata: kmstypes.GetKeyMetadata(keyInfo)}
+	return &resp, nil
 }
 
-func (d *Dice) AsymmetricDecrypt(ctx context.Context, req *kmstypes.AsymmetricDecryptRequest) (*kmstypes.AsymmetricDecryptResponse, error) {
-	panic("implement me")
+func (d *Dice) ReEncrypt(ctx context.Context, req *kmstypes.ReEncryptRequest) (*kmstypes.ReEncryptResponse, error) {
+	keyInfo, err := d.store.GetKey(req.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// asymmetric ciphertext doesn't contain key version
+	if !keyInfo.GetKeySpec().IsSymmetric() {
+		decryptResp, err := d.AsymmetricDecrypt(ctx, &kmstypes.AsymmetricDecryptRequest{
+			KeyID:            req.KeyID,
+			KeyVersionID:     req.SourceKeyVersionID,
+			CiphertextBase64: req.CiphertextBase64,
+		})
+		if err != nil {
+			return nil, err
+		}
+		encryptResp, err := d.AsymmetricEncrypt(ctx, &kmstypes.AsymmetricEncryptRequest{
+			KeyID:           req.KeyID,
+			PlaintextBase64: decryptResp.PlaintextBase64,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &kmstypes.ReEncryptResponse{
+			KeyID:            req.KeyID,
+			KeyVersionID:     encryptResp.KeyVersionID,
+			CiphertextBase64: encryptResp.CiphertextBase64,
+		}, nil
+	}
+
+	decryptResp, err := d.Decrypt(ctx, &kmstypes.DecryptRequest{
+		KeyID:            req.KeyID,
+		CiphertextBase64: req.CiphertextBase64,
+	})
+	if err != nil {
+		return nil, err
+	}
+	encryptResp, err := d.Encrypt(ctx, &kmstypes.EncryptRequest{
+		KeyID:           req.KeyID,
+		PlaintextBase64: decryptResp.PlaintextBase64,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// parse keyVersionID from new ciphertext
+	ciphertextBytes, err := base64.StdEncoding.DecodeString(encryptResp.CiphertextBase64)
+	if err != nil {
+		return nil, err
+	}
+	keyVersionIDBytes, _, err := kmscrypto.PrefixUnAppend000Length(ciphertextBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kmstypes.ReEncryptResponse{
+		KeyID:            req.KeyID,
+		KeyVersionID:     string(keyVersionIDBytes),
+		CiphertextBase64: encryptResp.CiphertextBase64,
+	}, nil
 }
@@ -0,0 +1,117 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dicekms
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+func TestUpdateRotationPolicy(t *testing.T) {
+	d := newTestDice()
+	ctx := context.Background()
+	createResp, err := d.CreateKey(ctx, &kmstypes.CreateKeyRequest{
+		PluginKind:            kmstypes.PluginKind_DICE_KMS,
+		CustomerMasterKeySpec: kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT,
+		KeyUsage:              kmstypes.KeyUsage_ENCRYPT_DECRYPT,
+	})
+	assert.NoError(t, err)
+	keyID := createResp.KeyMetadata.KeyID
+	assert.Nil(t, createResp.KeyMetadata.RotationPolicy)
+
+	before := time.Now()
+	policyResp, err := d.UpdateRotationPolicy(ctx, &kmstypes.UpdateRotationPolicyRequest{
+		KeyID:                   keyID,
+		EnableAutomaticRotation: true,
+		RotationInterval:        "30d",
+	})
+	assert.NoError(t, err)
+	policy := policyResp.KeyMetadata.RotationPolicy
+	assert.True(t, policy.Enabled)
+	assert.False(t, policy.NextRotationTime.Before(before.Add(30*24*time.Hour)))
+	assert.False(t, policy.IsDue(time.Now()))
+	assert.True(t, policy.IsDue(policy.NextRotationTime.Add(time.Second)))
+
+	// manual rotation postpones next rotation
+	rotateResp, err := d.RotateKeyVersion(ctx, &kmstypes.RotateKeyVersionRequest{KeyID: keyID})
+	assert.NoError(t, err)
+	rotated := rotateResp.KeyMetadata.RotationPolicy
+	assert.NotNil(t, rotated.LastRotationTime)
+	assert.False(t, rotated.NextRotationTime.Before(*policy.NextRotationTime))
+
+	// disable
+	policyResp, err = d.UpdateRotationPolicy(ctx, &kmstypes.UpdateRotationPolicyRequest{KeyID: keyID})
+	assert.NoError(t, err)
+	policy = policyResp.KeyMetadata.RotationPolicy
+	assert.False(t, policy.Enabled)
+	assert.Equal(t, "30d", policy.RotationInterval)
+	assert.Nil(t, policy.NextRotationTime)
+	assert.Equal(t, rotated.LastRotationTime.Unix(), policy.LastRotationTime.Unix())
+}
+
+func TestReEncrypt(t *testing.T) {
+	d := newTestDice()
+	ctx := context.Background()
+
+	// symmetric
+	createResp, err := d.CreateKey(ctx, &kmstypes.CreateKeyRequest{
+		PluginKind:            kmstypes.PluginKind_DICE_KMS,
+		CustomerMasterKeySpec: kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT,
+		KeyUsage:              kmstypes.KeyUsage_ENCRYPT_DECRYPT,
+	})
+	assert.NoError(t, err)
+	keyID := createResp.KeyMetadata.KeyID
+	encryptResp, err := d.Encrypt(ctx, &kmstypes.EncryptRequest{KeyID: keyID, PlaintextBase64: "aGVsbG8="})
+	assert.NoError(t, err)
+	rotateResp, err := d.RotateKeyVersion(ctx, &kmstypes.RotateKeyVersionRequest{KeyID: keyID})
+	assert.NoError(t, err)
+
+	reEncryptResp, err := d.ReEncrypt(ctx, &kmstypes.ReEncryptRequest{KeyID: keyID, CiphertextBase64: encryptResp.CiphertextBase64})
+	assert.NoError(t, err)
+	assert.Equal(t, rotateResp.KeyMetadata.PrimaryKeyVersionID, reEncryptResp.KeyVersionID)
+	assert.NotEqual(t, encryptResp.CiphertextBase64, reEncryptResp.CiphertextBase64)
+	decryptResp, err := d.Decrypt(ctx, &kmstypes.DecryptRequest{KeyID: keyID, CiphertextBase64: reEncryptResp.CiphertextBase64})
+	assert.NoError(t, err)
+	assert.Equal(t, "aGVsbG8=", decryptResp.PlaintextBase64)
+
+	// asymmetric
+	createResp, err = d.CreateKey(ctx, &kmstypes.CreateKeyRequest{
+		PluginKind:            kmstypes.PluginKind_DICE_KMS,
+		CustomerMasterKeySpec: kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_2048,
+		KeyUsage:              kmstypes.KeyUsage_ENCRYPT_DECRYPT,
+	})
+	assert.NoError(t, err)
+	keyID = createResp.KeyMetadata.KeyID
+	asymEncryptResp, err := d.AsymmetricEncrypt(ctx, &kmstypes.AsymmetricEncryptRequest{KeyID: keyID, PlaintextBase64: "aGVsbG8="})
+	assert.NoError(t, err)
+	rotateResp, err = d.RotateKeyVersion(ctx, &kmstypes.RotateKeyVersionRequest{KeyID: keyID})
+	assert.NoError(t, err)
+
+	reEncryptResp, err = d.ReEncrypt(ctx, &kmstypes.ReEncryptRequest{
+		KeyID:              keyID,
+		SourceKeyVersionID: asymEncryptResp.KeyVersionID,
+		CiphertextBase64:   asymEncryptResp.CiphertextBase64,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, rotateResp.KeyMetadata.PrimaryKeyVersionID, reEncryptResp.KeyVersionID)
+	asymDecryptResp, err := d.AsymmetricDecrypt(ctx, &kmstypes.AsymmetricDecryptRequest{KeyID: keyID, CiphertextBase64: reEncryptResp.CiphertextBase64})
+	assert.NoError(t, err)
+	assert.Equal(t, "aGVsbG8=", asymDecryptResp.PlaintextBase64)
+}
@@ -90,6 +90,8 @@ func (s *Store) CreateKey(keyInfo kmstypes.KeyInfo) error {
 	keyVersion := kmstypes.KeyVersion{
 		VersionID:          keyInfo.GetPrimaryKeyVersion().GetVersionID(),
 		SymmetricKeyBase64: keyInfo.GetPrimaryKeyVersion().GetSymmetricKeyBase64(),
+		PublicKeyPem:       keyInfo.GetPrimaryKeyVersion().GetPublicKeyPem(),
+		PrivateKeyBase64:   keyInfo.GetPrimaryKeyVersion().GetPrivateKeyBase64(),
 		CreatedAt:          &now,
 		UpdatedAt:          &now,
 	}
@@ -105,6 +107,7 @@ func (s *Store) CreateKey(keyInfo kmstypes.KeyInfo) error {
 		KeyUsage:          keyInfo.GetKeyUsage(),
 		KeyState:          keyInfo.GetKeyState(),
 		Description:       keyInfo.GetDescription(),
+		RotationPolicy:    keyInfo.GetRotationPolicy(),
 		CreatedAt:         &now,
 		UpdatedAt:         &now,
 	}
@@ -155,7 +158,7 @@ func (s *Store) ListKeysByKind(kind kmstypes.PluginKind) ([]string, error) {
 	var keys []string
 	prefix := makeEtcdPluginKindPrefix(kind)
 	for _, v := range values {
-		keys = append(keys, strings.TrimPrefix(string(v.Value), prefix))
+		keys = append(keys, strings.TrimPrefix(string(v.Key), prefix))
 	}
 	return keys, nil
 }
@@ -196,6 +199,7 @@ func (s *Store) RotateKeyVersion(keyID string, newKeyVersionInfo kmstypes.KeyVer
 		return nil, err
 	}
 	keyInfo.SetPrimaryKeyVersion(newKeyVersionInfo)
+	keyInfo.GetRotationPolicy().Rotated(now)
 	keyInfo.SetUpdatedAt(now)
 
 	keyJSON, err := json.Marshal(keyInfo)
@@ -230,6 +234,24 @@ func (s *Store) RotateKeyVersion(keyID string, newKeyVersionInfo kmstypes.KeyVer
 	return newKeyVersionInfo, nil
 }
 
+func (s *Store) UpdateKeyRotationPolicy(keyID string, policy *kmstypes.KeyRotationPolicy) (kmstypes.KeyInfo, error) {
+	ctx := context.Background()
+	keyInfo, err := s.GetKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	keyInfo.SetRotationPolicy(policy)
+	keyInfo.SetUpdatedAt(time.Now())
+	keyJSON, err := json.Marshal(keyInfo)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.etcdClient.Put(ctx, makeEtcdKeyID(keyID), string(keyJSON)); err != nil {
+		return nil, err
+	}
+	return keyInfo, nil
+}
+
 func makeEtcdKeyID(keyID string) string {
 	return fmt.Sprintf("/dice/kms/cmk/%s", keyID)
 }
@@ -0,0 +1,180 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package memory is an in-memory kms store, only for test.
+// It is not registered to kmstypes.StoreFactory.
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+type Store struct {
+	sync.Mutex
+	// keyID -> key JSON
+	keys map[string][]byte
+	// keyID/keyVersionID -> key version JSON
+	versions map[string][]byte
+}
+
+func New() *Store {
+	return &Store{
+		keys:     make(map[string][]byte),
+		versions: make(map[string][]byte),
+	}
+}
+
+func (s *Store) GetKind() kmstypes.StoreKind {
+	return kmstypes.StoreKind_MEMORY
+}
+
+func (s *Store) CreateKey(keyInfo kmstypes.KeyInfo) error {
+	if err := kmstypes.CheckKeyForCreate(keyInfo); err != nil {
+		return err
+	}
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+	keyInfo.SetCreatedAt(now)
+	keyInfo.SetUpdatedAt(now)
+	keyVersionInfo := keyInfo.GetPrimaryKeyVersion()
+	keyVersionInfo.SetCreatedAt(now)
+	keyVersionInfo.SetUpdatedAt(now)
+	if err := s.putKey(keyInfo); err != nil {
+		return err
+	}
+	return s.putKeyVersion(keyInfo.GetKeyID(), keyVersionInfo)
+}
+
+func (s *Store) GetKey(keyID string) (kmstypes.KeyInfo, error) {
+	s.Lock()
+	defer s.Unlock()
+	return s.getKey(keyID)
+}
+
+func (s *Store) ListKeysByKind(kind kmstypes.PluginKind) ([]string, error) {
+	s.Lock()
+	defer s.Unlock()
+	var keys []string
+	for keyID := range s.keys {
+		key, err := s.getKey(keyID)
+		if err != nil {
+			return nil, err
+		}
+		if key.GetPluginKind() == kind {
+			keys = append(keys, keyID)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (s *Store) DeleteByKeyID(keyID string) error {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.keys, keyID)
+	return nil
+}
+
+func (s *Store) GetKeyVersion(keyID, keyVersionID string) (kmstypes.KeyVersionInfo, error) {
+	s.Lock()
+	defer s.Unlock()
+	b, ok := s.versions[makeKeyVersionID(keyID, keyVersionID)]
+	if !ok {
+		return nil, fmt.Errorf("key version not exist")
+	}
+	var keyVersion kmstypes.KeyVersion
+	if err := json.Unmarshal(b, &keyVersion); err != nil {
+		return nil, err
+	}
+	return &keyVersion, nil
+}
+
+func (s *Store) RotateKeyVersion(keyID string, newKeyVersionInfo kmstypes.KeyVersionInfo) (kmstypes.KeyVersionInfo, error) {
+	s.Lock()
+	defer s.Unlock()
+	now := time.Now()
+	keyInfo, err := s.getKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	newKeyVersionInfo.SetCreatedAt(now)
+	newKeyVersionInfo.SetUpdatedAt(now)
+	keyInfo.SetPrimaryKeyVersion(newKeyVersionInfo)
+	keyInfo.GetRotationPolicy().Rotated(now)
+	keyInfo.SetUpdatedAt(now)
+	if err := s.putKeyVersion(keyID, newKeyVersionInfo); err != nil {
+		return nil, err
+	}
+	if err := s.putKey(keyInfo); err != nil {
+		return nil, err
+	}
+	return newKeyVersionInfo, nil
+}
+
+func (s *Store) UpdateKeyRotationPolicy(keyID string, policy *kmstypes.KeyRotationPolicy) (kmstypes.KeyInfo, error) {
+	s.Lock()
+	defer s.Unlock()
+	keyInfo, err := s.getKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	keyInfo.SetRotationPolicy(policy)
+	keyInfo.SetUpdatedAt(time.Now())
+	if err := s.putKey(keyInfo); err != nil {
+		return nil, err
+	}
+	return keyInfo, nil
+}
+
+func (s *Store) getKey(keyID string) (kmstypes.KeyInfo, error) {
+	b, ok := s.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("key not exist")
+	}
+	var key kmstypes.Key
+	if err := json.Unmarshal(b, &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (s *Store) putKey(keyInfo kmstypes.KeyInfo) error {
+	b, err := json.Marshal(keyInfo)
+	if err != nil {
+		return err
+	}
+	s.keys[keyInfo.GetKeyID()] = b
+	return nil
+}
+
+func (s *Store) putKeyVersion(keyID string, keyVersionInfo kmstypes.KeyVersionInfo) error {
+	b, err := json.Marshal(keyVersionInfo)
+	if err != nil {
+		return err
+	}
+	s.versions[makeKeyVersionID(keyID, keyVersionInfo.GetVersionID())] = b
+	return nil
+}
+
+func makeKeyVersionID(keyID, keyVersionID string) string {
+	return keyID + "/" + keyVersionID
+}
@@ -177,6 +177,67 @@ func (o *BasicValidateVisitor) VisitDeployments(v DiceYmlVisitor, obj *Deploymen
 	if obj.Policies != "" && obj.Policies != "shuffle" && obj.Policies != "affinity" && obj.Policies != "unique" {
 		o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{o.currentService, "deployments"}, "policies")] = errors.Wrap(invalidPolicy, o.currentService)
 	}
+
+	if obj.Strategy != nil {
+		o.validateStrategy(obj)
+	}
+	if obj.Autoscaling != nil {
+		o.validateAutoscaling(obj)
+	}
+}
+
+func (o *BasicValidateVisitor) validateStrategy(obj *Deployments) {
+	header := yamlHeaderRegexWithUpperHeader([]string{o.currentService, "deployments"}, "strategy")
+	switch obj.Strategy.Type {
+	case "", DeploymentStrategyRolling:
+		return
+	case DeploymentStrategyCanary:
+		if len(obj.Strategy.Steps) == 0 {
+			o.collectErrors[header] = errors.Wrap(invalidCanaryStep, o.currentService)
+			return
+		}
+		last := 0
+		for _, step := range obj.Strategy.Steps {
+			if step.Weight <= last || step.Weight >= 100 || step.Pause < 0 {
+				o.collectErrors[header] = errors.Wrap(invalidCanaryStep, o.currentService)
+				return
+			}
+			last = step.Weight
+		}
+	case DeploymentStrategyBlueGreen:
+		if len(obj.Strategy.Steps) > 0 {
+			o.collectErrors[header] = errors.Wrap(invalidStrategy, o.currentService+": blue-green does not support steps")
+			return
+		}
+	default:
+		o.collectErrors[header] = errors.Wrap(invalidStrategy, o.currentService)
+		return
+	}
+	if obj.Workload == "per_node" {
+		o.collectErrors[header] = errors.Wrap(invalidStrategy, o.currentService+": per-node workload does not support "+obj.Strategy.Type)
+	}
+}
+
+func (o *BasicValidateVisitor) validateAutoscaling(obj *Deployments) {
+	header := yamlHeaderRegexWithUpperHeader([]string{o.currentService, "deployments"}, "autoscaling")
+	as := obj.Autoscaling
+	if as.MinReplicas <= 0 || as.MaxReplicas < as.MinReplicas {
+		o.collectErrors[header] = errors.Wrap(invalidAutoscaling, o.currentService)
+		return
+	}
+	if as.CPU < 0 || as.Mem < 0 || (as.CPU == 0 && as.Mem == 0 && len(as.Metrics) == 0) {
+		o.collectErrors[header] = errors.Wrap(invalidAutoscaling, o.currentService)
+		return
+	}
+	for _, m := range as.Metrics {
+		if m.Name == "" || m.Target <= 0 {
+			o.collectErrors[header] = errors.Wrap(invalidAutoscaling, o.currentService+": invalid metric")
+			return
+		}
+	}
+	if obj.Workload == "per_node" {
+		o.collectErrors[header] = errors.Wrap(invalidAutoscaling, o.currentService+": per-node workload does not support autoscaling")
+	}
 }
 
 func (o *BasicValidateVisitor) VisitAddOns(v DiceYmlVisitor, obj *AddOns) {
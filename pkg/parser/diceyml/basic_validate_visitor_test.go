@@ -85,3 +85,119 @@ func TestBasicValidate(t *testing.T) {
 	assert.Equal(t, 6, len(es), "%v", es)
 
 }
+
+var strategy_validate_yml = `version: 2.0
+services:
+  canary-ok:
+    image: nginx
+    resources:
+      cpu: 0.1
+      mem: 128
+    deployments:
+      replicas: 4
+      strategy:
+        type: canary
+        steps:
+        - weight: 20
+          pause: 300
+        - weight: 50
+  canary-bad:
+    image: nginx
+    resources:
+      cpu: 0.1
+      mem: 128
+    deployments:
+      replicas: 4
+      strategy:
+        type: canary
+        steps:
+        - weight: 50
+        - weight: 20
+  blue-green-ok:
+    image: nginx
+    resources:
+      cpu: 0.1
+      mem: 128
+    deployments:
+      replicas: 2
+      strategy:
+        type: blue-green
+  unknown:
+    image: nginx
+    resources:
+      cpu: 0.1
+      mem: 128
+    deployments:
+      replicas: 2
+      strategy:
+        type: shadow
+`
+
+func TestBasicValidateStrategy(t *testing.T) {
+	d, err := New([]byte(strategy_validate_yml), false)
+	assert.Nil(t, err)
+	es := BasicValidate(d.Obj())
+	assert.Equal(t, 2, len(es), "%v", es)
+	assert.True(t, d.Obj().Services["canary-ok"].Deployments.Strategy.Progressive())
+	assert.Equal(t, 50, d.Obj().Services["canary-ok"].Deployments.Strategy.Steps[1].Weight)
+}
+
+var autoscaling_validate_yml = `version: 2.0
+services:
+  hpa-ok:
+    image: nginx
+    resources:
+      cpu: 0.1
+      mem: 128
+    deployments:
+      replicas: 2
+      autoscaling:
+        min_replicas: 2
+        max_replicas: 10
+        cpu: 70
+        metrics:
+        - name: http_requests_per_second
+          target: 100
+  hpa-bad-replicas:
+    image: nginx
+    resources:
+      cpu: 0.1
+      mem: 128
+    deployments:
+      replicas: 2
+      autoscaling:
+        min_replicas: 5
+        max_replicas: 2
+        cpu: 70
+  hpa-no-target:
+    image: nginx
+    resources:
+      cpu: 0.1
+      mem: 128
+    deployments:
+      replicas: 2
+      autoscaling:
+        min_replicas: 1
+        max_replicas: 2
+  hpa-per-node:
+    image: nginx
+    resources:
+      cpu: 0.1
+      mem: 128
+    deployments:
+      workload: per_node
+      autoscaling:
+        min_replicas: 1
+        max_replicas: 2
+        mem: 80
+`
+
+func TestBasicValidateAutoscaling(t *testing.T) {
+	d, err := New([]byte(autoscaling_validate_yml), true)
+	assert.Error(t, err)
+	d, err = New([]byte(autoscaling_validate_yml), false)
+	assert.Nil(t, err)
+	es := BasicValidate(d.Obj())
+	assert.Equal(t, 3, len(es), "%v", es)
+	assert.Equal(t, 70, d.Obj().Services["hpa-ok"].Deployments.Autoscaling.CPU)
+}
@@ -610,6 +610,72 @@ type Deployments struct {
 	// Selectors available selectors:
 	// [location]
 	Selectors Selectors `yaml:"selectors,omitempty" json:"selectors,omitempty"`
+	// Strategy progressive delivery strategy when updating the service, rolling update by default
+	Strategy *DeploymentStrategy `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+	// Autoscaling horizontal pod autoscaling of the service, replicas is fixed if not set
+	Autoscaling *Autoscaling `yaml:"autoscaling,omitempty" json:"autoscaling,omitempty"`
+}
+
+const (
+	DeploymentStrategyRolling   = "rolling"
+	DeploymentStrategyCanary    = "canary"
+	DeploymentStrategyBlueGreen = "blue-green"
+)
+
+// DeploymentStrategy e.g.
+//
+//	strategy:
+//	  type: canary
+//	  steps:
+//	  - weight: 20
+//	    pause: 300
+//	  - weight: 50
+//
+// canary: the new version receives traffic by the weight of each step, all traffic goes to the new version after the last step;
+// blue-green: the new version is started without traffic and replaces the old one after manual promote
+type DeploymentStrategy struct {
+	Type  string       `yaml:"type,omitempty" json:"type,omitempty"`
+	Steps []CanaryStep `yaml:"steps,omitempty" json:"steps,omitempty"`
+}
+
+type CanaryStep struct {
+	// Weight percentage of traffic to the new version, 1-99
+	Weight int `yaml:"weight" json:"weight"`
+	// Pause seconds to wait before the next step, 0 means waiting for manual promote
+	Pause int `yaml:"pause,omitempty" json:"pause,omitempty"`
+}
+
+// Progressive returns whether the service is deployed by canary or blue-green
+func (s *DeploymentStrategy) Progressive() bool {
+	return s != nil && (s.Type == DeploymentStrategyCanary || s.Type == DeploymentStrategyBlueGreen)
+}
+
+// Autoscaling e.g.
+//
+//	autoscaling:
+//	  min_replicas: 2
+//	  max_replicas: 10
+//	  cpu: 70
+//	  mem: 80
+//	  metrics:
+//	  - name: http_requests_per_second
+//	    target: 100
+//
+// cpu and mem are the target average utilization percentage of the requested resources,
+// metrics are custom pod metrics reported to Erda monitor, which are served by the custom metrics API of the cluster
+type Autoscaling struct {
+	MinReplicas int                 `yaml:"min_replicas" json:"min_replicas"`
+	MaxReplicas int                 `yaml:"max_replicas" json:"max_replicas"`
+	CPU         int                 `yaml:"cpu,omitempty" json:"cpu,omitempty"`
+	Mem         int                 `yaml:"mem,omitempty" json:"mem,omitempty"`
+	Metrics     []AutoscalingMetric `yaml:"metrics,omitempty" json:"metrics,omitempty"`
+}
+
+type AutoscalingMetric struct {
+	// Name metric name in Erda monitor
+	Name string `yaml:"name" json:"name"`
+	// Target average value of the metric per pod
+	Target float64 `yaml:"target" json:"target"`
 }
 
 type TrafficSecurity struct {
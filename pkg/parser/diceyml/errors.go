@@ -22,6 +22,9 @@ var (
 	notfoundVersion            = errortype("not found version in yaml")
 	invalidReplicas            = errortype("invalid replicas defined in yaml")
 	invalidPolicy              = errortype("invalid policy defined in yaml")
+	invalidAutoscaling         = errortype("invalid autoscaling defined in yaml, 0 < min_replicas <= max_replicas and at least one target of cpu, mem or metrics is required")
+	invalidStrategy            = errortype("invalid deployment strategy defined in yaml, must be 'rolling', 'canary' or 'blue-green'")
+	invalidCanaryStep          = errortype("invalid canary steps defined in yaml, weights must be increasing between 1 and 99")
 	invalidCPU                 = errortype("invalid cpu defined in yaml")
 	invalidMaxCPU              = errortype("invalid max cpu defined in yaml")
 	invalidMaxMem              = errortype("invalid max mem defined in yaml")
@@ -148,8 +148,8 @@ func (o *FieldnameValidateVisitor) VisitDeployments(v DiceYmlVisitor, obj *Deplo
 	for k := range dep {
 		switch i := k.(type) {
 		case string:
-			if !contain(i, []string{"workload", "replicas", "policies", "labels", "selectors"}) {
-				o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{o.currentServiceName, "deployments"}, i)] = fmt.Errorf("[%s]/[deployments] field '%s' not one of [replicas, policies, labels, selectors]", o.currentServiceName, i)
+			if !contain(i, []string{"workload", "replicas", "policies", "labels", "selectors", "strategy", "autoscaling"}) {
+				o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{o.currentServiceName, "deployments"}, i)] = fmt.Errorf("[%s]/[deployments] field '%s' not one of [replicas, policies, labels, selectors, strategy, autoscaling]", o.currentServiceName, i)
 			}
 		default:
 			o.collectErrors[yamlHeaderRegex("_"+strconv.Itoa(len(o.collectErrors)))] = fmt.Errorf("[%s]/[deployments] %v not string type", o.currentServiceName, k)
@@ -100,6 +100,12 @@ func (o *MergeEnvVisitor) VisitDeployments(v DiceYmlVisitor, obj *Deployments) {
 	}
 	overrideIfNotZero(o.envObj.Services[o.currentService].Deployments.Policies, &obj.Policies)
 	overrideIfNotZero(o.envObj.Services[o.currentService].Deployments.Labels, &obj.Labels)
+	if o.envObj.Services[o.currentService].Deployments.Strategy != nil {
+		obj.Strategy = o.envObj.Services[o.currentService].Deployments.Strategy
+	}
+	if o.envObj.Services[o.currentService].Deployments.Autoscaling != nil {
+		obj.Autoscaling = o.envObj.Services[o.currentService].Deployments.Autoscaling
+	}
 }
 
 func (o *MergeEnvVisitor) VisitHTTPCheck(v DiceYmlVisitor, obj *HTTPCheck) {
@@ -58,6 +58,9 @@ type Spec struct {
 
 	// allActions represents all actions from all stages
 	allActions map[ActionAlias]*indexedAction
+
+	// matrixActions represents original actions before matrix expanded
+	matrixActions map[ActionAlias]*Action
 }
 
 // describe the use of network hook in the pipeline
@@ -126,6 +129,10 @@ type Action struct {
 	Commands  []string                     `yaml:"commands,omitempty"`
 	Loop      *apistructs.PipelineTaskLoop `yaml:"loop,omitempty"`
 
+	Retry *apistructs.PipelineTaskRetry `yaml:"retry,omitempty"` // retry policy when task failed
+
+	Services map[string]*apistructs.PipelineTaskService `yaml:"services,omitempty"` // service containers started alongside the task
+
 	Timeout int64 `yaml:"timeout,omitempty"` // unit: second
 
 	Resources Resources `yaml:"resources,omitempty"`
@@ -142,6 +149,15 @@ type Action struct {
 
 	Disable bool `yaml:"disable,omitempty"` // make task disable or enable
 
+	Matrix *ActionMatrix `yaml:"matrix,omitempty"` // expand one action into multiple tasks by combinations
+
+	// MatrixSource 和 MatrixCombination 表示由 matrix 展开后的 action 对应的原始 alias 和组合值。
+	// 目前不开放给用户使用。由 parser 自动赋值。
+	MatrixSource      ActionAlias       `yaml:"-"`
+	MatrixCombination map[string]string `yaml:"-"`
+	// MatrixMaxParallel 表示同一个 matrix 展开的 action 同时运行的最大数量，由 reconciler 调度时保证，0 表示不限制。
+	MatrixMaxParallel int `yaml:"-"`
+
 	// TODO 在未来版本中，可能去除 stage，依赖关系则必须通过 Needs 来声明。
 	// 目前不开放给用户使用。由 parser 自动赋值。
 	// Needs 显式声明依赖的 actions。隐式依赖关系是下一个 stage 依赖之前所有 stage 里的 action。
@@ -169,6 +185,27 @@ type Policy struct {
 	Type apistructs.PolicyType `yaml:"type,omitempty"`
 }
 
+// ActionMatrix expand an action into tasks by the cartesian product of dimensions.
+//
+// example:
+//
+//	matrix:
+//	  jdk: [8, 11]
+//	  region: [hz, sh]
+//	  exclude:
+//	    - jdk: 8
+//	      region: sh
+//	  include:
+//	    - jdk: 17
+//	      region: hz
+//	  max_parallel: 2
+type ActionMatrix struct {
+	Include     []map[string]string `yaml:"include,omitempty"`      // extra combinations or extra values for matched combinations
+	Exclude     []map[string]string `yaml:"exclude,omitempty"`      // combinations to be removed
+	MaxParallel int                 `yaml:"max_parallel,omitempty"` // max running tasks at the same time, limited by the reconciler, 0 means no limit
+	Dimensions  map[string][]string `yaml:",inline"`                // dimension name -> values
+}
+
 type SnippetConfig struct {
 	Source string            `yaml:"source,omitempty"` // 来源 gittar dice test
 	Name   string            `yaml:"name,omitempty"`   // 名称
@@ -196,8 +233,11 @@ type ActionCache struct {
 	// 缓存生成的 key 或者是用户指定的 key
 	// 用户指定的话 需要 {{basePath}}/路径/{{endPath}} 来自定义 key
 	// 用户没有指定 key 有一定的生成规则, 具体生成规则看 prepare.go 的 setActionCacheStorageAndBinds 方法
+	// 不使用上述占位符的 key 为远程缓存的 key, 如 go-{{ hashFiles "go.sum" }}, 缓存保存在对象存储中
 	Key  string `yaml:"key,omitempty"`
 	Path string `yaml:"path,omitempty"` // 指定那个目录被缓存, 只能是由 / 开始的绝对路径
+	// 远程缓存未命中 key 时, 依次使用 restoreKeys 作为前缀匹配最近使用的缓存
+	RestoreKeys []string `yaml:"restoreKeys,omitempty"`
 }
 
 type ActionType string
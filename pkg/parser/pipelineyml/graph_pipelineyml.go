@@ -18,6 +18,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/strutil"
 )
 
 // ConvertGraphPipelineYmlContent: YAML(apistructs.PipelineYml) -> YAML(Spec)
@@ -53,6 +54,8 @@ func ConvertGraphPipelineYmlContent(data []byte) ([]byte, error) {
 					Timeout:     frontendAction.Timeout,
 					If:          frontendAction.If,
 					Loop:        frontendAction.Loop,
+					Retry:       frontendAction.Retry,
+					Services:    frontendAction.Services,
 					Type:        ActionType(frontendAction.Type),
 					Namespaces:  frontendAction.Namespaces,
 					Resources: Resources{
@@ -72,8 +75,9 @@ func ConvertGraphPipelineYmlContent(data []byte) ([]byte, error) {
 
 			for _, cache := range frontendAction.Caches {
 				maps[ActionType(frontendAction.Type)].Caches = append(maps[ActionType(frontendAction.Type)].Caches, ActionCache{
-					Key:  cache.Key,
-					Path: cache.Path,
+					Key:         cache.Key,
+					Path:        cache.Path,
+					RestoreKeys: cache.RestoreKeys,
 				})
 			}
 
@@ -83,6 +87,15 @@ func ConvertGraphPipelineYmlContent(data []byte) ([]byte, error) {
 				}
 			}
 
+			if frontendAction.Matrix != nil {
+				maps[ActionType(frontendAction.Type)].Matrix = &ActionMatrix{
+					Dimensions:  frontendAction.Matrix.Dimensions,
+					Include:     frontendAction.Matrix.Include,
+					Exclude:     frontendAction.Matrix.Exclude,
+					MaxParallel: frontendAction.Matrix.MaxParallel,
+				}
+			}
+
 			actions = append(actions, maps)
 		}
 		s.Stages = append(s.Stages, &Stage{Actions: actions})
@@ -204,6 +217,9 @@ func ConvertToGraphPipelineYml(data []byte) (*apistructs.PipelineYml, error) {
 
 	if result.NeedUpgrade {
 		result.YmlContent = string(pipelineYml.upgradedYmlContent)
+	} else if pipelineYml.matrixOriginalYmlContent != nil {
+		// matrix 展开前的 yml，保证图形化编辑后 matrix 不丢失
+		result.YmlContent = string(pipelineYml.matrixOriginalYmlContent)
 	} else {
 		graphYmlContent, err := GenerateYml(pipelineYml.s)
 		if err != nil {
@@ -214,45 +230,30 @@ func ConvertToGraphPipelineYml(data []byte) (*apistructs.PipelineYml, error) {
 
 	for _, stage := range pipelineYml.Spec().Stages {
 		stageActions := make([]*apistructs.PipelineYmlAction, 0)
+		// matrixActions 按原始 alias 聚合展开后的 action
+		matrixActions := make(map[ActionAlias]*apistructs.PipelineYmlAction)
 		for _, typedAction := range stage.Actions {
 			for _, action := range typedAction {
-				resultAction := &apistructs.PipelineYmlAction{}
-				resultAction.Type = action.Type.String()
-				resultAction.Alias = action.Alias.String()
-				resultAction.Version = action.Version
-				resultAction.Params = action.Params
-				resultAction.Image = action.Image
-				resultAction.Commands = action.Commands
-				resultAction.Timeout = action.Timeout
-				resultAction.Namespaces = action.Namespaces
-				resultAction.If = action.If
-				resultAction.Disable = action.Disable
-				resultAction.Loop = action.Loop
-				resultAction.Resources = apistructs.Resources{Cpu: action.Resources.CPU, Mem: float64(action.Resources.Mem), Disk: float64(action.Resources.Disk)}
-
-				caches := action.Caches
-				if caches != nil {
-					var resultActionCaches []apistructs.ActionCache
-					for _, v := range caches {
-						resultActionCaches = append(resultActionCaches, apistructs.ActionCache{
-							Path: v.Path,
-							Key:  v.Key,
-						})
-					}
-					resultAction.Caches = resultActionCaches
-				}
-
-				if action.SnippetConfig != nil {
-					resultAction.SnippetConfig = action.SnippetConfig.toApiSnippetConfig()
+				resultAction := toApiAction(action)
+				if action.MatrixSource == "" {
+					stageActions = append(stageActions, resultAction)
+					continue
 				}
-
-				if action.Policy != nil {
-					resultAction.Policy = &apistructs.Policy{
-						Type: action.Policy.Type,
+				resultAction.MatrixCombination = action.MatrixCombination
+				matrixAction, ok := matrixActions[action.MatrixSource]
+				if !ok {
+					originalAction, err := GetMatrixAction(pipelineYml.Spec(), action.MatrixSource)
+					if err != nil {
+						return nil, err
 					}
+					matrixAction = toApiAction(originalAction)
+					matrixAction.Type = action.Type.String()
+					matrixAction.Alias = action.MatrixSource.String()
+					matrixAction.Namespaces = strutil.RemoveSlice(originalAction.Namespaces, action.MatrixSource.String())
+					matrixActions[action.MatrixSource] = matrixAction
+					stageActions = append(stageActions, matrixAction)
 				}
-
-				stageActions = append(stageActions, resultAction)
+				matrixAction.MatrixActions = append(matrixAction.MatrixActions, resultAction)
 			}
 		}
 		result.Stages = append(result.Stages, stageActions)
@@ -262,6 +263,58 @@ func ConvertToGraphPipelineYml(data []byte) (*apistructs.PipelineYml, error) {
 	return result, nil
 }
 
+func toApiAction(action *Action) *apistructs.PipelineYmlAction {
+	resultAction := &apistructs.PipelineYmlAction{}
+	resultAction.Type = action.Type.String()
+	resultAction.Alias = action.Alias.String()
+	resultAction.Version = action.Version
+	resultAction.Params = action.Params
+	resultAction.Image = action.Image
+	resultAction.Commands = action.Commands
+	resultAction.Timeout = action.Timeout
+	resultAction.Namespaces = action.Namespaces
+	resultAction.If = action.If
+	resultAction.Disable = action.Disable
+	resultAction.Loop = action.Loop
+	resultAction.Retry = action.Retry
+	resultAction.Services = action.Services
+	resultAction.Resources = apistructs.Resources{Cpu: action.Resources.CPU, Mem: float64(action.Resources.Mem), Disk: float64(action.Resources.Disk)}
+
+	caches := action.Caches
+	if caches != nil {
+		var resultActionCaches []apistructs.ActionCache
+		for _, v := range caches {
+			resultActionCaches = append(resultActionCaches, apistructs.ActionCache{
+				Path:        v.Path,
+				Key:         v.Key,
+				RestoreKeys: v.RestoreKeys,
+			})
+		}
+		resultAction.Caches = resultActionCaches
+	}
+
+	if action.SnippetConfig != nil {
+		resultAction.SnippetConfig = action.SnippetConfig.toApiSnippetConfig()
+	}
+
+	if action.Policy != nil {
+		resultAction.Policy = &apistructs.Policy{
+			Type: action.Policy.Type,
+		}
+	}
+
+	if action.Matrix != nil {
+		resultAction.Matrix = &apistructs.ActionMatrix{
+			Dimensions:  action.Matrix.Dimensions,
+			Include:     action.Matrix.Include,
+			Exclude:     action.Matrix.Exclude,
+			MaxParallel: action.Matrix.MaxParallel,
+		}
+	}
+
+	return resultAction
+}
+
 func cronCompensatorReset(cronCompensator *apistructs.CronCompensator) *apistructs.CronCompensator {
 	if cronCompensator != nil {
 		if cronCompensator.Enable == DefaultCronCompensator.Enable &&
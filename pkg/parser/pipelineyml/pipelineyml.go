@@ -52,6 +52,9 @@ type PipelineYml struct {
 	triggerLabels map[string]string
 
 	runParams []apistructs.PipelineRunParam // 运行时的输入参数
+
+	// matrix
+	matrixOriginalYmlContent []byte // matrix 展开前的 yml content
 }
 
 func New(b []byte, ops ...Option) (_ *PipelineYml, err error) {
@@ -136,12 +139,23 @@ func New(b []byte, ops ...Option) (_ *PipelineYml, err error) {
 		}
 	}
 
+	// 展开 matrix，保留展开前的 yml 用于图形化编辑
+	if y.s.HasMatrix() {
+		y.matrixOriginalYmlContent, err = GenerateYml(y.s)
+		if err != nil {
+			panic(err)
+		}
+		y.s.Accept(NewMatrixVisitor())
+	}
+
 	// 遍历 action，为 render ref,output 做准备
 	// 不做 flatParams，JSON 序列化在最后进行，防止简单 render 后 JSON 无效
 	y.s.Accept(NewStageVisitor(false))
 
 	y.s.Accept(NewCronVisitor())
 	y.s.Accept(NewTimeoutVisitor())
+	y.s.Accept(NewRetryVisitor())
+	y.s.Accept(NewServiceVisitor())
 
 	if len(y.aliasToCheckRefOp) > 0 {
 		y.s.Accept(NewRefOpVisitor(y.aliasToCheckRefOp, y.refs, y.outputs, y.allowMissingCustomScriptOutputs, y.globalSnippetConfigLabels))
@@ -0,0 +1,298 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelineyml
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/erda-project/erda/pkg/expression"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+const (
+	// MatrixEnvPrefix is the env prefix of matrix values injected into expanded tasks.
+	MatrixEnvPrefix = "MATRIX_"
+
+	matrixKeyExclude     = "exclude"
+	matrixKeyMaxParallel = "max_parallel"
+
+	// maxMatrixCombinations limits the count of tasks expanded from one action.
+	maxMatrixCombinations = 256
+)
+
+var matrixEnvKeyReplacer = strings.NewReplacer("-", "_", ".", "_")
+
+// 匹配 ${{ matrix.xxx }}
+var matrixPlaceholderRegexp = regexp.MustCompile(`\$\{\{\s*` + expression.Matrix + `\.([^\s{}]+)\s*\}\}`)
+
+// MatrixVisitor expand actions which declared `matrix` into multiple actions.
+// MatrixVisitor 需要在 StageVisitor 之前执行，保证展开后的 action 参与 needs/namespaces 计算。
+type MatrixVisitor struct{}
+
+func NewMatrixVisitor() *MatrixVisitor {
+	return &MatrixVisitor{}
+}
+
+func (v *MatrixVisitor) Visit(s *Spec) {
+	for stageIndex, stage := range s.Stages {
+		var expandedActions []typedActionMap
+		for _, typedActionMap := range stage.Actions {
+			if len(typedActionMap) != 1 {
+				// invalid typedActionMap, handled by stageVisitor
+				expandedActions = append(expandedActions, typedActionMap)
+				continue
+			}
+			for actionType, action := range typedActionMap {
+				if action == nil || action.Matrix == nil {
+					expandedActions = append(expandedActions, typedActionMap)
+					continue
+				}
+				alias := action.Alias
+				if alias == "" {
+					alias = ActionAlias(actionType)
+				}
+				if actionType.IsSnippet() {
+					s.appendError(errors.New("matrix is not supported by snippet action"), stageIndex, alias)
+					expandedActions = append(expandedActions, typedActionMap)
+					continue
+				}
+				actions, err := expandMatrixAction(alias, action)
+				if err != nil {
+					s.appendError(err, stageIndex, alias)
+					expandedActions = append(expandedActions, typedActionMap)
+					continue
+				}
+				if s.matrixActions == nil {
+					s.matrixActions = make(map[ActionAlias]*Action)
+				}
+				s.matrixActions[alias] = action
+				for _, expanded := range actions {
+					expandedActions = append(expandedActions, map[ActionType]*Action{actionType: expanded})
+				}
+			}
+		}
+		stage.Actions = expandedActions
+	}
+}
+
+// HasMatrix return whether there is any action declared matrix.
+func (s *Spec) HasMatrix() bool {
+	var has bool
+	s.LoopStagesActions(func(stage int, action *Action) {
+		if action != nil && action.Matrix != nil {
+			has = true
+		}
+	})
+	return has
+}
+
+// expandMatrixAction render one action for each combination.
+// The alias of expanded action is `{alias}-{index}`, index starts from 1.
+func expandMatrixAction(alias ActionAlias, action *Action) ([]*Action, error) {
+	combinations, err := action.Matrix.Combinations()
+	if err != nil {
+		return nil, err
+	}
+
+	// action without matrix as template
+	tmpl := *action
+	tmpl.Matrix = nil
+
+	var actions []*Action
+	for i, combination := range combinations {
+		var node yaml.Node
+		if err := node.Encode(&tmpl); err != nil {
+			return nil, errors.Errorf("failed to encode action as matrix template, err: %v", err)
+		}
+		if err := renderMatrixPlaceholders(&node, combination); err != nil {
+			return nil, err
+		}
+		var expanded Action
+		if err := node.Decode(&expanded); err != nil {
+			return nil, errors.Errorf("failed to decode action after render matrix, combination: %v, err: %v", combination, err)
+		}
+		expanded.Alias = ActionAlias(fmt.Sprintf("%s-%d", alias, i+1))
+		expanded.Namespaces = strutil.RemoveSlice(expanded.Namespaces, alias.String())
+		expanded.MatrixSource = alias
+		expanded.MatrixCombination = combination
+		expanded.MatrixMaxParallel = action.Matrix.MaxParallel
+		actions = append(actions, &expanded)
+	}
+	return actions, nil
+}
+
+// renderMatrixPlaceholders replace ${{ matrix.xxx }} by combination values in every scalar of the yaml node.
+// Values are rendered inside the scalar, so they can't change the structure of the action.
+// A scalar which is exactly one placeholder is resolved by the value, e.g. `timeout: ${{ matrix.timeout }}` is an int.
+func renderMatrixPlaceholders(node *yaml.Node, combination map[string]string) error {
+	var notFound []string
+	var render func(node *yaml.Node)
+	render = func(node *yaml.Node) {
+		if node.Kind != yaml.ScalarNode {
+			for _, child := range node.Content {
+				render(child)
+			}
+			return
+		}
+		if !matrixPlaceholderRegexp.MatchString(node.Value) {
+			return
+		}
+		whole := matrixPlaceholderRegexp.FindString(node.Value) == node.Value
+		node.Value = matrixPlaceholderRegexp.ReplaceAllStringFunc(node.Value, func(ph string) string {
+			key := matrixPlaceholderRegexp.FindStringSubmatch(ph)[1]
+			value, ok := combination[key]
+			if !ok {
+				notFound = append(notFound, ph)
+				return ph
+			}
+			return value
+		})
+		if whole {
+			node.Tag = ""
+			node.Style = 0
+		}
+	}
+	render(node)
+	if len(notFound) > 0 {
+		return errors.Errorf("matrix value not found: %s", strutil.Join(strutil.DedupSlice(notFound), ", ", true))
+	}
+	return nil
+}
+
+// Combinations return all combinations after applied exclude and include.
+// Dimensions are sorted by name to make sure the result is stable.
+func (m *ActionMatrix) Combinations() ([]map[string]string, error) {
+	if m.MaxParallel < 0 {
+		return nil, errors.Errorf("invalid matrix %s: %d", matrixKeyMaxParallel, m.MaxParallel)
+	}
+	var dimensions []string
+	for name, values := range m.Dimensions {
+		if len(values) == 0 {
+			return nil, errors.Errorf("matrix dimension %q doesn't have any values", name)
+		}
+		dimensions = append(dimensions, name)
+	}
+	sort.Strings(dimensions)
+
+	// cartesian product
+	var combinations []map[string]string
+	if len(dimensions) > 0 {
+		combinations = []map[string]string{{}}
+	}
+	for _, name := range dimensions {
+		var product []map[string]string
+		for _, combination := range combinations {
+			for _, value := range m.Dimensions[name] {
+				newCombination := copyCombination(combination)
+				newCombination[name] = value
+				product = append(product, newCombination)
+			}
+		}
+		combinations = product
+	}
+
+	// exclude
+	for _, exclude := range m.Exclude {
+		for name := range exclude {
+			if _, ok := m.Dimensions[name]; !ok {
+				return nil, errors.Errorf("matrix %s contains unknown dimension %q", matrixKeyExclude, name)
+			}
+		}
+		var remained []map[string]string
+		for _, combination := range combinations {
+			if !combinationMatch(combination, exclude) {
+				remained = append(remained, combination)
+			}
+		}
+		combinations = remained
+	}
+
+	// include: extend matched combinations, or append as a new combination
+	for _, include := range m.Include {
+		if len(include) == 0 {
+			continue
+		}
+		originalPart := make(map[string]string)
+		for name, value := range include {
+			if _, ok := m.Dimensions[name]; ok {
+				originalPart[name] = value
+			}
+		}
+		var matched bool
+		if len(originalPart) > 0 {
+			for _, combination := range combinations {
+				if !combinationMatch(combination, originalPart) {
+					continue
+				}
+				matched = true
+				for name, value := range include {
+					combination[name] = value
+				}
+			}
+		}
+		if !matched {
+			combinations = append(combinations, copyCombination(include))
+		}
+	}
+
+	if len(combinations) == 0 {
+		return nil, errors.New("matrix doesn't have any combinations")
+	}
+	if len(combinations) > maxMatrixCombinations {
+		return nil, errors.Errorf("too many matrix combinations: %d (max: %d)", len(combinations), maxMatrixCombinations)
+	}
+	return combinations, nil
+}
+
+// GetMatrixAction return the original action before matrix expanded.
+func GetMatrixAction(s *Spec, alias ActionAlias) (*Action, error) {
+	action, ok := s.matrixActions[alias]
+	if !ok {
+		return nil, errors.Errorf("matrix action not found, alias: %s", alias)
+	}
+	return action, nil
+}
+
+// MatrixEnvs return envs of the combination, key is upper-case with `MATRIX_` prefix.
+func MatrixEnvs(combination map[string]string) map[string]string {
+	envs := make(map[string]string, len(combination))
+	for k, v := range combination {
+		envs[MatrixEnvPrefix+strutil.ToUpper(matrixEnvKeyReplacer.Replace(k))] = v
+	}
+	return envs
+}
+
+func combinationMatch(combination, part map[string]string) bool {
+	for name, value := range part {
+		if combination[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func copyCombination(combination map[string]string) map[string]string {
+	newCombination := make(map[string]string, len(combination))
+	for k, v := range combination {
+		newCombination[k] = v
+	}
+	return newCombination
+}
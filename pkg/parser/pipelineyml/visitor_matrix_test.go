@@ -0,0 +1,170 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelineyml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const matrixYml = `version: "1.1"
+stages:
+  - stage:
+      - git-checkout:
+          alias: repo
+  - stage:
+      - custom-script:
+          alias: build
+          image: openjdk:${{ matrix.jdk }}
+          commands:
+            - echo ${{ matrix.region }}
+          matrix:
+            jdk: [8, 11]
+            region: [hz, sh]
+            exclude:
+              - jdk: 8
+                region: sh
+            include:
+              - jdk: 11
+                region: hz
+                experimental: "true"
+              - jdk: 17
+                region: bj
+            max_parallel: 2
+  - stage:
+      - custom-script:
+          alias: release
+`
+
+func TestActionMatrix_Combinations(t *testing.T) {
+	m := ActionMatrix{
+		Dimensions: map[string][]string{"jdk": {"8", "11"}, "region": {"hz", "sh"}},
+		Exclude:    []map[string]string{{"jdk": "8", "region": "sh"}},
+		Include:    []map[string]string{{"jdk": "11", "region": "hz", "experimental": "true"}, {"jdk": "17", "region": "bj"}},
+	}
+	combinations, err := m.Combinations()
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]string{
+		{"jdk": "8", "region": "hz"},
+		{"jdk": "11", "region": "hz", "experimental": "true"},
+		{"jdk": "11", "region": "sh"},
+		{"jdk": "17", "region": "bj"},
+	}, combinations)
+
+	// unknown exclude dimension
+	m.Exclude = []map[string]string{{"os": "linux"}}
+	_, err = m.Combinations()
+	assert.Error(t, err)
+
+	// empty matrix
+	_, err = (&ActionMatrix{}).Combinations()
+	assert.Error(t, err)
+}
+
+func TestMatrixVisitor_Visit(t *testing.T) {
+	y, err := New([]byte(matrixYml))
+	assert.NoError(t, err)
+
+	actions := y.Spec().Stages[1].Actions
+	assert.Equal(t, 4, len(actions))
+
+	build1, err := GetAction(y.Spec(), "build-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "openjdk:8", build1.Image)
+	assert.Equal(t, []string{"echo hz"}, build1.Commands)
+	assert.Equal(t, ActionAlias("build"), build1.MatrixSource)
+	assert.Nil(t, build1.Matrix)
+	assert.Equal(t, []ActionAlias{"repo"}, build1.Needs)
+
+	// max_parallel is limited by the reconciler, expanded actions don't need each other
+	build3, err := GetAction(y.Spec(), "build-3")
+	assert.NoError(t, err)
+	assert.Equal(t, "openjdk:11", build3.Image)
+	assert.Equal(t, []ActionAlias{"repo"}, build3.Needs)
+	assert.Equal(t, 2, build3.MatrixMaxParallel)
+
+	// next stage depends on all expanded actions
+	release, err := GetAction(y.Spec(), "release")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(release.Needs))
+
+	// unknown matrix value
+	_, err = New([]byte(`version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          commands:
+            - echo ${{ matrix.os }}
+          matrix:
+            jdk: [8]
+`))
+	assert.Error(t, err)
+}
+
+func TestMatrixVisitor_VisitRenderValues(t *testing.T) {
+	y, err := New([]byte(`version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          alias: build
+          image: "${{ matrix.image }}"
+          params:
+            count: ${{ matrix.count }}
+          commands:
+            - echo '${{ matrix.msg }}'
+          matrix:
+            image: ["nginx\nresources:\n  cpu: 100"]
+            count: [60]
+            msg: ["a: b", "x\n- injected"]
+`))
+	assert.NoError(t, err)
+
+	// values can't inject fields into the action
+	build1, err := GetAction(y.Spec(), "build-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "nginx\nresources:\n  cpu: 100", build1.Image)
+	assert.Equal(t, float64(0), build1.Resources.CPU)
+	assert.Equal(t, 60, build1.Params["count"])
+	assert.Equal(t, []string{"echo 'a: b'"}, build1.Commands)
+
+	build2, err := GetAction(y.Spec(), "build-2")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"echo 'x\n- injected'"}, build2.Commands)
+}
+
+func TestConvertToGraphPipelineYml_Matrix(t *testing.T) {
+	graph, err := ConvertToGraphPipelineYml([]byte(matrixYml))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(graph.Stages[1]))
+	build := graph.Stages[1][0]
+	assert.Equal(t, "build", build.Alias)
+	assert.Equal(t, "openjdk:${{ matrix.jdk }}", build.Image)
+	assert.NotNil(t, build.Matrix)
+	assert.Equal(t, 4, len(build.MatrixActions))
+	assert.Equal(t, "build-4", build.MatrixActions[3].Alias)
+	assert.Equal(t, map[string]string{"jdk": "17", "region": "bj"}, build.MatrixActions[3].MatrixCombination)
+	assert.Contains(t, graph.YmlContent, "matrix:")
+
+	// yml content keeps matrix
+	y, err := New([]byte(graph.YmlContent))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(y.Spec().Stages[1].Actions))
+}
+
+func TestMatrixEnvs(t *testing.T) {
+	envs := MatrixEnvs(map[string]string{"jdk-version": "8", "os.name": "linux"})
+	assert.Equal(t, map[string]string{"MATRIX_JDK_VERSION": "8", "MATRIX_OS_NAME": "linux"}, envs)
+}
@@ -226,6 +226,10 @@ func (v *RefOpVisitor) handleAction(action *indexedAction, handler func(ori stri
 		for index := range caches {
 			replaced := handler(caches[index].Path)
 			caches[index].Path = replaced
+			caches[index].Key = handler(caches[index].Key)
+			for i := range caches[index].RestoreKeys {
+				caches[index].RestoreKeys[i] = handler(caches[index].RestoreKeys[i])
+			}
 		}
 	}
 
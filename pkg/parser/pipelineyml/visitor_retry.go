@@ -0,0 +1,44 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelineyml
+
+import (
+	"github.com/pkg/errors"
+)
+
+type RetryVisitor struct{}
+
+func NewRetryVisitor() *RetryVisitor {
+	return &RetryVisitor{}
+}
+
+func (v *RetryVisitor) Visit(s *Spec) {
+	for stageIndex, stage := range s.Stages {
+		for _, typedActionMap := range stage.Actions {
+			for _, action := range typedActionMap {
+				if action.Retry == nil {
+					continue
+				}
+				if action.Type.IsSnippet() {
+					s.appendError(errors.New("retry is not supported by snippet action"), stageIndex, action.Alias)
+					continue
+				}
+				if err := action.Retry.Validate(); err != nil {
+					s.appendError(err, stageIndex, action.Alias)
+				}
+			}
+		}
+	}
+}
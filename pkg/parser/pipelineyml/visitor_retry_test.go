@@ -0,0 +1,62 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelineyml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryVisitor_Visit(t *testing.T) {
+	y, err := New([]byte(`version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          alias: build
+          retry:
+            max_attempts: 3
+            backoff:
+              interval_sec: 5
+            exit_codes: [137]
+            on: [image-pull]
+`))
+	assert.NoError(t, err)
+	build, err := GetAction(y.Spec(), "build")
+	assert.NoError(t, err)
+	assert.NotNil(t, build.Retry)
+	assert.Equal(t, uint64(3), build.Retry.MaxAttempts)
+	assert.Equal(t, uint64(5), build.Retry.Backoff.IntervalSec)
+	assert.Equal(t, []int{137}, build.Retry.ExitCodes)
+
+	_, err = New([]byte(`version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          retry:
+            max_attempts: 100
+`))
+	assert.Error(t, err)
+
+	_, err = New([]byte(`version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          retry:
+            max_attempts: 2
+            on: [unknown]
+`))
+	assert.Error(t, err)
+}
@@ -16,6 +16,7 @@ package pipelineyml
 
 import (
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -49,6 +50,7 @@ const validSecretRegExpr = `\(\(([^()\s]+)\)\)`
 
 var looseSecretRegexp = regexp.MustCompile(looseSecretRegExpr)
 var validSecretRegexp = regexp.MustCompile(validSecretRegExpr)
+var configsSecretRegexp = regexp.MustCompile(`\$\{\{\s*` + expression.Configs + `\.([^\s{}]+)\s*\}\}`)
 
 // yaml 全局文本替换
 func (v *SecretVisitor) Visit(s *Spec) {
@@ -114,3 +116,20 @@ func RenderSecrets(input []byte, secrets map[string]string) ([]byte, error) {
 
 	return []byte(replaced), nil
 }
+
+// ListSecretNames return sorted names of secrets referenced by ((key)) or ${{ configs.key }}
+func ListSecretNames(input []byte) []string {
+	names := make(map[string]struct{})
+	for _, wrappedSec := range validSecretRegexp.FindAllString(string(input), -1) {
+		names[unwrapSecret(wrappedSec)] = struct{}{}
+	}
+	for _, sub := range configsSecretRegexp.FindAllStringSubmatch(string(input), -1) {
+		names[sub[1]] = struct{}{}
+	}
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
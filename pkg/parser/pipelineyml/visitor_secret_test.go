@@ -43,6 +43,20 @@ envs:
 	assert.Error(t, s.mergeErrors())
 }
 
+func TestListSecretNames(t *testing.T) {
+	input := []byte(`version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          commands:
+            - echo ((b)) ((a)) ((b))
+            - echo ${{ configs.c.d }} ${{configs.e}}
+            - echo (( invalid )) ${{ params.f }}
+`)
+	assert.Equal(t, []string{"a", "b", "c.d", "e"}, ListSecretNames(input))
+	assert.Empty(t, ListSecretNames([]byte("version: 1.1")))
+}
+
 //func TestRenderSecrets(t *testing.T) {
 //	input := []byte("((a))((b))((c))")
 //	secret := map[string]string{
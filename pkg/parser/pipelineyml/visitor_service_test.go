@@ -0,0 +1,57 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelineyml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceVisitor_Visit(t *testing.T) {
+	y, err := New([]byte(`version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          alias: it
+          commands:
+            - mysql -h mysql -uroot -proot -e 'select 1'
+          services:
+            mysql:
+              image: mysql:5.7
+              envs:
+                MYSQL_ROOT_PASSWORD: root
+              ports: [3306]
+              health_check:
+                exec: mysqladmin ping -uroot -proot
+`))
+	assert.NoError(t, err)
+	it, err := GetAction(y.Spec(), "it")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(it.Services))
+	assert.Equal(t, "mysql:5.7", it.Services["mysql"].Image)
+	assert.Equal(t, []int{3306}, it.Services["mysql"].Ports)
+	assert.Equal(t, "mysqladmin ping -uroot -proot", it.Services["mysql"].HealthCheck.Exec)
+
+	_, err = New([]byte(`version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          services:
+            mysql:
+              ports: [3306]
+`))
+	assert.Error(t, err)
+}
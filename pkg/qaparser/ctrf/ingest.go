@@ -0,0 +1,143 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctrf
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/qaparser"
+)
+
+// Report is the Common Test Report Format, see https://ctrf.io
+// only the fields used to build test suites are declared
+type Report struct {
+	Results Results `json:"results"`
+}
+
+type Results struct {
+	Tool  Tool   `json:"tool"`
+	Tests []Test `json:"tests"`
+}
+
+type Tool struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type Test struct {
+	Name     string   `json:"name"`
+	Status   string   `json:"status"`
+	Duration float64  `json:"duration"` // in milliseconds
+	Message  string   `json:"message,omitempty"`
+	Trace    string   `json:"trace,omitempty"`
+	Suite    Suite    `json:"suite,omitempty"`
+	FilePath string   `json:"filePath,omitempty"`
+	Stdout   []string `json:"stdout,omitempty"`
+	Stderr   []string `json:"stderr,omitempty"`
+}
+
+// Suite is a string in early versions of ctrf, and a list of nested suite names since v0.0.16
+type Suite string
+
+func (s *Suite) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*s = Suite(name)
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	*s = Suite(strings.Join(names, " > "))
+	return nil
+}
+
+const (
+	statusPassed  = "passed"
+	statusFailed  = "failed"
+	statusSkipped = "skipped"
+	statusPending = "pending"
+)
+
+// Ingest will parse the given ctrf report and return the tests grouped into suites,
+// a test is grouped by its suite, or by its file path if the suite is absent.
+func Ingest(data []byte) ([]*apistructs.TestSuite, error) {
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, errors.Wrap(err, "parse ctrf report")
+	}
+
+	var (
+		suites []*apistructs.TestSuite
+		byName = make(map[string]*apistructs.TestSuite)
+	)
+	for _, t := range report.Results.Tests {
+		name := string(t.Suite)
+		if name == "" {
+			name = t.FilePath
+		}
+		if name == "" {
+			name = report.Results.Tool.Name
+		}
+		suite, ok := byName[name]
+		if !ok {
+			suite = &apistructs.TestSuite{Name: name, Package: report.Results.Tool.Name}
+			byName[name] = suite
+			suites = append(suites, suite)
+		}
+		suite.Tests = append(suite.Tests, ingestTest(t, name))
+	}
+
+	for _, suite := range suites {
+		su := &qaparser.Suite{TestSuite: suite}
+		su.Aggregate()
+	}
+	return suites, nil
+}
+
+func ingestTest(t Test, suiteName string) *apistructs.Test {
+	test := apistructs.Test{
+		Name:      t.Name,
+		Classname: t.FilePath,
+		Duration:  time.Duration(t.Duration * float64(time.Millisecond)),
+		SystemOut: strings.Join(t.Stdout, "\n"),
+		SystemErr: strings.Join(t.Stderr, "\n"),
+	}
+	if test.Classname == "" {
+		test.Classname = suiteName
+	}
+
+	switch t.Status {
+	case statusPassed:
+		test.Status = apistructs.TestStatusPassed
+	case statusFailed:
+		test.Status = apistructs.TestStatusFailed
+		test.Error = apistructs.TestError{Message: t.Message, Body: t.Trace}
+	case statusSkipped, statusPending:
+		test.Status = apistructs.TestStatusSkipped
+	default:
+		// other: 工具特有的状态, 如 flaky 重试中的状态, 无法判断结果, 按跳过处理
+		test.Status = apistructs.TestStatusSkipped
+		test.Error = apistructs.TestError{Message: t.Message, Body: t.Trace}
+	}
+
+	return &test
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctrf
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestIngest(t *testing.T) {
+	bs, err := ioutil.ReadFile("../testdata/ctrf-report.json")
+	assert.NoError(t, err)
+
+	suites, err := Ingest(bs)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(suites))
+
+	button := suites[0]
+	assert.Equal(t, "Button", button.Name)
+	assert.Equal(t, "jest", button.Package)
+	assert.Equal(t, 2, button.Totals.Tests)
+	assert.Equal(t, 1, button.Totals.Statuses[apistructs.TestStatusPassed])
+	assert.Equal(t, 1, button.Totals.Statuses[apistructs.TestStatusFailed])
+	assert.Equal(t, 46500*time.Microsecond, button.Totals.Duration)
+
+	click := button.Tests[1]
+	assert.Equal(t, "src/Button.test.js", click.Classname)
+	assert.Equal(t, "expect(received).toBe(expected)", click.Error.(apistructs.TestError).Message)
+	assert.Equal(t, "clicked\ndone", click.SystemOut)
+
+	form := suites[1]
+	assert.Equal(t, "Form > submit", form.Name)
+	assert.Equal(t, "Form > submit", form.Tests[0].Classname)
+	assert.Equal(t, apistructs.TestStatusSkipped, form.Tests[0].Status)
+
+	assert.Equal(t, "src/utils.test.js", suites[2].Name)
+	assert.Equal(t, "jest", suites[3].Name)
+	assert.Equal(t, apistructs.TestStatusSkipped, suites[3].Tests[0].Status)
+}
+
+func TestIngestInvalid(t *testing.T) {
+	_, err := Ingest([]byte("<testsuites/>"))
+	assert.Error(t, err)
+}
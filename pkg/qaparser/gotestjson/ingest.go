@@ -0,0 +1,174 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gotestjson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/qaparser"
+)
+
+// event is one line of `go test -json` output, see `go doc test2json`
+type event struct {
+	Time    time.Time `json:"Time"`
+	Action  string    `json:"Action"`
+	Package string    `json:"Package"`
+	Test    string    `json:"Test"`
+	Elapsed float64   `json:"Elapsed"`
+	Output  string    `json:"Output"`
+}
+
+const (
+	actionRun    = "run"
+	actionOutput = "output"
+	actionPass   = "pass"
+	actionFail   = "fail"
+	actionSkip   = "skip"
+	actionBench  = "bench"
+)
+
+type pkgResult struct {
+	name   string
+	output strings.Builder
+	action string
+	tests  []*testResult
+	byName map[string]*testResult
+}
+
+type testResult struct {
+	name    string
+	output  strings.Builder
+	action  string
+	elapsed float64
+}
+
+// Ingest will parse the given `go test -json` output and return one suite for each tested package.
+// Lines that are not json events, e.g. build output mixed in by `2>&1`, are ignored.
+func Ingest(data []byte) ([]*apistructs.TestSuite, error) {
+	var (
+		pkgs   []*pkgResult
+		byName = make(map[string]*pkgResult)
+		reader = bufio.NewReader(bytes.NewReader(data))
+	)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var e event
+			if json.Unmarshal(line, &e) == nil && e.Action != "" {
+				pkg, ok := byName[e.Package]
+				if !ok {
+					pkg = &pkgResult{name: e.Package, byName: make(map[string]*testResult)}
+					byName[e.Package] = pkg
+					pkgs = append(pkgs, pkg)
+				}
+				pkg.handle(e)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var suites []*apistructs.TestSuite
+	for _, pkg := range pkgs {
+		suite := pkg.toSuite()
+		if len(suite.Tests) == 0 {
+			continue
+		}
+		suites = append(suites, suite)
+	}
+	return suites, nil
+}
+
+func (p *pkgResult) handle(e event) {
+	if e.Test == "" {
+		switch e.Action {
+		case actionOutput:
+			p.output.WriteString(e.Output)
+		case actionPass, actionFail, actionSkip:
+			p.action = e.Action
+		}
+		return
+	}
+
+	t, ok := p.byName[e.Test]
+	if !ok {
+		t = &testResult{name: e.Test}
+		p.byName[e.Test] = t
+		p.tests = append(p.tests, t)
+	}
+	switch e.Action {
+	case actionOutput:
+		t.output.WriteString(e.Output)
+	case actionPass, actionFail, actionSkip, actionBench:
+		t.action = e.Action
+		t.elapsed = e.Elapsed
+	}
+}
+
+func (p *pkgResult) toSuite() *apistructs.TestSuite {
+	suite := apistructs.TestSuite{
+		Name:      p.name,
+		Package:   p.name,
+		SystemOut: p.output.String(),
+	}
+
+	for _, t := range p.tests {
+		test := &apistructs.Test{
+			Name:      t.name,
+			Classname: p.name,
+			Duration:  time.Duration(t.elapsed * float64(time.Second)),
+			SystemOut: t.output.String(),
+		}
+		switch t.action {
+		case actionPass, actionBench:
+			test.Status = apistructs.TestStatusPassed
+		case actionSkip:
+			test.Status = apistructs.TestStatusSkipped
+		case actionFail:
+			test.Status = apistructs.TestStatusFailed
+			test.Error = apistructs.TestError{Body: test.SystemOut}
+		default:
+			// 测试未结束, 通常是 panic 或超时导致整个包退出
+			test.Status = apistructs.TestStatusError
+			test.Error = apistructs.TestError{Message: "test did not finish", Body: test.SystemOut + suite.SystemOut}
+		}
+		suite.Tests = append(suite.Tests, test)
+	}
+
+	// 包失败但没有任何测试结果, 例如编译失败或 TestMain 失败
+	if p.action == actionFail && len(suite.Tests) == 0 {
+		suite.Tests = append(suite.Tests, &apistructs.Test{
+			Name:      p.name,
+			Classname: p.name,
+			Status:    apistructs.TestStatusError,
+			Error:     apistructs.TestError{Message: "package failed", Body: suite.SystemOut},
+		})
+	}
+
+	su := &qaparser.Suite{TestSuite: &suite}
+	su.Aggregate()
+	return &suite
+}
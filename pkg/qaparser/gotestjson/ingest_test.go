@@ -0,0 +1,75 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gotestjson
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestIngest(t *testing.T) {
+	bs, err := ioutil.ReadFile("../testdata/go-test.json")
+	assert.NoError(t, err)
+
+	suites, err := Ingest(bs)
+	assert.NoError(t, err)
+	// 没有测试文件的包被忽略
+	assert.Equal(t, 2, len(suites))
+
+	strutil := suites[0]
+	assert.Equal(t, "github.com/erda-project/erda/pkg/strutil", strutil.Name)
+	assert.Equal(t, 4, len(strutil.Tests))
+	assert.Equal(t, 4, strutil.Totals.Tests)
+	assert.Equal(t, 1, strutil.Totals.Statuses[apistructs.TestStatusPassed])
+	assert.Equal(t, 2, strutil.Totals.Statuses[apistructs.TestStatusFailed])
+	assert.Equal(t, 1, strutil.Totals.Statuses[apistructs.TestStatusSkipped])
+	assert.Equal(t, 30*time.Millisecond, strutil.Totals.Duration)
+
+	assert.Equal(t, "TestTrim", strutil.Tests[0].Name)
+	assert.Equal(t, 10*time.Millisecond, strutil.Tests[0].Duration)
+	assert.Equal(t, "TestSplit/empty", strutil.Tests[2].Name)
+	assert.Equal(t, apistructs.TestStatusFailed, strutil.Tests[2].Status)
+	assert.Contains(t, strutil.Tests[2].Error.(apistructs.TestError).Body, "expected 0, got 1")
+	assert.Equal(t, apistructs.TestStatusSkipped, strutil.Tests[3].Status)
+
+	broken := suites[1]
+	assert.Equal(t, 1, len(broken.Tests))
+	assert.Equal(t, apistructs.TestStatusError, broken.Tests[0].Status)
+	assert.Contains(t, broken.Tests[0].Error.(apistructs.TestError).Body, "[build failed]")
+}
+
+func TestIngestUnfinishedTest(t *testing.T) {
+	data := []byte(`{"Action":"run","Package":"a","Test":"TestPanic"}
+{"Action":"output","Package":"a","Test":"TestPanic","Output":"=== RUN   TestPanic\n"}
+{"Action":"output","Package":"a","Output":"panic: boom\n"}
+{"Action":"fail","Package":"a","Elapsed":0.1}
+`)
+	suites, err := Ingest(data)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(suites))
+	assert.Equal(t, apistructs.TestStatusError, suites[0].Tests[0].Status)
+	assert.Contains(t, suites[0].Tests[0].Error.(apistructs.TestError).Body, "panic: boom")
+}
+
+func TestIngestEmpty(t *testing.T) {
+	suites, err := Ingest(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(suites))
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package junitxml
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/qaparser"
+)
+
+// Ingest will parse the given junit xml and return a slice of all contained test suites.
+//
+// Compared with the surefire format, the variants produced by pytest and jest-junit may:
+//   - nest testsuite in testsuite, nested suites are flattened and named as "parent.child"
+//   - omit the classname of testcase, the file attribute or the suite name is used instead
+//   - omit the message attribute of failure, the first line of the failure body is used instead
+//   - report more than one result for a testcase, e.g. pytest reports a teardown error after a failure,
+//     the first one is kept
+func Ingest(data []byte) ([]*apistructs.TestSuite, error) {
+	nodes, err := qaparser.NodeParse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var suites []*apistructs.TestSuite
+	findSuites(nodes, "", &suites)
+	return suites, nil
+}
+
+func findSuites(nodes []qaparser.XmlNode, parent string, suites *[]*apistructs.TestSuite) {
+	for _, node := range nodes {
+		switch node.XMLName.Local {
+		case "testsuite":
+			name := node.Attr("name")
+			if parent != "" {
+				name = parent + "." + name
+			}
+			if suite := ingestSuite(node, name); len(suite.Tests) > 0 {
+				*suites = append(*suites, suite)
+			}
+			findSuites(node.Nodes, name, suites)
+		default:
+			findSuites(node.Nodes, parent, suites)
+		}
+	}
+}
+
+func ingestSuite(root qaparser.XmlNode, name string) *apistructs.TestSuite {
+	suite := apistructs.TestSuite{
+		Name:    name,
+		Package: root.Attr("package"),
+	}
+
+	for _, node := range root.Nodes {
+		switch node.XMLName.Local {
+		case "testcase":
+			suite.Tests = append(suite.Tests, ingestTestcase(node, name))
+		case "properties":
+			suite.Properties = ingestProperties(node)
+		case "system-out":
+			suite.SystemOut = string(node.Content)
+		case "system-err":
+			suite.SystemErr = string(node.Content)
+		}
+	}
+
+	su := &qaparser.Suite{TestSuite: &suite}
+	su.Aggregate()
+	return &suite
+}
+
+func ingestProperties(root qaparser.XmlNode) map[string]string {
+	props := make(map[string]string, len(root.Nodes))
+	for _, node := range root.Nodes {
+		if node.XMLName.Local == "property" {
+			props[node.Attr("name")] = node.Attr("value")
+		}
+	}
+	return props
+}
+
+func ingestTestcase(root qaparser.XmlNode, suiteName string) *apistructs.Test {
+	test := apistructs.Test{
+		Name:      root.Attr("name"),
+		Classname: root.Attr("classname"),
+		Duration:  duration(root.Attr("time")),
+		Status:    apistructs.TestStatusPassed,
+	}
+	if test.Classname == "" {
+		test.Classname = root.Attr("file")
+	}
+	if test.Classname == "" {
+		test.Classname = suiteName
+	}
+
+	for _, node := range root.Nodes {
+		switch node.XMLName.Local {
+		case "skipped":
+			if test.Status == apistructs.TestStatusPassed {
+				test.Status = apistructs.TestStatusSkipped
+				test.Error = ingestError(node)
+			}
+		case "failure":
+			if test.Status == apistructs.TestStatusPassed {
+				test.Status = apistructs.TestStatusFailed
+				test.Error = ingestError(node)
+			}
+		case "error":
+			if test.Status == apistructs.TestStatusPassed {
+				test.Status = apistructs.TestStatusError
+				test.Error = ingestError(node)
+			}
+		case "system-out":
+			test.SystemOut = string(node.Content)
+		case "system-err":
+			test.SystemErr = string(node.Content)
+		}
+	}
+
+	return &test
+}
+
+func ingestError(root qaparser.XmlNode) apistructs.TestError {
+	e := apistructs.TestError{
+		Body:    string(root.Content),
+		Type:    root.Attr("type"),
+		Message: root.Attr("message"),
+	}
+	if e.Message == "" {
+		for _, line := range strings.Split(e.Body, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				e.Message = line
+				break
+			}
+		}
+	}
+	return e
+}
+
+// duration parses the time attribute in seconds, some reporters format it with thousands separators, e.g. "1,234.567"
+func duration(t string) time.Duration {
+	t = strings.ReplaceAll(strings.TrimSpace(t), ",", "")
+	if s, err := strconv.ParseFloat(t, 64); err == nil {
+		return time.Duration(s*1000000) * time.Microsecond
+	}
+	if d, err := time.ParseDuration(t); err == nil {
+		return d
+	}
+	return 0
+}
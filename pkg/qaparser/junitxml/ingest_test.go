@@ -0,0 +1,83 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package junitxml
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestIngestPytest(t *testing.T) {
+	bs, err := ioutil.ReadFile("../testdata/pytest-junit.xml")
+	assert.NoError(t, err)
+
+	suites, err := Ingest(bs)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(suites))
+
+	suite := suites[0]
+	assert.Equal(t, "pytest", suite.Name)
+	assert.Equal(t, 5, suite.Totals.Tests)
+	assert.Equal(t, 2, suite.Totals.Statuses[apistructs.TestStatusPassed])
+	assert.Equal(t, 1, suite.Totals.Statuses[apistructs.TestStatusFailed])
+	assert.Equal(t, 1, suite.Totals.Statuses[apistructs.TestStatusError])
+	assert.Equal(t, 1, suite.Totals.Statuses[apistructs.TestStatusSkipped])
+
+	// failure 之后的 teardown error 被忽略
+	div := suite.Tests[1]
+	assert.Equal(t, apistructs.TestStatusFailed, div.Status)
+	assert.Equal(t, "ZeroDivisionError: division by zero", div.Error.(apistructs.TestError).Message)
+	assert.Contains(t, div.Error.(apistructs.TestError).Body, "assert div(1, 0) == 0")
+
+	assert.Equal(t, "only run on windows", suite.Tests[3].Error.(apistructs.TestError).Message)
+	assert.Equal(t, "hello", suite.Tests[4].SystemOut)
+	assert.Equal(t, 1234*time.Second, suite.Tests[4].Duration)
+}
+
+func TestIngestJest(t *testing.T) {
+	bs, err := ioutil.ReadFile("../testdata/jest-junit.xml")
+	assert.NoError(t, err)
+
+	suites, err := Ingest(bs)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(suites))
+
+	button := suites[0]
+	assert.Equal(t, "Button", button.Name)
+	assert.Equal(t, 3, button.Totals.Tests)
+	assert.Equal(t, 46*time.Millisecond, button.Totals.Duration)
+
+	click := button.Tests[1]
+	assert.Equal(t, "src/Button.test.js", click.Classname)
+	assert.Equal(t, apistructs.TestStatusFailed, click.Status)
+	assert.Equal(t, "Error: expect(received).toBe(expected)", click.Error.(apistructs.TestError).Message)
+	assert.Equal(t, apistructs.TestStatusSkipped, button.Tests[2].Status)
+
+	nested := suites[1]
+	assert.Equal(t, "Button.nested", nested.Name)
+	assert.Equal(t, "Button.nested", nested.Tests[0].Classname)
+}
+
+func TestDuration(t *testing.T) {
+	assert.Equal(t, 1500*time.Millisecond, duration("1.5"))
+	assert.Equal(t, 1234500*time.Millisecond, duration("1,234.5"))
+	assert.Equal(t, 2*time.Second, duration("2s"))
+	assert.Equal(t, time.Duration(0), duration(""))
+}
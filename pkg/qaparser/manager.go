@@ -29,6 +29,11 @@ type Parser interface {
 	Register()
 }
 
+// Ingester parses report content directly, used when the report is not uploaded to cloud storage
+type Ingester interface {
+	Ingest(data []byte) ([]*apistructs.TestSuite, error)
+}
+
 type Manager struct {
 	parsers map[types.TestParserType]Parser
 }
@@ -64,3 +69,16 @@ func Register(p Parser, types ...types.TestParserType) error {
 
 	return nil
 }
+
+// Ingest parses report content with the parser registered for the given type
+func (m *Manager) Ingest(t types.TestParserType, data []byte) ([]*apistructs.TestSuite, error) {
+	p, ok := m.parsers[t]
+	if !ok {
+		return nil, errors.Errorf("not found test type=%s", t)
+	}
+	ingester, ok := p.(Ingester)
+	if !ok {
+		return nil, errors.Errorf("test type=%s not support ingest report content", t)
+	}
+	return ingester.Ingest(data)
+}
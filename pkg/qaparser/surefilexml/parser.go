@@ -57,3 +57,7 @@ func (DefaultParser) Parse(endpoint, ak, sk, bucket, objectName string) ([]*apis
 
 	return suites, nil
 }
+
+func (DefaultParser) Ingest(data []byte) ([]*apistructs.TestSuite, error) {
+	return Ingest(data)
+}
@@ -57,3 +57,11 @@ func (NgParser) Parse(endpoint, ak, sk, bucket, objectName string) ([]*apistruct
 
 	return ng.Transfer()
 }
+
+func (NgParser) Ingest(data []byte) ([]*apistructs.TestSuite, error) {
+	ng, err := Ingest(data)
+	if err != nil {
+		return nil, err
+	}
+	return ng.Transfer()
+}
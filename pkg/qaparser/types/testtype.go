@@ -24,6 +24,14 @@ const (
 	NGTest TestParserType = "NGTEST"
 	// 使用 junit 生成的 xml 格式进行解析
 	JUnit TestParserType = "JUNIT"
+	// 使用 go test -json 输出的事件流进行解析
+	GoTest TestParserType = "GOTEST"
+	// 使用 pytest --junitxml 生成的 xml 格式进行解析
+	PyTest TestParserType = "PYTEST"
+	// 使用 jest-junit reporter 生成的 xml 格式进行解析
+	Jest TestParserType = "JEST"
+	// 使用 CTRF (Common Test Report Format) 的 json 格式进行解析
+	CTRF TestParserType = "CTRF"
 )
 
 func (t TestParserType) TPValue() string {
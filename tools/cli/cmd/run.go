@@ -0,0 +1,173 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/tools/cli/command"
+	"github.com/erda-project/erda/tools/cli/localrun"
+)
+
+// RUN command
+var RUN = command.Command{
+	Name:      "run",
+	ShortHelp: "Run pipeline.yml locally",
+	Example: `
+  $ erda-cli run -f pipeline.yml
+  $ erda-cli run -f pipeline.yml --shell --env GOPROXY=https://goproxy.cn --param branch=develop
+  $ erda-cli run --action-image git-checkout=registry.erda.cloud/erda-actions/git-action:1.0 --secret token=xxx
+`,
+	Flags: []command.Flag{
+		command.StringFlag{
+			Short:        "f",
+			Name:         "file",
+			Doc:          "the local pipeline file",
+			DefaultValue: "pipeline.yml",
+		},
+		command.BoolFlag{
+			Short:        "",
+			Name:         "shell",
+			Doc:          "run commands of actions by local shell instead of docker, actions without commands are not supported",
+			DefaultValue: false,
+		},
+		command.StringFlag{
+			Short:        "w",
+			Name:         "workspace",
+			Doc:          "the local dir emulating /.pipeline/container of action, default: .erda/pipeline",
+			DefaultValue: ".erda/pipeline",
+		},
+		command.StringListFlag{
+			Short:        "e",
+			Name:         "env",
+			Doc:          "pipeline envs, format: key=value",
+			DefaultValue: nil,
+		},
+		command.StringListFlag{
+			Short:        "p",
+			Name:         "param",
+			Doc:          "pipeline run params, format: name=value",
+			DefaultValue: nil,
+		},
+		command.StringListFlag{
+			Short:        "s",
+			Name:         "secret",
+			Doc:          "secrets referenced by ((key)), format: key=value",
+			DefaultValue: nil,
+		},
+		command.StringListFlag{
+			Short:        "",
+			Name:         "action-image",
+			Doc:          "image of action without commands in docker mode, format: type=image",
+			DefaultValue: nil,
+		},
+		command.StringFlag{
+			Short:        "",
+			Name:         "image",
+			Doc:          "default image of actions only declare commands",
+			DefaultValue: localrun.DefaultImage,
+		},
+	},
+	Run: RunPipelineLocally,
+}
+
+// RunPipelineLocally parse pipeline.yml and run actions by DAG on local machine
+func RunPipelineLocally(ctx *command.Context, file string, shell bool, workspace string, envs, params, secrets, actionImages []string, image string) error {
+	yml, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	opt := localrun.Option{
+		Mode:         localrun.ModeDocker,
+		Workspace:    workspace,
+		DefaultImage: image,
+		Stdout:       os.Stdout,
+	}
+	if shell {
+		opt.Mode = localrun.ModeShell
+	}
+	if opt.Envs, err = parseKVs("env", envs); err != nil {
+		return err
+	}
+	if opt.Secrets, err = parseKVs("secret", secrets); err != nil {
+		return err
+	}
+	if opt.ActionImages, err = parseKVs("action-image", actionImages); err != nil {
+		return err
+	}
+	runParams, err := parseKVs("param", params)
+	if err != nil {
+		return err
+	}
+	for k, v := range runParams {
+		opt.RunParams = append(opt.RunParams, apistructs.PipelineRunParam{Name: k, Value: v})
+	}
+
+	runner, err := localrun.New(yml, opt)
+	if err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sig)
+	go func() {
+		select {
+		case <-sig:
+			cancel()
+		case <-runCtx.Done():
+		}
+	}()
+
+	results, runErr := runner.Run(runCtx)
+	fmt.Println()
+	for _, result := range results {
+		line := fmt.Sprintf("%-20s %-16s %s", result.Alias, result.Status, result.Cost.Round(time.Millisecond))
+		if result.Msg != "" {
+			line += "  " + result.Msg
+		}
+		if result.Failed() {
+			ctx.Fail("%s", line)
+		} else {
+			ctx.Succ("%s", line)
+		}
+	}
+	return runErr
+}
+
+func parseKVs(name string, kvs []string) (map[string]string, error) {
+	m := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		vs := strings.SplitN(kv, "=", 2)
+		if len(vs) != 2 || vs[0] == "" {
+			return nil, errors.Errorf("invalid %s: %s, format: key=value", name, kv)
+		}
+		m[vs[0]] = vs[1]
+	}
+	return m, nil
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localrun
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// same as action agent, see modules/actionagent/step_prepare.go
+const (
+	buildScript = `#!/bin/sh
+set -e
+%s
+`
+	traceScript = `
+echo + %s
+%s || ((echo "- FAIL! exit code: $?") && false)
+echo
+`
+	scriptName = "run.sh"
+)
+
+func (r *Runner) execute(ctx context.Context, action *pipelineyml.Action, envs map[string]string) error {
+	alias := action.Alias.String()
+	if err := os.MkdirAll(r.hostWorkdir(alias), 0755); err != nil {
+		return err
+	}
+	// outputs of last run should not be used
+	if err := os.RemoveAll(filepath.Dir(r.hostMetafile(alias))); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.hostMetafile(alias)), 0755); err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	switch r.opt.Mode {
+	case ModeShell:
+		if len(action.Commands) == 0 {
+			return errors.Errorf("action %s has no commands, only docker mode supported", alias)
+		}
+		script, err := r.writeScript(action)
+		if err != nil {
+			return err
+		}
+		cmd = exec.CommandContext(ctx, "sh", script)
+		cmd.Dir = r.hostWorkdir(alias)
+		cmd.Env = os.Environ()
+	case ModeDocker:
+		args := []string{"run", "--rm",
+			"-v", r.opt.Workspace + ":" + ContainerRootDir,
+			"-w", r.workdir(alias),
+		}
+		// values are passed by env of docker client, avoid secrets shown in process list
+		for _, k := range sortedKeys(envs) {
+			args = append(args, "-e", k)
+		}
+		if len(action.Commands) > 0 {
+			if _, err := r.writeScript(action); err != nil {
+				return err
+			}
+			image := action.Image
+			if image == "" {
+				image = r.opt.DefaultImage
+			}
+			args = append(args, image, "sh", filepath.Join(r.workdir(alias), scriptName))
+		} else {
+			image := r.opt.ActionImages[action.Type.String()]
+			if image == "" {
+				image = action.Image
+			}
+			if image == "" {
+				return errors.Errorf("missing image of action %s, type: %s", alias, action.Type)
+			}
+			args = append(args, image, "/opt/action/run")
+		}
+		cmd = exec.CommandContext(ctx, "docker", args...)
+		cmd.Env = os.Environ()
+	}
+	for k, v := range envs {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+
+	out := newPrefixWriter(r.opt.Stdout, fmt.Sprintf("[%s] ", alias))
+	defer out.Flush()
+	cmd.Stdout = out
+	cmd.Stderr = out
+	return cmd.Run()
+}
+
+// writeScript generate script from commands into workdir of action
+func (r *Runner) writeScript(action *pipelineyml.Action) (string, error) {
+	var buf bytes.Buffer
+	for _, command := range action.Commands {
+		escaped := fmt.Sprintf("%q", command)
+		escaped = strings.Replace(escaped, `$`, `\$`, -1)
+		buf.WriteString(fmt.Sprintf(traceScript, escaped, command))
+	}
+	script := filepath.Join(r.hostWorkdir(action.Alias.String()), scriptName)
+	if err := ioutil.WriteFile(script, []byte(fmt.Sprintf(buildScript, buf.String())), 0755); err != nil {
+		return "", err
+	}
+	return script, nil
+}
+
+// makeActionEnvs same as prepare of pipeline task
+func (r *Runner) makeActionEnvs(spec *pipelineyml.Spec, action *pipelineyml.Action) map[string]string {
+	envs := make(map[string]string)
+	// global envs
+	for k, v := range spec.Envs {
+		envs[k] = v
+	}
+	// action params -> envs
+	for k, v := range action.Params {
+		envs["ACTION_"+envKey(k)] = fmt.Sprintf("%v", v)
+	}
+	// matrix values -> envs
+	for k, v := range pipelineyml.MatrixEnvs(action.MatrixCombination) {
+		envs[k] = v
+	}
+	// secrets -> envs
+	for k, v := range r.opt.Secrets {
+		envs["PIPELINE_SECRET_"+envKey(k)] = v
+		envs[envKey(k)] = v
+	}
+	envs["PIPELINE_TASK_NAME"] = action.Alias.String()
+	envs["WORKDIR"] = r.workdir(action.Alias.String())
+	envs["METAFILE"] = r.metafile(action.Alias.String())
+	if r.opt.Mode == ModeShell {
+		envs["CONTEXTDIR"] = filepath.Join(r.opt.Workspace, contextDirName)
+	} else {
+		envs["CONTEXTDIR"] = filepath.Join(ContainerRootDir, contextDirName)
+	}
+	return envs
+}
+
+func envKey(k string) string {
+	return strings.Replace(strings.Replace(strings.ToUpper(k), ".", "_", -1), "-", "_", -1)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// readMetafile parse metafile same as action agent, json `{"metadata":[{"name":"k","value":"v"}]}` or `k=v` lines.
+func readMetafile(path string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	return parseMetafile(b), nil
+}
+
+func parseMetafile(b []byte) map[string]string {
+	outputs := make(map[string]string)
+
+	var meta struct {
+		Metadata apistructs.Metadata `json:"metadata"`
+	}
+	if err := json.NewDecoder(bytes.NewReader(b)).Decode(&meta); err == nil || err == io.EOF {
+		for _, field := range meta.Metadata {
+			outputs[field.Name] = field.Value
+		}
+		return outputs
+	}
+
+	for _, line := range strutil.Lines(string(b), true) {
+		kv := strings.SplitN(line, "=", 2)
+		k := strings.TrimSpace(kv[0])
+		var v string
+		if len(kv) > 1 {
+			v = strings.TrimSpace(kv[1])
+		}
+		outputs[k] = v
+	}
+	return outputs
+}
+
+// prefixWriter add prefix to every line, so logs of parallel actions can be distinguished
+type prefixWriter struct {
+	lock   sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    bytes.Buffer
+}
+
+func newPrefixWriter(w io.Writer, prefix string) *prefixWriter {
+	return &prefixWriter{w: w, prefix: prefix}
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.buf.Write(b)
+	for {
+		line, err := p.buf.ReadBytes('\n')
+		if err != nil {
+			// incomplete line, wait for more
+			p.buf.Write(line)
+			return len(b), nil
+		}
+		if _, err := p.w.Write(append([]byte(p.prefix), line...)); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Flush write the last incomplete line
+func (p *prefixWriter) Flush() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.buf.Len() > 0 {
+		p.w.Write(append([]byte(p.prefix), append(p.buf.Bytes(), '\n')...))
+		p.buf.Reset()
+	}
+}
@@ -0,0 +1,357 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package localrun run pipeline.yml on local machine, so developers can iterate on pipeline.yml without pushing to gittar.
+package localrun
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/dag"
+	"github.com/erda-project/erda/pkg/expression"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+)
+
+type Mode string
+
+const (
+	// ModeDocker run action in container, actions without commands run /opt/action/run of the action image
+	ModeDocker Mode = "docker"
+	// ModeShell run commands of action by local sh, only actions with commands are supported
+	ModeShell Mode = "shell"
+)
+
+// same layout as action agent in container, see modules/pipeline/pipengine/pvolumes
+const (
+	ContainerRootDir = "/.pipeline/container"
+	contextDirName   = "context"
+	metadataDirName  = "metadata"
+
+	DefaultImage = "alpine:3"
+)
+
+type Option struct {
+	Mode Mode
+	// Workspace is the local dir mounted as /.pipeline/container
+	Workspace string
+	Envs      map[string]string
+	Secrets   map[string]string
+	RunParams []apistructs.PipelineRunParam
+	// DefaultImage is used by actions which only declare commands
+	DefaultImage string
+	// ActionImages action type -> image, used by actions without commands in docker mode
+	ActionImages map[string]string
+	Stdout       io.Writer
+}
+
+type Runner struct {
+	opt Option
+	yml []byte
+
+	lock    sync.Mutex
+	outputs pipelineyml.Outputs
+}
+
+// ActionResult is the result of one action.
+type ActionResult struct {
+	Alias   string
+	Status  apistructs.PipelineStatus
+	Outputs map[string]string
+	Msg     string
+	Cost    time.Duration
+}
+
+// Failed return true if action failed, action skipped by `if` is not treated as failed.
+func (r *ActionResult) Failed() bool {
+	return r.Status.IsFailedStatus() && r.Status != apistructs.PipelineStatusNoNeedBySystem
+}
+
+func New(yml []byte, opt Option) (*Runner, error) {
+	if opt.Mode == "" {
+		opt.Mode = ModeDocker
+	}
+	if opt.Mode != ModeDocker && opt.Mode != ModeShell {
+		return nil, errors.Errorf("invalid mode: %s", opt.Mode)
+	}
+	if opt.Workspace == "" {
+		return nil, errors.New("missing workspace")
+	}
+	workspace, err := filepath.Abs(opt.Workspace)
+	if err != nil {
+		return nil, err
+	}
+	opt.Workspace = workspace
+	if opt.DefaultImage == "" {
+		opt.DefaultImage = DefaultImage
+	}
+	if opt.Secrets == nil {
+		opt.Secrets = make(map[string]string)
+	}
+	if opt.Stdout == nil {
+		opt.Stdout = os.Stdout
+	}
+	return &Runner{opt: opt, yml: yml, outputs: pipelineyml.Outputs{}}, nil
+}
+
+// Run execute actions by DAG, actions can run at the same time are executed in parallel.
+// Stop scheduling when any action failed.
+func (r *Runner) Run(ctx context.Context) ([]*ActionResult, error) {
+	y, err := pipelineyml.New(r.yml,
+		pipelineyml.WithEnvs(r.opt.Envs),
+		pipelineyml.WithSecrets(r.opt.Secrets),
+	)
+	if err != nil {
+		return nil, err
+	}
+	r.opt.RunParams, err = makeRunParams(y.Spec().Params, r.opt.RunParams)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []dag.NamedNode
+	y.Spec().LoopStagesActions(func(stage int, action *pipelineyml.Action) {
+		nodes = append(nodes, newActionNode(action))
+	})
+	g, err := dag.New(nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*ActionResult
+	var done []string
+	for {
+		schedulable, err := g.GetSchedulableNodeNames(done...)
+		if err != nil {
+			return results, err
+		}
+		if len(schedulable) == 0 {
+			return results, nil
+		}
+
+		waveResults := make([]*ActionResult, len(schedulable))
+		var wg sync.WaitGroup
+		for i := range schedulable {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				waveResults[i] = r.runAction(ctx, schedulable[i])
+			}(i)
+		}
+		wg.Wait()
+
+		var failed []string
+		for _, result := range waveResults {
+			results = append(results, result)
+			done = append(done, result.Alias)
+			if result.Failed() {
+				failed = append(failed, result.Alias)
+			}
+		}
+		if len(failed) > 0 {
+			return results, errors.Errorf("actions failed: %v", failed)
+		}
+	}
+}
+
+func (r *Runner) runAction(ctx context.Context, alias string) *ActionResult {
+	begin := time.Now()
+	result := &ActionResult{Alias: alias, Status: apistructs.PipelineStatusSuccess}
+	defer func() {
+		result.Cost = time.Since(begin)
+	}()
+	fail := func(err error) *ActionResult {
+		result.Status = apistructs.PipelineStatusFailed
+		result.Msg = err.Error()
+		return result
+	}
+
+	// render again with outputs of done actions, same as prepare of pipeline task
+	action, y, err := r.renderAction(alias)
+	if err != nil {
+		return fail(err)
+	}
+	if action.Disable {
+		result.Status = apistructs.PipelineStatusDisabled
+		return result
+	}
+	if action.Type.IsSnippet() {
+		result.Status = apistructs.PipelineStatusNoNeedBySystem
+		result.Msg = "snippet is not supported locally, skipped"
+		return result
+	}
+	if action.If != "" {
+		sign := expression.Reconcile(action.If)
+		if sign.Err != nil {
+			return fail(sign.Err)
+		}
+		if sign.Sign == expression.TaskJumpOver {
+			result.Status = apistructs.PipelineStatusNoNeedBySystem
+			result.Msg = sign.Msg
+			return result
+		}
+	}
+
+	if action.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(action.Timeout)*time.Second)
+		defer cancel()
+	}
+	if err := r.execute(ctx, action, r.makeActionEnvs(y.Spec(), action)); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			result.Status = apistructs.PipelineStatusTimeout
+			result.Msg = fmt.Sprintf("timeout after %ds", action.Timeout)
+			return result
+		}
+		return fail(err)
+	}
+
+	outputs, err := readMetafile(r.hostMetafile(alias))
+	if err != nil {
+		return fail(err)
+	}
+	result.Outputs = outputs
+	r.lock.Lock()
+	r.outputs[action.Alias] = outputs
+	r.lock.Unlock()
+	return result
+}
+
+func (r *Runner) renderAction(alias string) (*pipelineyml.Action, *pipelineyml.PipelineYml, error) {
+	r.lock.Lock()
+	outputs := make(pipelineyml.Outputs, len(r.outputs))
+	for k, v := range r.outputs {
+		outputs[k] = v
+	}
+	r.lock.Unlock()
+
+	refs, err := r.makeRefs()
+	if err != nil {
+		return nil, nil, err
+	}
+	y, err := pipelineyml.New(r.yml,
+		pipelineyml.WithEnvs(r.opt.Envs),
+		pipelineyml.WithSecrets(r.opt.Secrets),
+		pipelineyml.WithAliasesToCheckRefOp(nil, pipelineyml.ActionAlias(alias)),
+		pipelineyml.WithRefs(refs),
+		pipelineyml.WithRefOpOutputs(outputs),
+		pipelineyml.WithFlatParams(true),
+		pipelineyml.WithRunParams(apistructs.PipelineRunParams(r.opt.RunParams).ToPipelineRunParamsWithValue()),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	var found *pipelineyml.Action
+	y.Spec().LoopStagesActions(func(stage int, action *pipelineyml.Action) {
+		if action.Alias.String() == alias {
+			found = action
+		}
+	})
+	if found == nil {
+		return nil, nil, errors.Errorf("not found action %s", alias)
+	}
+	return found, y, nil
+}
+
+// makeRefs every namespace can be referenced by ${{ dirs.xxx }}
+func (r *Runner) makeRefs() (pipelineyml.Refs, error) {
+	y, err := pipelineyml.New(r.yml, pipelineyml.WithEnvs(r.opt.Envs))
+	if err != nil {
+		return nil, err
+	}
+	refs := pipelineyml.Refs{}
+	y.Spec().LoopStagesActions(func(stage int, action *pipelineyml.Action) {
+		for _, namespace := range action.Namespaces {
+			refs[namespace] = r.workdir(namespace)
+		}
+	})
+	return refs, nil
+}
+
+// makeRunParams use default value if param not passed
+func makeRunParams(params []*pipelineyml.PipelineParam, runParams []apistructs.PipelineRunParam) ([]apistructs.PipelineRunParam, error) {
+	passed := make(map[string]interface{}, len(runParams))
+	for _, rp := range runParams {
+		passed[rp.Name] = rp.Value
+	}
+	var result []apistructs.PipelineRunParam
+	for _, param := range params {
+		value, ok := passed[param.Name]
+		if !ok || value == nil {
+			if param.Default == nil && param.Required {
+				return nil, errors.Errorf("missing required param %s", param.Name)
+			}
+			value = param.Default
+			if value == nil {
+				value = pipelineyml.GetParamDefaultValue(param.Type)
+			}
+		}
+		result = append(result, apistructs.PipelineRunParam{Name: param.Name, Value: value})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// workdir return workdir of action, which is the same as ${{ dirs.alias }}
+func (r *Runner) workdir(namespace string) string {
+	if r.opt.Mode == ModeShell {
+		return filepath.Join(r.opt.Workspace, contextDirName, namespace)
+	}
+	return filepath.Join(ContainerRootDir, contextDirName, namespace)
+}
+
+func (r *Runner) metafile(alias string) string {
+	if r.opt.Mode == ModeShell {
+		return r.hostMetafile(alias)
+	}
+	return filepath.Join(ContainerRootDir, metadataDirName, alias, "metadata")
+}
+
+func (r *Runner) hostWorkdir(alias string) string {
+	return filepath.Join(r.opt.Workspace, contextDirName, alias)
+}
+
+func (r *Runner) hostMetafile(alias string) string {
+	return filepath.Join(r.opt.Workspace, metadataDirName, alias, "metadata")
+}
+
+type actionNode struct {
+	name  string
+	needs []string
+}
+
+func newActionNode(action *pipelineyml.Action) *actionNode {
+	n := &actionNode{name: action.Alias.String()}
+	for _, need := range action.Needs {
+		n.needs = append(n.needs, need.String())
+	}
+	return n
+}
+
+func (n *actionNode) NodeName() string {
+	return n.name
+}
+
+func (n *actionNode) PrevNodeNames() []string {
+	return n.needs
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localrun
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestRunnerShellMode(t *testing.T) {
+	yml := `version: "1.1"
+envs:
+  GREETING: hello
+stages:
+  - stage:
+      - custom-script:
+          alias: a
+          commands:
+            - echo "name=${GREETING}" >> $METAFILE
+            - echo content > file
+  - stage:
+      - custom-script:
+          alias: b
+          commands:
+            - test "${{ outputs.a.name }}" = hello
+            - cat ${{ dirs.a }}/file
+      - custom-script:
+          alias: c
+          if: ${{ 1 == 2 }}
+          commands:
+            - exit 1
+`
+	workspace, err := ioutil.TempDir("", "localrun")
+	assert.NoError(t, err)
+	defer os.RemoveAll(workspace)
+
+	var out bytes.Buffer
+	r, err := New([]byte(yml), Option{Mode: ModeShell, Workspace: workspace, Stdout: &out})
+	assert.NoError(t, err)
+	results, err := r.Run(context.Background())
+	assert.NoError(t, err, out.String())
+	assert.Equal(t, 3, len(results))
+
+	statuses := make(map[string]apistructs.PipelineStatus)
+	for _, result := range results {
+		statuses[result.Alias] = result.Status
+	}
+	assert.Equal(t, apistructs.PipelineStatusSuccess, statuses["a"])
+	assert.Equal(t, apistructs.PipelineStatusSuccess, statuses["b"])
+	assert.Equal(t, apistructs.PipelineStatusNoNeedBySystem, statuses["c"])
+	assert.Equal(t, "hello", results[0].Outputs["name"])
+	assert.Contains(t, out.String(), "[b] content")
+
+	_, err = os.Stat(filepath.Join(workspace, "context", "b", scriptName))
+	assert.NoError(t, err)
+}
+
+func TestRunnerStopWhenFailed(t *testing.T) {
+	yml := `version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          alias: a
+          commands:
+            - exit 2
+  - stage:
+      - custom-script:
+          alias: b
+          commands:
+            - echo b
+`
+	workspace, err := ioutil.TempDir("", "localrun")
+	assert.NoError(t, err)
+	defer os.RemoveAll(workspace)
+
+	r, err := New([]byte(yml), Option{Mode: ModeShell, Workspace: workspace, Stdout: ioutil.Discard})
+	assert.NoError(t, err)
+	results, err := r.Run(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, apistructs.PipelineStatusFailed, results[0].Status)
+}
+
+func TestParseMetafile(t *testing.T) {
+	assert.Equal(t, map[string]string{"a": "1", "b": "x=y"}, parseMetafile([]byte("a=1\n b = x=y \n")))
+	assert.Equal(t, map[string]string{"a": "1"}, parseMetafile([]byte(`{"metadata":[{"name":"a","value":"1"}]}`)))
+}
+
+func TestMakeRunParams(t *testing.T) {
+	yml := `version: "1.1"
+params:
+  - name: branch
+    default: master
+  - name: count
+    type: int
+stages: []
+`
+	r, err := New([]byte(yml), Option{Mode: ModeShell, Workspace: "."})
+	assert.NoError(t, err)
+	_, err = r.Run(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []apistructs.PipelineRunParam{
+		{Name: "branch", Value: "master"},
+		{Name: "count", Value: ""},
+	}, r.opt.RunParams)
+}